@@ -8,8 +8,11 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"plandex-cli/types"
+	"strconv"
 	"strings"
+	"time"
 
 	shared "plandex-shared"
 
@@ -929,6 +932,39 @@ func (a *Api) RejectFiles(planId, branch string, paths []string) *shared.ApiErro
 	return nil
 }
 
+func (a *Api) ReviewReplacements(planId, branch string, req shared.ReviewReplacementsRequest) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/review_replacements", GetApiHost(), planId, branch)
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPatch, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		didRefresh, apiErr := refreshAuthIfNeeded(apiErr)
+		if didRefresh {
+			return a.ReviewReplacements(planId, branch, req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
 func (a *Api) LoadContext(planId, branch string, req shared.LoadContextRequest) (*shared.LoadContextResponse, *shared.ApiError) {
 	serverUrl := fmt.Sprintf("%s/plans/%s/%s/context", GetApiHost(), planId, branch)
 	reqBytes, err := json.Marshal(req)
@@ -1522,6 +1558,114 @@ func (a *Api) ListOrgRoles() ([]*shared.OrgRole, *shared.ApiError) {
 	return roles, nil
 }
 
+func (a *Api) CreateApiToken(req shared.CreateApiTokenRequest) (*shared.CreateApiTokenResponse, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/api_tokens"
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.CreateApiToken(req)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.CreateApiTokenResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
+func (a *Api) ListApiTokens() ([]*shared.ApiToken, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/api_tokens"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListApiTokens()
+		}
+		return nil, apiErr
+	}
+
+	var tokens []*shared.ApiToken
+	err = json.NewDecoder(resp.Body).Decode(&tokens)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return tokens, nil
+}
+
+func (a *Api) RevokeApiToken(tokenId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/api_tokens/%s", GetApiHost(), tokenId)
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.RevokeApiToken(tokenId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) GetApiTokenSession() (*shared.SessionResponse, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/api_tokens/session"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		// no refresh here -- an invalid api token can't be refreshed
+		return nil, HandleApiError(resp, errorBody)
+	}
+
+	var session shared.SessionResponse
+	err = json.NewDecoder(resp.Body).Decode(&session)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &session, nil
+}
+
 func (a *Api) InviteUser(req shared.InviteRequest) *shared.ApiError {
 	serverUrl := GetApiHost() + "/invites"
 	reqBytes, err := json.Marshal(req)
@@ -2355,6 +2499,70 @@ func (a *Api) GetFileMap(req shared.GetFileMapRequest) (*shared.GetFileMapRespon
 	return &respBody, nil
 }
 
+func (a *Api) GetFileSymbols(req shared.GetFileSymbolsRequest) (*shared.GetFileSymbolsResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/file_map/symbols", GetApiHost())
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedSlowClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetFileSymbols(req)
+		}
+		return nil, apiErr
+	}
+
+	var respBody shared.GetFileSymbolsResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &respBody, nil
+}
+
+func (a *Api) GetFileDeps(req shared.GetFileDepsRequest) (*shared.GetFileDepsResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/file_map/deps", GetApiHost())
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedSlowClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetFileDeps(req)
+		}
+		return nil, apiErr
+	}
+
+	var respBody shared.GetFileDepsResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &respBody, nil
+}
+
 func (a *Api) GetContextBody(planId, branch, contextId string) (*shared.GetContextBodyResponse, *shared.ApiError) {
 	serverUrl := fmt.Sprintf("%s/plans/%s/%s/context/%s/body", GetApiHost(), planId, branch, contextId)
 
@@ -2452,3 +2660,603 @@ func (a *Api) GetBuildStatus(planId, branch string) (*shared.GetBuildStatusRespo
 
 	return &respBody, nil
 }
+
+func (a *Api) ListPlanShares(planId string) ([]*shared.PlanShare, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/shares", GetApiHost(), planId)
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListPlanShares(planId)
+		}
+		return nil, apiErr
+	}
+
+	var shares []*shared.PlanShare
+	err = json.NewDecoder(resp.Body).Decode(&shares)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return shares, nil
+}
+
+func (a *Api) SharePlan(planId string, req shared.SharePlanRequest) (*shared.PlanShare, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/shares", GetApiHost(), planId)
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.SharePlan(planId, req)
+		}
+		return nil, apiErr
+	}
+
+	var share shared.PlanShare
+	err = json.NewDecoder(resp.Body).Decode(&share)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &share, nil
+}
+
+func (a *Api) UnsharePlan(planId, userId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plans/%s/shares/%s", GetApiHost(), planId, userId)
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.UnsharePlan(planId, userId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) CreateOrgRole(req shared.CreateOrgRoleRequest) (*shared.OrgRole, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/orgs/roles"
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.CreateOrgRole(req)
+		}
+		return nil, apiErr
+	}
+
+	var role shared.OrgRole
+	err = json.NewDecoder(resp.Body).Decode(&role)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &role, nil
+}
+
+func (a *Api) UpdateOrgRole(roleId string, req shared.UpdateOrgRoleRequest) (*shared.OrgRole, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/orgs/roles/%s", GetApiHost(), roleId)
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.UpdateOrgRole(roleId, req)
+		}
+		return nil, apiErr
+	}
+
+	var role shared.OrgRole
+	err = json.NewDecoder(resp.Body).Decode(&role)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &role, nil
+}
+
+func (a *Api) DeleteOrgRole(roleId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/orgs/roles/%s", GetApiHost(), roleId)
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeleteOrgRole(roleId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) SetOrgUserRole(userId string, req shared.SetOrgUserRoleRequest) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/orgs/users/%s/role", GetApiHost(), userId)
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.SetOrgUserRole(userId, req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ListAuditLog(params types.ListAuditLogParams) ([]*shared.AuditLogEntry, *shared.ApiError) {
+	query := url.Values{}
+	if params.UserEmail != "" {
+		query.Set("userEmail", params.UserEmail)
+	}
+	if params.Action != "" {
+		query.Set("action", params.Action)
+	}
+	if params.PlanId != "" {
+		query.Set("planId", params.PlanId)
+	}
+	if params.Since != nil {
+		query.Set("since", params.Since.UTC().Format(time.RFC3339))
+	}
+	if params.Until != nil {
+		query.Set("until", params.Until.UTC().Format(time.RFC3339))
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+
+	serverUrl := fmt.Sprintf("%s/audit?%s", GetApiHost(), query.Encode())
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListAuditLog(params)
+		}
+		return nil, apiErr
+	}
+
+	var entries []*shared.AuditLogEntry
+	err = json.NewDecoder(resp.Body).Decode(&entries)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return entries, nil
+}
+
+func (a *Api) ListBudgets() ([]*shared.Budget, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/budgets"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListBudgets()
+		}
+		return nil, apiErr
+	}
+
+	var budgets []*shared.Budget
+	err = json.NewDecoder(resp.Body).Decode(&budgets)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return budgets, nil
+}
+
+func (a *Api) SetBudget(req shared.SetBudgetRequest) (*shared.Budget, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/budgets"
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.SetBudget(req)
+		}
+		return nil, apiErr
+	}
+
+	var budget shared.Budget
+	err = json.NewDecoder(resp.Body).Decode(&budget)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &budget, nil
+}
+
+func (a *Api) DeleteBudget(budgetId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/budgets/%s", GetApiHost(), budgetId)
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeleteBudget(budgetId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) GetBudgetStatus(planId string) ([]*shared.BudgetStatus, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/budgets/status"
+	if planId != "" {
+		serverUrl += "?" + url.Values{"planId": []string{planId}}.Encode()
+	}
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetBudgetStatus(planId)
+		}
+		return nil, apiErr
+	}
+
+	var statuses []*shared.BudgetStatus
+	err = json.NewDecoder(resp.Body).Decode(&statuses)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return statuses, nil
+}
+
+func (a *Api) GetUsageReport(params types.GetUsageReportParams) (*shared.UsageReport, *shared.ApiError) {
+	query := url.Values{}
+	if params.By != "" {
+		query.Set("by", string(params.By))
+	}
+	if params.Since != nil {
+		query.Set("since", params.Since.UTC().Format(time.RFC3339))
+	}
+	if params.Until != nil {
+		query.Set("until", params.Until.UTC().Format(time.RFC3339))
+	}
+	if params.PlanId != "" {
+		query.Set("planId", params.PlanId)
+	}
+	if params.UserEmail != "" {
+		query.Set("userEmail", params.UserEmail)
+	}
+	if params.SessionId != "" {
+		query.Set("sessionId", params.SessionId)
+	}
+
+	serverUrl := fmt.Sprintf("%s/usage?%s", GetApiHost(), query.Encode())
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetUsageReport(params)
+		}
+		return nil, apiErr
+	}
+
+	var report shared.UsageReport
+	err = json.NewDecoder(resp.Body).Decode(&report)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &report, nil
+}
+
+func (a *Api) GetOrgExecPolicy() (*shared.ExecPolicy, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/exec_policy"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetOrgExecPolicy()
+		}
+		return nil, apiErr
+	}
+
+	var policy shared.ExecPolicy
+	err = json.NewDecoder(resp.Body).Decode(&policy)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &policy, nil
+}
+
+func (a *Api) SetOrgExecPolicy(req shared.SetOrgExecPolicyRequest) *shared.ApiError {
+	serverUrl := GetApiHost() + "/exec_policy"
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.SetOrgExecPolicy(req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) DeleteOrgExecPolicy() *shared.ApiError {
+	serverUrl := GetApiHost() + "/exec_policy"
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeleteOrgExecPolicy()
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) SaveContextSet(planId, branch string, req shared.SaveContextSetRequest) (*shared.ContextSet, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/context_sets", GetApiHost(), planId, branch)
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.SaveContextSet(planId, branch, req)
+		}
+		return nil, apiErr
+	}
+
+	var set shared.ContextSet
+	err = json.NewDecoder(resp.Body).Decode(&set)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &set, nil
+}
+
+func (a *Api) ListContextSets(projectId string) ([]*shared.ContextSet, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/projects/%s/context_sets", GetApiHost(), projectId)
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListContextSets(projectId)
+		}
+		return nil, apiErr
+	}
+
+	var sets []*shared.ContextSet
+	err = json.NewDecoder(resp.Body).Decode(&sets)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return sets, nil
+}
+
+func (a *Api) GetContextSet(projectId, name string) (*shared.ContextSet, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/projects/%s/context_sets/%s", GetApiHost(), projectId, url.PathEscape(name))
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetContextSet(projectId, name)
+		}
+		return nil, apiErr
+	}
+
+	var set shared.ContextSet
+	err = json.NewDecoder(resp.Body).Decode(&set)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &set, nil
+}
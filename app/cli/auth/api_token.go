@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+// CI jobs and other non-interactive environments can authenticate with an API token instead of signing in. PLANDEX_HOST is only needed for self-hosted servers.
+const ApiTokenEnvVar = "PLANDEX_API_TOKEN"
+const ApiTokenHostEnvVar = "PLANDEX_HOST"
+
+func UsingApiToken() bool {
+	return os.Getenv(ApiTokenEnvVar) != ""
+}
+
+// resolveApiTokenAuth sets up the current auth from the API token env var. It's kept in memory only -- nothing is written to auth.json or accounts.json.
+func resolveApiTokenAuth() error {
+	token := strings.TrimSpace(os.Getenv(ApiTokenEnvVar))
+	if !strings.HasPrefix(token, shared.ApiTokenPrefix) {
+		return fmt.Errorf("%s should start with '%s'", ApiTokenEnvVar, shared.ApiTokenPrefix)
+	}
+
+	host := strings.TrimSuffix(os.Getenv(ApiTokenHostEnvVar), "/")
+
+	Current = &shared.ClientAuth{
+		ClientAccount: shared.ClientAccount{
+			IsCloud: host == "",
+			Host:    host,
+			Token:   token,
+		},
+	}
+
+	session, apiErr := apiClient.GetApiTokenSession()
+	if apiErr != nil {
+		return fmt.Errorf("error getting API token session: %v", apiErr.Msg)
+	}
+
+	if len(session.Orgs) == 0 {
+		return fmt.Errorf("API token has no org")
+	}
+	org := session.Orgs[0]
+
+	Current.UserId = session.UserId
+	Current.Email = session.Email
+	Current.UserName = session.UserName
+	Current.OrgId = org.Id
+	Current.OrgName = org.Name
+	Current.OrgIsTrial = org.IsTrial
+	Current.IntegratedModelsMode = org.IntegratedModelsMode
+
+	return nil
+}
@@ -30,6 +30,14 @@ func MustResolveAuth(requireOrg bool) {
 		term.OutputErrorAndExit("error resolving auth: api client not set")
 	}
 
+	if UsingApiToken() {
+		err := resolveApiTokenAuth()
+		if err != nil {
+			term.OutputErrorAndExit("error resolving auth: %v", err)
+		}
+		return
+	}
+
 	// load HomeAuthPath file into ClientAuth struct
 	bytes, err := os.ReadFile(fs.HomeAuthPath)
 
@@ -91,6 +99,10 @@ func RefreshInvalidToken() error {
 	if Current == nil {
 		return fmt.Errorf("error refreshing token: auth not loaded")
 	}
+
+	if UsingApiToken() {
+		term.OutputErrorAndExit("%s is invalid, expired, or revoked", ApiTokenEnvVar)
+	}
 	res, err := verifyEmail(Current.Email, Current.Host)
 
 	if err != nil {
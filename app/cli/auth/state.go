@@ -91,6 +91,11 @@ func writeCurrentAuth() error {
 		return fmt.Errorf("error writing auth: auth not loaded")
 	}
 
+	if UsingApiToken() {
+		// api token auth isn't persisted
+		return nil
+	}
+
 	bytes, err := json.Marshal(Current)
 
 	if err != nil {
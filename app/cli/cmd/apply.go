@@ -11,6 +11,7 @@ import (
 )
 
 var autoCommit, skipCommit, autoExec bool
+var applyBranch, applyWorktree string
 
 func init() {
 	initApplyFlags(applyCmd, false)
@@ -18,6 +19,8 @@ func init() {
 	RootCmd.AddCommand(applyCmd)
 
 	applyCmd.Flags().BoolVar(&fullAuto, "full", false, "Apply the plan and debug in full auto mode")
+	applyCmd.Flags().StringVar(&applyBranch, "branch", "", "Commit the changes to a new git branch instead of the project files")
+	applyCmd.Flags().StringVar(&applyWorktree, "worktree", "", "Commit the changes to a new git branch and check it out in a new worktree at this dir")
 }
 
 var applyCmd = &cobra.Command{
@@ -46,6 +49,15 @@ func apply(cmd *cobra.Command, args []string) {
 		term.OutputNoCurrentPlanErrorAndExit()
 	}
 
+	if applyBranch != "" || applyWorktree != "" {
+		if cmd.Flags().Changed("skip-commit") {
+			term.OutputErrorAndExit("--skip-commit can't be used with --branch or --worktree")
+		}
+		if fullAuto || cmd.Flags().Changed("debug") {
+			term.OutputErrorAndExit("--full and --debug can't be used with --branch or --worktree, since commands aren't run")
+		}
+	}
+
 	applyFlags := types.ApplyFlags{
 		AutoConfirm: true,
 		AutoCommit:  autoCommit,
@@ -53,6 +65,8 @@ func apply(cmd *cobra.Command, args []string) {
 		AutoExec:    autoExec,
 		NoExec:      noExec,
 		AutoDebug:   autoDebug,
+		Branch:      applyBranch,
+		Worktree:    applyWorktree,
 	}
 
 	tellFlags := types.TellFlags{
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var auditUser string
+var auditAction string
+var auditCurrentPlan bool
+var auditSince string
+var auditUntil string
+var auditLimit int
+var auditJSON bool
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show who took security-relevant or destructive actions in the org, and when",
+	Run:   audit,
+	Args:  cobra.NoArgs,
+}
+
+func init() {
+	RootCmd.AddCommand(auditCmd)
+
+	auditCmd.Flags().StringVarP(&auditUser, "user", "u", "", "Only show actions taken by the user with this email")
+	auditCmd.Flags().StringVarP(&auditAction, "action", "a", "", "Only show this action, like 'plan.deleted', or every action in a category, like 'plan'")
+	auditCmd.Flags().BoolVar(&auditCurrentPlan, "plan", false, "Only show actions on the current plan")
+	auditCmd.Flags().StringVar(&auditSince, "since", "", "Only show actions since a date (2025-07-01), time (2025-07-01T09:00:00Z), or duration ago (24h, 7d)")
+	auditCmd.Flags().StringVar(&auditUntil, "until", "", "Only show actions before a date, time, or duration ago")
+	auditCmd.Flags().IntVarP(&auditLimit, "limit", "n", 100, "Maximum number of entries to show")
+	auditCmd.Flags().BoolVar(&auditJSON, "json", false, "Output entries as newline-delimited JSON")
+}
+
+func audit(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	params := types.ListAuditLogParams{
+		UserEmail: auditUser,
+		Action:    auditAction,
+		Limit:     auditLimit,
+	}
+
+	if auditCurrentPlan {
+		lib.MustResolveProject()
+		if lib.CurrentPlanId == "" {
+			term.OutputNoCurrentPlanErrorAndExit()
+		}
+		params.PlanId = lib.CurrentPlanId
+	}
+
+	var err error
+	params.Since, err = parseTimeFlag(auditSince)
+	if err != nil {
+		term.OutputErrorAndExit("Invalid --since: %v", err)
+	}
+	params.Until, err = parseTimeFlag(auditUntil)
+	if err != nil {
+		term.OutputErrorAndExit("Invalid --until: %v", err)
+	}
+
+	if !auditJSON {
+		term.StartSpinner("")
+	}
+	entries, apiErr := api.Client.ListAuditLog(params)
+	if !auditJSON {
+		term.StopSpinner()
+	}
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing audit log: %v", apiErr.Msg)
+	}
+
+	if auditJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, entry := range entries {
+			err := encoder.Encode(entry)
+			if err != nil {
+				term.OutputErrorAndExit("Error encoding audit log entry: %v", err)
+			}
+		}
+		return
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("🤷‍♂️ No matching audit log entries")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Time", "User", "Action", "Plan", "Details"})
+
+	for _, entry := range entries {
+		user := entry.UserEmail
+		if entry.ApiTokenId != nil {
+			user += " (API token)"
+		}
+
+		var plan string
+		if entry.PlanId != nil {
+			plan = *entry.PlanId
+			if entry.Branch != nil {
+				plan += " / " + *entry.Branch
+			}
+		}
+
+		table.Append([]string{
+			entry.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			user,
+			string(entry.Action),
+			plan,
+			formatAuditData(entry.Data),
+		})
+	}
+
+	table.Render()
+
+	if len(entries) == auditLimit {
+		fmt.Println()
+		fmt.Printf("Showing the %d most recent entries. Use --limit or --since/--until to see more.\n", auditLimit)
+	}
+}
+
+// parseTimeFlag accepts a date, an RFC 3339 time, or a duration ago like '24h' or '7d'
+func parseTimeFlag(s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return &t, nil
+	}
+
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err == nil {
+			t := time.Now().AddDate(0, 0, -days)
+			return &t, nil
+		}
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		t := time.Now().Add(-d)
+		return &t, nil
+	}
+
+	return nil, fmt.Errorf("'%s' isn't a date, time, or duration", s)
+}
+
+func formatAuditData(data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", key, data[key]))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,356 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var budgetUser string
+var budgetCurrentPlan bool
+var budgetPeriod string
+var budgetTokens string
+var budgetCost string
+var budgetWarn bool
+
+var budgetsCmd = &cobra.Command{
+	Use:   "budgets",
+	Short: "List the org's model spend budgets",
+	Run:   listBudgets,
+	Args:  cobra.NoArgs,
+}
+
+var listBudgetsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the org's model spend budgets",
+	Run:   listBudgets,
+	Args:  cobra.NoArgs,
+}
+
+var setBudgetCmd = &cobra.Command{
+	Use:   "set [org|user|plan]",
+	Short: "Set a daily or monthly token or cost budget for the org, users, or plans",
+	Long: `Set a daily or monthly token or cost budget for the org, users, or plans.
+
+A user budget without --user applies to each user in the org separately. A plan budget without --plan applies to each plan separately.
+Setting a budget with the same scope, user or plan, and period as an existing budget replaces its limits.`,
+	Run:  setBudget,
+	Args: cobra.ExactArgs(1),
+}
+
+var deleteBudgetCmd = &cobra.Command{
+	Use:     "rm [budget-id]",
+	Aliases: []string{"delete"},
+	Short:   "Remove a budget",
+	Run:     deleteBudget,
+	Args:    cobra.MaximumNArgs(1),
+}
+
+func init() {
+	RootCmd.AddCommand(budgetsCmd)
+	budgetsCmd.AddCommand(listBudgetsCmd)
+	budgetsCmd.AddCommand(setBudgetCmd)
+	budgetsCmd.AddCommand(deleteBudgetCmd)
+
+	setBudgetCmd.Flags().StringVarP(&budgetUser, "user", "u", "", "For a user budget, only apply it to the user with this email")
+	setBudgetCmd.Flags().BoolVar(&budgetCurrentPlan, "plan", false, "For a plan budget, only apply it to the current plan")
+	setBudgetCmd.Flags().StringVar(&budgetPeriod, "period", string(shared.BudgetPeriodMonthly), "Period the budget resets after: 'daily' or 'monthly' (UTC)")
+	setBudgetCmd.Flags().StringVar(&budgetTokens, "tokens", "", "Maximum input and output tokens per period, like 500000, 500k, or 2m")
+	setBudgetCmd.Flags().StringVar(&budgetCost, "cost", "", "Maximum model spend in USD per period, like 25 or 2.50")
+	setBudgetCmd.Flags().BoolVar(&budgetWarn, "warn", false, "Only warn when a request would go over the budget instead of blocking it")
+}
+
+func listBudgets(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	mustBeSelfHostedForBudgets()
+
+	budgets := mustListBudgets()
+
+	if len(budgets) == 0 {
+		fmt.Println("🤷‍♂️ No budgets")
+		fmt.Println()
+		term.PrintCmds("", "budgets set")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"ID", "Applies To", "Period", "Limit", "When Exceeded"})
+
+	for _, budget := range budgets {
+		table.Append([]string{
+			budget.Id,
+			describeBudgetTarget(budget),
+			string(budget.Period),
+			formatBudgetLimit(budget),
+			string(budget.Action),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "usage", "budgets set", "budgets rm")
+}
+
+func setBudget(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	mustBeSelfHostedForBudgets()
+
+	req := shared.SetBudgetRequest{
+		Scope:     shared.BudgetScope(strings.ToLower(args[0])),
+		UserEmail: budgetUser,
+		Period:    shared.BudgetPeriod(strings.ToLower(budgetPeriod)),
+		Action:    shared.BudgetActionBlock,
+	}
+
+	if req.Scope != shared.BudgetScopeOrg && req.Scope != shared.BudgetScopeUser && req.Scope != shared.BudgetScopePlan {
+		term.OutputErrorAndExit("Scope must be 'org', 'user', or 'plan'")
+	}
+
+	if budgetUser != "" && req.Scope != shared.BudgetScopeUser {
+		term.OutputErrorAndExit("--user can only be used with a user budget")
+	}
+
+	if budgetCurrentPlan {
+		if req.Scope != shared.BudgetScopePlan {
+			term.OutputErrorAndExit("--plan can only be used with a plan budget")
+		}
+		lib.MustResolveProject()
+		if lib.CurrentPlanId == "" {
+			term.OutputNoCurrentPlanErrorAndExit()
+		}
+		req.PlanId = lib.CurrentPlanId
+	}
+
+	if budgetTokens == "" && budgetCost == "" {
+		term.OutputErrorAndExit("Set a limit with --tokens, --cost, or both")
+	}
+
+	if budgetTokens != "" {
+		tokens, err := parseTokenAmount(budgetTokens)
+		if err != nil {
+			term.OutputErrorAndExit("Invalid --tokens: %v", err)
+		}
+		req.MaxTokens = &tokens
+	}
+
+	if budgetCost != "" {
+		cost, err := decimal.NewFromString(strings.TrimPrefix(strings.TrimSpace(budgetCost), "$"))
+		if err != nil {
+			term.OutputErrorAndExit("Invalid --cost: %v", err)
+		}
+		req.MaxCost = &cost
+	}
+
+	if budgetWarn {
+		req.Action = shared.BudgetActionWarn
+	}
+
+	term.StartSpinner("")
+	budget, apiErr := api.Client.SetBudget(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error setting budget: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Set %s budget of %s for %s\n", budget.Period, color.New(color.Bold, term.ColorHiCyan).Sprint(formatBudgetLimit(budget)), strings.ToLower(describeBudgetTarget(budget)))
+	fmt.Println()
+	term.PrintCmds("", "budgets", "usage")
+}
+
+func deleteBudget(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	mustBeSelfHostedForBudgets()
+
+	budgets := mustListBudgets()
+
+	if len(budgets) == 0 {
+		fmt.Println("🤷‍♂️ No budgets")
+		return
+	}
+
+	var budget *shared.Budget
+	if len(args) > 0 {
+		for _, b := range budgets {
+			if b.Id == args[0] {
+				budget = b
+				break
+			}
+		}
+		if budget == nil {
+			term.OutputErrorAndExit("Budget '%s' not found", args[0])
+		}
+	} else {
+		var opts []string
+		for _, b := range budgets {
+			opts = append(opts, fmt.Sprintf("%s | %s | %s", describeBudgetTarget(b), b.Period, formatBudgetLimit(b)))
+		}
+
+		selected, err := term.SelectFromList("Select a budget to remove:", opts)
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting budget: %v", err)
+		}
+
+		for i, opt := range opts {
+			if opt == selected {
+				budget = budgets[i]
+				break
+			}
+		}
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteBudget(budget.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing budget: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Removed %s budget for %s\n", budget.Period, strings.ToLower(describeBudgetTarget(budget)))
+}
+
+// showBudgetStatuses prints how much of each budget that applies to the user, and to the plan if planId is set, has been used this period.
+// It prints nothing if no budgets apply.
+func showBudgetStatuses(planId string) {
+	term.StartSpinner("")
+	statuses, apiErr := api.Client.GetBudgetStatus(planId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting budget status: %v", apiErr.Msg)
+	}
+
+	if len(statuses) == 0 {
+		return
+	}
+
+	color.New(color.Bold, term.ColorHiCyan).Println("💰 Budgets")
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Applies To", "Period", "Used", "Remaining", "Resets"})
+
+	for _, status := range statuses {
+		budget := status.Budget
+
+		var used, remaining []string
+		if budget.MaxTokens != nil {
+			used = append(used, fmt.Sprintf("%s / %s tokens", formatTokenAmount(status.UsedTokens), formatTokenAmount(*budget.MaxTokens)))
+			remaining = append(remaining, formatTokenAmount(*status.RemainingTokens)+" tokens")
+		}
+		if budget.MaxCost != nil {
+			used = append(used, fmt.Sprintf("%s / %s", formatSpend(status.UsedCost), formatSpend(*budget.MaxCost)))
+			remaining = append(remaining, formatSpend(*status.RemainingCost))
+		}
+
+		remainingStr := strings.Join(remaining, "\n")
+		if status.Exceeded {
+			c := term.ColorHiRed
+			if budget.Action == shared.BudgetActionWarn {
+				c = term.ColorHiYellow
+			}
+			remainingStr = color.New(c, color.Bold).Sprint("Over budget (" + string(budget.Action) + ")")
+		}
+
+		table.Append([]string{
+			describeBudgetTarget(budget),
+			string(budget.Period),
+			strings.Join(used, "\n"),
+			remainingStr,
+			status.PeriodEnd.Local().Format("Jan 2 15:04"),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+}
+
+func mustBeSelfHostedForBudgets() {
+	if auth.Current.IsCloud {
+		term.OutputErrorAndExit("Budgets are only available on self-hosted servers. On Plandex Cloud, set a monthly limit in billing settings.")
+	}
+}
+
+func mustListBudgets() []*shared.Budget {
+	term.StartSpinner("")
+	budgets, apiErr := api.Client.ListBudgets()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing budgets: %v", apiErr.Msg)
+	}
+
+	return budgets
+}
+
+func describeBudgetTarget(budget *shared.Budget) string {
+	switch budget.Scope {
+	case shared.BudgetScopeUser:
+		if budget.UserEmail != nil {
+			return "User " + *budget.UserEmail
+		}
+		return "Each user"
+	case shared.BudgetScopePlan:
+		if budget.PlanName != nil {
+			return "Plan " + *budget.PlanName
+		}
+		return "Each plan"
+	}
+	return "Org"
+}
+
+func formatBudgetLimit(budget *shared.Budget) string {
+	var limits []string
+	if budget.MaxTokens != nil {
+		limits = append(limits, formatTokenAmount(*budget.MaxTokens)+" tokens")
+	}
+	if budget.MaxCost != nil {
+		limits = append(limits, formatSpend(*budget.MaxCost))
+	}
+	return strings.Join(limits, " / ")
+}
+
+// parseTokenAmount accepts a plain number or one with a k or m suffix, like '500k' or '2m'
+func parseTokenAmount(s string) (int64, error) {
+	s = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), ",", ""))
+
+	multiplier := 1.0
+	if strings.HasSuffix(s, "k") {
+		multiplier = 1000
+		s = strings.TrimSuffix(s, "k")
+	} else if strings.HasSuffix(s, "m") {
+		multiplier = 1000000
+		s = strings.TrimSuffix(s, "m")
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("'%s' isn't a positive number of tokens", s)
+	}
+
+	return int64(n * multiplier), nil
+}
+
+func formatTokenAmount(n int64) string {
+	switch {
+	case n >= 1000000:
+		return strings.TrimSuffix(strconv.FormatFloat(float64(n)/1000000, 'f', 2, 64), ".00") + "M"
+	case n >= 1000:
+		return strings.TrimSuffix(strconv.FormatFloat(float64(n)/1000, 'f', 1, 64), ".0") + "k"
+	}
+	return strconv.FormatInt(n, 10)
+}
@@ -52,6 +52,7 @@ func build(cmd *cobra.Command, args []string) {
 	}, types.BuildFlags{
 		BuildBg:   tellBg,
 		AutoApply: tellAutoApply,
+		JSON:      tellJSON,
 	})
 
 	if err != nil {
@@ -91,7 +92,7 @@ func build(cmd *cobra.Command, args []string) {
 			TellFlags:  tellFlags,
 			OnExecFail: plan_exec.GetOnApplyExecFail(applyFlags, tellFlags),
 		})
-	} else {
+	} else if !tellJSON {
 		fmt.Println()
 		term.PrintCmds("", "diff", "diff --ui", "apply", "reject", "log")
 	}
@@ -32,6 +32,7 @@ func init() {
 		omitExec:         true,
 		omitSmartContext: true,
 		omitSkipMenu:     true,
+		omitJSON:         true,
 	})
 
 }
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strconv"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var contextSetShared bool
+
+var contextSetsCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Save and restore named sets of context",
+}
+
+var saveContextSetCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save the current plan's context as a named set in the project",
+	Long: `Save the current plan's context as a named set in the project, so it can be restored into other plans with 'plandex context restore <name>'.
+
+A set records how each context was loaded -- file paths, urls, symbols, map and tree directories, image detail, git diffs and commands -- rather than copying it, so restoring it loads current contents. Notes and piped data are saved as-is.
+Saving with the name of one of your existing sets replaces it.`,
+	Run:  saveContextSet,
+	Args: cobra.ExactArgs(1),
+}
+
+var restoreContextSetCmd = &cobra.Command{
+	Use:   "restore <name>",
+	Short: "Load a saved context set into the current plan",
+	Long: `Load a saved context set into the current plan. Your own set is used if you have one with this name, otherwise a set shared with the org.
+
+Paths are reloaded relative to the current directory, like with 'plandex load', and anything that's already in context is skipped. Paths outside the project are skipped too.
+
+Commands and git diffs that someone else loaded are shown and only run if you confirm them, even if you saved the set.`,
+	Run:  restoreContextSet,
+	Args: cobra.ExactArgs(1),
+}
+
+var listContextSetsCmd = &cobra.Command{
+	Use:     "sets",
+	Aliases: []string{"ls"},
+	Short:   "List your context sets in the project and the ones shared with the org",
+	Run:     listContextSets,
+	Args:    cobra.NoArgs,
+}
+
+func init() {
+	RootCmd.AddCommand(contextSetsCmd)
+	contextSetsCmd.AddCommand(saveContextSetCmd)
+	contextSetsCmd.AddCommand(restoreContextSetCmd)
+	contextSetsCmd.AddCommand(listContextSetsCmd)
+
+	saveContextSetCmd.Flags().BoolVar(&contextSetShared, "shared", false, "Share the set with org members who have access to the project")
+}
+
+func saveContextSet(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+		return
+	}
+
+	term.StartSpinner("")
+	set, apiErr := api.Client.SaveContextSet(lib.CurrentPlanId, lib.CurrentBranch, shared.SaveContextSetRequest{
+		Name:     args[0],
+		IsShared: contextSetShared,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error saving context set: %v", apiErr.Msg)
+		return
+	}
+
+	sharedMsg := ""
+	if set.IsShared {
+		sharedMsg = " and shared it with the org"
+	}
+	fmt.Printf("✅ Saved %d %s as %s%s\n", len(set.Entries), contextSetEntriesLabel(len(set.Entries)), color.New(color.Bold, term.ColorHiCyan).Sprint(set.Name), sharedMsg)
+	fmt.Println()
+	term.PrintCmds("", "context restore", "context sets")
+}
+
+func restoreContextSet(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+		return
+	}
+
+	term.StartSpinner("")
+	set, apiErr := api.Client.GetContextSet(lib.CurrentProjectId, args[0])
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting context set: %v", apiErr.Msg)
+		return
+	}
+
+	lib.MustRestoreContextSet(set)
+}
+
+func listContextSets(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	term.StartSpinner("")
+	sets, apiErr := api.Client.ListContextSets(lib.CurrentProjectId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing context sets: %v", apiErr.Msg)
+		return
+	}
+
+	if len(sets) == 0 {
+		fmt.Println("🤷‍♂️ No context sets")
+		fmt.Println()
+		term.PrintCmds("", "context save")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "Entries", "Owner", "Shared", "Updated"})
+
+	for _, set := range sets {
+		owner := set.OwnerEmail
+		if set.OwnerId == auth.Current.UserId {
+			owner = "you"
+		}
+		isShared := ""
+		if set.IsShared {
+			isShared = "✓"
+		}
+		table.Append([]string{
+			set.Name,
+			strconv.Itoa(len(set.Entries)),
+			owner,
+			isShared,
+			format.Time(set.UpdatedAt),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "context restore", "context save")
+}
+
+func contextSetEntriesLabel(n int) string {
+	if n == 1 {
+		return "context"
+	}
+	return "contexts"
+}
@@ -49,6 +49,7 @@ func doContinue(cmd *cobra.Command, args []string) {
 		AutoApply:       tellAutoApply,
 		IsChatOnly:      chatOnly,
 		SkipChangesMenu: tellSkipMenu,
+		JSON:            tellJSON,
 	}
 
 	plan_exec.TellPlan(plan_exec.ExecParams{
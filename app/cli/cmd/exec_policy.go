@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/execpolicy"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var execPolicyCmd = &cobra.Command{
+	Use:   "exec-policy",
+	Short: "Show the rules for commands in apply scripts",
+	Long: `Show the allow, confirm, and deny rules that commands in the plan's apply scripts are checked against before they run.
+
+Rules come from .plandex-policy.json in the project root and from the org's policy. When rules from both match a command, the strictest one wins.
+
+Denied commands never run. Commands that need confirmation are always confirmed, even with auto-exec. Commands that no rule matches get the policy's default, which is confirm unless it's set.`,
+	Run:  showExecPolicy,
+	Args: cobra.NoArgs,
+}
+
+var checkExecPolicyCmd = &cobra.Command{
+	Use:   "check [command]",
+	Short: "Check commands against the exec policy",
+	Example: `  plandex exec-policy check 'npm run build && git push'
+  plandex exec-policy check "$(cat _apply.sh)"`,
+	Run:  checkExecPolicy,
+	Args: cobra.MinimumNArgs(1),
+}
+
+var setOrgExecPolicyCmd = &cobra.Command{
+	Use:   "set-org [file]",
+	Short: "Set the org's exec policy from a JSON file",
+	Long: `Set the org's exec policy from a JSON file in the same format as .plandex-policy.json, like:
+
+{
+  "allow": ["go test", "npm run *"],
+  "confirm": ["rm -rf", "curl | sh"],
+  "deny": ["git push", "sudo"],
+  "default": "confirm"
+}`,
+	Run:  setOrgExecPolicy,
+	Args: cobra.ExactArgs(1),
+}
+
+var clearOrgExecPolicyCmd = &cobra.Command{
+	Use:   "clear-org",
+	Short: "Remove the org's exec policy",
+	Run:   clearOrgExecPolicy,
+	Args:  cobra.NoArgs,
+}
+
+func init() {
+	RootCmd.AddCommand(execPolicyCmd)
+	execPolicyCmd.AddCommand(checkExecPolicyCmd)
+	execPolicyCmd.AddCommand(setOrgExecPolicyCmd)
+	execPolicyCmd.AddCommand(clearOrgExecPolicyCmd)
+}
+
+func showExecPolicy(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	term.StartSpinner("")
+	policy := lib.MustGetExecPolicy()
+	term.StopSpinner()
+
+	if policy.IsEmpty() {
+		fmt.Println("🤷‍♂️ No exec policy -- apply scripts are confirmed as a whole, or run without asking with auto-exec")
+		fmt.Println()
+		fmt.Printf("Add allow, confirm, and deny rules to %s in the project root, or use %s to set them for the whole org.\n", lib.ExecPolicyFile, color.New(color.Bold, term.ColorHiCyan).Sprint("plandex exec-policy set-org"))
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Verdict", "Rule", "From"})
+
+	for _, verdict := range []shared.ExecPolicyVerdict{shared.ExecPolicyDeny, shared.ExecPolicyConfirm, shared.ExecPolicyAllow} {
+		for _, rule := range policy.Rules {
+			if rule.Verdict == verdict {
+				table.Append([]string{string(rule.Verdict), rule.Pattern, rule.Source})
+			}
+		}
+	}
+
+	defaultSource := policy.DefaultSource
+	if defaultSource == "" {
+		defaultSource = "built-in"
+	}
+	table.Append([]string{string(policy.Default), "(anything else)", defaultSource})
+
+	table.Render()
+	fmt.Println()
+
+	term.PrintCmds("", "exec-policy check")
+}
+
+func checkExecPolicy(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	term.StartSpinner("")
+	policy := lib.MustGetExecPolicy()
+	term.StopSpinner()
+
+	results := policy.Evaluate(strings.Join(args, " "))
+
+	if len(results) == 0 {
+		fmt.Println("🤷‍♂️ No commands to check")
+		return
+	}
+
+	lib.PrintExecPolicyResults(results)
+	fmt.Println()
+
+	switch execpolicy.Verdict(results) {
+	case shared.ExecPolicyAllow:
+		fmt.Println("✅ Would run without confirmation under auto-exec")
+	case shared.ExecPolicyConfirm:
+		fmt.Println("⚠️  Would need confirmation before running")
+	case shared.ExecPolicyDeny:
+		fmt.Println("⛔ Would be blocked")
+	}
+}
+
+func setOrgExecPolicy(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	bytes, err := os.ReadFile(args[0])
+	if err != nil {
+		term.OutputErrorAndExit("Error reading %s: %v", args[0], err)
+	}
+
+	var policy shared.ExecPolicy
+	err = json.Unmarshal(bytes, &policy)
+	if err != nil {
+		term.OutputErrorAndExit("Error parsing %s: %v", args[0], err)
+	}
+
+	err = policy.Validate()
+	if err != nil {
+		term.OutputErrorAndExit("Invalid exec policy: %v", err)
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.SetOrgExecPolicy(shared.SetOrgExecPolicyRequest{Policy: &policy})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error setting org exec policy: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Set the org's exec policy: %d allow, %d confirm, and %d deny rules\n", len(policy.Allow), len(policy.Confirm), len(policy.Deny))
+	fmt.Println()
+
+	term.PrintCmds("", "exec-policy", "exec-policy check")
+}
+
+func clearOrgExecPolicy(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	confirmed, err := term.ConfirmYesNo("Remove the org's exec policy?")
+	if err != nil {
+		term.OutputErrorAndExit("Error getting confirmation: %v", err)
+	}
+	if !confirmed {
+		return
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteOrgExecPolicy()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing org exec policy: %v", apiErr.Msg)
+	}
+
+	fmt.Println("✅ Removed the org's exec policy")
+}
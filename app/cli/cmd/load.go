@@ -19,14 +19,30 @@ var (
 	forceSkipIgnore bool
 	imageDetail     string
 	defsOnly        bool
+	gitDiff         string
+	gitStaged       bool
+	gitCommits      string
+	changedSince    string
+	gitFiles        bool
+	loadCmds        []string
+	withDeps        int
+	crawl           bool
+	crawlDepth      int
+	samePrefix      bool
 )
 
 var contextLoadCmd = &cobra.Command{
 	Use:     "load [files-or-urls...]",
 	Aliases: []string{"l", "add"},
 	Short:   "Load context from various inputs",
-	Long:    `Load context from a file path, a directory, a URL, an image, a note, or piped data.`,
-	Run:     contextLoad,
+	Long: `Load context from a file path, a directory, a URL, an image, a note, or piped data.
+
+PDFs, Word documents (.docx), Jupyter notebooks (.ipynb), and tables (.csv, .tsv, .parquet) are loaded as text extracted from them -- notebooks as their cells with truncated outputs, and tables as a summary of their columns with sample rows. Notebooks and .csv/.tsv tables under 100 KB are loaded as regular files. Documents are re-extracted on 'plandex update'.
+
+Load a documentation site with --crawl, which follows links from a URL within the same site (and its sitemap.xml), respecting robots.txt. Each page is loaded as its own URL context and is only refetched on 'plandex update' when it has changed.
+
+Load just some definitions from a file with file#Symbol, like 'server/api.go#Server.Start'. Symbols can be globs ('api.go#Handle*') or comma-separated ('api.go#Server.Start,Server.Stop'). Enclosing signatures are included, and the selection stays in sync with the file on 'plandex update'.`,
+	Run: contextLoad,
 }
 
 func init() {
@@ -36,6 +52,16 @@ func init() {
 	contextLoadCmd.Flags().BoolVarP(&forceSkipIgnore, "force", "f", false, "Load files even when ignored by .gitignore or .plandexignore")
 	contextLoadCmd.Flags().StringVarP(&imageDetail, "detail", "d", "high", "Image detail level (high or low)")
 	contextLoadCmd.Flags().BoolVar(&defsOnly, "map", false, "Load file maps (function/method/class signatures, variable names, types, etc.)")
+	contextLoadCmd.Flags().StringVar(&gitDiff, "git-diff", "", "Load the diff between a git ref and the working tree (kept in sync on 'plandex update')")
+	contextLoadCmd.Flags().BoolVar(&gitStaged, "git-staged", false, "Load the diff of staged changes (kept in sync on 'plandex update')")
+	contextLoadCmd.Flags().StringVar(&gitCommits, "git-commits", "", "Load the commits in a git range like main..HEAD, with their patches (kept in sync on 'plandex update')")
+	contextLoadCmd.Flags().StringVar(&changedSince, "changed-since", "", "Load every file changed since a git ref, including new untracked files")
+	contextLoadCmd.Flags().StringArrayVar(&loadCmds, "cmd", nil, "Load the output of a shell command, which is re-run to keep it current on 'plandex update' (can be repeated)")
+	contextLoadCmd.Flags().IntVar(&withDeps, "with-deps", 0, "Also load files that import or are imported by the loaded files, up to N hops away")
+	contextLoadCmd.Flags().BoolVar(&crawl, "crawl", false, "Follow links from the given URLs and load each page, within the same site")
+	contextLoadCmd.Flags().IntVar(&crawlDepth, "depth", 2, "With --crawl, how many links away from each URL to follow")
+	contextLoadCmd.Flags().BoolVar(&samePrefix, "same-prefix", false, "With --crawl, only follow links under each URL's path")
+	contextLoadCmd.Flags().BoolVar(&gitFiles, "git-files", false, "Also load the files touched by --git-diff, --git-staged, or --git-commits")
 	RootCmd.AddCommand(contextLoadCmd)
 }
 
@@ -56,6 +82,16 @@ func contextLoad(cmd *cobra.Command, args []string) {
 		ImageDetail:     openai.ImageURLDetail(imageDetail),
 		DefsOnly:        defsOnly,
 		SessionId:       os.Getenv("PLANDEX_REPL_SESSION_ID"),
+		GitDiff:         gitDiff,
+		GitStaged:       gitStaged,
+		GitCommits:      gitCommits,
+		ChangedSince:    changedSince,
+		GitFiles:        gitFiles,
+		Cmds:            loadCmds,
+		WithDeps:        withDeps,
+		Crawl:           crawl,
+		CrawlDepth:      crawlDepth,
+		SamePrefix:      samePrefix,
 	})
 
 	fmt.Println()
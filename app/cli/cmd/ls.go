@@ -9,6 +9,7 @@ import (
 	"plandex-cli/lib"
 	"plandex-cli/term"
 	"strconv"
+	"strings"
 
 	shared "plandex-shared"
 
@@ -17,6 +18,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var lsAll bool
+
 var contextCmd = &cobra.Command{
 	Use:     "ls",
 	Aliases: []string{"list-context"},
@@ -55,6 +58,8 @@ func listContext(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	numOutOfScope := 0
+
 	for i, context := range contexts {
 		totalTokens += context.NumTokens
 
@@ -64,6 +69,12 @@ func listContext(cmd *cobra.Command, args []string) {
 			totalPlannerTokens += context.NumTokens
 		}
 
+		// rows keep their numbers when others are hidden so they still match 'plandex rm'
+		if !lsAll && !lib.ContextInScope(context, planConfig.Scope) {
+			numOutOfScope++
+			continue
+		}
+
 		t, icon := context.TypeAndIcon()
 
 		name := context.Name
@@ -88,6 +99,10 @@ func listContext(cmd *cobra.Command, args []string) {
 		})
 	}
 
+	if len(planConfig.Scope) > 0 {
+		fmt.Println(color.New(term.ColorHiCyan, color.Bold).Sprint("🎯 Scope → ") + strings.Join(planConfig.Scope, ", "))
+	}
+
 	table.Render()
 
 	tokensTbl := tablewriter.NewWriter(os.Stdout)
@@ -104,6 +119,15 @@ func listContext(cmd *cobra.Command, args []string) {
 	}
 	tokensTbl.Render()
 
+	if numOutOfScope > 0 {
+		fmt.Println()
+		label := "item"
+		if numOutOfScope > 1 {
+			label = "items"
+		}
+		fmt.Printf("ℹ️  %d context %s outside the plan's scope not shown. Use --all to include everything.\n", numOutOfScope, label)
+	}
+
 	fmt.Println()
 	term.PrintCmds("", "load", "rm", "clear")
 
@@ -112,4 +136,6 @@ func listContext(cmd *cobra.Command, args []string) {
 func init() {
 	RootCmd.AddCommand(contextCmd)
 
+	contextCmd.Flags().BoolVar(&lsAll, "all", false, "Include context outside the plan's scope")
+
 }
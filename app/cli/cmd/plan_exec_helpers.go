@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"plandex-cli/lib"
+	streamjson "plandex-cli/stream_json"
 	"plandex-cli/term"
 	"strconv"
 
@@ -31,6 +32,7 @@ var tellAutoApply bool
 var tellAutoContext bool
 var tellSmartContext bool
 var tellSkipMenu bool
+var tellJSON bool
 var noExec bool
 var autoDebug int
 
@@ -59,6 +61,7 @@ type initExecFlagsParams struct {
 	omitAutoContext  bool
 	omitSmartContext bool
 	omitSkipMenu     bool
+	omitJSON         bool
 }
 
 func initExecFlags(cmd *cobra.Command, params initExecFlagsParams) {
@@ -105,6 +108,10 @@ func initExecFlags(cmd *cobra.Command, params initExecFlagsParams) {
 	if !params.omitSkipMenu {
 		cmd.Flags().BoolVar(&tellSkipMenu, "skip-menu", false, shared.ConfigSettingsByKey["skip-changes-menu"].Desc)
 	}
+
+	if !params.omitJSON {
+		cmd.Flags().BoolVar(&tellJSON, "json", false, "Output stream messages as newline-delimited JSON and read missing file responses from stdin")
+	}
 }
 
 func initApplyFlags(cmd *cobra.Command, applyFlag bool) {
@@ -137,6 +144,15 @@ func validatePlanExecFlags(isApply bool) {
 		term.OutputErrorAndExit("--auto-context/-c can't be used with --bg")
 	}
 
+	if tellJSON {
+		if tellBg {
+			term.OutputErrorAndExit("--json can't be used with --bg")
+		}
+		if tellAutoApply {
+			term.OutputErrorAndExit("--json can't be used with --apply")
+		}
+	}
+
 	if !isApply {
 		if autoDebug > 0 && !tellAutoApply {
 			term.OutputErrorAndExit("--debug can only be used with --apply")
@@ -207,6 +223,22 @@ func mustSetPlanExecFlags(cmd *cobra.Command, isApply bool) {
 		tellSkipMenu = config.SkipChangesMenu
 	}
 
+	if tellJSON {
+		streamjson.Enable()
+
+		// there's no one to answer prompts or review changes in JSON mode, so config defaults that need them are ignored
+		if !cmd.Flags().Changed("auto-update-context") {
+			autoConfirm = true
+		}
+		if !cmd.Flags().Changed("apply") {
+			tellAutoApply = false
+		}
+		if !cmd.Flags().Changed("debug") {
+			autoDebug = 0
+		}
+		tellSkipMenu = true
+	}
+
 	// tell command editor is no longer tied to config *unless* it's set to vim or nano
 	// otherwise, the flag or EDITOR env var are used
 	// config.Editor is now used for mainly for JSON editing (and perhaps other purposes)
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/plan_exec"
+	reviewtui "plandex-cli/review_tui"
+	"plandex-cli/term"
+	"plandex-cli/types"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Review pending changes one hunk at a time",
+	Long: `Walk through each pending change to accept, reject, edit, or comment on it.
+
+Rejected and edited changes are saved when you quit with (q). With (f), they're saved and your comments are sent to the model as feedback.`,
+	Run:  review,
+	Args: cobra.NoArgs,
+}
+
+func init() {
+	RootCmd.AddCommand(reviewCmd)
+
+	initExecFlags(reviewCmd, initExecFlagsParams{
+		omitFile:     true,
+		omitEditor:   true,
+		omitBg:       true,
+		omitApply:    true,
+		omitSkipMenu: true,
+		omitJSON:     true,
+	})
+}
+
+func review(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+	mustSetPlanExecFlags(cmd, false)
+
+	term.StartSpinner("")
+	planState, apiErr := api.Client.GetCurrentPlanState(lib.CurrentPlanId, lib.CurrentBranch)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting current plan state: %v", apiErr.Msg)
+	}
+
+	hunks := reviewtui.GetHunks(planState)
+	if len(hunks) == 0 {
+		fmt.Println("🤷‍♂️ No pending changes")
+		return
+	}
+
+	res, err := reviewtui.StartReviewUI(hunks, lib.EditorWaitCommand(lib.MustGetCurrentPlanConfig()))
+	if err != nil {
+		term.OutputErrorAndExit("Error reviewing changes: %v", err)
+	}
+
+	if res.Aborted {
+		fmt.Println("🛑 Quit without saving")
+		return
+	}
+
+	if conflicted := reviewtui.KeptInRejectedFiles(res.Hunks); len(conflicted) > 0 {
+		color.New(term.ColorHiYellow, color.Bold).Println("⚠️  Rejecting a whole-file change rejects every pending change to the file, including ones you kept or edited:")
+		for _, path := range conflicted {
+			fmt.Printf(" • %s\n", path)
+		}
+		fmt.Println()
+
+		reject, err := term.ConfirmYesNo("Reject these files anyway?")
+		if err != nil {
+			term.OutputErrorAndExit("Error getting confirmation: %v", err)
+		}
+		fmt.Println()
+
+		if !reject {
+			reviewtui.KeepWholeFileChanges(res.Hunks, conflicted)
+		}
+	}
+
+	req, rejectedPaths := reviewtui.ReviewRequest(res.Hunks)
+
+	if len(req.RejectedIds) > 0 || len(req.EditedById) > 0 {
+		term.StartSpinner("")
+		apiErr = api.Client.ReviewReplacements(lib.CurrentPlanId, lib.CurrentBranch, req)
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error saving review: %v", apiErr.Msg)
+		}
+	}
+
+	if len(rejectedPaths) > 0 {
+		term.StartSpinner("")
+		apiErr = api.Client.RejectFiles(lib.CurrentPlanId, lib.CurrentBranch, rejectedPaths)
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error rejecting files: %v", apiErr.Msg)
+		}
+	}
+
+	numRejected := len(req.RejectedIds) + len(rejectedPaths)
+	if numRejected > 0 {
+		suffix := ""
+		if numRejected > 1 {
+			suffix = "s"
+		}
+		fmt.Printf("🚫 Rejected %d change%s\n", numRejected, suffix)
+	}
+	if len(req.EditedById) > 0 {
+		suffix := ""
+		if len(req.EditedById) > 1 {
+			suffix = "s"
+		}
+		fmt.Printf("✏️  Edited %d change%s\n", len(req.EditedById), suffix)
+	}
+	if numRejected == 0 && len(req.EditedById) == 0 {
+		fmt.Println("👍 No changes rejected or edited")
+	}
+	fmt.Println()
+
+	if !res.SendFeedback {
+		term.PrintCmds("", "diff", "apply", "review")
+		return
+	}
+
+	tellFlags := types.TellFlags{
+		TellStop:     tellStop,
+		TellNoBuild:  tellNoBuild,
+		AutoContext:  tellAutoContext,
+		SmartContext: tellSmartContext,
+		ExecEnabled:  !noExec,
+	}
+
+	plan_exec.TellPlan(plan_exec.ExecParams{
+		CurrentPlanId: lib.CurrentPlanId,
+		CurrentBranch: lib.CurrentBranch,
+		AuthVars:      lib.MustVerifyAuthVars(auth.Current.IntegratedModelsMode),
+		CheckOutdatedContext: func(maybeContexts []*shared.Context, projectPaths *types.ProjectPaths) (bool, bool, error) {
+			auto := autoConfirm || tellAutoContext
+			return lib.CheckOutdatedContextWithOutput(auto, auto, maybeContexts, projectPaths)
+		},
+	}, reviewtui.FeedbackPrompt(res.Hunks), tellFlags)
+}
@@ -0,0 +1,348 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var rolePermissions []string
+var roleDescription string
+var roleNewName string
+
+var rolesCmd = &cobra.Command{
+	Use:   "roles",
+	Short: "List org roles",
+	Run:   listOrgRoles,
+	Args:  cobra.NoArgs,
+}
+
+var listRolesCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List org roles",
+	Run:   listOrgRoles,
+	Args:  cobra.NoArgs,
+}
+
+var showRoleCmd = &cobra.Command{
+	Use:   "show [name]",
+	Short: "Show an org role's permissions",
+	Run:   showOrgRole,
+	Args:  cobra.MaximumNArgs(1),
+}
+
+var createRoleCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a custom org role",
+	Run:   createOrgRole,
+	Args:  cobra.MaximumNArgs(1),
+}
+
+var updateRoleCmd = &cobra.Command{
+	Use:   "update [name]",
+	Short: "Update a custom org role",
+	Run:   updateOrgRole,
+	Args:  cobra.MaximumNArgs(1),
+}
+
+var deleteRoleCmd = &cobra.Command{
+	Use:   "delete [name]",
+	Short: "Delete a custom org role",
+	Run:   deleteOrgRole,
+	Args:  cobra.MaximumNArgs(1),
+}
+
+var assignRoleCmd = &cobra.Command{
+	Use:   "assign [email] [role]",
+	Short: "Change a user's org role",
+	Run:   assignOrgRole,
+	Args:  cobra.MaximumNArgs(2),
+}
+
+var rolePermissionsCmd = &cobra.Command{
+	Use:   "permissions",
+	Short: "List the permissions that can be included in a custom role",
+	Run:   listRolePermissions,
+	Args:  cobra.NoArgs,
+}
+
+func init() {
+	RootCmd.AddCommand(rolesCmd)
+	rolesCmd.AddCommand(listRolesCmd)
+	rolesCmd.AddCommand(showRoleCmd)
+	rolesCmd.AddCommand(createRoleCmd)
+	rolesCmd.AddCommand(updateRoleCmd)
+	rolesCmd.AddCommand(deleteRoleCmd)
+	rolesCmd.AddCommand(assignRoleCmd)
+	rolesCmd.AddCommand(rolePermissionsCmd)
+
+	for _, c := range []*cobra.Command{createRoleCmd, updateRoleCmd} {
+		c.Flags().StringSliceVarP(&rolePermissions, "permission", "p", nil, "Permission to include in the role, like 'create_plan' (repeatable). Must be a permission you have.")
+		c.Flags().StringVarP(&roleDescription, "description", "d", "", "Description of the role")
+	}
+	updateRoleCmd.Flags().StringVar(&roleNewName, "name", "", "New name for the role")
+}
+
+func listOrgRoles(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	roles := mustListOrgRoles()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "Type", "Permissions", "Description"})
+
+	for _, role := range roles {
+		roleType := "Custom"
+		if role.IsDefault {
+			roleType = "Built-in"
+		}
+		table.Append([]string{role.Name, roleType, fmt.Sprintf("%d", len(role.Permissions)), role.Description})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "roles show", "roles create", "roles assign")
+}
+
+func showOrgRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	roles := mustListOrgRoles()
+	role := mustSelectOrgRole(roles, args, false)
+
+	color.New(color.Bold, term.ColorHiCyan).Println(role.Name)
+	if role.Description != "" {
+		fmt.Println(role.Description)
+	}
+	fmt.Println()
+
+	if len(role.Permissions) == 0 {
+		fmt.Println("🤷‍♂️ No permissions")
+		return
+	}
+
+	for _, permission := range role.Permissions {
+		fmt.Println("• " + string(permission))
+	}
+}
+
+func createOrgRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		var err error
+		name, err = term.GetRequiredUserStringInput("Role name:")
+		if err != nil {
+			term.OutputErrorAndExit("Error getting role name: %v", err)
+		}
+	}
+
+	req := shared.CreateOrgRoleRequest{
+		Name:        strings.ToLower(strings.TrimSpace(name)),
+		Description: roleDescription,
+		Permissions: parseRolePermissions(),
+	}
+
+	term.StartSpinner("")
+	role, apiErr := api.Client.CreateOrgRole(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error creating org role: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Created role %s with %d permission(s)\n", color.New(color.Bold, term.ColorHiCyan).Sprint(role.Name), len(role.Permissions))
+	fmt.Println()
+	term.PrintCmds("", "roles show", "roles assign", "invite")
+}
+
+func updateOrgRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	roles := mustListOrgRoles()
+	role := mustSelectOrgRole(roles, args, true)
+
+	req := shared.UpdateOrgRoleRequest{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.Permissions,
+	}
+
+	if cmd.Flags().Changed("name") {
+		req.Name = strings.ToLower(strings.TrimSpace(roleNewName))
+	}
+	if cmd.Flags().Changed("description") {
+		req.Description = roleDescription
+	}
+	if cmd.Flags().Changed("permission") {
+		req.Permissions = parseRolePermissions()
+	}
+
+	term.StartSpinner("")
+	updated, apiErr := api.Client.UpdateOrgRole(role.Id, req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating org role: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Updated role %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(updated.Name))
+}
+
+func deleteOrgRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	roles := mustListOrgRoles()
+	role := mustSelectOrgRole(roles, args, true)
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteOrgRole(role.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error deleting org role: %v", apiErr.Msg)
+	}
+
+	fmt.Println("✅ Deleted role " + color.New(color.Bold, term.ColorHiCyan).Sprint(role.Name))
+}
+
+func assignOrgRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var email string
+	if len(args) > 0 {
+		email = args[0]
+	} else {
+		var err error
+		email, err = term.GetRequiredUserStringInput("Email:")
+		if err != nil {
+			term.OutputErrorAndExit("Error getting email: %v", err)
+		}
+	}
+
+	term.StartSpinner("")
+	userResp, apiErr := api.Client.ListUsers()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching users: %v", apiErr.Msg)
+	}
+
+	var user *shared.User
+	for _, u := range userResp.Users {
+		if strings.EqualFold(u.Email, email) {
+			user = u
+			break
+		}
+	}
+
+	if user == nil {
+		term.OutputErrorAndExit("No user with email %s in the org", email)
+	}
+
+	var roleArgs []string
+	if len(args) > 1 {
+		roleArgs = args[1:]
+	}
+
+	roles := mustListOrgRoles()
+	role := mustSelectOrgRole(roles, roleArgs, false)
+
+	term.StartSpinner("")
+	apiErr = api.Client.SetOrgUserRole(user.Id, shared.SetOrgUserRoleRequest{OrgRoleId: role.Id})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error setting role: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ %s now has the %s role\n", color.New(color.Bold, term.ColorHiCyan).Sprint(user.Email), color.New(color.Bold).Sprint(role.Name))
+}
+
+func listRolePermissions(cmd *cobra.Command, args []string) {
+	for _, permission := range shared.AllPermissions {
+		fmt.Println("• " + string(permission))
+	}
+}
+
+func mustListOrgRoles() []*shared.OrgRole {
+	term.StartSpinner("")
+	roles, apiErr := api.Client.ListOrgRoles()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing org roles: %v", apiErr.Msg)
+	}
+
+	return roles
+}
+
+// mustSelectOrgRole finds a role by the name in args, or prompts for one if args is empty
+func mustSelectOrgRole(roles []*shared.OrgRole, args []string, customOnly bool) *shared.OrgRole {
+	var opts []*shared.OrgRole
+	for _, role := range roles {
+		if customOnly && role.IsDefault {
+			continue
+		}
+		opts = append(opts, role)
+	}
+
+	if len(opts) == 0 {
+		fmt.Println("🤷‍♂️ No custom roles")
+		fmt.Println()
+		term.PrintCmds("", "roles create")
+		os.Exit(0)
+	}
+
+	if len(args) > 0 {
+		for _, role := range opts {
+			if role.Name == args[0] || role.Id == args[0] {
+				return role
+			}
+		}
+		term.OutputErrorAndExit("Role '%s' not found", args[0])
+	}
+
+	var names []string
+	for _, role := range opts {
+		names = append(names, role.Name)
+	}
+
+	selected, err := term.SelectFromList("Select a role:", names)
+	if err != nil {
+		term.OutputErrorAndExit("Error selecting role: %v", err)
+	}
+
+	for _, role := range opts {
+		if role.Name == selected {
+			return role
+		}
+	}
+
+	return nil
+}
+
+func parseRolePermissions() []shared.Permission {
+	permissions := []shared.Permission{}
+	for _, permission := range rolePermissions {
+		p := shared.Permission(strings.TrimSpace(permission))
+		if !p.IsValid() {
+			term.OutputErrorAndExit("Invalid permission '%s'. Run 'plandex roles permissions' to see valid permissions", permission)
+		}
+		permissions = append(permissions, p)
+	}
+	return permissions
+}
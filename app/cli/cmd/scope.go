@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/fs"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var clearScope bool
+
+var scopeCmd = &cobra.Command{
+	Use:   "scope [package-or-dir...]",
+	Short: "Limit the plan to some packages of a monorepo",
+	Long: `Limit the current plan to one or more packages of a monorepo, so the auto-context project map and 'plandex ls' only cover those packages.
+
+Packages can be given by name or directory, as listed by 'plandex workspaces'. Any other directory in the project works too. With no arguments, shows the current scope.
+
+Files outside the scope can still be loaded explicitly with 'plandex load'.`,
+	Example: `  plandex scope @acme/web packages/ui
+  plandex scope services/billing
+  plandex scope --clear`,
+	Run: scope,
+}
+
+func init() {
+	RootCmd.AddCommand(scopeCmd)
+
+	scopeCmd.Flags().BoolVar(&clearScope, "clear", false, "Remove the plan's scope")
+}
+
+func scope(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	term.StartSpinner("")
+	config, apiErr := api.Client.GetPlanConfig(lib.CurrentPlanId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting current config: %v", apiErr)
+		return
+	}
+
+	if len(args) == 0 && !clearScope {
+		if len(config.Scope) == 0 {
+			fmt.Println("🤷‍♂️ No scope set -- the plan covers the whole project")
+		} else {
+			fmt.Println(color.New(term.ColorHiCyan, color.Bold).Sprint("🎯 Scope → ") + strings.Join(config.Scope, ", "))
+		}
+		fmt.Println()
+		term.PrintCmds("", "workspaces", "scope")
+		return
+	}
+
+	if clearScope && len(args) > 0 {
+		term.OutputErrorAndExit("Use either --clear or a list of packages, not both")
+	}
+
+	var updatedScope []string
+	if !clearScope {
+		packages, err := fs.GetWorkspacePackages(fs.ProjectRoot)
+		if err != nil {
+			term.OutputErrorAndExit("Error finding workspace packages: %v", err)
+		}
+
+		// packages can also be given as a single comma-separated argument
+		var names []string
+		for _, arg := range args {
+			names = append(names, strings.Split(arg, ",")...)
+		}
+
+		updatedScope, err = lib.ResolveScope(names, packages)
+		if err != nil {
+			term.OutputErrorAndExit("%v", err)
+		}
+	}
+
+	if strings.Join(updatedScope, "\n") == strings.Join(config.Scope, "\n") {
+		fmt.Println("🤷‍♂️ Scope is unchanged")
+		return
+	}
+
+	updatedConfig := *config
+	updatedConfig.Scope = updatedScope
+
+	term.StartSpinner("")
+	apiErr = api.Client.UpdatePlanConfig(lib.CurrentPlanId, shared.UpdatePlanConfigRequest{
+		Config: &updatedConfig,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating config: %v", apiErr)
+		return
+	}
+
+	lib.SetCachedPlanConfig(&updatedConfig)
+
+	if len(updatedScope) == 0 {
+		fmt.Println("✅ Scope removed -- the plan covers the whole project")
+	} else {
+		fmt.Println("✅ Scope set → " + strings.Join(updatedScope, ", "))
+	}
+	fmt.Println()
+
+	updateMapsForScope()
+
+	term.PrintCmds("", "ls", "workspaces")
+}
+
+// updateMapsForScope brings maps that the scope narrows in line with it, dropping files that are now out of scope and adding ones that are now in it
+func updateMapsForScope() {
+	term.StartSpinner("")
+	contexts, apiErr := api.Client.ListContext(lib.CurrentPlanId, lib.CurrentBranch)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing context: %v", apiErr)
+	}
+
+	var maps []*shared.Context
+	for _, c := range contexts {
+		if c.ContextType == shared.ContextMapType {
+			maps = append(maps, c)
+		}
+	}
+	if len(maps) == 0 {
+		return
+	}
+
+	projectPaths, err := fs.GetProjectPaths(fs.ProjectRoot)
+	if err != nil {
+		term.OutputErrorAndExit("Error getting project paths: %v", err)
+	}
+
+	_, _, err = lib.CheckOutdatedContextWithOutput(true, true, maps, projectPaths)
+	if err != nil {
+		term.OutputErrorAndExit("Error updating project map: %v", err)
+	}
+}
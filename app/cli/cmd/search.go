@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/fs"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var searchLimit int
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search project files with the local index used for auto-context",
+	Long: `Rank project files against a query, the same way prompts are matched to candidate files before auto-context loading.
+
+Files are scored on their contents, paths, and definition names. The index is kept in the project's .plandex-v2 directory and only changed files are re-read, so searching works offline and without a plan.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  search,
+}
+
+func init() {
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 20, "Maximum number of results")
+	RootCmd.AddCommand(searchCmd)
+}
+
+func search(cmd *cobra.Command, args []string) {
+	query := strings.Join(args, " ")
+
+	term.StartSpinner("")
+	baseDir := fs.ProjectRoot
+	if baseDir == "" {
+		// no plandex project here yet, so search the current directory without saving the index
+		baseDir = fs.Cwd
+	}
+
+	paths, err := fs.GetPaths(baseDir, baseDir)
+	if err != nil {
+		term.OutputErrorAndExit("Error getting project paths: %v", err)
+	}
+
+	results, err := lib.SearchProject(query, paths, searchLimit)
+	term.StopSpinner()
+	if err != nil {
+		term.OutputErrorAndExit("Error searching project: %v", err)
+	}
+
+	if len(results) == 0 {
+		fmt.Println("🤷‍♂️ No matching files")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"#", "File", "Score", "Matching Definitions"})
+	table.SetAutoWrapText(false)
+
+	for i, result := range results {
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			result.Path,
+			fmt.Sprintf("%.2f", result.Score),
+			strings.Join(result.Symbols, ", "),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "load")
+}
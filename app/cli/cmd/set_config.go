@@ -105,6 +105,7 @@ func setConfig(cmd *cobra.Command, args []string) {
 
 	if !(config.AutoApply && config.AutoExec) && updatedConfig.AutoApply && updatedConfig.AutoExec {
 		color.New(term.ColorHiYellow, color.Bold).Println("⚠️  You enabled automatic apply and execution.")
+		printExecSandboxTip(updatedConfig)
 
 		fmt.Println()
 	} else if !config.AutoApply && updatedConfig.AutoApply {
@@ -112,6 +113,7 @@ func setConfig(cmd *cobra.Command, args []string) {
 		fmt.Println()
 	} else if !config.AutoExec && updatedConfig.AutoExec {
 		color.New(term.ColorHiYellow, color.Bold).Println("⚠️  You enabled automatic execution.")
+		printExecSandboxTip(updatedConfig)
 		fmt.Println()
 	}
 
@@ -302,6 +304,14 @@ func updateConfig(args []string, originalConfig *shared.PlanConfig) (string, *sh
 			}
 			cfgSetting.IntSetter(&config, n)
 		} else if cfgSetting.StringSetter != nil {
+			// settings with a fixed set of choices are checked here rather than failing later when they're used
+			if cfgSetting.Choices != nil && len(*cfgSetting.Choices) > 0 && !cfgSetting.HasCustomChoice && cfgSetting.ChoiceToKey == nil {
+				normalized := strings.ToLower(strings.TrimSpace(value))
+				if normalized != "" && !slices.Contains(*cfgSetting.Choices, normalized) {
+					term.OutputErrorAndExit("Invalid value for %s (%s) -- must be one of: %s", cfgSetting.Name, value, strings.Join(*cfgSetting.Choices, ", "))
+					return "", nil
+				}
+			}
 			cfgSetting.StringSetter(&config, value)
 		} else if cfgSetting.EditorSetter != nil {
 			fields := strings.Fields(value)
@@ -317,6 +327,12 @@ func updateConfig(args []string, originalConfig *shared.PlanConfig) (string, *sh
 	return setting, &config
 }
 
+func printExecSandboxTip(config *shared.PlanConfig) {
+	if config.GetExecSandbox() == shared.ExecSandboxNone {
+		fmt.Println("Use 'plandex set-config exec-sandbox' to run commands in a sandbox without access to your credentials.")
+	}
+}
+
 func parseBooleanArg(value string) (bool, error) {
 	switch value {
 	case "enabled", "true", "t", "yes", "y", "1":
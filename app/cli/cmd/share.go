@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var shareRole string
+
+var shareCmd = &cobra.Command{
+	Use:   "share [email]",
+	Short: "Share the current plan with a member of your org",
+	Run:   share,
+	Args:  cobra.MaximumNArgs(1),
+}
+
+var unshareCmd = &cobra.Command{
+	Use:   "unshare [email]",
+	Short: "Stop sharing the current plan with a user",
+	Run:   unshare,
+	Args:  cobra.MaximumNArgs(1),
+}
+
+var sharesCmd = &cobra.Command{
+	Use:   "shares",
+	Short: "List who the current plan is shared with",
+	Run:   listShares,
+	Args:  cobra.NoArgs,
+}
+
+func init() {
+	RootCmd.AddCommand(shareCmd)
+	RootCmd.AddCommand(unshareCmd)
+	RootCmd.AddCommand(sharesCmd)
+
+	shareCmd.Flags().StringVarP(&shareRole, "role", "r", string(shared.PlanShareRoleCollaborator), "Access to grant: 'viewer' (read-only), 'collaborator' (can tell, build and apply), or 'owner' (can also rename, archive, delete and share)")
+}
+
+func share(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	role := shared.PlanShareRole(strings.ToLower(shareRole))
+	if !role.IsValid() {
+		term.OutputErrorAndExit("Invalid role '%s'. Use 'viewer', 'collaborator', or 'owner'", shareRole)
+	}
+
+	var email string
+	if len(args) > 0 {
+		email = args[0]
+	} else {
+		var err error
+		email, err = term.GetRequiredUserStringInput("Email:")
+		if err != nil {
+			term.OutputErrorAndExit("Error reading email: %v", err)
+		}
+	}
+
+	term.StartSpinner("")
+	planShare, apiErr := api.Client.SharePlan(lib.CurrentPlanId, shared.SharePlanRequest{
+		Email: email,
+		Role:  role,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error sharing plan: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Shared plan with %s as %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(planShare.UserEmail), color.New(color.Bold).Sprint(planShare.Role))
+	fmt.Println()
+	term.PrintCmds("", "shares", "unshare")
+}
+
+func unshare(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	term.StartSpinner("")
+	shares, apiErr := api.Client.ListPlanShares(lib.CurrentPlanId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing plan shares: %v", apiErr.Msg)
+	}
+
+	if len(shares) == 0 {
+		fmt.Println("🤷‍♂️ Plan isn't shared with anyone")
+		return
+	}
+
+	var toRemove *shared.PlanShare
+
+	if len(args) > 0 {
+		email := strings.ToLower(strings.TrimSpace(args[0]))
+		for _, planShare := range shares {
+			if strings.ToLower(planShare.UserEmail) == email {
+				toRemove = planShare
+				break
+			}
+		}
+
+		if toRemove == nil {
+			term.OutputErrorAndExit("Plan isn't shared with %s", args[0])
+		}
+	} else {
+		var opts []string
+		for _, planShare := range shares {
+			opts = append(opts, fmt.Sprintf("%s (%s)", planShare.UserEmail, planShare.Role))
+		}
+
+		selected, err := term.SelectFromList("Select a user to remove:", opts)
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting user: %v", err)
+		}
+
+		for i, opt := range opts {
+			if opt == selected {
+				toRemove = shares[i]
+				break
+			}
+		}
+	}
+
+	term.StartSpinner("")
+	apiErr = api.Client.UnsharePlan(lib.CurrentPlanId, toRemove.UserId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error unsharing plan: %v", apiErr.Msg)
+	}
+
+	fmt.Println("✅ Stopped sharing plan with " + color.New(color.Bold, term.ColorHiCyan).Sprint(toRemove.UserEmail))
+}
+
+func listShares(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	term.StartSpinner("")
+	shares, apiErr := api.Client.ListPlanShares(lib.CurrentPlanId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing plan shares: %v", apiErr.Msg)
+	}
+
+	if len(shares) == 0 {
+		fmt.Println("🤷‍♂️ Plan isn't shared with anyone")
+		fmt.Println()
+		term.PrintCmds("", "share")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Email", "Name", "Role", "Shared"})
+
+	for _, planShare := range shares {
+		table.Append([]string{planShare.UserEmail, planShare.UserName, string(planShare.Role), format.Time(planShare.CreatedAt)})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "share", "unshare")
+}
@@ -67,6 +67,7 @@ func doTell(cmd *cobra.Command, args []string) {
 		AutoApply:              tellAutoApply,
 		IsImplementationOfChat: isImplementationOfChat,
 		SkipChangesMenu:        tellSkipMenu,
+		JSON:                   tellJSON,
 	}
 
 	plan_exec.TellPlan(plan_exec.ExecParams{
@@ -113,6 +114,14 @@ func getTellPrompt(args []string) string {
 		prompt = string(bytes)
 	}
 
+	if tellJSON {
+		// stdin is reserved for missing file responses in JSON mode
+		if prompt == "" {
+			term.OutputErrorAndExit("A prompt argument or --file is required with --json")
+		}
+		return prompt
+	}
+
 	// Check if there's piped input
 	fileInfo, err := os.Stdin.Stat()
 	if err != nil {
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var tokenProjectScoped bool
+var tokenPermissions []string
+var tokenExpiresInDays int
+
+var tokensCmd = &cobra.Command{
+	Use:   "tokens",
+	Short: "List API tokens",
+	Run:   listApiTokens,
+	Args:  cobra.NoArgs,
+}
+
+var listTokensCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List API tokens",
+	Run:   listApiTokens,
+	Args:  cobra.NoArgs,
+}
+
+var createTokenCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create an API token for CI or other automated use",
+	Run:   createApiToken,
+	Args:  cobra.MaximumNArgs(1),
+}
+
+var revokeTokenCmd = &cobra.Command{
+	Use:   "revoke [id-or-name]",
+	Short: "Revoke an API token",
+	Run:   revokeApiToken,
+	Args:  cobra.MaximumNArgs(1),
+}
+
+func init() {
+	RootCmd.AddCommand(tokensCmd)
+	tokensCmd.AddCommand(listTokensCmd)
+	tokensCmd.AddCommand(createTokenCmd)
+	tokensCmd.AddCommand(revokeTokenCmd)
+
+	createTokenCmd.Flags().BoolVar(&tokenProjectScoped, "project", false, "Limit the token to the current project")
+	createTokenCmd.Flags().StringSliceVarP(&tokenPermissions, "permission", "p", nil, "Org permission to grant the token, like 'create_plan' (repeatable). Must be a permission you have.")
+	createTokenCmd.Flags().IntVar(&tokenExpiresInDays, "expires-in", 0, "Number of days until the token expires (default: never)")
+}
+
+func listApiTokens(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	tokens, apiErr := api.Client.ListApiTokens()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing API tokens: %v", apiErr.Msg)
+	}
+
+	if len(tokens) == 0 {
+		fmt.Println("🤷‍♂️ No API tokens")
+		fmt.Println()
+		term.PrintCmds("", "tokens create")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Id", "Name", "Token", "Project", "Permissions", "Expires", "Last Used"})
+
+	for _, token := range tokens {
+		project := "All"
+		if token.ProjectId != nil {
+			project = *token.ProjectId
+		}
+
+		var permissions []string
+		for _, permission := range token.Permissions {
+			permissions = append(permissions, string(permission))
+		}
+		permissionsStr := strings.Join(permissions, ", ")
+		if permissionsStr == "" {
+			permissionsStr = "None"
+		}
+
+		expires := "Never"
+		if token.ExpiresAt != nil {
+			expires = format.Time(*token.ExpiresAt)
+		}
+
+		lastUsed := "Never"
+		if token.LastUsedAt != nil {
+			lastUsed = format.Time(*token.LastUsedAt)
+		}
+
+		table.Append([]string{token.Id, token.Name, token.Prefix + "...", project, permissionsStr, expires, lastUsed})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "tokens create", "tokens revoke")
+}
+
+func createApiToken(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		var err error
+		name, err = term.GetRequiredUserStringInput("Token name:")
+		if err != nil {
+			term.OutputErrorAndExit("Error getting token name: %v", err)
+		}
+	}
+
+	req := shared.CreateApiTokenRequest{
+		Name: name,
+	}
+
+	if tokenProjectScoped {
+		lib.MustResolveProject()
+		req.ProjectId = lib.CurrentProjectId
+	}
+
+	for _, permission := range tokenPermissions {
+		req.Permissions = append(req.Permissions, shared.Permission(strings.TrimSpace(permission)))
+	}
+
+	if tokenExpiresInDays < 0 {
+		term.OutputErrorAndExit("--expires-in must be a positive number of days")
+	} else if tokenExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, tokenExpiresInDays)
+		req.ExpiresAt = &expiresAt
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.CreateApiToken(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error creating API token: %v", apiErr.Msg)
+	}
+
+	fmt.Println("✅ Created API token " + color.New(color.Bold, term.ColorHiCyan).Sprint(res.ApiToken.Name))
+	fmt.Println()
+	fmt.Println(res.Token)
+	fmt.Println()
+	fmt.Println("Copy it now—it won't be shown again.")
+	fmt.Printf("Set %s in your CI environment to use it", color.New(color.Bold).Sprint(auth.ApiTokenEnvVar))
+	if !auth.Current.IsCloud {
+		fmt.Printf(", along with %s=%s", color.New(color.Bold).Sprint(auth.ApiTokenHostEnvVar), auth.Current.Host)
+	}
+	fmt.Println(".")
+}
+
+func revokeApiToken(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	tokens, apiErr := api.Client.ListApiTokens()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing API tokens: %v", apiErr.Msg)
+	}
+
+	if len(tokens) == 0 {
+		fmt.Println("🤷‍♂️ No API tokens")
+		return
+	}
+
+	var toRevoke *shared.ApiToken
+
+	if len(args) > 0 {
+		for _, token := range tokens {
+			if token.Id == args[0] || token.Name == args[0] || token.Prefix == args[0] {
+				toRevoke = token
+				break
+			}
+		}
+
+		if toRevoke == nil {
+			term.OutputErrorAndExit("API token '%s' not found", args[0])
+		}
+	} else {
+		var opts []string
+		for _, token := range tokens {
+			opts = append(opts, fmt.Sprintf("%s (%s...)", token.Name, token.Prefix))
+		}
+
+		selected, err := term.SelectFromList("Select a token to revoke:", opts)
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting token: %v", err)
+		}
+
+		for i, opt := range opts {
+			if opt == selected {
+				toRevoke = tokens[i]
+				break
+			}
+		}
+	}
+
+	term.StartSpinner("")
+	apiErr = api.Client.RevokeApiToken(toRevoke.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error revoking API token: %v", apiErr.Msg)
+	}
+
+	fmt.Println("✅ Revoked API token " + color.New(color.Bold, term.ColorHiCyan).Sprint(toRevoke.Name))
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/fs"
+	"plandex-cli/term"
+	"plandex-cli/url"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var urlAuthCmd = &cobra.Command{
+	Use:   "url-auth",
+	Short: "Show the credentials configured for loading private URLs",
+	Long: `Show the hosts with credentials configured for loading private URLs, without their secret values.
+
+Credentials are configured in url-auth.json in the Plandex home directory. They're only used by the CLI when it fetches URLs and are never sent to the Plandex server. Each host can set headers (with values like '${WIKI_TOKEN}' read from env vars), a bearer token env var, a client certificate and key, and a CA certificate. Credentials from ~/.netrc are also used for hosts without an Authorization header unless "netrc" is set to false.`,
+	Run:  urlAuth,
+	Args: cobra.NoArgs,
+}
+
+func init() {
+	RootCmd.AddCommand(urlAuthCmd)
+}
+
+func urlAuth(cmd *cobra.Command, args []string) {
+	config, err := url.LoadUrlAuthConfig()
+	if err != nil {
+		term.OutputErrorAndExit("%v", err)
+	}
+
+	fmt.Printf("Config file: %s\n", fs.HomeUrlAuthPath)
+	fmt.Println()
+
+	if len(config.Hosts) == 0 {
+		fmt.Println("🤷‍♂️ No hosts configured")
+		fmt.Println()
+		fmt.Println("Example url-auth.json:")
+		fmt.Println(color.New(term.ColorHiCyan).Sprint(`{
+  "hosts": {
+    "wiki.example.com": { "headers": { "Cookie": "session=${WIKI_SESSION}" } },
+    "raw.githubusercontent.com": { "bearerTokenEnv": "GITHUB_TOKEN" },
+    "*.internal.example.com": { "clientCert": "~/certs/me.pem", "clientKey": "~/certs/me.key" }
+  }
+}`))
+		return
+	}
+
+	var patterns []string
+	for pattern := range config.Hosts {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Host", "Credentials"})
+	for _, pattern := range patterns {
+		table.Append([]string{pattern, config.Hosts[pattern].Describe()})
+	}
+	table.Render()
+}
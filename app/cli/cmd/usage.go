@@ -38,7 +38,7 @@ var creditsCurrentPlan bool
 
 var usageCmd = &cobra.Command{
 	Use:   "usage",
-	Short: "Display credits balance and usage report",
+	Short: "Display credits balance and usage report, or a usage report on self-hosted servers",
 	Run:   usage,
 }
 
@@ -57,6 +57,22 @@ func init() {
 }
 
 func usage(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	if !auth.Current.IsCloud {
+		if showUsageLog {
+			term.OutputErrorAndExit("The usage log is only available for Plandex Cloud accounts")
+		}
+		showUsageReport()
+		return
+	}
+
+	for _, flag := range []string{"by", "since", "until", "user", "csv"} {
+		if cmd.Flags().Changed(flag) {
+			term.OutputErrorAndExit("--%s is only available on self-hosted servers", flag)
+		}
+	}
+
 	if showUsageLog {
 		showLog(cmd, args)
 	} else {
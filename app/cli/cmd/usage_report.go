@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"strconv"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+)
+
+var usageBy string
+var usageSince string
+var usageUntil string
+var usageUser string
+var usageCsv bool
+
+var usageGroupLabels = map[shared.UsageGroupBy]string{
+	shared.UsageGroupByPlan:  "📋 Plan",
+	shared.UsageGroupByUser:  "👤 User",
+	shared.UsageGroupByRole:  "🎭 Role",
+	shared.UsageGroupByModel: "🤖 Model",
+	shared.UsageGroupByDay:   "📅 Day (UTC)",
+}
+
+func init() {
+	usageCmd.Flags().StringVar(&usageBy, "by", string(shared.UsageGroupByModel), "Group usage by 'plan', 'user', 'role', 'model', or 'day' (self-hosted only)")
+	usageCmd.Flags().StringVar(&usageSince, "since", "", "Show usage since a date (2025-07-01), time (2025-07-01T09:00:00Z), or duration ago (24h, 7d) (self-hosted only)")
+	usageCmd.Flags().StringVar(&usageUntil, "until", "", "Show usage before a date, time, or duration ago (self-hosted only)")
+	usageCmd.Flags().StringVarP(&usageUser, "user", "u", "", "Show usage for the user with this email (self-hosted only)")
+	usageCmd.Flags().BoolVar(&usageCsv, "csv", false, "Output usage as CSV (self-hosted only)")
+}
+
+// showUsageReport is 'plandex usage' for self-hosted servers, which record each model request instead of using credits
+func showUsageReport() {
+	by := shared.UsageGroupBy(strings.ToLower(usageBy))
+	if _, ok := usageGroupLabels[by]; !ok {
+		term.OutputErrorAndExit("--by must be 'plan', 'user', 'role', 'model', or 'day'")
+	}
+
+	params := types.GetUsageReportParams{
+		By:        by,
+		UserEmail: usageUser,
+	}
+
+	var err error
+	params.Since, err = parseTimeFlag(usageSince)
+	if err != nil {
+		term.OutputErrorAndExit("Invalid --since: %v", err)
+	}
+	params.Until, err = parseTimeFlag(usageUntil)
+	if err != nil {
+		term.OutputErrorAndExit("Invalid --until: %v", err)
+	}
+
+	// without --since, use the same defaults as Plandex Cloud: the current REPL session, or today so far
+	var periodLbl string
+	if params.Since == nil {
+		now := time.Now()
+		switch {
+		case creditsMonth:
+			monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+			params.Since = &monthStart
+			periodLbl = "This Month"
+		case creditsToday:
+			midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			params.Since = &midnight
+			periodLbl = "Today"
+		case creditsCurrentPlan:
+			allTime := time.Unix(0, 0)
+			params.Since = &allTime
+		case os.Getenv("PLANDEX_REPL_SESSION_ID") != "":
+			allTime := time.Unix(0, 0)
+			params.Since = &allTime
+			params.SessionId = os.Getenv("PLANDEX_REPL_SESSION_ID")
+			periodLbl = "This Session"
+		default:
+			midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			params.Since = &midnight
+			periodLbl = "Today"
+		}
+	} else {
+		periodLbl = "Since " + params.Since.Format("Jan 2 15:04")
+	}
+
+	if creditsCurrentPlan {
+		lib.MustResolveProject()
+		if lib.CurrentPlanId == "" {
+			term.OutputNoCurrentPlanErrorAndExit()
+		}
+		params.PlanId = lib.CurrentPlanId
+	} else if !usageCsv {
+		lib.MaybeResolveProject()
+	}
+
+	term.StartSpinner("")
+	report, apiErr := api.Client.GetUsageReport(params)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting usage report: %v", apiErr.Msg)
+	}
+
+	if usageCsv {
+		writeUsageCsv(report)
+		return
+	}
+
+	lbl := "💸 Usage"
+	if periodLbl != "" {
+		lbl += " " + periodLbl
+	}
+	if creditsCurrentPlan {
+		lbl += " On Current Plan"
+	}
+	if usageUser != "" {
+		lbl += " By " + usageUser
+	} else if !report.AllUsers {
+		lbl += " (your requests only)"
+	}
+	color.New(color.Bold, term.ColorHiCyan).Println(lbl)
+
+	if len(report.Rows) == 0 {
+		fmt.Println()
+		fmt.Println("🤷‍♂️ No model requests")
+	} else {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAutoWrapText(false)
+		table.SetHeader([]string{usageGroupLabels[report.By], "Requests", "Input", "Cached", "Output", "Cost", "Avg Latency"})
+
+		for _, row := range report.Rows {
+			table.Append(usageTableRow(usageRowLabel(report.By, row), row))
+		}
+		if len(report.Rows) > 1 {
+			table.SetFooter(usageTableRow("Total", report.Total))
+		}
+
+		table.Render()
+	}
+	fmt.Println()
+
+	showBudgetStatuses(lib.CurrentPlanId)
+
+	term.PrintCmds("", "usage --by plan", "usage --by day --since 30d", "usage --csv", "budgets")
+}
+
+func usageRowLabel(by shared.UsageGroupBy, row *shared.UsageReportRow) string {
+	if row.Label != "" {
+		return row.Label
+	}
+	if by == shared.UsageGroupByPlan && row.Key == "" {
+		return "(no plan)"
+	}
+	if row.Key == "" {
+		return "(none)"
+	}
+	return row.Key
+}
+
+func usageTableRow(label string, row *shared.UsageReportRow) []string {
+	latency := "-"
+	if row.AvgLatencyMs > 0 {
+		latency = (time.Duration(row.AvgLatencyMs) * time.Millisecond).Round(100 * time.Millisecond).String()
+	}
+
+	return []string{
+		label,
+		strconv.FormatInt(row.Requests, 10),
+		formatTokenAmount(row.InputTokens),
+		formatTokenAmount(row.CachedTokens),
+		formatTokenAmount(row.OutputTokens),
+		formatSpend(row.Cost),
+		latency,
+	}
+}
+
+func writeUsageCsv(report *shared.UsageReport) {
+	w := csv.NewWriter(os.Stdout)
+
+	w.Write([]string{string(report.By), "label", "requests", "input_tokens", "cached_tokens", "output_tokens", "cost", "errors", "avg_latency_ms", "avg_first_token_ms"})
+	for _, row := range report.Rows {
+		w.Write([]string{
+			row.Key,
+			row.Label,
+			strconv.FormatInt(row.Requests, 10),
+			strconv.FormatInt(row.InputTokens, 10),
+			strconv.FormatInt(row.CachedTokens, 10),
+			strconv.FormatInt(row.OutputTokens, 10),
+			row.Cost.String(),
+			strconv.FormatInt(row.Errors, 10),
+			strconv.FormatInt(row.AvgLatencyMs, 10),
+			strconv.FormatInt(row.AvgFirstTokenMs, 10),
+		})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		term.OutputErrorAndExit("Error writing CSV: %v", err)
+	}
+}
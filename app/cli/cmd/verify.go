@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Run the project's verification commands against pending changes",
+	Long: `Run the commands in .plandex-verify.json, like formatters, linters, and type checkers, against a scratch copy of the project with the plan's pending changes applied. The project itself isn't changed.
+
+The same commands run automatically after each build, before the changes menu. Like the apply script, they run in the plan's exec sandbox with its env allowlist and are checked against the exec policy. If execution is disabled for the plan, you're asked before they run. Exits with status 1 if any command fails.`,
+	Run:  verify,
+	Args: cobra.NoArgs,
+}
+
+func init() {
+	RootCmd.AddCommand(verifyCmd)
+}
+
+func verify(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	config, err := lib.LoadVerifyConfig()
+	if err != nil {
+		term.OutputErrorAndExit("Error loading %s: %v", lib.VerifyConfigFile, err)
+	}
+	if config == nil {
+		fmt.Printf("🤷‍♂️ No verification commands -- add them to %s in the project root\n", lib.VerifyConfigFile)
+		return
+	}
+
+	if !lib.MustGetCurrentPlanConfig().CanExec {
+		color.New(term.ColorHiYellow, color.Bold).Println("⚠️  Execution is disabled for this plan")
+		run, err := term.ConfirmYesNo("Run the verification commands anyway?")
+		if err != nil {
+			term.OutputErrorAndExit("Error getting confirmation: %v", err)
+		}
+		fmt.Println()
+		if !run {
+			return
+		}
+	}
+
+	term.StartSpinner("")
+	results, err := lib.VerifyPendingChanges(config, lib.CurrentPlanId, lib.CurrentBranch)
+	term.StopSpinner()
+
+	if err != nil {
+		term.OutputErrorAndExit("Error verifying pending changes: %v", err)
+	}
+
+	if results == nil {
+		fmt.Println("🤷‍♂️ No pending changes to verify")
+		return
+	}
+
+	lib.PrintVerifyResults(results)
+
+	if lib.VerifyFailed(results) {
+		term.PrintCmds("", "tell", "diff", "reject")
+		os.Exit(1)
+	}
+
+	term.PrintCmds("", "diff", "apply")
+}
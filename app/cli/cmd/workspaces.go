@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/fs"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var workspacesCmd = &cobra.Command{
+	Use:     "workspaces",
+	Aliases: []string{"ws"},
+	Short:   "List the packages of a monorepo",
+	Long: `List the packages found from the project's go.work, pnpm-workspace.yaml, package.json workspaces, Cargo.toml workspace, or Bazel BUILD files.
+
+Packages in the current plan's scope are marked. Use 'plandex scope' to limit the plan to one or more of them.`,
+	Run:  workspaces,
+	Args: cobra.NoArgs,
+}
+
+func init() {
+	RootCmd.AddCommand(workspacesCmd)
+}
+
+func workspaces(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	packages, err := fs.GetWorkspacePackages(fs.ProjectRoot)
+	if err != nil {
+		term.OutputErrorAndExit("Error finding workspace packages: %v", err)
+	}
+
+	if len(packages) == 0 {
+		fmt.Println("🤷‍♂️ No workspace packages found")
+		fmt.Println()
+		fmt.Println("Packages are found from go.work, pnpm-workspace.yaml, package.json workspaces, Cargo.toml [workspace] members, or BUILD files in a Bazel workspace.")
+		return
+	}
+
+	var scope []string
+	if lib.CurrentPlanId != "" {
+		term.StartSpinner("")
+		planConfig, apiErr := api.Client.GetPlanConfig(lib.CurrentPlanId)
+		term.StopSpinner()
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error getting plan config: %v", apiErr)
+		}
+		scope = planConfig.Scope
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "Dir", "Kind", "In Scope"})
+
+	for _, pkg := range packages {
+		inScope := ""
+		if len(scope) > 0 && lib.PathInScope(pkg.Dir, scope) {
+			inScope = "✅"
+		}
+		table.Append([]string{pkg.Name, filepath.ToSlash(pkg.Dir), pkg.Kind, inScope})
+	}
+
+	table.Render()
+	fmt.Println()
+
+	term.PrintCmds("", "scope")
+}
@@ -0,0 +1,105 @@
+package document
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxInputSize limits the size of a document file that will be read for extraction. Extracted text is much smaller than the file, so this is well above the context body size limit.
+const MaxInputSize = 100 * 1024 * 1024
+
+// MinTextDocumentSize is the size below which csv, tsv, and notebook files are loaded as regular files instead of being extracted -- they're already text, so small ones are more useful as-is, and they stay editable
+const MinTextDocumentSize = 100 * 1024
+
+type Format string
+
+const (
+	FormatPdf      Format = "pdf"
+	FormatDocx     Format = "docx"
+	FormatNotebook Format = "notebook"
+	FormatCsv      Format = "csv"
+	FormatParquet  Format = "parquet"
+)
+
+var formatsByExt = map[string]Format{
+	".pdf":     FormatPdf,
+	".docx":    FormatDocx,
+	".ipynb":   FormatNotebook,
+	".csv":     FormatCsv,
+	".tsv":     FormatCsv,
+	".parquet": FormatParquet,
+}
+
+// formats that are readable as text, so they're only extracted when they're large
+var textFormats = map[Format]bool{
+	FormatNotebook: true,
+	FormatCsv:      true,
+}
+
+// FormatForPath returns the document format for a path based on its extension, or "" if it isn't a document that needs extracting
+func FormatForPath(path string) Format {
+	return formatsByExt[strings.ToLower(filepath.Ext(path))]
+}
+
+// IsDocument checks whether a file should be loaded as a document. Notebooks and csv/tsv tables are only documents when they're at least MinTextDocumentSize.
+func IsDocument(path string) bool {
+	format := FormatForPath(path)
+	if format == "" {
+		return false
+	}
+	if textFormats[format] {
+		info, err := os.Stat(path)
+		return err == nil && info.Size() >= MinTextDocumentSize
+	}
+	return true
+}
+
+// Extract converts a document to text for context -- PDFs and DOCX files to their text, notebooks to their cells with truncated outputs, and tables to a summary of their columns with sample rows
+func Extract(path string) (res string, err error) {
+	// the parsers read untrusted files, so a bug in one fails the document rather than the whole command
+	defer func() {
+		if r := recover(); r != nil {
+			res = ""
+			err = fmt.Errorf("failed to extract %s: %v", path, r)
+		}
+	}()
+
+	format := FormatForPath(path)
+	if format == "" {
+		return "", fmt.Errorf("%s isn't a supported document format", path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file info for %s: %v", path, err)
+	}
+	if info.Size() > MaxInputSize {
+		return "", fmt.Errorf("%s is too large to extract (%d MB, max %d MB)", path, info.Size()/1024/1024, MaxInputSize/1024/1024)
+	}
+
+	var text string
+	switch format {
+	case FormatPdf:
+		text, err = extractPdf(path)
+	case FormatDocx:
+		text, err = extractDocx(path)
+	case FormatNotebook:
+		text, err = extractNotebook(path)
+	case FormatCsv:
+		text, err = summarizeCsv(path)
+	case FormatParquet:
+		text, err = summarizeParquet(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to extract %s: %v", path, err)
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("no text found in %s", path)
+	}
+
+	return text + "\n", nil
+}
@@ -0,0 +1,60 @@
+package document
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsDocument(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, size int) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(strings.Repeat("a", size)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		// binary formats are always extracted
+		{write("small.pdf", 10), true},
+		{write("small.docx", 10), true},
+		{write("small.parquet", 10), true},
+		// text formats stay regular files until they're large
+		{write("small.csv", 10), false},
+		{write("small.TSV", 10), false},
+		{write("small.ipynb", 10), false},
+		{write("large.csv", MinTextDocumentSize), true},
+		{write("large.ipynb", MinTextDocumentSize), true},
+		{write("main.go", MinTextDocumentSize), false},
+		{filepath.Join(dir, "missing.csv"), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsDocument(tt.path); got != tt.want {
+			t.Errorf("%s: got %v, want %v", filepath.Base(tt.path), got, tt.want)
+		}
+	}
+}
+
+func TestExtractErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Extract(filepath.Join(dir, "main.go")); err == nil {
+		t.Error("expected an error for a file that isn't a document")
+	}
+
+	path := filepath.Join(dir, "broken.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-1.4\n1 0 obj << /Type /Page >> endobj\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Extract(path); err == nil {
+		t.Error("expected an error for a PDF with no text")
+	}
+}
@@ -0,0 +1,219 @@
+package document
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// extractDocx converts a Word document's body to markdown-ish text, keeping headings, list items, and tables. Headers, footers, comments, and deleted tracked changes are left out.
+func extractDocx(path string) (string, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return "", fmt.Errorf("not a valid DOCX file: %v", err)
+	}
+	defer zr.Close()
+
+	var docFile *zip.File
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			docFile = f
+			break
+		}
+	}
+	if docFile == nil {
+		return "", fmt.Errorf("not a valid DOCX file: word/document.xml is missing")
+	}
+
+	r, err := docFile.Open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	return docxToText(io.LimitReader(r, maxInflatedSize))
+}
+
+type docxParagraph struct {
+	text      strings.Builder
+	style     string
+	listLevel int
+	isList    bool
+}
+
+type docxTable struct {
+	rows [][]string
+}
+
+func docxToText(r io.Reader) (string, error) {
+	dec := xml.NewDecoder(r)
+
+	var blocks []string
+	var para *docxParagraph
+	var tables []*docxTable
+	var cell *strings.Builder
+	inText := false
+	// consecutive list items are kept together as one list
+	lastWasList := false
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("error parsing document.xml: %v", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				para = &docxParagraph{}
+			case "pStyle":
+				if para != nil {
+					para.style = docxAttr(t, "val")
+				}
+			case "numPr":
+				if para != nil {
+					para.isList = true
+				}
+			case "ilvl":
+				if para != nil {
+					para.listLevel, _ = strconv.Atoi(docxAttr(t, "val"))
+				}
+			case "t":
+				inText = true
+			case "tab":
+				if para != nil {
+					para.text.WriteString("\t")
+				}
+			case "br", "cr":
+				if para != nil {
+					para.text.WriteString("\n")
+				}
+			case "tbl":
+				tables = append(tables, &docxTable{})
+			case "tr":
+				if len(tables) == 1 {
+					tables[0].rows = append(tables[0].rows, nil)
+				}
+			case "tc":
+				if len(tables) == 1 {
+					cell = &strings.Builder{}
+				}
+			}
+
+		case xml.CharData:
+			if inText && para != nil {
+				para.text.Write(t)
+			}
+
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if para == nil {
+					continue
+				}
+				text := strings.TrimSpace(para.text.String())
+				if len(tables) > 0 {
+					// nested tables are flattened into their outer table's cells
+					if cell != nil && text != "" {
+						if cell.Len() > 0 {
+							cell.WriteString(" ")
+						}
+						cell.WriteString(text)
+					}
+				} else if text != "" {
+					formatted, isList := para.format(text)
+					if isList && lastWasList {
+						blocks[len(blocks)-1] += "\n" + formatted
+					} else {
+						blocks = append(blocks, formatted)
+					}
+					lastWasList = isList
+				}
+				para = nil
+			case "tc":
+				if len(tables) == 1 && cell != nil {
+					rows := tables[0].rows
+					if len(rows) > 0 {
+						rows[len(rows)-1] = append(rows[len(rows)-1], cell.String())
+					}
+					cell = nil
+				}
+			case "tbl":
+				if len(tables) == 0 {
+					continue
+				}
+				if len(tables) == 1 {
+					if md := markdownTable(tables[0].rows); md != "" {
+						blocks = append(blocks, md)
+						lastWasList = false
+					}
+				}
+				tables = tables[:len(tables)-1]
+			}
+		}
+	}
+
+	return strings.Join(blocks, "\n\n"), nil
+}
+
+// format renders a paragraph by its style, and returns whether it's a list item
+func (p *docxParagraph) format(text string) (string, bool) {
+	style := strings.ToLower(strings.ReplaceAll(p.style, " ", ""))
+
+	if style == "title" {
+		return "# " + text, false
+	}
+	if level, ok := strings.CutPrefix(style, "heading"); ok {
+		if n, err := strconv.Atoi(level); err == nil && n >= 1 && n <= 6 {
+			return strings.Repeat("#", n) + " " + text, false
+		}
+	}
+	if p.isList || strings.HasPrefix(style, "listbullet") || strings.HasPrefix(style, "listnumber") {
+		return strings.Repeat("  ", p.listLevel) + "- " + text, true
+	}
+	return text, false
+}
+
+func docxAttr(el xml.StartElement, name string) string {
+	for _, attr := range el.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// markdownTable renders rows as a markdown table with the first row as the header, padding short rows
+func markdownTable(rows [][]string) string {
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	if cols == 0 {
+		return ""
+	}
+
+	var lines []string
+	for i, row := range rows {
+		cells := make([]string, cols)
+		for j := range cells {
+			if j < len(row) {
+				cells[j] = strings.ReplaceAll(strings.ReplaceAll(strings.TrimSpace(row[j]), "\n", " "), "|", `\|`)
+			}
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", cols))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,171 @@
+package document
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	maxNotebookOutputLines = 30
+	maxNotebookOutputChars = 3000
+)
+
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// notebookText is a notebook string field, which can be either a string or a list of lines
+type notebookText string
+
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = notebookText(s)
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return err
+	}
+	*t = notebookText(strings.Join(lines, ""))
+	return nil
+}
+
+type notebook struct {
+	Cells    []notebookCell `json:"cells"`
+	Metadata struct {
+		Kernelspec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+type notebookCell struct {
+	CellType       string           `json:"cell_type"`
+	Source         notebookText     `json:"source"`
+	ExecutionCount *int             `json:"execution_count"`
+	Outputs        []notebookOutput `json:"outputs"`
+}
+
+type notebookOutput struct {
+	OutputType string                     `json:"output_type"`
+	Text       notebookText               `json:"text"`
+	Data       map[string]json.RawMessage `json:"data"`
+	Ename      string                     `json:"ename"`
+	Evalue     string                     `json:"evalue"`
+	Traceback  []string                   `json:"traceback"`
+}
+
+// extractNotebook renders a Jupyter notebook's cells in order -- markdown as is, code in fenced blocks, and outputs truncated since they're often long logs or data dumps. Images and other rich outputs are only noted.
+func extractNotebook(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	var nb notebook
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return "", fmt.Errorf("not a valid notebook: %v", err)
+	}
+
+	lang := nb.Metadata.LanguageInfo.Name
+	if lang == "" {
+		lang = nb.Metadata.Kernelspec.Language
+	}
+
+	var blocks []string
+	for i, cell := range nb.Cells {
+		source := strings.TrimRight(string(cell.Source), "\n ")
+
+		switch cell.CellType {
+		case "markdown":
+			if strings.TrimSpace(source) != "" {
+				blocks = append(blocks, fmt.Sprintf("<!-- cell %d: markdown -->\n\n%s", i+1, source))
+			}
+
+		case "code":
+			label := fmt.Sprintf("cell %d: code", i+1)
+			if cell.ExecutionCount != nil {
+				label += fmt.Sprintf(" [%d]", *cell.ExecutionCount)
+			}
+			block := fmt.Sprintf("<!-- %s -->\n\n%s", label, fence(source, lang))
+
+			for _, output := range cell.Outputs {
+				if text := notebookOutputText(output); text != "" {
+					block += "\n\nOutput:\n\n" + fence(text, "")
+				}
+			}
+			blocks = append(blocks, block)
+
+		default:
+			if strings.TrimSpace(source) != "" {
+				blocks = append(blocks, fmt.Sprintf("<!-- cell %d: %s -->\n\n%s", i+1, cell.CellType, fence(source, "")))
+			}
+		}
+	}
+
+	return strings.Join(blocks, "\n\n"), nil
+}
+
+func notebookOutputText(output notebookOutput) string {
+	switch output.OutputType {
+	case "stream":
+		return truncateOutput(string(output.Text))
+
+	case "error":
+		text := output.Ename + ": " + output.Evalue
+		if len(output.Traceback) > 0 {
+			text = strings.Join(output.Traceback, "\n")
+		}
+		return truncateOutput(ansiRegex.ReplaceAllString(text, ""))
+
+	case "execute_result", "display_data":
+		if raw, ok := output.Data["text/plain"]; ok {
+			var text notebookText
+			if json.Unmarshal(raw, &text) == nil {
+				return truncateOutput(string(text))
+			}
+		}
+		var types []string
+		for mimeType := range output.Data {
+			types = append(types, mimeType)
+		}
+		sort.Strings(types)
+		if len(types) > 0 {
+			return "[" + strings.Join(types, ", ") + " output]"
+		}
+	}
+	return ""
+}
+
+// truncateOutput keeps the start and end of long outputs, which is where the useful parts of logs and tracebacks usually are
+func truncateOutput(text string) string {
+	text = strings.Trim(text, "\n")
+	lines := strings.Split(text, "\n")
+
+	if len(lines) > maxNotebookOutputLines {
+		head := maxNotebookOutputLines * 2 / 3
+		tail := maxNotebookOutputLines - head
+		omitted := len(lines) - head - tail
+		lines = append(append(lines[:head:head], fmt.Sprintf("... (%d lines omitted)", omitted)), lines[len(lines)-tail:]...)
+		text = strings.Join(lines, "\n")
+	}
+
+	if len(text) > maxNotebookOutputChars {
+		text = strings.ToValidUTF8(text[:maxNotebookOutputChars], "") + fmt.Sprintf("\n... (%d characters omitted)", len(text)-maxNotebookOutputChars)
+	}
+	return text
+}
+
+func fence(text, lang string) string {
+	f := "```"
+	for strings.Contains(text, f) {
+		f += "`"
+	}
+	return f + lang + "\n" + text + "\n" + f
+}
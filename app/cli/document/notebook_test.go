@@ -0,0 +1,65 @@
+package document
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractNotebook(t *testing.T) {
+	var longOutput []string
+	for i := 1; i <= 100; i++ {
+		longOutput = append(longOutput, `"line `+strings.Repeat("x", i%3)+`\n"`)
+	}
+
+	nb := `{
+  "metadata": {"language_info": {"name": "python"}},
+  "cells": [
+    {"cell_type": "markdown", "source": ["# Analysis\n", "Loads the data."]},
+    {"cell_type": "code", "execution_count": 3, "source": "df = load()\ndf.head()", "outputs": [
+      {"output_type": "execute_result", "data": {"text/plain": ["   a  b\n", "0  1  2"], "text/html": ["<table/>"]}},
+      {"output_type": "display_data", "data": {"image/png": "iVBOR"}}
+    ]},
+    {"cell_type": "code", "execution_count": null, "source": "train()", "outputs": [
+      {"output_type": "stream", "name": "stdout", "text": [` + strings.Join(longOutput, ",") + `]},
+      {"output_type": "error", "ename": "ValueError", "evalue": "bad", "traceback": ["\u001b[31mValueError\u001b[0m: bad"]}
+    ]},
+    {"cell_type": "markdown", "source": "  "}
+  ]
+}`
+
+	path := filepath.Join(t.TempDir(), "analysis.ipynb")
+	if err := os.WriteFile(path, []byte(nb), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := extractNotebook(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"<!-- cell 1: markdown -->\n\n# Analysis\nLoads the data.",
+		"<!-- cell 2: code [3] -->\n\n```python\ndf = load()\ndf.head()\n```",
+		"Output:\n\n```\n   a  b\n0  1  2\n```",
+		"[image/png output]",
+		"<!-- cell 3: code -->",
+		"... (70 lines omitted)",
+		"ValueError: bad",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "cell 4") || strings.Contains(got, "\x1b") {
+		t.Errorf("unexpected output:\n%s", got)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := extractNotebook(path); err == nil {
+		t.Error("expected an error for an invalid notebook")
+	}
+}
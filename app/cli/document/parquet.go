@@ -0,0 +1,348 @@
+package document
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var parquetMagic = []byte("PAR1")
+
+var parquetPhysicalTypes = map[int64]string{
+	0: "boolean", 1: "int32", 2: "int64", 3: "int96", 4: "float", 5: "double", 6: "binary", 7: "fixed_len_byte_array",
+}
+
+var parquetConvertedTypes = map[int64]string{
+	0: "string", 1: "map", 2: "map_key_value", 3: "list", 4: "enum", 5: "decimal", 6: "date", 7: "time_millis", 8: "time_micros",
+	9: "timestamp_millis", 10: "timestamp_micros", 11: "uint8", 12: "uint16", 13: "uint32", 14: "uint64",
+	15: "int8", 16: "int16", 17: "int32", 18: "int64", 19: "json", 20: "bson", 21: "interval",
+}
+
+// summarizeParquet describes a Parquet file's schema and row count from its footer. Rows aren't sampled, since that would mean decoding Parquet's compressed column pages.
+func summarizeParquet(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	size := info.Size()
+	if size < 12 {
+		return "", fmt.Errorf("not a valid Parquet file")
+	}
+
+	tail := make([]byte, 8)
+	if _, err := f.ReadAt(tail, size-8); err != nil {
+		return "", err
+	}
+	if !bytes.Equal(tail[4:], parquetMagic) {
+		return "", fmt.Errorf("not a valid Parquet file")
+	}
+	footerLen := int64(binary.LittleEndian.Uint32(tail[:4]))
+	if footerLen <= 0 || footerLen > size-12 {
+		return "", fmt.Errorf("not a valid Parquet file: bad footer length")
+	}
+
+	footer := make([]byte, footerLen)
+	if _, err := f.ReadAt(footer, size-8-footerLen); err != nil {
+		return "", err
+	}
+
+	d := &thriftDecoder{data: footer}
+	meta, err := d.readStruct(0)
+	if err != nil {
+		return "", fmt.Errorf("error reading Parquet metadata: %v", err)
+	}
+
+	// FileMetaData: 2 = schema, 3 = num_rows, 4 = row_groups, 6 = created_by
+	schema, _ := meta[2].([]any)
+	numRows, _ := meta[3].(int64)
+	rowGroups, _ := meta[4].([]any)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Parquet table with %d rows in %d row groups\n\n", numRows, len(rowGroups))
+
+	rows := [][]string{{"column", "type", "nullable"}}
+	// the schema is a flattened tree -- the root comes first, and each group is followed by its children
+	var walk func(i int, prefix string) int
+	walk = func(i int, prefix string) int {
+		if i >= len(schema) {
+			return i
+		}
+		el, _ := schema[i].(map[int16]any)
+		// SchemaElement: 1 = type, 3 = repetition_type, 4 = name, 5 = num_children, 6 = converted_type, 7 = scale, 8 = precision
+		name := string(asBytes(el[4]))
+		numChildren, _ := el[5].(int64)
+
+		fullName := name
+		if prefix != "" {
+			fullName = prefix + "." + name
+		}
+
+		next := i + 1
+		if numChildren > 0 {
+			childPrefix := fullName
+			if i == 0 {
+				childPrefix = ""
+			}
+			if i > 0 {
+				rows = append(rows, []string{fullName, parquetTypeName(el), parquetNullable(el)})
+			}
+			for c := int64(0); c < numChildren && next < len(schema); c++ {
+				next = walk(next, childPrefix)
+			}
+			return next
+		}
+
+		rows = append(rows, []string{fullName, parquetTypeName(el), parquetNullable(el)})
+		return next
+	}
+	walk(0, "")
+
+	b.WriteString(markdownTable(rows))
+	return b.String(), nil
+}
+
+func parquetTypeName(el map[int16]any) string {
+	var parts []string
+	if converted, ok := el[6].(int64); ok {
+		name := parquetConvertedTypes[converted]
+		if name == "decimal" {
+			precision, _ := el[8].(int64)
+			scale, _ := el[7].(int64)
+			name = fmt.Sprintf("decimal(%d,%d)", precision, scale)
+		}
+		if name != "" {
+			parts = append(parts, name)
+		}
+	}
+	if physical, ok := el[1].(int64); ok {
+		name := parquetPhysicalTypes[physical]
+		if len(parts) == 0 {
+			parts = append(parts, name)
+		} else if name != "" {
+			parts = append(parts, "("+name+")")
+		}
+	} else if len(parts) == 0 {
+		parts = append(parts, "group")
+	}
+	return strings.Join(parts, " ")
+}
+
+func parquetNullable(el map[int16]any) string {
+	repetition, _ := el[3].(int64)
+	switch repetition {
+	case 1:
+		return "yes"
+	case 2:
+		return "repeated"
+	}
+	return "no"
+}
+
+func asBytes(v any) []byte {
+	b, _ := v.([]byte)
+	return b
+}
+
+// thriftDecoder reads thrift's compact protocol generically -- structs are map[int16]any, lists []any, integers int64, and binary []byte
+type thriftDecoder struct {
+	data []byte
+	pos  int
+}
+
+const (
+	thriftStop      = 0
+	thriftTrue      = 1
+	thriftFalse     = 2
+	thriftByte      = 3
+	thriftI16       = 4
+	thriftI32       = 5
+	thriftI64       = 6
+	thriftDouble    = 7
+	thriftBinary    = 8
+	thriftList      = 9
+	thriftSet       = 10
+	thriftMap       = 11
+	thriftStruct    = 12
+	maxThriftDepth  = 64
+	maxThriftLength = 1 << 24
+)
+
+func (d *thriftDecoder) readByte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := d.data[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *thriftDecoder) readVarint() (uint64, error) {
+	v, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	d.pos += n
+	return v, nil
+}
+
+func (d *thriftDecoder) readZigzag() (int64, error) {
+	v, err := d.readVarint()
+	if err != nil {
+		return 0, err
+	}
+	return int64(v>>1) ^ -int64(v&1), nil
+}
+
+func (d *thriftDecoder) readStruct(depth int) (map[int16]any, error) {
+	if depth > maxThriftDepth {
+		return nil, fmt.Errorf("metadata is nested too deeply")
+	}
+	fields := map[int16]any{}
+	var lastId int16
+
+	for {
+		header, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		fieldType := header & 0x0f
+		if fieldType == thriftStop {
+			return fields, nil
+		}
+
+		var id int16
+		if delta := header >> 4; delta != 0 {
+			id = lastId + int16(delta)
+		} else {
+			v, err := d.readZigzag()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		lastId = id
+
+		var value any
+		switch fieldType {
+		case thriftTrue:
+			value = true
+		case thriftFalse:
+			value = false
+		default:
+			value, err = d.readValue(fieldType, depth)
+			if err != nil {
+				return nil, err
+			}
+		}
+		fields[id] = value
+	}
+}
+
+func (d *thriftDecoder) readValue(t byte, depth int) (any, error) {
+	switch t {
+	case thriftTrue, thriftFalse:
+		// booleans in lists are a byte each
+		b, err := d.readByte()
+		return b == thriftTrue, err
+	case thriftByte:
+		b, err := d.readByte()
+		return int64(int8(b)), err
+	case thriftI16, thriftI32, thriftI64:
+		return d.readZigzag()
+	case thriftDouble:
+		if d.pos+8 > len(d.data) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(d.data[d.pos:]))
+		d.pos += 8
+		return v, nil
+	case thriftBinary:
+		n, err := d.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		if n > maxThriftLength || d.pos+int(n) > len(d.data) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		v := d.data[d.pos : d.pos+int(n)]
+		d.pos += int(n)
+		return v, nil
+	case thriftList, thriftSet:
+		header, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		size := uint64(header >> 4)
+		if size == 15 {
+			size, err = d.readVarint()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if size > maxThriftLength {
+			return nil, fmt.Errorf("list is too long")
+		}
+		elemType := header & 0x0f
+		list := make([]any, 0, min(size, 1024))
+		for i := uint64(0); i < size; i++ {
+			v, err := d.readValue(elemType, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case thriftMap:
+		size, err := d.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return map[string]any{}, nil
+		}
+		if size > maxThriftLength {
+			return nil, fmt.Errorf("map is too large")
+		}
+		types, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		m := map[string]any{}
+		for i := uint64(0); i < size; i++ {
+			k, err := d.readValue(types>>4, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.readValue(types&0x0f, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			m[thriftKeyString(k)] = v
+		}
+		return m, nil
+	case thriftStruct:
+		return d.readStruct(depth + 1)
+	}
+	return nil, fmt.Errorf("unknown thrift type %d", t)
+}
+
+func thriftKeyString(k any) string {
+	switch k := k.(type) {
+	case []byte:
+		return string(k)
+	case int64:
+		return strconv.FormatInt(k, 10)
+	}
+	return fmt.Sprint(k)
+}
@@ -0,0 +1,149 @@
+package document
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// thriftWriter writes the subset of thrift's compact protocol the footer needs
+type thriftWriter struct {
+	b      bytes.Buffer
+	lastId []int16
+}
+
+func (w *thriftWriter) begin() { w.lastId = append(w.lastId, 0) }
+
+func (w *thriftWriter) end() {
+	w.b.WriteByte(thriftStop)
+	w.lastId = w.lastId[:len(w.lastId)-1]
+}
+
+func (w *thriftWriter) field(id int16, t byte) {
+	last := &w.lastId[len(w.lastId)-1]
+	w.b.WriteByte(byte(id-*last)<<4 | t)
+	*last = id
+}
+
+func (w *thriftWriter) zigzag(v int64) {
+	w.b.Write(binary.AppendUvarint(nil, uint64((v<<1)^(v>>63))))
+}
+
+func (w *thriftWriter) int(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.zigzag(v)
+}
+
+func (w *thriftWriter) str(id int16, s string) {
+	w.field(id, thriftBinary)
+	w.b.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	w.b.WriteString(s)
+}
+
+func (w *thriftWriter) structList(id int16, n int) {
+	w.field(id, thriftList)
+	w.b.WriteByte(byte(n)<<4 | thriftStruct)
+}
+
+func writeParquet(t *testing.T, footer []byte) string {
+	t.Helper()
+	var b bytes.Buffer
+	b.Write(parquetMagic)
+	b.Write(footer)
+	b.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	b.Write(parquetMagic)
+
+	path := filepath.Join(t.TempDir(), "data.parquet")
+	if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSummarizeParquet(t *testing.T) {
+	w := &thriftWriter{}
+	w.begin()
+	w.structList(2, 5)
+	// root with 3 children
+	w.begin()
+	w.str(4, "schema")
+	w.int(5, 3)
+	w.end()
+	// required int64
+	w.begin()
+	w.int(1, 2)
+	w.int(3, 0)
+	w.str(4, "id")
+	w.end()
+	// optional string
+	w.begin()
+	w.int(1, 6)
+	w.int(3, 1)
+	w.str(4, "name")
+	w.int(6, 0)
+	w.end()
+	// group with one decimal child
+	w.begin()
+	w.int(3, 1)
+	w.str(4, "price")
+	w.int(5, 1)
+	w.end()
+	w.begin()
+	w.int(1, 1)
+	w.int(3, 0)
+	w.str(4, "amount")
+	w.int(6, 5)
+	w.int(7, 2)
+	w.int(8, 10)
+	w.end()
+	w.int(3, 1234)
+	w.structList(4, 2)
+	w.begin()
+	w.end()
+	w.begin()
+	w.end()
+	w.end()
+
+	got, err := summarizeParquet(writeParquet(t, w.b.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"Parquet table with 1234 rows in 2 row groups",
+		"| id | int64 | no |",
+		"| name | string (binary) | yes |",
+		"| price | group | yes |",
+		"| price.amount | decimal(10,2) (int32) | no |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "| schema |") {
+		t.Errorf("the root shouldn't be listed as a column:\n%s", got)
+	}
+}
+
+func TestSummarizeParquetMalformed(t *testing.T) {
+	tests := map[string][]byte{
+		"truncated footer": {0x19, 0x5c},
+		// a binary field claiming far more bytes than the footer has
+		"long binary":  {0x48, 0xff, 0xff, 0xff, 0x0f},
+		"unknown type": {0x1d},
+	}
+	for name, footer := range tests {
+		if _, err := summarizeParquet(writeParquet(t, footer)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "bad.parquet")
+	os.WriteFile(path, []byte("PAR1 not parquet"), 0644)
+	if _, err := summarizeParquet(path); err == nil {
+		t.Error("expected an error for a file without the footer magic")
+	}
+}
@@ -0,0 +1,907 @@
+package document
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+const pdftotextTimeout = 2 * time.Minute
+
+// maxInflatedSize caps the total size of the streams inflated for one PDF, since a small compressed stream can expand to gigabytes
+const maxInflatedSize = 256 * 1024 * 1024
+
+// extractPdf uses poppler's pdftotext when it's installed, since it handles layout and font encodings far better, and otherwise falls back to a built-in extractor that covers text in typical generated PDFs
+func extractPdf(path string) (string, error) {
+	if _, err := exec.LookPath("pdftotext"); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), pdftotextTimeout)
+		defer cancel()
+
+		out, err := exec.CommandContext(ctx, "pdftotext", "-layout", "-enc", "UTF-8", path, "-").Output()
+		if err == nil {
+			return formatPdfPages(strings.Split(string(out), "\f")), nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	doc, err := parsePdf(data)
+	if err != nil {
+		return "", err
+	}
+
+	var pages []string
+	for _, page := range doc.pages() {
+		pages = append(pages, doc.pageText(page))
+	}
+
+	text := formatPdfPages(pages)
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("no text found -- it may be scanned images, which need OCR")
+	}
+	return text, nil
+}
+
+// formatPdfPages marks where each page starts so the model can refer to pages
+func formatPdfPages(pages []string) string {
+	var nonEmpty []string
+	for _, page := range pages {
+		if strings.TrimSpace(page) != "" {
+			nonEmpty = append(nonEmpty, page)
+		}
+	}
+	if len(nonEmpty) <= 1 {
+		return strings.Join(nonEmpty, "")
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, page := range pages {
+		n++
+		page = strings.Trim(page, "\n")
+		if strings.TrimSpace(page) == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "--- page %d ---\n\n%s\n\n", n, page)
+	}
+	return b.String()
+}
+
+// pdf values as parsed -- dicts are map[string]any, arrays []any, strings []byte, and numbers float64
+type pdfName string
+type pdfRef int
+
+type pdfObject struct {
+	value  any
+	stream []byte
+}
+
+type pdfDoc struct {
+	objects map[int]*pdfObject
+	fonts   map[any]*pdfFont
+	// bytes inflated so far, limited by maxInflatedSize
+	inflated int64
+}
+
+var pdfObjRegex = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+
+func parsePdf(data []byte) (*pdfDoc, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\r\n\t "), []byte("%PDF")) {
+		return nil, fmt.Errorf("not a PDF file")
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return nil, fmt.Errorf("encrypted PDFs aren't supported")
+	}
+
+	doc := &pdfDoc{objects: map[int]*pdfObject{}, fonts: map[any]*pdfFont{}}
+
+	// objects are found by scanning rather than through the xref table, which is often damaged -- later definitions win, as with incremental updates
+	for _, loc := range pdfObjRegex.FindAllSubmatchIndex(data, -1) {
+		num, err := strconv.Atoi(string(data[loc[2]:loc[3]]))
+		if err != nil {
+			continue
+		}
+
+		p := &pdfParser{data: data, pos: loc[1]}
+		obj := &pdfObject{value: p.value(0)}
+
+		p.skipSpace()
+		if bytes.HasPrefix(data[p.pos:], []byte("stream")) {
+			obj.stream = streamData(data, p.pos+len("stream"), obj.value)
+		}
+
+		doc.objects[num] = obj
+	}
+
+	// objects can also be packed into compressed object streams
+	var objStms []*pdfObject
+	for _, num := range doc.sortedObjectNums() {
+		obj := doc.objects[num]
+		if dict, ok := obj.value.(map[string]any); ok && dict["Type"] == pdfName("ObjStm") {
+			objStms = append(objStms, obj)
+		}
+	}
+
+	for _, obj := range objStms {
+		dict := obj.value.(map[string]any)
+		decoded, ok := doc.decodeStream(obj)
+		if !ok {
+			continue
+		}
+		n, _ := doc.resolve(dict["N"]).(float64)
+		first, _ := doc.resolve(dict["First"]).(float64)
+		if first < 0 || int(first) > len(decoded) {
+			continue
+		}
+
+		header := &pdfParser{data: decoded[:int(first)]}
+		for i := 0; i < int(n); i++ {
+			num, ok1 := header.value(0).(float64)
+			offset, ok2 := header.value(0).(float64)
+			if !ok1 || !ok2 {
+				break
+			}
+			start := int(first) + int(offset)
+			if offset < 0 || start >= len(decoded) {
+				continue
+			}
+			if _, exists := doc.objects[int(num)]; exists {
+				continue
+			}
+			p := &pdfParser{data: decoded, pos: start}
+			doc.objects[int(num)] = &pdfObject{value: p.value(0)}
+		}
+	}
+
+	return doc, nil
+}
+
+// streamData finds a stream's bytes, using its /Length when it's direct and falling back to searching for 'endstream'
+func streamData(data []byte, pos int, dictVal any) []byte {
+	if bytes.HasPrefix(data[pos:], []byte("\r\n")) {
+		pos += 2
+	} else if pos < len(data) && (data[pos] == '\n' || data[pos] == '\r') {
+		pos++
+	}
+
+	if dict, ok := dictVal.(map[string]any); ok {
+		if length, ok := dict["Length"].(float64); ok {
+			end := pos + int(length)
+			if length >= 0 && end <= len(data) && bytes.Contains(data[end:min(end+32, len(data))], []byte("endstream")) {
+				return data[pos:end]
+			}
+		}
+	}
+
+	end := bytes.Index(data[pos:], []byte("endstream"))
+	if end < 0 {
+		return data[pos:]
+	}
+	return bytes.TrimRight(data[pos:pos+end], "\r\n")
+}
+
+func (doc *pdfDoc) resolve(v any) any {
+	for i := 0; i < 8; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		obj := doc.objects[int(ref)]
+		if obj == nil {
+			return nil
+		}
+		v = obj.value
+	}
+	return v
+}
+
+func (doc *pdfDoc) dict(v any) map[string]any {
+	dict, _ := doc.resolve(v).(map[string]any)
+	return dict
+}
+
+// decodeStream inflates a stream -- only FlateDecode is supported, since the other filters are used for images rather than text
+func (doc *pdfDoc) decodeStream(obj *pdfObject) ([]byte, bool) {
+	if obj == nil || obj.stream == nil {
+		return nil, false
+	}
+	dict, _ := obj.value.(map[string]any)
+
+	var filters []any
+	switch f := doc.resolve(dict["Filter"]).(type) {
+	case pdfName:
+		filters = []any{f}
+	case []any:
+		filters = f
+	}
+
+	data := obj.stream
+	for _, filter := range filters {
+		if doc.resolve(filter) != pdfName("FlateDecode") {
+			return nil, false
+		}
+		data = inflate(data, maxInflatedSize-doc.inflated)
+		if data == nil {
+			return nil, false
+		}
+		doc.inflated += int64(len(data))
+	}
+	return data, true
+}
+
+// inflate keeps whatever could be read from a truncated or corrupt stream, up to limit bytes
+func inflate(data []byte, limit int64) []byte {
+	if limit <= 0 {
+		return nil
+	}
+
+	var r io.ReadCloser
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	defer r.Close()
+
+	out, _ := io.ReadAll(io.LimitReader(r, limit))
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+type pdfPage struct {
+	dict      map[string]any
+	resources map[string]any
+}
+
+// pages walks the page tree from the catalog so pages are in reading order, inheriting resources from parent nodes
+func (doc *pdfDoc) pages() []pdfPage {
+	var pages []pdfPage
+	seen := map[pdfRef]bool{}
+
+	var walk func(node any, resources map[string]any, depth int)
+	walk = func(node any, resources map[string]any, depth int) {
+		if ref, ok := node.(pdfRef); ok {
+			if seen[ref] {
+				return
+			}
+			seen[ref] = true
+		}
+		dict := doc.dict(node)
+		if dict == nil || depth > 32 {
+			return
+		}
+		if res := doc.dict(dict["Resources"]); res != nil {
+			resources = res
+		}
+		if dict["Type"] == pdfName("Page") {
+			pages = append(pages, pdfPage{dict: dict, resources: resources})
+			return
+		}
+		kids, _ := doc.resolve(dict["Kids"]).([]any)
+		for _, kid := range kids {
+			walk(kid, resources, depth+1)
+		}
+	}
+
+	for _, num := range doc.sortedObjectNums() {
+		dict, ok := doc.objects[num].value.(map[string]any)
+		if ok && dict["Type"] == pdfName("Catalog") {
+			walk(dict["Pages"], nil, 0)
+			if len(pages) > 0 {
+				return pages
+			}
+		}
+	}
+
+	// no usable catalog, so fall back to every page object in the order they're numbered
+	for _, num := range doc.sortedObjectNums() {
+		dict, ok := doc.objects[num].value.(map[string]any)
+		if ok && dict["Type"] == pdfName("Page") {
+			pages = append(pages, pdfPage{dict: dict, resources: doc.dict(dict["Resources"])})
+		}
+	}
+	return pages
+}
+
+func (doc *pdfDoc) sortedObjectNums() []int {
+	nums := make([]int, 0, len(doc.objects))
+	for num := range doc.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	return nums
+}
+
+func (doc *pdfDoc) pageText(page pdfPage) string {
+	var content []byte
+	switch contents := doc.resolve(page.dict["Contents"]).(type) {
+	case []any:
+		for _, c := range contents {
+			if ref, ok := c.(pdfRef); ok {
+				if data, ok := doc.decodeStream(doc.objects[int(ref)]); ok {
+					content = append(content, data...)
+					content = append(content, '\n')
+				}
+			}
+		}
+	case map[string]any:
+		if ref, ok := page.dict["Contents"].(pdfRef); ok {
+			content, _ = doc.decodeStream(doc.objects[int(ref)])
+		}
+	}
+
+	w := &pdfTextWriter{}
+	doc.runContent(content, page.resources, w, 0)
+	return strings.TrimSpace(w.b.String())
+}
+
+type pdfTextWriter struct {
+	b strings.Builder
+}
+
+func (w *pdfTextWriter) write(s string) {
+	w.b.WriteString(s)
+}
+
+func (w *pdfTextWriter) newline() {
+	s := w.b.String()
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		w.b.WriteString("\n")
+	}
+}
+
+func (w *pdfTextWriter) space() {
+	s := w.b.String()
+	if s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+		w.b.WriteString(" ")
+	}
+}
+
+// runContent interprets the text operators in a content stream, recursing into form xobjects
+func (doc *pdfDoc) runContent(content []byte, resources map[string]any, w *pdfTextWriter, depth int) {
+	if depth > 8 {
+		return
+	}
+
+	var font *pdfFont
+	var lastY float64
+	p := &pdfParser{data: content}
+	var operands []any
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return
+		}
+
+		v := p.value(0)
+		op, isOp := v.(pdfOp)
+		if !isOp {
+			operands = append(operands, v)
+			if len(operands) > 64 {
+				operands = operands[1:]
+			}
+			continue
+		}
+
+		num := func(i int) float64 {
+			if i < len(operands) {
+				f, _ := operands[i].(float64)
+				return f
+			}
+			return 0
+		}
+
+		switch op {
+		case "BT":
+			lastY = 0
+		case "Tf":
+			if len(operands) >= 1 {
+				if name, ok := operands[0].(pdfName); ok {
+					font = doc.font(doc.dict(resources["Font"])[string(name)])
+				}
+			}
+		case "Td", "TD":
+			if num(1) != 0 {
+				w.newline()
+			} else if num(0) != 0 {
+				w.space()
+			}
+		case "Tm":
+			y := num(5)
+			if y != lastY {
+				w.newline()
+			} else {
+				w.space()
+			}
+			lastY = y
+		case "T*":
+			w.newline()
+		case "Tj":
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].([]byte); ok {
+					w.write(font.decode(s))
+				}
+			}
+		case "'", "\"":
+			w.newline()
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].([]byte); ok {
+					w.write(font.decode(s))
+				}
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				if arr, ok := operands[len(operands)-1].([]any); ok {
+					for _, item := range arr {
+						switch item := item.(type) {
+						case []byte:
+							w.write(font.decode(item))
+						case float64:
+							// a large negative adjustment is a gap between words
+							if item < -200 {
+								w.space()
+							}
+						}
+					}
+				}
+			}
+		case "Do":
+			if len(operands) > 0 {
+				if name, ok := operands[0].(pdfName); ok {
+					ref, _ := doc.dict(resources["XObject"])[string(name)].(pdfRef)
+					obj := doc.objects[int(ref)]
+					if obj != nil {
+						dict, _ := obj.value.(map[string]any)
+						if dict["Subtype"] == pdfName("Form") {
+							if data, ok := doc.decodeStream(obj); ok {
+								formResources := doc.dict(dict["Resources"])
+								if formResources == nil {
+									formResources = resources
+								}
+								doc.runContent(data, formResources, w, depth+1)
+							}
+						}
+					}
+				}
+			}
+		case "BI":
+			// inline image data is binary, so skip to the end of it
+			if end := bytes.Index(p.data[p.pos:], []byte("EI")); end >= 0 {
+				p.pos += end + 2
+			} else {
+				p.pos = len(p.data)
+			}
+		}
+
+		operands = operands[:0]
+	}
+}
+
+type pdfFont struct {
+	cmap *pdfCMap
+	// composite fonts use multi-byte codes, which can't be decoded without a ToUnicode map
+	composite bool
+}
+
+func (doc *pdfDoc) font(ref any) *pdfFont {
+	if ref == nil {
+		return nil
+	}
+	// fonts are usually references, which can be cached -- inline font dicts aren't hashable
+	_, isRef := ref.(pdfRef)
+	if isRef {
+		if font, ok := doc.fonts[ref]; ok {
+			return font
+		}
+	}
+
+	font := &pdfFont{}
+	dict := doc.dict(ref)
+	if dict != nil {
+		font.composite = dict["Subtype"] == pdfName("Type0")
+		if toUnicode, ok := dict["ToUnicode"].(pdfRef); ok {
+			if data, ok := doc.decodeStream(doc.objects[int(toUnicode)]); ok {
+				font.cmap = parseCMap(data)
+			}
+		}
+	}
+
+	if isRef {
+		doc.fonts[ref] = font
+	}
+	return font
+}
+
+// cp1252 characters in the 0x80-0x9f range that differ from latin-1, which is what most simple fonts use
+var winAnsiRunes = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡', 0x89: '‰', 0x8b: '‹', 0x8c: 'Œ',
+	0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—', 0x99: '™', 0x9b: '›', 0x9c: 'œ',
+}
+
+func (font *pdfFont) decode(s []byte) string {
+	if font != nil && font.cmap != nil {
+		return font.cmap.decode(s)
+	}
+	if font != nil && font.composite {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, c := range s {
+		if r, ok := winAnsiRunes[c]; ok {
+			b.WriteRune(r)
+		} else if c >= 0x20 || c == '\t' {
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
+
+type pdfCMap struct {
+	codeLen int
+	runes   map[int]string
+}
+
+// parseCMap reads the bfchar and bfrange mappings from a ToUnicode cmap
+func parseCMap(data []byte) *pdfCMap {
+	cmap := &pdfCMap{codeLen: 0, runes: map[int]string{}}
+	p := &pdfParser{data: data}
+
+	var operands []any
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			break
+		}
+		v := p.value(0)
+		op, isOp := v.(pdfOp)
+		if !isOp {
+			operands = append(operands, v)
+			continue
+		}
+
+		switch op {
+		case "endcodespacerange":
+			if len(operands) > 0 && cmap.codeLen == 0 {
+				if lo, ok := operands[0].([]byte); ok {
+					cmap.codeLen = len(lo)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 {
+					cmap.runes[bytesToInt(src)] = utf16BEString(dst)
+					if cmap.codeLen == 0 {
+						cmap.codeLen = len(src)
+					}
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 {
+					continue
+				}
+				if cmap.codeLen == 0 {
+					cmap.codeLen = len(lo)
+				}
+				start, end := bytesToInt(lo), bytesToInt(hi)
+				if end-start > 0xffff {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case []byte:
+					base := []rune(utf16BEString(dst))
+					if len(base) == 0 {
+						continue
+					}
+					for code := start; code <= end; code++ {
+						r := append([]rune{}, base...)
+						r[len(r)-1] += rune(code - start)
+						cmap.runes[code] = string(r)
+					}
+				case []any:
+					for j, item := range dst {
+						if s, ok := item.([]byte); ok && start+j <= end {
+							cmap.runes[start+j] = utf16BEString(s)
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+
+	if cmap.codeLen == 0 {
+		cmap.codeLen = 1
+	}
+	return cmap
+}
+
+func (cmap *pdfCMap) decode(s []byte) string {
+	var b strings.Builder
+	for i := 0; i+cmap.codeLen <= len(s); i += cmap.codeLen {
+		b.WriteString(cmap.runes[bytesToInt(s[i:i+cmap.codeLen])])
+	}
+	return b.String()
+}
+
+func bytesToInt(b []byte) int {
+	n := 0
+	for _, c := range b {
+		n = n<<8 | int(c)
+	}
+	return n
+}
+
+func utf16BEString(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// pdfOp is a content stream operator or other bare keyword
+type pdfOp string
+
+type pdfParser struct {
+	data []byte
+	pos  int
+}
+
+func isPdfSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPdfDelim(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func (p *pdfParser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if isPdfSpace(c) {
+			p.pos++
+		} else if c == '%' {
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+		} else {
+			return
+		}
+	}
+}
+
+// value parses the next value, returning a pdfOp for keywords, and nil at the end of the data or for anything it can't parse
+func (p *pdfParser) value(depth int) any {
+	p.skipSpace()
+	if p.pos >= len(p.data) || depth > 64 {
+		return nil
+	}
+
+	c := p.data[p.pos]
+	switch {
+	case c == '/':
+		p.pos++
+		start := p.pos
+		for p.pos < len(p.data) && !isPdfSpace(p.data[p.pos]) && !isPdfDelim(p.data[p.pos]) {
+			p.pos++
+		}
+		return pdfName(unescapeName(string(p.data[start:p.pos])))
+
+	case c == '(':
+		return p.literalString()
+
+	case c == '<' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '<':
+		p.pos += 2
+		dict := map[string]any{}
+		for {
+			p.skipSpace()
+			if p.pos >= len(p.data) {
+				return dict
+			}
+			if bytes.HasPrefix(p.data[p.pos:], []byte(">>")) {
+				p.pos += 2
+				return dict
+			}
+			key, ok := p.value(depth + 1).(pdfName)
+			if !ok {
+				// skip whatever isn't a key so a malformed dict can't loop forever
+				p.pos++
+				continue
+			}
+			dict[string(key)] = p.value(depth + 1)
+		}
+
+	case c == '<':
+		p.pos++
+		start := p.pos
+		end := bytes.IndexByte(p.data[p.pos:], '>')
+		if end < 0 {
+			p.pos = len(p.data)
+			return []byte{}
+		}
+		p.pos += end + 1
+		return decodeHex(p.data[start : start+end])
+
+	case c == '[':
+		p.pos++
+		var arr []any
+		for {
+			p.skipSpace()
+			if p.pos >= len(p.data) {
+				return arr
+			}
+			if p.data[p.pos] == ']' {
+				p.pos++
+				return arr
+			}
+			before := p.pos
+			arr = append(arr, p.value(depth+1))
+			if p.pos == before {
+				p.pos++
+			}
+		}
+
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		p.pos++
+		return pdfOp(string(c))
+
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.data) && (p.data[p.pos] == '.' || (p.data[p.pos] >= '0' && p.data[p.pos] <= '9')) {
+			p.pos++
+		}
+		n, _ := strconv.ParseFloat(string(p.data[start:p.pos]), 64)
+
+		// 'num gen R' is a reference
+		save := p.pos
+		p.skipSpace()
+		genStart := p.pos
+		for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+			p.pos++
+		}
+		if p.pos > genStart {
+			p.skipSpace()
+			if p.pos < len(p.data) && p.data[p.pos] == 'R' && (p.pos+1 == len(p.data) || isPdfSpace(p.data[p.pos+1]) || isPdfDelim(p.data[p.pos+1])) {
+				p.pos++
+				return pdfRef(int(n))
+			}
+		}
+		p.pos = save
+		return n
+	}
+
+	start := p.pos
+	for p.pos < len(p.data) && !isPdfSpace(p.data[p.pos]) && !isPdfDelim(p.data[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		p.pos++
+	}
+	word := string(p.data[start:p.pos])
+	switch word {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	return pdfOp(word)
+}
+
+func (p *pdfParser) literalString() []byte {
+	p.pos++ // opening paren
+	var out []byte
+	depth := 1
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+			out = append(out, c)
+		case '\\':
+			if p.pos >= len(p.data) {
+				return out
+			}
+			e := p.data[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					n := int(e - '0')
+					for i := 0; i < 2 && p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+						n = n*8 + int(p.data[p.pos]-'0')
+						p.pos++
+					}
+					out = append(out, byte(n))
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func decodeHex(b []byte) []byte {
+	var digits []byte
+	for _, c := range b {
+		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		n, _ := strconv.ParseUint(string(digits[i*2:i*2+2]), 16, 8)
+		out[i] = byte(n)
+	}
+	return out
+}
+
+func unescapeName(name string) string {
+	if !strings.Contains(name, "#") {
+		return name
+	}
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '#' && i+2 < len(name) {
+			if n, err := strconv.ParseUint(name[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,158 @@
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func deflate(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+// buildPdf joins numbered objects into a PDF -- a []byte value is written as a stream with the dict before it
+func buildPdf(objects ...any) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n")
+	for i := 0; i < len(objects); i += 2 {
+		num := i/2 + 1
+		fmt.Fprintf(&b, "%d 0 obj\n%s\n", num, objects[i])
+		if stream, ok := objects[i+1].([]byte); ok {
+			fmt.Fprintf(&b, "stream\n%s\nendstream\n", stream)
+		}
+		b.WriteString("endobj\n")
+	}
+	b.WriteString("trailer << /Root 1 0 R >>\n%%EOF\n")
+	return b.Bytes()
+}
+
+func pdfText(t *testing.T, data []byte) string {
+	t.Helper()
+	doc, err := parsePdf(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pages []string
+	for _, page := range doc.pages() {
+		pages = append(pages, doc.pageText(page))
+	}
+	return formatPdfPages(pages)
+}
+
+func TestParsePdf(t *testing.T) {
+	content := []byte("BT /F1 12 Tf 72 700 Td (Hello) Tj 0 -14 Td (World) Tj ET")
+	compressed := deflate(t, []byte("BT /F1 12 Tf 72 700 Td (Second page) Tj ET"))
+
+	data := buildPdf(
+		"<< /Type /Catalog /Pages 2 0 R >>", nil,
+		"<< /Type /Pages /Kids [3 0 R 6 0 R] /Count 2 /Resources << /Font << /F1 4 0 R >> >> >>", nil,
+		"<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>", nil,
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>", nil,
+		fmt.Sprintf("<< /Length %d >>", len(content)), content,
+		"<< /Type /Page /Parent 2 0 R /Contents 7 0 R >>", nil,
+		fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>", len(compressed)), compressed,
+	)
+
+	got := pdfText(t, data)
+	want := "--- page 1 ---\n\nHello\nWorld\n\n--- page 2 ---\n\nSecond page\n\n"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestParsePdfObjectStream(t *testing.T) {
+	content := []byte("BT /F1 12 Tf (Packed) Tj ET")
+
+	// objects 2-4 are packed into object 6
+	packed := "<< /Type /Pages /Kids [3 0 R] /Count 1 >> << /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >> << /Type /Font /Subtype /Type1 >>"
+	first := strings.Index(packed, "<< /Type /Page ")
+	second := strings.Index(packed, "<< /Type /Font")
+	header := fmt.Sprintf("2 0 3 %d 4 %d ", first, second)
+	objStm := deflate(t, []byte(header+packed))
+
+	data := buildPdf(
+		"<< /Type /Catalog /Pages 2 0 R >>", nil,
+		"null", nil,
+		"null", nil,
+		"null", nil,
+		fmt.Sprintf("<< /Length %d >>", len(content)), content,
+		fmt.Sprintf("<< /Type /ObjStm /N 3 /First %d /Length %d /Filter /FlateDecode >>", len(header), len(objStm)), objStm,
+	)
+	// the null placeholders keep the numbering -- drop them so the packed objects are used
+	data = bytes.ReplaceAll(data, []byte("2 0 obj\nnull\nendobj\n3 0 obj\nnull\nendobj\n4 0 obj\nnull\nendobj\n"), nil)
+
+	if got := pdfText(t, data); got != "Packed" {
+		t.Fatalf("got %q, want %q", got, "Packed")
+	}
+}
+
+func TestParsePdfMalformed(t *testing.T) {
+	objStm := func(header string, first int) []byte {
+		body := deflate(t, []byte(header+"<< /Type /Page >>"))
+		return buildPdf(
+			fmt.Sprintf("<< /Type /ObjStm /N 2 /First %d /Length %d /Filter /FlateDecode >>", first, len(body)), body,
+		)
+	}
+
+	tests := map[string][]byte{
+		"negative first":        objStm("2 0 ", -5),
+		"first past the end":    objStm("2 0 ", 10000),
+		"negative offset":       objStm("2 -100 3 0 ", 10),
+		"offset past the end":   objStm("2 5000 ", 6),
+		"negative length":       buildPdf("<< /Length -10 >>", []byte("BT (x) Tj ET")),
+		"truncated stream":      []byte("%PDF-1.4\n1 0 obj << /Length 100 >> stream\nBT (x"),
+		"corrupt flate":         buildPdf("<< /Length 4 /Filter /FlateDecode >>", []byte("abcd")),
+		"unterminated dict":     []byte("%PDF-1.4\n1 0 obj << /Type /Catalog /Pages << /Kids [ 1 0 R"),
+		"self referencing kids": buildPdf("<< /Type /Catalog /Pages 2 0 R >>", nil, "<< /Type /Pages /Kids [2 0 R 1 0 R] >>", nil),
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			doc, err := parsePdf(data)
+			if err != nil {
+				return
+			}
+			for _, page := range doc.pages() {
+				doc.pageText(page)
+			}
+		})
+	}
+
+	if _, err := parsePdf([]byte("not a pdf")); err == nil {
+		t.Error("expected an error for a file that isn't a PDF")
+	}
+	if _, err := parsePdf(buildPdf("<< /Encrypt 2 0 R >>", nil)); err == nil {
+		t.Error("expected an error for an encrypted PDF")
+	}
+}
+
+func TestInflateLimit(t *testing.T) {
+	bomb := deflate(t, bytes.Repeat([]byte{0}, 10*1024*1024))
+
+	if out := inflate(bomb, 1024); len(out) != 1024 {
+		t.Fatalf("got %d bytes, want the 1024 byte limit", len(out))
+	}
+	if out := inflate(bomb, 0); out != nil {
+		t.Fatalf("expected nothing once the limit is used up, got %d bytes", len(out))
+	}
+
+	// the limit applies to the document as a whole, not each stream
+	doc := &pdfDoc{objects: map[int]*pdfObject{}, inflated: maxInflatedSize - 100}
+	obj := &pdfObject{value: map[string]any{"Filter": pdfName("FlateDecode")}, stream: bomb}
+	data, ok := doc.decodeStream(obj)
+	if !ok || len(data) != 100 {
+		t.Fatalf("got %d bytes, want the 100 remaining", len(data))
+	}
+	if _, ok := doc.decodeStream(obj); ok {
+		t.Fatal("expected decoding to stop once the document's limit is reached")
+	}
+}
@@ -0,0 +1,258 @@
+package document
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// tables with up to this many rows are included in full
+	maxFullTableRows = 50
+	numSampleRows    = 10
+	maxDistinctTrack = 20
+	maxSampleCellLen = 80
+)
+
+var tableDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"01/02/2006",
+	"1/2/2006",
+}
+
+type columnStats struct {
+	name     string
+	empty    int
+	isInt    bool
+	isFloat  bool
+	isBool   bool
+	isDate   bool
+	nonEmpty int
+	min, max float64
+	distinct map[string]int
+	// set once there are more distinct values than are tracked
+	manyDistinct bool
+}
+
+func newColumnStats(name string) *columnStats {
+	return &columnStats{name: name, isInt: true, isFloat: true, isBool: true, isDate: true, distinct: map[string]int{}}
+}
+
+func (c *columnStats) add(value string) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "null") || strings.EqualFold(value, "na") || strings.EqualFold(value, "nan") {
+		c.empty++
+		return
+	}
+	c.nonEmpty++
+
+	if c.isInt {
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			c.isInt = false
+		}
+	}
+	if c.isFloat {
+		if f, err := strconv.ParseFloat(value, 64); err != nil {
+			c.isFloat = false
+		} else {
+			if c.nonEmpty == 1 || f < c.min {
+				c.min = f
+			}
+			if c.nonEmpty == 1 || f > c.max {
+				c.max = f
+			}
+		}
+	}
+	if c.isBool {
+		switch strings.ToLower(value) {
+		case "true", "false", "yes", "no":
+		default:
+			c.isBool = false
+		}
+	}
+	if c.isDate {
+		parsed := false
+		for _, layout := range tableDateLayouts {
+			if _, err := time.Parse(layout, value); err == nil {
+				parsed = true
+				break
+			}
+		}
+		c.isDate = parsed
+	}
+
+	if !c.manyDistinct {
+		c.distinct[value]++
+		if len(c.distinct) > maxDistinctTrack {
+			c.manyDistinct = true
+		}
+	}
+}
+
+func (c *columnStats) typeDescription() string {
+	switch {
+	case c.nonEmpty == 0:
+		return "empty"
+	case c.isInt:
+		return fmt.Sprintf("integer (%s to %s)", strconv.FormatFloat(c.min, 'f', -1, 64), strconv.FormatFloat(c.max, 'f', -1, 64))
+	case c.isFloat:
+		return fmt.Sprintf("number (%s to %s)", strconv.FormatFloat(c.min, 'g', 6, 64), strconv.FormatFloat(c.max, 'g', 6, 64))
+	case c.isBool:
+		return "boolean"
+	case c.isDate:
+		return "date/time"
+	case !c.manyDistinct:
+		return fmt.Sprintf("text (%d distinct)", len(c.distinct))
+	}
+	return "text"
+}
+
+// values lists the distinct values of low-cardinality columns, most common first, since those are usually categories worth knowing
+func (c *columnStats) values() string {
+	if c.manyDistinct || len(c.distinct) == 0 || c.isInt || c.isFloat || c.isDate {
+		return ""
+	}
+	var values []string
+	for v := range c.distinct {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if c.distinct[values[i]] != c.distinct[values[j]] {
+			return c.distinct[values[i]] > c.distinct[values[j]]
+		}
+		return values[i] < values[j]
+	})
+	for i, v := range values {
+		values[i] = truncateCell(v)
+	}
+	return strings.Join(values, ", ")
+}
+
+// summarizeCsv describes a CSV or TSV file's columns with their inferred types and sample rows, rather than including every row. Small tables are included in full.
+func summarizeCsv(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+
+	delimiter := ','
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		delimiter = '\t'
+	} else {
+		firstLine, _ := br.Peek(64 * 1024)
+		delimiter = sniffDelimiter(string(firstLine))
+	}
+
+	r := csv.NewReader(br)
+	r.Comma = delimiter
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+
+	var header []string
+	var stats []*columnStats
+	var sample [][]string
+	numRows := 0
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("error parsing row %d: %v", numRows+2, err)
+		}
+
+		if header == nil {
+			header = record
+			for i, name := range header {
+				name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+				if name == "" {
+					name = fmt.Sprintf("column %d", i+1)
+				}
+				header[i] = name
+				stats = append(stats, newColumnStats(name))
+			}
+			continue
+		}
+
+		numRows++
+		for i, value := range record {
+			if i < len(stats) {
+				stats[i].add(value)
+			}
+		}
+		if len(sample) < maxFullTableRows+1 {
+			sample = append(sample, record)
+		}
+	}
+
+	if header == nil {
+		return "", nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Table with %d rows and %d columns\n\n", numRows, len(header))
+
+	rows := [][]string{{"column", "type", "empty", "values"}}
+	for _, c := range stats {
+		rows = append(rows, []string{c.name, c.typeDescription(), strconv.Itoa(c.empty), c.values()})
+	}
+	b.WriteString(markdownTable(rows))
+
+	if numRows > 0 {
+		sampleRows := sample
+		if numRows > maxFullTableRows {
+			sampleRows = sample[:numSampleRows]
+			fmt.Fprintf(&b, "\n\nFirst %d rows:\n\n", numSampleRows)
+		} else {
+			b.WriteString("\n\nAll rows:\n\n")
+		}
+
+		table := [][]string{header}
+		for _, row := range sampleRows {
+			cells := make([]string, len(row))
+			for i, v := range row {
+				cells[i] = truncateCell(v)
+			}
+			table = append(table, cells)
+		}
+		b.WriteString(markdownTable(table))
+	}
+
+	return b.String(), nil
+}
+
+// sniffDelimiter picks whichever common delimiter appears most in the header line
+func sniffDelimiter(data string) rune {
+	line, _, _ := strings.Cut(data, "\n")
+	best := ','
+	bestCount := 0
+	for _, d := range []rune{',', ';', '\t', '|'} {
+		if n := strings.Count(line, string(d)); n > bestCount {
+			best = d
+			bestCount = n
+		}
+	}
+	return best
+}
+
+func truncateCell(v string) string {
+	v = strings.TrimSpace(v)
+	if r := []rune(v); len(r) > maxSampleCellLen {
+		return string(r[:maxSampleCellLen]) + "…"
+	}
+	return v
+}
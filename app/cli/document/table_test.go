@@ -0,0 +1,70 @@
+package document
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSummarizeCsv(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("\ufeffid;status;amount;created;paid\n")
+	for i := 1; i <= 60; i++ {
+		status := "open"
+		if i%3 == 0 {
+			status = "closed"
+		}
+		amount := fmt.Sprintf("%d.5", i)
+		if i == 7 {
+			amount = ""
+		}
+		fmt.Fprintf(&b, "%d;%s;%s;2024-01-%02d;%v\n", i, status, amount, i%28+1, i%2 == 0)
+	}
+
+	path := filepath.Join(t.TempDir(), "orders.csv")
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := summarizeCsv(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"Table with 60 rows and 5 columns",
+		"| id | integer (1 to 60) | 0 |  |",
+		"| status | text (2 distinct) | 0 | open, closed |",
+		"| amount | number (1.5 to 60.5) | 1 |  |",
+		"| created | date/time | 0 |  |",
+		"| paid | boolean | 0 | false, true |",
+		"First 10 rows:",
+		"| 10 | open | 10.5 | 2024-01-11 | true |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "| 11 | ") {
+		t.Errorf("expected only sample rows:\n%s", got)
+	}
+}
+
+func TestSummarizeSmallTsv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "people.tsv")
+	if err := os.WriteFile(path, []byte("name\tteam\nada\tcore\nbob\t\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := summarizeCsv(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Table with 2 rows and 2 columns", "| team | text (1 distinct) | 1 | core |", "All rows:", "| bob |  |"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,1025 @@
+package execpolicy
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// scripts nested deeper than this in substitutions, 'sh -c', or eval aren't parsed, so they get a confirm verdict
+const maxNestingDepth = 8
+
+// Command is a simple command with its words after quote removal. Leading variable assignments and shell keywords aren't included.
+type Command struct {
+	Words []string
+
+	// set for 'sh -c', eval, and a shell that's piped a script by echo or printf, whose script is parsed into the statement's nested statements
+	Script bool
+	// set for a shell that reads a script from its input that isn't known until it runs, like 'curl ... | sh' or 'sh <<EOF'
+	StdinScript bool
+}
+
+// Redirect is a redirection that writes to a file, like '> out.log' or '>> ~/.bashrc'
+type Redirect struct {
+	Op     string
+	Target string
+}
+
+func (r *Redirect) String() string {
+	return r.Op + " " + r.Target
+}
+
+// Statement is a pipeline of commands, along with any commands it runs in substitutions, 'sh -c', or eval
+type Statement struct {
+	// the 1-based script line that the statement starts on
+	Line int
+	Text string
+
+	Pipeline []*Command
+	Nested   []*Statement
+	// files the statement's commands write to through redirections
+	Writes []*Redirect
+}
+
+func (c *Command) String() string {
+	return strings.Join(c.Words, " ")
+}
+
+// Parse splits a shell script into statements. It understands quoting, comments, line continuations, heredocs, redirections, command substitutions, and the control flow keywords, but it doesn't expand variables, globs, or aliases.
+func Parse(script string) ([]*Statement, error) {
+	return parse(script, 0)
+}
+
+func parse(script string, depth int) ([]*Statement, error) {
+	if depth > maxNestingDepth {
+		return nil, fmt.Errorf("commands are nested too deeply")
+	}
+
+	l := &lexer{src: script, line: 1}
+	err := l.run()
+	if err != nil {
+		return nil, err
+	}
+
+	b := &builder{src: script, depth: depth}
+	for i, tok := range l.tokens {
+		var next *token
+		if i+1 < len(l.tokens) {
+			next = &l.tokens[i+1]
+		}
+		err := b.add(tok, next)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = b.endStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	return b.statements, nil
+}
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	// ; & && || ;; newlines and parens, which end a statement
+	tokSep
+	// | and |&
+	tokPipe
+	// a redirection operator
+	tokRedirect
+	// a redirection target or heredoc body, which only matters for the substitutions it holds and the file it writes to
+	tokSubsOnly
+)
+
+type token struct {
+	kind       tokenKind
+	val        string
+	start, end int
+	line       int
+	// scripts of command substitutions in the token, which run as their own commands
+	subs []string
+	// for the target of a redirection that writes to a file, the operator
+	writeOp string
+}
+
+type pendingHeredoc struct {
+	delim     string
+	stripTabs bool
+	expand    bool
+}
+
+type lexer struct {
+	src    string
+	pos    int
+	line   int
+	tokens []token
+
+	heredocs []pendingHeredoc
+	// set after a redirection operator, so the next word is its target
+	redirectTarget bool
+	redirectOp     string
+	// set after '<<' or '<<-', so the next word is a heredoc delimiter
+	heredocTarget    bool
+	heredocStripTabs bool
+}
+
+func (l *lexer) run() error {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+
+		switch {
+		case c == '\\' && l.peek(1) == '\n':
+			l.pos += 2
+			l.line++
+
+		case c == ' ' || c == '\t' || c == '\r':
+			l.pos++
+
+		case c == '\n':
+			start := l.pos
+			l.pos++
+			l.line++
+			if len(l.heredocs) > 0 {
+				err := l.readHeredocBodies()
+				if err != nil {
+					return err
+				}
+			}
+			l.tokens = append(l.tokens, token{kind: tokSep, val: "\n", start: start, end: start + 1, line: l.line - 1})
+
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+
+		case c == '(' && l.peek(1) == '(':
+			// arithmetic, like '(( i++ ))' -- not a command
+			_, err := l.readBalanced(l.pos+2, '(', ')')
+			if err != nil {
+				return err
+			}
+			// skip the second closing paren
+			if l.peek(0) == ')' {
+				l.pos++
+			}
+
+		case (c == '<' || c == '>') && l.peek(1) == '(':
+			// process substitution
+			start := l.pos
+			inner, err := l.readBalanced(l.pos+2, '(', ')')
+			if err != nil {
+				return err
+			}
+			l.tokens = append(l.tokens, token{kind: tokSubsOnly, start: start, end: l.pos, line: l.line, subs: []string{inner}})
+
+		case strings.ContainsRune(";&|<>()", rune(c)):
+			err := l.readOperator()
+			if err != nil {
+				return err
+			}
+
+		default:
+			err := l.readWord()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if l.redirectTarget || l.heredocTarget {
+		return fmt.Errorf("line %d: redirection without a target", l.line)
+	}
+	if len(l.heredocs) > 0 {
+		return fmt.Errorf("heredoc '%s' is never closed", l.heredocs[0].delim)
+	}
+
+	return nil
+}
+
+func (l *lexer) peek(offset int) byte {
+	if l.pos+offset < len(l.src) {
+		return l.src[l.pos+offset]
+	}
+	return 0
+}
+
+var operators = []string{
+	"<<<", "<<-", "&>>",
+	";;", "&&", "||", "|&", "&>", "<<", "<>", "<&", ">>", ">&", ">|",
+	";", "&", "|", "(", ")", "<", ">",
+}
+
+func (l *lexer) readOperator() error {
+	for _, op := range operators {
+		if !strings.HasPrefix(l.src[l.pos:], op) {
+			continue
+		}
+
+		if l.redirectTarget || l.heredocTarget {
+			return fmt.Errorf("line %d: redirection without a target", l.line)
+		}
+
+		start := l.pos
+		l.pos += len(op)
+
+		switch op {
+		case "|", "|&":
+			l.tokens = append(l.tokens, token{kind: tokPipe, val: op, start: start, end: l.pos, line: l.line})
+		case ";", ";;", "&", "&&", "||", "(", ")":
+			l.tokens = append(l.tokens, token{kind: tokSep, val: op, start: start, end: l.pos, line: l.line})
+		case "<<", "<<-":
+			l.heredocTarget = true
+			l.heredocStripTabs = op == "<<-"
+			l.tokens = append(l.tokens, token{kind: tokRedirect, val: op, start: start, end: l.pos, line: l.line})
+		default:
+			l.redirectTarget = true
+			l.redirectOp = op
+			l.tokens = append(l.tokens, token{kind: tokRedirect, val: op, start: start, end: l.pos, line: l.line})
+		}
+		return nil
+	}
+	return fmt.Errorf("line %d: unexpected '%c'", l.line, l.src[l.pos])
+}
+
+// isWriteRedirect checks whether a redirection opens its target for writing. '>&' with a file descriptor, like '2>&1', only duplicates it.
+func isWriteRedirect(op, target string) bool {
+	switch op {
+	case ">", ">>", ">|", "&>", "&>>", "<>":
+		return true
+	case ">&":
+		return target != "-" && !isDigits(target)
+	}
+	return false
+}
+
+func isWordBreak(c byte) bool {
+	return strings.IndexByte(" \t\r\n;&|<>()", c) >= 0
+}
+
+func (l *lexer) readWord() error {
+	start := l.pos
+	startLine := l.line
+	var val strings.Builder
+	var subs []string
+	quoted := false
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+
+		if isWordBreak(c) {
+			// array assignments like 'files=(a b)'
+			if c == '(' && strings.HasSuffix(val.String(), "=") {
+				inner, err := l.readBalanced(l.pos+1, '(', ')')
+				if err != nil {
+					return err
+				}
+				val.WriteString("(" + inner + ")")
+				continue
+			}
+			break
+		}
+
+		switch c {
+		case '\\':
+			if l.peek(1) == '\n' {
+				l.pos += 2
+				l.line++
+				continue
+			}
+			if l.pos+1 == len(l.src) {
+				l.pos++
+				continue
+			}
+			val.WriteByte(l.src[l.pos+1])
+			l.pos += 2
+
+		case '\'':
+			quoted = true
+			end := strings.IndexByte(l.src[l.pos+1:], '\'')
+			if end == -1 {
+				return fmt.Errorf("line %d: unterminated single quote", startLine)
+			}
+			s := l.src[l.pos+1 : l.pos+1+end]
+			val.WriteString(s)
+			l.line += strings.Count(s, "\n")
+			l.pos += end + 2
+
+		case '"':
+			quoted = true
+			s, ss, err := l.readDoubleQuoted()
+			if err != nil {
+				return err
+			}
+			val.WriteString(s)
+			subs = append(subs, ss...)
+
+		case '`':
+			inner, err := l.readBackticks()
+			if err != nil {
+				return err
+			}
+			subs = append(subs, inner)
+			val.WriteString("`" + inner + "`")
+
+		case '$':
+			s, ss, err := l.readDollar()
+			if err != nil {
+				return err
+			}
+			val.WriteString(s)
+			subs = append(subs, ss...)
+
+		default:
+			val.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	word := val.String()
+
+	// a file descriptor number right before a redirection, like the 2 in '2>&1'
+	if !quoted && isDigits(word) && (l.peek(0) == '<' || l.peek(0) == '>') {
+		return nil
+	}
+
+	if l.heredocTarget {
+		l.heredocTarget = false
+		l.heredocs = append(l.heredocs, pendingHeredoc{delim: word, stripTabs: l.heredocStripTabs, expand: !quoted})
+		l.tokens = append(l.tokens, token{kind: tokSubsOnly, start: start, end: l.pos, line: startLine})
+		return nil
+	}
+
+	if l.redirectTarget {
+		l.redirectTarget = false
+		tok := token{kind: tokSubsOnly, val: word, start: start, end: l.pos, line: startLine, subs: subs}
+		if isWriteRedirect(l.redirectOp, word) {
+			tok.writeOp = l.redirectOp
+		}
+		l.tokens = append(l.tokens, tok)
+		return nil
+	}
+
+	l.tokens = append(l.tokens, token{kind: tokWord, val: word, start: start, end: l.pos, line: startLine, subs: subs})
+	return nil
+}
+
+func (l *lexer) readDoubleQuoted() (string, []string, error) {
+	startLine := l.line
+	l.pos++
+	var val strings.Builder
+	var subs []string
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch c {
+		case '"':
+			l.pos++
+			return val.String(), subs, nil
+		case '\\':
+			next := l.peek(1)
+			if next == '\n' {
+				l.pos += 2
+				l.line++
+				continue
+			}
+			if strings.IndexByte("\\\"$`", next) >= 0 {
+				val.WriteByte(next)
+			} else {
+				val.WriteByte(c)
+				val.WriteByte(next)
+			}
+			l.pos += 2
+		case '`':
+			inner, err := l.readBackticks()
+			if err != nil {
+				return "", nil, err
+			}
+			subs = append(subs, inner)
+			val.WriteString("`" + inner + "`")
+		case '$':
+			s, ss, err := l.readDollar()
+			if err != nil {
+				return "", nil, err
+			}
+			val.WriteString(s)
+			subs = append(subs, ss...)
+		default:
+			if c == '\n' {
+				l.line++
+			}
+			val.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	return "", nil, fmt.Errorf("line %d: unterminated double quote", startLine)
+}
+
+// readDollar reads a '$' expansion. Command substitutions are returned so they can be parsed as commands; other expansions are kept as they are.
+func (l *lexer) readDollar() (string, []string, error) {
+	switch l.peek(1) {
+	case '(':
+		if l.peek(2) == '(' {
+			// arithmetic expansion
+			start := l.pos
+			_, err := l.readBalanced(l.pos+3, '(', ')')
+			if err != nil {
+				return "", nil, err
+			}
+			if l.peek(0) == ')' {
+				l.pos++
+			}
+			return l.src[start:l.pos], nil, nil
+		}
+		inner, err := l.readBalanced(l.pos+2, '(', ')')
+		if err != nil {
+			return "", nil, err
+		}
+		return "$(" + inner + ")", []string{inner}, nil
+
+	case '{':
+		inner, err := l.readBalanced(l.pos+2, '{', '}')
+		if err != nil {
+			return "", nil, err
+		}
+		return "${" + inner + "}", nil, nil
+
+	case '\'':
+		// ANSI-C quoting like $'\n'
+		l.pos++
+		end := l.pos + 1
+		for end < len(l.src) && l.src[end] != '\'' {
+			if l.src[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(l.src) {
+			return "", nil, fmt.Errorf("line %d: unterminated quote", l.line)
+		}
+		s := l.src[l.pos+1 : end]
+		l.pos = end + 1
+		return s, nil, nil
+	}
+
+	l.pos++
+	return "$", nil, nil
+}
+
+func (l *lexer) readBackticks() (string, error) {
+	startLine := l.line
+	var inner strings.Builder
+	l.pos++
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == '`' {
+			l.pos++
+			return inner.String(), nil
+		}
+		if c == '\\' && l.pos+1 < len(l.src) {
+			inner.WriteByte(l.src[l.pos+1])
+			l.pos += 2
+			continue
+		}
+		if c == '\n' {
+			l.line++
+		}
+		inner.WriteByte(c)
+		l.pos++
+	}
+	return "", fmt.Errorf("line %d: unterminated backtick", startLine)
+}
+
+// readBalanced reads from start up to the matching close, skipping over quotes, and leaves the position after it
+func (l *lexer) readBalanced(start int, open, close byte) (string, error) {
+	startLine := l.line
+	depth := 1
+	i := start
+	for i < len(l.src) {
+		c := l.src[i]
+		switch c {
+		case '\\':
+			i++
+		case '\'':
+			end := strings.IndexByte(l.src[i+1:], '\'')
+			if end == -1 {
+				return "", fmt.Errorf("line %d: unterminated single quote", startLine)
+			}
+			l.line += strings.Count(l.src[i+1:i+1+end], "\n")
+			i += end + 1
+		case '"':
+			for i++; i < len(l.src) && l.src[i] != '"'; i++ {
+				if l.src[i] == '\\' {
+					i++
+				} else if l.src[i] == '\n' {
+					l.line++
+				}
+			}
+		case '\n':
+			l.line++
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				l.pos = i + 1
+				return l.src[start:i], nil
+			}
+		}
+		i++
+	}
+	return "", fmt.Errorf("line %d: unterminated '%c'", startLine, open)
+}
+
+// readHeredocBodies skips the bodies of heredocs started on the line that just ended. Substitutions in unquoted heredocs still run, so they're kept.
+func (l *lexer) readHeredocBodies() error {
+	for _, heredoc := range l.heredocs {
+		start := l.pos
+		startLine := l.line
+		var body strings.Builder
+		closed := false
+
+		for l.pos < len(l.src) {
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			var line string
+			if end == -1 {
+				line = l.src[l.pos:]
+				l.pos = len(l.src)
+			} else {
+				line = l.src[l.pos : l.pos+end]
+				l.pos += end + 1
+			}
+			l.line++
+
+			check := strings.TrimSuffix(line, "\r")
+			if heredoc.stripTabs {
+				check = strings.TrimLeft(check, "\t")
+			}
+			if check == heredoc.delim {
+				closed = true
+				break
+			}
+			body.WriteString(line + "\n")
+		}
+
+		if !closed {
+			return fmt.Errorf("line %d: heredoc '%s' is never closed", startLine-1, heredoc.delim)
+		}
+
+		if heredoc.expand {
+			subs, err := substitutions(body.String())
+			if err != nil {
+				return err
+			}
+			if len(subs) > 0 {
+				l.tokens = append(l.tokens, token{kind: tokSubsOnly, start: start, end: start, line: startLine, subs: subs})
+			}
+		}
+	}
+	l.heredocs = nil
+	return nil
+}
+
+// substitutions finds the command substitutions in text that's expanded like a double-quoted string
+func substitutions(text string) ([]string, error) {
+	l := &lexer{src: text, line: 1}
+	var subs []string
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\\':
+			l.pos += 2
+		case '`':
+			inner, err := l.readBackticks()
+			if err != nil {
+				return nil, err
+			}
+			subs = append(subs, inner)
+		case '$':
+			_, ss, err := l.readDollar()
+			if err != nil {
+				return nil, err
+			}
+			subs = append(subs, ss...)
+		default:
+			l.pos++
+		}
+	}
+	return subs, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// keywords that are dropped from the start of a command
+var shellKeywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true,
+	"do": true, "done": true, "while": true, "until": true,
+	"esac": true, "{": true, "}": true, "!": true, "time": true, "coproc": true,
+}
+
+// keywords whose whole command is a header rather than something that runs
+var shellHeaderKeywords = map[string]bool{
+	"for": true, "select": true, "case": true, "function": true,
+}
+
+var shells = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true,
+}
+
+type builder struct {
+	src   string
+	depth int
+
+	statements []*Statement
+	current    *Statement
+	command    *Command
+	// the token span of the current statement, for its text
+	start, end int
+	// set while skipping a header like 'for x in a b c'
+	skipping bool
+	// set inside '[[ ... ]]', where && and || are part of the test
+	inTest bool
+}
+
+func (b *builder) statement(tok token) *Statement {
+	if b.current == nil {
+		b.current = &Statement{Line: tok.line}
+		b.start = tok.start
+	}
+	if tok.end > tok.start {
+		b.end = tok.end
+	}
+	return b.current
+}
+
+func (b *builder) add(tok token, next *token) error {
+	for _, sub := range tok.subs {
+		nested, err := parse(sub, b.depth+1)
+		if err != nil {
+			return err
+		}
+		st := b.statement(tok)
+		st.Nested = append(st.Nested, nested...)
+	}
+
+	switch tok.kind {
+	case tokWord:
+		b.statement(tok)
+
+		if b.inTest {
+			b.command.Words = append(b.command.Words, tok.val)
+			if tok.val == "]]" {
+				b.inTest = false
+			}
+			return nil
+		}
+
+		if b.skipping {
+			return nil
+		}
+
+		if b.command == nil || len(b.command.Words) == 0 {
+			if shellKeywords[tok.val] {
+				return nil
+			}
+			if shellHeaderKeywords[tok.val] {
+				b.skipping = true
+				return nil
+			}
+			if isAssignment(tok.val) {
+				return nil
+			}
+			// a 'case' pattern like 'build)'
+			if next != nil && next.kind == tokSep && next.val == ")" {
+				b.skipping = true
+				return nil
+			}
+		}
+
+		if b.command == nil {
+			b.command = &Command{}
+		}
+		b.command.Words = append(b.command.Words, tok.val)
+		if len(b.command.Words) == 1 && tok.val == "[[" {
+			b.inTest = true
+		}
+
+	case tokRedirect, tokSubsOnly:
+		if b.inTest {
+			// a comparison like '[[ a > b ]]'
+			b.command.Words = append(b.command.Words, tok.val)
+			return nil
+		}
+		st := b.statement(tok)
+		if tok.writeOp != "" {
+			st.Writes = append(st.Writes, &Redirect{Op: tok.writeOp, Target: tok.val})
+		}
+
+	case tokPipe:
+		b.statement(tok)
+		err := b.endCommand()
+		if err != nil {
+			return err
+		}
+		b.skipping = false
+
+	case tokSep:
+		if b.inTest && (tok.val == "&&" || tok.val == "||") {
+			b.command.Words = append(b.command.Words, tok.val)
+			return nil
+		}
+
+		// a function definition like 'build() {'
+		if tok.val == "(" && next != nil && next.kind == tokSep && next.val == ")" && b.command != nil && len(b.command.Words) == 1 {
+			b.command = nil
+			b.skipping = true
+			return nil
+		}
+		if tok.val == ")" && b.skipping {
+			// the end of a function definition header or case pattern
+			b.skipping = false
+			return nil
+		}
+
+		b.inTest = false
+		b.skipping = false
+		return b.endStatement()
+	}
+
+	return nil
+}
+
+func (b *builder) endCommand() error {
+	command := b.command
+	b.command = nil
+	b.inTest = false
+
+	if command == nil || len(command.Words) == 0 {
+		return nil
+	}
+
+	return b.addCommand(b.current, command)
+}
+
+// addCommand adds a command to a statement's pipeline, parsing any script it runs into nested statements
+func (b *builder) addCommand(st *Statement, command *Command) error {
+	words := Unwrap(command.Words)
+
+	script, ok := inlineScript(words)
+	if !ok && readsStdinScript(words) {
+		// a script piped from echo or printf can be checked like 'sh -c' -- anything else can't be known ahead of time
+		if len(st.Pipeline) > 0 {
+			script, ok = echoedText(Unwrap(st.Pipeline[len(st.Pipeline)-1].Words))
+		}
+		command.StdinScript = !ok
+	}
+	if ok {
+		command.Script = true
+		nested, err := parse(script, b.depth+1)
+		if err != nil {
+			return err
+		}
+		st.Nested = append(st.Nested, nested...)
+	}
+
+	// commands run by find are checked as their own statements
+	for _, execWords := range findExecCommands(words) {
+		nested := &Statement{Line: st.Line, Text: strings.Join(execWords, " ")}
+		err := b.addCommand(nested, &Command{Words: execWords})
+		if err != nil {
+			return err
+		}
+		st.Nested = append(st.Nested, nested)
+	}
+
+	st.Pipeline = append(st.Pipeline, command)
+	return nil
+}
+
+func (b *builder) endStatement() error {
+	if b.current == nil {
+		return nil
+	}
+
+	err := b.endCommand()
+	if err != nil {
+		return err
+	}
+
+	st := b.current
+	b.current = nil
+
+	if len(st.Pipeline) == 0 && len(st.Nested) == 0 && len(st.Writes) == 0 {
+		return nil
+	}
+
+	st.Text = strings.TrimSpace(b.src[b.start:b.end])
+	b.statements = append(b.statements, st)
+	return nil
+}
+
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	if !ok || name == "" {
+		return false
+	}
+	name = strings.TrimSuffix(name, "+")
+	for i, c := range name {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// inlineScript returns the script run by 'sh -c <script>' or 'eval <args>'
+func inlineScript(words []string) (string, bool) {
+	if len(words) == 0 {
+		return "", false
+	}
+
+	name := filepath.Base(words[0])
+
+	if name == "eval" {
+		return strings.Join(words[1:], " "), len(words) > 1
+	}
+
+	if !shells[name] {
+		return "", false
+	}
+
+	// options come before the script file or the -c script
+	for i := 1; i < len(words); i++ {
+		word := words[i]
+		if strings.HasPrefix(word, "--") {
+			continue
+		}
+		if !strings.HasPrefix(word, "-") {
+			return "", false
+		}
+		if strings.ContainsRune(word[1:], 'c') && i+1 < len(words) {
+			return words[i+1], true
+		}
+	}
+
+	return "", false
+}
+
+// readsStdinScript checks whether a shell command reads its script from its input rather than a file -- with no script file, or with -s
+func readsStdinScript(words []string) bool {
+	if len(words) == 0 || !shells[filepath.Base(words[0])] {
+		return false
+	}
+	for _, word := range words[1:] {
+		switch {
+		case word == "-" || word == "-s":
+			return true
+		case strings.HasPrefix(word, "-") && len(word) > 1:
+			if !strings.HasPrefix(word, "--") && strings.ContainsRune(word[1:], 's') {
+				return true
+			}
+		default:
+			// a script file, which is checked like any other command
+			return false
+		}
+	}
+	return true
+}
+
+// echoedText returns the text written by echo or printf. Arguments with expansions, escapes, or format directives are only known when they run, so they aren't returned.
+func echoedText(words []string) (string, bool) {
+	if len(words) < 2 {
+		return "", false
+	}
+	args := words[1:]
+	switch filepath.Base(words[0]) {
+	case "echo":
+		for len(args) > 0 && (args[0] == "-n" || args[0] == "-e" || args[0] == "-E") {
+			args = args[1:]
+		}
+	case "printf":
+		if args[0] == "--" {
+			args = args[1:]
+		}
+	default:
+		return "", false
+	}
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "$`\\%") {
+			// expanded or formatted when it runs
+			return "", false
+		}
+	}
+	return strings.Join(args, " "), len(args) > 0
+}
+
+// findExecCommands returns the commands that find runs with -exec, -execdir, -ok, and -okdir, which end at ';' or '+'
+func findExecCommands(words []string) [][]string {
+	if len(words) == 0 || filepath.Base(words[0]) != "find" {
+		return nil
+	}
+
+	var res [][]string
+	for i := 1; i < len(words); i++ {
+		switch words[i] {
+		case "-exec", "-execdir", "-ok", "-okdir":
+		default:
+			continue
+		}
+		end := i + 1
+		for end < len(words) && words[end] != ";" && words[end] != "+" {
+			end++
+		}
+		if end > i+1 {
+			res = append(res, words[i+1:end])
+		}
+		i = end
+	}
+	return res
+}
+
+type wrapper struct {
+	// options that take a value as the following word
+	valueOpts map[string]bool
+	// the number of positional args before the wrapped command, like the duration for timeout
+	positionals int
+	// whether NAME=value args come before the wrapped command
+	assignments bool
+}
+
+// commands that run another command given as their arguments
+var wrappers = map[string]wrapper{
+	"sudo":    {valueOpts: map[string]bool{"-u": true, "-g": true, "-U": true, "-C": true, "-D": true, "-h": true, "-p": true, "-r": true, "-t": true, "-T": true}},
+	"doas":    {valueOpts: map[string]bool{"-u": true, "-C": true}},
+	"env":     {valueOpts: map[string]bool{"-u": true, "-C": true, "-S": true}, assignments: true},
+	"nohup":   {},
+	"nice":    {valueOpts: map[string]bool{"-n": true}},
+	"ionice":  {valueOpts: map[string]bool{"-c": true, "-n": true, "-p": true}},
+	"timeout": {valueOpts: map[string]bool{"-s": true, "-k": true}, positionals: 1},
+	"exec":    {valueOpts: map[string]bool{"-a": true}},
+	"command": {},
+	"builtin": {},
+	"stdbuf":  {valueOpts: map[string]bool{"-i": true, "-o": true, "-e": true}},
+	"xargs":   {valueOpts: map[string]bool{"-I": true, "-n": true, "-P": true, "-L": true, "-d": true, "-s": true, "-E": true, "-a": true}},
+}
+
+// Unwrap strips wrapper commands like sudo, env, and xargs, returning the command that ends up running. Words that aren't wrapped are returned as they are.
+func Unwrap(words []string) []string {
+	for {
+		inner := unwrapOnce(words)
+		if inner == nil {
+			return words
+		}
+		words = inner
+	}
+}
+
+// unwrapOnce strips a single wrapper command, returning nil if words don't start with one or it doesn't wrap anything
+func unwrapOnce(words []string) []string {
+	if len(words) == 0 {
+		return nil
+	}
+	name := filepath.Base(words[0])
+	w, ok := wrappers[name]
+	if !ok {
+		return nil
+	}
+	// 'command -v' only looks a command up
+	if name == "command" && len(words) > 1 && (words[1] == "-v" || words[1] == "-V") {
+		return nil
+	}
+
+	positionals := w.positionals
+	for i := 1; i < len(words); i++ {
+		word := words[i]
+		switch {
+		case word == "--":
+			if i+1 < len(words) {
+				return words[i+1:]
+			}
+			return nil
+		case strings.HasPrefix(word, "-") && len(word) > 1:
+			if w.valueOpts[word] {
+				i++
+			}
+		case w.assignments && isAssignment(word):
+		case positionals > 0:
+			positionals--
+		default:
+			return words[i:]
+		}
+	}
+	return nil
+}
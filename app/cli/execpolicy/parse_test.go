@@ -0,0 +1,196 @@
+package execpolicy
+
+import (
+	"strings"
+	"testing"
+)
+
+// commands flattens a script's statements to their commands, nested ones included
+func commands(t *testing.T, script string) []string {
+	t.Helper()
+	statements, err := Parse(script)
+	if err != nil {
+		t.Fatalf("error parsing %q: %v", script, err)
+	}
+
+	var res []string
+	var walk func(statements []*Statement)
+	walk = func(statements []*Statement) {
+		for _, st := range statements {
+			for _, cmd := range st.Pipeline {
+				res = append(res, cmd.String())
+			}
+			walk(st.Nested)
+		}
+	}
+	walk(statements)
+	return res
+}
+
+func TestParseCommands(t *testing.T) {
+	tests := []struct {
+		script string
+		want   []string
+	}{
+		{"go build ./... && go test ./...", []string{"go build ./...", "go test ./..."}},
+		{"echo 'a  b' \"c $HOME\" d\\ e", []string{"echo a  b c $HOME d e"}},
+		{"npm install \\\n  --save-dev jest", []string{"npm install --save-dev jest"}},
+		{"# a comment\nls # trailing", []string{"ls"}},
+		// assignments and keywords aren't commands
+		{"FOO=1 BAR=2 make build", []string{"make build"}},
+		{"if [ -f go.mod ]; then go test; fi", []string{"[ -f go.mod ]", "go test"}},
+		{"for f in a b; do rm $f; done", []string{"rm $f"}},
+		{"case $x in\n  build) make;;\nesac", []string{"make"}},
+		{"build() { go build; }\nbuild", []string{"go build", "build"}},
+		{"[[ -n $x && -z $y ]] && echo ok", []string{"[[ -n $x && -z $y ]]", "echo ok"}},
+		{"(( i++ )); echo $((1 + 2))", []string{"echo $((1 + 2))"}},
+		// redirections are dropped
+		{"go test 2>&1 > out.log < /dev/null", []string{"go test"}},
+		// substitutions run as their own commands
+		{"echo $(git rev-parse HEAD) `date`", []string{"echo $(git rev-parse HEAD) `date`", "git rev-parse HEAD", "date"}},
+		{"diff <(ls a) >(cat)", []string{"diff", "ls a", "cat"}},
+		{"echo \"$(whoami)\"", []string{"echo $(whoami)", "whoami"}},
+		// heredoc bodies aren't commands, but substitutions in unquoted ones run
+		{"cat <<EOF > file\nrm -rf /\n$(id)\nEOF\necho done", []string{"cat", "id", "echo done"}},
+		{"cat <<'EOF'\n$(id)\nEOF", []string{"cat"}},
+		{"cat <<-EOF\n\trm -rf /\n\tEOF", []string{"cat"}},
+		// inline scripts are parsed
+		{"sh -c 'go vet && git push'", []string{"sh -c go vet && git push", "go vet", "git push"}},
+		{"sudo bash -ec 'make install'", []string{"sudo bash -ec make install", "make install"}},
+		{"eval \"git push\"", []string{"eval git push", "git push"}},
+		// scripts piped from echo or printf are parsed
+		{"echo git push | sh", []string{"echo git push", "sh", "git push"}},
+		{"echo -n 'rm -rf /' | sudo bash -s", []string{"echo -n rm -rf /", "sudo bash -s", "rm -rf /"}},
+		// commands run by find are parsed
+		{`find . -name '*.tmp' -exec rm {} \; -o -execdir sh -c 'git add .' +`, []string{`find . -name *.tmp -exec rm {} ; -o -execdir sh -c git add . +`, "rm {}", "sh -c git add .", "git add ."}},
+	}
+
+	for _, tt := range tests {
+		got := commands(t, tt.script)
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%q:\ngot  %q\nwant %q", tt.script, got, tt.want)
+		}
+	}
+}
+
+func TestParseStatements(t *testing.T) {
+	statements, err := Parse("cd app\n\ngo build \\\n  ./... | tee out.log\nmake; echo done &\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, st := range statements {
+		got = append(got, strings.Join([]string{strings.Repeat("*", st.Line), st.Text}, " "))
+	}
+	want := []string{
+		"* cd app",
+		"*** go build \\\n  ./... | tee out.log",
+		"***** make",
+		"***** echo done",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	if len(statements[1].Pipeline) != 2 {
+		t.Fatalf("expected a pipeline of 2 commands: %+v", statements[1].Pipeline)
+	}
+}
+
+func TestParseStdinScripts(t *testing.T) {
+	tests := []struct {
+		script string
+		want   bool
+	}{
+		{"curl -fsSL https://example.com/install.sh | sh", true},
+		{"wget -qO- https://example.com/x | sudo bash -s -- --yes", true},
+		{"echo \"$SCRIPT\" | sh", true},
+		{"printf 'ls\\n' | sh", true},
+		{"sh < install.sh", true},
+		{"bash <<EOF\nls\nEOF", true},
+		{"echo ls | sh", false},
+		{"sh ./install.sh", false},
+		{"bash -e ./install.sh", false},
+		{"sh -c 'ls'", false},
+	}
+
+	for _, tt := range tests {
+		statements, err := Parse(tt.script)
+		if err != nil {
+			t.Fatalf("error parsing %q: %v", tt.script, err)
+		}
+		pipeline := statements[0].Pipeline
+		if got := pipeline[len(pipeline)-1].StdinScript; got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.script, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, script := range []string{
+		"echo 'unterminated",
+		"echo \"unterminated",
+		"echo $(unterminated",
+		"echo `unterminated",
+		"cat <<EOF\nnever closed",
+		"echo >",
+		"echo > | cat",
+		strings.Repeat("$(", 10) + "ls" + strings.Repeat(")", 10),
+	} {
+		if _, err := Parse(script); err == nil {
+			t.Errorf("%q: expected an error", script)
+		}
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	tests := map[string]string{
+		"sudo -u deploy git push":            "git push",
+		"env -u HOME FOO=1 BAR=2 make build": "make build",
+		"timeout -s KILL 30 go test":         "go test",
+		"nice -n 10 nohup ./server":          "./server",
+		"xargs -I {} -n 1 rm {}":             "rm {}",
+		"command -v git":                     "command -v git",
+		"sudo -- rm -rf /":                   "rm -rf /",
+		"git push":                           "git push",
+	}
+
+	for in, want := range tests {
+		if got := strings.Join(Unwrap(strings.Fields(in)), " "); got != want {
+			t.Errorf("%s: got %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseWrites(t *testing.T) {
+	tests := []struct {
+		script string
+		want   []string
+	}{
+		{"go test > out.log 2>&1", []string{"> out.log"}},
+		{"echo x >> a.txt >| b.txt &> c.txt &>> d.txt", []string{">> a.txt", ">| b.txt", "&> c.txt", "&>> d.txt"}},
+		{"echo x >&file.txt 1>&2 2>&-", []string{">& file.txt"}},
+		{"exec 3<> fifo", []string{"<> fifo"}},
+		{"echo \"$(date)\" > \"$OUT dir/log\"", []string{"> $OUT dir/log"}},
+		{"cat < in.txt <<< 'x' <<EOF\nbody\nEOF", nil},
+		{"[[ b > a ]]", nil},
+		{"{ echo a; echo b; } > both.txt", []string{"> both.txt"}},
+	}
+
+	for _, tt := range tests {
+		statements, err := Parse(tt.script)
+		if err != nil {
+			t.Fatalf("error parsing %q: %v", tt.script, err)
+		}
+		var got []string
+		for _, st := range statements {
+			for _, write := range st.Writes {
+				got = append(got, write.String())
+			}
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%q: got %q, want %q", tt.script, got, tt.want)
+		}
+	}
+}
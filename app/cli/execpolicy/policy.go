@@ -0,0 +1,503 @@
+package execpolicy
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+// commands that can't do anything on their own, which are allowed unless a rule says otherwise
+var implicitlyAllowed = map[string]bool{
+	"cd": true, "pwd": true, "echo": true, "printf": true, "export": true, "unset": true, "set": true,
+	"true": true, "false": true, "test": true, "[": true, "[[": true, ":": true, "exit": true, "return": true,
+	"local": true, "shift": true, "read": true, "wait": true, "sleep": true,
+}
+
+// Source is a policy and where it comes from, like 'project' or 'org'
+type Source struct {
+	Name   string
+	Policy *shared.ExecPolicy
+}
+
+type Rule struct {
+	Pattern string
+	Verdict shared.ExecPolicyVerdict
+	Source  string
+
+	// the commands of a pipeline pattern like 'curl | sh', or a single command
+	segments [][]string
+	// for a rule like '> dist/*', the files it matches writes to
+	writeTarget string
+}
+
+// files that decide what later scripts and verification commands can run, so no rule can allow writing to them -- along with anything in a .git dir, which git can run on the host through hooks and config
+var protectedFiles = []string{".plandex-policy.json", ".plandex-verify.json"}
+
+// writes to these are harmless, so they're allowed unless a rule says otherwise
+var implicitlyAllowedWrites = map[string]bool{
+	"/dev/null": true, "/dev/stdout": true, "/dev/stderr": true,
+}
+
+// Policy is the merged rules from one or more sources. When several rules match a command, the strictest one wins, so a project policy can't loosen an org policy.
+type Policy struct {
+	Rules         []*Rule
+	Default       shared.ExecPolicyVerdict
+	DefaultSource string
+}
+
+// Result is the verdict for a top-level statement of a script
+type Result struct {
+	Line    int
+	Text    string
+	Verdict shared.ExecPolicyVerdict
+
+	// the command that decided the verdict, which can differ from Text for a nested or piped command
+	Command string
+	// the rule that decided the verdict -- empty when no rule matched
+	Rule   string
+	Source string
+	// why the verdict was raised without a rule, like a command name that's only known when the script runs
+	Reason string
+	// set when the script couldn't be parsed
+	Error string
+}
+
+func New(sources ...Source) *Policy {
+	p := &Policy{Default: shared.ExecPolicyConfirm}
+	defaultSet := false
+
+	for _, source := range sources {
+		if source.Policy.IsEmpty() {
+			continue
+		}
+
+		for _, verdict := range []shared.ExecPolicyVerdict{shared.ExecPolicyDeny, shared.ExecPolicyConfirm, shared.ExecPolicyAllow} {
+			patterns := source.Policy.Allow
+			switch verdict {
+			case shared.ExecPolicyConfirm:
+				patterns = source.Policy.Confirm
+			case shared.ExecPolicyDeny:
+				patterns = source.Policy.Deny
+			}
+			for _, pattern := range patterns {
+				rule := &Rule{Pattern: strings.TrimSpace(pattern), Verdict: verdict, Source: source.Name}
+				if words := strings.Fields(rule.Pattern); len(words) == 2 && (words[0] == ">" || words[0] == ">>") {
+					rule.writeTarget = words[1]
+					p.Rules = append(p.Rules, rule)
+					continue
+				}
+				for _, segment := range strings.Split(rule.Pattern, "|") {
+					words := strings.Fields(segment)
+					if len(words) > 0 {
+						rule.segments = append(rule.segments, words)
+					}
+				}
+				if len(rule.segments) > 0 {
+					p.Rules = append(p.Rules, rule)
+				}
+			}
+		}
+
+		if d := source.Policy.Default; d.IsValid() && (!defaultSet || d.Severity() > p.Default.Severity()) {
+			p.Default = d
+			p.DefaultSource = source.Name
+			defaultSet = true
+		}
+	}
+
+	return p
+}
+
+func (p *Policy) IsEmpty() bool {
+	return len(p.Rules) == 0 && p.DefaultSource == ""
+}
+
+// Evaluate parses a script and returns a verdict for each of its top-level statements. A script that can't be parsed gets a single confirm verdict.
+func (p *Policy) Evaluate(script string) []*Result {
+	statements, err := Parse(script)
+	if err != nil {
+		return []*Result{{
+			Line:    1,
+			Text:    "(whole script)",
+			Verdict: shared.ExecPolicyConfirm,
+			Error:   err.Error(),
+		}}
+	}
+
+	var results []*Result
+	for _, st := range statements {
+		res := p.evaluateStatement(st)
+		res.Line = st.Line
+		res.Text = st.Text
+		results = append(results, res)
+	}
+	return results
+}
+
+// Verdict returns the strictest verdict of the results
+func Verdict(results []*Result) shared.ExecPolicyVerdict {
+	verdict := shared.ExecPolicyAllow
+	for _, res := range results {
+		if res.Verdict.Severity() > verdict.Severity() {
+			verdict = res.Verdict
+		}
+	}
+	return verdict
+}
+
+func (p *Policy) evaluateStatement(st *Statement) *Result {
+	var res *Result
+	consider := func(r *Result) {
+		if r != nil && (res == nil || r.Verdict.Severity() > res.Verdict.Severity()) {
+			res = r
+		}
+	}
+
+	if len(st.Pipeline) > 1 {
+		for _, rule := range p.Rules {
+			if len(rule.segments) > 1 && matchPipeline(rule.segments, st.Pipeline, rule.Verdict != shared.ExecPolicyAllow) {
+				consider(&Result{Verdict: rule.Verdict, Command: pipelineString(st.Pipeline), Rule: rule.Pattern, Source: rule.Source})
+			}
+		}
+	}
+
+	for _, cmd := range st.Pipeline {
+		consider(p.evaluateCommand(cmd))
+	}
+
+	for _, nested := range st.Nested {
+		consider(p.evaluateStatement(nested))
+	}
+
+	for _, write := range st.Writes {
+		consider(p.evaluateWrite(write))
+	}
+
+	if res == nil {
+		res = &Result{Verdict: shared.ExecPolicyAllow}
+	}
+	return res
+}
+
+// evaluateCommand checks a command and, for wrappers like sudo, the command it runs. Rules matching a wrapper count, but only the command that ends up running falls back to the default.
+func (p *Policy) evaluateCommand(cmd *Command) *Result {
+	var res *Result
+	consider := func(r *Result) {
+		if res == nil || r.Verdict.Severity() > res.Verdict.Severity() {
+			res = r
+		}
+	}
+
+	// what runs can't be checked ahead of time, so no rule can allow it
+	unknown := func(reason string) {
+		consider(&Result{Verdict: p.unknownVerdict(), Command: cmd.String(), Reason: reason})
+	}
+
+	if cmd.StdinScript {
+		unknown("runs a script from its input")
+	}
+
+	// tee writes its input to files just like a redirection
+	for _, target := range teeTargets(Unwrap(cmd.Words)) {
+		consider(p.evaluateWrite(&Redirect{Op: "tee", Target: target}))
+	}
+
+	words := cmd.Words
+	// xargs adds arguments from its input, so the command it runs may have more than are written
+	appended := false
+	for words != nil {
+		inner := unwrapOnce(words)
+
+		if isDynamicWord(words[0]) {
+			unknown("the command is only known when it runs")
+		}
+
+		rule := p.match(words, appended)
+		if rule != nil {
+			consider(&Result{Verdict: rule.Verdict, Command: cmd.String(), Rule: rule.Pattern, Source: rule.Source})
+		} else if inner == nil && !cmd.Script && !isDynamicWord(words[0]) {
+			if implicitlyAllowed[filepath.Base(words[0])] {
+				consider(&Result{Verdict: shared.ExecPolicyAllow, Command: cmd.String()})
+			} else {
+				consider(&Result{Verdict: p.Default, Command: cmd.String(), Source: p.DefaultSource})
+			}
+		}
+
+		if filepath.Base(words[0]) == "xargs" {
+			appended = true
+		}
+		words = inner
+	}
+	return res
+}
+
+// evaluateWrite checks a redirection to a file. Writing to the policy and verify files or into a .git dir is always denied. Other writes need confirmation unless a rule like '> dist/*' allows them.
+func (p *Policy) evaluateWrite(write *Redirect) *Result {
+	if isProtectedPath(write.Target) {
+		return &Result{Verdict: shared.ExecPolicyDeny, Command: write.String(), Reason: "writes to a plandex config file or a .git dir"}
+	}
+
+	target := filepath.ToSlash(filepath.Clean(write.Target))
+
+	var res *Result
+	if !isDynamicWord(write.Target) {
+		for _, rule := range p.Rules {
+			if rule.writeTarget == "" || !matchWriteTarget(rule.writeTarget, target) {
+				continue
+			}
+			if res == nil || rule.Verdict.Severity() > res.Verdict.Severity() {
+				res = &Result{Verdict: rule.Verdict, Command: write.String(), Rule: rule.Pattern, Source: rule.Source}
+			}
+		}
+	}
+	if res != nil {
+		return res
+	}
+
+	if implicitlyAllowedWrites[target] {
+		return &Result{Verdict: shared.ExecPolicyAllow, Command: write.String()}
+	}
+	return &Result{Verdict: p.unknownVerdict(), Command: write.String(), Reason: "writes to a file"}
+}
+
+// isProtectedPath checks for the policy and verify files, or a path in a .git dir, wherever they are. Names are compared case-insensitively, since they're the same files on macOS and Windows.
+func isProtectedPath(target string) bool {
+	parts := strings.Split(filepath.ToSlash(target), "/")
+	for _, part := range parts {
+		if strings.EqualFold(part, ".git") {
+			return true
+		}
+	}
+	for _, name := range protectedFiles {
+		if strings.EqualFold(parts[len(parts)-1], name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWriteTarget matches a cleaned redirection target against a rule's glob. A pattern ending in '/*' covers everything below the dir.
+func matchWriteTarget(pattern, target string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	if globMatch(pattern, target) {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok && !strings.ContainsAny(prefix, "*?[") {
+		return strings.HasPrefix(target, prefix+"/")
+	}
+	return false
+}
+
+// teeTargets returns the files a tee command writes to
+func teeTargets(words []string) []string {
+	if len(words) == 0 || filepath.Base(words[0]) != "tee" {
+		return nil
+	}
+	var res []string
+	opts := true
+	for _, word := range words[1:] {
+		if opts && word == "--" {
+			opts = false
+			continue
+		}
+		if opts && strings.HasPrefix(word, "-") && word != "-" {
+			continue
+		}
+		res = append(res, word)
+	}
+	return res
+}
+
+// unknownVerdict is the verdict for a command that can't be known until it runs -- confirm, or the default if it's stricter
+func (p *Policy) unknownVerdict() shared.ExecPolicyVerdict {
+	if p.Default.Severity() > shared.ExecPolicyConfirm.Severity() {
+		return p.Default
+	}
+	return shared.ExecPolicyConfirm
+}
+
+// isDynamicWord checks whether a word is expanded when the script runs, like '$cmd', '$(echo git)', or a glob
+func isDynamicWord(word string) bool {
+	if word == "[" || word == "[[" {
+		return false
+	}
+	return strings.ContainsAny(word, "$`*?[")
+}
+
+// match returns the strictest single-command rule that matches words
+func (p *Policy) match(words []string, appended bool) *Rule {
+	var res *Rule
+	for _, rule := range p.Rules {
+		if rule.writeTarget != "" || len(rule.segments) != 1 || !matchCommand(rule.segments[0], words, rule.Verdict != shared.ExecPolicyAllow, appended) {
+			continue
+		}
+		if res == nil || rule.Verdict.Severity() > res.Verdict.Severity() {
+			res = rule
+		}
+	}
+	return res
+}
+
+// matchPipeline checks whether the pipeline has commands matching each segment of the pattern in order, like 'curl | sh' for 'curl -fsSL https://... | sudo bash'
+func matchPipeline(segments [][]string, pipeline []*Command, strict bool) bool {
+	i := 0
+	for _, cmd := range pipeline {
+		if i == len(segments) {
+			break
+		}
+		for words := cmd.Words; words != nil; words = unwrapOnce(words) {
+			if matchCommand(segments[i], words, strict, false) {
+				i++
+				break
+			}
+		}
+	}
+	return i == len(segments)
+}
+
+// options of common commands that take the following word as their value, like 'git -C <dir>'
+var valueOptions = map[string]map[string]bool{
+	"git":     {"-C": true, "-c": true, "--git-dir": true, "--work-tree": true, "--namespace": true, "--config-env": true, "--super-prefix": true},
+	"docker":  {"-H": true, "--host": true, "-c": true, "--context": true, "--config": true, "-l": true, "--log-level": true},
+	"kubectl": {"-n": true, "--namespace": true, "--context": true, "--cluster": true, "--user": true, "-s": true, "--server": true, "--kubeconfig": true},
+	"npm":     {"--prefix": true, "-w": true, "--workspace": true},
+	"pnpm":    {"-C": true, "--dir": true, "-F": true, "--filter": true},
+	"yarn":    {"--cwd": true},
+	"go":      {"-C": true},
+	"make":    {"-C": true, "--directory": true, "-f": true, "--file": true},
+	"cargo":   {"--manifest-path": true, "--config": true, "-Z": true},
+}
+
+// commandArg is an argument that isn't an option
+type commandArg struct {
+	word string
+	// set when the argument follows an option, so it might be that option's value
+	afterOption bool
+}
+
+// matchCommand checks a command against a pattern. The pattern's first word matches the command name or its base name. Options in the pattern like '-rf' or '--force' can appear anywhere in the command, with short options in any order or grouping. The pattern's other words match the command's leading arguments, and can use globs like 'npm run *'.
+//
+// Values of options known to take one, like the dir in 'git -C <dir> push', are skipped before matching arguments. When strict is set, for rules that confirm or deny, any argument right after an option is also tried as that option's value, so 'git -x y push' still matches 'git push'. When appended is set, the command gets more arguments when it runs, so a strict rule matches as long as nothing written contradicts it.
+func matchCommand(pattern []string, words []string, strict, appended bool) bool {
+	if len(pattern) == 0 || len(words) == 0 {
+		return false
+	}
+
+	if !globMatch(pattern[0], words[0]) && !globMatch(pattern[0], filepath.Base(words[0])) {
+		return false
+	}
+
+	opts := valueOptions[filepath.Base(words[0])]
+
+	var args []commandArg
+	var shortFlags, longOpts []string
+	afterOption := false
+	for i := 1; i < len(words); i++ {
+		word := words[i]
+		switch {
+		case word == "--":
+			for _, arg := range words[i+1:] {
+				args = append(args, commandArg{word: arg})
+			}
+			i = len(words)
+		case strings.HasPrefix(word, "--") && len(word) > 2:
+			longOpts = append(longOpts, word)
+			if opts[word] {
+				i++
+				afterOption = false
+			} else {
+				afterOption = !strings.Contains(word, "=")
+			}
+			continue
+		case strings.HasPrefix(word, "-") && len(word) > 1:
+			shortFlags = append(shortFlags, word[1:])
+			if opts[word] {
+				i++
+			}
+			afterOption = !opts[word]
+			continue
+		default:
+			args = append(args, commandArg{word: word, afterOption: afterOption})
+		}
+		afterOption = false
+	}
+
+	lenient := strict && appended
+
+	var positionals []string
+	for _, p := range pattern[1:] {
+		switch {
+		case strings.HasPrefix(p, "--") && len(p) > 2:
+			if !hasLongOpt(p, longOpts) && !lenient {
+				return false
+			}
+		case strings.HasPrefix(p, "-") && len(p) > 1:
+			if !hasShortFlags(p[1:], shortFlags) && !lenient {
+				return false
+			}
+		default:
+			positionals = append(positionals, p)
+		}
+	}
+
+	return matchArgs(positionals, args, strict, lenient)
+}
+
+// matchArgs matches patterns against the leading args, trying args that follow an option both as arguments and as the option's value when strict is set
+func matchArgs(patterns []string, args []commandArg, strict, lenient bool) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	if len(args) == 0 {
+		return lenient
+	}
+	if globMatch(patterns[0], args[0].word) && matchArgs(patterns[1:], args[1:], strict, lenient) {
+		return true
+	}
+	return strict && args[0].afterOption && matchArgs(patterns, args[1:], strict, lenient)
+}
+
+func hasLongOpt(pattern string, opts []string) bool {
+	for _, opt := range opts {
+		name, _, _ := strings.Cut(opt, "=")
+		if globMatch(pattern, opt) || globMatch(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasShortFlags checks that every letter of a short option pattern like 'rf' is set, whether as '-rf', '-fr', or '-r -f'
+func hasShortFlags(pattern string, flags []string) bool {
+	for _, c := range pattern {
+		found := false
+		for _, flag := range flags {
+			if strings.ContainsRune(flag, c) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func globMatch(pattern, s string) bool {
+	if pattern == s {
+		return true
+	}
+	ok, _ := path.Match(pattern, s)
+	return ok
+}
+
+func pipelineString(pipeline []*Command) string {
+	var parts []string
+	for _, cmd := range pipeline {
+		parts = append(parts, cmd.String())
+	}
+	return strings.Join(parts, " | ")
+}
@@ -16,6 +16,7 @@ var CacheDir string
 var HomeDir string
 var HomeAuthPath string
 var HomeAccountsPath string
+var HomeUrlAuthPath string
 
 func init() {
 	var err error
@@ -45,6 +46,8 @@ func init() {
 	CacheDir = filepath.Join(HomePlandexDir, "cache")
 	HomeAuthPath = filepath.Join(HomePlandexDir, "auth.json")
 	HomeAccountsPath = filepath.Join(HomePlandexDir, "accounts.json")
+	// credentials for loading private urls -- only read locally, never sent to the server
+	HomeUrlAuthPath = filepath.Join(HomePlandexDir, "url-auth.json")
 
 	err = os.MkdirAll(filepath.Join(CacheDir, "tiktoken"), os.ModePerm)
 	if err != nil {
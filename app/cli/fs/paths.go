@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"plandex-cli/types"
+	"sort"
 	"strings"
 	"sync"
 
@@ -349,22 +350,99 @@ func GetPaths(baseDir, currentDir string) (*types.ProjectPaths, error) {
 	}, nil
 }
 
-func GetPlandexIgnore(dir string) (*ignore.GitIgnore, error) {
-	ignorePath := filepath.Join(dir, ".plandexignore")
+// GetPlandexIgnore loads the .plandexignore at the root of dir along with any nested .plandexignore files in its subdirectories
+func GetPlandexIgnore(dir string) (*types.PlandexIgnore, error) {
+	ignorePaths, err := findPlandexIgnoreFiles(dir)
+	if err != nil {
+		return nil, err
+	}
 
-	if _, err := os.Stat(ignorePath); err == nil {
-		ignored, err := ignore.CompileIgnoreFile(ignorePath)
+	if len(ignorePaths) == 0 {
+		return nil, nil
+	}
 
+	res := &types.PlandexIgnore{}
+
+	for _, relPath := range ignorePaths {
+		ignorePath := filepath.Join(dir, relPath)
+
+		ignored, err := ignore.CompileIgnoreFile(ignorePath)
 		if err != nil {
-			return nil, fmt.Errorf("error reading .plandexignore file: %s", err)
+			return nil, fmt.Errorf("error reading %s: %s", relPath, err)
 		}
 
-		return ignored, nil
+		res.Files = append(res.Files, &types.PlandexIgnoreFile{
+			Dir:    filepath.Dir(relPath),
+			Ignore: ignored,
+		})
+	}
+
+	return res, nil
+}
+
+// findPlandexIgnoreFiles returns the paths of .plandexignore files in dir relative to dir, shallowest first
+func findPlandexIgnoreFiles(dir string) ([]string, error) {
+	var res []string
+
+	if _, err := os.Stat(filepath.Join(dir, ".plandexignore")); err == nil {
+		res = append(res, ".plandexignore")
 	} else if !os.IsNotExist(err) {
 		return nil, fmt.Errorf("error checking for .plandexignore file: %s", err)
 	}
 
-	return nil, nil
+	if IsGitRepo(dir) {
+		// a pathspec '*' matches across directories, so this finds nested files at any depth, skipping any in git ignored dirs
+		cmd := exec.Command("git", "ls-files", "--cached", "--others", "--exclude-standard", "--", "*/.plandexignore")
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("error finding nested .plandexignore files: %s", err)
+		}
+
+		for _, line := range strings.Split(string(out), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || IsInSkippedDir(line) {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(dir, line)); err != nil {
+				// deleted but still tracked
+				continue
+			}
+			res = append(res, filepath.FromSlash(line))
+		}
+	} else {
+		err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != dir && ShouldSkipDir(d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Name() != ".plandexignore" {
+				return nil
+			}
+			relPath, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			if relPath != ".plandexignore" {
+				res = append(res, relPath)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error finding nested .plandexignore files: %s", err)
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return strings.Count(res[i], string(os.PathSeparator)) < strings.Count(res[j], string(os.PathSeparator))
+	})
+
+	return res, nil
 }
 
 func GetBaseDirForContexts(contexts []*shared.Context) string {
@@ -0,0 +1,293 @@
+package fs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// dependency dirs are linked into the scratch copy rather than copied, since they can be large and verification commands only need to read them
+var scratchLinkDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"venv":         true,
+	".venv":        true,
+	".bundle":      true,
+	".terraform":   true,
+}
+
+// CreateScratchCopy copies the project into a temp dir and applies pending changes to the copy, so commands can check the changes without touching the project. Files are copied, git-ignored ones like build output included, apart from dependency dirs like node_modules and vendor, which are symlinked so tools can still find them without copying them, so commands that write into those dirs write into the project's. The .git dir and other skipped dirs like .cache and .next aren't included. Pending paths that would land outside the copy are an error. The caller should remove the returned dir.
+func CreateScratchCopy(root string, files map[string]string, removed map[string]bool) (string, error) {
+	dir, err := os.MkdirTemp("", "plandex-verify-*")
+	if err != nil {
+		return "", fmt.Errorf("error creating scratch dir: %v", err)
+	}
+
+	var copyPaths, linkPaths []string
+	if IsGitRepo(root) {
+		copyPaths, linkPaths, err = gitScratchPaths(root)
+	} else {
+		copyPaths, linkPaths, err = walkScratchPaths(root, "")
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	for _, path := range copyPaths {
+		err = copyScratchFile(filepath.Join(root, path), filepath.Join(dir, path))
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+	}
+
+	for _, path := range linkPaths {
+		dst := filepath.Join(dir, path)
+		if _, err := os.Lstat(dst); err == nil {
+			continue
+		}
+		err = os.MkdirAll(filepath.Dir(dst), 0755)
+		if err == nil {
+			err = os.Symlink(filepath.Join(root, path), dst)
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("error linking %s: %v", path, err)
+		}
+	}
+
+	for path := range removed {
+		var dst string
+		dst, err = scratchDest(dir, path)
+		if err == nil {
+			err = unlinkScratchParents(root, dir, path)
+		}
+		if err == nil {
+			err = os.RemoveAll(dst)
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("error removing %s: %v", path, err)
+		}
+	}
+
+	for path, content := range files {
+		var dst string
+		dst, err = scratchDest(dir, path)
+		if err == nil {
+			err = unlinkScratchParents(root, dir, path)
+		}
+		if err == nil {
+			// replaces a symlink rather than writing through it
+			os.Remove(dst)
+			err = os.MkdirAll(filepath.Dir(dst), 0755)
+		}
+		if err == nil {
+			err = os.WriteFile(dst, []byte(content), 0644)
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("error writing %s: %v", path, err)
+		}
+	}
+
+	return dir, nil
+}
+
+// scratchDest is where a pending path goes in the scratch copy. Paths from the plan are relative to the project root, so one that's absolute or climbs out with '..' is rejected.
+func scratchDest(dir, path string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(path))
+	if path == "" || filepath.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("%s is outside the project", path)
+	}
+	return filepath.Join(dir, clean), nil
+}
+
+// unlinkScratchParents replaces any symlinked dirs above a path in the scratch copy with real dirs, whose entries link back to the project, so changing the path can't reach through a link into the project
+func unlinkScratchParents(root, dir, path string) error {
+	parts := strings.Split(filepath.Clean(filepath.FromSlash(path)), string(os.PathSeparator))
+	current := ""
+	for _, part := range parts[:len(parts)-1] {
+		current = filepath.Join(current, part)
+		scratchPath := filepath.Join(dir, current)
+
+		info, err := os.Lstat(scratchPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+
+		err = os.Remove(scratchPath)
+		if err != nil {
+			return err
+		}
+		err = os.Mkdir(scratchPath, 0755)
+		if err != nil {
+			return err
+		}
+
+		entries, err := os.ReadDir(filepath.Join(root, current))
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			err = os.Symlink(filepath.Join(root, current, entry.Name()), filepath.Join(scratchPath, entry.Name()))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// gitScratchPaths lists tracked, untracked, and ignored files to copy, and dependency dirs to link. Ignored dirs like dist/ are walked so a build in the copy can't write into the project through a link.
+func gitScratchPaths(root string) ([]string, []string, error) {
+	copyPaths, err := gitLsFiles(root, "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ignored, err := gitLsFiles(root, "--others", "--ignored", "--exclude-standard", "--directory")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var linkPaths []string
+	for _, path := range ignored {
+		path = strings.TrimSuffix(path, string(os.PathSeparator))
+		if strings.HasPrefix(filepath.Base(path), ".plandex") {
+			continue
+		}
+
+		info, err := os.Lstat(filepath.Join(root, path))
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading %s: %v", path, err)
+		}
+		if !info.IsDir() {
+			copyPaths = append(copyPaths, path)
+			continue
+		}
+
+		dirCopyPaths, dirLinkPaths, err := walkScratchPaths(root, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		copyPaths = append(copyPaths, dirCopyPaths...)
+		linkPaths = append(linkPaths, dirLinkPaths...)
+	}
+
+	return copyPaths, linkPaths, nil
+}
+
+func gitLsFiles(root string, args ...string) ([]string, error) {
+	cmd := exec.Command("git", append([]string{"ls-files", "-z"}, args...)...)
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing files in git repo: %v", err)
+	}
+
+	var res []string
+	for _, path := range strings.Split(string(out), "\x00") {
+		if path != "" {
+			res = append(res, filepath.FromSlash(path))
+		}
+	}
+	return res, nil
+}
+
+// walkScratchPaths lists files to copy below a dir of the project ("" for the whole project), linking dependency dirs instead of walking them
+func walkScratchPaths(root, dir string) ([]string, []string, error) {
+	var copyPaths, linkPaths []string
+
+	err := filepath.Walk(filepath.Join(root, dir), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		if info.IsDir() {
+			if scratchLinkDirs[info.Name()] {
+				linkPaths = append(linkPaths, relPath)
+				return filepath.SkipDir
+			}
+			// .git, plandex dirs, and caches and build output that tools can regenerate
+			if ShouldSkipDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		copyPaths = append(copyPaths, relPath)
+		return nil
+	})
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("error walking project: %v", err)
+	}
+
+	return copyPaths, linkPaths, nil
+}
+
+func copyScratchFile(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		// files deleted from the working tree are still listed by git
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error reading %s: %v", src, err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(dst), 0755)
+	if err != nil {
+		return fmt.Errorf("error creating dir for %s: %v", dst, err)
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(src)
+		if err != nil {
+			return fmt.Errorf("error reading link %s: %v", src, err)
+		}
+		return os.Symlink(target, dst)
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %v", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("error creating %s: %v", dst, err)
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		return fmt.Errorf("error copying %s: %v", src, err)
+	}
+
+	return nil
+}
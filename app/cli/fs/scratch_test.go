@@ -0,0 +1,159 @@
+package fs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newScratchTestRepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+
+	files := map[string]string{
+		".gitignore":              "dist/\nnode_modules/\n.cache/\n.env\n",
+		"main.go":                 "package main\n",
+		"old.go":                  "package main\n",
+		"dist/app.js":             "built\n",
+		"node_modules/dep/dep.js": "dep\n",
+		".cache/build":            "cache\n",
+		".env":                    "SECRET=1\n",
+	}
+	for path, content := range files {
+		err := os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0755)
+		if err == nil {
+			err = os.WriteFile(filepath.Join(root, path), []byte(content), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, args := range [][]string{{"init", "-q"}, {"add", "-A"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	return root
+}
+
+func TestCreateScratchCopy(t *testing.T) {
+	root := newScratchTestRepo(t)
+
+	dir, err := CreateScratchCopy(root, map[string]string{
+		"main.go":     "package main\n\nfunc main() {}\n",
+		"dist/app.js": "changed\n",
+		"pkg/new.go":  "package pkg\n",
+	}, map[string]bool{"old.go": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for path, want := range map[string]string{
+		"main.go":     "package main\n\nfunc main() {}\n",
+		"dist/app.js": "changed\n",
+		"pkg/new.go":  "package pkg\n",
+		".env":        "SECRET=1\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil || string(got) != want {
+			t.Errorf("%s: got %q (%v), want %q", path, got, err, want)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "old.go")); !os.IsNotExist(err) {
+		t.Errorf("old.go wasn't removed: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, ".cache")); !os.IsNotExist(err) {
+		t.Errorf(".cache was included: %v", err)
+	}
+
+	// ignored build output is copied, so writing to it leaves the project alone
+	info, err := os.Lstat(filepath.Join(dir, "dist"))
+	if err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("dist should be a copied dir: %v %v", info, err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(root, "dist/app.js")); string(got) != "built\n" {
+		t.Errorf("project's dist/app.js changed to %q", got)
+	}
+
+	// dependency dirs are linked
+	info, err = os.Lstat(filepath.Join(dir, "node_modules"))
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("node_modules should be linked: %v %v", info, err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "node_modules/dep/dep.js")); string(got) != "dep\n" {
+		t.Errorf("node_modules/dep/dep.js: got %q", got)
+	}
+}
+
+func TestCreateScratchCopyWritesThroughLinkedDir(t *testing.T) {
+	root := newScratchTestRepo(t)
+
+	dir, err := CreateScratchCopy(root, map[string]string{"node_modules/dep/dep.js": "patched\n"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if got, _ := os.ReadFile(filepath.Join(dir, "node_modules/dep/dep.js")); string(got) != "patched\n" {
+		t.Errorf("scratch node_modules/dep/dep.js: got %q", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(root, "node_modules/dep/dep.js")); string(got) != "dep\n" {
+		t.Errorf("project's node_modules/dep/dep.js changed to %q", got)
+	}
+}
+
+func TestCreateScratchCopyRejectsEscapingPaths(t *testing.T) {
+	root := newScratchTestRepo(t)
+	outside := t.TempDir()
+	target := filepath.Join(outside, "file")
+	err := os.WriteFile(target, []byte("keep\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"../file", "a/../../file", target, ".."} {
+		_, err := CreateScratchCopy(root, map[string]string{path: "overwritten\n"}, nil)
+		if err == nil || !strings.Contains(err.Error(), "outside the project") {
+			t.Errorf("writing %s: expected an error, got %v", path, err)
+		}
+
+		_, err = CreateScratchCopy(root, nil, map[string]bool{path: true})
+		if err == nil || !strings.Contains(err.Error(), "outside the project") {
+			t.Errorf("removing %s: expected an error, got %v", path, err)
+		}
+	}
+
+	if got, err := os.ReadFile(target); err != nil || string(got) != "keep\n" {
+		t.Errorf("file outside the project changed: %q %v", got, err)
+	}
+}
+
+func TestWalkScratchPaths(t *testing.T) {
+	root := newScratchTestRepo(t)
+
+	copyPaths, linkPaths, err := walkScratchPaths(root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	copied := strings.Join(copyPaths, ",")
+	for _, path := range []string{"main.go", filepath.Join("dist", "app.js"), ".env"} {
+		if !strings.Contains(copied, path) {
+			t.Errorf("%s wasn't copied: %v", path, copyPaths)
+		}
+	}
+	if strings.Contains(copied, ".git"+string(os.PathSeparator)) || strings.Contains(copied, ".cache") {
+		t.Errorf("skipped dirs were copied: %v", copyPaths)
+	}
+	if strings.Join(linkPaths, ",") != "node_modules" {
+		t.Errorf("got links %v, want [node_modules]", linkPaths)
+	}
+}
@@ -0,0 +1,481 @@
+package fs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plandex-cli/types"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	goModuleRegex      = regexp.MustCompile(`(?m)^module\s+"?([^\s"]+)"?`)
+	cargoNameRegex     = regexp.MustCompile(`(?m)^\s*name\s*=\s*"([^"]+)"`)
+	tomlStringRegex    = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+	bazelRootFileNames = []string{"MODULE.bazel", "WORKSPACE", "WORKSPACE.bazel"}
+)
+
+// GetWorkspacePackages finds the sub-projects of a monorepo from go.work, pnpm-workspace.yaml, package.json workspaces, Cargo workspaces, and Bazel BUILD files. A directory that's listed by more than one of these is only included once, from whichever is checked first.
+func GetWorkspacePackages(root string) ([]*types.WorkspacePackage, error) {
+	var res []*types.WorkspacePackage
+	seen := map[string]bool{}
+
+	add := func(pkgs []*types.WorkspacePackage) {
+		for _, pkg := range pkgs {
+			if pkg.Dir == "." || seen[pkg.Dir] {
+				continue
+			}
+			seen[pkg.Dir] = true
+			res = append(res, pkg)
+		}
+	}
+
+	detectors := []func(string) ([]*types.WorkspacePackage, error){
+		goWorkPackages,
+		pnpmPackages,
+		packageJsonPackages,
+		cargoPackages,
+		bazelPackages,
+	}
+
+	for _, detect := range detectors {
+		pkgs, err := detect(root)
+		if err != nil {
+			return nil, err
+		}
+		add(pkgs)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Dir < res[j].Dir
+	})
+
+	return res, nil
+}
+
+func goWorkPackages(root string) ([]*types.WorkspacePackage, error) {
+	bytes, err := os.ReadFile(filepath.Join(root, "go.work"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading go.work: %v", err)
+	}
+
+	var dirs []string
+	inUseBlock := false
+	for _, line := range strings.Split(string(bytes), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		line = strings.TrimSpace(line)
+
+		if inUseBlock {
+			if line == ")" {
+				inUseBlock = false
+			} else if line != "" {
+				dirs = append(dirs, strings.Trim(line, `"`))
+			}
+			continue
+		}
+
+		rest, ok := strings.CutPrefix(line, "use")
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t' && rest[0] != '(') {
+			continue
+		}
+		rest = strings.TrimSpace(rest)
+		if rest == "(" {
+			inUseBlock = true
+		} else if rest != "" {
+			dirs = append(dirs, strings.Trim(rest, `"`))
+		}
+	}
+
+	var res []*types.WorkspacePackage
+	for _, dir := range dirs {
+		dir, ok := workspaceRelDir(root, dir)
+		if !ok {
+			continue
+		}
+		name := dir
+		if bytes, err := os.ReadFile(filepath.Join(root, dir, "go.mod")); err == nil {
+			if m := goModuleRegex.FindSubmatch(bytes); m != nil {
+				name = string(m[1])
+			}
+		}
+		res = append(res, &types.WorkspacePackage{Name: name, Dir: dir, Kind: "go"})
+	}
+	return res, nil
+}
+
+func pnpmPackages(root string) ([]*types.WorkspacePackage, error) {
+	bytes, err := os.ReadFile(filepath.Join(root, "pnpm-workspace.yaml"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading pnpm-workspace.yaml: %v", err)
+	}
+
+	// only the top-level 'packages' list matters here, so this reads just that rather than parsing the yaml in full
+	var patterns []string
+	inPackages := false
+	for _, line := range strings.Split(string(bytes), "\n") {
+		line, _, _ = strings.Cut(line, " #")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if line[0] != ' ' && line[0] != '\t' && line[0] != '-' {
+			key, value, _ := strings.Cut(trimmed, ":")
+			inPackages = key == "packages"
+			if inPackages && strings.HasPrefix(strings.TrimSpace(value), "[") {
+				// flow style: packages: ['a/*', 'b']
+				for _, item := range strings.Split(strings.Trim(strings.TrimSpace(value), "[]"), ",") {
+					patterns = append(patterns, strings.Trim(strings.TrimSpace(item), `'"`))
+				}
+				inPackages = false
+			}
+			continue
+		}
+
+		if inPackages {
+			if item, ok := strings.CutPrefix(trimmed, "-"); ok {
+				patterns = append(patterns, strings.Trim(strings.TrimSpace(item), `'"`))
+			}
+		}
+	}
+
+	return npmStylePackages(root, patterns, "pnpm"), nil
+}
+
+func packageJsonPackages(root string) ([]*types.WorkspacePackage, error) {
+	bytes, err := os.ReadFile(filepath.Join(root, "package.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading package.json: %v", err)
+	}
+
+	var pkg struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := json.Unmarshal(bytes, &pkg); err != nil || len(pkg.Workspaces) == 0 {
+		// an invalid package.json isn't a workspace config problem, so it's left for the package manager to complain about
+		return nil, nil
+	}
+
+	// workspaces is either a list of patterns or, with yarn, an object with a 'packages' list
+	var patterns []string
+	if err := json.Unmarshal(pkg.Workspaces, &patterns); err != nil {
+		var obj struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(pkg.Workspaces, &obj); err != nil {
+			return nil, nil
+		}
+		patterns = obj.Packages
+	}
+
+	kind := "npm"
+	if _, err := os.Stat(filepath.Join(root, "yarn.lock")); err == nil {
+		kind = "yarn"
+	}
+
+	return npmStylePackages(root, patterns, kind), nil
+}
+
+func npmStylePackages(root string, patterns []string, kind string) []*types.WorkspacePackage {
+	var res []*types.WorkspacePackage
+	for _, dir := range expandWorkspacePatterns(root, patterns, "package.json") {
+		name := dir
+		if bytes, err := os.ReadFile(filepath.Join(root, dir, "package.json")); err == nil {
+			var pkg struct {
+				Name string `json:"name"`
+			}
+			if json.Unmarshal(bytes, &pkg) == nil && pkg.Name != "" {
+				name = pkg.Name
+			}
+		}
+		res = append(res, &types.WorkspacePackage{Name: name, Dir: dir, Kind: kind})
+	}
+	return res
+}
+
+func cargoPackages(root string) ([]*types.WorkspacePackage, error) {
+	bytes, err := os.ReadFile(filepath.Join(root, "Cargo.toml"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading Cargo.toml: %v", err)
+	}
+
+	section := tomlSection(string(bytes), "workspace")
+	if section == "" {
+		return nil, nil
+	}
+
+	patterns := tomlStringArray(section, "members")
+	for _, exclude := range tomlStringArray(section, "exclude") {
+		patterns = append(patterns, "!"+exclude)
+	}
+
+	var res []*types.WorkspacePackage
+	for _, dir := range expandWorkspacePatterns(root, patterns, "Cargo.toml") {
+		name := dir
+		if bytes, err := os.ReadFile(filepath.Join(root, dir, "Cargo.toml")); err == nil {
+			if m := cargoNameRegex.FindStringSubmatch(tomlSection(string(bytes), "package")); m != nil {
+				name = m[1]
+			}
+		}
+		res = append(res, &types.WorkspacePackage{Name: name, Dir: dir, Kind: "cargo"})
+	}
+	return res, nil
+}
+
+// bazelPackages lists every directory with a BUILD file, since in Bazel each of those is a package
+func bazelPackages(root string) ([]*types.WorkspacePackage, error) {
+	isBazel := false
+	for _, name := range bazelRootFileNames {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			isBazel = true
+			break
+		}
+	}
+	if !isBazel {
+		return nil, nil
+	}
+
+	var buildFiles []string
+	if IsGitRepo(root) {
+		cmd := exec.Command("git", "ls-files", "--cached", "--others", "--exclude-standard", "--", "*/BUILD", "*/BUILD.bazel")
+		cmd.Dir = root
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("error finding Bazel BUILD files: %v", err)
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				buildFiles = append(buildFiles, filepath.FromSlash(line))
+			}
+		}
+	} else {
+		err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && (ShouldSkipDir(d.Name()) || strings.HasPrefix(d.Name(), "bazel-")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Name() == "BUILD" || d.Name() == "BUILD.bazel" {
+				relPath, err := filepath.Rel(root, path)
+				if err != nil {
+					return err
+				}
+				buildFiles = append(buildFiles, relPath)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error finding Bazel BUILD files: %v", err)
+		}
+	}
+
+	var res []*types.WorkspacePackage
+	seen := map[string]bool{}
+	for _, buildFile := range buildFiles {
+		dir := filepath.Dir(buildFile)
+		if seen[dir] || IsInSkippedDir(buildFile) {
+			continue
+		}
+		seen[dir] = true
+		res = append(res, &types.WorkspacePackage{Name: "//" + filepath.ToSlash(dir), Dir: dir, Kind: "bazel"})
+	}
+	return res, nil
+}
+
+// expandWorkspacePatterns expands workspace globs like 'packages/*' or 'apps/**' into the directories they match that contain the given manifest. Patterns starting with '!' exclude directories.
+func expandWorkspacePatterns(root string, patterns []string, manifest string) []string {
+	included := map[string]bool{}
+	var excludes []string
+
+	for _, pattern := range patterns {
+		if exclude, ok := strings.CutPrefix(pattern, "!"); ok {
+			excludes = append(excludes, exclude)
+			continue
+		}
+		for _, dir := range globDirs(root, pattern) {
+			if _, err := os.Stat(filepath.Join(root, dir, manifest)); err == nil {
+				included[dir] = true
+			}
+		}
+	}
+
+	var res []string
+	for dir := range included {
+		excluded := false
+		for _, exclude := range excludes {
+			if matchWorkspacePattern(cleanWorkspacePattern(exclude), filepath.ToSlash(dir)) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			res = append(res, dir)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// globDirs returns the directories under root matching a slash-separated pattern, where '**' matches any number of directories
+func globDirs(root, pattern string) []string {
+	pattern = cleanWorkspacePattern(pattern)
+	if pattern == "" {
+		return nil
+	}
+
+	if !strings.ContainsAny(pattern, "*?[") {
+		dir, ok := workspaceRelDir(root, pattern)
+		if !ok {
+			return nil
+		}
+		return []string{dir}
+	}
+
+	var res []string
+	var walk func(rel string, segments []string)
+	walk = func(rel string, segments []string) {
+		if len(segments) == 0 {
+			res = append(res, rel)
+			return
+		}
+
+		entries, err := os.ReadDir(filepath.Join(root, rel))
+		if err != nil {
+			return
+		}
+
+		segment := segments[0]
+		if segment == "**" {
+			// zero directories
+			walk(rel, segments[1:])
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() || ShouldSkipDir(entry.Name()) {
+				continue
+			}
+			childRel := filepath.Join(rel, entry.Name())
+			if segment == "**" {
+				walk(childRel, segments)
+			} else if ok, _ := filepath.Match(segment, entry.Name()); ok {
+				walk(childRel, segments[1:])
+			}
+		}
+	}
+	walk(".", strings.Split(pattern, "/"))
+
+	return res
+}
+
+func matchWorkspacePattern(pattern, dir string) bool {
+	patternSegments := strings.Split(pattern, "/")
+	dirSegments := strings.Split(dir, "/")
+
+	var match func(p, d []string) bool
+	match = func(p, d []string) bool {
+		if len(p) == 0 {
+			return len(d) == 0
+		}
+		if p[0] == "**" {
+			for i := 0; i <= len(d); i++ {
+				if match(p[1:], d[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(d) == 0 {
+			return false
+		}
+		if ok, _ := filepath.Match(p[0], d[0]); !ok {
+			return false
+		}
+		return match(p[1:], d[1:])
+	}
+
+	return match(patternSegments, dirSegments)
+}
+
+func cleanWorkspacePattern(pattern string) string {
+	pattern = strings.TrimSpace(filepath.ToSlash(pattern))
+	pattern = strings.TrimPrefix(pattern, "./")
+	return strings.TrimSuffix(pattern, "/")
+}
+
+// workspaceRelDir cleans a workspace member path and checks that it's a directory inside root
+func workspaceRelDir(root, dir string) (string, bool) {
+	dir = filepath.Clean(filepath.FromSlash(dir))
+	if filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, ".."+string(os.PathSeparator)) {
+		return "", false
+	}
+	info, err := os.Stat(filepath.Join(root, dir))
+	if err != nil || !info.IsDir() {
+		return "", false
+	}
+	return dir, true
+}
+
+// tomlSection returns the body of a top-level [name] table, up to the next table header
+func tomlSection(toml, name string) string {
+	var b strings.Builder
+	inSection := false
+	for _, line := range strings.Split(toml, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			inSection = trimmed == "["+name+"]"
+			continue
+		}
+		if inSection {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// tomlStringArray reads a string array value, which may span several lines
+func tomlStringArray(section, key string) []string {
+	re := regexp.MustCompile(`(?m)^\s*` + regexp.QuoteMeta(key) + `\s*=\s*\[`)
+	loc := re.FindStringIndex(section)
+	if loc == nil {
+		return nil
+	}
+
+	rest := section[loc[1]:]
+	var body strings.Builder
+	for _, line := range strings.Split(rest, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if before, _, found := strings.Cut(line, "]"); found {
+			body.WriteString(before)
+			break
+		}
+		body.WriteString(line)
+		body.WriteString("\n")
+	}
+
+	var res []string
+	for _, m := range tomlStringRegex.FindAllStringSubmatch(body.String(), -1) {
+		if m[1] != "" {
+			res = append(res, m[1])
+		} else if m[2] != "" {
+			res = append(res, m[2])
+		}
+	}
+	return res
+}
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"plandex-cli/api"
 	"plandex-cli/auth"
+	"plandex-cli/execpolicy"
 	"plandex-cli/fs"
 	"plandex-cli/term"
 	"plandex-cli/types"
@@ -147,6 +148,11 @@ func MustApplyPlanAttempt(
 		return
 	}
 
+	if applyFlags.Branch != "" || applyFlags.Worktree != "" {
+		MustApplyToBranch(planId, branch, currentPlanState, applyFlags)
+		return
+	}
+
 	hasFileChanges := !hasExec || len(toApply) > 1
 
 	var toRollback *types.ApplyRollbackPlan
@@ -168,6 +174,11 @@ func MustApplyPlanAttempt(
 
 	log.Println("Has file changes:", hasFileChanges)
 
+	if hasExec && !noExec && params.ExecCommand == "" {
+		// loaded before any file changes are written, so changes to the project's policy file only apply to later runs
+		MustGetExecPolicy()
+	}
+
 	if hasFileChanges {
 		if !autoConfirm {
 			log.Println("Asking user to confirm applying changes")
@@ -281,13 +292,35 @@ func handleApplyScript(
 
 	fmt.Println(strings.TrimSpace(md))
 
+	// the exec policy only covers model-written scripts, not a command passed to 'plandex debug'
+	verdict := shared.ExecPolicyAllow
+	if params.ExecCommand == "" {
+		policy := MustGetExecPolicy()
+		if !policy.IsEmpty() {
+			results := policy.Evaluate(content)
+			verdict = execpolicy.Verdict(results)
+
+			fmt.Println()
+			color.New(term.ColorHiCyan, color.Bold).Println("🛡️  Exec policy")
+			PrintExecPolicyResults(results)
+			fmt.Println()
+		}
+	}
+
 	log.Println("Asking user to confirm executing apply script")
 
 	var confirmed bool
-	if params.ApplyFlags.AutoExec {
+	switch {
+	case verdict == shared.ExecPolicyDeny:
+		color.New(term.ColorHiRed, color.Bold).Println("⛔ Execution blocked by the exec policy")
+	case params.ApplyFlags.AutoExec && verdict == shared.ExecPolicyAllow:
 		confirmed = true
-	} else {
-		confirmed, err = term.ConfirmYesNo("Execute now?")
+	default:
+		msg := "Execute now?"
+		if params.ApplyFlags.AutoExec {
+			msg = "Some commands need confirmation under the exec policy. Execute now?"
+		}
+		confirmed, err = term.ConfirmYesNo(msg)
 		if err != nil {
 			onErr("failed to get confirmation user input: %s", err)
 		}
@@ -371,10 +404,13 @@ func execApplyScript(
 		filteredLines = append(filteredLines, line)
 	}
 
+	config := MustGetCurrentPlanConfig()
+
 	// Detect shell
 	shell := os.Getenv("SHELL")
-	if shell == "" {
-		shell = "/bin/bash" // fallback
+	if shell == "" || config.GetExecSandbox() == shared.ExecSandboxContainer {
+		// fallback, and the container sandbox runs the script with the image's bash rather than the host shell
+		shell = "/bin/bash"
 	}
 
 	// Get appropriate header
@@ -396,9 +432,12 @@ func execApplyScript(
 		onErr("failed to write _apply.sh: %s", err)
 	}
 
-	execCmd := exec.Command(shell, "-c", scriptPath)
-	execCmd.Dir = fs.ProjectRoot
-	execCmd.Env = os.Environ()
+	execCmd, err := applyScriptCmd(config, shell, scriptPath)
+	if err != nil {
+		// best effort cleanup
+		os.Remove(scriptPath)
+		onErr("failed to set up exec sandbox: %s", err)
+	}
 	execCmd.Stdin = os.Stdin
 
 	// Create a pipe for both stdout and stderr
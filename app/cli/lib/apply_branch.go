@@ -0,0 +1,379 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"plandex-cli/fs"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+const gitNullSha = "0000000000000000000000000000000000000000"
+
+// branchApply is a commit's tree built from the plan's changes on top of HEAD, in a temporary index so the user's checkout is never touched
+type branchApply struct {
+	repoRoot string
+	// the project's dir within the repo, like 'services/api/' -- empty at the repo root
+	prefix   string
+	parent   string
+	tree     string
+	branch   string
+	worktree string
+
+	updatedFiles []string
+}
+
+// MustApplyToBranch commits the plan's pending changes to a new branch, and checks it out in a new linked worktree if one is given, leaving the current checkout as it is. Everything that can fail is checked before the server marks the changes applied.
+func MustApplyToBranch(planId, branch string, currentPlanState *shared.CurrentPlanState, applyFlags types.ApplyFlags) {
+	toApply := currentPlanState.CurrentPlanFiles.Files
+	toRemove := currentPlanState.CurrentPlanFiles.Removed
+
+	if !fs.ProjectRootIsGitRepo() {
+		term.StopSpinner()
+		term.OutputErrorAndExit("--branch and --worktree need the project to be in a git repository")
+	}
+
+	// the server marks the script applied along with the files, so its commands could never be run afterward
+	if strings.TrimSpace(toApply["_apply.sh"]) != "" {
+		term.StopSpinner()
+		term.OutputErrorAndExit("The plan has pending commands that would be marked applied without running. Apply to the project with 'plandex apply' to run them, or drop them with 'plandex reject _apply.sh' first.")
+	}
+
+	ba, err := prepareBranchApply(applyFlags.Branch, applyFlags.Worktree, branchApplyPaths(toApply, toRemove))
+	if err != nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("%v", err)
+	}
+
+	err = ba.buildTree(toApply, toRemove)
+	if err != nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("Error building commit: %v", err)
+	}
+
+	if len(ba.updatedFiles) == 0 {
+		term.StopSpinner()
+		fmt.Println("🤷‍♂️ The pending changes match the current commit, so there's nothing to commit to a new branch")
+		return
+	}
+
+	commitSummary, err := apiApplyPlan(planId, branch)
+	if err != nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("apply plan server error: %s", err)
+	}
+
+	msg := currentPlanState.PendingChangesSummaryForApply(commitSummary)
+
+	// the changes are marked applied now, so on failure, say how to recover the commit's contents
+	err = ba.commit(msg)
+	term.StopSpinner()
+	if err != nil {
+		term.OutputErrorAndExit("Error creating branch %s: %v\n\nThe changes were marked applied. Their files are in git tree %s -- recover them with 'git read-tree %s' or 'git archive %s'.", ba.branch, err, ba.tree, ba.tree, ba.tree)
+	}
+
+	suffix := ""
+	if len(ba.updatedFiles) > 1 {
+		suffix = "s"
+	}
+	fmt.Printf("✅ Committed changes to new branch %s, %d file%s updated\n", color.New(color.Bold, term.ColorHiCyan).Sprint(ba.branch), len(ba.updatedFiles), suffix)
+	for _, file := range ba.updatedFiles {
+		fmt.Println(" • 📄 " + file)
+	}
+	fmt.Println()
+
+	if ba.worktree != "" {
+		fmt.Printf("🌳 Checked out in a new worktree at %s\n", ba.worktreeProjectDir())
+	}
+	fmt.Println("Your current checkout wasn't changed.")
+
+	fmt.Println()
+	fmt.Printf("To run CI on it, push it with %s\n", color.New(color.Bold, term.ColorHiCyan).Sprintf("git push -u origin %s", ba.branch))
+}
+
+func prepareBranchApply(branch, worktree string, paths []string) (*branchApply, error) {
+	repoRoot, err := gitRun(fs.ProjectRoot, nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := gitRun(fs.ProjectRoot, nil, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+
+	ba := &branchApply{repoRoot: repoRoot, prefix: prefix}
+
+	if worktree != "" {
+		ba.worktree, err = filepath.Abs(worktree)
+		if err != nil {
+			return nil, fmt.Errorf("invalid worktree dir: %v", err)
+		}
+
+		entries, err := os.ReadDir(ba.worktree)
+		if err == nil && len(entries) > 0 {
+			return nil, fmt.Errorf("worktree dir %s already exists and isn't empty", worktree)
+		} else if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error checking worktree dir: %v", err)
+		}
+
+		// like 'git worktree add', the branch is named after the dir by default
+		if branch == "" {
+			branch = filepath.Base(ba.worktree)
+		}
+	}
+
+	_, err = gitRun(repoRoot, nil, "check-ref-format", "--branch", branch)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' isn't a valid branch name", branch)
+	}
+	_, err = gitRun(repoRoot, nil, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	if err == nil {
+		return nil, fmt.Errorf("branch %s already exists", branch)
+	}
+	ba.branch = branch
+
+	// commit-tree needs a name and email, and it's too late to find out they're missing after the changes are applied
+	_, err = gitRun(repoRoot, nil, "var", "GIT_COMMITTER_IDENT")
+	if err != nil {
+		return nil, fmt.Errorf("git needs a user name and email to commit -- set them with 'git config user.name' and 'git config user.email'")
+	}
+
+	// an empty repo has no HEAD to build on
+	ba.parent, _ = gitRun(repoRoot, nil, "rev-parse", "--verify", "--quiet", "HEAD^{commit}")
+
+	uncommitted, err := ba.uncommittedPaths(paths)
+	if err != nil {
+		return nil, fmt.Errorf("error checking for uncommitted changes: %v", err)
+	}
+	if len(uncommitted) > 0 {
+		return nil, fmt.Errorf("the plan changes files with uncommitted changes, which would be committed to the branch along with the plan's changes:\n\n • %s\n\nCommit or stash them first, or apply to the project with 'plandex apply'", strings.Join(uncommitted, "\n • "))
+	}
+
+	return ba, nil
+}
+
+// branchApplyPaths lists the project paths that a branch apply writes or removes
+func branchApplyPaths(toApply map[string]string, toRemove map[string]bool) []string {
+	var paths []string
+	for p := range toApply {
+		if p != "_apply.sh" {
+			paths = append(paths, p)
+		}
+	}
+	for p, remove := range toRemove {
+		if remove {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// uncommittedPaths lists the paths that differ from HEAD in the index or working tree, or that exist but aren't tracked. The plan's files are built on the working tree's versions, so their uncommitted changes would end up in the branch's commit.
+func (ba *branchApply) uncommittedPaths(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	var repoPaths []string
+	for _, p := range paths {
+		repoPaths = append(repoPaths, ba.repoPath(p))
+	}
+
+	// plan paths are file names, not patterns
+	env := []string{"GIT_LITERAL_PATHSPECS=1"}
+
+	var out []string
+	if ba.parent != "" {
+		changed, err := gitRun(ba.repoRoot, env, append([]string{"diff", "--name-only", "-z", "--no-renames", "HEAD", "--"}, repoPaths...)...)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, strings.Split(changed, "\x00")...)
+	}
+	untracked, err := gitRun(ba.repoRoot, env, append([]string{"ls-files", "--others", "-z", "--"}, repoPaths...)...)
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, strings.Split(untracked, "\x00")...)
+
+	var res []string
+	for _, repoPath := range out {
+		if repoPath != "" {
+			res = append(res, strings.TrimPrefix(repoPath, filepath.ToSlash(ba.prefix)))
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// buildTree writes the plan's files as objects and builds a tree with them on top of HEAD
+func (ba *branchApply) buildTree(toApply map[string]string, toRemove map[string]bool) error {
+	indexDir, err := os.MkdirTemp("", "plandex-apply-index-*")
+	if err != nil {
+		return fmt.Errorf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(indexDir)
+
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(indexDir, "index")}
+
+	var repoPaths []string
+	for _, p := range branchApplyPaths(toApply, toRemove) {
+		repoPaths = append(repoPaths, ba.repoPath(p))
+	}
+	if len(repoPaths) == 0 {
+		return nil
+	}
+
+	// modes and object ids of the paths in HEAD, to keep executable bits and skip unchanged files
+	headEntries := map[string][2]string{}
+	if ba.parent != "" {
+		_, err = gitRun(ba.repoRoot, env, "read-tree", ba.parent)
+		if err != nil {
+			return err
+		}
+
+		// plan paths are file names, not patterns
+		literalEnv := append([]string{"GIT_LITERAL_PATHSPECS=1"}, env...)
+		out, err := gitRun(ba.repoRoot, literalEnv, append([]string{"ls-files", "--stage", "-z", "--"}, repoPaths...)...)
+		if err != nil {
+			return err
+		}
+		for _, entry := range strings.Split(out, "\x00") {
+			// '<mode> <sha> <stage>\t<path>'
+			meta, repoPath, ok := strings.Cut(entry, "\t")
+			fields := strings.Fields(meta)
+			if !ok || len(fields) < 2 {
+				continue
+			}
+			headEntries[repoPath] = [2]string{fields[0], fields[1]}
+		}
+	}
+
+	var indexInfo strings.Builder
+	var updated []string
+
+	for p, content := range toApply {
+		if p == "_apply.sh" {
+			continue
+		}
+		repoPath := ba.repoPath(p)
+
+		content = strings.ReplaceAll(content, "\\`\\`\\`", "```")
+		sha, err := gitRunInput(ba.repoRoot, env, content, "hash-object", "-w", "--stdin", "--path", repoPath)
+		if err != nil {
+			return err
+		}
+
+		mode := "100644"
+		if head, ok := headEntries[repoPath]; ok {
+			if head[1] == sha {
+				continue
+			}
+			if head[0] == "100755" {
+				mode = head[0]
+			}
+		}
+
+		fmt.Fprintf(&indexInfo, "%s %s\t%s\n", mode, sha, repoPath)
+		updated = append(updated, p)
+	}
+
+	for p, remove := range toRemove {
+		if !remove {
+			continue
+		}
+		repoPath := ba.repoPath(p)
+		if _, ok := headEntries[repoPath]; !ok {
+			continue
+		}
+		fmt.Fprintf(&indexInfo, "0 %s\t%s\n", gitNullSha, repoPath)
+		updated = append(updated, p)
+	}
+
+	sort.Strings(updated)
+	ba.updatedFiles = updated
+
+	if len(updated) == 0 {
+		return nil
+	}
+
+	_, err = gitRunInput(ba.repoRoot, env, indexInfo.String(), "update-index", "--index-info")
+	if err != nil {
+		return err
+	}
+
+	ba.tree, err = gitRun(ba.repoRoot, env, "write-tree")
+	return err
+}
+
+func (ba *branchApply) commit(msg string) error {
+	args := []string{"commit-tree", ba.tree, "-m", msg}
+	if ba.parent != "" {
+		args = append(args, "-p", ba.parent)
+	}
+	commitSha, err := gitRun(ba.repoRoot, nil, args...)
+	if err != nil {
+		return err
+	}
+
+	// the empty old value makes this fail if the branch was created in the meantime
+	_, err = gitRun(ba.repoRoot, nil, "update-ref", "-m", "plandex apply --branch", "refs/heads/"+ba.branch, commitSha, "")
+	if err != nil {
+		return err
+	}
+
+	if ba.worktree != "" {
+		_, err = gitRun(ba.repoRoot, nil, "worktree", "add", ba.worktree, ba.branch)
+		if err != nil {
+			return fmt.Errorf("branch was created, but checking it out in a worktree failed: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (ba *branchApply) repoPath(projectPath string) string {
+	return path.Join(filepath.ToSlash(ba.prefix), filepath.ToSlash(projectPath))
+}
+
+// worktreeProjectDir is the project's dir within the new worktree, which is a subdir if the project isn't at the repo root
+func (ba *branchApply) worktreeProjectDir() string {
+	return filepath.Join(ba.worktree, filepath.FromSlash(ba.prefix))
+}
+
+func gitRun(dir string, env []string, args ...string) (string, error) {
+	return gitRunInput(dir, env, "", args...)
+}
+
+// gitRunInput runs a git command in dir with extra env vars and optional stdin, returning its trimmed output
+func gitRunInput(dir string, env []string, input string, args ...string) (string, error) {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), env...)
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error running git %s | err: %v, output: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
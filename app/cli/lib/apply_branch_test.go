@@ -0,0 +1,297 @@
+package lib
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plandex-cli/fs"
+	"strings"
+	"testing"
+)
+
+// newBranchTestRepo creates a repo with a commit and points the project root at dir within it
+func newBranchTestRepo(t *testing.T, dir string, files map[string]string, executable ...string) string {
+	t.Helper()
+
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	repo := t.TempDir()
+	mustGit(t, repo, "init", "-q", "-b", "main")
+
+	for path, content := range files {
+		err := os.MkdirAll(filepath.Join(repo, filepath.Dir(path)), 0755)
+		if err == nil {
+			err = os.WriteFile(filepath.Join(repo, path), []byte(content), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, path := range executable {
+		if err := os.Chmod(filepath.Join(repo, path), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(files) > 0 {
+		mustGit(t, repo, "add", "-A")
+		mustGit(t, repo, "commit", "-q", "-m", "initial")
+	}
+
+	origRoot := fs.ProjectRoot
+	fs.ProjectRoot = filepath.Join(repo, dir)
+	t.Cleanup(func() { fs.ProjectRoot = origRoot })
+
+	return repo
+}
+
+func mustGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestApplyToBranchSubdir(t *testing.T) {
+	repo := newBranchTestRepo(t, "app", map[string]string{
+		"README.md":   "readme\n",
+		"app/main.go": "package main\n",
+		"app/old.go":  "package main\n",
+		"app/run.sh":  "#!/bin/sh\necho run\n",
+		"app/same.go": "package main\n",
+	}, "app/run.sh")
+	head := mustGit(t, repo, "rev-parse", "HEAD")
+
+	ba, err := prepareBranchApply("plandex/test", "", []string{"main.go", "missing.go", "old.go", "pkg/new.go", "run.sh", "same.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ba.prefix != "app/" || ba.parent != head {
+		t.Fatalf("got prefix %q and parent %q", ba.prefix, ba.parent)
+	}
+
+	err = ba.buildTree(map[string]string{
+		"main.go":    "package main\n\nfunc main() {}\n",
+		"run.sh":     "#!/bin/sh\necho changed\n",
+		"same.go":    "package main\n",
+		"pkg/new.go": "package pkg\n",
+		"_apply.sh":  "go test ./...\n",
+	}, map[string]bool{"old.go": true, "missing.go": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"main.go", "old.go", "pkg/new.go", "run.sh"}
+	if strings.Join(ba.updatedFiles, ",") != strings.Join(want, ",") {
+		t.Fatalf("got updated files %v, want %v", ba.updatedFiles, want)
+	}
+
+	err = ba.commit("test commit")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree := mustGit(t, repo, "ls-tree", "-r", "plandex/test")
+	for _, entry := range []string{"100644 blob", "\tREADME.md", "\tapp/main.go", "\tapp/pkg/new.go", "\tapp/same.go", "100755 blob"} {
+		if !strings.Contains(tree, entry) {
+			t.Errorf("tree is missing %q:\n%s", entry, tree)
+		}
+	}
+	for _, line := range strings.Split(tree, "\n") {
+		if strings.HasSuffix(line, "\tapp/run.sh") && !strings.HasPrefix(line, "100755") {
+			t.Errorf("run.sh lost its executable bit: %s", line)
+		}
+		if strings.HasSuffix(line, "\tapp/old.go") || strings.HasSuffix(line, "_apply.sh") {
+			t.Errorf("unexpected entry: %s", line)
+		}
+	}
+
+	if got := mustGit(t, repo, "show", "plandex/test:app/main.go"); got != "package main\n\nfunc main() {}" {
+		t.Errorf("got app/main.go %q", got)
+	}
+	if got := mustGit(t, repo, "rev-parse", "plandex/test^"); got != head {
+		t.Errorf("branch parent is %s, want %s", got, head)
+	}
+
+	// the checkout is left alone
+	if got := mustGit(t, repo, "rev-parse", "HEAD"); got != head {
+		t.Errorf("HEAD moved to %s", got)
+	}
+	if got := mustGit(t, repo, "status", "--porcelain"); got != "" {
+		t.Errorf("working tree changed:\n%s", got)
+	}
+}
+
+func TestApplyToBranchUnchanged(t *testing.T) {
+	newBranchTestRepo(t, "", map[string]string{"main.go": "package main\n"})
+
+	ba, err := prepareBranchApply("plandex/test", "", []string{"main.go", "missing.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ba.buildTree(map[string]string{"main.go": "package main\n"}, map[string]bool{"missing.go": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ba.updatedFiles) != 0 || ba.tree != "" {
+		t.Errorf("expected no changes, got %v and tree %q", ba.updatedFiles, ba.tree)
+	}
+}
+
+func TestApplyToBranchEmptyRepo(t *testing.T) {
+	repo := newBranchTestRepo(t, "", nil)
+
+	ba, err := prepareBranchApply("plandex/test", "", []string{"main.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ba.buildTree(map[string]string{"main.go": "package main\n"}, nil)
+	if err == nil {
+		err = ba.commit("first commit")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := mustGit(t, repo, "show", "plandex/test:main.go"); got != "package main" {
+		t.Errorf("got main.go %q", got)
+	}
+	if got := mustGit(t, repo, "rev-list", "--count", "plandex/test"); got != "1" {
+		t.Errorf("expected a root commit, got %s commits", got)
+	}
+}
+
+func TestApplyToBranchWorktree(t *testing.T) {
+	repo := newBranchTestRepo(t, "app", map[string]string{"app/main.go": "package main\n"})
+	worktree := filepath.Join(t.TempDir(), "feature")
+
+	ba, err := prepareBranchApply("", worktree, []string{"main.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ba.branch != "feature" {
+		t.Errorf("branch should be named after the worktree dir, got %s", ba.branch)
+	}
+
+	err = ba.buildTree(map[string]string{"main.go": "package main\n\nfunc main() {}\n"}, nil)
+	if err == nil {
+		err = ba.commit("test commit")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(ba.worktreeProjectDir(), "main.go"))
+	if err != nil || string(got) != "package main\n\nfunc main() {}\n" {
+		t.Errorf("worktree main.go: got %q (%v)", got, err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(repo, "app", "main.go")); string(got) != "package main\n" {
+		t.Errorf("checkout's main.go changed to %q", got)
+	}
+}
+
+func TestApplyToBranchWorktreeAddFails(t *testing.T) {
+	repo := newBranchTestRepo(t, "", map[string]string{"main.go": "package main\n"})
+	worktree := filepath.Join(t.TempDir(), "feature")
+
+	ba, err := prepareBranchApply("plandex/test", worktree, []string{"main.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ba.buildTree(map[string]string{"main.go": "package main\n\nfunc main() {}\n"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the dir fills up after the checks, so 'git worktree add' refuses it
+	err = os.MkdirAll(worktree, 0755)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(worktree, "file"), []byte("x"), 0644)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ba.commit("test commit")
+	if err == nil || !strings.Contains(err.Error(), "checking it out in a worktree failed") {
+		t.Fatalf("expected a worktree error, got %v", err)
+	}
+
+	// the branch is still there with the changes
+	if got := mustGit(t, repo, "show", "plandex/test:main.go"); got != "package main\n\nfunc main() {}" {
+		t.Errorf("got main.go %q", got)
+	}
+}
+
+func TestPrepareBranchApplyErrors(t *testing.T) {
+	newBranchTestRepo(t, "", map[string]string{"main.go": "package main\n"})
+
+	nonEmpty := t.TempDir()
+	err := os.WriteFile(filepath.Join(nonEmpty, "file"), []byte("x"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		branch, worktree, want string
+	}{
+		{"main", "", "already exists"},
+		{"bad..name", "", "isn't a valid branch name"},
+		{"plandex/test", nonEmpty, "isn't empty"},
+	}
+
+	for _, tt := range tests {
+		_, err := prepareBranchApply(tt.branch, tt.worktree, nil)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("branch %q, worktree %q: got %v, want an error with %q", tt.branch, tt.worktree, err, tt.want)
+		}
+	}
+}
+
+func TestPrepareBranchApplyUncommitted(t *testing.T) {
+	repo := newBranchTestRepo(t, "app", map[string]string{
+		"app/clean.go":  "package main\n",
+		"app/main.go":   "package main\n",
+		"app/staged.go": "package main\n",
+		"other.go":      "package main\n",
+	})
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("app/main.go", "package main\n\n// wip\n")
+	write("app/staged.go", "package main\n\n// staged\n")
+	mustGit(t, repo, "add", "app/staged.go")
+	write("app/untracked.go", "package main\n")
+	// changes outside the plan's paths don't matter
+	write("other.go", "package other\n")
+
+	_, err := prepareBranchApply("plandex/test", "", []string{"clean.go", "missing.go"})
+	if err != nil {
+		t.Fatalf("unexpected error for clean paths: %v", err)
+	}
+
+	_, err = prepareBranchApply("plandex/test", "", []string{"clean.go", "main.go", "missing.go", "staged.go", "untracked.go"})
+	if err == nil {
+		t.Fatal("expected an error for uncommitted changes")
+	}
+	for _, want := range []string{"uncommitted changes", " • main.go\n • staged.go\n • untracked.go\n"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error is missing %q: %v", want, err)
+		}
+	}
+	for _, notWant := range []string{"clean.go", "missing.go", "other.go"} {
+		if strings.Contains(err.Error(), notWant) {
+			t.Errorf("error shouldn't mention %s: %v", notWant, err)
+		}
+	}
+}
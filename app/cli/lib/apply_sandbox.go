@@ -0,0 +1,240 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"plandex-cli/fs"
+	"runtime"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+// env vars that commands need to behave normally and that don't hold credentials -- these are always passed through
+var baseExecEnvVars = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "LANG", "LC_*", "TERM", "COLORTERM", "NO_COLOR", "FORCE_COLOR", "TZ", "TMPDIR",
+}
+
+// base env vars that only make sense on the host, so they aren't passed into a container
+var hostOnlyEnvVars = map[string]bool{
+	"PATH": true, "HOME": true, "SHELL": true, "TMPDIR": true,
+}
+
+// toolchains are commonly installed under the home dir, which the bwrap sandbox hides -- these are bound back read-only if they exist
+var sandboxToolchainDirs = []string{
+	"go", ".cargo", ".rustup", ".nvm", ".pyenv", ".rbenv", ".local/bin", ".local/share/pnpm", ".bun", ".deno", ".sdkman", ".volta", ".asdf",
+}
+
+// applyScriptCmd builds the command that runs the apply script, in the plan's exec sandbox if it has one
+func applyScriptCmd(config *shared.PlanConfig, shell, scriptPath string) (*exec.Cmd, error) {
+	command := scriptPath
+	if config.GetExecSandbox() == shared.ExecSandboxContainer {
+		command = "./" + filepath.Base(scriptPath)
+	}
+	return sandboxCmd(context.Background(), config, fs.ProjectRoot, fs.ProjectRoot, nil, shell, command)
+}
+
+// sandboxCmd builds a command that runs a shell command in dir, in the plan's exec sandbox if it has one. In a sandbox, root is writable apart from its read-only paths, and the readOnly dirs can be read. The container sandbox always uses the image's bash.
+func sandboxCmd(ctx context.Context, config *shared.PlanConfig, root, dir string, readOnly []string, shell, command string) (*exec.Cmd, error) {
+	env := execEnv(config)
+
+	var cmd *exec.Cmd
+
+	switch config.GetExecSandbox() {
+	case shared.ExecSandboxNone:
+		cmd = exec.CommandContext(ctx, shell, "-c", command)
+
+	case shared.ExecSandboxBwrap:
+		args, err := bwrapArgs(config, root, dir, readOnly)
+		if err != nil {
+			return nil, err
+		}
+		cmd = exec.CommandContext(ctx, "bwrap", append(args, shell, "-c", command)...)
+
+	case shared.ExecSandboxContainer:
+		containerRuntime, args, err := containerArgs(config, env, root, dir, readOnly)
+		if err != nil {
+			return nil, err
+		}
+		cmd = exec.CommandContext(ctx, containerRuntime, append(args, "/bin/bash", "-c", command)...)
+		// the runtime itself needs the full environment (DOCKER_HOST and so on) -- only the filtered vars are passed into the container
+		env = os.Environ()
+
+	default:
+		return nil, fmt.Errorf("unknown exec sandbox '%s' -- use 'plandex set-config exec-sandbox' to choose none, bwrap, or container", config.ExecSandbox)
+	}
+
+	cmd.Dir = dir
+	cmd.Env = env
+
+	return cmd, nil
+}
+
+// execEnv filters the environment by the plan's env allowlist. Without an allowlist, sandboxed commands only get the base env vars and unsandboxed commands get the full environment as before.
+func execEnv(config *shared.PlanConfig) []string {
+	environ := os.Environ()
+
+	if len(config.ExecEnvAllowlist) == 0 && config.GetExecSandbox() == shared.ExecSandboxNone {
+		return environ
+	}
+
+	patterns := append(append([]string{}, baseExecEnvVars...), config.ExecEnvAllowlist...)
+
+	var res []string
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				res = append(res, kv)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// bwrapArgs sets up a bubblewrap sandbox with its own namespaces: the filesystem is read-only apart from the project dir (less its .git dir and plandex config files) and a fresh /tmp, /run is emptied, the home dir is hidden apart from toolchains, and the network is off unless enabled
+func bwrapArgs(config *shared.PlanConfig, root, dir string, readOnly []string) ([]string, error) {
+	if runtime.GOOS != "linux" {
+		return nil, fmt.Errorf("the bwrap exec sandbox only works on Linux -- use the container sandbox instead")
+	}
+	if _, err := exec.LookPath("bwrap"); err != nil {
+		return nil, fmt.Errorf("the bwrap exec sandbox needs bubblewrap installed (the 'bubblewrap' package on most distros)")
+	}
+
+	args := []string{
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+	}
+
+	// unix sockets like /var/run/docker.sock and /run/user/$UID/bus can still be connected to on a read-only mount and across namespaces, so the dirs that hold them are replaced with empty ones
+	args = append(args, sandboxRunDirArgs()...)
+
+	if fs.HomeDir != "" {
+		args = append(args, "--tmpfs", fs.HomeDir)
+		for _, name := range sandboxToolchainDirs {
+			toolchainDir := filepath.Join(fs.HomeDir, name)
+			if _, err := os.Stat(toolchainDir); err == nil {
+				args = append(args, "--ro-bind", toolchainDir, toolchainDir)
+			}
+		}
+	}
+
+	for _, path := range readOnly {
+		args = append(args, "--ro-bind", path, path)
+	}
+
+	// the project dir is bound last so it's writable even when it's inside the home dir
+	args = append(args, bwrapProjectArgs(root)...)
+	args = append(args,
+		"--chdir", dir,
+		"--unshare-all",
+		"--die-with-parent",
+	)
+
+	if config.ExecSandboxNetwork {
+		args = append(args, "--share-net")
+	}
+
+	return args, nil
+}
+
+// sandboxReadOnlyPaths are the paths in the project that a sandboxed script can't write. Git runs hooks and commands from its config (like core.fsmonitor) on the host, and the policy and verify files decide what runs next, so a script that could change them could get out of the sandbox. Only existing paths are covered, since mounting over a missing one would create it in the project.
+func sandboxReadOnlyPaths(root string) []string {
+	var res []string
+	for _, name := range []string{".git", ExecPolicyFile, VerifyConfigFile} {
+		path := filepath.Join(root, name)
+		if _, err := os.Lstat(path); err == nil {
+			res = append(res, path)
+		}
+	}
+	return res
+}
+
+// bwrapProjectArgs binds the project dir writable, then the read-only paths over it
+func bwrapProjectArgs(root string) []string {
+	args := []string{"--bind", root, root}
+	for _, path := range sandboxReadOnlyPaths(root) {
+		args = append(args, "--ro-bind", path, path)
+	}
+	return args
+}
+
+// containerProjectArgs mounts the project dir writable, then the read-only paths over it
+func containerProjectArgs(root string) []string {
+	args := []string{"-v", root + ":" + root}
+	for _, path := range sandboxReadOnlyPaths(root) {
+		args = append(args, "-v", path+":"+path+":ro")
+	}
+	return args
+}
+
+// sandboxRunDirArgs covers /run and /var/run with fresh tmpfs mounts. /var/run is usually a symlink to /run, in which case covering /run is enough.
+func sandboxRunDirArgs() []string {
+	var args []string
+	for _, dir := range []string{"/run", "/var/run"} {
+		info, err := os.Lstat(dir)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		args = append(args, "--tmpfs", dir)
+	}
+	return args
+}
+
+// containerArgs runs the script in a throwaway container with only the project dir mounted (its .git dir and plandex config files read-only), as the current user so files it writes aren't owned by root
+func containerArgs(config *shared.PlanConfig, env []string, root, dir string, readOnly []string) (string, []string, error) {
+	var containerRuntime string
+	for _, name := range []string{"docker", "podman"} {
+		if _, err := exec.LookPath(name); err == nil {
+			containerRuntime = name
+			break
+		}
+	}
+	if containerRuntime == "" {
+		return "", nil, fmt.Errorf("the container exec sandbox needs docker or podman installed")
+	}
+
+	args := []string{
+		"run", "--rm", "-i", "--init",
+	}
+	for _, path := range readOnly {
+		args = append(args, "-v", path+":"+path+":ro")
+	}
+	args = append(args, containerProjectArgs(root)...)
+	args = append(args,
+		"-w", dir,
+		// the user has no home dir in the image
+		"-e", "HOME=/tmp",
+	)
+
+	if containerRuntime == "podman" {
+		args = append(args, "--userns=keep-id")
+	} else if runtime.GOOS != "windows" {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()))
+	}
+
+	if config.ExecSandboxNetwork {
+		args = append(args, "--network", "bridge")
+	} else {
+		args = append(args, "--network", "none")
+	}
+
+	// passed by name so values are read from the runtime's env rather than showing up in its args
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if !hostOnlyEnvVars[name] {
+			args = append(args, "-e", name)
+		}
+	}
+
+	args = append(args, config.GetExecSandboxImage())
+
+	return containerRuntime, args, nil
+}
@@ -0,0 +1,98 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestSandboxRunDirArgs(t *testing.T) {
+	args := strings.Join(sandboxRunDirArgs(), " ")
+
+	for _, dir := range []string{"/run", "/var/run"} {
+		info, err := os.Lstat(dir)
+		covered := strings.Contains(" "+args+" ", " --tmpfs "+dir+" ")
+		switch {
+		case err == nil && info.IsDir() && !covered:
+			t.Errorf("%s isn't covered: %s", dir, args)
+		case (err != nil || !info.IsDir()) && covered:
+			// a symlink is covered through its target -- mounting over it would follow it out of the sandbox root
+			t.Errorf("%s isn't a dir but was covered: %s", dir, args)
+		}
+	}
+}
+
+func TestExecEnv(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("SECRET_TOKEN", "secret")
+	t.Setenv("CI_JOB", "1")
+
+	has := func(env []string, name string) bool {
+		for _, kv := range env {
+			if strings.HasPrefix(kv, name+"=") {
+				return true
+			}
+		}
+		return false
+	}
+
+	// unsandboxed commands get everything unless there's an allowlist
+	env := execEnv(&shared.PlanConfig{})
+	if !has(env, "SECRET_TOKEN") {
+		t.Error("unsandboxed commands should get the full environment")
+	}
+
+	env = execEnv(&shared.PlanConfig{ExecSandbox: shared.ExecSandboxBwrap})
+	if !has(env, "PATH") || !has(env, "LC_ALL") || has(env, "SECRET_TOKEN") || has(env, "CI_JOB") {
+		t.Errorf("sandboxed commands should only get the base env: %v", env)
+	}
+
+	env = execEnv(&shared.PlanConfig{ExecEnvAllowlist: []string{"CI_*"}})
+	if !has(env, "PATH") || !has(env, "CI_JOB") || has(env, "SECRET_TOKEN") {
+		t.Errorf("the allowlist wasn't applied: %v", env)
+	}
+}
+
+func TestSandboxProjectArgs(t *testing.T) {
+	root := t.TempDir()
+	for _, path := range []string{".git/hooks", "src"} {
+		if err := os.MkdirAll(filepath.Join(root, path), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, ExecPolicyFile), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	gitDir := filepath.Join(root, ".git")
+	policyFile := filepath.Join(root, ExecPolicyFile)
+
+	// the read-only binds have to come after the project's, or it would mount over them
+	bwrap := strings.Join(bwrapProjectArgs(root), " ")
+	want := strings.Join([]string{"--bind", root, root, "--ro-bind", gitDir, gitDir, "--ro-bind", policyFile, policyFile}, " ")
+	if bwrap != want {
+		t.Errorf("got bwrap args %q, want %q", bwrap, want)
+	}
+
+	container := strings.Join(containerProjectArgs(root), " ")
+	want = strings.Join([]string{"-v", root + ":" + root, "-v", gitDir + ":" + gitDir + ":ro", "-v", policyFile + ":" + policyFile + ":ro"}, " ")
+	if container != want {
+		t.Errorf("got container args %q, want %q", container, want)
+	}
+
+	// a verify file that exists is covered too
+	if err := os.WriteFile(filepath.Join(root, VerifyConfigFile), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	verifyFile := filepath.Join(root, VerifyConfigFile)
+	if args := strings.Join(bwrapProjectArgs(root), " "); !strings.HasSuffix(args, "--ro-bind "+verifyFile+" "+verifyFile) {
+		t.Errorf("verify file isn't read-only: %s", args)
+	}
+	if args := strings.Join(containerProjectArgs(root), " "); !strings.HasSuffix(args, verifyFile+":"+verifyFile+":ro") {
+		t.Errorf("verify file isn't read-only: %s", args)
+	}
+}
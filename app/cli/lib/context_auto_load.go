@@ -7,6 +7,8 @@ import (
 	"log"
 	"os"
 	"plandex-cli/api"
+	"plandex-cli/document"
+	"plandex-cli/fs"
 	"plandex-cli/types"
 	shared "plandex-shared"
 	"sync"
@@ -20,6 +22,14 @@ func AutoLoadContextFiles(ctx context.Context, files []string) (string, error) {
 		return "", fmt.Errorf("failed to get contexts: %v", err)
 	}
 
+	if hops := MustGetCurrentPlanConfig().AutoContextDepHops; hops > 0 && len(files) > 0 {
+		withDeps, err := withAutoContextDeps(files, hops, contexts)
+		if err != nil {
+			return "", err
+		}
+		files = withDeps
+	}
+
 	var totalSize int64
 	totalContexts := len(contexts)
 
@@ -58,6 +68,19 @@ func AutoLoadContextFiles(ctx context.Context, files []string) (string, error) {
 
 			size := fileInfo.Size()
 
+			// documents are limited by the size of their extracted text rather than the file
+			var documentBody string
+			isDocument := document.IsDocument(path)
+			if isDocument {
+				documentBody, err = document.Extract(path)
+				if err != nil {
+					log.Println("Skipping document", path, "because text couldn't be extracted:", err)
+					errCh <- nil
+					return
+				}
+				size = int64(len(documentBody))
+			}
+
 			mu.Lock()
 			if size > shared.MaxContextBodySize {
 				log.Println("Skipping file", path, "because it's too large", size)
@@ -76,16 +99,21 @@ func AutoLoadContextFiles(ctx context.Context, files []string) (string, error) {
 			totalSize += size
 			mu.Unlock()
 
-			b, err := os.ReadFile(path)
-			if err != nil {
-				errCh <- fmt.Errorf("failed to read file %s: %v", path, err)
-				return
+			var b []byte
+			if !isDocument {
+				b, err = os.ReadFile(path)
+				if err != nil {
+					errCh <- fmt.Errorf("failed to read file %s: %v", path, err)
+					return
+				}
 			}
 
 			var contextType shared.ContextType
 			isImage := shared.IsImageFile(path)
 			if isImage {
 				contextType = shared.ContextImageType
+			} else if isDocument {
+				contextType = shared.ContextDocumentType
 			} else {
 				contextType = shared.ContextFileType
 			}
@@ -98,6 +126,8 @@ func AutoLoadContextFiles(ctx context.Context, files []string) (string, error) {
 			var body string
 			if isImage {
 				body = base64.StdEncoding.EncodeToString(b)
+			} else if isDocument {
+				body = documentBody
 			} else {
 				body = string(shared.NormalizeEOL(b))
 			}
@@ -158,3 +188,34 @@ func MustLoadAutoContextMap() {
 		AutoLoaded:        true,
 	})
 }
+
+// withAutoContextDeps adds the files within hops steps of the auto-loaded files in the import graph, per the 'auto-context-deps' config setting, skipping any already in context
+func withAutoContextDeps(files []string, hops int, contexts []*shared.Context) ([]string, error) {
+	paths, err := fs.GetProjectPaths(fs.GetBaseDirForFilePaths(files))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project paths: %v", err)
+	}
+
+	depPaths, err := getDepPaths(files, hops, paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dependencies: %v", err)
+	}
+
+	loaded := map[string]bool{}
+	for _, context := range contexts {
+		if context.ContextType == shared.ContextFileType {
+			loaded[context.FilePath] = true
+		}
+	}
+	for _, file := range files {
+		loaded[file] = true
+	}
+
+	res := append([]string{}, files...)
+	for _, path := range depPaths {
+		if !loaded[path] {
+			res = append(res, path)
+		}
+	}
+	return res, nil
+}
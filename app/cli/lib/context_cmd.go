@@ -0,0 +1,77 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"plandex-cli/auth"
+	"plandex-cli/term"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+// commands loaded with 'plandex load --cmd' are re-run before each tell, so keep a slow one from hanging it
+var contextCmdTimeout = 2 * time.Minute
+
+// how long to wait for the output to close after the command's process group is killed, in case something outside the group still holds it
+const contextCmdWaitDelay = 5 * time.Second
+
+// runContextCmd runs a command context's shell command and returns its combined output. A failing command is still useful context (like failing tests), so its exit status is appended to the output rather than returned as an error.
+func runContextCmd(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), contextCmdTimeout)
+	defer cancel()
+
+	execCmd := exec.CommandContext(ctx, "sh", "-c", command)
+	// kill the whole group on timeout, since children left running would hold the output open
+	SetPlatformSpecificAttrs(execCmd)
+	execCmd.Cancel = func() error {
+		return KillProcessGroup(execCmd, 9)
+	}
+	execCmd.WaitDelay = contextCmdWaitDelay
+
+	res, err := execCmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("command '%s' timed out after %s", command, contextCmdTimeout)
+	}
+
+	output := string(shared.NormalizeEOL(res))
+
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return "", fmt.Errorf("failed to run command '%s': %v", command, err)
+		}
+		status := fmt.Sprintf("[%s]", exitErr.ProcessState.String())
+		if strings.TrimSpace(output) == "" {
+			output = status
+		} else {
+			output = strings.TrimRight(output, "\n") + "\n\n" + status
+		}
+	} else if strings.TrimSpace(output) == "" {
+		output = "[no output]"
+	}
+
+	if len(output) > shared.MaxContextBodySize {
+		return "", fmt.Errorf("output of '%s' is too large: %d bytes (max %d)", command, len(output), shared.MaxContextBodySize)
+	}
+
+	return output, nil
+}
+
+// isOwnContext reports whether the current user loaded a context. Contexts loaded by other users of a shared plan come from the server, so the commands in them shouldn't be run on this machine.
+func isOwnContext(context *shared.Context) bool {
+	return auth.Current != nil && context.OwnerId == auth.Current.UserId
+}
+
+func printSkippedCommands(contexts []*shared.Context) {
+	color.New(term.ColorHiYellow, color.Bold).Println("⚠️  These commands in context were loaded by another user, so they weren't re-run:")
+	for _, context := range contexts {
+		fmt.Printf("  • %s\n", context.Name)
+	}
+	fmt.Printf("To re-run one, check it and then load it yourself with %s\n\n", color.New(color.Bold, term.ColorHiCyan).Sprint("plandex rm <name> && plandex load --cmd <command>"))
+}
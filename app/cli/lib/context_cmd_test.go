@@ -0,0 +1,45 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunContextCmd(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"echo hello", "hello\n"},
+		{"true", "[no output]"},
+		{"echo failed; exit 3", "failed\n\n[exit status 3]"},
+	}
+
+	for _, tt := range tests {
+		got, err := runContextCmd(tt.command)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.command, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestRunContextCmdTimeoutKillsChildren(t *testing.T) {
+	orig := contextCmdTimeout
+	contextCmdTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { contextCmdTimeout = orig })
+
+	start := time.Now()
+	// the background sleep holds the output open after sh is gone
+	_, err := runContextCmd("sleep 30 & sleep 30")
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("took %s to return after the timeout", elapsed)
+	}
+}
@@ -0,0 +1,158 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/types"
+	"sort"
+	"sync"
+
+	shared "plandex-shared"
+)
+
+// getDepPaths returns the project files within hops steps of the given files in the import graph, in either direction -- files they import, and files that import them. The given files aren't included.
+func getDepPaths(files []string, hops int, paths *types.ProjectPaths) ([]string, error) {
+	if hops <= 0 || len(files) == 0 {
+		return nil, nil
+	}
+
+	graph, err := getImportGraph(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	importedBy := map[string][]string{}
+	for path, deps := range graph {
+		for _, dep := range deps {
+			importedBy[dep] = append(importedBy[dep], path)
+		}
+	}
+
+	visited := map[string]bool{}
+	frontier := []string{}
+	for _, file := range files {
+		file = filepath.ToSlash(filepath.Clean(file))
+		if !visited[file] {
+			visited[file] = true
+			frontier = append(frontier, file)
+		}
+	}
+
+	var res []string
+	for i := 0; i < hops && len(frontier) > 0; i++ {
+		var next []string
+		for _, file := range frontier {
+			for _, neighbor := range append(append([]string{}, graph[file]...), importedBy[file]...) {
+				if visited[neighbor] {
+					continue
+				}
+				visited[neighbor] = true
+				next = append(next, neighbor)
+				res = append(res, filepath.FromSlash(neighbor))
+			}
+		}
+		frontier = next
+	}
+
+	sort.Strings(res)
+	return res, nil
+}
+
+// getImportGraph reads every project file with import support and resolves its imports to project files on the server, in batches
+func getImportGraph(paths *types.ProjectPaths) (map[string][]string, error) {
+	var projectPaths []string
+	var sourcePaths []string
+	for path := range paths.ActivePaths {
+		projectPaths = append(projectPaths, filepath.ToSlash(path))
+		if shared.HasImportGraphSupport(path) {
+			sourcePaths = append(sourcePaths, path)
+		}
+	}
+	sort.Strings(sourcePaths)
+
+	// any file can be imported, but on a huge project, stick to the ones that can import others
+	if len(projectPaths) > shared.MaxContextMapPaths*10 {
+		projectPaths = nil
+		for _, path := range sourcePaths {
+			projectPaths = append(projectPaths, filepath.ToSlash(path))
+		}
+	}
+
+	// go.mod files are needed to resolve Go imports, so they go in every batch
+	goMods := shared.FileMapInputs{}
+	inputs := shared.FileMapInputs{}
+	for _, path := range sourcePaths {
+		info, err := os.Stat(path)
+		if err != nil || info.Size() > shared.MaxContextMapSingleInputSize {
+			continue
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		if filepath.Base(path) == "go.mod" {
+			goMods[filepath.ToSlash(path)] = string(b)
+		} else {
+			inputs[filepath.ToSlash(path)] = string(shared.NormalizeEOL(b))
+		}
+	}
+
+	newBatch := func() shared.FileMapInputs {
+		batch := shared.FileMapInputs{}
+		for path, content := range goMods {
+			batch[path] = content
+		}
+		return batch
+	}
+
+	var batches []shared.FileMapInputs
+	current := newBatch()
+	currentSize := goMods.TotalSize()
+	for path, content := range inputs {
+		size := int64(len(content))
+		if len(current) > len(goMods) && (len(current) >= shared.ContextMapMaxBatchSize || currentSize+size > shared.ContextMapMaxBatchBytes) {
+			batches = append(batches, current)
+			current = newBatch()
+			currentSize = goMods.TotalSize()
+		}
+		current[path] = content
+		currentSize += size
+	}
+	if len(current) > len(goMods) {
+		batches = append(batches, current)
+	}
+
+	graph := map[string][]string{}
+	var mu sync.Mutex
+	errCh := make(chan error, len(batches))
+
+	for _, batch := range batches {
+		go func(batch shared.FileMapInputs) {
+			batchRes, apiErr := api.Client.GetFileDeps(shared.GetFileDepsRequest{
+				Inputs:       batch,
+				ProjectPaths: projectPaths,
+			})
+			if apiErr != nil {
+				errCh <- fmt.Errorf("failed to resolve imports: %v", apiErr.Msg)
+				return
+			}
+			mu.Lock()
+			for path, deps := range batchRes.Deps {
+				graph[path] = deps
+			}
+			mu.Unlock()
+			errCh <- nil
+		}(batch)
+	}
+
+	for range batches {
+		err := <-errCh
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return graph, nil
+}
@@ -27,6 +27,18 @@ func GetContextLabelAndIcon(contextType shared.ContextType) (string, string) {
 	case shared.ContextMapType:
 		icon = "🗺️ "
 		lbl = "map"
+	case shared.ContextSymbolType:
+		icon = "🔣"
+		lbl = "symbol"
+	case shared.ContextGitDiffType:
+		icon = "🔀"
+		lbl = "git diff"
+	case shared.ContextCommandType:
+		icon = "💻"
+		lbl = "cmd"
+	case shared.ContextDocumentType:
+		icon = "📑"
+		lbl = "doc"
 	}
 
 	return lbl, icon
@@ -0,0 +1,151 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/fs"
+	"plandex-cli/types"
+	"strings"
+	"unicode"
+
+	shared "plandex-shared"
+)
+
+// a git diff context's name is the command that produced it, like 'git diff main', so it can be re-run on 'plandex update'
+const gitDiffContextPrefix = "git "
+
+func gitDiffContextName(args []string) string {
+	return gitDiffContextPrefix + strings.Join(args, " ")
+}
+
+// gitArgsForContext rebuilds the git command for a diff context from its name. The name comes from the server, where anyone who can work on the plan could change it, so only the forms that load creates are accepted and the ref is validated again -- otherwise it could pass options like --output to git.
+func gitArgsForContext(name string) ([]string, error) {
+	if !strings.HasPrefix(name, gitDiffContextPrefix) {
+		return nil, fmt.Errorf("'%s' isn't a git diff that can be re-run", name)
+	}
+	fields := strings.Fields(strings.TrimPrefix(name, gitDiffContextPrefix))
+
+	switch {
+	case len(fields) == 2 && fields[0] == "diff" && fields[1] == "--cached":
+		return []string{"diff", "--cached"}, nil
+
+	case len(fields) == 2 && fields[0] == "diff":
+		if err := validateGitRef(fields[1]); err != nil {
+			return nil, err
+		}
+		return []string{"diff", fields[1]}, nil
+
+	case len(fields) == 3 && fields[0] == "log" && fields[1] == "-p":
+		if err := validateGitRef(fields[2]); err != nil {
+			return nil, err
+		}
+		return []string{"log", "-p", fields[2]}, nil
+	}
+
+	return nil, fmt.Errorf("'%s' isn't a git diff that can be re-run", name)
+}
+
+// validateGitRef checks that a ref or range like 'main' or 'HEAD~3..HEAD' can't be read by git as an option
+func validateGitRef(ref string) error {
+	if ref == "" || strings.HasPrefix(ref, "-") || strings.IndexFunc(ref, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) != -1 {
+		return fmt.Errorf("invalid git ref or range: %q", ref)
+	}
+	return nil
+}
+
+func hasGitLoadFlags(params *types.LoadContextParams) bool {
+	return params.GitDiff != "" || params.GitStaged || params.GitCommits != "" || params.ChangedSince != ""
+}
+
+// getGitLoadContext resolves the --git-diff, --git-staged, --git-commits, and --changed-since load flags into diff contexts, plus the paths of any files they touched that should be loaded too
+func getGitLoadContext(params *types.LoadContextParams) ([]*shared.LoadContextParams, []string, error) {
+	if !fs.IsGitRepo(fs.Cwd) {
+		return nil, nil, fmt.Errorf("git context can only be loaded inside a git repository")
+	}
+
+	for _, ref := range []string{params.GitDiff, params.GitCommits, params.ChangedSince} {
+		if ref == "" {
+			continue
+		}
+		if err := validateGitRef(ref); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var diffCmds [][]string
+	if params.GitDiff != "" {
+		diffCmds = append(diffCmds, []string{"diff", params.GitDiff})
+	}
+	if params.GitStaged {
+		diffCmds = append(diffCmds, []string{"diff", "--cached"})
+	}
+	if params.GitCommits != "" {
+		diffCmds = append(diffCmds, []string{"log", "-p", params.GitCommits})
+	}
+
+	var diffParams []*shared.LoadContextParams
+	var paths []string
+
+	for _, args := range diffCmds {
+		body, err := GitOutput(args...)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		name := gitDiffContextName(args)
+		if strings.TrimSpace(body) == "" {
+			return nil, nil, fmt.Errorf("'%s' has no changes to load", name)
+		}
+		if len(body) > shared.MaxContextBodySize {
+			return nil, nil, fmt.Errorf("'%s' is too large to load: %d bytes (max %d)", name, len(body), shared.MaxContextBodySize)
+		}
+
+		diffParams = append(diffParams, &shared.LoadContextParams{
+			ContextType: shared.ContextGitDiffType,
+			Name:        name,
+			Body:        body,
+			AutoLoaded:  params.AutoLoaded,
+		})
+
+		if params.GitFiles {
+			// 'log -p' lists files per commit without the patch
+			changedArgs := args
+			if args[0] == "log" {
+				changedArgs = []string{"log", args[2]}
+			}
+			changed, err := GitChangedFiles(changedArgs...)
+			if err != nil {
+				return nil, nil, err
+			}
+			paths = append(paths, changed...)
+		}
+	}
+
+	if params.ChangedSince != "" {
+		changed, err := GitChangedFiles("diff", params.ChangedSince)
+		if err != nil {
+			return nil, nil, err
+		}
+		untracked, err := GitUntrackedFiles()
+		if err != nil {
+			return nil, nil, err
+		}
+		paths = append(paths, changed...)
+		paths = append(paths, untracked...)
+	}
+
+	// deleted files are part of a diff, but there's nothing left to load
+	var existing []string
+	seen := map[string]bool{}
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			existing = append(existing, path)
+		}
+	}
+
+	return diffParams, existing, nil
+}
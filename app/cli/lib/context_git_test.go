@@ -0,0 +1,48 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGitArgsForContext(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"git diff main", []string{"diff", "main"}},
+		{"git diff HEAD~3..HEAD", []string{"diff", "HEAD~3..HEAD"}},
+		{"git diff --cached", []string{"diff", "--cached"}},
+		{"git log -p main..feature", []string{"log", "-p", "main..feature"}},
+
+		// names changed on the server to pass options or other commands to git
+		{"git diff --output=/tmp/x", nil},
+		{"git diff --ext-diff", nil},
+		{"git diff main --output=/tmp/x", nil},
+		{"git log -p --output=/tmp/x", nil},
+		{"git log -p main --ext-diff", nil},
+		{"git log main", nil},
+		{"git -c core.pager=sh diff main", nil},
+		{"git config core.sshCommand sh", nil},
+		{"git diff", nil},
+		{"diff main", nil},
+		{"git diff ma\x00in", nil},
+	}
+
+	for _, tt := range tests {
+		got, err := gitArgsForContext(tt.name)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %q", tt.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%q: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -9,10 +9,12 @@ import (
 	"path/filepath"
 	"plandex-cli/api"
 	"plandex-cli/auth"
+	"plandex-cli/document"
 	"plandex-cli/fs"
 	"plandex-cli/term"
 	"plandex-cli/types"
 	"plandex-cli/url"
+	"sort"
 	"strings"
 	"sync"
 
@@ -88,12 +90,41 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 		}
 	}
 
+	var gitDiffParams []*shared.LoadContextParams
+	if hasGitLoadFlags(params) {
+		if params.DefsOnly || params.NamesOnly {
+			onErr(fmt.Errorf("git context can't be loaded with --map or --tree"))
+		}
+
+		var gitPaths []string
+		gitDiffParams, gitPaths, err = getGitLoadContext(params)
+		if err != nil {
+			onErr(err)
+		}
+		resources = append(resources, gitPaths...)
+	} else if params.GitFiles {
+		onErr(fmt.Errorf("--git-files requires --git-diff, --git-staged, or --git-commits"))
+	}
+
+	if len(params.Cmds) > 0 && (params.DefsOnly || params.NamesOnly) {
+		onErr(fmt.Errorf("command output can't be loaded with --map or --tree"))
+	}
+
+	if params.WithDeps > 0 && (params.DefsOnly || params.NamesOnly) {
+		onErr(fmt.Errorf("--with-deps can't be used with --map or --tree"))
+	}
+
+	if params.Crawl && (params.DefsOnly || params.NamesOnly) {
+		onErr(fmt.Errorf("--crawl can't be used with --map or --tree"))
+	}
+
 	var inputUrls []string
 	var inputFilePaths []string
+	var inputSymbols []string
 
 	if len(resources) > 0 {
 		for _, resource := range resources {
-			// so far resources are either files or urls
+			// resources are files, urls, or symbols selected from a file like 'server/api.go#Server.Start'
 			if url.IsValidURL(resource) {
 				inputUrls = append(inputUrls, resource)
 			} else {
@@ -101,11 +132,23 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 					resource = resource[2:]
 				}
 
-				inputFilePaths = append(inputFilePaths, resource)
+				if isSymbolResource(resource) {
+					inputSymbols = append(inputSymbols, resource)
+				} else {
+					inputFilePaths = append(inputFilePaths, resource)
+				}
 			}
 		}
 	}
 
+	if params.Crawl && len(inputUrls) == 0 {
+		onErr(fmt.Errorf("--crawl requires at least one URL"))
+	}
+
+	if len(inputSymbols) > 0 && (params.DefsOnly || params.NamesOnly) {
+		onErr(fmt.Errorf("symbols like %s can't be loaded with --map or --tree", inputSymbols[0]))
+	}
+
 	var contextMu sync.Mutex
 
 	errCh := make(chan error)
@@ -120,6 +163,10 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 
 	var totalSize int64
 
+	var crawlResults []*url.CrawlResult
+	// documents that text couldn't be extracted from, like scanned or encrypted PDFs
+	var documentErrs []string
+
 	numRoutines := 0
 
 	// filter out already loaded contexts
@@ -132,15 +179,30 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 	existsByComposite := make(map[string]*shared.Context)
 	for _, context := range existingContexts {
 		switch context.ContextType {
-		case shared.ContextFileType, shared.ContextDirectoryTreeType, shared.ContextMapType, shared.ContextImageType:
+		case shared.ContextFileType, shared.ContextDirectoryTreeType, shared.ContextMapType, shared.ContextImageType, shared.ContextDocumentType:
 			existsByComposite[strings.Join([]string{string(context.ContextType), context.FilePath}, "|")] = context
 		case shared.ContextURLType:
 			existsByComposite[strings.Join([]string{string(context.ContextType), context.Url}, "|")] = context
+		case shared.ContextSymbolType, shared.ContextGitDiffType, shared.ContextCommandType:
+			existsByComposite[strings.Join([]string{string(context.ContextType), context.Name}, "|")] = context
+		}
+	}
+
+	for _, diffParams := range gitDiffParams {
+		composite := strings.Join([]string{string(shared.ContextGitDiffType), diffParams.Name}, "|")
+		if existsByComposite[composite] != nil {
+			alreadyLoadedByComposite[composite] = existsByComposite[composite]
+			continue
 		}
+		loadContextReq = append(loadContextReq, diffParams)
 	}
 
+	loadContextReq = append(loadContextReq, params.Restored...)
+
 	var cachedMapPaths map[string]bool
 	var cachedMapLoadRes *shared.LoadContextResponse
+	// scope dirs for maps that the plan's scope narrows
+	mapScopes := map[string][]string{}
 
 	mapInputShas := map[string]string{}
 	mapInputTokens := map[string]int{}
@@ -171,15 +233,29 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 
 			var uncachedMapPaths []string
 
-			res, err := api.Client.LoadCachedFileMap(CurrentPlanId, CurrentBranch, shared.LoadCachedFileMapRequest{
-				FilePaths: toLoadMapPaths,
-			})
+			// the project's map cache holds full maps, so a map narrowed by the plan's scope is always built fresh
+			var cacheableMapPaths []string
+			for _, path := range toLoadMapPaths {
+				if scope := scopeForMap(path); len(scope) > 0 {
+					mapScopes[path] = scope
+				} else {
+					cacheableMapPaths = append(cacheableMapPaths, path)
+				}
+			}
 
-			if err != nil {
-				onErr(fmt.Errorf("error checking cached file map: %v", err))
+			var res *shared.LoadCachedFileMapResponse
+			if len(cacheableMapPaths) > 0 {
+				var apiErr *shared.ApiError
+				res, apiErr = api.Client.LoadCachedFileMap(CurrentPlanId, CurrentBranch, shared.LoadCachedFileMapRequest{
+					FilePaths: cacheableMapPaths,
+				})
+
+				if apiErr != nil {
+					onErr(fmt.Errorf("error checking cached file map: %v", apiErr))
+				}
 			}
 
-			if res.LoadRes != nil {
+			if res != nil && res.LoadRes != nil {
 				if res.LoadRes.MaxTokensExceeded {
 					term.StopSpinner()
 					overage := res.LoadRes.TotalTokens - res.LoadRes.MaxTokens
@@ -354,11 +430,19 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 
 				}
 
+				if params.WithDeps > 0 {
+					depPaths, err := getDepPaths(flattenedPaths, params.WithDeps, paths)
+					if err != nil {
+						onErr(fmt.Errorf("failed to load dependencies: %v", err))
+					}
+					flattenedPaths = append(flattenedPaths, depPaths...)
+				}
+
 				var numPaths int
 				if params.DefsOnly {
 					filtered := []string{}
 					for _, path := range flattenedPaths {
-						if shared.HasFileMapSupport(path) {
+						if shared.HasFileMapSupport(path) && pathInMapScopes(path, mapScopes) {
 							numPaths++
 
 							if numPaths > shared.MaxContextMapPaths {
@@ -428,10 +512,13 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 
 					var contextType shared.ContextType
 					isImage := shared.IsImageFile(path)
+					isDocument := !isImage && !params.DefsOnly && document.IsDocument(path)
 					if isImage {
 						contextType = shared.ContextImageType
 					} else if params.DefsOnly {
 						contextType = shared.ContextMapType
+					} else if isDocument {
+						contextType = shared.ContextDocumentType
 					} else {
 						contextType = shared.ContextFileType
 					}
@@ -450,6 +537,44 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 						sem <- struct{}{}
 						defer func() { <-sem }()
 
+						// documents are limited by the size of their extracted text rather than the file
+						if isDocument {
+							body, err := document.Extract(path)
+
+							contextMu.Lock()
+							defer contextMu.Unlock()
+
+							if err != nil {
+								documentErrs = append(documentErrs, err.Error())
+								errCh <- nil
+								return
+							}
+
+							size := int64(len(body))
+							if size > shared.MaxContextBodySize {
+								filesSkippedTooLarge = append(filesSkippedTooLarge, filePathWithSize{Path: path, Size: size})
+								errCh <- nil
+								return
+							}
+							if totalSize+size > shared.MaxContextBodySize {
+								filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, path)
+								errCh <- nil
+								return
+							}
+							totalSize += size
+
+							loadContextReq = append(loadContextReq, &shared.LoadContextParams{
+								ContextType: shared.ContextDocumentType,
+								Name:        path,
+								Body:        body,
+								FilePath:    path,
+								AutoLoaded:  params.AutoLoaded,
+							})
+
+							errCh <- nil
+							return
+						}
+
 						var size int64
 
 						fileInfo, err := os.Stat(path)
@@ -553,12 +678,92 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 		}
 	}
 
+	for _, command := range params.Cmds {
+		command = strings.TrimSpace(command)
+		if command == "" {
+			continue
+		}
+
+		composite := strings.Join([]string{string(shared.ContextCommandType), command}, "|")
+		if existsByComposite[composite] != nil {
+			alreadyLoadedByComposite[composite] = existsByComposite[composite]
+			continue
+		}
+
+		numRoutines++
+		go func(command string) {
+			body, err := runContextCmd(command)
+			if err != nil {
+				errCh <- err
+				return
+			}
+
+			contextMu.Lock()
+			defer contextMu.Unlock()
+
+			loadContextReq = append(loadContextReq, &shared.LoadContextParams{
+				ContextType: shared.ContextCommandType,
+				Name:        command,
+				Body:        body,
+				AutoLoaded:  params.AutoLoaded,
+			})
+
+			errCh <- nil
+		}(command)
+	}
+
 	if len(inputUrls) > 0 {
-		for _, u := range inputUrls {
-			composite := strings.Join([]string{string(shared.ContextURLType), u}, "|")
+		// crawls can reach the same page from more than one root url
+		loadedUrls := map[string]bool{}
+
+		addUrlPage := func(page *url.Page) {
+			composite := strings.Join([]string{string(shared.ContextURLType), page.Url}, "|")
 			if existsByComposite[composite] != nil {
 				alreadyLoadedByComposite[composite] = existsByComposite[composite]
-				continue
+				return
+			}
+			if loadedUrls[page.Url] {
+				return
+			}
+			loadedUrls[page.Url] = true
+
+			name := url.SanitizeURL(page.Url)
+			// show the first 20 characters, then ellipsis then the last 20 characters of 'name'
+			if len(name) > 40 {
+				name = name[:20] + "⋯" + name[len(name)-20:]
+			}
+
+			// Check the size of the URL body, just like a file:
+			size := int64(len(page.Body))
+
+			if size > shared.MaxContextBodySize {
+				filesSkippedTooLarge = append(filesSkippedTooLarge, filePathWithSize{Path: page.Url, Size: size})
+				return
+			}
+			if totalSize+size > shared.MaxContextBodySize {
+				filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, page.Url)
+				return
+			}
+			totalSize += size
+
+			loadContextReq = append(loadContextReq, &shared.LoadContextParams{
+				ContextType:     shared.ContextURLType,
+				Name:            name,
+				Body:            page.Body,
+				Url:             page.Url,
+				UrlETag:         page.ETag,
+				UrlLastModified: page.LastModified,
+				AutoLoaded:      params.AutoLoaded,
+			})
+		}
+
+		for _, u := range inputUrls {
+			if !params.Crawl {
+				composite := strings.Join([]string{string(shared.ContextURLType), u}, "|")
+				if existsByComposite[composite] != nil {
+					alreadyLoadedByComposite[composite] = existsByComposite[composite]
+					continue
+				}
 			}
 
 			numRoutines++
@@ -566,43 +771,38 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 				sem <- struct{}{}
 				defer func() { <-sem }()
 
-				body, err := url.FetchURLContent(u)
-				if err != nil {
-					errCh <- fmt.Errorf("failed to fetch content from URL %s: %v", u, err)
-					return
-				}
-
-				name := url.SanitizeURL(u)
-				// show the first 20 characters, then ellipsis then the last 20 characters of 'name'
-				if len(name) > 40 {
-					name = name[:20] + "⋯" + name[len(name)-20:]
-				}
+				if params.Crawl {
+					crawlRes, err := url.Crawl(u, url.CrawlParams{
+						Depth:      params.CrawlDepth,
+						SamePrefix: params.SamePrefix,
+					})
+					if err != nil {
+						errCh <- fmt.Errorf("failed to crawl %s: %v", u, err)
+						return
+					}
 
-				// Check the size of the URL body, just like a file:
-				size := int64(len(body))
+					contextMu.Lock()
+					defer contextMu.Unlock()
 
-				contextMu.Lock()
-				defer contextMu.Unlock()
+					for _, page := range crawlRes.Pages {
+						addUrlPage(page)
+					}
+					crawlResults = append(crawlResults, crawlRes)
 
-				if size > shared.MaxContextBodySize {
-					filesSkippedTooLarge = append(filesSkippedTooLarge, filePathWithSize{Path: u, Size: size})
 					errCh <- nil
 					return
 				}
-				if totalSize+size > shared.MaxContextBodySize {
-					filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, u)
-					errCh <- nil
+
+				page, err := url.FetchPage(u, "", "")
+				if err != nil {
+					errCh <- fmt.Errorf("failed to fetch content from URL %s: %v", u, err)
 					return
 				}
-				totalSize += size
 
-				loadContextReq = append(loadContextReq, &shared.LoadContextParams{
-					ContextType: shared.ContextURLType,
-					Name:        name,
-					Body:        body,
-					Url:         u,
-					AutoLoaded:  params.AutoLoaded,
-				})
+				contextMu.Lock()
+				defer contextMu.Unlock()
+
+				addUrlPage(page)
 
 				errCh <- nil
 			}(u)
@@ -616,6 +816,71 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 		}
 	}
 
+	if len(inputSymbols) > 0 {
+		symbolInputs := map[string]shared.FileSymbolsInput{}
+		var symbolPaths []string
+
+		for _, resource := range inputSymbols {
+			composite := strings.Join([]string{string(shared.ContextSymbolType), resource}, "|")
+			if existsByComposite[composite] != nil {
+				alreadyLoadedByComposite[composite] = existsByComposite[composite]
+				continue
+			}
+
+			filePath, pattern, _ := shared.ParseSymbolResource(resource)
+			input, err := readSymbolInput(filePath, pattern)
+			if err != nil {
+				onErr(err)
+			}
+			symbolInputs[resource] = input
+			symbolPaths = append(symbolPaths, filePath)
+		}
+
+		if len(symbolPaths) > 0 && !params.ForceSkipIgnore {
+			baseDir := fs.GetBaseDirForFilePaths(symbolPaths)
+			paths, err := fs.GetProjectPaths(baseDir)
+			if err != nil {
+				onErr(fmt.Errorf("failed to get project paths: %v", err))
+			}
+
+			for name, input := range symbolInputs {
+				if _, ok := paths.ActivePaths[input.Path]; ok {
+					continue
+				}
+				ignored, reason, err := fs.IsIgnored(paths, input.Path, baseDir)
+				if err != nil {
+					onErr(fmt.Errorf("failed to check if %s is ignored: %v", input.Path, err))
+				}
+				if ignored {
+					ignoredPaths[input.Path] = reason
+					delete(symbolInputs, name)
+				}
+			}
+		}
+
+		if len(symbolInputs) > 0 {
+			symbolsRes, err := processSymbolBatches(symbolInputs)
+			if err != nil {
+				onErr(err)
+			}
+
+			for name, unmatched := range symbolsRes.Unmatched {
+				onErr(fmt.Errorf("no definitions in %s match %s", symbolInputs[name].Path, strings.Join(unmatched, ", ")))
+			}
+
+			for name, input := range symbolInputs {
+				loadContextReq = append(loadContextReq, &shared.LoadContextParams{
+					ContextType:     shared.ContextSymbolType,
+					Name:            name,
+					FilePath:        input.Path,
+					Body:            symbolsRes.Bodies[name],
+					ForceSkipIgnore: params.ForceSkipIgnore,
+					AutoLoaded:      params.AutoLoaded,
+				})
+			}
+		}
+	}
+
 	if params.DefsOnly {
 		allMapBodies, err := processMapBatches(mapInputBatches)
 		if err != nil {
@@ -688,6 +953,10 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 			printIgnoredMsg()
 			didOutputReason = true
 		}
+		if len(documentErrs) > 0 {
+			printDocumentErrsMsg(documentErrs)
+			didOutputReason = true
+		}
 
 		if !didOutputReason {
 			fmt.Println()
@@ -696,6 +965,19 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 			fmt.Println("plandex load file.c file.h")
 			fmt.Println("plandex load https://github.com/some-org/some-repo/README.md")
 
+			fmt.Println()
+			fmt.Printf("%s with file#Symbol:\n", color.New(color.Bold, term.ColorHiCyan).Sprint("Load just some definitions"))
+			fmt.Println("plandex load server/api.go#Server.Start 'server/handlers.go#Handle*'")
+
+			fmt.Println()
+			fmt.Printf("%s with the --git-diff, --git-staged, --git-commits, or --changed-since flags:\n", color.New(color.Bold, term.ColorHiCyan).Sprint("Load what changed"))
+			fmt.Println("plandex load --git-diff main --git-files")
+			fmt.Println("plandex load --changed-since main")
+
+			fmt.Println()
+			fmt.Printf("%s with the --cmd flag:\n", color.New(color.Bold, term.ColorHiCyan).Sprint("Load command output that stays current"))
+			fmt.Println("plandex load --cmd 'go test ./... 2>&1'")
+
 			fmt.Println()
 			fmt.Printf("%s with the --recursive/-r flag:\n", color.New(color.Bold, term.ColorHiCyan).Sprint("Load a whole directory"))
 			fmt.Println("plandex load app/src -r")
@@ -754,6 +1036,26 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 		printSkippedFilesMsg(filesSkippedTooLarge, filesSkippedAfterSizeLimit,
 			mapFilesTruncatedTooLarge, mapFilesSkippedAfterSizeLimit)
 	}
+
+	printCrawlSkippedMsg(crawlResults)
+
+	if len(documentErrs) > 0 {
+		printDocumentErrsMsg(documentErrs)
+	}
+}
+
+func printDocumentErrsMsg(documentErrs []string) {
+	sort.Strings(documentErrs)
+
+	fmt.Println()
+	fmt.Println("⚠️  Text couldn't be extracted from these documents, so they were skipped:")
+	for i, msg := range documentErrs {
+		if i == maxSkippedFileList {
+			fmt.Printf("  • and %d more\n", len(documentErrs)-maxSkippedFileList)
+			break
+		}
+		fmt.Printf("  • %s\n", msg)
+	}
 }
 
 func printAlreadyLoadedMsg(alreadyLoadedByComposite map[string]*shared.Context) {
@@ -770,6 +1072,41 @@ func printAlreadyLoadedMsg(alreadyLoadedByComposite map[string]*shared.Context)
 	}
 }
 
+func printCrawlSkippedMsg(crawlResults []*url.CrawlResult) {
+	var failed, disallowed []string
+	truncated := false
+	for _, res := range crawlResults {
+		failed = append(failed, res.Failed...)
+		disallowed = append(disallowed, res.Disallowed...)
+		truncated = truncated || res.Truncated
+	}
+
+	printList := func(urls []string) {
+		for i, u := range urls {
+			if i == maxSkippedFileList {
+				fmt.Printf("  • and %d more\n", len(urls)-maxSkippedFileList)
+				break
+			}
+			fmt.Printf("  • %s\n", u)
+		}
+	}
+
+	if len(failed) > 0 {
+		fmt.Println()
+		fmt.Println("⚠️  These pages couldn't be fetched or aren't text, so they were skipped:")
+		printList(failed)
+	}
+	if len(disallowed) > 0 {
+		fmt.Println()
+		fmt.Println("ℹ️  These pages were skipped because robots.txt doesn't allow crawling them:")
+		printList(disallowed)
+	}
+	if truncated {
+		fmt.Println()
+		fmt.Printf("ℹ️  Stopped crawling after %d pages per URL. Use a more specific URL with --same-prefix, or a lower --depth, to load the pages you need.\n", url.MaxCrawlPages)
+	}
+}
+
 func printIgnoredMsg() {
 	fmt.Println()
 	fmt.Println("ℹ️  " + color.New(color.FgWhite).Sprint("Due to .gitignore or .plandexignore, some paths weren't loaded.\nUse --force / -f to load ignored paths."))
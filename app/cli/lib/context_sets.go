@@ -0,0 +1,283 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/fs"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+// MustRestoreContextSet loads a saved context set into the current plan. Entries are re-read from the project rather than copied from the set, so the plan gets their current contents -- git diffs and commands are re-run too. Only notes and piped data are loaded from the set itself.
+// Paths outside the project are skipped, and commands and git diffs that someone else loaded only run after the user confirms them, whoever saved the set.
+func MustRestoreContextSet(set *shared.ContextSet) {
+	term.StartSpinner("")
+	existingContexts, apiErr := api.Client.ListContext(CurrentPlanId, CurrentBranch)
+	term.StopSpinner()
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing context: %v", apiErr.Msg)
+	}
+
+	loaded := map[string]bool{}
+	for _, context := range existingContexts {
+		loaded[contextSetComposite(context.ContextType, context.FilePath, context.Url, context.Name)] = true
+	}
+
+	// MustLoadContext takes one map directory at a time, and tree, ignore, and image detail flags apply to a whole load, so entries are grouped into one load per combination
+	type loadGroup struct {
+		resources []string
+		params    *types.LoadContextParams
+	}
+	var groups []*loadGroup
+	groupsByKey := map[string]*loadGroup{}
+	getGroup := func(key string, params *types.LoadContextParams) *loadGroup {
+		group, ok := groupsByKey[key]
+		if !ok {
+			group = &loadGroup{params: params}
+			groupsByKey[key] = group
+			groups = append(groups, group)
+		}
+		return group
+	}
+	mainGroup := func() *loadGroup {
+		return getGroup("load|false|", &types.LoadContextParams{})
+	}
+
+	var numAlreadyLoaded int
+	skipped := map[string]string{}
+
+	unconfirmed := confirmContextSetCommands(set, loaded)
+
+	for _, entry := range set.Entries {
+		if loaded[contextSetComposite(entry.ContextType, entry.FilePath, entry.Url, entry.Name)] {
+			numAlreadyLoaded++
+			continue
+		}
+
+		if unconfirmed[entry] {
+			skipped[entry.Name] = "not confirmed"
+			continue
+		}
+
+		switch entry.ContextType {
+		case shared.ContextFileType, shared.ContextImageType, shared.ContextDocumentType, shared.ContextSymbolType, shared.ContextMapType, shared.ContextDirectoryTreeType:
+			path := entry.FilePath
+			if entry.ContextType == shared.ContextSymbolType {
+				// the symbol is loaded by its name, so that's the path that has to stay in the project
+				path = strings.SplitN(entry.Name, "#", 2)[0]
+			}
+			if !isInProject(path) || !isInProject(entry.FilePath) {
+				skipped[entry.Name] = fmt.Sprintf("%s is outside the project", entry.FilePath)
+				continue
+			}
+			if _, err := os.Stat(entry.FilePath); err != nil {
+				skipped[entry.Name] = fmt.Sprintf("%s not found", entry.FilePath)
+				continue
+			}
+		}
+
+		switch entry.ContextType {
+		case shared.ContextFileType, shared.ContextImageType, shared.ContextDocumentType:
+			key := fmt.Sprintf("load|%v|%s", entry.ForceSkipIgnore, entry.ImageDetail)
+			group := getGroup(key, &types.LoadContextParams{
+				ForceSkipIgnore: entry.ForceSkipIgnore,
+				ImageDetail:     entry.ImageDetail,
+			})
+			group.resources = append(group.resources, entry.FilePath)
+
+		case shared.ContextSymbolType:
+			// the name is the resource it was loaded with, like 'server/api.go#Server.Start'
+			key := fmt.Sprintf("load|%v|", entry.ForceSkipIgnore)
+			group := getGroup(key, &types.LoadContextParams{ForceSkipIgnore: entry.ForceSkipIgnore})
+			group.resources = append(group.resources, entry.Name)
+
+		case shared.ContextURLType:
+			group := mainGroup()
+			group.resources = append(group.resources, entry.Url)
+
+		case shared.ContextMapType:
+			key := fmt.Sprintf("map|%s|%v", entry.FilePath, entry.ForceSkipIgnore)
+			group := getGroup(key, &types.LoadContextParams{DefsOnly: true, ForceSkipIgnore: entry.ForceSkipIgnore})
+			group.resources = append(group.resources, entry.FilePath)
+
+		case shared.ContextDirectoryTreeType:
+			key := fmt.Sprintf("tree|%v", entry.ForceSkipIgnore)
+			group := getGroup(key, &types.LoadContextParams{NamesOnly: true, ForceSkipIgnore: entry.ForceSkipIgnore})
+			group.resources = append(group.resources, entry.FilePath)
+
+		case shared.ContextCommandType:
+			group := mainGroup()
+			group.params.Cmds = append(group.params.Cmds, entry.Name)
+
+		case shared.ContextGitDiffType:
+			name := entry.Name
+			args, err := gitArgsForContext(name)
+			if err != nil {
+				skipped[name] = err.Error()
+				continue
+			}
+			body, err := GitOutput(args...)
+			if err != nil {
+				skipped[name] = err.Error()
+				continue
+			}
+			if strings.TrimSpace(body) == "" {
+				skipped[name] = "no changes"
+				continue
+			}
+			if len(body) > shared.MaxContextBodySize {
+				skipped[name] = fmt.Sprintf("too large: %d bytes (max %d)", len(body), shared.MaxContextBodySize)
+				continue
+			}
+			group := mainGroup()
+			group.params.Restored = append(group.params.Restored, &shared.LoadContextParams{
+				ContextType: shared.ContextGitDiffType,
+				Name:        name,
+				Body:        body,
+			})
+
+		case shared.ContextNoteType, shared.ContextPipedDataType:
+			group := mainGroup()
+			group.params.Restored = append(group.params.Restored, &shared.LoadContextParams{
+				ContextType: entry.ContextType,
+				Name:        entry.Name,
+				Body:        entry.Body,
+			})
+		}
+	}
+
+	if len(skipped) > 0 {
+		fmt.Println("⚠️  Skipped because they can't be restored:")
+		for name, reason := range skipped {
+			fmt.Printf("  • %s %s\n", name, color.New(term.ColorHiYellow).Sprintf("(%s)", reason))
+		}
+		fmt.Println()
+	}
+
+	if len(groups) == 0 {
+		if numAlreadyLoaded > 0 {
+			fmt.Printf("🙅‍♂️ Everything in %s is already in context\n", color.New(color.Bold, term.ColorHiCyan).Sprint(set.Name))
+		} else {
+			fmt.Println("🤷‍♂️ No context restored")
+		}
+		return
+	}
+
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Println()
+		}
+		MustLoadContext(group.resources, group.params)
+	}
+}
+
+// confirmContextSetCommands shows the commands and git diffs in a set that someone other than the user loaded and asks before running them. A set the user saved can still hold them, since saving copies every context in the plan. It returns the entries that weren't confirmed.
+func confirmContextSetCommands(set *shared.ContextSet, loaded map[string]bool) map[*shared.ContextSetEntry]bool {
+	toConfirm := contextSetCommandsToConfirm(set, loaded)
+	if len(toConfirm) == 0 {
+		return nil
+	}
+
+	suffix := ""
+	if len(toConfirm) > 1 {
+		suffix = "s"
+	}
+	color.New(term.ColorHiYellow, color.Bold).Printf("⚠️  %s runs %d command%s that you didn't load:\n", set.Name, len(toConfirm), suffix)
+	for _, entry := range toConfirm {
+		owner := "another user"
+		if entry.OwnerId == set.OwnerId && set.OwnerEmail != "" {
+			owner = set.OwnerEmail
+		}
+		cmd := entry.Name
+		if entry.ContextType == shared.ContextGitDiffType {
+			args, _ := gitArgsForContext(entry.Name)
+			cmd = "git " + strings.Join(args, " ")
+		}
+		fmt.Printf("  • %s %s\n", cmd, color.New(term.ColorHiYellow).Sprintf("(loaded by %s)", owner))
+	}
+	fmt.Println()
+
+	confirmed, err := term.ConfirmYesNo("Run them?")
+	if err != nil {
+		term.OutputErrorAndExit("Error confirming: %v", err)
+	}
+	fmt.Println()
+	if confirmed {
+		return nil
+	}
+
+	unconfirmed := map[*shared.ContextSetEntry]bool{}
+	for _, entry := range toConfirm {
+		unconfirmed[entry] = true
+	}
+	return unconfirmed
+}
+
+// contextSetCommandsToConfirm lists the commands and git diffs in a set that aren't already loaded and that the current user didn't load themselves. Entries saved before owners were recorded have none, so they're confirmed too.
+func contextSetCommandsToConfirm(set *shared.ContextSet, loaded map[string]bool) []*shared.ContextSetEntry {
+	var res []*shared.ContextSetEntry
+	for _, entry := range set.Entries {
+		if loaded[contextSetComposite(entry.ContextType, entry.FilePath, entry.Url, entry.Name)] {
+			continue
+		}
+		if auth.Current != nil && entry.OwnerId != "" && entry.OwnerId == auth.Current.UserId {
+			continue
+		}
+		switch entry.ContextType {
+		case shared.ContextCommandType:
+			res = append(res, entry)
+		case shared.ContextGitDiffType:
+			// invalid ones are skipped when the set is restored
+			if _, err := gitArgsForContext(entry.Name); err == nil {
+				res = append(res, entry)
+			}
+		}
+	}
+	return res
+}
+
+// isInProject reports whether a path from a context set, relative to the current directory, stays inside the project root, following symlinks where the path exists
+func isInProject(path string) bool {
+	if path == "" {
+		return true
+	}
+	inProject, err := fs.IsSubpathOf(fs.ProjectRoot, path, fs.Cwd)
+	if err != nil || !inProject {
+		return false
+	}
+
+	abs := path
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(fs.Cwd, abs)
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		// doesn't exist yet -- the stat check reports it
+		return true
+	}
+	root, err := filepath.EvalSymlinks(fs.ProjectRoot)
+	if err != nil {
+		root = fs.ProjectRoot
+	}
+	inProject, err = fs.IsSubpathOf(root, resolved, fs.Cwd)
+	return err == nil && inProject
+}
+
+// contextSetComposite identifies a context the same way loading does when it skips contexts that are already loaded
+func contextSetComposite(contextType shared.ContextType, filePath, url, name string) string {
+	switch contextType {
+	case shared.ContextFileType, shared.ContextDirectoryTreeType, shared.ContextMapType, shared.ContextImageType, shared.ContextDocumentType:
+		return strings.Join([]string{string(contextType), filePath}, "|")
+	case shared.ContextURLType:
+		return strings.Join([]string{string(contextType), url}, "|")
+	}
+	return strings.Join([]string{string(contextType), name}, "|")
+}
@@ -174,6 +174,97 @@ func processMapBatches(mapInputBatches []shared.FileMapInputs) (shared.FileMapBo
 	return allMapBodies, nil
 }
 
+// isSymbolResource checks whether a resource selects definitions from a file, like 'server/api.go#Server.Start', rather than naming a file that contains '#'
+func isSymbolResource(resource string) bool {
+	if _, err := os.Stat(resource); err == nil {
+		return false
+	}
+
+	filePath, _, ok := shared.ParseSymbolResource(resource)
+	if !ok {
+		return false
+	}
+
+	info, err := os.Stat(filePath)
+	return err == nil && !info.IsDir()
+}
+
+func readSymbolInput(filePath, pattern string) (shared.FileSymbolsInput, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return shared.FileSymbolsInput{}, fmt.Errorf("failed to read file %s: %v", filePath, err)
+	}
+
+	if len(content) > shared.MaxContextBodySize {
+		return shared.FileSymbolsInput{}, fmt.Errorf("file %s is too large to select symbols from: %d bytes (max %d)", filePath, len(content), shared.MaxContextBodySize)
+	}
+
+	return shared.FileSymbolsInput{
+		Path:    filePath,
+		Pattern: pattern,
+		Content: string(shared.NormalizeEOL(content)),
+	}, nil
+}
+
+// processSymbolBatches selects definitions for symbol contexts on the server, keyed by context name, splitting requests by the same limits as maps
+func processSymbolBatches(inputs map[string]shared.FileSymbolsInput) (*shared.GetFileSymbolsResponse, error) {
+	var batches []map[string]shared.FileSymbolsInput
+	current := map[string]shared.FileSymbolsInput{}
+	var currentSize int64
+
+	for name, input := range inputs {
+		size := int64(len(input.Content))
+		if len(current) > 0 && (len(current) >= shared.ContextMapMaxBatchSize || currentSize+size > shared.ContextMapMaxBatchBytes) {
+			batches = append(batches, current)
+			current = map[string]shared.FileSymbolsInput{}
+			currentSize = 0
+		}
+		current[name] = input
+		currentSize += size
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+
+	res := &shared.GetFileSymbolsResponse{
+		Bodies:    map[string]string{},
+		Unmatched: map[string][]string{},
+	}
+
+	var mu sync.Mutex
+	errCh := make(chan error, len(batches))
+
+	for _, batch := range batches {
+		go func(batch map[string]shared.FileSymbolsInput) {
+			batchRes, apiErr := api.Client.GetFileSymbols(shared.GetFileSymbolsRequest{
+				Inputs: batch,
+			})
+			if apiErr != nil {
+				errCh <- fmt.Errorf("failed to select symbols: %v", apiErr.Msg)
+				return
+			}
+			mu.Lock()
+			for name, body := range batchRes.Bodies {
+				res.Bodies[name] = body
+			}
+			for name, unmatched := range batchRes.Unmatched {
+				res.Unmatched[name] = unmatched
+			}
+			mu.Unlock()
+			errCh <- nil
+		}(batch)
+	}
+
+	for range batches {
+		err := <-errCh
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
 func readImageTokensForDefsOnly(path string, size int64, detail openai.ImageURLDetail, headerBytes int64) (int, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"plandex-cli/api"
+	"plandex-cli/document"
 	"plandex-cli/fs"
 	"plandex-cli/term"
 	"plandex-cli/types"
@@ -49,6 +50,10 @@ func CheckOutdatedContextWithOutput(quiet, autoConfirm bool, maybeContexts []*sh
 		term.StopSpinner()
 	}
 
+	if len(outdatedRes.SkippedCommands) > 0 {
+		printSkippedCommands(outdatedRes.SkippedCommands)
+	}
+
 	if len(outdatedRes.UpdatedContexts) == 0 && len(outdatedRes.RemovedContexts) == 0 {
 		if !quiet {
 			fmt.Println("✅ Context is up to date")
@@ -89,6 +94,38 @@ func CheckOutdatedContextWithOutput(quiet, autoConfirm bool, maybeContexts []*sh
 			lbl = strconv.Itoa(outdatedRes.NumMaps) + " " + lbl
 			types = append(types, lbl)
 		}
+		if outdatedRes.NumSymbols > 0 {
+			lbl := "symbol selection"
+			if outdatedRes.NumSymbols > 1 {
+				lbl = "symbol selections"
+			}
+			lbl = strconv.Itoa(outdatedRes.NumSymbols) + " " + lbl
+			types = append(types, lbl)
+		}
+		if outdatedRes.NumGitDiffs > 0 {
+			lbl := "git diff"
+			if outdatedRes.NumGitDiffs > 1 {
+				lbl = "git diffs"
+			}
+			lbl = strconv.Itoa(outdatedRes.NumGitDiffs) + " " + lbl
+			types = append(types, lbl)
+		}
+		if outdatedRes.NumCommands > 0 {
+			lbl := "command output"
+			if outdatedRes.NumCommands > 1 {
+				lbl = "command outputs"
+			}
+			lbl = strconv.Itoa(outdatedRes.NumCommands) + " " + lbl
+			types = append(types, lbl)
+		}
+		if outdatedRes.NumDocuments > 0 {
+			lbl := "document"
+			if outdatedRes.NumDocuments > 1 {
+				lbl = "documents"
+			}
+			lbl = strconv.Itoa(outdatedRes.NumDocuments) + " " + lbl
+			types = append(types, lbl)
+		}
 
 		var msg string
 		if len(types) <= 2 {
@@ -136,6 +173,14 @@ func CheckOutdatedContextWithOutput(quiet, autoConfirm bool, maybeContexts []*sh
 			lbl = strconv.Itoa(outdatedRes.NumTreesRemoved) + " " + lbl
 			types = append(types, lbl)
 		}
+		if outdatedRes.NumSymbolsRemoved > 0 {
+			lbl := "symbol selection"
+			if outdatedRes.NumSymbolsRemoved > 1 {
+				lbl = "symbol selections"
+			}
+			lbl = strconv.Itoa(outdatedRes.NumSymbolsRemoved) + " " + lbl
+			types = append(types, lbl)
+		}
 
 		var msg string
 		if len(types) <= 2 {
@@ -330,8 +375,13 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 	var numUrls int
 	var numTrees int
 	var numMaps int
+	var numSymbols int
+	var numGitDiffs int
+	var numCommands int
+	var numDocuments int
 	var numFilesRemoved int
 	var numTreesRemoved int
+	var numSymbolsRemoved int
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 	contextsById := make(map[string]*shared.Context)
@@ -362,8 +412,16 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 		contextsById[c.Id] = c
 	}
 
+	// symbol contexts are checked together after the loop since their definitions are selected on the server
+	var symbolContexts []*shared.Context
+
+	var skippedCommands []*shared.Context
+
 	for _, context := range contexts {
 		switch context.ContextType {
+		case shared.ContextSymbolType:
+			symbolContexts = append(symbolContexts, context)
+
 		case shared.ContextFileType:
 			wg.Add(1)
 			go func(ctx *shared.Context) {
@@ -581,6 +639,8 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 			}(context)
 
 		case shared.ContextMapType:
+			mapScope := scopeForMap(context.FilePath)
+
 			// Instead of reading all files in the same goroutine,
 			// we now spawn one goroutine per map-file to mirror the loading logic concurrency.
 			wg.Add(1)
@@ -589,8 +649,14 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 
 				// We collect paths from the existing map
 				var mapPaths []string
+				// files that are outside the plan's scope, since it was set or changed after the map was loaded, are dropped from the map
+				var outOfScopePaths []string
 				for path := range ctx.MapShas {
-					mapPaths = append(mapPaths, path)
+					if PathInScope(path, mapScope) {
+						mapPaths = append(mapPaths, path)
+					} else {
+						outOfScopePaths = append(outOfScopePaths, path)
+					}
 				}
 
 				// Next, see if there are newly added files
@@ -620,7 +686,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 
 				// If a path was not already in the map, it's newly added
 				for _, p := range flattenedPaths {
-					if _, ok := ctx.MapShas[p]; !ok {
+					if _, ok := ctx.MapShas[p]; !ok && PathInScope(p, mapScope) {
 						mapPaths = append(mapPaths, p)
 					}
 				}
@@ -638,6 +704,16 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 					mapInputBatches:      []shared.FileMapInputs{currentMapInputBatch},
 				}
 
+				if len(outOfScopePaths) > 0 {
+					mu.Lock()
+					for _, path := range outOfScopePaths {
+						state.removedMapPaths = append(state.removedMapPaths, path)
+						tokenDiffsById[ctx.Id] -= ctx.MapTokens[path]
+						state.totalMapSize -= ctx.MapSizes[path]
+					}
+					mu.Unlock()
+				}
+
 				innerExistenceErrCh := make(chan error, len(mapPaths))
 
 				// Existence: check each path in its own goroutine:
@@ -844,6 +920,168 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 
 			}(context)
 
+		case shared.ContextGitDiffType:
+			wg.Add(1)
+			go func(ctx *shared.Context) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				args, err := gitArgsForContext(ctx.Name)
+				if err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					errs = append(errs, fmt.Errorf("failed to refresh '%s' (use 'plandex rm' to remove it): %v", ctx.Name, err))
+					return
+				}
+
+				body, err := GitOutput(args...)
+				if err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					errs = append(errs, fmt.Errorf("failed to refresh '%s' (use 'plandex rm' to remove it if the ref is gone): %v", ctx.Name, err))
+					return
+				}
+
+				size := int64(len(body))
+				if size > shared.MaxContextBodySize {
+					mu.Lock()
+					defer mu.Unlock()
+					filesSkippedTooLarge = append(filesSkippedTooLarge, filePathWithSize{Path: ctx.Name, Size: size})
+					return
+				}
+
+				hash := sha256.Sum256([]byte(body))
+				newSha := hex.EncodeToString(hash[:])
+				if newSha == ctx.Sha {
+					return
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				oldBodySize := int64(len(ctx.Body))
+				if totalBodySize+(size-oldBodySize) > shared.MaxContextBodySize {
+					filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, ctx.Name)
+					return
+				}
+				totalBodySize += (size - oldBodySize)
+
+				tokenDiffsById[ctx.Id] = shared.GetNumTokensEstimate(body) - ctx.NumTokens
+				numGitDiffs++
+				updatedContexts = append(updatedContexts, ctx)
+				reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
+					return &shared.UpdateContextParams{
+						Body: body,
+					}, nil
+				}
+			}(context)
+
+		case shared.ContextCommandType:
+			// a command context's name is a shell command, and anyone who can work on the plan can add one -- only re-run the ones the current user loaded
+			if !isOwnContext(context) {
+				skippedCommands = append(skippedCommands, context)
+				continue
+			}
+
+			wg.Add(1)
+			go func(ctx *shared.Context) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				body, err := runContextCmd(ctx.Name)
+				if err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					errs = append(errs, err)
+					return
+				}
+
+				hash := sha256.Sum256([]byte(body))
+				newSha := hex.EncodeToString(hash[:])
+				if newSha == ctx.Sha {
+					return
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				size := int64(len(body))
+				oldBodySize := int64(len(ctx.Body))
+				if totalBodySize+(size-oldBodySize) > shared.MaxContextBodySize {
+					filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, ctx.Name)
+					return
+				}
+				totalBodySize += (size - oldBodySize)
+
+				tokenDiffsById[ctx.Id] = shared.GetNumTokensEstimate(body) - ctx.NumTokens
+				numCommands++
+				updatedContexts = append(updatedContexts, ctx)
+				reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
+					return &shared.UpdateContextParams{
+						Body: body,
+					}, nil
+				}
+			}(context)
+
+		case shared.ContextDocumentType:
+			wg.Add(1)
+			go func(ctx *shared.Context) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				if _, err := os.Stat(ctx.FilePath); os.IsNotExist(err) {
+					mu.Lock()
+					defer mu.Unlock()
+
+					deleteIds[ctx.Id] = true
+					numFilesRemoved++
+					tokenDiffsById[ctx.Id] = -ctx.NumTokens
+					return
+				}
+
+				// the document is re-extracted and compared by its text, so changes that don't affect the text (like metadata) don't cause an update
+				body, err := document.Extract(ctx.FilePath)
+				if err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					errs = append(errs, err)
+					return
+				}
+
+				hash := sha256.Sum256([]byte(body))
+				newSha := hex.EncodeToString(hash[:])
+				if newSha == ctx.Sha {
+					return
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				size := int64(len(body))
+				oldBodySize := int64(len(ctx.Body))
+				if size > shared.MaxContextBodySize {
+					filesSkippedTooLarge = append(filesSkippedTooLarge, filePathWithSize{Path: ctx.FilePath, Size: size})
+					return
+				}
+				if totalBodySize+(size-oldBodySize) > shared.MaxContextBodySize {
+					filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, ctx.FilePath)
+					return
+				}
+				totalBodySize += (size - oldBodySize)
+
+				tokenDiffsById[ctx.Id] = shared.GetNumTokensEstimate(body) - ctx.NumTokens
+				numDocuments++
+				updatedContexts = append(updatedContexts, ctx)
+				reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
+					return &shared.UpdateContextParams{
+						Body: body,
+					}, nil
+				}
+			}(context)
+
 		case shared.ContextURLType:
 			wg.Add(1)
 			go func(ctx *shared.Context) {
@@ -851,7 +1089,8 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 				sem <- struct{}{}
 				defer func() { <-sem }()
 
-				body, err := url.FetchURLContent(ctx.Url)
+				// with validators from the last fetch, the server can tell us the page hasn't changed without sending it again
+				page, err := url.FetchPage(ctx.Url, ctx.UrlETag, ctx.UrlLastModified)
 
 				if err != nil {
 					mu.Lock()
@@ -860,6 +1099,12 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 					return
 				}
 
+				if page.NotModified {
+					return
+				}
+
+				body := page.Body
+
 				size := int64(len(body))
 				if size > shared.MaxContextBodySize {
 					mu.Lock()
@@ -906,7 +1151,9 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 					updatedContexts = append(updatedContexts, ctx)
 					reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
 						return &shared.UpdateContextParams{
-							Body: string(body),
+							Body:            body,
+							UrlETag:         page.ETag,
+							UrlLastModified: page.LastModified,
 						}, nil
 					}
 				}
@@ -920,6 +1167,56 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 		return nil, fmt.Errorf("failed to check context outdated: %v", errs)
 	}
 
+	if len(symbolContexts) > 0 {
+		symbolInputs := map[string]shared.FileSymbolsInput{}
+		symbolContextsByName := map[string]*shared.Context{}
+
+		for _, ctx := range symbolContexts {
+			if _, err := os.Stat(ctx.FilePath); os.IsNotExist(err) {
+				deleteIds[ctx.Id] = true
+				numSymbolsRemoved++
+				tokenDiffsById[ctx.Id] = -ctx.NumTokens
+				continue
+			}
+
+			input, err := readSymbolInput(ctx.FilePath, ctx.SymbolPattern())
+			if err != nil {
+				return nil, fmt.Errorf("failed to check context outdated: %v", err)
+			}
+			symbolInputs[ctx.Name] = input
+			symbolContextsByName[ctx.Name] = ctx
+		}
+
+		if len(symbolInputs) > 0 {
+			symbolsRes, err := processSymbolBatches(symbolInputs)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check context outdated: %v", err)
+			}
+
+			for name, body := range symbolsRes.Bodies {
+				ctx := symbolContextsByName[name]
+				if ctx == nil {
+					continue
+				}
+
+				hash := sha256.Sum256([]byte(body))
+				if hex.EncodeToString(hash[:]) == ctx.Sha {
+					continue
+				}
+
+				tokenDiffsById[ctx.Id] = shared.GetNumTokensEstimate(body) - ctx.NumTokens
+				numSymbols++
+				updatedContexts = append(updatedContexts, ctx)
+
+				reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
+					return &shared.UpdateContextParams{
+						Body: body,
+					}, nil
+				}
+			}
+		}
+	}
+
 	// Identify contexts to remove
 	var removedContexts []*shared.Context
 	for id := range deleteIds {
@@ -962,16 +1259,22 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 
 	// Build final result
 	outdatedRes := types.ContextOutdatedResult{
-		UpdatedContexts: updatedContexts,
-		RemovedContexts: removedContexts,
-		TokenDiffsById:  tokenDiffsById,
-		NumFiles:        numFiles,
-		NumUrls:         numUrls,
-		NumTrees:        numTrees,
-		NumMaps:         numMaps,
-		NumFilesRemoved: numFilesRemoved,
-		NumTreesRemoved: numTreesRemoved,
-		ReqFn:           reqFn,
+		UpdatedContexts:   updatedContexts,
+		RemovedContexts:   removedContexts,
+		TokenDiffsById:    tokenDiffsById,
+		NumFiles:          numFiles,
+		NumUrls:           numUrls,
+		NumTrees:          numTrees,
+		NumMaps:           numMaps,
+		NumSymbols:        numSymbols,
+		NumGitDiffs:       numGitDiffs,
+		NumCommands:       numCommands,
+		NumDocuments:      numDocuments,
+		NumFilesRemoved:   numFilesRemoved,
+		NumTreesRemoved:   numTreesRemoved,
+		NumSymbolsRemoved: numSymbolsRemoved,
+		ReqFn:             reqFn,
+		SkippedCommands:   skippedCommands,
 	}
 
 	var hasConflicts bool
@@ -995,12 +1298,16 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 		}
 		newTotal := totalTokens + tokensDiff
 		outdatedRes.Msg = shared.SummaryForUpdateContext(shared.SummaryForUpdateContextParams{
-			NumFiles:    numFiles,
-			NumTrees:    numTrees,
-			NumUrls:     numUrls,
-			NumMaps:     numMaps,
-			TokensDiff:  tokensDiff,
-			TotalTokens: newTotal,
+			NumFiles:     numFiles,
+			NumTrees:     numTrees,
+			NumUrls:      numUrls,
+			NumMaps:      numMaps,
+			NumSymbols:   numSymbols,
+			NumGitDiffs:  numGitDiffs,
+			NumCommands:  numCommands,
+			NumDocuments: numDocuments,
+			TokensDiff:   tokensDiff,
+			TotalTokens:  newTotal,
 		})
 	}
 
@@ -7,6 +7,7 @@ import (
 	"plandex-cli/api"
 	"plandex-cli/term"
 	shared "plandex-shared"
+	"slices"
 	"sort"
 	"strings"
 )
@@ -80,6 +81,51 @@ func MaybePromptAndOpen(path string, defaultConfig *shared.PlanConfig, planConfi
 	return true
 }
 
+// flags that keep GUI editors from returning until the file is closed
+var editorWaitFlags = map[string]string{
+	"code":      "--wait",
+	"cursor":    "--wait",
+	"zed":       "--wait",
+	"subl":      "--wait",
+	"mate":      "--wait",
+	"kate":      "--block",
+	"idea":      "--wait",
+	"goland":    "--wait",
+	"pycharm":   "--wait",
+	"clion":     "--wait",
+	"webstorm":  "--wait",
+	"phpstorm":  "--wait",
+	"datagrip":  "--wait",
+	"rubymine":  "--wait",
+	"rider":     "--wait",
+	"dataspell": "--wait",
+}
+
+// EditorWaitCommand is the editor from the plan config as a command and args that don't return until the file is closed, for edits that are read back when the editor exits. GUI editors get their wait flag. If no editor is set, or files are opened manually, it falls back to $VISUAL, then $EDITOR, then vim.
+func EditorWaitCommand(config *shared.PlanConfig) []string {
+	var parts []string
+	if config != nil && config.EditorCommand != "" && !config.EditorOpenManually {
+		parts = append([]string{config.EditorCommand}, config.EditorArgs...)
+	} else {
+		for _, env := range []string{"VISUAL", "EDITOR"} {
+			// the var can include args, like 'code --wait'
+			if parts = strings.Fields(os.Getenv(env)); len(parts) > 0 {
+				break
+			}
+		}
+		if len(parts) == 0 {
+			parts = []string{shared.EditorTypeVim}
+		}
+	}
+
+	flag := editorWaitFlags[filepath.Base(parts[0])]
+	if flag != "" && !slices.Contains(parts[1:], flag) && !(flag == "--wait" && slices.Contains(parts[1:], "-w")) {
+		parts = append(parts, flag)
+	}
+
+	return parts
+}
+
 type SelectEditorResult struct {
 	Name         string
 	Cmd          string
@@ -0,0 +1,42 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestEditorWaitCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *shared.PlanConfig
+		visual string
+		editor string
+		want   string
+	}{
+		{"configured terminal editor", &shared.PlanConfig{EditorCommand: "nvim"}, "emacs", "", "nvim"},
+		{"configured args are kept", &shared.PlanConfig{EditorCommand: "hx", EditorArgs: []string{"--vsplit"}}, "", "", "hx --vsplit"},
+		{"gui editor waits", &shared.PlanConfig{EditorCommand: "code"}, "", "", "code --wait"},
+		{"jetbrains ide waits", &shared.PlanConfig{EditorCommand: "/usr/local/bin/goland"}, "", "", "/usr/local/bin/goland --wait"},
+		{"wait flag isn't repeated", &shared.PlanConfig{EditorCommand: "subl", EditorArgs: []string{"-w"}}, "", "", "subl -w"},
+		{"kate blocks", &shared.PlanConfig{EditorCommand: "kate"}, "", "", "kate --block"},
+		{"open manually uses env", &shared.PlanConfig{EditorCommand: "code", EditorOpenManually: true}, "", "nano", "nano"},
+		{"unset uses VISUAL first", &shared.PlanConfig{}, "emacs -nw", "nano", "emacs -nw"},
+		{"env gui editor waits", nil, "", "zed", "zed --wait"},
+		{"env wait flag isn't repeated", nil, "code --wait", "", "code --wait"},
+		{"defaults to vim", nil, "", "", "vim"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VISUAL", tt.visual)
+			t.Setenv("EDITOR", tt.editor)
+
+			got := strings.Join(EditorWaitCommand(tt.config), " ")
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
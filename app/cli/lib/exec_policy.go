@@ -0,0 +1,135 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/execpolicy"
+	"plandex-cli/fs"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+const ExecPolicyFile = ".plandex-policy.json"
+
+var cachedExecPolicy *execpolicy.Policy
+
+// MustGetExecPolicy merges the project's .plandex-policy.json with the org's policy. It's cached for the rest of the process, so a plan that changes the policy file can't loosen the rules for its own apply script.
+func MustGetExecPolicy() *execpolicy.Policy {
+	if cachedExecPolicy != nil {
+		return cachedExecPolicy
+	}
+
+	projectPolicy, err := LoadProjectExecPolicy()
+	if err != nil {
+		term.OutputErrorAndExit("Error loading %s: %v", ExecPolicyFile, err)
+	}
+
+	orgPolicy, apiErr := api.Client.GetOrgExecPolicy()
+	if apiErr != nil {
+		// servers from before exec policies don't have the endpoint
+		if apiErr.Status != http.StatusNotFound {
+			term.OutputErrorAndExit("Error getting org exec policy: %v", apiErr.Msg)
+		}
+		orgPolicy = nil
+	}
+
+	cachedExecPolicy = execpolicy.New(
+		execpolicy.Source{Name: "org", Policy: orgPolicy},
+		execpolicy.Source{Name: "project", Policy: projectPolicy},
+	)
+	return cachedExecPolicy
+}
+
+// LoadProjectExecPolicy reads .plandex-policy.json from the project root, returning nil if there isn't one
+func LoadProjectExecPolicy() (*shared.ExecPolicy, error) {
+	bytes, err := os.ReadFile(filepath.Join(fs.ProjectRoot, ExecPolicyFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var policy shared.ExecPolicy
+	err = json.Unmarshal(bytes, &policy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	err = policy.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// PrintExecPolicyResults shows the verdict for each statement of a script, with the rule that decided it
+func PrintExecPolicyResults(results []*execpolicy.Result) {
+	for _, res := range results {
+		icon, c := execPolicyVerdictStyle(res.Verdict)
+
+		text := res.Text
+		if i := strings.Index(text, "\n"); i >= 0 {
+			text = text[:i] + " ..."
+		}
+
+		fmt.Printf("%s %3d  %s %s\n", icon, res.Line, text, c.Sprint("→ "+execPolicyReason(res)))
+	}
+}
+
+func execPolicyVerdictStyle(verdict shared.ExecPolicyVerdict) (string, *color.Color) {
+	switch verdict {
+	case shared.ExecPolicyConfirm:
+		return "⚠️ ", color.New(term.ColorHiYellow)
+	case shared.ExecPolicyDeny:
+		return "⛔", color.New(term.ColorHiRed)
+	default:
+		return "✅", color.New(term.ColorHiGreen)
+	}
+}
+
+// strictestExecPolicyResult is the result that decided a script's verdict
+func strictestExecPolicyResult(results []*execpolicy.Result) *execpolicy.Result {
+	var res *execpolicy.Result
+	for _, r := range results {
+		if res == nil || r.Verdict.Severity() > res.Verdict.Severity() {
+			res = r
+		}
+	}
+	return res
+}
+
+func execPolicyReason(res *execpolicy.Result) string {
+	if res.Error != "" {
+		return fmt.Sprintf("%s (couldn't parse the script: %s)", res.Verdict, res.Error)
+	}
+
+	var reason string
+	switch {
+	case res.Reason != "":
+		reason = fmt.Sprintf("%s (%s)", res.Verdict, res.Reason)
+	case res.Rule != "":
+		reason = fmt.Sprintf("%s '%s' (%s)", res.Verdict, res.Rule, res.Source)
+	case res.Source != "":
+		reason = fmt.Sprintf("%s by default (%s)", res.Verdict, res.Source)
+	case res.Verdict == shared.ExecPolicyAllow:
+		reason = string(res.Verdict)
+	default:
+		reason = fmt.Sprintf("%s -- no matching rule", res.Verdict)
+	}
+
+	if res.Command != "" && res.Command != res.Text && !strings.HasPrefix(res.Text, res.Command) {
+		reason += fmt.Sprintf(" for '%s'", res.Command)
+	}
+
+	return reason
+}
@@ -214,6 +214,74 @@ func GitCheckoutFile(path string) error {
 	return nil
 }
 
+// GitOutput runs a read-only git command like 'diff main' in the current directory and returns its output, without color or external diff tools
+func GitOutput(args ...string) (string, error) {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	if len(args) == 0 {
+		return "", fmt.Errorf("no git command given")
+	}
+
+	cmdArgs := append([]string{args[0], "--no-color", "--no-ext-diff"}, args[1:]...)
+
+	res, err := exec.Command("git", cmdArgs...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("error running git %s | err: %v, output: %s", strings.Join(args, " "), err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("error running git %s | err: %v", strings.Join(args, " "), err)
+	}
+
+	return string(res), nil
+}
+
+// GitChangedFiles lists the files touched by a diff or log command like 'diff main', relative to the current directory
+func GitChangedFiles(args ...string) ([]string, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no git command given")
+	}
+
+	cmdArgs := append([]string{args[0], "--name-only", "--relative"}, args[1:]...)
+	if args[0] == "log" {
+		cmdArgs = append(cmdArgs, "--pretty=format:")
+	}
+
+	res, err := GitOutput(cmdArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return gitOutputLines(res), nil
+}
+
+// GitUntrackedFiles lists new files that aren't ignored, relative to the current directory
+func GitUntrackedFiles() ([]string, error) {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	res, err := exec.Command("git", "ls-files", "--others", "--exclude-standard").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error listing untracked files | err: %v, output: %s", err, string(res))
+	}
+
+	return gitOutputLines(string(res)), nil
+}
+
+func gitOutputLines(output string) []string {
+	var lines []string
+	seen := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || seen[line] {
+			continue
+		}
+		seen[line] = true
+		lines = append(lines, line)
+	}
+	return lines
+}
+
 const GitLogTimestampFormat = "Mon Jan 2, 2006 | 3:04:05pm"
 
 var GitLogTimestampRegex = regexp.MustCompile(`\w{3} \w{3} \d{1,2}, \d{4} \| \d{1,2}:\d{2}:\d{2}(am|pm) UTC`)
@@ -0,0 +1,117 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/fs"
+	"plandex-cli/types"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+// scopeForMap returns the plan's scope dirs that fall within a map's directory. If the plan has no scope, or the map is for a directory outside of it (so it was loaded explicitly), the map isn't narrowed and this returns nil.
+func scopeForMap(mapPath string) []string {
+	scope := MustGetCurrentPlanConfig().Scope
+	if len(scope) == 0 {
+		return nil
+	}
+
+	var res []string
+	for _, dir := range scope {
+		if ok, _ := fs.IsSubpathOf(mapPath, dir, fs.ProjectRoot); ok {
+			res = append(res, dir)
+		}
+	}
+	return res
+}
+
+// pathInMapScopes checks a path against the scopes of any narrowed maps that it falls within
+func pathInMapScopes(path string, mapScopes map[string][]string) bool {
+	for mapPath, scope := range mapScopes {
+		if ok, _ := fs.IsSubpathOf(mapPath, path, fs.ProjectRoot); ok && !PathInScope(path, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// PathInScope checks whether a path relative to the project root is inside any of the scope dirs. An empty scope includes everything.
+func PathInScope(path string, scope []string) bool {
+	if len(scope) == 0 {
+		return true
+	}
+	for _, dir := range scope {
+		if ok, _ := fs.IsSubpathOf(dir, path, fs.ProjectRoot); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ContextInScope checks whether a context belongs in a scoped listing -- contexts that aren't project files always do, as do maps and trees of directories that contain a scope dir
+func ContextInScope(context *shared.Context, scope []string) bool {
+	switch context.ContextType {
+	case shared.ContextFileType, shared.ContextImageType, shared.ContextDocumentType, shared.ContextSymbolType, shared.ContextMapType, shared.ContextDirectoryTreeType:
+	default:
+		return true
+	}
+
+	if len(scope) == 0 || context.FilePath == "" || PathInScope(context.FilePath, scope) {
+		return true
+	}
+	for _, dir := range scope {
+		if ok, _ := fs.IsSubpathOf(context.FilePath, dir, fs.ProjectRoot); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveScope turns workspace package names, package dirs, or any other directories in the project into scope dirs relative to the project root. Directories are relative to the current directory.
+func ResolveScope(args []string, packages []*types.WorkspacePackage) ([]string, error) {
+	var res []string
+	seen := map[string]bool{}
+
+	for _, arg := range args {
+		arg = strings.TrimSpace(arg)
+		if arg == "" {
+			continue
+		}
+
+		dir := ""
+		for _, pkg := range packages {
+			if pkg.Name == arg || filepath.ToSlash(pkg.Dir) == strings.TrimSuffix(filepath.ToSlash(arg), "/") {
+				dir = pkg.Dir
+				break
+			}
+		}
+
+		if dir == "" {
+			absPath, err := filepath.Abs(arg)
+			if err != nil {
+				return nil, fmt.Errorf("error resolving %s: %v", arg, err)
+			}
+			info, err := os.Stat(absPath)
+			if err != nil || !info.IsDir() {
+				return nil, fmt.Errorf("%s isn't a workspace package or a directory", arg)
+			}
+			relPath, err := filepath.Rel(fs.ProjectRoot, absPath)
+			if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(os.PathSeparator)) {
+				return nil, fmt.Errorf("%s is outside the project", arg)
+			}
+			if relPath == "." {
+				return nil, fmt.Errorf("%s is the project root -- use --clear to remove the scope instead", arg)
+			}
+			dir = relPath
+		}
+
+		if !seen[dir] {
+			seen[dir] = true
+			res = append(res, dir)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,106 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"plandex-cli/fs"
+	"plandex-cli/types"
+	"strings"
+	"testing"
+)
+
+func TestResolveScope(t *testing.T) {
+	root := t.TempDir()
+	root, _ = filepath.EvalSymlinks(root)
+	for _, dir := range []string{"packages/web", "packages/api", "services/worker", "docs"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "README.md"), []byte("readme"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// directories are relative to the current directory, which is inside the project
+	if err := os.Chdir(filepath.Join(root, "packages")); err != nil {
+		t.Fatal(err)
+	}
+	prevRoot := fs.ProjectRoot
+	fs.ProjectRoot = root
+	t.Cleanup(func() {
+		os.Chdir(wd)
+		fs.ProjectRoot = prevRoot
+	})
+
+	packages := []*types.WorkspacePackage{
+		{Name: "@acme/web", Dir: filepath.Join("packages", "web"), Kind: "pnpm"},
+		{Name: "@acme/api", Dir: filepath.Join("packages", "api"), Kind: "pnpm"},
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{"package names", []string{"@acme/web", "@acme/api"}, []string{"packages/web", "packages/api"}, ""},
+		{"package dirs with a trailing slash", []string{"packages/api/"}, []string{"packages/api"}, ""},
+		{"dirs relative to the current dir", []string{"web", "../services/worker"}, []string{"packages/web", "services/worker"}, ""},
+		{"duplicates and blanks are dropped", []string{"@acme/web", " ", "web"}, []string{"packages/web"}, ""},
+		{"not a dir", []string{"../README.md"}, nil, "isn't a workspace package or a directory"},
+		{"missing", []string{"mobile"}, nil, "isn't a workspace package or a directory"},
+		{"project root", []string{".."}, nil, "is the project root"},
+		{"outside the project", []string{"../.."}, nil, "is outside the project"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveScope(tt.args, packages)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range got {
+				got[i] = filepath.ToSlash(got[i])
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathInScope(t *testing.T) {
+	prevRoot := fs.ProjectRoot
+	fs.ProjectRoot = "/project"
+	t.Cleanup(func() { fs.ProjectRoot = prevRoot })
+
+	scope := []string{"packages/web", "services"}
+
+	tests := map[string]bool{
+		"packages/web":            true,
+		"packages/web/src/app.ts": true,
+		"services/worker/main.go": true,
+		"packages/webapp/app.ts":  false,
+		"packages/api/main.go":    false,
+		"README.md":               false,
+	}
+	for path, want := range tests {
+		if got := PathInScope(path, scope); got != want {
+			t.Errorf("%s: got %v, want %v", path, got, want)
+		}
+	}
+
+	if !PathInScope("README.md", nil) {
+		t.Error("an empty scope should include everything")
+	}
+}
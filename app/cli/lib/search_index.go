@@ -0,0 +1,472 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"plandex-cli/fs"
+	"plandex-cli/types"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	shared "plandex-shared"
+)
+
+// bump when the tokenizer changes so stale indexes are rebuilt
+const searchIndexVersion = 1
+
+const searchIndexFile = "search-index.json"
+
+// larger files are usually generated or data, and would crowd out source files in the rankings
+const searchIndexMaxFileSize = 1024 * 1024
+
+// BM25 parameters
+const (
+	searchK1 = 1.2
+	searchB  = 0.75
+)
+
+// term frequencies are weighted by where a term appears -- a match in a file's path or a definition name says more than one in the body
+const (
+	searchPathWeight   = 3.0
+	searchSymbolWeight = 2.0
+)
+
+type searchIndexDoc struct {
+	Size        int64          `json:"size"`
+	ModTime     int64          `json:"modTime"`
+	Terms       map[string]int `json:"terms"`
+	Length      int            `json:"length"`
+	Symbols     []string       `json:"symbols,omitempty"`
+	SymbolTerms map[string]int `json:"symbolTerms,omitempty"`
+}
+
+type searchIndex struct {
+	Version int                        `json:"version"`
+	Docs    map[string]*searchIndexDoc `json:"docs"`
+}
+
+// definitions across common languages, like 'func (s *Server) Start', 'def load', 'class Store', or 'pub struct Pool'
+var symbolRegex = regexp.MustCompile(`(?m)^[ \t]*(?:export[ \t]+)?(?:default[ \t]+)?(?:pub(?:\([^)]*\))?[ \t]+)?(?:(?:public|private|protected|static|abstract|final|async|data|sealed|open)[ \t]+)*(?:func|def|defp|class|type|fn|interface|struct|enum|trait|function|module|object|fun|impl)[ \t]+(?:\([^)]*\)[ \t]*)?([A-Za-z_$][A-Za-z0-9_$]*)`)
+
+var searchStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true, "by": true,
+	"can": true, "do": true, "does": true, "for": true, "from": true, "how": true, "i": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "its": true, "let": true, "make": true, "me": true, "my": true, "need": true,
+	"new": true, "no": true, "not": true, "of": true, "on": true, "or": true, "our": true, "please": true, "should": true,
+	"so": true, "that": true, "the": true, "their": true, "them": true, "then": true, "there": true, "these": true,
+	"this": true, "to": true, "up": true, "use": true, "we": true, "what": true, "when": true, "where": true,
+	"which": true, "will": true, "with": true, "would": true, "you": true, "your": true,
+}
+
+// SearchProject ranks project files against a query using a BM25 index of file contents, paths, and definition names. The index is kept in the project's plandex directory and only changed files are re-read.
+func SearchProject(query string, paths *types.ProjectPaths, limit int) ([]shared.SearchResult, error) {
+	index, err := loadSearchIndex(paths)
+	if err != nil {
+		return nil, err
+	}
+	return index.search(query, limit), nil
+}
+
+func loadSearchIndex(paths *types.ProjectPaths) (*searchIndex, error) {
+	index := &searchIndex{}
+
+	var indexPath string
+	if fs.PlandexDir != "" {
+		indexPath = filepath.Join(fs.PlandexDir, searchIndexFile)
+		b, err := os.ReadFile(indexPath)
+		if err == nil {
+			// a corrupt index is just rebuilt
+			_ = json.Unmarshal(b, index)
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read search index: %v", err)
+		}
+	}
+
+	if index.Version != searchIndexVersion || index.Docs == nil {
+		index = &searchIndex{Version: searchIndexVersion, Docs: map[string]*searchIndexDoc{}}
+	}
+
+	changed, err := index.update(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	if changed && indexPath != "" {
+		b, err := json.Marshal(index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal search index: %v", err)
+		}
+		err = os.WriteFile(indexPath, b, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write search index: %v", err)
+		}
+	}
+
+	return index, nil
+}
+
+// update re-indexes files that were added or modified since the index was saved and drops files that are gone or now ignored
+func (index *searchIndex) update(paths *types.ProjectPaths) (bool, error) {
+	changed := false
+
+	for path := range index.Docs {
+		if !paths.ActivePaths[path] {
+			delete(index.Docs, path)
+			changed = true
+		}
+	}
+
+	var mu sync.Mutex
+	errCh := make(chan error, len(paths.ActivePaths))
+	sem := make(chan struct{}, ContextMapMaxClientConcurrency)
+
+	for path := range paths.ActivePaths {
+		go func(path string) {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				// deleted since the paths were listed
+				errCh <- nil
+				return
+			}
+
+			mu.Lock()
+			existing := index.Docs[path]
+			mu.Unlock()
+			if existing != nil && existing.Size == info.Size() && existing.ModTime == info.ModTime().UnixNano() {
+				errCh <- nil
+				return
+			}
+
+			doc := &searchIndexDoc{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+
+			if info.Size() <= searchIndexMaxFileSize && !shared.IsImageFile(path) {
+				b, err := os.ReadFile(path)
+				if err != nil {
+					errCh <- fmt.Errorf("failed to read %s: %v", path, err)
+					return
+				}
+				// binary files only get their path indexed
+				if !strings.ContainsRune(string(b[:min(len(b), 8000)]), 0) {
+					indexContent(doc, string(b))
+				}
+			}
+
+			mu.Lock()
+			index.Docs[path] = doc
+			changed = true
+			mu.Unlock()
+			errCh <- nil
+		}(path)
+	}
+
+	for range paths.ActivePaths {
+		if err := <-errCh; err != nil {
+			return false, err
+		}
+	}
+
+	return changed, nil
+}
+
+func indexContent(doc *searchIndexDoc, content string) {
+	doc.Terms = map[string]int{}
+	for _, term := range searchTerms(content) {
+		doc.Terms[term]++
+		doc.Length++
+	}
+
+	seen := map[string]bool{}
+	for _, match := range symbolRegex.FindAllStringSubmatch(content, -1) {
+		name := match[1]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		doc.Symbols = append(doc.Symbols, name)
+	}
+
+	if len(doc.Symbols) > 0 {
+		doc.SymbolTerms = map[string]int{}
+		for _, name := range doc.Symbols {
+			for _, term := range searchTerms(name) {
+				doc.SymbolTerms[term]++
+			}
+		}
+	}
+}
+
+// searchTerms splits text into lowercase identifier terms. Compound identifiers like 'loadContextFiles' or 'max_tokens' are indexed whole and by their parts, so either form matches.
+func searchTerms(text string) []string {
+	var terms []string
+
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+
+	for _, word := range words {
+		if len(word) > 64 {
+			continue
+		}
+
+		parts := identifierParts(word)
+		whole := strings.ToLower(strings.Trim(word, "_"))
+		if isSearchTerm(whole) {
+			terms = append(terms, whole)
+		}
+		if len(parts) > 1 {
+			for _, part := range parts {
+				if isSearchTerm(part) {
+					terms = append(terms, part)
+				}
+			}
+		}
+	}
+
+	return terms
+}
+
+func isSearchTerm(term string) bool {
+	if len(term) < 2 {
+		return false
+	}
+	for _, r := range term {
+		if !unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// identifierParts splits an identifier on underscores and case changes: 'parseHTTPRequest_v2' -> parse, http, request, v2
+func identifierParts(word string) []string {
+	var parts []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			parts = append(parts, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+
+	runes := []rune(word)
+	for i, r := range runes {
+		if r == '_' {
+			flush()
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+
+	return parts
+}
+
+func pathTerms(path string) map[string]int {
+	res := map[string]int{}
+	for _, term := range searchTerms(filepath.ToSlash(path)) {
+		res[term]++
+	}
+	return res
+}
+
+type weightedTerm struct {
+	term   string
+	weight float64
+}
+
+func (index *searchIndex) search(query string, limit int) []shared.SearchResult {
+	if len(index.Docs) == 0 {
+		return nil
+	}
+
+	docFreq := map[string]int{}
+	pathTermsByDoc := map[string]map[string]int{}
+	var totalLength int
+	for path, doc := range index.Docs {
+		pt := pathTerms(path)
+		pathTermsByDoc[path] = pt
+
+		seen := map[string]bool{}
+		for _, terms := range []map[string]int{doc.Terms, doc.SymbolTerms, pt} {
+			for term := range terms {
+				if !seen[term] {
+					seen[term] = true
+					docFreq[term]++
+				}
+			}
+		}
+		totalLength += doc.Length
+	}
+
+	numDocs := float64(len(index.Docs))
+	avgLength := math.Max(float64(totalLength)/numDocs, 1)
+
+	queryTerms := index.expandQuery(query, docFreq)
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	var results []shared.SearchResult
+	for path, doc := range index.Docs {
+		var score float64
+		var matchedSymbols []string
+		matchedSymbolSet := map[string]bool{}
+
+		for _, qt := range queryTerms {
+			tf := float64(doc.Terms[qt.term]) +
+				searchSymbolWeight*float64(doc.SymbolTerms[qt.term]) +
+				searchPathWeight*float64(pathTermsByDoc[path][qt.term])
+			if tf == 0 {
+				continue
+			}
+
+			df := float64(docFreq[qt.term])
+			idf := math.Log(1 + (numDocs-df+0.5)/(df+0.5))
+			norm := searchK1 * (1 - searchB + searchB*float64(doc.Length)/avgLength)
+			score += qt.weight * idf * (tf * (searchK1 + 1)) / (tf + norm)
+
+			if doc.SymbolTerms[qt.term] > 0 {
+				for _, name := range doc.Symbols {
+					if matchedSymbolSet[name] {
+						continue
+					}
+					for _, term := range searchTerms(name) {
+						if term == qt.term {
+							matchedSymbolSet[name] = true
+							matchedSymbols = append(matchedSymbols, name)
+							break
+						}
+					}
+				}
+			}
+		}
+
+		if score > 0 {
+			if len(matchedSymbols) > 5 {
+				matchedSymbols = matchedSymbols[:5]
+			}
+			results = append(results, shared.SearchResult{
+				Path:    path,
+				Score:   math.Round(score*100) / 100,
+				Symbols: matchedSymbols,
+			})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			return results[i].Path < results[j].Path
+		}
+		return results[i].Score > results[j].Score
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// expandQuery turns a query into weighted terms. Terms that aren't in the index, like misspellings or partial names, are matched to the closest indexed terms by trigram similarity instead.
+func (index *searchIndex) expandQuery(query string, docFreq map[string]int) []weightedTerm {
+	var res []weightedTerm
+	seen := map[string]bool{}
+
+	add := func(term string, weight float64) {
+		if !seen[term] {
+			seen[term] = true
+			res = append(res, weightedTerm{term: term, weight: weight})
+		}
+	}
+
+	var missing []string
+	for _, term := range searchTerms(query) {
+		if searchStopWords[term] {
+			continue
+		}
+		if docFreq[term] > 0 {
+			add(term, 1)
+		} else {
+			missing = append(missing, term)
+		}
+	}
+
+	if len(missing) == 0 {
+		return res
+	}
+
+	byTrigram := map[string][]string{}
+	for term := range docFreq {
+		for _, tg := range trigrams(term) {
+			byTrigram[tg] = append(byTrigram[tg], term)
+		}
+	}
+
+	for _, term := range missing {
+		termTrigrams := trigrams(term)
+		overlap := map[string]int{}
+		for _, tg := range termTrigrams {
+			for _, candidate := range byTrigram[tg] {
+				overlap[candidate]++
+			}
+		}
+
+		type similar struct {
+			term       string
+			similarity float64
+		}
+		var matches []similar
+		for candidate, n := range overlap {
+			// Jaccard similarity of the two trigram sets
+			similarity := float64(n) / float64(len(termTrigrams)+len(trigrams(candidate))-n)
+			if similarity >= 0.5 {
+				matches = append(matches, similar{candidate, similarity})
+			}
+		}
+		sort.Slice(matches, func(i, j int) bool {
+			if matches[i].similarity == matches[j].similarity {
+				return matches[i].term < matches[j].term
+			}
+			return matches[i].similarity > matches[j].similarity
+		})
+		for i, m := range matches {
+			if i >= 3 {
+				break
+			}
+			add(m.term, m.similarity)
+		}
+	}
+
+	return res
+}
+
+func trigrams(term string) []string {
+	padded := []rune(" " + term + " ")
+	seen := map[string]bool{}
+	var res []string
+	for i := 0; i+3 <= len(padded); i++ {
+		tg := string(padded[i : i+3])
+		if !seen[tg] {
+			seen[tg] = true
+			res = append(res, tg)
+		}
+	}
+	return res
+}
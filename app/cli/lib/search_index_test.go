@@ -0,0 +1,204 @@
+package lib
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"plandex-cli/fs"
+	"plandex-cli/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIdentifierParts(t *testing.T) {
+	tests := map[string]string{
+		"loadContextFiles":    "load context files",
+		"parseHTTPRequest":    "parse http request",
+		"max_tokens":          "max tokens",
+		"parseHTTPRequest_v2": "parse http request v2",
+		"HTTPServer":          "http server",
+		"simple":              "simple",
+	}
+
+	for word, want := range tests {
+		if got := strings.Join(identifierParts(word), " "); got != want {
+			t.Errorf("%s: got %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestSearchTermsIncludeWholeAndParts(t *testing.T) {
+	got := strings.Join(searchTerms("func loadContextFiles(x int) // a 42"), " ")
+	want := "func loadcontextfiles load context files int"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func newTestSearchIndex(docs map[string]string) *searchIndex {
+	index := &searchIndex{Version: searchIndexVersion, Docs: map[string]*searchIndexDoc{}}
+	for path, content := range docs {
+		doc := &searchIndexDoc{}
+		indexContent(doc, content)
+		index.Docs[path] = doc
+	}
+	return index
+}
+
+func TestSearchRanking(t *testing.T) {
+	index := newTestSearchIndex(map[string]string{
+		"server/auth/session.go": "package auth\n\nfunc validateSession(token string) error {\n\treturn nil\n}\n",
+		"server/billing.go":      "package server\n\n// charges are checked against the session before billing\nfunc chargeCard() {}\n",
+		"server/webhooks.go":     "package server\n\nfunc deliverWebhook() {}\n\nfunc retryWebhook() {}\n\n// webhook webhook webhook\n",
+		"README.md":              "This project has a server with auth, billing, and webhooks.\n",
+	})
+
+	tests := []struct {
+		query string
+		first string
+	}{
+		// a path and definition match beats a passing mention in a comment
+		{"session", "server/auth/session.go"},
+		// repeated terms rank higher
+		{"webhook", "server/webhooks.go"},
+		// compound identifiers match by their parts
+		{"charge card", "server/billing.go"},
+		// stop words are ignored
+		{"how do we validate the session", "server/auth/session.go"},
+		// misspellings are matched to similar indexed terms
+		{"webhok", "server/webhooks.go"},
+	}
+
+	for _, tt := range tests {
+		results := index.search(tt.query, 0)
+		if len(results) == 0 {
+			t.Errorf("%q: no results", tt.query)
+			continue
+		}
+		if results[0].Path != tt.first {
+			t.Errorf("%q: got %s first, want %s (results: %+v)", tt.query, results[0].Path, tt.first, results)
+		}
+		for i := 1; i < len(results); i++ {
+			if results[i].Score > results[i-1].Score {
+				t.Errorf("%q: results aren't sorted by score: %+v", tt.query, results)
+			}
+		}
+	}
+
+	results := index.search("validate", 0)
+	if len(results) != 1 || len(results[0].Symbols) != 1 || results[0].Symbols[0] != "validateSession" {
+		t.Errorf("expected the matching definition to be returned: %+v", results)
+	}
+
+	if results := index.search("webhook", 1); len(results) != 1 {
+		t.Errorf("limit wasn't applied: %+v", results)
+	}
+
+	if results := index.search("the and of", 0); len(results) != 0 {
+		t.Errorf("expected no results for a query of stop words: %+v", results)
+	}
+
+	if results := index.search("kubernetes", 0); len(results) != 0 {
+		t.Errorf("expected no results for an unrelated term: %+v", results)
+	}
+}
+
+func TestSearchIndexIncrementalUpdate(t *testing.T) {
+	dir := t.TempDir()
+	plandexDir := filepath.Join(dir, ".plandex")
+	if err := os.Mkdir(plandexDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	prevPlandexDir := fs.PlandexDir
+	fs.PlandexDir = plandexDir
+	t.Cleanup(func() {
+		os.Chdir(wd)
+		fs.PlandexDir = prevPlandexDir
+	})
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("kept.go", "func keptFunction() {}\n")
+	write("changed.go", "func oldName() {}\n")
+	write("removed.go", "func removedFunction() {}\n")
+
+	paths := &types.ProjectPaths{ActivePaths: map[string]bool{"kept.go": true, "changed.go": true, "removed.go": true}}
+
+	_, err = loadSearchIndex(paths)
+	if err != nil {
+		t.Fatalf("error building index: %v", err)
+	}
+
+	// mark the saved doc for an unchanged file, so re-reading it would be noticed
+	indexPath := filepath.Join(plandexDir, searchIndexFile)
+	var saved searchIndex
+	b, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Docs) != 3 {
+		t.Fatalf("expected 3 indexed files, got %d", len(saved.Docs))
+	}
+	saved.Docs["kept.go"].Terms["untouchedmarker"] = 1
+	b, _ = json.Marshal(saved)
+	write(indexPath, string(b))
+
+	write("changed.go", "func newName() {}\n")
+	// the size is the same, so make sure the mod time differs
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes("changed.go", future, future); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove("removed.go")
+	delete(paths.ActivePaths, "removed.go")
+
+	index, err := loadSearchIndex(paths)
+	if err != nil {
+		t.Fatalf("error updating index: %v", err)
+	}
+
+	if len(index.Docs) != 2 || index.Docs["removed.go"] != nil {
+		t.Fatalf("removed file wasn't dropped: %v", index.Docs)
+	}
+	if index.Docs["kept.go"].Terms["untouchedmarker"] != 1 {
+		t.Fatal("unchanged file was re-indexed")
+	}
+	if index.Docs["changed.go"].Terms["newname"] == 0 || index.Docs["changed.go"].Terms["oldname"] != 0 {
+		t.Fatalf("changed file wasn't re-indexed: %v", index.Docs["changed.go"].Terms)
+	}
+
+	results := index.search("new name", 0)
+	if len(results) == 0 || results[0].Path != "changed.go" {
+		t.Fatalf("expected changed.go to match its new content: %+v", results)
+	}
+
+	// an index from an older version of the tokenizer is rebuilt
+	saved = searchIndex{Version: searchIndexVersion - 1, Docs: map[string]*searchIndexDoc{"stale.go": {}}}
+	b, _ = json.Marshal(saved)
+	write(indexPath, string(b))
+
+	index, err = loadSearchIndex(paths)
+	if err != nil {
+		t.Fatalf("error rebuilding index: %v", err)
+	}
+	if index.Docs["stale.go"] != nil || index.Docs["kept.go"].Terms["untouchedmarker"] != 0 {
+		t.Fatal("index from an older version wasn't rebuilt")
+	}
+}
@@ -0,0 +1,386 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/execpolicy"
+	"plandex-cli/fs"
+	"plandex-cli/term"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+const VerifyConfigFile = ".plandex-verify.json"
+
+const defaultVerifyTimeout = 120 * time.Second
+
+// how long to wait for a timed out command's output pipes to close after its process group is killed
+const verifyWaitDelay = 5 * time.Second
+
+// output beyond this is cut from the start, since the end of a tool's output usually has the summary
+const maxVerifyOutputBytes = 20000
+
+// VerifyConfig is the project's verification commands, from .plandex-verify.json in the project root. They run against a scratch copy of the project with the pending changes applied, after a build and before the changes menu.
+type VerifyConfig struct {
+	Commands []*VerifyCommand `json:"commands"`
+	// send failures back to the model to fix without asking, up to MaxFixAttempts times in a row
+	AutoFix        bool `json:"autoFix,omitempty"`
+	MaxFixAttempts int  `json:"maxFixAttempts,omitempty"`
+}
+
+type VerifyCommand struct {
+	// shown in place of the command when set, like 'vet'
+	Name string `json:"name,omitempty"`
+	Run  string `json:"run"`
+	// subdirectory of the project to run in
+	Dir string `json:"dir,omitempty"`
+	// only run when a pending change matches one of these globs, like '*.go' or 'web/src/*'. Globs without a slash match file names in any directory.
+	Paths []string `json:"paths,omitempty"`
+	// fail when the command prints anything, for commands like 'gofmt -l' that exit 0 when they find problems
+	FailOnOutput bool `json:"failOnOutput,omitempty"`
+	// seconds -- defaults to 120
+	Timeout int `json:"timeout,omitempty"`
+}
+
+type VerifyResult struct {
+	Command    *VerifyCommand
+	Passed     bool
+	Skipped    bool
+	SkipReason string
+	ExitCode   int
+	TimedOut   bool
+	Output     string
+}
+
+func (c *VerifyCommand) Label() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Run
+}
+
+func (c *VerifyConfig) GetMaxFixAttempts() int {
+	if c.MaxFixAttempts > 0 {
+		return c.MaxFixAttempts
+	}
+	return 3
+}
+
+// LoadVerifyConfig reads .plandex-verify.json from the project root, returning nil if there isn't one
+func LoadVerifyConfig() (*VerifyConfig, error) {
+	bytes, err := os.ReadFile(filepath.Join(fs.ProjectRoot, VerifyConfigFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var config VerifyConfig
+	err = json.Unmarshal(bytes, &config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	for i, cmd := range config.Commands {
+		if strings.TrimSpace(cmd.Run) == "" {
+			return nil, fmt.Errorf("command %d has no 'run'", i+1)
+		}
+		if cmd.Dir != "" && (filepath.IsAbs(cmd.Dir) || strings.HasPrefix(filepath.Clean(cmd.Dir), "..")) {
+			return nil, fmt.Errorf("command '%s' has a dir outside the project", cmd.Label())
+		}
+	}
+
+	if len(config.Commands) == 0 {
+		return nil, nil
+	}
+
+	return &config, nil
+}
+
+// VerifyPendingChanges runs the verification commands against a scratch copy of the project with the plan's pending changes applied. They're checked against the exec policy first, and run in the plan's exec sandbox with its env allowlist, just like the apply script, since they run code the model wrote that hasn't been reviewed. It returns nil results if there are no pending file changes or none of the commands apply to them. The caller checks that the user can exec.
+func VerifyPendingChanges(config *VerifyConfig, planId, branch string) ([]*VerifyResult, error) {
+	planState, apiErr := api.Client.GetCurrentPlanState(planId, branch)
+	if apiErr != nil {
+		return nil, fmt.Errorf("error getting current plan state: %v", apiErr.Msg)
+	}
+
+	files := map[string]string{}
+	for path, content := range planState.CurrentPlanFiles.Files {
+		if path != "_apply.sh" {
+			files[path] = content
+		}
+	}
+	removed := planState.CurrentPlanFiles.Removed
+
+	var changed []string
+	for path := range files {
+		changed = append(changed, path)
+	}
+	for path := range removed {
+		changed = append(changed, path)
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	applies := map[*VerifyCommand]bool{}
+	for _, cmd := range config.Commands {
+		if verifyCommandApplies(cmd, changed) {
+			applies[cmd] = true
+		}
+	}
+	if len(applies) == 0 {
+		return nil, nil
+	}
+
+	var toRun []*VerifyCommand
+	for _, cmd := range config.Commands {
+		if applies[cmd] {
+			toRun = append(toRun, cmd)
+		}
+	}
+	skipReasons := checkVerifyPolicy(toRun)
+
+	planConfig := MustGetCurrentPlanConfig()
+
+	term.StartSpinner("")
+	dir, err := fs.CreateScratchCopy(fs.ProjectRoot, files, removed)
+	term.StopSpinner()
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	var results []*VerifyResult
+	for _, cmd := range config.Commands {
+		if !applies[cmd] {
+			results = append(results, &VerifyResult{Command: cmd, Skipped: true, Passed: true, SkipReason: "no matching changes"})
+			continue
+		}
+		if reason := skipReasons[cmd]; reason != "" {
+			results = append(results, &VerifyResult{Command: cmd, Skipped: true, Passed: true, SkipReason: reason})
+			continue
+		}
+
+		term.StartSpinner("🔎 " + cmd.Label())
+		res, err := runVerifyCommand(cmd, dir, planConfig)
+		term.StopSpinner()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
+func verifyCommandApplies(cmd *VerifyCommand, changed []string) bool {
+	if len(cmd.Paths) == 0 {
+		return true
+	}
+	for _, changedPath := range changed {
+		changedPath = filepath.ToSlash(changedPath)
+		for _, pattern := range cmd.Paths {
+			var ok bool
+			if strings.Contains(pattern, "/") {
+				ok, _ = path.Match(pattern, changedPath)
+				if !ok {
+					// a dir pattern like 'web/src' or 'web/src/*' covers everything below it
+					prefix := strings.TrimSuffix(strings.TrimSuffix(pattern, "*"), "/")
+					ok = !strings.ContainsAny(prefix, "*?[") && strings.HasPrefix(changedPath, prefix+"/")
+				}
+			} else {
+				ok, _ = path.Match(pattern, path.Base(changedPath))
+			}
+			if ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkVerifyPolicy checks the commands that are about to run against the exec policy, like the apply script. Denied commands are skipped, and so are commands that need confirmation unless the user confirms them. It returns why each skipped command is skipped.
+func checkVerifyPolicy(cmds []*VerifyCommand) map[*VerifyCommand]string {
+	policy := MustGetExecPolicy()
+	if policy.IsEmpty() {
+		return nil
+	}
+
+	skipReasons := map[*VerifyCommand]string{}
+	var flagged, toConfirm []*VerifyCommand
+	decidedBy := map[*VerifyCommand]*execpolicy.Result{}
+
+	for _, cmd := range cmds {
+		res := strictestExecPolicyResult(policy.Evaluate(cmd.Run))
+		if res == nil || res.Verdict == shared.ExecPolicyAllow {
+			continue
+		}
+		flagged = append(flagged, cmd)
+		decidedBy[cmd] = res
+		if res.Verdict == shared.ExecPolicyDeny {
+			skipReasons[cmd] = "blocked by the exec policy"
+		} else {
+			toConfirm = append(toConfirm, cmd)
+		}
+	}
+
+	if len(flagged) == 0 {
+		return nil
+	}
+
+	term.StopSpinner()
+	color.New(term.ColorHiCyan, color.Bold).Println("🛡️  Exec policy")
+	for _, cmd := range flagged {
+		icon, c := execPolicyVerdictStyle(decidedBy[cmd].Verdict)
+		fmt.Printf("%s %s %s\n", icon, cmd.Label(), c.Sprint("→ "+execPolicyReason(decidedBy[cmd])))
+	}
+	fmt.Println()
+
+	if len(toConfirm) > 0 {
+		confirmed, err := term.ConfirmYesNo("Run the verification commands that need confirmation?")
+		if err != nil {
+			term.OutputErrorAndExit("Error getting confirmation: %v", err)
+		}
+		fmt.Println()
+		if !confirmed {
+			for _, cmd := range toConfirm {
+				skipReasons[cmd] = "not confirmed"
+			}
+		}
+	}
+
+	return skipReasons
+}
+
+// runVerifyCommand runs a command in the scratch copy, in the plan's exec sandbox if it has one. The project is readable in the sandbox so links to its dependency dirs resolve. It only returns an error if the command couldn't be set up.
+func runVerifyCommand(cmd *VerifyCommand, scratchDir string, planConfig *shared.PlanConfig) (*VerifyResult, error) {
+	timeout := defaultVerifyTimeout
+	if cmd.Timeout > 0 {
+		timeout = time.Duration(cmd.Timeout) * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	c, err := sandboxCmd(ctx, planConfig, scratchDir, filepath.Join(scratchDir, cmd.Dir), []string{fs.ProjectRoot}, "/bin/bash", cmd.Run)
+	if err != nil {
+		return nil, fmt.Errorf("error setting up exec sandbox: %v", err)
+	}
+	SetPlatformSpecificAttrs(c)
+	c.Cancel = func() error {
+		return KillProcessGroup(c, 9)
+	}
+	c.WaitDelay = verifyWaitDelay
+
+	out, err := c.CombinedOutput()
+	output := strings.TrimSpace(strings.ReplaceAll(string(out), scratchDir+string(os.PathSeparator), ""))
+	if len(output) > maxVerifyOutputBytes {
+		output = "[...]\n" + output[len(output)-maxVerifyOutputBytes:]
+	}
+
+	res := &VerifyResult{Command: cmd, Output: output, Passed: true}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		res.Passed = false
+		res.TimedOut = true
+		return res, nil
+	}
+
+	if err != nil {
+		res.Passed = false
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			res.ExitCode = exitErr.ExitCode()
+		} else {
+			res.ExitCode = -1
+			res.Output = strings.TrimSpace(res.Output + "\n" + err.Error())
+		}
+	} else if cmd.FailOnOutput && output != "" {
+		res.Passed = false
+	}
+
+	return res, nil
+}
+
+func VerifyFailed(results []*VerifyResult) bool {
+	for _, res := range results {
+		if !res.Passed {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintVerifyResults shows a line per command, with the output of failed ones
+func PrintVerifyResults(results []*VerifyResult) {
+	color.New(term.ColorHiCyan, color.Bold).Println("🔎 Verifying pending changes")
+
+	for _, res := range results {
+		switch {
+		case res.Skipped:
+			fmt.Printf("⏭️  %s (%s)\n", res.Command.Label(), res.SkipReason)
+		case res.Passed:
+			fmt.Printf("✅ %s\n", res.Command.Label())
+		default:
+			color.New(term.ColorHiRed, color.Bold).Printf("❌ %s %s\n", res.Command.Label(), describeVerifyFailure(res))
+			if res.Output != "" {
+				lines := strings.Split(res.Output, "\n")
+				// the full output goes to the model -- the terminal only gets the end of it
+				if len(lines) > 30 {
+					lines = append([]string{fmt.Sprintf("[%d lines cut]", len(lines)-30)}, lines[len(lines)-30:]...)
+				}
+				for _, line := range lines {
+					fmt.Println("   " + line)
+				}
+			}
+		}
+	}
+
+	fmt.Println()
+}
+
+func describeVerifyFailure(res *VerifyResult) string {
+	switch {
+	case res.TimedOut:
+		return "(timed out)"
+	case res.ExitCode != 0:
+		return fmt.Sprintf("(exit status %d)", res.ExitCode)
+	default:
+		return "(printed output)"
+	}
+}
+
+// VerifyFixPrompt asks the model to fix the failed verification commands
+func VerifyFixPrompt(results []*VerifyResult) string {
+	var b strings.Builder
+	b.WriteString("The pending changes fail these verification commands, which were run against the project with the changes applied:\n\n")
+
+	for _, res := range results {
+		if res.Passed {
+			continue
+		}
+		fmt.Fprintf(&b, "`%s` %s", res.Command.Run, describeVerifyFailure(res))
+		if res.Command.Dir != "" {
+			fmt.Fprintf(&b, " in %s", res.Command.Dir)
+		}
+		if res.Output == "" {
+			b.WriteString(" with no output\n\n")
+		} else {
+			b.WriteString(":\n\n```\n" + res.Output + "\n```\n\n")
+		}
+	}
+
+	b.WriteString("Fix the pending changes so that these commands pass.")
+	return b.String()
+}
@@ -40,6 +40,13 @@ var allHotkeyOptions = []hotkeyOption{
 		replOnly:     false,
 		terminalOnly: false,
 	},
+	{
+		char:         "v",
+		command:      "review",
+		description:  "Accept, reject, edit, or comment on each change",
+		replOnly:     false,
+		terminalOnly: false,
+	},
 	{
 		char:         "r",
 		command:      "reject",
@@ -242,6 +249,15 @@ func handleHotkeyOption(option hotkeyOption, diffs []string, params ExecParams)
 		}
 		fmt.Println()
 		os.Exit(0)
+	} else if option.char == "v" {
+		fmt.Println()
+		_, err := lib.ExecPlandexCommand([]string{"review"})
+		if err != nil {
+			fmt.Printf("\nError reviewing changes: %v\n", err)
+		}
+		fmt.Println()
+		// feedback from the review starts a new response with its own menu
+		os.Exit(0)
 	} else if option.char == "r" {
 		fmt.Println()
 		_, err := lib.ExecPlandexCommand([]string{"reject"})
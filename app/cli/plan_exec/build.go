@@ -3,9 +3,11 @@ package plan_exec
 import (
 	"fmt"
 	"log"
+	"os"
 	"plandex-cli/api"
 	"plandex-cli/fs"
 	"plandex-cli/stream"
+	streamjson "plandex-cli/stream_json"
 	streamtui "plandex-cli/stream_tui"
 	"plandex-cli/term"
 	"plandex-cli/types"
@@ -66,7 +68,13 @@ func Build(params ExecParams, flags types.BuildFlags) (bool, error) {
 		return false, fmt.Errorf("error building plan: %v", apiErr.Msg)
 	}
 
-	if !buildBg {
+	if flags.JSON {
+		err := streamjson.Run()
+		if err != nil {
+			log.Println("JSON stream error:", err)
+			os.Exit(1)
+		}
+	} else if !buildBg {
 		ch := make(chan error)
 
 		go func() {
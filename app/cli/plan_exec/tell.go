@@ -7,7 +7,9 @@ import (
 	"plandex-cli/api"
 	"plandex-cli/auth"
 	"plandex-cli/fs"
+	"plandex-cli/lib"
 	"plandex-cli/stream"
+	streamjson "plandex-cli/stream_json"
 	streamtui "plandex-cli/stream_tui"
 	"plandex-cli/term"
 	"plandex-cli/types"
@@ -23,6 +25,9 @@ import (
 // For cloud trials in Integrated Models mode, we warn after the stream finishes when the balance is less than $1
 const CloudTrialBalanceWarningThreshold = 1
 
+// how many files ranked by the local search index are sent with an auto-context prompt
+const autoContextSearchCandidates = 30
+
 func TellPlan(
 	params ExecParams,
 	prompt string,
@@ -42,6 +47,7 @@ func TellPlan(
 	isApplyDebug := flags.IsApplyDebug
 	isImplementationOfChat := flags.IsImplementationOfChat
 	skipChangesMenu := flags.SkipChangesMenu
+	jsonOutput := flags.JSON
 	done := make(chan struct{})
 
 	if prompt == "" && isImplementationOfChat {
@@ -49,7 +55,7 @@ func TellPlan(
 	}
 
 	outputPromptIfTell := func() {
-		if isUserContinue || prompt == "" {
+		if isUserContinue || prompt == "" || jsonOutput {
 			return
 		}
 
@@ -106,6 +112,17 @@ func TellPlan(
 		os.Exit(0)
 	}
 
+	var searchCandidates []shared.SearchResult
+	if autoContext && prompt != "" {
+		// preselects candidate files for the architect, which matters most when the project map is too large to include whole
+		searchCandidates, err = lib.SearchProject(prompt, paths, autoContextSearchCandidates)
+		if err != nil {
+			// search results are only a hint, so don't block the prompt on them
+			log.Printf("Error searching project for auto-context candidates: %v", err)
+			searchCandidates = nil
+		}
+	}
+
 	var fn func() bool
 	fn = func() bool {
 
@@ -148,6 +165,7 @@ func TellPlan(
 			IsImplementationOfChat: isImplementationOfChat,
 			IsGitRepo:              isGitRepo,
 			SessionId:              os.Getenv("PLANDEX_REPL_SESSION_ID"),
+			SearchCandidates:       searchCandidates,
 		}, stream.OnStreamPlan)
 
 		term.StopSpinner()
@@ -182,7 +200,16 @@ func TellPlan(
 			os.Exit(0)
 		}
 
-		if !tellBg {
+		if jsonOutput {
+			go func() {
+				err := streamjson.Run()
+				if err != nil {
+					log.Println("JSON stream error:", err)
+					os.Exit(1)
+				}
+				close(done)
+			}()
+		} else if !tellBg {
 			go func() {
 				err := streamtui.StartStreamUI(
 					prompt,
@@ -229,7 +256,10 @@ func TellPlan(
 					}
 				} else if autoApply || isDebugCmd || isApplyDebug {
 					term.StopSpinner()
-					// do nothing, allow auto apply to run
+					if autoApply {
+						verifyBeforeAutoApply(params, flags)
+					}
+					// allow auto apply to run
 				} else if skipChangesMenu {
 					term.StopSpinner()
 					// script mode, don't show menu
@@ -248,7 +278,9 @@ func TellPlan(
 
 					fmt.Println()
 
-					if tellStop && hasDiffs {
+					if hasDiffs && verifyBeforeMenu(params, flags) {
+						// the fix request showed its own menu
+					} else if tellStop && hasDiffs {
 						if hasDiffs {
 							// term.PrintCmds("", "continue", "diff", "diff --ui", "apply", "reject", "log")
 							showHotkeyMenu(diffs)
@@ -0,0 +1,103 @@
+package plan_exec
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"plandex-cli/types"
+
+	"github.com/fatih/color"
+)
+
+// verifyBeforeMenu runs the project's verification commands against the pending changes after a build. If any fail and the failures are sent back to the model, it tells the plan to fix them and returns true, since that tell shows its own changes menu when it finishes.
+func verifyBeforeMenu(params ExecParams, flags types.TellFlags) bool {
+	_, fixed := verifyPendingChanges(params, flags)
+	return fixed
+}
+
+// verifyBeforeAutoApply runs the verification commands before changes are applied automatically. If any fail and the failures aren't sent back to the model, it asks whether to apply the changes anyway and exits if not. A fix request verifies its own changes before they're applied.
+func verifyBeforeAutoApply(params ExecParams, flags types.TellFlags) {
+	failed, fixed := verifyPendingChanges(params, flags)
+	if !failed || fixed {
+		return
+	}
+
+	apply, err := term.ConfirmYesNo("Apply the changes anyway?")
+	if err != nil {
+		term.OutputErrorAndExit("Error getting confirmation: %v", err)
+	}
+	fmt.Println()
+
+	if !apply {
+		fmt.Println("🙅‍♂️ Changes weren't applied")
+		fmt.Println()
+		term.PrintCmds("", "diff", "tell", "apply", "reject")
+		os.Exit(0)
+	}
+}
+
+// verifyPendingChanges returns whether any verification command failed, and whether the failures were sent back to the model to fix
+func verifyPendingChanges(params ExecParams, flags types.TellFlags) (bool, bool) {
+	config, err := lib.LoadVerifyConfig()
+	if err != nil {
+		color.New(term.ColorHiYellow, color.Bold).Printf("⚠️  Skipped verification -- error loading %s: %v\n\n", lib.VerifyConfigFile, err)
+		return false, false
+	}
+	if config == nil {
+		return false, false
+	}
+
+	// verification commands run code the model wrote, so they follow the same exec setting as the apply script
+	if !flags.ExecEnabled {
+		color.New(term.ColorHiYellow, color.Bold).Println("⚠️  Skipped verification -- execution is disabled for this plan")
+		fmt.Println()
+		return false, false
+	}
+
+	results, err := lib.VerifyPendingChanges(config, params.CurrentPlanId, params.CurrentBranch)
+	if err != nil {
+		color.New(term.ColorHiYellow, color.Bold).Printf("⚠️  Skipped verification -- %v\n\n", err)
+		return false, false
+	}
+	if results == nil {
+		return false, false
+	}
+
+	lib.PrintVerifyResults(results)
+
+	if !lib.VerifyFailed(results) {
+		return false, false
+	}
+
+	var fix bool
+	maxAttempts := config.GetMaxFixAttempts()
+	if config.AutoFix && flags.VerifyFixAttempt < maxAttempts {
+		fix = true
+		fmt.Printf("🔧 Sending failures to the model to fix (attempt %d/%d)\n", flags.VerifyFixAttempt+1, maxAttempts)
+		fmt.Println()
+	} else {
+		if config.AutoFix {
+			color.New(term.ColorHiYellow, color.Bold).Printf("⚠️  Still failing after %d automatic fix attempts\n", maxAttempts)
+		}
+		fix, err = term.ConfirmYesNo("Send failures to the model to fix?")
+		if err != nil {
+			term.OutputErrorAndExit("Error getting confirmation: %v", err)
+		}
+		fmt.Println()
+	}
+
+	if !fix {
+		return true, false
+	}
+
+	fixFlags := flags
+	fixFlags.IsUserContinue = false
+	fixFlags.IsImplementationOfChat = false
+	fixFlags.TellStop = false
+	fixFlags.VerifyFixAttempt++
+
+	TellPlan(params, lib.VerifyFixPrompt(results), fixFlags)
+
+	return true, true
+}
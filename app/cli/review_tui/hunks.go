@@ -0,0 +1,327 @@
+package reviewtui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+type decision int
+
+const (
+	decisionNone decision = iota
+	decisionAccepted
+	decisionRejected
+)
+
+// Hunk is a single pending change to review -- a replacement within a file, or a whole new or removed file
+type Hunk struct {
+	Path        string
+	Replacement *shared.Replacement
+
+	NewFile     bool
+	RemovedFile bool
+	Content     string
+
+	// replacements from older builds include line numbers, so they can't be edited
+	CanEdit bool
+
+	// position within the file
+	Num       int
+	NumInFile int
+
+	decision decision
+	edited   *string
+	Comment  string
+}
+
+func (h *Hunk) IsWholeFile() bool {
+	return h.Replacement == nil
+}
+
+func (h *Hunk) Rejected() bool {
+	return h.decision == decisionRejected
+}
+
+func (h *Hunk) Edited() (string, bool) {
+	if h.edited == nil || h.decision == decisionRejected {
+		return "", false
+	}
+	return *h.edited, true
+}
+
+func (h *Hunk) newText() string {
+	if h.edited != nil {
+		return *h.edited
+	}
+	if h.Replacement != nil {
+		return h.Replacement.New
+	}
+	return h.Content
+}
+
+// GetHunks lists the pending changes in a plan's current state, in path order. The apply script isn't included.
+func GetHunks(planState *shared.CurrentPlanState) []*Hunk {
+	var hunks []*Hunk
+
+	for _, path := range planState.PlanResult.SortedPaths {
+		if path == "_apply.sh" {
+			continue
+		}
+
+		var fileHunks []*Hunk
+		for _, result := range planState.PlanResult.FileResultsByPath[path] {
+			if !result.IsPending() {
+				continue
+			}
+
+			if result.RemovedFile {
+				fileHunks = append(fileHunks, &Hunk{Path: path, RemovedFile: true})
+				continue
+			}
+
+			if len(result.Replacements) == 0 {
+				fileHunks = append(fileHunks, &Hunk{Path: path, NewFile: true, Content: result.Content})
+				continue
+			}
+
+			for _, replacement := range result.Replacements {
+				if !replacement.IsPending() {
+					continue
+				}
+				fileHunks = append(fileHunks, &Hunk{
+					Path:        path,
+					Replacement: replacement,
+					CanEdit:     !result.ReplaceWithLineNums,
+				})
+			}
+		}
+
+		for i, hunk := range fileHunks {
+			hunk.Num = i + 1
+			hunk.NumInFile = len(fileHunks)
+		}
+
+		hunks = append(hunks, fileHunks...)
+	}
+
+	return hunks
+}
+
+// ReviewRequest collects the rejected and edited replacements. Whole-file changes can't be rejected by replacement, so their paths are returned separately to be rejected as files. Rejecting a file rejects every pending change to it, so the file's other hunks are left out of the request.
+func ReviewRequest(hunks []*Hunk) (shared.ReviewReplacementsRequest, []string) {
+	req := shared.ReviewReplacementsRequest{
+		EditedById: map[string]string{},
+	}
+	rejectedPaths := rejectedFiles(hunks)
+
+	for _, hunk := range hunks {
+		if hunk.IsWholeFile() || rejectedPaths[hunk.Path] {
+			continue
+		}
+		if hunk.Rejected() {
+			req.RejectedIds = append(req.RejectedIds, hunk.Replacement.Id)
+		} else if edited, ok := hunk.Edited(); ok {
+			req.EditedById[hunk.Replacement.Id] = edited
+		}
+	}
+
+	var paths []string
+	for path := range rejectedPaths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return req, paths
+}
+
+// KeptInRejectedFiles lists the paths where a whole-file change is rejected but other changes to the file were kept or edited. Rejecting the file would reject those too.
+func KeptInRejectedFiles(hunks []*Hunk) []string {
+	rejectedPaths := rejectedFiles(hunks)
+	conflicted := map[string]bool{}
+	var paths []string
+
+	for _, hunk := range hunks {
+		if !rejectedPaths[hunk.Path] || hunk.Rejected() || conflicted[hunk.Path] {
+			continue
+		}
+		conflicted[hunk.Path] = true
+		paths = append(paths, hunk.Path)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// KeepWholeFileChanges undoes the rejection of the whole-file changes to the given paths
+func KeepWholeFileChanges(hunks []*Hunk, paths []string) {
+	keep := map[string]bool{}
+	for _, path := range paths {
+		keep[path] = true
+	}
+
+	for _, hunk := range hunks {
+		if hunk.IsWholeFile() && keep[hunk.Path] && hunk.Rejected() {
+			hunk.decision = decisionNone
+		}
+	}
+}
+
+func rejectedFiles(hunks []*Hunk) map[string]bool {
+	res := map[string]bool{}
+	for _, hunk := range hunks {
+		if hunk.IsWholeFile() && hunk.Rejected() {
+			res[hunk.Path] = true
+		}
+	}
+	return res
+}
+
+// FeedbackPrompt asks the model to follow up on the comments left during review
+func FeedbackPrompt(hunks []*Hunk) string {
+	var b strings.Builder
+	b.WriteString("I reviewed the pending changes and left comments on some of them.\n\n")
+
+	for _, hunk := range hunks {
+		if hunk.Comment == "" {
+			continue
+		}
+
+		var status string
+		switch {
+		case hunk.Rejected():
+			status = "rejected"
+		case hunk.edited != nil:
+			status = "edited and kept"
+		default:
+			status = "kept"
+		}
+
+		var label string
+		switch {
+		case hunk.NewFile:
+			label = "new file"
+		case hunk.RemovedFile:
+			label = "removal of the file"
+		default:
+			label = fmt.Sprintf("change %d of %d", hunk.Num, hunk.NumInFile)
+		}
+
+		fmt.Fprintf(&b, "%s (%s, %s):\n\n", hunk.Path, label, status)
+
+		if !hunk.IsWholeFile() {
+			b.WriteString("```diff\n")
+			for _, line := range diffLines(hunk.Replacement.Old, hunk.newText()) {
+				b.WriteString(line.prefix() + line.text + "\n")
+			}
+			b.WriteString("```\n\n")
+		}
+
+		fmt.Fprintf(&b, "Comment: %s\n\n", hunk.Comment)
+	}
+
+	b.WriteString("Rejected changes have been removed from the pending changes. Update the plan based on these comments.")
+	return b.String()
+}
+
+func HasComments(hunks []*Hunk) bool {
+	for _, hunk := range hunks {
+		if hunk.Comment != "" {
+			return true
+		}
+	}
+	return false
+}
+
+const maxDiffCells = 1000000
+
+type diffLineKind int
+
+const (
+	diffContext diffLineKind = iota
+	diffRemoved
+	diffAdded
+)
+
+type diffLine struct {
+	kind diffLineKind
+	text string
+}
+
+func (l diffLine) prefix() string {
+	switch l.kind {
+	case diffRemoved:
+		return "-"
+	case diffAdded:
+		return "+"
+	default:
+		return " "
+	}
+}
+
+// diffLines is a line diff of a replacement's old and new text, which include the surrounding context lines
+func diffLines(old, new string) []diffLine {
+	var a, b []string
+	if old != "" {
+		a = strings.Split(old, "\n")
+	}
+	if new != "" {
+		b = strings.Split(new, "\n")
+	}
+
+	// hunks are usually small, but a replacement of a whole file may not be -- just show it as removed and added
+	if len(a)*len(b) > maxDiffCells {
+		var res []diffLine
+		for _, line := range a {
+			res = append(res, diffLine{diffRemoved, line})
+		}
+		for _, line := range b {
+			res = append(res, diffLine{diffAdded, line})
+		}
+		return res
+	}
+
+	// longest common subsequence
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var res []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			res = append(res, diffLine{diffContext, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			res = append(res, diffLine{diffRemoved, a[i]})
+			i++
+		default:
+			res = append(res, diffLine{diffAdded, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		res = append(res, diffLine{diffRemoved, a[i]})
+	}
+	for ; j < len(b); j++ {
+		res = append(res, diffLine{diffAdded, b[j]})
+	}
+
+	return res
+}
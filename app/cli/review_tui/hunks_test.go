@@ -0,0 +1,229 @@
+package reviewtui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+)
+
+func testPlanState() *shared.CurrentPlanState {
+	now := time.Now()
+	return &shared.CurrentPlanState{
+		PlanResult: &shared.PlanResult{
+			SortedPaths: []string{"_apply.sh", "a.go", "b.go", "c.go", "d.go"},
+			FileResultsByPath: shared.PlanFileResultsByPath{
+				"_apply.sh": {{Path: "_apply.sh", Content: "go test ./..."}},
+				"a.go": {
+					{Path: "a.go", Replacements: []*shared.Replacement{
+						{Id: "a1", Old: "x", New: "X"},
+						{Id: "a2", Old: "y", New: "Y", RejectedAt: &now},
+						{Id: "a3", Old: "z", New: "Z"},
+					}},
+					{Path: "a.go", AppliedAt: &now, Replacements: []*shared.Replacement{{Id: "a4", Old: "w", New: "W"}}},
+				},
+				"b.go": {{Path: "b.go", Content: "package b"}},
+				"c.go": {{Path: "c.go", RemovedFile: true}},
+				"d.go": {{Path: "d.go", ReplaceWithLineNums: true, Replacements: []*shared.Replacement{
+					{Id: "d1", Old: "pdx-1: x", New: "pdx-1: X"},
+				}}},
+			},
+		},
+	}
+}
+
+func TestGetHunks(t *testing.T) {
+	hunks := GetHunks(testPlanState())
+
+	type hunkSummary struct {
+		path           string
+		id             string
+		newFile        bool
+		removedFile    bool
+		canEdit        bool
+		num, numInFile int
+	}
+
+	want := []hunkSummary{
+		{path: "a.go", id: "a1", canEdit: true, num: 1, numInFile: 2},
+		{path: "a.go", id: "a3", canEdit: true, num: 2, numInFile: 2},
+		{path: "b.go", newFile: true, num: 1, numInFile: 1},
+		{path: "c.go", removedFile: true, num: 1, numInFile: 1},
+		{path: "d.go", id: "d1", num: 1, numInFile: 1},
+	}
+
+	var got []hunkSummary
+	for _, hunk := range hunks {
+		s := hunkSummary{
+			path:        hunk.Path,
+			newFile:     hunk.NewFile,
+			removedFile: hunk.RemovedFile,
+			canEdit:     hunk.CanEdit,
+			num:         hunk.Num,
+			numInFile:   hunk.NumInFile,
+		}
+		if hunk.Replacement != nil {
+			s.id = hunk.Replacement.Id
+		}
+		got = append(got, s)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got hunks\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestReviewRequest(t *testing.T) {
+	edited := "EDITED"
+
+	tests := []struct {
+		name          string
+		hunks         []*Hunk
+		wantRejected  []string
+		wantEdited    map[string]string
+		wantPaths     []string
+		wantConflicts []string
+	}{
+		{
+			name: "nothing decided",
+			hunks: []*Hunk{
+				{Path: "a.go", Replacement: &shared.Replacement{Id: "a1"}},
+			},
+			wantEdited: map[string]string{},
+		},
+		{
+			name: "rejected and edited replacements",
+			hunks: []*Hunk{
+				{Path: "a.go", Replacement: &shared.Replacement{Id: "a1"}, decision: decisionRejected},
+				{Path: "a.go", Replacement: &shared.Replacement{Id: "a2"}, edited: &edited, decision: decisionAccepted},
+				{Path: "a.go", Replacement: &shared.Replacement{Id: "a3"}, decision: decisionAccepted},
+			},
+			wantRejected: []string{"a1"},
+			wantEdited:   map[string]string{"a2": edited},
+		},
+		{
+			name: "edit of a rejected replacement is dropped",
+			hunks: []*Hunk{
+				{Path: "a.go", Replacement: &shared.Replacement{Id: "a1"}, edited: &edited, decision: decisionRejected},
+			},
+			wantRejected: []string{"a1"},
+			wantEdited:   map[string]string{},
+		},
+		{
+			name: "whole-file changes are rejected by path",
+			hunks: []*Hunk{
+				{Path: "c.go", RemovedFile: true, decision: decisionRejected},
+				{Path: "b.go", NewFile: true, decision: decisionRejected},
+				{Path: "d.go", NewFile: true, decision: decisionAccepted},
+			},
+			wantEdited: map[string]string{},
+			wantPaths:  []string{"b.go", "c.go"},
+		},
+		{
+			name: "other hunks in a rejected file are left to the file rejection",
+			hunks: []*Hunk{
+				{Path: "b.go", NewFile: true, decision: decisionRejected},
+				{Path: "b.go", Replacement: &shared.Replacement{Id: "b1"}, decision: decisionRejected},
+				{Path: "b.go", Replacement: &shared.Replacement{Id: "b2"}, edited: &edited},
+				{Path: "e.go", NewFile: true, decision: decisionRejected},
+				{Path: "e.go", Replacement: &shared.Replacement{Id: "e1"}, decision: decisionRejected},
+			},
+			wantEdited:    map[string]string{},
+			wantPaths:     []string{"b.go", "e.go"},
+			wantConflicts: []string{"b.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, paths := ReviewRequest(tt.hunks)
+			if !reflect.DeepEqual(req.RejectedIds, tt.wantRejected) {
+				t.Errorf("rejected ids = %v, want %v", req.RejectedIds, tt.wantRejected)
+			}
+			if !reflect.DeepEqual(req.EditedById, tt.wantEdited) {
+				t.Errorf("edited = %v, want %v", req.EditedById, tt.wantEdited)
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("rejected paths = %v, want %v", paths, tt.wantPaths)
+			}
+			if conflicts := KeptInRejectedFiles(tt.hunks); !reflect.DeepEqual(conflicts, tt.wantConflicts) {
+				t.Errorf("kept in rejected files = %v, want %v", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestKeepWholeFileChanges(t *testing.T) {
+	edited := "EDITED"
+	hunks := []*Hunk{
+		{Path: "b.go", NewFile: true, decision: decisionRejected},
+		{Path: "b.go", Replacement: &shared.Replacement{Id: "b1"}, edited: &edited},
+		{Path: "c.go", RemovedFile: true, decision: decisionRejected},
+	}
+
+	KeepWholeFileChanges(hunks, KeptInRejectedFiles(hunks))
+
+	req, paths := ReviewRequest(hunks)
+	if !reflect.DeepEqual(paths, []string{"c.go"}) {
+		t.Errorf("rejected paths = %v, want [c.go]", paths)
+	}
+	if req.EditedById["b1"] != edited {
+		t.Errorf("edit to b1 was dropped: %v", req.EditedById)
+	}
+}
+
+func TestFeedbackPrompt(t *testing.T) {
+	edited := "a\nB"
+
+	tests := []struct {
+		name    string
+		hunk    *Hunk
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "no comment",
+			hunk:    &Hunk{Path: "a.go", Replacement: &shared.Replacement{Old: "a", New: "b"}, Num: 1, NumInFile: 1},
+			notWant: []string{"a.go"},
+		},
+		{
+			name: "kept replacement",
+			hunk: &Hunk{Path: "a.go", Replacement: &shared.Replacement{Old: "a\nb", New: "a\nc"}, Num: 2, NumInFile: 3, Comment: "rename this"},
+			want: []string{"a.go (change 2 of 3, kept):", "```diff\n a\n-b\n+c\n```", "Comment: rename this"},
+		},
+		{
+			name: "edited replacement shows the edit",
+			hunk: &Hunk{Path: "a.go", Replacement: &shared.Replacement{Old: "a\nb", New: "a\nc"}, edited: &edited, Num: 1, NumInFile: 1, Comment: "fixed the case"},
+			want: []string{"a.go (change 1 of 1, edited and kept):", " a\n-b\n+B\n"},
+		},
+		{
+			name:    "rejected new file has no diff",
+			hunk:    &Hunk{Path: "b.go", NewFile: true, Content: "package b", decision: decisionRejected, Comment: "not needed"},
+			want:    []string{"b.go (new file, rejected):", "Comment: not needed"},
+			notWant: []string{"```diff"},
+		},
+		{
+			name: "removed file",
+			hunk: &Hunk{Path: "c.go", RemovedFile: true, Comment: "keep it"},
+			want: []string{"c.go (removal of the file, kept):"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FeedbackPrompt([]*Hunk{tt.hunk})
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("prompt doesn't contain %q:\n%s", s, got)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("prompt contains %q:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
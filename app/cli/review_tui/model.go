@@ -0,0 +1,137 @@
+package reviewtui
+
+import (
+	bubbleKey "github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type reviewUIModel struct {
+	keymap keymap
+
+	hunks []*Hunk
+	idx   int
+
+	viewport viewport.Model
+	ready    bool
+	width    int
+	height   int
+
+	commenting   bool
+	commentInput textinput.Model
+
+	// command and args that open a file for editing and return when it's closed
+	editor []string
+
+	// a one-line note shown above the help, like why an action isn't available
+	status string
+
+	aborted      bool
+	sendFeedback bool
+}
+
+type keymap = struct {
+	accept,
+	reject,
+	edit,
+	comment,
+	next,
+	prev,
+	scrollUp,
+	scrollDown,
+	pageUp,
+	pageDown,
+	feedback,
+	done,
+	quit bubbleKey.Binding
+}
+
+func (m reviewUIModel) Init() tea.Cmd {
+	return nil
+}
+
+func initialModel(hunks []*Hunk, editor []string) *reviewUIModel {
+	input := textinput.New()
+	input.Placeholder = "What should change?"
+	input.Prompt = "💬 "
+	input.CharLimit = 2000
+
+	initialState := reviewUIModel{
+		hunks:        hunks,
+		commentInput: input,
+		editor:       editor,
+		keymap: keymap{
+			accept: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("a", "y"),
+				bubbleKey.WithHelp("a", "accept"),
+			),
+
+			reject: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("r", "x"),
+				bubbleKey.WithHelp("r", "reject"),
+			),
+
+			edit: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("e"),
+				bubbleKey.WithHelp("e", "edit"),
+			),
+
+			comment: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("c"),
+				bubbleKey.WithHelp("c", "comment"),
+			),
+
+			next: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("n", "right", "tab"),
+				bubbleKey.WithHelp("n", "next"),
+			),
+
+			prev: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("p", "left", "shift+tab"),
+				bubbleKey.WithHelp("p", "prev"),
+			),
+
+			scrollDown: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("j", "down"),
+				bubbleKey.WithHelp("j", "scroll down"),
+			),
+
+			scrollUp: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("k", "up"),
+				bubbleKey.WithHelp("k", "scroll up"),
+			),
+
+			pageDown: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("d", "pgdown"),
+				bubbleKey.WithHelp("d", "page down"),
+			),
+
+			pageUp: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("u", "pgup"),
+				bubbleKey.WithHelp("u", "page up"),
+			),
+
+			feedback: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("f"),
+				bubbleKey.WithHelp("f", "save and send comments"),
+			),
+
+			done: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("q"),
+				bubbleKey.WithHelp("q", "save and quit"),
+			),
+
+			quit: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("ctrl+c"),
+				bubbleKey.WithHelp("ctrl+c", "quit without saving"),
+			),
+		},
+	}
+
+	return &initialState
+}
+
+func (m *reviewUIModel) current() *Hunk {
+	return m.hunks[m.idx]
+}
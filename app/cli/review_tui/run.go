@@ -0,0 +1,44 @@
+package reviewtui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type Result struct {
+	Hunks []*Hunk
+	// quit without saving
+	Aborted bool
+	// send the comments back to the model after saving
+	SendFeedback bool
+}
+
+// StartReviewUI walks through the hunks one at a time to accept, reject, edit, or comment on each, editing with the editor command and args. Nothing is saved by the UI -- the caller persists the decisions in the result.
+func StartReviewUI(hunks []*Hunk, editor []string) (*Result, error) {
+	if len(hunks) == 0 {
+		return &Result{}, nil
+	}
+
+	initial := initialModel(hunks, editor)
+
+	m, err := tea.NewProgram(initial, tea.WithAltScreen()).Run()
+	if err != nil {
+		return nil, fmt.Errorf("error running review UI: %v", err)
+	}
+
+	var mod *reviewUIModel
+	c, ok := m.(*reviewUIModel)
+	if ok {
+		mod = c
+	} else {
+		c := m.(reviewUIModel)
+		mod = &c
+	}
+
+	return &Result{
+		Hunks:        mod.hunks,
+		Aborted:      mod.aborted,
+		SendFeedback: mod.sendFeedback,
+	}, nil
+}
@@ -0,0 +1,212 @@
+package reviewtui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	bubbleKey "github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type editorFinishedMsg struct {
+	hunk *Hunk
+	path string
+	err  error
+}
+
+func (m reviewUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		m.windowResized(msg.Width, msg.Height)
+
+	case editorFinishedMsg:
+		m.editorFinished(msg)
+
+	case tea.KeyMsg:
+		if m.commenting {
+			return m, m.updateComment(msg)
+		}
+
+		m.status = ""
+
+		switch {
+		case bubbleKey.Matches(msg, m.keymap.quit):
+			m.aborted = true
+			return m, tea.Quit
+
+		case bubbleKey.Matches(msg, m.keymap.done):
+			return m, tea.Quit
+
+		case bubbleKey.Matches(msg, m.keymap.feedback):
+			if !HasComments(m.hunks) {
+				m.status = "Add a comment with (c) first"
+				break
+			}
+			m.sendFeedback = true
+			return m, tea.Quit
+
+		case bubbleKey.Matches(msg, m.keymap.accept):
+			m.current().decision = decisionAccepted
+			m.next()
+
+		case bubbleKey.Matches(msg, m.keymap.reject):
+			m.current().decision = decisionRejected
+			m.next()
+
+		case bubbleKey.Matches(msg, m.keymap.edit):
+			return m, m.edit()
+
+		case bubbleKey.Matches(msg, m.keymap.comment):
+			m.commenting = true
+			m.commentInput.SetValue(m.current().Comment)
+			m.commentInput.CursorEnd()
+			m.commentInput.Focus()
+
+		case bubbleKey.Matches(msg, m.keymap.next):
+			m.next()
+
+		case bubbleKey.Matches(msg, m.keymap.prev):
+			if m.idx > 0 {
+				m.idx--
+				m.updateViewport()
+			}
+
+		case bubbleKey.Matches(msg, m.keymap.scrollDown):
+			m.viewport.LineDown(1)
+
+		case bubbleKey.Matches(msg, m.keymap.scrollUp):
+			m.viewport.LineUp(1)
+
+		case bubbleKey.Matches(msg, m.keymap.pageDown):
+			m.viewport.ViewDown()
+
+		case bubbleKey.Matches(msg, m.keymap.pageUp):
+			m.viewport.ViewUp()
+		}
+	}
+
+	return m, nil
+}
+
+func (m *reviewUIModel) updateComment(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.current().Comment = strings.TrimSpace(m.commentInput.Value())
+		m.commenting = false
+		m.commentInput.Blur()
+		return nil
+
+	case tea.KeyEsc:
+		m.commenting = false
+		m.commentInput.Blur()
+		return nil
+
+	case tea.KeyCtrlC:
+		m.aborted = true
+		return tea.Quit
+	}
+
+	var cmd tea.Cmd
+	m.commentInput, cmd = m.commentInput.Update(msg)
+	return cmd
+}
+
+func (m *reviewUIModel) next() {
+	if m.idx < len(m.hunks)-1 {
+		m.idx++
+	}
+	m.updateViewport()
+}
+
+// edit opens the hunk's updated text in the configured editor, suspending the UI until it's closed
+func (m *reviewUIModel) edit() tea.Cmd {
+	hunk := m.current()
+
+	if !hunk.CanEdit {
+		if hunk.IsWholeFile() {
+			m.status = "Only changes within a file can be edited"
+		} else {
+			m.status = "Changes from older builds can't be edited"
+		}
+		return nil
+	}
+
+	f, err := os.CreateTemp("", "plandex-review-*"+filepath.Ext(hunk.Path))
+	if err != nil {
+		m.status = fmt.Sprintf("Error creating temp file: %v", err)
+		return nil
+	}
+	_, err = f.WriteString(hunk.newText() + "\n")
+	f.Close()
+	if err != nil {
+		os.Remove(f.Name())
+		m.status = fmt.Sprintf("Error writing temp file: %v", err)
+		return nil
+	}
+
+	c := exec.Command(m.editor[0], append(m.editor[1:], f.Name())...)
+
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		return editorFinishedMsg{hunk: hunk, path: f.Name(), err: err}
+	})
+}
+
+func (m *reviewUIModel) editorFinished(msg editorFinishedMsg) {
+	defer os.Remove(msg.path)
+
+	if msg.err != nil {
+		m.status = fmt.Sprintf("Error running editor: %v", msg.err)
+		return
+	}
+
+	bytes, err := os.ReadFile(msg.path)
+	if err != nil {
+		m.status = fmt.Sprintf("Error reading edited change: %v", err)
+		return
+	}
+
+	// the replacement's text doesn't end in a newline, but editors usually add one
+	edited := strings.TrimSuffix(string(bytes), "\n")
+	if edited != msg.hunk.newText() {
+		msg.hunk.edited = &edited
+	}
+	if msg.hunk.decision == decisionRejected {
+		msg.hunk.decision = decisionAccepted
+	}
+
+	m.updateViewport()
+}
+
+func (m *reviewUIModel) windowResized(w, h int) {
+	m.width = w
+	m.height = h
+
+	viewportHeight := h - headerHeight - footerHeight - lipgloss.Height(m.renderHelp())
+	if viewportHeight < 1 {
+		viewportHeight = 1
+	}
+
+	if !m.ready {
+		m.viewport = viewport.New(w, viewportHeight)
+		m.ready = true
+	} else {
+		m.viewport.Width = w
+		m.viewport.Height = viewportHeight
+	}
+
+	m.updateViewport()
+}
+
+func (m *reviewUIModel) updateViewport() {
+	if !m.ready {
+		return
+	}
+	m.viewport.SetContent(m.renderHunk())
+	m.viewport.GotoTop()
+}
@@ -0,0 +1,151 @@
+package reviewtui
+
+import (
+	"fmt"
+	"strings"
+
+	"plandex-cli/term"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+)
+
+var borderColor = lipgloss.Color("#444")
+var helpTextColor = lipgloss.Color("#ddd")
+
+// rows above the viewport for the title and summary, and below it for the comment and status lines (the help's height depends on the width)
+const headerHeight = 2
+const footerHeight = 2
+
+func (m reviewUIModel) View() string {
+	if !m.ready {
+		return ""
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		m.renderHeader(),
+		m.viewport.View(),
+		m.renderComment(),
+		m.renderStatus(),
+		m.renderHelp(),
+	)
+}
+
+func (m reviewUIModel) renderHeader() string {
+	hunk := m.current()
+
+	var label string
+	switch {
+	case hunk.NewFile:
+		label = "new file"
+	case hunk.RemovedFile:
+		label = "removed file"
+	default:
+		label = fmt.Sprintf("change %d/%d in file", hunk.Num, hunk.NumInFile)
+	}
+
+	title := fmt.Sprintf(" 📄 %s %s %s",
+		color.New(color.Bold, term.ColorHiCyan).Sprint(hunk.Path),
+		label,
+		m.renderDecision(hunk),
+	)
+
+	var numAccepted, numRejected, numEdited, numComments int
+	for _, h := range m.hunks {
+		switch h.decision {
+		case decisionAccepted:
+			numAccepted++
+		case decisionRejected:
+			numRejected++
+		}
+		if _, ok := h.Edited(); ok {
+			numEdited++
+		}
+		if h.Comment != "" {
+			numComments++
+		}
+	}
+
+	summary := fmt.Sprintf(" %d of %d • ✅ %d accepted • 🚫 %d rejected • ✏️  %d edited • 💬 %d comments",
+		m.idx+1, len(m.hunks), numAccepted, numRejected, numEdited, numComments)
+
+	return title + "\n" + summary
+}
+
+func (m reviewUIModel) renderDecision(hunk *Hunk) string {
+	var s string
+	switch hunk.decision {
+	case decisionAccepted:
+		s = color.New(color.Bold, term.ColorHiGreen).Sprint("✅ accepted")
+	case decisionRejected:
+		s = color.New(color.Bold, term.ColorHiRed).Sprint("🚫 rejected")
+	}
+	if _, ok := hunk.Edited(); ok {
+		s += color.New(color.Bold, term.ColorHiYellow).Sprint(" ✏️  edited")
+	}
+	return s
+}
+
+func (m reviewUIModel) renderHunk() string {
+	hunk := m.current()
+
+	var b strings.Builder
+	b.WriteString("\n")
+
+	if hunk.Replacement != nil && hunk.Replacement.Summary != "" {
+		b.WriteString(" " + hunk.Replacement.Summary + "\n\n")
+	}
+
+	if hunk.RemovedFile {
+		b.WriteString(color.New(term.ColorHiRed).Sprint(" 🗑️  The file will be removed") + "\n")
+		return b.String()
+	}
+
+	var lines []diffLine
+	if hunk.NewFile {
+		lines = diffLines("", hunk.newText())
+	} else {
+		lines = diffLines(hunk.Replacement.Old, hunk.newText())
+	}
+
+	for _, line := range lines {
+		s := " " + line.prefix() + " " + line.text
+		switch line.kind {
+		case diffRemoved:
+			s = color.New(term.ColorHiRed).Sprint(s)
+		case diffAdded:
+			s = color.New(term.ColorHiGreen).Sprint(s)
+		}
+		b.WriteString(s + "\n")
+	}
+
+	return b.String()
+}
+
+func (m reviewUIModel) renderComment() string {
+	if m.commenting {
+		return " " + m.commentInput.View()
+	}
+	comment := m.current().Comment
+	if comment == "" {
+		return ""
+	}
+	return " 💬 " + comment
+}
+
+func (m reviewUIModel) renderStatus() string {
+	if m.status == "" {
+		return ""
+	}
+	return color.New(term.ColorHiYellow).Sprint(" ⚠️  " + m.status)
+}
+
+func (m reviewUIModel) renderHelp() string {
+	style := lipgloss.NewStyle().Width(m.width).Foreground(lipgloss.Color(helpTextColor)).BorderStyle(lipgloss.NormalBorder()).BorderTop(true).BorderForeground(lipgloss.Color(borderColor))
+
+	if m.commenting {
+		return style.Render(" (enter) save comment • (esc) cancel")
+	}
+
+	return style.Render(" (a)ccept • (r)eject • (e)dit • (c)omment • (n/p) next/prev • (j/k) scroll • (f) save and send comments • (q) save and quit • (ctrl+c) quit without saving")
+}
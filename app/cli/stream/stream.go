@@ -4,6 +4,7 @@ import (
 	"log"
 	"plandex-cli/api"
 	"plandex-cli/lib"
+	streamjson "plandex-cli/stream_json"
 	streamtui "plandex-cli/stream_tui"
 	"plandex-cli/term"
 	"plandex-cli/types"
@@ -14,12 +15,20 @@ import (
 
 var OnStreamPlan types.OnStreamPlan
 
+func send(msg shared.StreamMessage) {
+	if streamjson.Enabled() {
+		streamjson.Send(msg)
+		return
+	}
+	streamtui.Send(msg)
+}
+
 func init() {
 	OnStreamPlan = func(params types.OnStreamPlanParams) {
 		if params.Err != nil {
 			if strings.Contains(params.Err.Error(), "missing heartbeats") || strings.Contains(strings.ToLower(params.Err.Error()), "eof") {
 				log.Println("Error in stream:", params.Err)
-				streamtui.Send(shared.StreamMessage{
+				send(shared.StreamMessage{
 					Type: shared.StreamMessageError,
 					Error: &shared.ApiError{
 						Msg: "Stream error: " + params.Err.Error(),
@@ -47,6 +56,6 @@ func init() {
 		// log.Println("Stream message:")
 		// log.Println(spew.Sdump(*params.Msg))
 
-		streamtui.Send(*params.Msg)
+		send(*params.Msg)
 	}
 }
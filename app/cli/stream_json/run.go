@@ -0,0 +1,199 @@
+package streamjson
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"sync"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+// out is the real stdout, captured when JSON mode is enabled. Everything else that would normally go to stdout is sent to stderr so that stdout only contains newline-delimited stream messages.
+var out io.Writer
+var outMu sync.Mutex
+
+var enabled bool
+var msgCh = make(chan shared.StreamMessage, 100)
+var stdin *bufio.Reader
+
+type MissingFileResponse struct {
+	Choice shared.RespondMissingFileChoice `json:"choice"`
+}
+
+func Enable() {
+	if enabled {
+		return
+	}
+	enabled = true
+
+	out = os.Stdout
+	os.Stdout = os.Stderr
+	color.Output = color.Error
+	term.DisableSpinner()
+
+	stdin = bufio.NewReader(os.Stdin)
+}
+
+func Enabled() bool {
+	return enabled
+}
+
+func Send(msg shared.StreamMessage) {
+	msgCh <- msg
+}
+
+// Run writes stream messages to stdout until the stream finishes, is stopped, or errors. It returns an error if the stream ended with an error.
+func Run() error {
+	log.Println("Starting JSON stream output")
+
+	for msg := range msgCh {
+		var msgs []shared.StreamMessage
+		if msg.Type == shared.StreamMessageMulti {
+			msgs = msg.StreamMessages
+		} else {
+			msgs = []shared.StreamMessage{msg}
+		}
+
+		for _, m := range msgs {
+			done, err := handleMessage(&m)
+			if err != nil {
+				if m.Type != shared.StreamMessageError {
+					// stream errors are already written, anything else is reported in the same format
+					write(&shared.StreamMessage{
+						Type:  shared.StreamMessageError,
+						Error: &shared.ApiError{Msg: err.Error()},
+					})
+				}
+				return err
+			}
+			if done {
+				log.Println("JSON stream output finished")
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+func handleMessage(msg *shared.StreamMessage) (bool, error) {
+	if msg.Type == shared.StreamMessageReply && msg.ReplyChunk == "" {
+		return false, nil
+	}
+
+	err := write(msg)
+	if err != nil {
+		return false, err
+	}
+
+	switch msg.Type {
+	case shared.StreamMessageConnectActive, shared.StreamMessagePromptMissingFile:
+		if msg.MissingFilePath != "" {
+			err := respondMissingFile(msg)
+			if err != nil {
+				return false, err
+			}
+		}
+
+	case shared.StreamMessageLoadContext:
+		_, err := lib.AutoLoadContextFiles(context.Background(), msg.LoadContextFiles)
+		if err != nil {
+			return false, fmt.Errorf("failed to auto load context files: %v", err)
+		}
+
+	case shared.StreamMessageError:
+		if msg.Error != nil {
+			return false, fmt.Errorf("%s", msg.Error.Msg)
+		}
+		return false, fmt.Errorf("stream error")
+
+	case shared.StreamMessageFinished, shared.StreamMessageAborted:
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func write(msg *shared.StreamMessage) error {
+	bytes, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("error marshalling stream message: %v", err)
+	}
+
+	outMu.Lock()
+	defer outMu.Unlock()
+
+	_, err = fmt.Fprintln(out, string(bytes))
+	if err != nil {
+		return fmt.Errorf("error writing stream message: %v", err)
+	}
+
+	return nil
+}
+
+// respondMissingFile reads a single JSON line like {"choice": "load"} from stdin and sends it to the server. If stdin is closed, the file is skipped.
+func respondMissingFile(msg *shared.StreamMessage) error {
+	bytes, err := os.ReadFile(msg.MissingFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %v", err)
+	}
+	content := string(shared.NormalizeEOL(bytes))
+
+	choice := shared.RespondMissingFileChoiceLoad
+
+	if !msg.MissingFileAutoContext {
+		choice, err = readMissingFileChoice()
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("JSON stream - responding to missing file", msg.MissingFilePath, "with choice", choice)
+
+	apiErr := api.Client.RespondMissingFile(lib.CurrentPlanId, lib.CurrentBranch, shared.RespondMissingFileRequest{
+		Choice:   choice,
+		FilePath: msg.MissingFilePath,
+		Body:     content,
+	})
+
+	if apiErr != nil {
+		return fmt.Errorf("error responding to missing file: %v", apiErr.Msg)
+	}
+
+	return nil
+}
+
+func readMissingFileChoice() (shared.RespondMissingFileChoice, error) {
+	line, err := stdin.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("error reading missing file response: %v", err)
+	}
+
+	if line == "" && err == io.EOF {
+		log.Println("JSON stream - stdin closed, skipping missing file")
+		return shared.RespondMissingFileChoiceSkip, nil
+	}
+
+	var res MissingFileResponse
+	err = json.Unmarshal([]byte(line), &res)
+	if err != nil {
+		return "", fmt.Errorf("invalid missing file response %q: %v", line, err)
+	}
+
+	switch res.Choice {
+	case shared.RespondMissingFileChoiceLoad, shared.RespondMissingFileChoiceSkip, shared.RespondMissingFileChoiceOverwrite:
+		return res.Choice, nil
+	}
+
+	return "", fmt.Errorf("invalid missing file choice %q (must be load, skip, or overwrite)", res.Choice)
+}
@@ -0,0 +1,125 @@
+package streamjson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func runWith(t *testing.T, msgs ...shared.StreamMessage) ([]shared.StreamMessage, error) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	out = &buf
+
+	for _, msg := range msgs {
+		Send(msg)
+	}
+	err := Run()
+
+	// drain anything left over so tests don't leak messages into each other
+	for len(msgCh) > 0 {
+		<-msgCh
+	}
+
+	var res []shared.StreamMessage
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		var msg shared.StreamMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			t.Fatalf("line isn't a single JSON object: %q: %v", line, err)
+		}
+		res = append(res, msg)
+	}
+	return res, err
+}
+
+func TestRunWritesOneMessagePerLine(t *testing.T) {
+	res, err := runWith(t,
+		shared.StreamMessage{Type: shared.StreamMessageReply, ReplyChunk: "hello\nworld"},
+		shared.StreamMessage{Type: shared.StreamMessageReply, ReplyChunk: ""},
+		shared.StreamMessage{Type: shared.StreamMessageMulti, StreamMessages: []shared.StreamMessage{
+			{Type: shared.StreamMessageReply, ReplyChunk: "a"},
+			{Type: shared.StreamMessageReply, ReplyChunk: "b"},
+		}},
+		shared.StreamMessage{Type: shared.StreamMessageFinished},
+		shared.StreamMessage{Type: shared.StreamMessageReply, ReplyChunk: "after finish"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, msg := range res {
+		got = append(got, string(msg.Type)+":"+msg.ReplyChunk)
+	}
+
+	// empty reply chunks are dropped, multi messages are flattened, and nothing is written after finish
+	want := []string{
+		string(shared.StreamMessageReply) + ":hello\nworld",
+		string(shared.StreamMessageReply) + ":a",
+		string(shared.StreamMessageReply) + ":b",
+		string(shared.StreamMessageFinished) + ":",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestRunStreamError(t *testing.T) {
+	res, err := runWith(t,
+		shared.StreamMessage{Type: shared.StreamMessageError, Error: &shared.ApiError{Msg: "boom"}},
+	)
+	if err == nil || err.Error() != "boom" {
+		t.Fatalf("expected boom error, got %v", err)
+	}
+
+	// the stream's own error is written once, not repeated
+	if len(res) != 1 || res[0].Type != shared.StreamMessageError || res[0].Error.Msg != "boom" {
+		t.Fatalf("unexpected output: %+v", res)
+	}
+}
+
+func TestRunAborted(t *testing.T) {
+	res, err := runWith(t, shared.StreamMessage{Type: shared.StreamMessageAborted})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res) != 1 || res[0].Type != shared.StreamMessageAborted {
+		t.Fatalf("unexpected output: %+v", res)
+	}
+}
+
+func TestReadMissingFileChoice(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    shared.RespondMissingFileChoice
+		wantErr bool
+	}{
+		{"load", `{"choice": "load"}` + "\n", shared.RespondMissingFileChoiceLoad, false},
+		{"overwrite without newline", `{"choice": "overwrite"}`, shared.RespondMissingFileChoiceOverwrite, false},
+		{"closed stdin skips", "", shared.RespondMissingFileChoiceSkip, false},
+		{"unknown choice", `{"choice": "delete"}` + "\n", "", true},
+		{"not json", "load\n", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdin = bufio.NewReader(strings.NewReader(tt.input))
+			got, err := readMissingFileChoice()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -211,6 +211,14 @@ func HandleApiError(apiError *shared.ApiError) {
 		}
 	}
 
+	if apiError.Type == shared.ApiErrorTypeBudgetExceeded {
+		StopSpinner()
+		OutputSimpleError("%s", apiError.Msg)
+		fmt.Println()
+		PrintCmds("", "usage", "budgets")
+		os.Exit(1)
+	}
+
 	StopSpinner()
 	OutputErrorAndExit(apiError.Msg)
 }
@@ -54,11 +54,18 @@ var CliCommands = []CmdConfig{
 	{"current", "cu", "show current plan", true},
 	{"rename", "", "rename the current plan", true},
 	{"delete-plan", "dp", "delete plan by name or index", true},
+	{"share", "", "share the current plan with a member of your org", true},
+	{"unshare", "", "stop sharing the current plan with a user", true},
+	{"shares", "", "list who the current plan is shared with", true},
 
 	{"config", "", "show current plan config", true},
 	{"set-config", "", "update current plan config", true},
 	{"config default", "", "show the default config for new plans", true},
 	{"set-config default", "", "update the default config for new plans", true},
+	{"exec-policy", "", "show the project and org rules for commands in apply scripts", true},
+	{"exec-policy check", "", "check commands against the exec policy", true},
+	{"exec-policy set-org", "", "set the org's exec policy from a JSON file", true},
+	{"exec-policy clear-org", "", "remove the org's exec policy", true},
 
 	{"set-auto", "", "update auto-mode (autonomy level) for current plan", true},
 	{"set-auto none", "", fmt.Sprintf("set auto-mode to %s", "'none'"), true},
@@ -72,20 +79,34 @@ var CliCommands = []CmdConfig{
 	{"tell", "t", "describe a task to complete", false},
 	{"chat", "ch", "ask a question or chat", false},
 
-	{"load", "l", "load files/dirs/urls/notes/images or pipe data into context", true},
+	{"load", "l", "load files/dirs/urls/notes/images/symbols (file.go#Type.Method) or pipe data into context", true},
+	{"load --git-diff", "", "load the diff against a git ref, kept in sync on update (--git-files loads the changed files too)", true},
+	{"load --changed-since", "", "load every file changed since a git ref", true},
+	{"load --cmd", "", "load a shell command's output, re-run to stay current on update", true},
+	{"load --with-deps", "", "also load files that import or are imported by the loaded files", true},
+	{"load --crawl", "", "load a docs site by following links from a url, each page as its own url context", true},
+	{"url-auth", "", "show the hosts with credentials for loading private urls", true},
 	{"ls", "", "list everything in context", true},
+	{"search", "", "rank project files for a query with the local search index", true},
 	{"rm", "", "remove context by index, range, name, or glob", true},
 	{"clear", "", "remove all context", true},
 	{"update", "u", "update outdated context", true},
 	{"show", "", "show current context by name or index", true},
+	{"context save", "", "save the plan's context as a named set in the project (--shared to share with the org)", true},
+	{"context restore", "", "load a saved context set into the current plan", true},
+	{"context sets", "", "list saved context sets in the project", true},
+	{"workspaces", "ws", "list the packages of a monorepo", true},
+	{"scope", "", "limit the plan's project map and context list to some monorepo packages", true},
 
 	{"diff --ui", "", "review pending changes in a browser UI", true},
 	{"diff", "", "review pending changes in 'git diff' format", true},
 	{"diff --plain", "", "review pending changes in 'git diff' format with no color formatting", false},
+	{"verify", "", "run the project's verification commands against pending changes", true},
 	{"summary", "", "show the latest summary of the current plan", true},
 
 	{"apply", "ap", "apply pending changes to project files", true},
 	{"reject", "rj", "reject pending changes to one or more project files", true},
+	{"review", "", "accept, reject, edit, or comment on pending changes one hunk at a time", true},
 
 	{"log", "", "show log of plan updates", true},
 	{"rewind", "rw", "rewind to a previous state", true},
@@ -145,17 +166,32 @@ var CliCommands = []CmdConfig{
 	{"invite", "", "invite a user to join your org", true},
 	{"revoke", "", "revoke an invite or remove a user from your org", true},
 	{"users", "", "list users and pending invites in your org", true},
+	{"tokens", "", "list API tokens for CI and automation", true},
+	{"tokens create", "", "create an API token", true},
+	{"tokens revoke", "", "revoke an API token", true},
+	{"roles", "", "list org roles", true},
+	{"roles show", "", "show an org role's permissions", true},
+	{"roles create", "", "create a custom org role", true},
+	{"roles update", "", "update a custom org role", true},
+	{"roles delete", "", "delete a custom org role", true},
+	{"roles assign", "", "change a user's org role", true},
+	{"audit", "", "show the org's audit log", true},
+	{"budgets", "", "show the org's model spend budgets", true},
+	{"budgets set", "", "set a daily or monthly token or cost budget", true},
+	{"budgets rm", "", "remove a budget", true},
 
 	{"connect-claude", "", "connect your Claude Pro or Max subscription", true},
 	{"disconnect-claude", "", "disconnect your Claude Pro or Max subscription", true},
 	{"claude-status", "", "status of your Claude Pro or Max subscription connection", true},
 
-	{"usage", "", "show Plandex Cloud current balance and usage report", true},
-	{"usage --today", "", "show Plandex Cloud usage for the day so far", true},
-	{"usage --month", "", "show Plandex Cloud usage for the current billing month", true},
-	{"usage --plan", "", "show Plandex Cloud usage for the current plan", true},
+	{"usage", "", "show Plandex Cloud current balance and usage report, or a usage report on self-hosted servers", true},
+	{"usage --today", "", "show usage for the day so far", true},
+	{"usage --month", "", "show usage for the current billing month", true},
+	{"usage --plan", "", "show usage for the current plan", true},
 
 	{"usage --log", "", "show Plandex Cloud transaction log", true},
+	{"usage --by plan", "", "show self-hosted usage grouped by plan, user, role, model, or day", true},
+	{"usage --csv", "", "export self-hosted usage as CSV", true},
 
 	{"billing", "", "show Plandex Cloud billing settings", true},
 }
@@ -301,11 +337,11 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Plans ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "new", "plans", "cd", "current", "delete-plan", "rename", "archive", "plans --archived", "unarchive")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "new", "plans", "cd", "current", "delete-plan", "rename", "archive", "plans --archived", "unarchive", "share", "unshare", "shares")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Changes ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "diff", "diff --ui", "diff --plain", "apply", "reject")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "diff", "diff --ui", "diff --plain", "verify", "review", "apply", "reject")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Context ")
@@ -329,7 +365,7 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Config ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "config", "set-config", "config default", "set-config default")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "config", "set-config", "config default", "set-config default", "exec-policy", "exec-policy check")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Autonomy ")
@@ -346,7 +382,7 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Accounts ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "sign-in", "invite", "revoke", "users")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "sign-in", "invite", "revoke", "users", "tokens", "tokens create", "tokens revoke", "roles", "roles create", "roles assign", "audit", "budgets", "budgets set", "budgets rm", "exec-policy set-org", "exec-policy clear-org")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Integrations ")
@@ -354,7 +390,7 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Cloud ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "usage", "usage --today", "usage --month", "usage --plan", "usage --log", "billing")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "usage", "usage --today", "usage --month", "usage --plan", "usage --log", "usage --by plan", "usage --csv", "billing")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " New Plan Shortcuts ")
@@ -16,8 +16,18 @@ var startedAt time.Time
 var lastMessage string
 var active bool
 var currentWarningLoop int32
+var disabled bool
+
+// DisableSpinner turns StartSpinner and StopSpinner into no-ops, for output modes that need clean stdout.
+func DisableSpinner() {
+	disabled = true
+}
 
 func StartSpinner(msg string) {
+	if disabled {
+		return
+	}
+
 	if active {
 		if msg == lastMessage {
 			return
@@ -34,6 +44,10 @@ func StartSpinner(msg string) {
 }
 
 func StopSpinner() {
+	if disabled {
+		return
+	}
+
 	elapsed := time.Since(startedAt)
 
 	if lastMessage != "" && elapsed < withMessageMinDuration {
@@ -3,6 +3,7 @@ package types
 import (
 	"context"
 	shared "plandex-shared"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
@@ -14,6 +15,24 @@ type OnStreamPlanParams struct {
 
 type OnStreamPlan func(params OnStreamPlanParams)
 
+type ListAuditLogParams struct {
+	UserEmail string
+	Action    string
+	PlanId    string
+	Since     *time.Time
+	Until     *time.Time
+	Limit     int
+}
+
+type GetUsageReportParams struct {
+	By        shared.UsageGroupBy
+	Since     *time.Time
+	Until     *time.Time
+	PlanId    string
+	UserEmail string
+	SessionId string
+}
+
 type ApiClient interface {
 	CreateCliTrialSession() (string, *shared.ApiError)
 	GetCliTrialSession(token string) (*shared.SessionResponse, *shared.ApiError)
@@ -39,6 +58,33 @@ type ApiClient interface {
 
 	ListOrgRoles() ([]*shared.OrgRole, *shared.ApiError)
 
+	CreateApiToken(req shared.CreateApiTokenRequest) (*shared.CreateApiTokenResponse, *shared.ApiError)
+	ListApiTokens() ([]*shared.ApiToken, *shared.ApiError)
+	RevokeApiToken(tokenId string) *shared.ApiError
+	GetApiTokenSession() (*shared.SessionResponse, *shared.ApiError)
+
+	ListPlanShares(planId string) ([]*shared.PlanShare, *shared.ApiError)
+	SharePlan(planId string, req shared.SharePlanRequest) (*shared.PlanShare, *shared.ApiError)
+	UnsharePlan(planId, userId string) *shared.ApiError
+
+	CreateOrgRole(req shared.CreateOrgRoleRequest) (*shared.OrgRole, *shared.ApiError)
+	UpdateOrgRole(roleId string, req shared.UpdateOrgRoleRequest) (*shared.OrgRole, *shared.ApiError)
+	DeleteOrgRole(roleId string) *shared.ApiError
+	SetOrgUserRole(userId string, req shared.SetOrgUserRoleRequest) *shared.ApiError
+
+	ListAuditLog(params ListAuditLogParams) ([]*shared.AuditLogEntry, *shared.ApiError)
+
+	ListBudgets() ([]*shared.Budget, *shared.ApiError)
+	SetBudget(req shared.SetBudgetRequest) (*shared.Budget, *shared.ApiError)
+	DeleteBudget(budgetId string) *shared.ApiError
+	GetBudgetStatus(planId string) ([]*shared.BudgetStatus, *shared.ApiError)
+
+	GetUsageReport(params GetUsageReportParams) (*shared.UsageReport, *shared.ApiError)
+
+	GetOrgExecPolicy() (*shared.ExecPolicy, *shared.ApiError)
+	SetOrgExecPolicy(req shared.SetOrgExecPolicyRequest) *shared.ApiError
+	DeleteOrgExecPolicy() *shared.ApiError
+
 	InviteUser(req shared.InviteRequest) *shared.ApiError
 	ListPendingInvites() ([]*shared.Invite, *shared.ApiError)
 	ListAcceptedInvites() ([]*shared.Invite, *shared.ApiError)
@@ -78,11 +124,15 @@ type ApiClient interface {
 	RejectAllChanges(planId, branch string) *shared.ApiError
 	RejectFile(planId, branch, filePath string) *shared.ApiError
 	RejectFiles(planId, branch string, paths []string) *shared.ApiError
+	ReviewReplacements(planId, branch string, req shared.ReviewReplacementsRequest) *shared.ApiError
 	GetPlanDiffs(planId, branch string, plain bool) (string, *shared.ApiError)
 
 	LoadContext(planId, branch string, req shared.LoadContextRequest) (*shared.LoadContextResponse, *shared.ApiError)
 	UpdateContext(planId, branch string, req shared.UpdateContextRequest) (*shared.UpdateContextResponse, *shared.ApiError)
 	DeleteContext(planId, branch string, req shared.DeleteContextRequest) (*shared.DeleteContextResponse, *shared.ApiError)
+	SaveContextSet(planId, branch string, req shared.SaveContextSetRequest) (*shared.ContextSet, *shared.ApiError)
+	ListContextSets(projectId string) ([]*shared.ContextSet, *shared.ApiError)
+	GetContextSet(projectId, name string) (*shared.ContextSet, *shared.ApiError)
 	ListContext(planId, branch string) ([]*shared.Context, *shared.ApiError)
 	LoadCachedFileMap(planId, branch string, req shared.LoadCachedFileMapRequest) (*shared.LoadCachedFileMapResponse, *shared.ApiError)
 
@@ -118,6 +168,8 @@ type ApiClient interface {
 	GetBalance() (decimal.Decimal, *shared.ApiError)
 
 	GetFileMap(req shared.GetFileMapRequest) (*shared.GetFileMapResponse, *shared.ApiError)
+	GetFileSymbols(req shared.GetFileSymbolsRequest) (*shared.GetFileSymbolsResponse, *shared.ApiError)
+	GetFileDeps(req shared.GetFileDepsRequest) (*shared.GetFileDepsResponse, *shared.ApiError)
 	GetContextBody(planId, branch, contextId string) (*shared.GetContextBodyResponse, *shared.ApiError)
 	AutoLoadContext(ctx context.Context, planId, branch string, req shared.LoadContextRequest) (*shared.LoadContextResponse, *shared.ApiError)
 	GetBuildStatus(planId, branch string) (*shared.GetBuildStatusResponse, *shared.ApiError)
@@ -11,6 +11,10 @@ type ApplyFlags struct {
 	AutoExec    bool
 	NoExec      bool
 	AutoDebug   int
+
+	// commit the changes to a new branch instead of writing them to the project, optionally checked out in a new linked worktree
+	Branch   string
+	Worktree string
 }
 
 type ApplyRollbackOption string
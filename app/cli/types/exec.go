@@ -15,8 +15,12 @@ type TellFlags struct {
 	AutoApply              bool
 	IsImplementationOfChat bool
 	SkipChangesMenu        bool
+	JSON                   bool
+	// consecutive rounds of fixing verification failures, to limit automatic fixes
+	VerifyFixAttempt int
 }
 type BuildFlags struct {
 	BuildBg   bool
 	AutoApply bool
+	JSON      bool
 }
@@ -1,13 +1,69 @@
 package types
 
-import ignore "github.com/sabhiram/go-gitignore"
+import (
+	"path/filepath"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+)
 
 type ProjectPaths struct {
 	ActivePaths    map[string]bool
 	AllPaths       map[string]bool
 	ActiveDirs     map[string]bool
 	AllDirs        map[string]bool
-	PlandexIgnored *ignore.GitIgnore
+	PlandexIgnored *PlandexIgnore
 	IgnoredPaths   map[string]string
 	GitIgnoredDirs map[string]bool
 }
+
+// PlandexIgnore holds the project's .plandexignore files. Like nested .gitignore files, the patterns in each one are relative to its own directory and only apply to paths inside it.
+type PlandexIgnore struct {
+	Files []*PlandexIgnoreFile
+}
+
+type PlandexIgnoreFile struct {
+	// relative to the project root, "." for the root .plandexignore
+	Dir    string
+	Ignore *ignore.GitIgnore
+}
+
+// MatchesPath checks a path relative to the project root against every .plandexignore that applies to it
+func (p *PlandexIgnore) MatchesPath(path string) bool {
+	if p == nil {
+		return false
+	}
+
+	path = filepath.ToSlash(filepath.Clean(path))
+
+	for _, f := range p.Files {
+		relPath := path
+		if f.Dir != "." {
+			dir := filepath.ToSlash(f.Dir)
+			if path == dir {
+				continue
+			}
+			var ok bool
+			relPath, ok = strings.CutPrefix(path, dir+"/")
+			if !ok {
+				continue
+			}
+		}
+
+		if f.Ignore.MatchesPath(relPath) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WorkspacePackage is a sub-project of a monorepo, found from the repo's workspace config
+type WorkspacePackage struct {
+	// the package's name from its manifest, or its Bazel label
+	Name string
+	// relative to the project root
+	Dir string
+	// go, pnpm, yarn, npm, cargo, or bazel
+	Kind string
+}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"testing"
+
+	ignore "github.com/sabhiram/go-gitignore"
+)
+
+func TestPlandexIgnoreMatchesPath(t *testing.T) {
+	p := &PlandexIgnore{Files: []*PlandexIgnoreFile{
+		{Dir: ".", Ignore: ignore.CompileIgnoreLines("*.log", "/build")},
+		{Dir: "packages/web", Ignore: ignore.CompileIgnoreLines("dist", "/fixtures", "*.snap")},
+		{Dir: "packages/web/src", Ignore: ignore.CompileIgnoreLines("generated.ts")},
+	}}
+
+	tests := map[string]bool{
+		// root patterns apply everywhere
+		"debug.log":                    true,
+		"packages/web/debug.log":       true,
+		"build/out.js":                 true,
+		"packages/web/build/out.js":    false,
+		"packages/web/dist/app.js":     true,
+		"packages/web/src/dist/x.js":   true,
+		"packages/web/fixtures/a.json": true,
+		// an anchored pattern is relative to its own file's dir
+		"packages/web/src/fixtures/a.json": false,
+		"fixtures/a.json":                  false,
+		// nested patterns don't apply outside their dir, or to siblings with the same prefix
+		"dist/app.js":                   false,
+		"packages/webapp/dist/app.js":   false,
+		"packages/web/src/generated.ts": true,
+		"packages/web/generated.ts":     false,
+		"packages/web/a.snap":           true,
+		"packages/web":                  false,
+		"./packages/web/dist/app.js":    true,
+	}
+
+	for path, want := range tests {
+		if got := p.MatchesPath(path); got != want {
+			t.Errorf("%s: got %v, want %v", path, got, want)
+		}
+	}
+
+	var none *PlandexIgnore
+	if none.MatchesPath("debug.log") {
+		t.Error("a nil PlandexIgnore matched a path")
+	}
+}
@@ -17,20 +17,40 @@ type LoadContextParams struct {
 	SkipIgnoreWarning bool
 	AutoLoaded        bool
 	SessionId         string
+	GitDiff           string
+	GitStaged         bool
+	GitCommits        string
+	ChangedSince      string
+	GitFiles          bool
+	Cmds              []string
+	WithDeps          int
+	Crawl             bool
+	CrawlDepth        int
+	SamePrefix        bool
+	// contexts restored from a context set that are loaded as-is, like notes and re-run git diffs
+	Restored []*shared.LoadContextParams
 }
 
 type ContextOutdatedResult struct {
-	Msg             string
-	UpdatedContexts []*shared.Context
-	RemovedContexts []*shared.Context
-	TokenDiffsById  map[string]int
-	NumFiles        int
-	NumUrls         int
-	NumTrees        int
-	NumMaps         int
-	NumFilesRemoved int
-	NumTreesRemoved int
-	ReqFn           func() (map[string]*shared.UpdateContextParams, error)
+	Msg               string
+	UpdatedContexts   []*shared.Context
+	RemovedContexts   []*shared.Context
+	TokenDiffsById    map[string]int
+	NumFiles          int
+	NumUrls           int
+	NumTrees          int
+	NumMaps           int
+	NumSymbols        int
+	NumGitDiffs       int
+	NumCommands       int
+	NumDocuments      int
+	NumFilesRemoved   int
+	NumTreesRemoved   int
+	NumSymbolsRemoved int
+	ReqFn             func() (map[string]*shared.UpdateContextParams, error)
+
+	// command contexts loaded by other users, which aren't re-run
+	SkippedCommands []*shared.Context
 }
 
 const (
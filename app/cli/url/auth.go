@@ -0,0 +1,356 @@
+package url
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"plandex-cli/fs"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// UrlAuthConfig is the per-host credentials for loading private urls, from url-auth.json in the home plandex dir. It's only read locally -- credentials are added to requests the CLI makes and are never sent to the Plandex server.
+type UrlAuthConfig struct {
+	// keyed by host, like 'wiki.example.com', 'wiki.example.com:8443' to only match a port, or '*.example.com' for any subdomain
+	Hosts map[string]*HostAuth `json:"hosts"`
+	// whether to use credentials from ~/.netrc (or $NETRC) for hosts with no Authorization header configured -- defaults to true
+	Netrc *bool `json:"netrc,omitempty"`
+}
+
+type HostAuth struct {
+	// header values can reference env vars like '${WIKI_TOKEN}', so secrets don't need to be stored in the file
+	Headers map[string]string `json:"headers,omitempty"`
+	// env var with a token to send as 'Authorization: Bearer <token>'
+	BearerTokenEnv string `json:"bearerTokenEnv,omitempty"`
+	// overrides the top-level netrc setting for this host
+	Netrc *bool `json:"netrc,omitempty"`
+	// PEM files with a client certificate and its key, for hosts that require mutual TLS
+	ClientCert string `json:"clientCert,omitempty"`
+	ClientKey  string `json:"clientKey,omitempty"`
+	// PEM file with CA certificates to trust along with the system's, for hosts with internal certificates
+	CACert string `json:"caCert,omitempty"`
+}
+
+var (
+	authConfig     *UrlAuthConfig
+	authConfigErr  error
+	authConfigOnce sync.Once
+
+	netrcEntries []netrcEntry
+	netrcOnce    sync.Once
+)
+
+// LoadUrlAuthConfig reads url-auth.json. A missing file is an empty config.
+func LoadUrlAuthConfig() (*UrlAuthConfig, error) {
+	authConfigOnce.Do(func() {
+		authConfig = &UrlAuthConfig{}
+
+		bytes, err := os.ReadFile(fs.HomeUrlAuthPath)
+		if os.IsNotExist(err) {
+			return
+		}
+		if err != nil {
+			authConfigErr = fmt.Errorf("error reading %s: %v", fs.HomeUrlAuthPath, err)
+			return
+		}
+
+		err = json.Unmarshal(bytes, authConfig)
+		if err != nil {
+			authConfigErr = fmt.Errorf("error parsing %s: %v", fs.HomeUrlAuthPath, err)
+			return
+		}
+
+		for pattern, hostAuth := range authConfig.Hosts {
+			if hostAuth == nil {
+				delete(authConfig.Hosts, pattern)
+				continue
+			}
+			if (hostAuth.ClientCert == "") != (hostAuth.ClientKey == "") {
+				authConfigErr = fmt.Errorf("error in %s: %s needs both clientCert and clientKey", fs.HomeUrlAuthPath, pattern)
+				return
+			}
+		}
+	})
+
+	return authConfig, authConfigErr
+}
+
+// ForHost returns the credentials configured for a host (with an optional port), preferring an exact match with the port, then the host, then the most specific wildcard
+func (c *UrlAuthConfig) ForHost(hostport string) (string, *HostAuth) {
+	hostport = strings.ToLower(hostport)
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+
+	for _, candidate := range []string{hostport, host} {
+		if hostAuth, ok := c.Hosts[candidate]; ok {
+			return candidate, hostAuth
+		}
+	}
+
+	var bestPattern string
+	var best *HostAuth
+	for pattern, hostAuth := range c.Hosts {
+		suffix, ok := strings.CutPrefix(strings.ToLower(pattern), "*")
+		if ok && strings.HasPrefix(suffix, ".") && strings.HasSuffix(host, suffix) && len(pattern) > len(bestPattern) {
+			bestPattern = pattern
+			best = hostAuth
+		}
+	}
+	return bestPattern, best
+}
+
+func (c *UrlAuthConfig) netrcEnabled(hostAuth *HostAuth) bool {
+	if hostAuth != nil && hostAuth.Netrc != nil {
+		return *hostAuth.Netrc
+	}
+	return c.Netrc == nil || *c.Netrc
+}
+
+// authTransport adds the configured credentials for each request's host. Since it runs for every request, redirects to another host get that host's credentials rather than carrying over the original ones.
+type authTransport struct {
+	mu         sync.Mutex
+	transports map[*HostAuth]http.RoundTripper
+}
+
+var defaultAuthTransport = &authTransport{transports: map[*HostAuth]http.RoundTripper{}}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	config, err := LoadUrlAuthConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, hostAuth := config.ForHost(req.URL.Host)
+
+	// a RoundTripper shouldn't modify the request it's given
+	req = req.Clone(req.Context())
+
+	if hostAuth != nil {
+		for name, value := range hostAuth.Headers {
+			expanded, err := expandEnv(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s header %s for %s: %v", filepath.Base(fs.HomeUrlAuthPath), name, pattern, err)
+			}
+			req.Header.Set(name, expanded)
+		}
+
+		if hostAuth.BearerTokenEnv != "" && req.Header.Get("Authorization") == "" {
+			token := os.Getenv(hostAuth.BearerTokenEnv)
+			if token == "" {
+				return nil, fmt.Errorf("%s bearer token for %s: %s isn't set", filepath.Base(fs.HomeUrlAuthPath), pattern, hostAuth.BearerTokenEnv)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	if req.Header.Get("Authorization") == "" && config.netrcEnabled(hostAuth) {
+		if entry := netrcForHost(req.URL.Hostname()); entry != nil {
+			req.SetBasicAuth(entry.login, entry.password)
+		}
+	}
+
+	transport, err := t.transportFor(hostAuth)
+	if err != nil {
+		return nil, fmt.Errorf("%s tls config for %s: %v", filepath.Base(fs.HomeUrlAuthPath), pattern, err)
+	}
+
+	return transport.RoundTrip(req)
+}
+
+func (t *authTransport) transportFor(hostAuth *HostAuth) (http.RoundTripper, error) {
+	if hostAuth == nil || (hostAuth.ClientCert == "" && hostAuth.CACert == "") {
+		return http.DefaultTransport, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if transport, ok := t.transports[hostAuth]; ok {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{}
+
+	if hostAuth.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(expandHome(hostAuth.ClientCert), expandHome(hostAuth.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if hostAuth.CACert != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(expandHome(hostAuth.CACert))
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA certificate: %v", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", hostAuth.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	t.transports[hostAuth] = transport
+
+	return transport, nil
+}
+
+// expandEnv replaces $VAR and ${VAR} with env var values, and errors on unset vars so a missing secret isn't silently sent as an empty header
+func expandEnv(value string) (string, error) {
+	var missing []string
+	expanded := os.Expand(value, func(name string) string {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%s isn't set", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		return filepath.Join(fs.HomeDir, rest)
+	}
+	return path
+}
+
+type netrcEntry struct {
+	machine  string
+	login    string
+	password string
+}
+
+func netrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	name := ".netrc"
+	if runtime.GOOS == "windows" {
+		name = "_netrc"
+	}
+	return filepath.Join(fs.HomeDir, name)
+}
+
+// netrcForHost finds the netrc entry for a host. The 'default' entry isn't used, since it would send credentials to any site a crawl reaches.
+func netrcForHost(host string) *netrcEntry {
+	netrcOnce.Do(func() {
+		bytes, err := os.ReadFile(netrcPath())
+		if err != nil {
+			return
+		}
+		netrcEntries = parseNetrc(string(bytes))
+	})
+
+	for i := range netrcEntries {
+		if strings.EqualFold(netrcEntries[i].machine, host) {
+			return &netrcEntries[i]
+		}
+	}
+	return nil
+}
+
+func parseNetrc(data string) []netrcEntry {
+	var entries []netrcEntry
+	var current *netrcEntry
+
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		for j := 0; j < len(fields); j++ {
+			next := func() string {
+				if j+1 < len(fields) {
+					j++
+					return fields[j]
+				}
+				return ""
+			}
+
+			switch fields[j] {
+			case "machine":
+				entries = append(entries, netrcEntry{machine: next()})
+				current = &entries[len(entries)-1]
+			case "default":
+				current = nil
+			case "login":
+				if current != nil {
+					current.login = next()
+				} else {
+					next()
+				}
+			case "password":
+				if current != nil {
+					current.password = next()
+				} else {
+					next()
+				}
+			case "account":
+				next()
+			case "macdef":
+				// a macro runs until the next blank line
+				current = nil
+				for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+					i++
+				}
+				j = len(fields)
+			}
+		}
+	}
+
+	return entries
+}
+
+// Describe summarizes a host's credentials without their values
+func (hostAuth *HostAuth) Describe() string {
+	var parts []string
+
+	if len(hostAuth.Headers) > 0 {
+		var names []string
+		for name := range hostAuth.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		parts = append(parts, "headers: "+strings.Join(names, ", "))
+	}
+	if hostAuth.BearerTokenEnv != "" {
+		parts = append(parts, "bearer token from $"+hostAuth.BearerTokenEnv)
+	}
+	if hostAuth.ClientCert != "" {
+		parts = append(parts, "client certificate")
+	}
+	if hostAuth.CACert != "" {
+		parts = append(parts, "custom CA")
+	}
+	if hostAuth.Netrc != nil {
+		if *hostAuth.Netrc {
+			parts = append(parts, "netrc")
+		} else {
+			parts = append(parts, "no netrc")
+		}
+	}
+
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,86 @@
+package url
+
+import (
+	"testing"
+)
+
+func TestForHost(t *testing.T) {
+	exact := &HostAuth{BearerTokenEnv: "EXACT"}
+	withPort := &HostAuth{BearerTokenEnv: "PORT"}
+	wildcard := &HostAuth{BearerTokenEnv: "WILDCARD"}
+	narrower := &HostAuth{BearerTokenEnv: "NARROWER"}
+
+	config := &UrlAuthConfig{Hosts: map[string]*HostAuth{
+		"wiki.example.com":       exact,
+		"wiki.example.com:8443":  withPort,
+		"*.example.com":          wildcard,
+		"*.internal.example.com": narrower,
+	}}
+
+	tests := []struct {
+		hostport    string
+		wantPattern string
+		want        *HostAuth
+	}{
+		{"wiki.example.com", "wiki.example.com", exact},
+		// a pattern with the port beats the host alone
+		{"wiki.example.com:8443", "wiki.example.com:8443", withPort},
+		{"wiki.example.com:9000", "wiki.example.com", exact},
+		{"WIKI.Example.com", "wiki.example.com", exact},
+		// an exact host beats a wildcard
+		{"docs.example.com", "*.example.com", wildcard},
+		// the most specific wildcard wins
+		{"git.internal.example.com", "*.internal.example.com", narrower},
+		{"git.internal.example.com:443", "*.internal.example.com", narrower},
+		// a wildcard only matches subdomains
+		{"example.com", "", nil},
+		{"badexample.com", "", nil},
+		{"other.org", "", nil},
+	}
+
+	for _, tt := range tests {
+		pattern, hostAuth := config.ForHost(tt.hostport)
+		if pattern != tt.wantPattern || hostAuth != tt.want {
+			t.Errorf("%s: got %q %+v, want %q %+v", tt.hostport, pattern, hostAuth, tt.wantPattern, tt.want)
+		}
+	}
+}
+
+func TestParseNetrc(t *testing.T) {
+	data := `# personal machines
+machine wiki.example.com login alice password s3cret
+machine git.example.com
+  login bob
+  account ignored
+  password hunter2
+
+macdef init
+machine evil.example.com login macro password macro
+
+default login anon password anon
+machine last.example.com login carol password pw
+`
+
+	entries := parseNetrc(data)
+
+	want := []netrcEntry{
+		{"wiki.example.com", "alice", "s3cret"},
+		{"git.example.com", "bob", "hunter2"},
+		{"last.example.com", "carol", "pw"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %+v, want %+v", entries, want)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entry %d: got %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+
+	// the default entry's credentials aren't attached to any machine
+	for _, entry := range entries {
+		if entry.login == "anon" {
+			t.Errorf("default credentials were used for %s", entry.machine)
+		}
+	}
+}
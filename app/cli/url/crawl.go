@@ -0,0 +1,283 @@
+package url
+
+import (
+	"crypto/sha256"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// MaxCrawlPages limits how many pages a single crawl loads
+	MaxCrawlPages = 100
+
+	crawlConcurrency = 4
+	maxSitemapUrls   = 5000
+)
+
+// links to these are files rather than pages, so they aren't worth fetching
+var crawlSkipExts = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true, ".ico": true,
+	".zip": true, ".gz": true, ".tgz": true, ".tar": true, ".dmg": true, ".exe": true, ".pkg": true, ".deb": true, ".rpm": true,
+	".mp3": true, ".mp4": true, ".mov": true, ".webm": true, ".woff": true, ".woff2": true, ".ttf": true, ".eot": true,
+	".css": true, ".js": true, ".map": true, ".pdf": true,
+}
+
+type CrawlParams struct {
+	// how many links away from the root page to follow
+	Depth int
+	// only follow links under the root page's path, like 'https://example.com/docs/', instead of anywhere on its host
+	SamePrefix bool
+}
+
+type CrawlResult struct {
+	Pages []*Page
+	// urls that couldn't be fetched or weren't text
+	Failed []string
+	// urls robots.txt didn't allow
+	Disallowed []string
+	// set when more pages were in scope than MaxCrawlPages
+	Truncated bool
+}
+
+// Crawl fetches a page and the pages it links to within the same site, breadth first, along with any pages listed in the site's sitemap.xml. Pages that robots.txt disallows for our user agent are skipped, and pages that are the same after normalizing their urls or comparing their contents are only included once.
+func Crawl(root string, params CrawlParams) (*CrawlResult, error) {
+	rootUrl, err := url.Parse(normalizeCrawlUrl(root))
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %s: %v", root, err)
+	}
+
+	origin := rootUrl.Scheme + "://" + rootUrl.Host
+
+	prefix := "/"
+	if params.SamePrefix {
+		prefix = rootUrl.Path
+		if !strings.HasSuffix(prefix, "/") {
+			prefix = path.Dir(prefix)
+			if !strings.HasSuffix(prefix, "/") {
+				prefix += "/"
+			}
+		}
+	}
+
+	inScope := func(u *url.URL) bool {
+		if u.Scheme+"://"+u.Host != origin || !strings.HasPrefix(u.Path, prefix) {
+			return false
+		}
+		return !crawlSkipExts[strings.ToLower(path.Ext(u.Path))]
+	}
+
+	robots := fetchRobots(origin)
+
+	res := &CrawlResult{}
+	seen := map[string]bool{}
+	seenContent := map[[32]byte]bool{}
+
+	// the root's path and query are what robots.txt rules match against
+	allowed := func(u *url.URL) bool {
+		return robots.allowed(u.RequestURI())
+	}
+
+	if !allowed(rootUrl) {
+		return nil, fmt.Errorf("robots.txt for %s doesn't allow crawling %s", rootUrl.Host, rootUrl.String())
+	}
+
+	var frontier []string
+	enqueue := func(rawUrl string) bool {
+		normalized := normalizeCrawlUrl(rawUrl)
+		if seen[normalized] {
+			return false
+		}
+		u, err := url.Parse(normalized)
+		if err != nil || !inScope(u) {
+			return false
+		}
+		seen[normalized] = true
+		if !allowed(u) {
+			res.Disallowed = append(res.Disallowed, normalized)
+			return false
+		}
+		if len(seen)-len(res.Disallowed) > MaxCrawlPages {
+			res.Truncated = true
+			return false
+		}
+		frontier = append(frontier, normalized)
+		return true
+	}
+
+	enqueue(rootUrl.String())
+
+	var sitemapUrls []string
+	if params.Depth > 0 {
+		sitemapUrls = fetchSitemapUrls(origin, robots.sitemaps)
+	}
+
+	for depth := 0; len(frontier) > 0; depth++ {
+		level := frontier
+		frontier = nil
+
+		pages := make([]*Page, len(level))
+		errs := make([]error, len(level))
+		sem := make(chan struct{}, crawlConcurrency)
+		var wg sync.WaitGroup
+
+		for i, pageUrl := range level {
+			wg.Add(1)
+			go func(i int, pageUrl string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				pages[i], errs[i] = FetchPage(pageUrl, "", "")
+			}(i, pageUrl)
+		}
+		wg.Wait()
+
+		for i, page := range pages {
+			if errs[i] != nil {
+				if depth == 0 {
+					return nil, fmt.Errorf("failed to fetch %s: %v", level[i], errs[i])
+				}
+				res.Failed = append(res.Failed, level[i])
+				continue
+			}
+
+			if !isTextContentType(page.ContentType) {
+				if depth == 0 {
+					return nil, fmt.Errorf("%s isn't a text page (content type %s)", level[i], page.ContentType)
+				}
+				res.Failed = append(res.Failed, level[i])
+				continue
+			}
+
+			// the same page is often reachable at more than one url, like with and without 'index.html'
+			contentHash := sha256.Sum256([]byte(page.Body))
+			if seenContent[contentHash] {
+				continue
+			}
+			seenContent[contentHash] = true
+
+			page.Url = level[i]
+			res.Pages = append(res.Pages, page)
+
+			if depth < params.Depth {
+				for _, link := range page.Links {
+					enqueue(link)
+				}
+			}
+		}
+
+		// sitemap pages count as linked from the root
+		if depth == 0 {
+			for _, sitemapUrl := range sitemapUrls {
+				enqueue(sitemapUrl)
+			}
+		}
+	}
+
+	sort.Strings(res.Failed)
+	sort.Strings(res.Disallowed)
+
+	return res, nil
+}
+
+// normalizeCrawlUrl drops fragments and default ports and lowercases the scheme and host, so the same page isn't fetched twice
+func normalizeCrawlUrl(rawUrl string) string {
+	u, err := url.Parse(strings.TrimSpace(rawUrl))
+	if err != nil {
+		return rawUrl
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if (u.Scheme == "http" && u.Port() == "80") || (u.Scheme == "https" && u.Port() == "443") {
+		u.Host = u.Hostname()
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String()
+}
+
+func isTextContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return contentType == "" || strings.HasPrefix(contentType, "text/") || strings.Contains(contentType, "markdown")
+}
+
+type sitemapXml struct {
+	Urls     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// fetchSitemapUrls reads the page urls from the site's sitemap.xml, or the sitemaps listed in robots.txt, following one level of sitemap indexes
+func fetchSitemapUrls(origin string, robotsSitemaps []string) []string {
+	sitemaps := robotsSitemaps
+	if len(sitemaps) == 0 {
+		sitemaps = []string{origin + "/sitemap.xml"}
+	}
+
+	var urls []string
+	for _, sitemapUrl := range sitemaps {
+		parsed := fetchSitemap(sitemapUrl)
+		if parsed == nil {
+			continue
+		}
+		for _, loc := range parsed.Urls {
+			urls = append(urls, strings.TrimSpace(loc.Loc))
+		}
+		for _, nested := range parsed.Sitemaps {
+			if nestedParsed := fetchSitemap(strings.TrimSpace(nested.Loc)); nestedParsed != nil {
+				for _, loc := range nestedParsed.Urls {
+					urls = append(urls, strings.TrimSpace(loc.Loc))
+				}
+			}
+			if len(urls) >= maxSitemapUrls {
+				break
+			}
+		}
+		if len(urls) >= maxSitemapUrls {
+			break
+		}
+	}
+
+	if len(urls) > maxSitemapUrls {
+		urls = urls[:maxSitemapUrls]
+	}
+	return urls
+}
+
+func fetchSitemap(sitemapUrl string) *sitemapXml {
+	req, err := http.NewRequest(http.MethodGet, sitemapUrl, nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := newClient().Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil
+	}
+
+	var parsed sitemapXml
+	err = xml.NewDecoder(io.LimitReader(resp.Body, maxContentSizeInMB*1024*1024)).Decode(&parsed)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
@@ -0,0 +1,177 @@
+package url
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// newTestSite serves html pages where each page's body lists its links
+func newTestSite(t *testing.T, robots string, pages map[string][]string) *httptest.Server {
+	t.Helper()
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+
+	mux := http.NewServeMux()
+	if robots != "" {
+		mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, robots)
+		})
+	}
+	for path, links := range pages {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != path {
+				http.NotFound(w, r)
+				return
+			}
+			// an index.html page has the same content as its directory's page
+			body := fmt.Sprintf("<html><body><main><p>%s</p>", strings.TrimSuffix(path, "index.html"))
+			for _, link := range links {
+				body += fmt.Sprintf(`<a href="%s">link</a>`, link)
+			}
+			body += "</main></body></html>"
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, body)
+		})
+	}
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func crawledPaths(srv *httptest.Server, pages []*Page) []string {
+	var paths []string
+	for _, page := range pages {
+		paths = append(paths, strings.TrimPrefix(page.Url, srv.URL))
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func testSitePages() map[string][]string {
+	docsLinks := []string{
+		"a",
+		"a#section",
+		"index.html",
+		"/docs/private/secret",
+		"/blog/post",
+		"logo.png",
+		"https://other.example.com/docs/",
+	}
+	return map[string][]string{
+		"/docs/":           docsLinks,
+		"/docs/index.html": docsLinks,
+		"/docs/a":          {"b"},
+		"/docs/b":          {"c"},
+		"/docs/c":          {},
+		"/blog/post":       {},
+	}
+}
+
+func TestCrawlSamePrefix(t *testing.T) {
+	srv := newTestSite(t, "User-agent: *\nDisallow: /docs/private\n", testSitePages())
+
+	res, err := Crawl(srv.URL+"/docs/", CrawlParams{Depth: 2, SamePrefix: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// /docs/index.html has the same content as /docs/, a#section is the same url as a, /blog/post is outside the prefix, and /docs/c is past the depth
+	got := strings.Join(crawledPaths(srv, res.Pages), " ")
+	want := "/docs/ /docs/a /docs/b"
+	if got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	if len(res.Disallowed) != 1 || res.Disallowed[0] != srv.URL+"/docs/private/secret" {
+		t.Fatalf("expected the disallowed page to be reported: %v", res.Disallowed)
+	}
+	if len(res.Failed) != 0 || res.Truncated {
+		t.Fatalf("unexpected failures: %+v", res)
+	}
+}
+
+func TestCrawlWholeHost(t *testing.T) {
+	srv := newTestSite(t, "", testSitePages())
+
+	res, err := Crawl(srv.URL+"/docs/", CrawlParams{Depth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without robots.txt nothing is disallowed, and other paths on the host are followed -- but never other hosts
+	got := strings.Join(crawledPaths(srv, res.Pages), " ")
+	want := "/blog/post /docs/ /docs/a"
+	if got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	if len(res.Disallowed) != 0 || len(res.Failed) != 1 || res.Failed[0] != srv.URL+"/docs/private/secret" {
+		t.Fatalf("expected only the missing page to fail: %+v", res)
+	}
+}
+
+func TestCrawlRootDisallowed(t *testing.T) {
+	srv := newTestSite(t, "User-agent: plandex\nDisallow: /\n\nUser-agent: *\nAllow: /\n", testSitePages())
+
+	if _, err := Crawl(srv.URL+"/docs/", CrawlParams{Depth: 1}); err == nil {
+		t.Fatal("expected an error when robots.txt disallows the root page")
+	}
+}
+
+func TestNormalizeCrawlUrl(t *testing.T) {
+	tests := map[string]string{
+		"HTTPS://Example.com:443/docs#intro": "https://example.com/docs",
+		"http://example.com:80":              "http://example.com/",
+		"http://example.com:8080/a?b=c":      "http://example.com:8080/a?b=c",
+	}
+	for in, want := range tests {
+		if got := normalizeCrawlUrl(in); got != want {
+			t.Errorf("%s: got %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestFetchPageNotModified(t *testing.T) {
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` || r.Header.Get("If-Modified-Since") == "Mon, 01 Jan 2024 00:00:00 GMT" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "hello")
+	}))
+	defer srv.Close()
+
+	page, err := FetchPage(srv.URL+"/", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.NotModified || page.Body != "hello" || page.ETag != `"v1"` {
+		t.Fatalf("unexpected first fetch: %+v", page)
+	}
+
+	page, err = FetchPage(srv.URL+"/", page.ETag, page.LastModified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the 304 has no validators of its own, so the ones it was fetched with are kept for the next fetch
+	if !page.NotModified || page.Body != "" || page.ETag != `"v1"` || page.LastModified != "Mon, 01 Jan 2024 00:00:00 GMT" {
+		t.Fatalf("unexpected conditional fetch: %+v", page)
+	}
+
+	if _, err := FetchPage(srv.URL+"/missing", "", ""); err == nil {
+		t.Fatal("expected an error for a 404")
+	}
+}
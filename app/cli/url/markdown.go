@@ -0,0 +1,278 @@
+package url
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var whitespaceRegex = regexp.MustCompile(`\s+`)
+var blankLinesRegex = regexp.MustCompile(`\n{3,}`)
+
+// page chrome and non-content elements that would only add noise to context
+const htmlSkipSelector = "script, style, noscript, template, svg, canvas, iframe, nav, footer, aside, form, button, [role=navigation], [aria-hidden=true]"
+
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "blockquote": true, "body": true, "dd": true, "details": true, "div": true, "dl": true, "dt": true,
+	"fieldset": true, "figcaption": true, "figure": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "html": true, "li": true, "main": true, "ol": true, "p": true, "pre": true, "section": true,
+	"summary": true, "table": true, "tbody": true, "td": true, "tfoot": true, "th": true, "thead": true, "tr": true, "ul": true,
+}
+
+// htmlToMarkdown converts a page's main content to markdown, keeping headings, code blocks, lists, tables, and links so the structure of documentation survives
+func htmlToMarkdown(doc *goquery.Document, base *url.URL) string {
+	doc.Find(htmlSkipSelector).Remove()
+
+	root := doc.Find("main, article, [role=main]").First()
+	if root.Length() == 0 {
+		root = doc.Find("body")
+	}
+	if root.Length() == 0 {
+		root = doc.Selection
+	}
+
+	c := markdownConverter{base: base}
+	md := c.blocks(root)
+
+	title := strings.TrimSpace(doc.Find("title").First().Text())
+	if title != "" && !strings.HasPrefix(md, "# ") {
+		md = "# " + whitespaceRegex.ReplaceAllString(title, " ") + "\n\n" + md
+	}
+
+	return strings.TrimSpace(blankLinesRegex.ReplaceAllString(md, "\n\n")) + "\n"
+}
+
+type markdownConverter struct {
+	base *url.URL
+}
+
+// blocks renders the children of a block element, separating block-level children with blank lines and gathering runs of inline content into paragraphs
+func (c *markdownConverter) blocks(s *goquery.Selection) string {
+	var parts []string
+	var inline strings.Builder
+
+	flush := func() {
+		text := strings.TrimSpace(inline.String())
+		if text != "" {
+			parts = append(parts, text)
+		}
+		inline.Reset()
+	}
+
+	s.Contents().Each(func(_ int, child *goquery.Selection) {
+		name := goquery.NodeName(child)
+		if !htmlBlockTags[name] {
+			inline.WriteString(c.inline(child))
+			return
+		}
+		flush()
+		if block := strings.TrimSpace(c.block(name, child)); block != "" {
+			parts = append(parts, block)
+		}
+	})
+	flush()
+
+	return strings.Join(parts, "\n\n")
+}
+
+func (c *markdownConverter) block(name string, s *goquery.Selection) string {
+	switch name {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := int(name[1] - '0')
+		return strings.Repeat("#", level) + " " + strings.TrimSpace(c.inlineChildren(s))
+
+	case "pre":
+		return c.codeBlock(s)
+
+	case "p", "dt", "summary", "figcaption":
+		return strings.TrimSpace(c.inlineChildren(s))
+
+	case "hr":
+		return "---"
+
+	case "ul", "ol":
+		return c.list(s, name == "ol")
+
+	case "blockquote":
+		lines := strings.Split(c.blocks(s), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return strings.Join(lines, "\n")
+
+	case "table":
+		return c.table(s)
+	}
+
+	return c.blocks(s)
+}
+
+func (c *markdownConverter) codeBlock(s *goquery.Selection) string {
+	code := strings.Trim(s.Text(), "\n")
+
+	lang := codeLanguage(s)
+	if lang == "" {
+		lang = codeLanguage(s.Find("code").First())
+	}
+
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+
+	return fence + lang + "\n" + code + "\n" + fence
+}
+
+var codeLanguageRegex = regexp.MustCompile(`(?:^|\s)(?:language|lang|highlight-source)-([\w+#-]+)`)
+
+func codeLanguage(s *goquery.Selection) string {
+	class, _ := s.Attr("class")
+	if m := codeLanguageRegex.FindStringSubmatch(class); m != nil {
+		return m[1]
+	}
+	if lang, ok := s.Attr("data-lang"); ok {
+		return lang
+	}
+	return ""
+}
+
+func (c *markdownConverter) list(s *goquery.Selection, ordered bool) string {
+	var items []string
+	n := 0
+	s.ChildrenFiltered("li").Each(func(_ int, li *goquery.Selection) {
+		n++
+		marker := "- "
+		if ordered {
+			marker = fmt.Sprintf("%d. ", n)
+		}
+		indent := strings.Repeat(" ", len(marker))
+
+		lines := strings.Split(c.blocks(li), "\n")
+		for i, line := range lines {
+			if i == 0 {
+				lines[i] = marker + line
+			} else if line != "" {
+				lines[i] = indent + line
+			}
+		}
+		items = append(items, strings.Join(lines, "\n"))
+	})
+	return strings.Join(items, "\n")
+}
+
+func (c *markdownConverter) table(s *goquery.Selection) string {
+	var rows [][]string
+	s.Find("tr").Each(func(_ int, tr *goquery.Selection) {
+		var row []string
+		tr.ChildrenFiltered("th, td").Each(func(_ int, cell *goquery.Selection) {
+			text := strings.TrimSpace(c.inlineChildren(cell))
+			row = append(row, strings.ReplaceAll(strings.ReplaceAll(text, "\n", " "), "|", `\|`))
+		})
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	})
+	if len(rows) == 0 {
+		return ""
+	}
+
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+
+	var lines []string
+	for i, row := range rows {
+		for len(row) < cols {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", cols))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *markdownConverter) inlineChildren(s *goquery.Selection) string {
+	var b strings.Builder
+	s.Contents().Each(func(_ int, child *goquery.Selection) {
+		b.WriteString(c.inline(child))
+	})
+	return b.String()
+}
+
+func (c *markdownConverter) inline(s *goquery.Selection) string {
+	switch goquery.NodeName(s) {
+	case "#text":
+		return whitespaceRegex.ReplaceAllString(s.Text(), " ")
+
+	case "#comment":
+		return ""
+
+	case "br":
+		return "\n"
+
+	case "code", "kbd", "samp":
+		text := strings.TrimSpace(s.Text())
+		if text == "" {
+			return ""
+		}
+		tick := "`"
+		for strings.Contains(text, tick) {
+			tick += "`"
+		}
+		return tick + text + tick
+
+	case "strong", "b":
+		return wrapInline(c.inlineChildren(s), "**")
+
+	case "em", "i":
+		return wrapInline(c.inlineChildren(s), "*")
+
+	case "a":
+		text := c.inlineChildren(s)
+		href, _ := s.Attr("href")
+		resolved := c.resolve(href)
+		if strings.TrimSpace(text) == "" || resolved == "" {
+			return text
+		}
+		return "[" + strings.TrimSpace(text) + "](" + resolved + ")"
+
+	case "img":
+		alt, _ := s.Attr("alt")
+		return alt
+	}
+
+	return c.inlineChildren(s)
+}
+
+// resolve makes a link absolute so it still works out of the page's context -- in-page anchors and javascript links are dropped
+func (c *markdownConverter) resolve(href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return ""
+	}
+	if c.base == nil {
+		return href
+	}
+	u, err := c.base.Parse(href)
+	if err != nil {
+		return ""
+	}
+	return u.String()
+}
+
+func wrapInline(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	// keep surrounding spaces outside the markers so the emphasis still parses
+	leading := text[:len(text)-len(strings.TrimLeft(text, " "))]
+	trailing := text[len(strings.TrimRight(text, " ")):]
+	return leading + marker + trimmed + marker + trailing
+}
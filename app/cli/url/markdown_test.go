@@ -0,0 +1,46 @@
+package url
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestHtmlToMarkdown(t *testing.T) {
+	html := `<html><head><title>Guide</title><script>track()</script></head>
+<body>
+<nav><a href="/">Home</a></nav>
+<main>
+<h2>Install <code>cli</code></h2>
+<p>Run the   <strong>installer</strong> from the <a href="../download">download page</a>. <a href="#top">Top</a></p>
+<pre><code class="language-bash">curl -sL https://example.com/install | bash</code></pre>
+<ul><li>one</li><li>two<ul><li>nested</li></ul></li></ul>
+<ol><li>first</li><li>second</li></ol>
+<table><tr><th>Flag</th><th>Use</th></tr><tr><td>-v</td><td>a | b</td></tr></table>
+<blockquote><p>Note</p></blockquote>
+</main>
+<footer>Copyright</footer>
+</body></html>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, _ := url.Parse("https://example.com/docs/guide")
+
+	got := htmlToMarkdown(doc, base)
+	want := "# Guide\n\n" +
+		"## Install `cli`\n\n" +
+		"Run the **installer** from the [download page](https://example.com/download). Top\n\n" +
+		"```bash\ncurl -sL https://example.com/install | bash\n```\n\n" +
+		"- one\n- two\n\n  - nested\n\n" +
+		"1. first\n2. second\n\n" +
+		"| Flag | Use |\n| --- | --- |\n| -v | a \\| b |\n\n" +
+		"> Note\n"
+
+	if got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,156 @@
+package url
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// robotsRules are the allow and disallow rules that apply to our user agent in a site's robots.txt
+type robotsRules struct {
+	allow    []robotsRule
+	disallow []robotsRule
+	// sitemap urls listed in robots.txt, which apply to every agent
+	sitemaps []string
+}
+
+// fetchRobots gets the robots.txt rules for a site. A missing or unreadable robots.txt allows everything.
+func fetchRobots(origin string) *robotsRules {
+	req, err := http.NewRequest(http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return &robotsRules{}
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := newClient().Do(req)
+	if err != nil {
+		return &robotsRules{}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &robotsRules{}
+	}
+
+	return parseRobots(io.LimitReader(resp.Body, 512*1024))
+}
+
+// parseRobots uses the group for our user agent if there is one, otherwise the group for '*'
+func parseRobots(r io.Reader) *robotsRules {
+	type group struct {
+		agents          []string
+		allow, disallow []string
+	}
+
+	var groups []*group
+	var current *group
+	inAgents := false
+	var sitemaps []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// consecutive user-agent lines share a group
+			if !inAgents {
+				current = &group{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			inAgents = true
+		case "allow", "disallow":
+			inAgents = false
+			if current == nil || value == "" {
+				continue
+			}
+			if key == "allow" {
+				current.allow = append(current.allow, value)
+			} else {
+				current.disallow = append(current.disallow, value)
+			}
+		case "sitemap":
+			sitemaps = append(sitemaps, value)
+		default:
+			inAgents = false
+		}
+	}
+
+	var matched, wildcard *group
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			if agent == "*" && wildcard == nil {
+				wildcard = g
+			} else if agent != "*" && strings.Contains(userAgent, agent) && matched == nil {
+				matched = g
+			}
+		}
+	}
+	if matched == nil {
+		matched = wildcard
+	}
+
+	rules := &robotsRules{sitemaps: sitemaps}
+	if matched != nil {
+		for _, pattern := range matched.allow {
+			rules.allow = append(rules.allow, robotsRule{pattern, robotsPatternRegex(pattern)})
+		}
+		for _, pattern := range matched.disallow {
+			rules.disallow = append(rules.disallow, robotsRule{pattern, robotsPatternRegex(pattern)})
+		}
+	}
+	return rules
+}
+
+type robotsRule struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// robotsPatternRegex converts a path pattern, where '*' matches anything and a trailing '$' anchors the end, to a regex
+func robotsPatternRegex(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// allowed checks a path (with its query) against the rules -- the longest matching pattern wins, and allow wins a tie
+func (r *robotsRules) allowed(path string) bool {
+	longest := func(rules []robotsRule) int {
+		n := -1
+		for _, rule := range rules {
+			if rule.re.MatchString(path) {
+				n = max(n, len(rule.pattern))
+			}
+		}
+		return n
+	}
+
+	disallowed := longest(r.disallow)
+	if disallowed < 0 {
+		return true
+	}
+	return longest(r.allow) >= disallowed
+}
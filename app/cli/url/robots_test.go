@@ -0,0 +1,59 @@
+package url
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRobots(t *testing.T) {
+	robots := `# comments are ignored
+User-agent: *
+Disallow: /
+
+User-agent: googlebot
+User-agent: plandex
+Disallow: /private
+Allow: /private/public
+Disallow: /*.json$
+Disallow: /search?
+Allow: /tie
+Disallow: /tie
+
+Sitemap: https://example.com/sitemap.xml
+`
+	rules := parseRobots(strings.NewReader(robots))
+
+	tests := map[string]bool{
+		// the group naming our agent is used instead of '*'
+		"/docs":                true,
+		"/private":             false,
+		"/private/keys":        false,
+		"/private/public/page": true,
+		"/data.json":           false,
+		"/data.json?x=1":       true,
+		"/search?q=x":          false,
+		"/search":              true,
+		"/tie":                 true,
+	}
+	for path, want := range tests {
+		if got := rules.allowed(path); got != want {
+			t.Errorf("%s: got %v, want %v", path, got, want)
+		}
+	}
+
+	if len(rules.sitemaps) != 1 || rules.sitemaps[0] != "https://example.com/sitemap.xml" {
+		t.Errorf("unexpected sitemaps: %v", rules.sitemaps)
+	}
+
+	// with no group for our agent, '*' applies
+	rules = parseRobots(strings.NewReader("User-agent: otherbot\nDisallow: /\n\nUser-agent: *\nDisallow: /admin\n"))
+	if rules.allowed("/admin/users") || !rules.allowed("/docs") {
+		t.Error("the '*' group wasn't used")
+	}
+
+	// an empty disallow allows everything
+	rules = parseRobots(strings.NewReader("User-agent: *\nDisallow:\n"))
+	if !rules.allowed("/anything") {
+		t.Error("an empty disallow blocked a path")
+	}
+}
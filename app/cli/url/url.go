@@ -1,11 +1,12 @@
 package url
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"plandex-cli/term"
 	"regexp"
 	"strings"
 	"time"
@@ -20,9 +21,26 @@ const (
 	maxContentSizeInMB = 10
 )
 
-func FetchURLContent(url string) (string, error) {
-	client := &http.Client{
-		Timeout: httpTimeout,
+// userAgent identifies requests to servers, and is the agent matched against robots.txt rules when crawling
+const userAgent = "plandex"
+
+// Page is a fetched url's content, along with the validators to refetch it conditionally
+type Page struct {
+	Url          string
+	Body         string
+	ContentType  string
+	ETag         string
+	LastModified string
+	// set when a conditional fetch found the page unchanged, in which case Body is empty
+	NotModified bool
+	// links on an html page, resolved to absolute urls
+	Links []string
+}
+
+func newClient() *http.Client {
+	return &http.Client{
+		Timeout:   httpTimeout,
+		Transport: defaultAuthTransport,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			if len(via) >= maxRedirections {
 				return errors.New("stopped after too many redirects")
@@ -30,15 +48,56 @@ func FetchURLContent(url string) (string, error) {
 			return nil
 		},
 	}
+}
 
-	resp, err := client.Get(url)
+func FetchURLContent(url string) (string, error) {
+	page, err := FetchPage(url, "", "")
 	if err != nil {
 		return "", err
 	}
+	return page.Body, nil
+}
+
+// FetchPage fetches a url, converting html to markdown. When etag or lastModified are set from an earlier fetch, the request is conditional, and an unchanged page is returned with NotModified set.
+func FetchPage(pageUrl, etag, lastModified string) (*Page, error) {
+	req, err := http.NewRequest(http.MethodGet, pageUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := newClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
 	defer resp.Body.Close()
 
+	page := &Page{
+		Url:          pageUrl,
+		ContentType:  resp.Header.Get("Content-Type"),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		page.NotModified = true
+		if page.ETag == "" {
+			page.ETag = etag
+		}
+		if page.LastModified == "" {
+			page.LastModified = lastModified
+		}
+		return page, nil
+	}
+
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", errors.New("non-2xx HTTP response status: " + resp.Status)
+		return nil, errors.New("non-2xx HTTP response status: " + resp.Status)
 	}
 
 	// Limit the response reader to a maximum amount
@@ -46,25 +105,36 @@ func FetchURLContent(url string) (string, error) {
 
 	content, err := io.ReadAll(limitedReader)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	contentType := resp.Header.Get("Content-Type")
-	if strings.Contains(contentType, "text/html") {
-		return ExtractTextualContent(string(content)), nil
-	} else {
-		return string(content), nil
-	}
-}
+	if strings.Contains(page.ContentType, "text/html") {
+		doc, err := goquery.NewDocumentFromReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse HTML: %v", err)
+		}
 
-func ExtractTextualContent(htmlContent string) string {
-	r := strings.NewReader(htmlContent)
-	doc, err := goquery.NewDocumentFromReader(r)
-	if err != nil {
-		term.OutputErrorAndExit("Failed to parse HTML: %v", err)
+		// relative links are relative to where any redirects ended up
+		base := resp.Request.URL
+		if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+			if u, err := base.Parse(href); err == nil {
+				base = u
+			}
+		}
+
+		doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+			href, _ := s.Attr("href")
+			if u, err := base.Parse(strings.TrimSpace(href)); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+				page.Links = append(page.Links, u.String())
+			}
+		})
+
+		page.Body = htmlToMarkdown(doc, base)
+	} else {
+		page.Body = string(content)
 	}
 
-	return doc.Text()
+	return page, nil
 }
 
 func SanitizeURL(url string) string {
@@ -0,0 +1,310 @@
+package budgets
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"plandex-server/webhooks"
+	"strings"
+	"sync"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/shopspring/decimal"
+)
+
+// Enabled reports whether budgets are enforced. Plandex Cloud has its own credits and monthly limits, so budgets only apply to self-hosted servers.
+func Enabled() bool {
+	return os.Getenv("IS_CLOUD") == ""
+}
+
+type CheckParams struct {
+	OrgId  string
+	UserId string
+	PlanId string
+
+	// the most the request is expected to use
+	InputTokens  int
+	OutputTokens int
+
+	ModelConfig *shared.BaseModelConfig
+}
+
+// Check returns an error if a model request could take spend over a blocking budget.
+// Warning budgets are logged and sent as budget.exceeded webhooks instead.
+func Check(params CheckParams) *shared.ApiError {
+	budgets, err := db.ListBudgetsForRequest(params.OrgId, params.UserId, params.PlanId)
+	if err != nil {
+		log.Printf("Error listing budgets: %v\n", err)
+		return &shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusInternalServerError,
+			Msg:    "Error checking budgets",
+		}
+	}
+
+	if len(budgets) == 0 {
+		return nil
+	}
+
+	statuses, err := getStatuses(budgets, params.OrgId, params.UserId, params.PlanId, time.Now())
+	if err != nil {
+		log.Printf("Error getting budget statuses: %v\n", err)
+		return &shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusInternalServerError,
+			Msg:    "Error checking budgets",
+		}
+	}
+
+	outputTokens := params.OutputTokens
+	if outputTokens < 0 {
+		outputTokens = 0
+	}
+	tokens := int64(params.InputTokens + outputTokens)
+
+	cost := decimal.Zero
+	if params.ModelConfig != nil {
+		cost = params.ModelConfig.GetCost(params.InputTokens, 0, outputTokens)
+	}
+
+	for _, status := range statuses {
+		if !wouldExceed(status, tokens, cost) {
+			continue
+		}
+
+		blocked := status.Budget.Action == shared.BudgetActionBlock
+		notifyExceeded(params, status, blocked)
+
+		if blocked {
+			log.Printf("Budget %s blocked model request for user %s on plan %s\n", status.Budget.Id, params.UserId, params.PlanId)
+			return &shared.ApiError{
+				Type:                shared.ApiErrorTypeBudgetExceeded,
+				Status:              http.StatusTooManyRequests,
+				Msg:                 describeExceeded(status),
+				BudgetExceededError: &shared.BudgetExceededError{Status: status},
+			}
+		}
+
+		log.Printf("Warning: model request for user %s on plan %s is over budget %s\n", params.UserId, params.PlanId, status.Budget.Id)
+	}
+
+	return nil
+}
+
+type RecordParams struct {
+	OrgId  string
+	UserId string
+	PlanId string
+
+	InputTokens  int
+	CachedTokens int
+	OutputTokens int
+
+	ModelConfig *shared.BaseModelConfig
+}
+
+// Record adds a finished model request's usage to the spend that budgets are checked against
+func Record(params RecordParams) error {
+	cost := decimal.Zero
+	if params.ModelConfig != nil {
+		cost = params.ModelConfig.GetCost(params.InputTokens, params.CachedTokens, params.OutputTokens)
+	}
+
+	return db.RecordModelSpend(db.RecordModelSpendParams{
+		OrgId:        params.OrgId,
+		UserId:       params.UserId,
+		PlanId:       params.PlanId,
+		InputTokens:  params.InputTokens,
+		CachedTokens: params.CachedTokens,
+		OutputTokens: params.OutputTokens,
+		Cost:         cost,
+	})
+}
+
+// GetStatuses returns the current period's spend for every budget that applies to a user, and to a plan if planId is set
+func GetStatuses(orgId, userId, planId string) ([]*shared.BudgetStatus, error) {
+	budgets, err := db.ListBudgetsForRequest(orgId, userId, planId)
+	if err != nil {
+		return nil, err
+	}
+
+	return getStatuses(budgets, orgId, userId, planId, time.Now())
+}
+
+func getStatuses(budgets []*db.Budget, orgId, userId, planId string, now time.Time) ([]*shared.BudgetStatus, error) {
+	// budgets with the same scope and period share a spend query
+	spendByKey := map[string]*db.ModelSpend{}
+
+	var statuses []*shared.BudgetStatus
+	for _, budget := range budgets {
+		start, end := periodBounds(budget.Period, now)
+
+		spendParams := db.GetModelSpendParams{
+			OrgId: orgId,
+			Since: start,
+		}
+		switch budget.Scope {
+		case shared.BudgetScopeUser:
+			spendParams.UserId = userId
+		case shared.BudgetScopePlan:
+			spendParams.PlanId = planId
+		}
+
+		key := strings.Join([]string{spendParams.UserId, spendParams.PlanId, start.Format(time.RFC3339)}, "|")
+		spend, ok := spendByKey[key]
+		if !ok {
+			var err error
+			spend, err = db.GetModelSpend(spendParams)
+			if err != nil {
+				return nil, err
+			}
+			spendByKey[key] = spend
+		}
+
+		statuses = append(statuses, newStatus(budget.ToApi(), spend, start, end))
+	}
+
+	return statuses, nil
+}
+
+// periodBounds returns the start and end of the UTC day or month containing now
+func periodBounds(period shared.BudgetPeriod, now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+
+	if period == shared.BudgetPeriodMonthly {
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	}
+
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 0, 1)
+}
+
+func newStatus(budget *shared.Budget, spend *db.ModelSpend, start, end time.Time) *shared.BudgetStatus {
+	status := &shared.BudgetStatus{
+		Budget:      budget,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		UsedTokens:  spend.TotalTokens(),
+		UsedCost:    spend.Cost,
+	}
+
+	if budget.MaxTokens != nil {
+		remaining := *budget.MaxTokens - status.UsedTokens
+		if remaining <= 0 {
+			remaining = 0
+			status.Exceeded = true
+		}
+		status.RemainingTokens = &remaining
+	}
+
+	if budget.MaxCost != nil {
+		remaining := budget.MaxCost.Sub(status.UsedCost)
+		if !remaining.IsPositive() {
+			remaining = decimal.Zero
+			status.Exceeded = true
+		}
+		status.RemainingCost = &remaining
+	}
+
+	return status
+}
+
+func wouldExceed(status *shared.BudgetStatus, tokens int64, cost decimal.Decimal) bool {
+	if status.Exceeded {
+		return true
+	}
+
+	if status.RemainingTokens != nil && tokens > *status.RemainingTokens {
+		return true
+	}
+
+	if status.RemainingCost != nil && cost.GreaterThan(*status.RemainingCost) {
+		return true
+	}
+
+	return false
+}
+
+func describeExceeded(status *shared.BudgetStatus) string {
+	budget := status.Budget
+
+	var owner string
+	switch budget.Scope {
+	case shared.BudgetScopeOrg:
+		owner = "your org's"
+	case shared.BudgetScopeUser:
+		owner = "your"
+	case shared.BudgetScopePlan:
+		owner = "this plan's"
+	}
+
+	var used []string
+	if budget.MaxTokens != nil {
+		used = append(used, fmt.Sprintf("%d of %d tokens", status.UsedTokens, *budget.MaxTokens))
+	}
+	if budget.MaxCost != nil {
+		used = append(used, fmt.Sprintf("$%s of $%s", status.UsedCost.StringFixed(2), budget.MaxCost.StringFixed(2)))
+	}
+
+	return fmt.Sprintf("This request would go over %s %s model budget (%s used). It resets at %s.", owner, budget.Period, strings.Join(used, ", "), status.PeriodEnd.Format("2006-01-02 15:04 MST"))
+}
+
+type budgetExceededWebhookData struct {
+	Status  *shared.BudgetStatus `json:"status"`
+	Blocked bool                 `json:"blocked"`
+	ModelId shared.ModelId       `json:"modelId,omitempty"`
+}
+
+// exceeded webhooks are only sent once per budget, user or plan, and period -- keyed by notifiedKey, with the period end as the value
+var notified sync.Map
+
+func notifyExceeded(params CheckParams, status *shared.BudgetStatus, blocked bool) {
+	if !webhooks.Enabled() {
+		return
+	}
+
+	key := notifiedKey(params, status)
+	if _, loaded := notified.LoadOrStore(key, status.PeriodEnd); loaded {
+		return
+	}
+
+	now := time.Now()
+	notified.Range(func(k, v any) bool {
+		if v.(time.Time).Before(now) {
+			notified.Delete(k)
+		}
+		return true
+	})
+
+	data := budgetExceededWebhookData{
+		Status:  status,
+		Blocked: blocked,
+	}
+	if params.ModelConfig != nil {
+		data.ModelId = params.ModelConfig.ModelId
+	}
+
+	webhooks.Emit(webhooks.EmitParams{
+		Type:   webhooks.EventBudgetExceeded,
+		OrgId:  params.OrgId,
+		UserId: params.UserId,
+		PlanId: params.PlanId,
+		Data:   data,
+	})
+}
+
+func notifiedKey(params CheckParams, status *shared.BudgetStatus) string {
+	parts := []string{status.Budget.Id, status.PeriodStart.Format(time.RFC3339)}
+	switch status.Budget.Scope {
+	case shared.BudgetScopeUser:
+		parts = append(parts, params.UserId)
+	case shared.BudgetScopePlan:
+		parts = append(parts, params.PlanId)
+	}
+	return strings.Join(parts, "|")
+}
@@ -0,0 +1,109 @@
+package budgets
+
+import (
+	"plandex-server/db"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestPeriodBounds(t *testing.T) {
+	now := time.Date(2025, 12, 31, 23, 30, 0, 0, time.UTC)
+
+	start, end := periodBounds(shared.BudgetPeriodDaily, now)
+	if !start.Equal(time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("daily bounds = %v - %v", start, end)
+	}
+
+	start, end = periodBounds(shared.BudgetPeriodMonthly, now)
+	if !start.Equal(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("monthly bounds = %v - %v", start, end)
+	}
+
+	// periods are in UTC regardless of the server's zone
+	local := time.Date(2025, 7, 1, 1, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+	start, _ = periodBounds(shared.BudgetPeriodDaily, local)
+	if !start.Equal(time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("daily start for %v = %v", local, start)
+	}
+}
+
+func TestWouldExceed(t *testing.T) {
+	maxTokens := int64(1000)
+	maxCost := decimal.NewFromInt(5)
+
+	tcs := []struct {
+		name   string
+		budget shared.Budget
+		spend  db.ModelSpend
+		tokens int64
+		cost   decimal.Decimal
+		want   bool
+	}{
+		{
+			name:   "under token budget",
+			budget: shared.Budget{MaxTokens: &maxTokens},
+			spend:  db.ModelSpend{InputTokens: 400, OutputTokens: 100},
+			tokens: 500,
+			want:   false,
+		},
+		{
+			name:   "request would go over token budget",
+			budget: shared.Budget{MaxTokens: &maxTokens},
+			spend:  db.ModelSpend{InputTokens: 400, OutputTokens: 100},
+			tokens: 501,
+			want:   true,
+		},
+		{
+			name:   "token budget already used up",
+			budget: shared.Budget{MaxTokens: &maxTokens},
+			spend:  db.ModelSpend{InputTokens: 1000},
+			want:   true,
+		},
+		{
+			name:   "request would go over cost budget",
+			budget: shared.Budget{MaxCost: &maxCost},
+			spend:  db.ModelSpend{Cost: decimal.RequireFromString("4.50")},
+			cost:   decimal.RequireFromString("0.51"),
+			want:   true,
+		},
+		{
+			name:   "unpriced model only counts toward tokens",
+			budget: shared.Budget{MaxTokens: &maxTokens, MaxCost: &maxCost},
+			spend:  db.ModelSpend{InputTokens: 100, Cost: decimal.RequireFromString("4.99")},
+			tokens: 100,
+			want:   false,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			status := newStatus(&tc.budget, &tc.spend, time.Time{}, time.Time{})
+			got := wouldExceed(status, tc.tokens, tc.cost)
+			if got != tc.want {
+				t.Errorf("wouldExceed() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewStatusRemaining(t *testing.T) {
+	maxTokens := int64(1000)
+	maxCost := decimal.NewFromInt(5)
+	budget := &shared.Budget{MaxTokens: &maxTokens, MaxCost: &maxCost}
+
+	status := newStatus(budget, &db.ModelSpend{InputTokens: 900, CachedTokens: 500, OutputTokens: 300, Cost: decimal.RequireFromString("1.25")}, time.Time{}, time.Time{})
+
+	if status.UsedTokens != 1200 {
+		t.Errorf("used tokens = %d, want 1200 (cached tokens are part of input)", status.UsedTokens)
+	}
+	if *status.RemainingTokens != 0 || !status.Exceeded {
+		t.Errorf("remaining tokens = %d, exceeded = %v", *status.RemainingTokens, status.Exceeded)
+	}
+	if !status.RemainingCost.Equal(decimal.RequireFromString("3.75")) {
+		t.Errorf("remaining cost = %s, want 3.75", status.RemainingCost)
+	}
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// the prefix plus the first 8 characters of the secret, stored so tokens can be identified in listings without storing the token
+const apiTokenDisplayLength = len(shared.ApiTokenPrefix) + 8
+
+type CreateApiTokenParams struct {
+	OrgId       string
+	CreatorId   string
+	ProjectId   *string
+	Name        string
+	Permissions []string
+	ExpiresAt   *time.Time
+}
+
+func CreateApiToken(params CreateApiTokenParams) (string, *ApiToken, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", nil, fmt.Errorf("error generating api token: %v", err)
+	}
+
+	token := shared.ApiTokenPrefix + hex.EncodeToString(secret)
+
+	apiToken := ApiToken{
+		OrgId:       params.OrgId,
+		CreatorId:   params.CreatorId,
+		ProjectId:   params.ProjectId,
+		Name:        params.Name,
+		TokenHash:   hashApiToken(token),
+		TokenPrefix: token[:apiTokenDisplayLength],
+		Permissions: pq.StringArray(params.Permissions),
+		ExpiresAt:   params.ExpiresAt,
+	}
+
+	if apiToken.Permissions == nil {
+		apiToken.Permissions = pq.StringArray{}
+	}
+
+	err = Conn.QueryRow(
+		"INSERT INTO api_tokens (org_id, creator_id, project_id, name, token_hash, token_prefix, permissions, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at, updated_at",
+		apiToken.OrgId, apiToken.CreatorId, apiToken.ProjectId, apiToken.Name, apiToken.TokenHash, apiToken.TokenPrefix, apiToken.Permissions, apiToken.ExpiresAt,
+	).Scan(&apiToken.Id, &apiToken.CreatedAt, &apiToken.UpdatedAt)
+
+	if err != nil {
+		return "", nil, fmt.Errorf("error creating api token: %v", err)
+	}
+
+	return token, &apiToken, nil
+}
+
+func ValidateApiToken(token string) (*ApiToken, error) {
+	var apiToken ApiToken
+	err := Conn.Get(&apiToken, "SELECT * FROM api_tokens WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())", hashApiToken(token))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Println("api token error - no rows found")
+			return nil, errors.New("invalid token")
+		}
+
+		return nil, fmt.Errorf("error validating api token: %v", err)
+	}
+
+	return &apiToken, nil
+}
+
+// TouchApiToken records that a token was used. It only writes once a minute per token to avoid an update on every request.
+func TouchApiToken(id string) error {
+	_, err := Conn.Exec("UPDATE api_tokens SET last_used_at = NOW() WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')", id)
+
+	if err != nil {
+		return fmt.Errorf("error updating api token last used: %v", err)
+	}
+
+	return nil
+}
+
+// ListApiTokens lists active tokens in an org. If creatorId is set, only that user's tokens are included.
+func ListApiTokens(orgId, creatorId string) ([]*ApiToken, error) {
+	var apiTokens []*ApiToken
+	var err error
+
+	if creatorId == "" {
+		err = Conn.Select(&apiTokens, "SELECT * FROM api_tokens WHERE org_id = $1 AND revoked_at IS NULL ORDER BY created_at DESC", orgId)
+	} else {
+		err = Conn.Select(&apiTokens, "SELECT * FROM api_tokens WHERE org_id = $1 AND creator_id = $2 AND revoked_at IS NULL ORDER BY created_at DESC", orgId, creatorId)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing api tokens: %v", err)
+	}
+
+	return apiTokens, nil
+}
+
+func GetApiToken(orgId, id string) (*ApiToken, error) {
+	var apiToken ApiToken
+	err := Conn.Get(&apiToken, "SELECT * FROM api_tokens WHERE org_id = $1 AND id = $2", orgId, id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting api token: %v", err)
+	}
+
+	return &apiToken, nil
+}
+
+func RevokeApiToken(orgId, id string) error {
+	_, err := Conn.Exec("UPDATE api_tokens SET revoked_at = NOW() WHERE org_id = $1 AND id = $2 AND revoked_at IS NULL", orgId, id)
+
+	if err != nil {
+		return fmt.Errorf("error revoking api token: %v", err)
+	}
+
+	return nil
+}
+
+func hashApiToken(token string) string {
+	hashBytes := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hashBytes[:])
+}
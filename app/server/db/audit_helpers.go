@@ -0,0 +1,104 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// CreateAuditLogEntry appends an entry to the audit log. Pass a tx to record the entry atomically with the change it describes.
+func CreateAuditLogEntry(entry *AuditLogEntry, tx *sqlx.Tx) error {
+	var data *string
+	if len(entry.Data) > 0 {
+		s := string(entry.Data)
+		data = &s
+	}
+
+	query := "INSERT INTO audit_log_entries (org_id, user_id, user_email, user_name, api_token_id, action, plan_id, branch, target_id, data, ip_address) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, created_at"
+	args := []interface{}{entry.OrgId, entry.UserId, entry.UserEmail, entry.UserName, entry.ApiTokenId, entry.Action, entry.PlanId, entry.Branch, entry.TargetId, data, entry.IpAddress}
+
+	var err error
+	if tx == nil {
+		err = Conn.QueryRow(query, args...).Scan(&entry.Id, &entry.CreatedAt)
+	} else {
+		err = tx.QueryRow(query, args...).Scan(&entry.Id, &entry.CreatedAt)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error creating audit log entry: %v", err)
+	}
+
+	return nil
+}
+
+type ListAuditLogEntriesParams struct {
+	OrgId     string
+	UserId    string
+	UserEmail string
+	Action    shared.AuditAction
+	PlanId    string
+	Since     *time.Time
+	Until     *time.Time
+	Limit     int
+}
+
+func ListAuditLogEntries(params ListAuditLogEntriesParams) ([]*AuditLogEntry, error) {
+	query := "SELECT * FROM audit_log_entries WHERE org_id = ?"
+	args := []interface{}{params.OrgId}
+
+	if params.UserId != "" {
+		query += " AND user_id = ?"
+		args = append(args, params.UserId)
+	}
+
+	if params.UserEmail != "" {
+		query += " AND LOWER(user_email) = LOWER(?)"
+		args = append(args, params.UserEmail)
+	}
+
+	// an action without a dot, like 'plan', matches every action in that category
+	if params.Action != "" {
+		if strings.Contains(string(params.Action), ".") {
+			query += " AND action = ?"
+			args = append(args, params.Action)
+		} else {
+			query += " AND action LIKE ?"
+			args = append(args, string(params.Action)+".%")
+		}
+	}
+
+	if params.PlanId != "" {
+		query += " AND plan_id = ?"
+		args = append(args, params.PlanId)
+	}
+
+	if params.Since != nil {
+		query += " AND created_at >= ?"
+		args = append(args, *params.Since)
+	}
+
+	if params.Until != nil {
+		query += " AND created_at < ?"
+		args = append(args, *params.Until)
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	var entries []*AuditLogEntry
+	err := Conn.Select(&entries, sqlx.Rebind(sqlx.DOLLAR, query), args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing audit log entries: %v", err)
+	}
+
+	return entries, nil
+}
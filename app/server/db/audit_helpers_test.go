@@ -0,0 +1,50 @@
+package db
+
+import (
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+)
+
+func TestAuditLogIsAppendOnly(t *testing.T) {
+	connectTestDb(t)
+
+	orgId := uuid.New().String()
+	entry := &AuditLogEntry{
+		OrgId:     orgId,
+		UserId:    uuid.New().String(),
+		UserEmail: "audit-test@example.com",
+		UserName:  "Audit Test",
+		Action:    shared.AuditActionPlanDeleted,
+	}
+
+	err := CreateAuditLogEntry(entry, nil)
+	if err != nil {
+		t.Fatalf("error creating entry: %v", err)
+	}
+
+	statements := map[string]string{
+		"update":   "UPDATE audit_log_entries SET user_name = 'changed' WHERE id = '" + entry.Id + "'",
+		"delete":   "DELETE FROM audit_log_entries WHERE id = '" + entry.Id + "'",
+		"truncate": "TRUNCATE audit_log_entries",
+	}
+
+	for name, statement := range statements {
+		_, err := Conn.Exec(statement)
+		if err == nil || !strings.Contains(err.Error(), "append-only") {
+			t.Errorf("%s: expected append-only error, got %v", name, err)
+		}
+	}
+
+	entries, err := ListAuditLogEntries(ListAuditLogEntriesParams{OrgId: orgId})
+	if err != nil {
+		t.Fatalf("error listing entries: %v", err)
+	}
+
+	if len(entries) != 1 || entries[0].Id != entry.Id || entries[0].UserName != entry.UserName {
+		t.Fatalf("entry was changed: %+v", entries)
+	}
+}
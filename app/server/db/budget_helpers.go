@@ -0,0 +1,177 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+)
+
+const budgetSelect = "SELECT b.*, u.email AS user_email, p.name AS plan_name FROM budgets b LEFT JOIN users u ON u.id = b.user_id LEFT JOIN plans p ON p.id = b.plan_id"
+
+func ListBudgets(orgId string) ([]*Budget, error) {
+	var budgets []*Budget
+	err := Conn.Select(&budgets, budgetSelect+" WHERE b.org_id = $1 ORDER BY b.scope, b.period, b.created_at", orgId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing budgets: %v", err)
+	}
+
+	return budgets, nil
+}
+
+// ListBudgetsForRequest returns the org's budgets that apply to a model request from a user on a plan.
+// planId can be empty to skip plan budgets.
+func ListBudgetsForRequest(orgId, userId, planId string) ([]*Budget, error) {
+	query := budgetSelect + ` WHERE b.org_id = $1 AND (
+		b.scope = 'org' OR
+		(b.scope = 'user' AND (b.user_id IS NULL OR b.user_id::text = $2)) OR
+		($3 != '' AND b.scope = 'plan' AND (b.plan_id IS NULL OR b.plan_id::text = $3))
+	) ORDER BY b.scope, b.period, b.created_at`
+
+	var budgets []*Budget
+	err := Conn.Select(&budgets, query, orgId, userId, planId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing budgets for request: %v", err)
+	}
+
+	return budgets, nil
+}
+
+func GetBudget(orgId, budgetId string) (*Budget, error) {
+	var budget Budget
+	err := Conn.Get(&budget, budgetSelect+" WHERE b.org_id = $1 AND b.id = $2", orgId, budgetId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting budget: %v", err)
+	}
+
+	return &budget, nil
+}
+
+// UpsertBudget creates a budget, or replaces the limits and action of the budget with the same scope, user or plan, and period
+func UpsertBudget(budget *Budget, tx *sqlx.Tx) error {
+	query := `INSERT INTO budgets (org_id, scope, user_id, plan_id, period, max_tokens, max_cost, action)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (org_id, scope, (COALESCE(user_id::text, '')), (COALESCE(plan_id::text, '')), period)
+DO UPDATE SET
+    max_tokens = EXCLUDED.max_tokens,
+    max_cost   = EXCLUDED.max_cost,
+    action     = EXCLUDED.action
+RETURNING id, created_at, updated_at`
+	args := []interface{}{budget.OrgId, budget.Scope, budget.UserId, budget.PlanId, budget.Period, budget.MaxTokens, budget.MaxCost, budget.Action}
+
+	var err error
+	if tx == nil {
+		err = Conn.QueryRow(query, args...).Scan(&budget.Id, &budget.CreatedAt, &budget.UpdatedAt)
+	} else {
+		err = tx.QueryRow(query, args...).Scan(&budget.Id, &budget.CreatedAt, &budget.UpdatedAt)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error upserting budget: %v", err)
+	}
+
+	return nil
+}
+
+func DeleteBudget(orgId, budgetId string, tx *sqlx.Tx) error {
+	query := "DELETE FROM budgets WHERE org_id = $1 AND id = $2"
+
+	var err error
+	if tx == nil {
+		_, err = Conn.Exec(query, orgId, budgetId)
+	} else {
+		_, err = tx.Exec(query, orgId, budgetId)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error deleting budget: %v", err)
+	}
+
+	return nil
+}
+
+type RecordModelSpendParams struct {
+	OrgId        string
+	UserId       string
+	PlanId       string
+	InputTokens  int
+	CachedTokens int
+	OutputTokens int
+	Cost         decimal.Decimal
+}
+
+// spend on model requests that aren't part of a plan is recorded under the nil uuid, so it counts toward org and user budgets but never matches a plan
+const noPlanSpendId = "00000000-0000-0000-0000-000000000000"
+
+// RecordModelSpend adds a model request's usage to the current UTC day's totals for the user and plan. PlanId can be empty.
+func RecordModelSpend(params RecordModelSpendParams) error {
+	planId := params.PlanId
+	if planId == "" {
+		planId = noPlanSpendId
+	}
+
+	query := `INSERT INTO model_spend_daily (org_id, user_id, plan_id, day, input_tokens, cached_tokens, output_tokens, cost)
+VALUES ($1, $2, $3, (NOW() AT TIME ZONE 'UTC')::date, $4, $5, $6, $7)
+ON CONFLICT (org_id, user_id, plan_id, day)
+DO UPDATE SET
+    input_tokens  = model_spend_daily.input_tokens + EXCLUDED.input_tokens,
+    cached_tokens = model_spend_daily.cached_tokens + EXCLUDED.cached_tokens,
+    output_tokens = model_spend_daily.output_tokens + EXCLUDED.output_tokens,
+    cost          = model_spend_daily.cost + EXCLUDED.cost`
+
+	_, err := Conn.Exec(query, params.OrgId, params.UserId, planId, params.InputTokens, params.CachedTokens, params.OutputTokens, params.Cost)
+
+	if err != nil {
+		return fmt.Errorf("error recording model spend: %v", err)
+	}
+
+	return nil
+}
+
+type GetModelSpendParams struct {
+	OrgId string
+
+	// optional -- empty means spend by every user or on every plan
+	UserId string
+	PlanId string
+
+	// the first UTC day to include
+	Since time.Time
+}
+
+func GetModelSpend(params GetModelSpendParams) (*ModelSpend, error) {
+	query := `SELECT
+    COALESCE(SUM(input_tokens), 0) AS input_tokens,
+    COALESCE(SUM(cached_tokens), 0) AS cached_tokens,
+    COALESCE(SUM(output_tokens), 0) AS output_tokens,
+    COALESCE(SUM(cost), 0) AS cost
+FROM model_spend_daily WHERE org_id = ? AND day >= ?`
+	args := []interface{}{params.OrgId, params.Since.UTC().Format("2006-01-02")}
+
+	if params.UserId != "" {
+		query += " AND user_id = ?"
+		args = append(args, params.UserId)
+	}
+
+	if params.PlanId != "" {
+		query += " AND plan_id = ?"
+		args = append(args, params.PlanId)
+	}
+
+	var spend ModelSpend
+	err := Conn.Get(&spend, sqlx.Rebind(sqlx.DOLLAR, query), args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting model spend: %v", err)
+	}
+
+	return &spend, nil
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestRecordModelSpendWithoutPlan(t *testing.T) {
+	connectTestDb(t)
+
+	org, user := createTestOrg(t)
+	planId := uuid.New().String()
+
+	for _, id := range []string{planId, ""} {
+		err := RecordModelSpend(RecordModelSpendParams{
+			OrgId:        org.Id,
+			UserId:       user.Id,
+			PlanId:       id,
+			InputTokens:  100,
+			OutputTokens: 10,
+			Cost:         decimal.NewFromFloat(0.5),
+		})
+		if err != nil {
+			t.Fatalf("error recording spend for plan %q: %v", id, err)
+		}
+	}
+
+	since := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		params GetModelSpendParams
+		tokens int64
+	}{
+		{"org", GetModelSpendParams{OrgId: org.Id, Since: since}, 220},
+		{"user", GetModelSpendParams{OrgId: org.Id, UserId: user.Id, Since: since}, 220},
+		{"plan", GetModelSpendParams{OrgId: org.Id, PlanId: planId, Since: since}, 110},
+	}
+
+	for _, tt := range tests {
+		spend, err := GetModelSpend(tt.params)
+		if err != nil {
+			t.Fatalf("%s: error getting spend: %v", tt.name, err)
+		}
+		if spend.TotalTokens() != tt.tokens {
+			t.Errorf("%s: got %d tokens, want %d", tt.name, spend.TotalTokens(), tt.tokens)
+		}
+	}
+}
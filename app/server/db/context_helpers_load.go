@@ -317,6 +317,8 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 					ContextType:     loadParams.ContextType,
 					Name:            loadParams.Name,
 					Url:             loadParams.Url,
+					UrlETag:         loadParams.UrlETag,
+					UrlLastModified: loadParams.UrlLastModified,
 					FilePath:        loadParams.FilePath,
 					NumTokens:       numTokensByTempId[tempId],
 					Sha:             sha,
@@ -133,6 +133,10 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 	numUrls := 0
 	numTrees := 0
 	numMaps := 0
+	numSymbols := 0
+	numGitDiffs := 0
+	numCommands := 0
+	numDocuments := 0
 
 	var mu sync.Mutex
 	errCh := make(chan error, len(*req))
@@ -204,6 +208,14 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 				numTrees++
 			case shared.ContextMapType:
 				numMaps++
+			case shared.ContextSymbolType:
+				numSymbols++
+			case shared.ContextGitDiffType:
+				numGitDiffs++
+			case shared.ContextCommandType:
+				numCommands++
+			case shared.ContextDocumentType:
+				numDocuments++
 			}
 
 			errCh <- nil
@@ -329,6 +341,10 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 				context.Body = params.Body
 				hash := sha256.Sum256([]byte(context.Body))
 				context.Sha = hex.EncodeToString(hash[:])
+				if context.ContextType == shared.ContextURLType {
+					context.UrlETag = params.UrlETag
+					context.UrlLastModified = params.UrlLastModified
+				}
 			}
 
 			// log.Println("storing context", id)
@@ -375,6 +391,10 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 		NumUrls:         numUrls,
 		NumTrees:        numTrees,
 		NumMaps:         numMaps,
+		NumSymbols:      numSymbols,
+		NumGitDiffs:     numGitDiffs,
+		NumCommands:     numCommands,
+		NumDocuments:    numDocuments,
 		MaxTokens:       plannerMaxTokens,
 	}
 
@@ -384,12 +404,16 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 	}
 
 	commitMsg := shared.SummaryForUpdateContext(shared.SummaryForUpdateContextParams{
-		NumFiles:    numFiles,
-		NumTrees:    numTrees,
-		NumUrls:     numUrls,
-		NumMaps:     numMaps,
-		TokensDiff:  aggregateTokensDiff,
-		TotalTokens: totalTokens,
+		NumFiles:     numFiles,
+		NumTrees:     numTrees,
+		NumUrls:      numUrls,
+		NumMaps:      numMaps,
+		NumSymbols:   numSymbols,
+		NumGitDiffs:  numGitDiffs,
+		NumCommands:  numCommands,
+		NumDocuments: numDocuments,
+		TokensDiff:   aggregateTokensDiff,
+		TotalTokens:  totalTokens,
 	}) + "\n\n" + shared.TableForContextUpdate(updateRes)
 	return &shared.LoadContextResponse{
 		TokensAdded: aggregateTokensDiff,
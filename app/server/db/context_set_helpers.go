@@ -0,0 +1,49 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+const contextSetSelect = "SELECT context_sets.*, users.email AS owner_email FROM context_sets JOIN users ON users.id = context_sets.owner_id"
+
+// UpsertContextSet saves a user's context set, replacing the entries of any set they already have with the same name in the project
+func UpsertContextSet(orgId, projectId, ownerId, name string, isShared bool, entries ContextSetEntries) error {
+	_, err := Conn.Exec(
+		"INSERT INTO context_sets (org_id, project_id, owner_id, name, is_shared, entries) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (project_id, owner_id, name) DO UPDATE SET is_shared = EXCLUDED.is_shared, entries = EXCLUDED.entries",
+		orgId, projectId, ownerId, name, isShared, entries,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error saving context set: %v", err)
+	}
+
+	return nil
+}
+
+// ListContextSets lists the user's own context sets in a project along with the ones other members have shared
+func ListContextSets(projectId, userId string) ([]*ContextSet, error) {
+	var sets []*ContextSet
+	err := Conn.Select(&sets, contextSetSelect+" WHERE context_sets.project_id = $1 AND (context_sets.owner_id = $2 OR context_sets.is_shared) ORDER BY context_sets.name, context_sets.updated_at DESC", projectId, userId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing context sets: %v", err)
+	}
+
+	return sets, nil
+}
+
+// GetContextSet gets a context set by name. The user's own set takes precedence over shared ones, then the most recently updated shared set.
+func GetContextSet(projectId, userId, name string) (*ContextSet, error) {
+	var set ContextSet
+	err := Conn.Get(&set, contextSetSelect+" WHERE context_sets.project_id = $1 AND context_sets.name = $3 AND (context_sets.owner_id = $2 OR context_sets.is_shared) ORDER BY (context_sets.owner_id = $2) DESC, context_sets.updated_at DESC LIMIT 1", projectId, userId, name)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting context set: %v", err)
+	}
+
+	return &set, nil
+}
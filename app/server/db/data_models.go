@@ -8,7 +8,9 @@ import (
 
 	shared "plandex-shared"
 
+	"github.com/lib/pq"
 	"github.com/sashabaranov/go-openai"
+	"github.com/shopspring/decimal"
 )
 
 // The models below should only be used server-side.
@@ -24,6 +26,126 @@ type AuthToken struct {
 	DeletedAt *time.Time `db:"deleted_at"`
 }
 
+type ApiToken struct {
+	Id          string         `db:"id"`
+	OrgId       string         `db:"org_id"`
+	CreatorId   string         `db:"creator_id"`
+	ProjectId   *string        `db:"project_id"`
+	Name        string         `db:"name"`
+	TokenHash   string         `db:"token_hash"`
+	TokenPrefix string         `db:"token_prefix"`
+	Permissions pq.StringArray `db:"permissions"`
+	ExpiresAt   *time.Time     `db:"expires_at"`
+	LastUsedAt  *time.Time     `db:"last_used_at"`
+	RevokedAt   *time.Time     `db:"revoked_at"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+}
+
+func (token *ApiToken) ToApi() *shared.ApiToken {
+	permissions := []shared.Permission{}
+	for _, permission := range token.Permissions {
+		permissions = append(permissions, shared.Permission(permission))
+	}
+
+	return &shared.ApiToken{
+		Id:          token.Id,
+		CreatorId:   token.CreatorId,
+		ProjectId:   token.ProjectId,
+		Name:        token.Name,
+		Prefix:      token.TokenPrefix,
+		Permissions: permissions,
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		RevokedAt:   token.RevokedAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
+
+type PlanShare struct {
+	Id         string               `db:"id"`
+	OrgId      string               `db:"org_id"`
+	PlanId     string               `db:"plan_id"`
+	UserId     string               `db:"user_id"`
+	Role       shared.PlanShareRole `db:"role"`
+	SharedById *string              `db:"shared_by_id"`
+	CreatedAt  time.Time            `db:"created_at"`
+	UpdatedAt  time.Time            `db:"updated_at"`
+
+	// joined from users
+	UserEmail string `db:"user_email"`
+	UserName  string `db:"user_name"`
+}
+
+func (share *PlanShare) ToApi() *shared.PlanShare {
+	var sharedById string
+	if share.SharedById != nil {
+		sharedById = *share.SharedById
+	}
+
+	return &shared.PlanShare{
+		Id:         share.Id,
+		PlanId:     share.PlanId,
+		UserId:     share.UserId,
+		UserEmail:  share.UserEmail,
+		UserName:   share.UserName,
+		Role:       share.Role,
+		SharedById: sharedById,
+		CreatedAt:  share.CreatedAt,
+		UpdatedAt:  share.UpdatedAt,
+	}
+}
+
+type ContextSetEntries []*shared.ContextSetEntry
+
+func (e *ContextSetEntries) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, e)
+	case string:
+		return json.Unmarshal([]byte(s), e)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (e ContextSetEntries) Value() (driver.Value, error) {
+	return json.Marshal(e)
+}
+
+type ContextSet struct {
+	Id        string            `db:"id"`
+	OrgId     string            `db:"org_id"`
+	ProjectId string            `db:"project_id"`
+	OwnerId   string            `db:"owner_id"`
+	Name      string            `db:"name"`
+	IsShared  bool              `db:"is_shared"`
+	Entries   ContextSetEntries `db:"entries"`
+	CreatedAt time.Time         `db:"created_at"`
+	UpdatedAt time.Time         `db:"updated_at"`
+
+	// joined from users
+	OwnerEmail string `db:"owner_email"`
+}
+
+func (set *ContextSet) ToApi() *shared.ContextSet {
+	return &shared.ContextSet{
+		Id:         set.Id,
+		ProjectId:  set.ProjectId,
+		OwnerId:    set.OwnerId,
+		OwnerEmail: set.OwnerEmail,
+		Name:       set.Name,
+		IsShared:   set.IsShared,
+		Entries:    set.Entries,
+		CreatedAt:  set.CreatedAt,
+		UpdatedAt:  set.UpdatedAt,
+	}
+}
+
 type Org struct {
 	Id                 string  `db:"id"`
 	Name               string  `db:"name"`
@@ -253,6 +375,7 @@ type OrgRole struct {
 func (role *OrgRole) ToApi() *shared.OrgRole {
 	return &shared.OrgRole{
 		Id:          role.Id,
+		Name:        role.Name,
 		IsDefault:   role.OrgId == nil,
 		Label:       role.Label,
 		Description: role.Description,
@@ -379,6 +502,11 @@ type CustomModel struct {
 	// for anthropic, token estimate padding percentage
 	TokenEstimatePaddingPct float64 `db:"token_estimate_padding_pct"`
 
+	// list prices in USD per million tokens, used for budgets
+	InputCostPerMillion       float64 `db:"input_cost_per_million"`
+	CachedInputCostPerMillion float64 `db:"cached_input_cost_per_million"`
+	OutputCostPerMillion      float64 `db:"output_cost_per_million"`
+
 	Providers CustomModelProviders `db:"providers"`
 
 	CreatedAt time.Time `db:"created_at"`
@@ -416,6 +544,9 @@ func CustomModelFromApi(apiModel *shared.CustomModel) *CustomModel {
 		SupportsCacheControl:        apiModel.SupportsCacheControl,
 		SingleMessageNoSystemPrompt: apiModel.SingleMessageNoSystemPrompt,
 		TokenEstimatePaddingPct:     apiModel.TokenEstimatePaddingPct,
+		InputCostPerMillion:         apiModel.InputCostPerMillion,
+		CachedInputCostPerMillion:   apiModel.CachedInputCostPerMillion,
+		OutputCostPerMillion:        apiModel.OutputCostPerMillion,
 		Providers:                   providers,
 	}
 
@@ -449,6 +580,9 @@ func (model *CustomModel) ToApi() *shared.CustomModel {
 			SupportsCacheControl:        model.SupportsCacheControl,
 			SingleMessageNoSystemPrompt: model.SingleMessageNoSystemPrompt,
 			TokenEstimatePaddingPct:     model.TokenEstimatePaddingPct,
+			InputCostPerMillion:         model.InputCostPerMillion,
+			CachedInputCostPerMillion:   model.CachedInputCostPerMillion,
+			OutputCostPerMillion:        model.OutputCostPerMillion,
 
 			ModelCompatibility: shared.ModelCompatibility{
 				HasImageSupport: model.HasImageSupport,
@@ -570,6 +704,8 @@ type Context struct {
 	ContextType     shared.ContextType    `json:"contextType"`
 	Name            string                `json:"name"`
 	Url             string                `json:"url"`
+	UrlETag         string                `json:"urlETag,omitempty"`
+	UrlLastModified string                `json:"urlLastModified,omitempty"`
 	FilePath        string                `json:"filePath"`
 	Sha             string                `json:"sha"`
 	NumTokens       int                   `json:"numTokens"`
@@ -597,6 +733,8 @@ func (context *Context) ToMeta() *Context {
 		ContextType:     context.ContextType,
 		Name:            context.Name,
 		Url:             context.Url,
+		UrlETag:         context.UrlETag,
+		UrlLastModified: context.UrlLastModified,
 		FilePath:        context.FilePath,
 		Sha:             context.Sha,
 		NumTokens:       context.NumTokens,
@@ -619,6 +757,8 @@ func (context *Context) ToApi() *shared.Context {
 		ContextType:     context.ContextType,
 		Name:            context.Name,
 		Url:             context.Url,
+		UrlETag:         context.UrlETag,
+		UrlLastModified: context.UrlLastModified,
 		FilePath:        context.FilePath,
 		Sha:             context.Sha,
 		NumTokens:       context.NumTokens,
@@ -800,3 +940,190 @@ func (subtask *Subtask) ToApi() *shared.Subtask {
 		IsFinished:  subtask.IsFinished,
 	}
 }
+
+type WebhookDelivery struct {
+	Id             string                       `db:"id"`
+	OrgId          string                       `db:"org_id"`
+	EndpointId     string                       `db:"endpoint_id"`
+	Url            string                       `db:"url"`
+	EventId        string                       `db:"event_id"`
+	EventType      string                       `db:"event_type"`
+	PlanId         *string                      `db:"plan_id"`
+	Payload        json.RawMessage              `db:"payload"`
+	Status         shared.WebhookDeliveryStatus `db:"status"`
+	NumAttempts    int                          `db:"num_attempts"`
+	LastStatusCode *int                         `db:"last_status_code"`
+	LastError      *string                      `db:"last_error"`
+	ReplayOfId     *string                      `db:"replay_of_id"`
+	NextAttemptAt  time.Time                    `db:"next_attempt_at"`
+	LockedAt       *time.Time                   `db:"locked_at"`
+	DeliveredAt    *time.Time                   `db:"delivered_at"`
+	CreatedAt      time.Time                    `db:"created_at"`
+	UpdatedAt      time.Time                    `db:"updated_at"`
+}
+
+func (delivery *WebhookDelivery) ToApi() *shared.WebhookDelivery {
+	return &shared.WebhookDelivery{
+		Id:             delivery.Id,
+		EndpointId:     delivery.EndpointId,
+		Url:            delivery.Url,
+		EventId:        delivery.EventId,
+		EventType:      delivery.EventType,
+		PlanId:         delivery.PlanId,
+		Status:         delivery.Status,
+		NumAttempts:    delivery.NumAttempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		ReplayOfId:     delivery.ReplayOfId,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
+
+type AuditLogEntry struct {
+	Id         string             `db:"id"`
+	OrgId      string             `db:"org_id"`
+	UserId     string             `db:"user_id"`
+	UserEmail  string             `db:"user_email"`
+	UserName   string             `db:"user_name"`
+	ApiTokenId *string            `db:"api_token_id"`
+	Action     shared.AuditAction `db:"action"`
+	PlanId     *string            `db:"plan_id"`
+	Branch     *string            `db:"branch"`
+	TargetId   *string            `db:"target_id"`
+	Data       json.RawMessage    `db:"data"`
+	IpAddress  string             `db:"ip_address"`
+	CreatedAt  time.Time          `db:"created_at"`
+}
+
+func (entry *AuditLogEntry) ToApi() *shared.AuditLogEntry {
+	var data map[string]string
+	if len(entry.Data) > 0 {
+		err := json.Unmarshal(entry.Data, &data)
+		if err != nil {
+			data = map[string]string{"raw": string(entry.Data)}
+		}
+	}
+
+	return &shared.AuditLogEntry{
+		Id:         entry.Id,
+		UserId:     entry.UserId,
+		UserEmail:  entry.UserEmail,
+		UserName:   entry.UserName,
+		ApiTokenId: entry.ApiTokenId,
+		Action:     entry.Action,
+		PlanId:     entry.PlanId,
+		Branch:     entry.Branch,
+		TargetId:   entry.TargetId,
+		Data:       data,
+		IpAddress:  entry.IpAddress,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
+
+type Budget struct {
+	Id        string              `db:"id"`
+	OrgId     string              `db:"org_id"`
+	Scope     shared.BudgetScope  `db:"scope"`
+	UserId    *string             `db:"user_id"`
+	PlanId    *string             `db:"plan_id"`
+	Period    shared.BudgetPeriod `db:"period"`
+	MaxTokens *int64              `db:"max_tokens"`
+	MaxCost   *decimal.Decimal    `db:"max_cost"`
+	Action    shared.BudgetAction `db:"action"`
+	CreatedAt time.Time           `db:"created_at"`
+	UpdatedAt time.Time           `db:"updated_at"`
+
+	// joined from users and plans when listing
+	UserEmail *string `db:"user_email"`
+	PlanName  *string `db:"plan_name"`
+}
+
+func (budget *Budget) ToApi() *shared.Budget {
+	return &shared.Budget{
+		Id:        budget.Id,
+		Scope:     budget.Scope,
+		UserId:    budget.UserId,
+		UserEmail: budget.UserEmail,
+		PlanId:    budget.PlanId,
+		PlanName:  budget.PlanName,
+		Period:    budget.Period,
+		MaxTokens: budget.MaxTokens,
+		MaxCost:   budget.MaxCost,
+		Action:    budget.Action,
+		CreatedAt: budget.CreatedAt,
+		UpdatedAt: budget.UpdatedAt,
+	}
+}
+
+// ModelSpend is model usage summed over model_spend_daily rows
+type ModelSpend struct {
+	InputTokens  int64           `db:"input_tokens"`
+	CachedTokens int64           `db:"cached_tokens"`
+	OutputTokens int64           `db:"output_tokens"`
+	Cost         decimal.Decimal `db:"cost"`
+}
+
+func (spend *ModelSpend) TotalTokens() int64 {
+	return spend.InputTokens + spend.OutputTokens
+}
+
+type ModelRequest struct {
+	Id              string               `db:"id"`
+	OrgId           string               `db:"org_id"`
+	UserId          string               `db:"user_id"`
+	PlanId          *string              `db:"plan_id"`
+	SessionId       *string              `db:"session_id"`
+	ModelId         shared.ModelId       `db:"model_id"`
+	ModelName       shared.ModelName     `db:"model_name"`
+	ModelProvider   shared.ModelProvider `db:"model_provider"`
+	ModelRole       shared.ModelRole     `db:"model_role"`
+	ModelPackName   string               `db:"model_pack_name"`
+	Purpose         string               `db:"purpose"`
+	GenerationId    *string              `db:"generation_id"`
+	InputTokens     int                  `db:"input_tokens"`
+	CachedTokens    int                  `db:"cached_tokens"`
+	OutputTokens    int                  `db:"output_tokens"`
+	Cost            decimal.Decimal      `db:"cost"`
+	Streaming       bool                 `db:"streaming"`
+	StoppedEarly    bool                 `db:"stopped_early"`
+	UserCancelled   bool                 `db:"user_cancelled"`
+	HadError        bool                 `db:"had_error"`
+	NoReportedUsage bool                 `db:"no_reported_usage"`
+	LatencyMs       *int                 `db:"latency_ms"`
+	FirstTokenMs    *int                 `db:"first_token_ms"`
+	CreatedAt       time.Time            `db:"created_at"`
+}
+
+// UsageRow is model_requests aggregated by a usage report's grouping
+type UsageRow struct {
+	Key             string          `db:"key"`
+	Label           *string         `db:"label"`
+	Requests        int64           `db:"requests"`
+	InputTokens     int64           `db:"input_tokens"`
+	CachedTokens    int64           `db:"cached_tokens"`
+	OutputTokens    int64           `db:"output_tokens"`
+	Cost            decimal.Decimal `db:"cost"`
+	Errors          int64           `db:"errors"`
+	AvgLatencyMs    int64           `db:"avg_latency_ms"`
+	AvgFirstTokenMs int64           `db:"avg_first_token_ms"`
+}
+
+func (row *UsageRow) ToApi() *shared.UsageReportRow {
+	res := &shared.UsageReportRow{
+		Key:             row.Key,
+		Requests:        row.Requests,
+		InputTokens:     row.InputTokens,
+		CachedTokens:    row.CachedTokens,
+		OutputTokens:    row.OutputTokens,
+		Cost:            row.Cost,
+		Errors:          row.Errors,
+		AvgLatencyMs:    row.AvgLatencyMs,
+		AvgFirstTokenMs: row.AvgFirstTokenMs,
+	}
+	if row.Label != nil {
+		res.Label = *row.Label
+	}
+	return res
+}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var testDbOnce sync.Once
+var testDbErr error
+
+// connectTestDb connects to the database in TEST_DATABASE_URL and runs the migrations. Tests that need a database are skipped if it isn't set. Use a throwaway database, since rows that tests create aren't cleaned up.
+func connectTestDb(t *testing.T) {
+	t.Helper()
+
+	dbUrl := os.Getenv("TEST_DATABASE_URL")
+	if dbUrl == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	testDbOnce.Do(func() {
+		os.Setenv("DATABASE_URL", dbUrl)
+		testDbErr = Connect()
+		if testDbErr != nil {
+			return
+		}
+		testDbErr = MigrationsUpWithDir("../migrations")
+	})
+
+	if testDbErr != nil {
+		t.Fatalf("error setting up test database: %v", testDbErr)
+	}
+}
+
+// createTestOrg creates a user and an org they own, with unique names so tests can share a database
+func createTestOrg(t *testing.T) (*Org, *User) {
+	t.Helper()
+
+	var org *Org
+	var user *User
+	err := WithTx(context.Background(), "create test org", func(tx *sqlx.Tx) error {
+		var err error
+		id := uuid.New().String()
+
+		user, err = CreateUser("Test User", "test-"+id+"@example.com", tx)
+		if err != nil {
+			return err
+		}
+
+		org, err = CreateOrg(&shared.CreateOrgRequest{Name: "test-" + id}, user.Id, nil, tx)
+		return err
+	})
+
+	if err != nil {
+		t.Fatalf("error creating test org: %v", err)
+	}
+
+	return org, user
+}
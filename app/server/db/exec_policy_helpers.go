@@ -0,0 +1,49 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// GetOrgExecPolicy returns the org's exec policy, or nil if it doesn't have one
+func GetOrgExecPolicy(orgId string) (*shared.ExecPolicy, error) {
+	var policy shared.ExecPolicy
+	err := Conn.Get(&policy, "SELECT policy FROM org_exec_policies WHERE org_id = $1", orgId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting org exec policy: %v", err)
+	}
+
+	return &policy, nil
+}
+
+func UpsertOrgExecPolicy(orgId string, policy *shared.ExecPolicy, tx *sqlx.Tx) error {
+	query := `INSERT INTO org_exec_policies (org_id, policy)
+VALUES ($1, $2)
+ON CONFLICT (org_id) DO UPDATE SET policy = EXCLUDED.policy`
+
+	_, err := tx.Exec(query, orgId, policy)
+
+	if err != nil {
+		return fmt.Errorf("error upserting org exec policy: %v", err)
+	}
+
+	return nil
+}
+
+func DeleteOrgExecPolicy(orgId string, tx *sqlx.Tx) error {
+	_, err := tx.Exec("DELETE FROM org_exec_policies WHERE org_id = $1", orgId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting org exec policy: %v", err)
+	}
+
+	return nil
+}
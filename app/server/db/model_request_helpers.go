@@ -0,0 +1,108 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func CreateModelRequest(req *ModelRequest) error {
+	query := `INSERT INTO model_requests (org_id, user_id, plan_id, session_id, model_id, model_name, model_provider, model_role, model_pack_name, purpose, generation_id, input_tokens, cached_tokens, output_tokens, cost, streaming, stopped_early, user_cancelled, had_error, no_reported_usage, latency_ms, first_token_ms)
+VALUES (:org_id, :user_id, :plan_id, :session_id, :model_id, :model_name, :model_provider, :model_role, :model_pack_name, :purpose, :generation_id, :input_tokens, :cached_tokens, :output_tokens, :cost, :streaming, :stopped_early, :user_cancelled, :had_error, :no_reported_usage, :latency_ms, :first_token_ms)`
+
+	_, err := Conn.NamedExec(query, req)
+
+	if err != nil {
+		return fmt.Errorf("error creating model request: %v", err)
+	}
+
+	return nil
+}
+
+type GetUsageReportParams struct {
+	OrgId string
+	By    shared.UsageGroupBy
+
+	// optional filters
+	UserId    string
+	PlanId    string
+	SessionId string
+
+	Since time.Time
+	Until time.Time
+}
+
+// usageGroupExprs are the key and label expressions for each usage report grouping
+var usageGroupExprs = map[shared.UsageGroupBy][2]string{
+	shared.UsageGroupByPlan:  {"COALESCE(mr.plan_id::text, '')", "MAX(p.name)"},
+	shared.UsageGroupByUser:  {"mr.user_id::text", "MAX(u.email)"},
+	shared.UsageGroupByRole:  {"mr.model_role", "NULL::text"},
+	shared.UsageGroupByModel: {"mr.model_id", "NULL::text"},
+	shared.UsageGroupByDay:   {"TO_CHAR(DATE(mr.created_at), 'YYYY-MM-DD')", "NULL::text"},
+}
+
+// GetUsageReport sums model requests in a time range, grouped by plan, user, model role, model, or day.
+// Pass an empty By to get a single row with the totals.
+func GetUsageReport(params GetUsageReportParams) ([]*UsageRow, error) {
+	keyExpr, labelExpr := "''", "NULL::text"
+	if params.By != "" {
+		exprs, ok := usageGroupExprs[params.By]
+		if !ok {
+			return nil, fmt.Errorf("invalid usage grouping: %s", params.By)
+		}
+		keyExpr, labelExpr = exprs[0], exprs[1]
+	}
+
+	query := fmt.Sprintf(`SELECT
+    %s AS key,
+    %s AS label,
+    COUNT(*) AS requests,
+    COALESCE(SUM(mr.input_tokens), 0) AS input_tokens,
+    COALESCE(SUM(mr.cached_tokens), 0) AS cached_tokens,
+    COALESCE(SUM(mr.output_tokens), 0) AS output_tokens,
+    COALESCE(SUM(mr.cost), 0) AS cost,
+    COUNT(*) FILTER (WHERE mr.had_error) AS errors,
+    COALESCE(AVG(mr.latency_ms), 0)::bigint AS avg_latency_ms,
+    COALESCE(AVG(mr.first_token_ms), 0)::bigint AS avg_first_token_ms
+FROM model_requests mr
+LEFT JOIN plans p ON p.id = mr.plan_id
+LEFT JOIN users u ON u.id = mr.user_id
+WHERE mr.org_id = ? AND mr.created_at >= ? AND mr.created_at < ?`, keyExpr, labelExpr)
+	args := []interface{}{params.OrgId, params.Since, params.Until}
+
+	if params.UserId != "" {
+		query += " AND mr.user_id = ?"
+		args = append(args, params.UserId)
+	}
+
+	if params.PlanId != "" {
+		query += " AND mr.plan_id = ?"
+		args = append(args, params.PlanId)
+	}
+
+	if params.SessionId != "" {
+		query += " AND mr.session_id = ?"
+		args = append(args, params.SessionId)
+	}
+
+	if params.By != "" {
+		query += " GROUP BY 1"
+		if params.By == shared.UsageGroupByDay {
+			query += " ORDER BY 1"
+		} else {
+			query += " ORDER BY cost DESC, input_tokens + output_tokens DESC"
+		}
+	}
+
+	var rows []*UsageRow
+	err := Conn.Select(&rows, sqlx.Rebind(sqlx.DOLLAR, query), args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting usage report: %v", err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestUsageReport(t *testing.T) {
+	connectTestDb(t)
+
+	org, user := createTestOrg(t)
+	planId := uuid.New().String()
+
+	reqs := []*ModelRequest{
+		{ModelId: "model-a", ModelRole: shared.ModelRolePlanner, InputTokens: 100, OutputTokens: 10, Cost: decimal.NewFromInt(3), PlanId: &planId},
+		{ModelId: "model-a", ModelRole: shared.ModelRoleBuilder, InputTokens: 200, OutputTokens: 20, Cost: decimal.NewFromInt(2), PlanId: &planId, HadError: true},
+		{ModelId: "model-b", ModelRole: shared.ModelRoleName, InputTokens: 50, OutputTokens: 5, Cost: decimal.NewFromInt(1)},
+	}
+	for _, req := range reqs {
+		req.OrgId = org.Id
+		req.UserId = user.Id
+		req.ModelName = shared.ModelName(req.ModelId)
+		if err := CreateModelRequest(req); err != nil {
+			t.Fatalf("error creating model request: %v", err)
+		}
+	}
+
+	since := time.Now().Add(-time.Hour)
+	until := time.Now().Add(time.Hour)
+
+	totals, err := GetUsageReport(GetUsageReportParams{OrgId: org.Id, Since: since, Until: until})
+	if err != nil {
+		t.Fatalf("error getting totals: %v", err)
+	}
+	if len(totals) != 1 || totals[0].Requests != 3 || totals[0].InputTokens != 350 || totals[0].Errors != 1 || !totals[0].Cost.Equal(decimal.NewFromInt(6)) {
+		t.Fatalf("unexpected totals: %+v", totals[0])
+	}
+
+	byModel, err := GetUsageReport(GetUsageReportParams{OrgId: org.Id, By: shared.UsageGroupByModel, Since: since, Until: until})
+	if err != nil {
+		t.Fatalf("error getting report by model: %v", err)
+	}
+	// ordered by cost
+	if len(byModel) != 2 || byModel[0].Key != "model-a" || byModel[0].Requests != 2 || byModel[1].Key != "model-b" {
+		t.Fatalf("unexpected report by model: %+v", byModel)
+	}
+
+	// requests without a plan are grouped under an empty key
+	byPlan, err := GetUsageReport(GetUsageReportParams{OrgId: org.Id, By: shared.UsageGroupByPlan, Since: since, Until: until})
+	if err != nil {
+		t.Fatalf("error getting report by plan: %v", err)
+	}
+	keys := map[string]int64{}
+	for _, row := range byPlan {
+		keys[row.Key] = row.Requests
+	}
+	if len(keys) != 2 || keys[planId] != 2 || keys[""] != 1 {
+		t.Fatalf("unexpected report by plan: %v", keys)
+	}
+
+	forPlan, err := GetUsageReport(GetUsageReportParams{OrgId: org.Id, PlanId: planId, Since: since, Until: until})
+	if err != nil {
+		t.Fatalf("error getting plan totals: %v", err)
+	}
+	if len(forPlan) != 1 || forPlan[0].Requests != 2 {
+		t.Fatalf("unexpected plan totals: %+v", forPlan)
+	}
+
+	_, err = GetUsageReport(GetUsageReportParams{OrgId: org.Id, By: "nope", Since: since, Until: until})
+	if err == nil {
+		t.Fatal("expected an error for an invalid grouping")
+	}
+}
@@ -22,6 +22,7 @@ INSERT INTO custom_models (
     predicted_output_enabled, reasoning_effort_enabled, reasoning_effort,
     include_reasoning, reasoning_budget, supports_cache_control,
     single_message_no_system_prompt, token_estimate_padding_pct,
+    input_cost_per_million, cached_input_cost_per_million, output_cost_per_million,
     providers
 )
 VALUES (
@@ -33,7 +34,8 @@ VALUES (
     $14,$15,$16,
     $17,$18,$19,
     $20,$21,
-    $22
+    $22,$23,$24,
+    $25
 )
 ON CONFLICT (org_id, model_id)
 DO UPDATE SET
@@ -56,6 +58,9 @@ DO UPDATE SET
     supports_cache_control        = EXCLUDED.supports_cache_control,
     single_message_no_system_prompt = EXCLUDED.single_message_no_system_prompt,
     token_estimate_padding_pct    = EXCLUDED.token_estimate_padding_pct,
+    input_cost_per_million        = EXCLUDED.input_cost_per_million,
+    cached_input_cost_per_million = EXCLUDED.cached_input_cost_per_million,
+    output_cost_per_million       = EXCLUDED.output_cost_per_million,
     providers                     = EXCLUDED.providers
 RETURNING id, created_at, updated_at;
 `
@@ -83,6 +88,9 @@ RETURNING id, created_at, updated_at;
 		model.SupportsCacheControl,
 		model.SingleMessageNoSystemPrompt,
 		model.TokenEstimatePaddingPct,
+		model.InputCostPerMillion,
+		model.CachedInputCostPerMillion,
+		model.OutputCostPerMillion,
 		model.Providers,
 	).Scan(&model.Id, &model.CreatedAt, &model.UpdatedAt)
 }
@@ -187,6 +187,12 @@ func DeleteOrgUser(orgId, userId string, tx *sqlx.Tx) error {
 		return fmt.Errorf("error deleting org member: %v", err)
 	}
 
+	_, err = tx.Exec("DELETE FROM plan_shares WHERE org_id = $1 AND user_id = $2", orgId, userId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting org member's plan shares: %v", err)
+	}
+
 	return nil
 }
 
@@ -94,8 +94,9 @@ func CreatePlan(ctx context.Context, orgId, projectId, userId, name string) (*Pl
 	return plan, nil
 }
 
+// ListOwnedPlans lists plans the user owns or that have been shared with them
 func ListOwnedPlans(projectIds []string, userId string, archived bool) ([]*Plan, error) {
-	qs := "SELECT * FROM plans WHERE project_id = ANY($1) AND owner_id = $2"
+	qs := "SELECT * FROM plans WHERE project_id = ANY($1) AND (owner_id = $2 OR id IN (SELECT plan_id FROM plan_shares WHERE user_id = $2))"
 	qargs := []interface{}{pq.Array(projectIds), userId}
 
 	if archived {
@@ -445,45 +446,6 @@ func DeleteOwnerPlans(orgId, projectId, userId string) error {
 	return nil
 }
 
-func ValidatePlanAccess(planId, userId, orgId string) (*Plan, error) {
-	// get plan
-	plan, err := GetPlan(planId)
-
-	if err != nil {
-		return nil, fmt.Errorf("error getting plan: %v", err)
-	}
-
-	if plan == nil {
-		return nil, nil
-	}
-
-	if plan.OrgId != orgId {
-		return nil, nil
-	}
-
-	hasProjectAccess, err := ProjectExists(orgId, plan.ProjectId)
-
-	if err != nil {
-		return nil, fmt.Errorf("error validating project membership: %v", err)
-	}
-
-	if !hasProjectAccess {
-		return nil, nil
-	}
-
-	// owner has access
-	if plan.OwnerId == userId {
-		return plan, nil
-	}
-
-	// plan is shared with org
-	if plan.SharedWithOrgAt != nil {
-		return plan, nil
-	}
-
-	return nil, nil
-}
-
 func BumpPlanUpdatedAt(planId string, t time.Time) error {
 	_, err := Conn.Exec("UPDATE plans SET updated_at = $1 WHERE id = $2", t, planId)
 
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	shared "plandex-shared"
+)
+
+const planShareSelect = "SELECT plan_shares.*, users.email AS user_email, users.name AS user_name FROM plan_shares JOIN users ON users.id = plan_shares.user_id"
+
+// UpsertPlanShare shares a plan with a user, or changes the role of an existing share
+func UpsertPlanShare(orgId, planId, userId, sharedById string, role shared.PlanShareRole) error {
+	_, err := Conn.Exec(
+		"INSERT INTO plan_shares (org_id, plan_id, user_id, role, shared_by_id) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (plan_id, user_id) DO UPDATE SET role = EXCLUDED.role, shared_by_id = EXCLUDED.shared_by_id",
+		orgId, planId, userId, role, sharedById,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error sharing plan: %v", err)
+	}
+
+	return nil
+}
+
+func ListPlanShares(planId string) ([]*PlanShare, error) {
+	var shares []*PlanShare
+	err := Conn.Select(&shares, planShareSelect+" WHERE plan_shares.plan_id = $1 ORDER BY plan_shares.created_at", planId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing plan shares: %v", err)
+	}
+
+	return shares, nil
+}
+
+func GetPlanShare(planId, userId string) (*PlanShare, error) {
+	var share PlanShare
+	err := Conn.Get(&share, planShareSelect+" WHERE plan_shares.plan_id = $1 AND plan_shares.user_id = $2", planId, userId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting plan share: %v", err)
+	}
+
+	return &share, nil
+}
+
+func DeletePlanShare(planId, userId string) error {
+	_, err := Conn.Exec("DELETE FROM plan_shares WHERE plan_id = $1 AND user_id = $2", planId, userId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting plan share: %v", err)
+	}
+
+	return nil
+}
+
+// GetPlanAccess returns the plan along with the user's level of access to it. The plan is nil if the user has no access.
+func GetPlanAccess(planId, userId, orgId string) (*Plan, shared.PlanShareRole, error) {
+	// get plan
+	plan, err := GetPlan(planId)
+
+	if err != nil {
+		return nil, "", fmt.Errorf("error getting plan: %v", err)
+	}
+
+	if plan == nil {
+		return nil, "", nil
+	}
+
+	if plan.OrgId != orgId {
+		return nil, "", nil
+	}
+
+	hasProjectAccess, err := ProjectExists(orgId, plan.ProjectId)
+
+	if err != nil {
+		return nil, "", fmt.Errorf("error validating project membership: %v", err)
+	}
+
+	if !hasProjectAccess {
+		return nil, "", nil
+	}
+
+	// owner has full access
+	if plan.OwnerId == userId {
+		return plan, shared.PlanShareRoleOwner, nil
+	}
+
+	share, err := GetPlanShare(planId, userId)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if share != nil {
+		return plan, share.Role, nil
+	}
+
+	// plans shared with the whole org can be viewed by any member -- working on them takes an explicit share
+	if plan.SharedWithOrgAt != nil {
+		return plan, shared.PlanShareRoleViewer, nil
+	}
+
+	return nil, "", nil
+}
@@ -1,8 +1,14 @@
 package db
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 var orgOwnerRoleId string
@@ -74,7 +80,7 @@ func CacheOrgRoleIds() error {
 
 func cacheOrgOwnerRoleId() error {
 	var roleId string
-	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE name = 'owner'")
+	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'owner'")
 
 	if err != nil {
 		return fmt.Errorf("error getting owner role id: %v", err)
@@ -87,7 +93,7 @@ func cacheOrgOwnerRoleId() error {
 
 func cacheOrgMemberRoleId() error {
 	var roleId string
-	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE name = 'member'")
+	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'member'")
 
 	if err != nil {
 		return fmt.Errorf("error getting member role id: %v", err)
@@ -97,3 +103,158 @@ func cacheOrgMemberRoleId() error {
 
 	return nil
 }
+
+// GetOrgRole returns a built-in role or a custom role that belongs to the org. It returns nil if the role doesn't exist or belongs to a different org.
+func GetOrgRole(orgId, roleId string) (*OrgRole, error) {
+	var role OrgRole
+	err := Conn.Get(&role, "SELECT * FROM org_roles WHERE id = $1 AND (org_id IS NULL OR org_id = $2)", roleId, orgId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting org role: %v", err)
+	}
+
+	return &role, nil
+}
+
+func GetOrgRoleByName(orgId, name string) (*OrgRole, error) {
+	var role OrgRole
+	err := Conn.Get(&role, "SELECT * FROM org_roles WHERE name = $1 AND (org_id IS NULL OR org_id = $2)", name, orgId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting org role: %v", err)
+	}
+
+	return &role, nil
+}
+
+// GetOrgRolePermissions returns the permission names for each role. Permissions that are scoped to other roles are only included once.
+func GetOrgRolePermissions(roleIds []string) (map[string][]shared.Permission, error) {
+	var rows []struct {
+		OrgRoleId string `db:"org_role_id"`
+		Name      string `db:"name"`
+	}
+
+	err := Conn.Select(&rows, "SELECT DISTINCT orp.org_role_id, p.name FROM org_roles_permissions orp JOIN permissions p ON p.id = orp.permission_id WHERE orp.org_role_id = ANY($1) ORDER BY p.name", pq.Array(roleIds))
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting org role permissions: %v", err)
+	}
+
+	res := map[string][]shared.Permission{}
+	for _, row := range rows {
+		res[row.OrgRoleId] = append(res[row.OrgRoleId], shared.Permission(row.Name))
+	}
+
+	return res, nil
+}
+
+func CreateOrgRole(orgId, name, description string, permissions []shared.Permission, tx *sqlx.Tx) (*OrgRole, error) {
+	var role OrgRole
+	err := tx.Get(&role, "INSERT INTO org_roles (org_id, name, label, description) VALUES ($1, $2, $3, $4) RETURNING *", orgId, name, name, description)
+
+	if err != nil {
+		return nil, fmt.Errorf("error creating org role: %v", err)
+	}
+
+	err = setOrgRolePermissions(role.Id, permissions, tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+func UpdateOrgRole(orgId, roleId, name, description string, permissions []shared.Permission, tx *sqlx.Tx) error {
+	_, err := tx.Exec("UPDATE org_roles SET name = $1, label = $1, description = $2 WHERE id = $3 AND org_id = $4", name, description, roleId, orgId)
+
+	if err != nil {
+		return fmt.Errorf("error updating org role: %v", err)
+	}
+
+	return setOrgRolePermissions(roleId, permissions, tx)
+}
+
+// DeleteOrgRole deletes a custom role. Accepted invites that used the role are moved to the member role, since they still reference it.
+func DeleteOrgRole(orgId, roleId string, tx *sqlx.Tx) error {
+	memberRoleId, err := GetOrgMemberRoleId()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE invites SET org_role_id = $1 WHERE org_id = $2 AND org_role_id = $3 AND accepted_at IS NOT NULL", memberRoleId, orgId, roleId)
+
+	if err != nil {
+		return fmt.Errorf("error updating accepted invites: %v", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM org_roles WHERE id = $1 AND org_id = $2", roleId, orgId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting org role: %v", err)
+	}
+
+	return nil
+}
+
+func SetOrgUserRole(orgId, userId, roleId string) error {
+	_, err := Conn.Exec("UPDATE orgs_users SET org_role_id = $1 WHERE org_id = $2 AND user_id = $3", roleId, orgId, userId)
+
+	if err != nil {
+		return fmt.Errorf("error setting org user role: %v", err)
+	}
+
+	return nil
+}
+
+func NumPendingInvitesWithRole(orgId, roleId string) (int, error) {
+	var count int
+	err := Conn.Get(&count, "SELECT COUNT(*) FROM invites WHERE org_id = $1 AND org_role_id = $2 AND accepted_at IS NULL", orgId, roleId)
+
+	if err != nil {
+		return 0, fmt.Errorf("error counting pending invites with role: %v", err)
+	}
+
+	return count, nil
+}
+
+// setOrgRolePermissions replaces a custom role's permissions. Permissions scoped to an org role are granted for the member role, which custom roles are treated like.
+func setOrgRolePermissions(roleId string, permissions []shared.Permission, tx *sqlx.Tx) error {
+	memberRoleId, err := GetOrgMemberRoleId()
+	if err != nil {
+		return err
+	}
+
+	var unscoped []string
+	var scoped []string
+	for _, permission := range permissions {
+		if permission.IsOrgRoleScoped() {
+			scoped = append(scoped, string(permission))
+		} else {
+			unscoped = append(unscoped, string(permission))
+		}
+	}
+
+	_, err = tx.Exec("DELETE FROM org_roles_permissions WHERE org_role_id = $1", roleId)
+
+	if err != nil {
+		return fmt.Errorf("error clearing org role permissions: %v", err)
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO org_roles_permissions (org_role_id, permission_id) SELECT $1, id FROM permissions WHERE (name = ANY($2) AND resource_id IS NULL) OR (name = ANY($3) AND resource_id = $4)",
+		roleId, pq.Array(unscoped), pq.Array(scoped), memberRoleId,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error setting org role permissions: %v", err)
+	}
+
+	return nil
+}
@@ -221,7 +221,7 @@ func GetCurrentPlanState(params CurrentPlanStateParams) (*shared.CurrentPlanStat
 		}
 
 		for _, context := range contexts {
-			if context.FilePath != "" {
+			if context.FilePath != "" && context.ContextType != shared.ContextSymbolType {
 				contextsByPath[context.FilePath] = context
 			}
 		}
@@ -479,7 +479,7 @@ func GetPlanResult(planFileResults []*shared.PlanFileResult) *shared.PlanResult
 
 	for _, results := range resByPath {
 		for _, planRes := range results {
-			replacementsByPath[planRes.Path] = append(replacementsByPath[planRes.Path], planRes.Replacements...)
+			replacementsByPath[planRes.Path] = append(replacementsByPath[planRes.Path], planRes.PendingReplacements()...)
 		}
 	}
 
@@ -524,7 +524,7 @@ func ApplyPlan(repo *GitRepo, ctx context.Context, params ApplyPlanParams) error
 
 	contextsByPath := make(map[string]*Context)
 	for _, context := range contexts {
-		if context.FilePath != "" {
+		if context.FilePath != "" && context.ContextType != shared.ContextSymbolType {
 			contextsByPath[context.FilePath] = context
 		}
 	}
@@ -988,42 +988,96 @@ func RejectPlanFile(orgId, planId, filePathOrResultId string, now time.Time) err
 	return nil
 }
 
-func RejectReplacement(orgId, planId, resultId, replacementId string) error {
+// ReviewReplacements rejects or edits individual pending replacements, returning the paths of the files they're in. The results are only written if every pending file still builds afterward, since a later change to a file can depend on an earlier one.
+func ReviewReplacements(orgId, planId string, rejectedIds []string, editedById map[string]string, now time.Time) ([]string, error) {
 	resultsDir := getPlanResultsDir(orgId, planId)
 
-	bytes, err := os.ReadFile(filepath.Join(resultsDir, resultId+".json"))
-
+	results, err := GetPlanFileResults(orgId, planId)
 	if err != nil {
-		return fmt.Errorf("error reading result file: %v", err)
+		return nil, fmt.Errorf("error getting plan file results: %v", err)
 	}
 
-	var result PlanFileResult
-	err = json.Unmarshal(bytes, &result)
+	rejected := map[string]bool{}
+	for _, id := range rejectedIds {
+		rejected[id] = true
+	}
 
-	if err != nil {
-		return fmt.Errorf("error unmarshalling result file: %v", err)
+	found := map[string]bool{}
+	updated := map[string]*PlanFileResult{}
+
+	for _, result := range results {
+		if result.AppliedAt != nil || result.RejectedAt != nil {
+			continue
+		}
+
+		for _, replacement := range result.Replacements {
+			if replacement.RejectedAt != nil {
+				continue
+			}
+
+			if rejected[replacement.Id] {
+				replacement.SetRejected(now)
+				found[replacement.Id] = true
+				updated[result.Id] = result
+			} else if newText, ok := editedById[replacement.Id]; ok {
+				if result.ReplaceWithLineNums {
+					return nil, fmt.Errorf("changes to %s are from an older version and can't be edited", result.Path)
+				}
+				replacement.New = newText
+				found[replacement.Id] = true
+				updated[result.Id] = result
+			}
+		}
+
+		if updated[result.Id] != nil {
+			result.UpdatedAt = now
+		}
 	}
 
-	if result.RejectedAt != nil {
-		return nil
+	for id := range rejected {
+		if !found[id] {
+			return nil, fmt.Errorf("pending change not found: %s", id)
+		}
+	}
+	for id := range editedById {
+		if !found[id] {
+			return nil, fmt.Errorf("pending change not found: %s", id)
+		}
 	}
 
-	now := time.Now()
+	_, err = GetCurrentPlanState(CurrentPlanStateParams{
+		OrgId:           orgId,
+		PlanId:          planId,
+		PlanFileResults: results,
+	})
+	if err != nil {
+		log.Printf("ReviewReplacements - error getting plan state with updates: %v\n", err)
+		return nil, fmt.Errorf("later pending changes to the same file depend on the changes being rejected or edited -- reject the whole file instead")
+	}
+
+	for _, result := range updated {
+		bytes, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling result: %v", err)
+		}
 
-	foundReplacement := false
-	for _, replacement := range result.Replacements {
-		if replacement.Id == replacementId {
-			replacement.RejectedAt = &now
-			foundReplacement = true
-			break
+		err = os.WriteFile(filepath.Join(resultsDir, result.Id+".json"), bytes, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("error writing result file: %v", err)
 		}
 	}
 
-	if !foundReplacement {
-		return fmt.Errorf("replacement not found: %s", replacementId)
+	pathsSet := map[string]bool{}
+	for _, result := range updated {
+		pathsSet[result.Path] = true
+	}
+	var paths []string
+	for path := range pathsSet {
+		paths = append(paths, path)
 	}
+	sort.Strings(paths)
 
-	return nil
+	return paths, nil
 }
 
 func GetPlanApplies(orgId, planId string) ([]*PlanApply, error) {
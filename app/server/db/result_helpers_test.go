@@ -0,0 +1,200 @@
+package db
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+)
+
+// setUpReviewTestPlan writes a plan with a context file for main.go and two pending results for it, where the second result's change depends on the first's, and a result for old.go from an older version with line numbers.
+func setUpReviewTestPlan(t *testing.T) (string, string) {
+	t.Helper()
+
+	origBaseDir := BaseDir
+	BaseDir = t.TempDir()
+	t.Cleanup(func() { BaseDir = origBaseDir })
+
+	orgId, planId := "org", "plan"
+	contextDir := getPlanContextDir(orgId, planId)
+	resultsDir := getPlanResultsDir(orgId, planId)
+	for _, dir := range []string{contextDir, resultsDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	contexts := map[string]string{
+		"main.go": "a\nb\nc\n",
+		"old.go":  "x\ny\n",
+	}
+	for path, body := range contexts {
+		id := strings.TrimSuffix(path, ".go")
+		meta, err := json.Marshal(&Context{Id: id, ContextType: shared.ContextFileType, FilePath: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(contextDir, id+".meta"), meta, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(contextDir, id+".body"), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Now().Add(-time.Hour)
+	results := []*PlanFileResult{
+		{
+			Id: "res1", Path: "main.go", CreatedAt: start,
+			Replacements: []*shared.Replacement{{Id: "rep1", Old: "a", New: "A"}},
+		},
+		{
+			Id: "res2", Path: "main.go", CreatedAt: start.Add(time.Minute),
+			Replacements: []*shared.Replacement{{Id: "rep2", Old: "A\nb", New: "A\nB"}},
+		},
+		{
+			Id: "res3", Path: "old.go", CreatedAt: start.Add(2 * time.Minute), ReplaceWithLineNums: true,
+			Replacements: []*shared.Replacement{{Id: "rep3", Old: "pdx-1: x", New: "pdx-1: X"}},
+		},
+	}
+	for _, result := range results {
+		bytes, err := json.Marshal(result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(resultsDir, result.Id+".json"), bytes, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return orgId, planId
+}
+
+func readReviewTestResults(t *testing.T, orgId, planId string) map[string]string {
+	t.Helper()
+
+	res := map[string]string{}
+	for _, id := range []string{"res1", "res2", "res3"} {
+		bytes, err := os.ReadFile(filepath.Join(getPlanResultsDir(orgId, planId), id+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res[id] = string(bytes)
+	}
+	return res
+}
+
+func TestReviewReplacements(t *testing.T) {
+	tests := []struct {
+		name        string
+		rejectedIds []string
+		editedById  map[string]string
+		wantErr     string
+		wantPaths   []string
+		check       func(t *testing.T, results map[string]*PlanFileResult)
+	}{
+		{
+			name:        "rejects a replacement nothing depends on",
+			rejectedIds: []string{"rep2"},
+			wantPaths:   []string{"main.go"},
+			check: func(t *testing.T, results map[string]*PlanFileResult) {
+				if results["res2"].Replacements[0].RejectedAt == nil {
+					t.Error("rep2 wasn't rejected")
+				}
+				if results["res1"].Replacements[0].RejectedAt != nil {
+					t.Error("rep1 was rejected")
+				}
+			},
+		},
+		{
+			name:       "edits a replacement",
+			editedById: map[string]string{"rep2": "A\nbee"},
+			wantPaths:  []string{"main.go"},
+			check: func(t *testing.T, results map[string]*PlanFileResult) {
+				if got := results["res2"].Replacements[0].New; got != "A\nbee" {
+					t.Errorf("rep2 new text = %q", got)
+				}
+			},
+		},
+		{
+			name:        "refuses to reject a replacement a later one depends on",
+			rejectedIds: []string{"rep1"},
+			wantErr:     "depend on the changes being rejected or edited",
+		},
+		{
+			name:       "refuses an edit a later replacement depends on",
+			editedById: map[string]string{"rep1": "Z"},
+			wantErr:    "depend on the changes being rejected or edited",
+		},
+		{
+			name:        "unknown id",
+			rejectedIds: []string{"rep2", "missing"},
+			wantErr:     "pending change not found: missing",
+		},
+		{
+			name:       "unknown edited id",
+			editedById: map[string]string{"missing": "x"},
+			wantErr:    "pending change not found: missing",
+		},
+		{
+			name:       "refuses to edit a replacement with line numbers",
+			editedById: map[string]string{"rep3": "pdx-1: z"},
+			wantErr:    "can't be edited",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgId, planId := setUpReviewTestPlan(t)
+			before := readReviewTestResults(t, orgId, planId)
+
+			paths, err := ReviewReplacements(orgId, planId, tt.rejectedIds, tt.editedById, time.Now())
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				after := readReviewTestResults(t, orgId, planId)
+				for id, content := range before {
+					if after[id] != content {
+						t.Errorf("%s was written after an error", id)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("paths = %v, want %v", paths, tt.wantPaths)
+			}
+
+			results, err := GetPlanFileResults(orgId, planId)
+			if err != nil {
+				t.Fatal(err)
+			}
+			byId := map[string]*PlanFileResult{}
+			for _, result := range results {
+				byId[result.Id] = result
+			}
+			tt.check(t, byId)
+		})
+	}
+
+	t.Run("already rejected id", func(t *testing.T) {
+		orgId, planId := setUpReviewTestPlan(t)
+
+		if _, err := ReviewReplacements(orgId, planId, []string{"rep2"}, nil, time.Now()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err := ReviewReplacements(orgId, planId, []string{"rep2"}, nil, time.Now())
+		if err == nil || !strings.Contains(err.Error(), "pending change not found: rep2") {
+			t.Fatalf("got error %v, want pending change not found", err)
+		}
+	})
+}
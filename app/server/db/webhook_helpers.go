@@ -0,0 +1,165 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func CreateWebhookDeliveries(deliveries []*WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	for _, delivery := range deliveries {
+		err := Conn.QueryRow(
+			"INSERT INTO webhook_deliveries (org_id, endpoint_id, url, event_id, event_type, plan_id, payload, replay_of_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, status, next_attempt_at, created_at, updated_at",
+			delivery.OrgId, delivery.EndpointId, delivery.Url, delivery.EventId, delivery.EventType, delivery.PlanId, string(delivery.Payload), delivery.ReplayOfId,
+		).Scan(&delivery.Id, &delivery.Status, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.UpdatedAt)
+
+		if err != nil {
+			return fmt.Errorf("error creating webhook delivery: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// ClaimWebhookDeliveries marks up to 'limit' due deliveries as in progress and returns them.
+// SKIP LOCKED allows multiple server instances to share the work without double-sending.
+func ClaimWebhookDeliveries(limit int) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+
+	err := Conn.Select(&deliveries, `
+		UPDATE webhook_deliveries
+		SET status = $1, locked_at = NOW()
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $2 AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		shared.WebhookDeliveryStatusInProgress, shared.WebhookDeliveryStatusPending, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("error claiming webhook deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+// ReleaseStaleWebhookDeliveries puts deliveries that were claimed by a server that went away back in the queue.
+func ReleaseStaleWebhookDeliveries(olderThan time.Duration) error {
+	_, err := Conn.Exec(`
+		UPDATE webhook_deliveries
+		SET status = $1, locked_at = NULL
+		WHERE status = $2 AND locked_at < $3`,
+		shared.WebhookDeliveryStatusPending, shared.WebhookDeliveryStatusInProgress, time.Now().Add(-olderThan))
+
+	if err != nil {
+		return fmt.Errorf("error releasing stale webhook deliveries: %v", err)
+	}
+
+	return nil
+}
+
+func MarkWebhookDelivered(id string, statusCode int) error {
+	_, err := Conn.Exec(`
+		UPDATE webhook_deliveries
+		SET status = $1, num_attempts = num_attempts + 1, last_status_code = $2, last_error = NULL, locked_at = NULL, delivered_at = NOW()
+		WHERE id = $3`,
+		shared.WebhookDeliveryStatusDelivered, statusCode, id)
+
+	if err != nil {
+		return fmt.Errorf("error marking webhook delivered: %v", err)
+	}
+
+	return nil
+}
+
+// MarkWebhookAttemptFailed records a failed attempt. If nextAttemptAt is nil, the delivery is marked as permanently failed.
+func MarkWebhookAttemptFailed(id string, statusCode *int, errMsg string, nextAttemptAt *time.Time) error {
+	status := shared.WebhookDeliveryStatusFailed
+	next := time.Now()
+	if nextAttemptAt != nil {
+		status = shared.WebhookDeliveryStatusPending
+		next = *nextAttemptAt
+	}
+
+	_, err := Conn.Exec(`
+		UPDATE webhook_deliveries
+		SET status = $1, num_attempts = num_attempts + 1, last_status_code = $2, last_error = $3, locked_at = NULL, next_attempt_at = $4
+		WHERE id = $5`,
+		status, statusCode, errMsg, next, id)
+
+	if err != nil {
+		return fmt.Errorf("error marking webhook attempt failed: %v", err)
+	}
+
+	return nil
+}
+
+type ListWebhookDeliveriesParams struct {
+	OrgId     string
+	PlanId    string
+	EventType string
+	Status    shared.WebhookDeliveryStatus
+	Limit     int
+}
+
+func ListWebhookDeliveries(params ListWebhookDeliveriesParams) ([]*WebhookDelivery, error) {
+	query := "SELECT * FROM webhook_deliveries WHERE org_id = ?"
+	args := []interface{}{params.OrgId}
+
+	if params.PlanId != "" {
+		query += " AND plan_id = ?"
+		args = append(args, params.PlanId)
+	}
+
+	if params.EventType != "" {
+		query += " AND event_type = ?"
+		args = append(args, params.EventType)
+	}
+
+	if params.Status != "" {
+		query += " AND status = ?"
+		args = append(args, params.Status)
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	var deliveries []*WebhookDelivery
+	err := Conn.Select(&deliveries, sqlx.Rebind(sqlx.DOLLAR, query), args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhook deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+func GetWebhookDelivery(orgId, id string) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	err := Conn.Get(&delivery, "SELECT * FROM webhook_deliveries WHERE org_id = $1 AND id = $2", orgId, id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting webhook delivery: %v", err)
+	}
+
+	return &delivery, nil
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func CreateApiTokenHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreateApiTokenHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	// tokens can't be used to create more tokens
+	if !requireSessionAuth(w, auth) {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error reading request body: %v\n", err)
+		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		return
+	}
+	defer r.Body.Close()
+
+	var req shared.CreateApiTokenRequest
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Name == "" {
+		log.Println("Received empty name field")
+		http.Error(w, "name field is required", http.StatusBadRequest)
+		return
+	}
+
+	var projectId *string
+	if req.ProjectId != "" {
+		if !authorizeProject(w, req.ProjectId, auth) {
+			return
+		}
+		projectId = &req.ProjectId
+	}
+
+	permissions := []string{}
+	for _, permission := range req.Permissions {
+		if !auth.HasPermission(permission) {
+			log.Printf("User does not have permission %s\n", permission)
+			http.Error(w, "Can't grant a permission you don't have: "+string(permission), http.StatusForbidden)
+			return
+		}
+		permissions = append(permissions, string(permission))
+	}
+
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		log.Println("Expiration is in the past")
+		http.Error(w, "expiresAt must be in the future", http.StatusBadRequest)
+		return
+	}
+
+	token, apiToken, err := db.CreateApiToken(db.CreateApiTokenParams{
+		OrgId:       auth.OrgId,
+		CreatorId:   auth.User.Id,
+		ProjectId:   projectId,
+		Name:        req.Name,
+		Permissions: permissions,
+		ExpiresAt:   req.ExpiresAt,
+	})
+
+	if err != nil {
+		log.Printf("Error creating api token: %v\n", err)
+		http.Error(w, "Error creating api token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:   shared.AuditActionApiTokenCreated,
+		targetId: apiToken.Id,
+		data:     map[string]string{"name": apiToken.Name, "permissions": strings.Join(permissions, ", ")},
+	})
+
+	bytes, err := json.Marshal(shared.CreateApiTokenResponse{
+		Token:    token,
+		ApiToken: apiToken.ToApi(),
+	})
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully created api token", apiToken.Id)
+}
+
+func ListApiTokensHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListApiTokensHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !requireSessionAuth(w, auth) {
+		return
+	}
+
+	// users see their own tokens unless they can manage every token in the org
+	creatorId := auth.User.Id
+	if auth.HasPermission(shared.PermissionManageApiTokens) {
+		creatorId = ""
+	}
+
+	apiTokens, err := db.ListApiTokens(auth.OrgId, creatorId)
+
+	if err != nil {
+		log.Printf("Error listing api tokens: %v\n", err)
+		http.Error(w, "Error listing api tokens: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiApiTokens := []*shared.ApiToken{}
+	for _, apiToken := range apiTokens {
+		apiApiTokens = append(apiApiTokens, apiToken.ToApi())
+	}
+
+	bytes, err := json.Marshal(apiApiTokens)
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for ListApiTokensHandler")
+}
+
+func RevokeApiTokenHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for RevokeApiTokenHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !requireSessionAuth(w, auth) {
+		return
+	}
+
+	vars := mux.Vars(r)
+	tokenId := vars["tokenId"]
+
+	log.Println("tokenId: ", tokenId)
+
+	apiToken, err := db.GetApiToken(auth.OrgId, tokenId)
+
+	if err != nil {
+		log.Printf("Error getting api token: %v\n", err)
+		http.Error(w, "Error getting api token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if apiToken == nil {
+		log.Println("Api token not found")
+		http.Error(w, "API token not found", http.StatusNotFound)
+		return
+	}
+
+	if apiToken.CreatorId != auth.User.Id && !auth.HasPermission(shared.PermissionManageApiTokens) {
+		log.Println("User does not have permission to revoke api token")
+		http.Error(w, "User does not have permission to revoke API token", http.StatusForbidden)
+		return
+	}
+
+	err = db.RevokeApiToken(auth.OrgId, tokenId)
+
+	if err != nil {
+		log.Printf("Error revoking api token: %v\n", err)
+		http.Error(w, "Error revoking api token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:   shared.AuditActionApiTokenRevoked,
+		targetId: tokenId,
+		data:     map[string]string{"name": apiToken.Name},
+	})
+
+	log.Println("Successfully revoked api token", tokenId)
+}
+
+// GetApiTokenSessionHandler returns the user and org that an API token acts as, so clients that only have a token can set up their session
+func GetApiTokenSessionHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetApiTokenSessionHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if auth.ApiToken == nil {
+		log.Println("Request isn't authenticated with an api token")
+		http.Error(w, "Request isn't authenticated with an API token", http.StatusBadRequest)
+		return
+	}
+
+	org, apiErr := getApiOrg(auth.OrgId)
+
+	if apiErr != nil {
+		log.Printf("Error converting org to api: %v\n", apiErr)
+		writeApiError(w, *apiErr)
+		return
+	}
+
+	org = withoutBillingFields(auth, org)
+
+	resp := shared.SessionResponse{
+		UserId:   auth.User.Id,
+		Email:    auth.User.Email,
+		UserName: auth.User.Name,
+		Orgs:     []*shared.Org{org},
+	}
+
+	bytes, err := json.Marshal(resp)
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for GetApiTokenSessionHandler")
+}
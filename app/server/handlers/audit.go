@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"plandex-server/db"
+	"plandex-server/notify"
+	"plandex-server/types"
+	"strconv"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type auditParams struct {
+	// defaults to the org the request is authenticated for
+	orgId    string
+	action   shared.AuditAction
+	planId   string
+	branch   string
+	targetId string
+	data     map[string]string
+}
+
+// recordAudit appends an entry to the org's audit log after an action has succeeded.
+// The action has already happened at this point, so a failure is reported rather than returned to the client.
+func recordAudit(r *http.Request, auth *types.ServerAuth, params auditParams) {
+	err := recordAuditTx(r, auth, params, nil)
+	if err != nil {
+		log.Printf("Error recording audit log entry for %s: %v\n", params.action, err)
+		go notify.NotifyErr(notify.SeverityError, fmt.Errorf("error recording audit log entry for %s: %v", params.action, err))
+	}
+}
+
+// recordAuditTx appends an entry to the org's audit log in the same transaction as the change it describes
+func recordAuditTx(r *http.Request, auth *types.ServerAuth, params auditParams, tx *sqlx.Tx) error {
+	entry := &db.AuditLogEntry{
+		OrgId:     params.orgId,
+		UserId:    auth.User.Id,
+		UserEmail: auth.User.Email,
+		UserName:  auth.User.Name,
+		Action:    params.action,
+		IpAddress: requestIp(r),
+	}
+
+	if entry.OrgId == "" {
+		entry.OrgId = auth.OrgId
+	}
+	if auth.ApiToken != nil {
+		entry.ApiTokenId = &auth.ApiToken.Id
+	}
+	if params.planId != "" {
+		entry.PlanId = &params.planId
+	}
+	if params.branch != "" {
+		entry.Branch = &params.branch
+	}
+	if params.targetId != "" {
+		entry.TargetId = &params.targetId
+	}
+
+	if len(params.data) > 0 {
+		data, err := json.Marshal(params.data)
+		if err != nil {
+			return fmt.Errorf("error marshalling audit log data: %v", err)
+		}
+		entry.Data = data
+	}
+
+	return db.CreateAuditLogEntry(entry, tx)
+}
+
+func requestIp(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func parseTimeParam(w http.ResponseWriter, query url.Values, name string) (*time.Time, bool) {
+	if query.Get(name) == "" {
+		return nil, true
+	}
+
+	t, err := time.Parse(time.RFC3339, query.Get(name))
+	if err != nil {
+		log.Printf("Invalid %s: %v\n", name, err)
+		http.Error(w, fmt.Sprintf("Invalid %s -- use RFC 3339 format, like 2025-07-01T00:00:00Z", name), http.StatusBadRequest)
+		return nil, false
+	}
+
+	return &t, true
+}
+
+func ListAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListAuditLogHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionViewAuditLog) {
+		log.Println("User does not have permission to view the audit log")
+		http.Error(w, "User does not have permission to view the audit log", http.StatusForbidden)
+		return
+	}
+
+	query := r.URL.Query()
+
+	var limit int
+	if query.Get("limit") != "" {
+		var err error
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			log.Printf("Invalid limit: %v\n", err)
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	params := db.ListAuditLogEntriesParams{
+		OrgId:     auth.OrgId,
+		UserId:    query.Get("userId"),
+		UserEmail: query.Get("userEmail"),
+		Action:    shared.AuditAction(query.Get("action")),
+		PlanId:    query.Get("planId"),
+		Limit:     limit,
+	}
+
+	var ok bool
+	params.Since, ok = parseTimeParam(w, query, "since")
+	if !ok {
+		return
+	}
+	params.Until, ok = parseTimeParam(w, query, "until")
+	if !ok {
+		return
+	}
+
+	entries, err := db.ListAuditLogEntries(params)
+
+	if err != nil {
+		log.Printf("Error listing audit log entries: %v\n", err)
+		http.Error(w, "Error listing audit log entries: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiEntries := []*shared.AuditLogEntry{}
+	for _, entry := range entries {
+		apiEntries = append(apiEntries, entry.ToApi())
+	}
+
+	bytes, err := json.Marshal(apiEntries)
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for ListAuditLogHandler")
+}
@@ -481,6 +481,10 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		return nil
 	}
 
+	if strings.HasPrefix(parsed.Token, shared.ApiTokenPrefix) {
+		return authenticateApiToken(w, r, parsed, raiseErr)
+	}
+
 	// validate the token
 	authToken, err := db.ValidateAuthToken(parsed.Token)
 
@@ -589,6 +593,104 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		Permissions: permissionsMap,
 	}
 
+	if !execAuthenticateHook(w, r, auth, parsed) {
+		return nil
+	}
+
+	log.Printf("UserId: %s, Email: %s, OrgId: %s\n", authToken.UserId, user.Email, parsed.OrgId)
+
+	return auth
+
+}
+
+// authenticateApiToken authenticates a request made with an API token. The request acts as the token's creator, limited to the token's permissions and project.
+func authenticateApiToken(w http.ResponseWriter, r *http.Request, parsed *shared.AuthHeader, raiseErr bool) *types.ServerAuth {
+	apiToken, err := db.ValidateApiToken(parsed.Token)
+
+	if err != nil {
+		log.Printf("error validating api token: %v\n", err)
+
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeInvalidToken,
+			Status: http.StatusUnauthorized,
+			Msg:    "Invalid API token",
+		})
+		return nil
+	}
+
+	// api tokens belong to a single org, so the org id in the header is optional
+	if parsed.OrgId != "" && parsed.OrgId != apiToken.OrgId {
+		log.Println("api token org id doesn't match auth header org id")
+		if raiseErr {
+			http.Error(w, "API token is for a different org", http.StatusUnauthorized)
+		}
+		return nil
+	}
+
+	user, err := db.GetUser(apiToken.CreatorId)
+
+	if err != nil {
+		log.Printf("error getting user: %v\n", err)
+		if raiseErr {
+			http.Error(w, "error getting user", http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	isMember, err := db.ValidateOrgMembership(apiToken.CreatorId, apiToken.OrgId)
+
+	if err != nil {
+		log.Printf("error validating org membership: %v\n", err)
+		if raiseErr {
+			http.Error(w, "error validating org membership", http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	if !isMember {
+		log.Println("api token creator is no longer a member of the org")
+		if raiseErr {
+			http.Error(w, "API token creator is not a member of org", http.StatusUnauthorized)
+		}
+		return nil
+	}
+
+	permissions, err := db.GetUserPermissions(apiToken.CreatorId, apiToken.OrgId)
+
+	if err != nil {
+		log.Printf("error getting user permissions: %v\n", err)
+		if raiseErr {
+			http.Error(w, "error getting user permissions", http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	permissionsMap := apiTokenPermissions(apiToken.Permissions, permissions)
+
+	err = db.TouchApiToken(apiToken.Id)
+
+	if err != nil {
+		// not worth failing the request over
+		log.Printf("error updating api token last used: %v\n", err)
+	}
+
+	auth := &types.ServerAuth{
+		ApiToken:    apiToken,
+		User:        user,
+		OrgId:       apiToken.OrgId,
+		Permissions: permissionsMap,
+	}
+
+	if !execAuthenticateHook(w, r, auth, parsed) {
+		return nil
+	}
+
+	log.Printf("ApiTokenId: %s, UserId: %s, OrgId: %s\n", apiToken.Id, apiToken.CreatorId, apiToken.OrgId)
+
+	return auth
+}
+
+func execAuthenticateHook(w http.ResponseWriter, r *http.Request, auth *types.ServerAuth, parsed *shared.AuthHeader) bool {
 	// don't send hash for org-session requests
 	var hash string
 	if r.URL.Path != "/orgs/session" {
@@ -605,13 +707,39 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 
 	if apiErr != nil {
 		writeApiError(w, *apiErr)
-		return nil
+		return false
 	}
 
-	log.Printf("UserId: %s, Email: %s, OrgId: %s\n", authToken.UserId, user.Email, parsed.OrgId)
+	return true
+}
 
-	return auth
+// apiTokenPermissions is a token's permissions that its creator still has. Token permissions are names like 'manage_plans', which match any of the creator's permissions with that name, like 'invite_user|member'.
+func apiTokenPermissions(tokenPermissions, creatorPermissions []string) shared.Permissions {
+	allowed := map[string]bool{}
+	for _, permission := range tokenPermissions {
+		allowed[permission] = true
+	}
 
+	permissionsMap := make(shared.Permissions)
+	for _, permission := range creatorPermissions {
+		name, _, _ := strings.Cut(permission, "|")
+		if allowed[name] {
+			permissionsMap[permission] = true
+		}
+	}
+
+	return permissionsMap
+}
+
+// requireSessionAuth rejects requests made with an API token, for actions that should only be done by a signed in user
+func requireSessionAuth(w http.ResponseWriter, auth *types.ServerAuth) bool {
+	if auth.ApiToken != nil {
+		log.Println("API tokens can't be used for this action")
+		http.Error(w, "API tokens can't be used for this action -- sign in instead", http.StatusForbidden)
+		return false
+	}
+
+	return true
 }
 
 func authorizeProject(w http.ResponseWriter, projectId string, auth *types.ServerAuth) bool {
@@ -635,6 +763,14 @@ func authorizeProjectOptional(w http.ResponseWriter, projectId string, auth *typ
 		return false
 	}
 
+	if projectExists && !auth.CanAccessProject(projectId) {
+		log.Println("api token is scoped to a different project")
+		if shouldErr {
+			http.Error(w, "API token is scoped to a different project", http.StatusForbidden)
+		}
+		return false
+	}
+
 	return projectExists
 }
 
@@ -667,19 +803,48 @@ func authorizeProjectDelete(w http.ResponseWriter, projectId string, auth *types
 }
 
 func authorizePlan(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
+	plan, _ := authorizePlanRole(w, planId, auth)
+	return plan
+}
+
+// authorizePlanRole checks that the user can at least view the plan and returns their level of access to it
+func authorizePlanRole(w http.ResponseWriter, planId string, auth *types.ServerAuth) (*db.Plan, shared.PlanShareRole) {
 	log.Println("authorizing plan")
 
-	plan, err := db.ValidatePlanAccess(planId, auth.User.Id, auth.OrgId)
+	plan, role, err := db.GetPlanAccess(planId, auth.User.Id, auth.OrgId)
 
 	if err != nil {
 		log.Printf("error validating plan membership: %v\n", err)
 		http.Error(w, "error validating plan membership", http.StatusInternalServerError)
-		return nil
+		return nil, ""
 	}
 
 	if plan == nil {
 		log.Println("user doesn't have access the plan")
 		http.Error(w, "no access to plan", http.StatusUnauthorized)
+		return nil, ""
+	}
+
+	if !auth.CanAccessProject(plan.ProjectId) {
+		log.Println("api token is scoped to a different project")
+		http.Error(w, "API token is scoped to a different project", http.StatusForbidden)
+		return nil, ""
+	}
+
+	return plan, role
+}
+
+// authorizePlanExec is for anything that changes a plan's state, like tell, build, apply, or context, settings and branch updates
+func authorizePlanExec(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
+	plan, role := authorizePlanRole(w, planId, auth)
+
+	if plan == nil {
+		return nil
+	}
+
+	if !role.Includes(shared.PlanShareRoleCollaborator) && !auth.HasPermission(shared.PermissionUpdateAnyPlan) {
+		log.Println("User only has view access to plan")
+		http.Error(w, "User only has view access to plan", http.StatusForbidden)
 		return nil
 	}
 
@@ -687,13 +852,13 @@ func authorizePlan(w http.ResponseWriter, planId string, auth *types.ServerAuth)
 }
 
 func authorizePlanUpdate(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
-	plan := authorizePlan(w, planId, auth)
+	plan, role := authorizePlanRole(w, planId, auth)
 
 	if plan == nil {
 		return nil
 	}
 
-	if plan.OwnerId != auth.User.Id && !auth.HasPermission(shared.PermissionUpdateAnyPlan) {
+	if role != shared.PlanShareRoleOwner && !auth.HasPermission(shared.PermissionUpdateAnyPlan) {
 		log.Println("User does not have permission to update plan")
 		http.Error(w, "User does not have permission to update plan", http.StatusForbidden)
 		return nil
@@ -703,13 +868,13 @@ func authorizePlanUpdate(w http.ResponseWriter, planId string, auth *types.Serve
 }
 
 func authorizePlanDelete(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
-	plan := authorizePlan(w, planId, auth)
+	plan, role := authorizePlanRole(w, planId, auth)
 
 	if plan == nil {
 		return nil
 	}
 
-	if plan.OwnerId != auth.User.Id && !auth.HasPermission(shared.PermissionDeleteAnyPlan) {
+	if role != shared.PlanShareRoleOwner && !auth.HasPermission(shared.PermissionDeleteAnyPlan) {
 		log.Println("User does not have permission to delete plan")
 		http.Error(w, "User does not have permission to delete plan", http.StatusForbidden)
 		return nil
@@ -719,13 +884,13 @@ func authorizePlanDelete(w http.ResponseWriter, planId string, auth *types.Serve
 }
 
 func authorizePlanRename(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
-	plan := authorizePlan(w, planId, auth)
+	plan, role := authorizePlanRole(w, planId, auth)
 
 	if plan == nil {
 		return nil
 	}
 
-	if plan.OwnerId != auth.User.Id && !auth.HasPermission(shared.PermissionRenameAnyPlan) {
+	if role != shared.PlanShareRoleOwner && !auth.HasPermission(shared.PermissionRenameAnyPlan) {
 		log.Println("User does not have permission to rename plan")
 		http.Error(w, "User does not have permission to rename plan", http.StatusForbidden)
 		return nil
@@ -735,13 +900,13 @@ func authorizePlanRename(w http.ResponseWriter, planId string, auth *types.Serve
 }
 
 func authorizePlanArchive(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
-	plan := authorizePlan(w, planId, auth)
+	plan, role := authorizePlanRole(w, planId, auth)
 
 	if plan == nil {
 		return nil
 	}
 
-	if plan.OwnerId != auth.User.Id && !auth.HasPermission(shared.PermissionArchiveAnyPlan) {
+	if role != shared.PlanShareRoleOwner && !auth.HasPermission(shared.PermissionArchiveAnyPlan) {
 		log.Println("User does not have permission to archive plan")
 		http.Error(w, "User does not have permission to archive plan", http.StatusForbidden)
 		return nil
@@ -749,3 +914,19 @@ func authorizePlanArchive(w http.ResponseWriter, planId string, auth *types.Serv
 
 	return plan
 }
+
+func authorizePlanShares(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
+	plan, role := authorizePlanRole(w, planId, auth)
+
+	if plan == nil {
+		return nil
+	}
+
+	if role != shared.PlanShareRoleOwner && !auth.HasPermission(shared.PermissionManageAnyPlanShares) {
+		log.Println("User does not have permission to manage plan shares")
+		http.Error(w, "User does not have permission to manage plan shares", http.StatusForbidden)
+		return nil
+	}
+
+	return plan
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"plandex-server/db"
+	"plandex-server/types"
+	"sort"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestApiTokenPermissions(t *testing.T) {
+	creator := []string{
+		"create_plan",
+		"invite_user|member",
+		"invite_user|admin",
+		"manage_billing",
+		"exec_commands",
+	}
+
+	tests := []struct {
+		name  string
+		token []string
+		want  []string
+	}{
+		{
+			name:  "only token permissions the creator has",
+			token: []string{"create_plan", "delete_org"},
+			want:  []string{"create_plan"},
+		},
+		{
+			name:  "a token permission matches every role scope the creator has for it",
+			token: []string{"invite_user"},
+			want:  []string{"invite_user|admin", "invite_user|member"},
+		},
+		{
+			name:  "scoped token permissions aren't matched as names",
+			token: []string{"invite_user|owner"},
+			want:  nil,
+		},
+		{
+			name:  "no token permissions",
+			token: nil,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := apiTokenPermissions(tt.token, creator)
+
+			var got []string
+			for permission, ok := range res {
+				if ok {
+					got = append(got, permission)
+				}
+			}
+			sort.Strings(got)
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// a creator who lost a permission loses it for their tokens too
+	res := apiTokenPermissions([]string{"manage_billing"}, []string{"create_plan"})
+	if res.HasPermission(shared.PermissionManageBilling) {
+		t.Fatal("token kept a permission its creator doesn't have")
+	}
+}
+
+func TestRequireSessionAuth(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if !requireSessionAuth(rec, &types.ServerAuth{AuthToken: &db.AuthToken{}}) {
+		t.Fatal("session auth was rejected")
+	}
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Fatalf("session auth wrote a response: %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	if requireSessionAuth(rec, &types.ServerAuth{ApiToken: &db.ApiToken{}}) {
+		t.Fatal("api token auth was accepted")
+	}
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
@@ -91,7 +91,7 @@ func CreateBranchHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanExec(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -171,7 +171,7 @@ func DeleteBranchHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanExec(w, planId, auth) == nil {
 		return
 	}
 
@@ -203,5 +203,11 @@ func DeleteBranchHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action: shared.AuditActionBranchDeleted,
+		planId: planId,
+		branch: branch,
+	})
+
 	log.Println("Successfully deleted branch")
 }
@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"plandex-server/budgets"
+	"plandex-server/db"
+	"plandex-server/types"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
+)
+
+func ListBudgetsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListBudgetsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !requireBudgetsEnabled(w) {
+		return
+	}
+
+	dbBudgets, err := db.ListBudgets(auth.OrgId)
+
+	if err != nil {
+		log.Printf("Error listing budgets: %v\n", err)
+		http.Error(w, "Error listing budgets: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiBudgets := []*shared.Budget{}
+	for _, budget := range dbBudgets {
+		apiBudgets = append(apiBudgets, budget.ToApi())
+	}
+
+	bytes, err := json.Marshal(apiBudgets)
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for ListBudgetsHandler")
+}
+
+func SetBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SetBudgetHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !requireBudgetsEnabled(w) || !requireManageBudgets(w, auth) {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error reading request body: %v\n", err)
+		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		return
+	}
+	defer r.Body.Close()
+
+	var req shared.SetBudgetRequest
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Action == "" {
+		req.Action = shared.BudgetActionBlock
+	}
+
+	switch {
+	case req.Scope != shared.BudgetScopeOrg && req.Scope != shared.BudgetScopeUser && req.Scope != shared.BudgetScopePlan:
+		http.Error(w, "scope must be one of: org, user, plan", http.StatusBadRequest)
+		return
+	case req.Period != shared.BudgetPeriodDaily && req.Period != shared.BudgetPeriodMonthly:
+		http.Error(w, "period must be one of: daily, monthly", http.StatusBadRequest)
+		return
+	case req.Action != shared.BudgetActionBlock && req.Action != shared.BudgetActionWarn:
+		http.Error(w, "action must be one of: block, warn", http.StatusBadRequest)
+		return
+	case req.MaxTokens == nil && req.MaxCost == nil:
+		http.Error(w, "maxTokens or maxCost is required", http.StatusBadRequest)
+		return
+	case req.MaxTokens != nil && *req.MaxTokens <= 0:
+		http.Error(w, "maxTokens must be positive", http.StatusBadRequest)
+		return
+	case req.MaxCost != nil && !req.MaxCost.IsPositive():
+		http.Error(w, "maxCost must be positive", http.StatusBadRequest)
+		return
+	case req.UserEmail != "" && req.Scope != shared.BudgetScopeUser:
+		http.Error(w, "userEmail can only be set for a user budget", http.StatusBadRequest)
+		return
+	case req.PlanId != "" && req.Scope != shared.BudgetScopePlan:
+		http.Error(w, "planId can only be set for a plan budget", http.StatusBadRequest)
+		return
+	}
+
+	budget := &db.Budget{
+		OrgId:     auth.OrgId,
+		Scope:     req.Scope,
+		Period:    req.Period,
+		MaxTokens: req.MaxTokens,
+		MaxCost:   req.MaxCost,
+		Action:    req.Action,
+	}
+
+	auditData := map[string]string{"scope": string(req.Scope), "period": string(req.Period), "action": string(req.Action)}
+
+	if req.UserEmail != "" {
+		email := strings.ToLower(strings.TrimSpace(req.UserEmail))
+		user, err := db.GetUserByEmail(email)
+
+		if err != nil {
+			log.Printf("Error getting user: %v\n", err)
+			http.Error(w, "Error getting user: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var isMember bool
+		if user != nil {
+			isMember, err = db.ValidateOrgMembership(user.Id, auth.OrgId)
+
+			if err != nil {
+				log.Printf("Error validating org membership: %v\n", err)
+				http.Error(w, "Error validating org membership: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if !isMember {
+			log.Println("User isn't a member of the org")
+			http.Error(w, "No one with that email is a member of the org", http.StatusNotFound)
+			return
+		}
+
+		budget.UserId = &user.Id
+		auditData["email"] = user.Email
+	}
+
+	if req.PlanId != "" {
+		plan, err := db.GetPlan(req.PlanId)
+
+		if err != nil {
+			log.Printf("Error getting plan: %v\n", err)
+			http.Error(w, "Error getting plan: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if plan == nil || plan.OrgId != auth.OrgId {
+			log.Println("Plan not found")
+			http.Error(w, "Plan not found", http.StatusNotFound)
+			return
+		}
+
+		budget.PlanId = &plan.Id
+	}
+
+	if req.MaxTokens != nil {
+		auditData["maxTokens"] = strconv.FormatInt(*req.MaxTokens, 10)
+	}
+	if req.MaxCost != nil {
+		auditData["maxCost"] = req.MaxCost.String()
+	}
+
+	err = db.WithTx(r.Context(), "set budget", func(tx *sqlx.Tx) error {
+		err := db.UpsertBudget(budget, tx)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditTx(r, auth, auditParams{
+			action:   shared.AuditActionBudgetSet,
+			planId:   req.PlanId,
+			targetId: budget.Id,
+			data:     auditData,
+		}, tx)
+	})
+
+	if err != nil {
+		log.Printf("Error setting budget: %v\n", err)
+		http.Error(w, "Error setting budget: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// re-fetch to include the user's email and plan's name
+	budget, err = db.GetBudget(auth.OrgId, budget.Id)
+
+	if err != nil {
+		log.Printf("Error getting budget: %v\n", err)
+		http.Error(w, "Error getting budget: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(budget.ToApi())
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully set budget", budget.Id)
+}
+
+func DeleteBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteBudgetHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !requireBudgetsEnabled(w) || !requireManageBudgets(w, auth) {
+		return
+	}
+
+	vars := mux.Vars(r)
+	budgetId := vars["budgetId"]
+
+	log.Println("budgetId: ", budgetId)
+
+	budget, err := db.GetBudget(auth.OrgId, budgetId)
+
+	if err != nil {
+		log.Printf("Error getting budget: %v\n", err)
+		http.Error(w, "Error getting budget: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if budget == nil {
+		log.Println("Budget not found")
+		http.Error(w, "Budget not found", http.StatusNotFound)
+		return
+	}
+
+	var planId string
+	if budget.PlanId != nil {
+		planId = *budget.PlanId
+	}
+
+	err = db.WithTx(r.Context(), "delete budget", func(tx *sqlx.Tx) error {
+		err := db.DeleteBudget(auth.OrgId, budgetId, tx)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditTx(r, auth, auditParams{
+			action:   shared.AuditActionBudgetDeleted,
+			planId:   planId,
+			targetId: budgetId,
+			data:     map[string]string{"scope": string(budget.Scope), "period": string(budget.Period)},
+		}, tx)
+	})
+
+	if err != nil {
+		log.Printf("Error deleting budget: %v\n", err)
+		http.Error(w, "Error deleting budget: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully deleted budget", budgetId)
+}
+
+func GetBudgetStatusHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetBudgetStatusHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !requireBudgetsEnabled(w) {
+		return
+	}
+
+	planId := r.URL.Query().Get("planId")
+	if planId != "" {
+		if authorizePlan(w, planId, auth) == nil {
+			return
+		}
+	}
+
+	statuses, err := budgets.GetStatuses(auth.OrgId, auth.User.Id, planId)
+
+	if err != nil {
+		log.Printf("Error getting budget statuses: %v\n", err)
+		http.Error(w, "Error getting budget statuses: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if statuses == nil {
+		statuses = []*shared.BudgetStatus{}
+	}
+
+	bytes, err := json.Marshal(statuses)
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for GetBudgetStatusHandler")
+}
+
+func requireBudgetsEnabled(w http.ResponseWriter) bool {
+	if !budgets.Enabled() {
+		log.Println("Budgets aren't available on Plandex Cloud")
+		http.Error(w, "Budgets aren't available on Plandex Cloud -- use billing settings to set a monthly limit", http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+func requireManageBudgets(w http.ResponseWriter, auth *types.ServerAuth) bool {
+	if !auth.HasPermission(shared.PermissionManageBudgets) {
+		log.Println("User does not have permission to manage budgets")
+		http.Error(w, "User does not have permission to manage budgets", http.StatusForbidden)
+		return false
+	}
+	return true
+}
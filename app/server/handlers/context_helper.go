@@ -93,7 +93,9 @@ func loadContexts(
 	var orgUserConfig *shared.OrgUserConfig
 
 	for _, context := range *loadReq {
-		if context.ContextType == shared.ContextPipedDataType || context.ContextType == shared.ContextNoteType || context.ContextType == shared.ContextImageType {
+		// notes and piped data restored from a context set keep their saved names
+		needsName := (context.ContextType == shared.ContextPipedDataType || context.ContextType == shared.ContextNoteType) && context.Name == ""
+		if needsName || context.ContextType == shared.ContextImageType {
 
 			settings, err = db.GetPlanSettings(plan)
 
@@ -144,6 +146,10 @@ func loadContexts(
 	num := 0
 	errCh := make(chan error, len(*loadReq))
 	for _, context := range *loadReq {
+		if context.Name != "" {
+			continue
+		}
+
 		if context.ContextType == shared.ContextPipedDataType {
 			num++
 
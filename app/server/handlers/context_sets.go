@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+const maxContextSetNameLength = 255
+
+func SaveContextSetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SaveContextSetHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+	log.Println("planId: ", planId, "branch: ", branch)
+
+	plan := authorizePlan(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error reading request body: %v\n", err)
+		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		return
+	}
+	defer r.Body.Close()
+
+	var req shared.SaveContextSetRequest
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		log.Println("Received empty name field")
+		http.Error(w, "name field is required", http.StatusBadRequest)
+		return
+	}
+
+	// names are used in paths when getting a set
+	if len(name) > maxContextSetNameLength || strings.ContainsAny(name, "/\\") {
+		log.Printf("Invalid context set name: %s\n", name)
+		http.Error(w, fmt.Sprintf("name must be at most %d characters and can't contain slashes", maxContextSetNameLength), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	var dbContexts []*db.Context
+
+	err = db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:    auth.OrgId,
+		UserId:   auth.User.Id,
+		PlanId:   planId,
+		Branch:   branch,
+		Reason:   "save context set",
+		Scope:    db.LockScopeRead,
+		Ctx:      ctx,
+		CancelFn: cancel,
+	}, func(repo *db.GitRepo) error {
+		// bodies are only kept for notes and piped data, but map parts are never needed
+		res, err := db.GetPlanContexts(auth.OrgId, planId, true, false)
+		if err != nil {
+			return err
+		}
+
+		dbContexts = res
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error getting contexts: %v\n", err)
+		http.Error(w, "Error getting contexts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(dbContexts) == 0 {
+		log.Println("No context to save")
+		http.Error(w, "The plan has no context to save", http.StatusBadRequest)
+		return
+	}
+
+	entries := db.ContextSetEntries{}
+	for _, dbContext := range dbContexts {
+		entry := &shared.ContextSetEntry{
+			ContextType:     dbContext.ContextType,
+			Name:            dbContext.Name,
+			FilePath:        dbContext.FilePath,
+			Url:             dbContext.Url,
+			ForceSkipIgnore: dbContext.ForceSkipIgnore,
+			ImageDetail:     dbContext.ImageDetail,
+			OwnerId:         dbContext.OwnerId,
+		}
+		if dbContext.ContextType == shared.ContextNoteType || dbContext.ContextType == shared.ContextPipedDataType {
+			entry.Body = dbContext.Body
+		}
+		entries = append(entries, entry)
+	}
+
+	err = db.UpsertContextSet(auth.OrgId, plan.ProjectId, auth.User.Id, name, req.IsShared, entries)
+
+	if err != nil {
+		log.Printf("Error saving context set: %v\n", err)
+		http.Error(w, "Error saving context set: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	set, err := db.GetContextSet(plan.ProjectId, auth.User.Id, name)
+
+	if err != nil || set == nil {
+		log.Printf("Error getting saved context set: %v\n", err)
+		http.Error(w, fmt.Sprintf("Error getting saved context set: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(set.ToApi())
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for SaveContextSetHandler")
+}
+
+func ListContextSetsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListContextSetsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	projectId := vars["projectId"]
+
+	log.Println("projectId: ", projectId)
+
+	if !authorizeProject(w, projectId, auth) {
+		return
+	}
+
+	sets, err := db.ListContextSets(projectId, auth.User.Id)
+
+	if err != nil {
+		log.Printf("Error listing context sets: %v\n", err)
+		http.Error(w, "Error listing context sets: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiSets := []*shared.ContextSet{}
+	for _, set := range sets {
+		apiSets = append(apiSets, set.ToApi())
+	}
+
+	bytes, err := json.Marshal(apiSets)
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for ListContextSetsHandler")
+}
+
+func GetContextSetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetContextSetHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	projectId := vars["projectId"]
+	name := vars["name"]
+
+	log.Println("projectId: ", projectId, "name: ", name)
+
+	if !authorizeProject(w, projectId, auth) {
+		return
+	}
+
+	set, err := db.GetContextSet(projectId, auth.User.Id, name)
+
+	if err != nil {
+		log.Printf("Error getting context set: %v\n", err)
+		http.Error(w, "Error getting context set: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if set == nil {
+		log.Printf("Context set not found: %s\n", name)
+		http.Error(w, fmt.Sprintf("No context set named '%s' in this project", name), http.StatusNotFound)
+		return
+	}
+
+	bytes, err := json.Marshal(set.ToApi())
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for GetContextSetHandler")
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/types"
+	"strconv"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// GetOrgExecPolicyHandler returns the org's exec policy, which any member can read since the CLI enforces it when running apply scripts. An org without a policy gets an empty one.
+func GetOrgExecPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetOrgExecPolicyHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	policy, err := db.GetOrgExecPolicy(auth.OrgId)
+
+	if err != nil {
+		log.Printf("Error getting org exec policy: %v\n", err)
+		http.Error(w, "Error getting org exec policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if policy == nil {
+		policy = &shared.ExecPolicy{}
+	}
+
+	bytes, err := json.Marshal(policy)
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for GetOrgExecPolicyHandler")
+}
+
+func SetOrgExecPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SetOrgExecPolicyHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !requireManageExecPolicy(w, auth) {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error reading request body: %v\n", err)
+		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		return
+	}
+	defer r.Body.Close()
+
+	var req shared.SetOrgExecPolicyRequest
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Policy == nil {
+		http.Error(w, "policy is required", http.StatusBadRequest)
+		return
+	}
+
+	err = req.Policy.Validate()
+	if err != nil {
+		http.Error(w, "Invalid exec policy: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = db.WithTx(r.Context(), "set org exec policy", func(tx *sqlx.Tx) error {
+		err := db.UpsertOrgExecPolicy(auth.OrgId, req.Policy, tx)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditTx(r, auth, auditParams{
+			action:   shared.AuditActionExecPolicyUpdated,
+			targetId: auth.OrgId,
+			data: map[string]string{
+				"allow":   strconv.Itoa(len(req.Policy.Allow)),
+				"confirm": strconv.Itoa(len(req.Policy.Confirm)),
+				"deny":    strconv.Itoa(len(req.Policy.Deny)),
+				"default": string(req.Policy.Default),
+			},
+		}, tx)
+	})
+
+	if err != nil {
+		log.Printf("Error setting org exec policy: %v\n", err)
+		http.Error(w, "Error setting org exec policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully set org exec policy")
+}
+
+func DeleteOrgExecPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteOrgExecPolicyHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !requireManageExecPolicy(w, auth) {
+		return
+	}
+
+	err := db.WithTx(r.Context(), "delete org exec policy", func(tx *sqlx.Tx) error {
+		err := db.DeleteOrgExecPolicy(auth.OrgId, tx)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditTx(r, auth, auditParams{
+			action:   shared.AuditActionExecPolicyDeleted,
+			targetId: auth.OrgId,
+		}, tx)
+	})
+
+	if err != nil {
+		log.Printf("Error deleting org exec policy: %v\n", err)
+		http.Error(w, "Error deleting org exec policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully deleted org exec policy")
+}
+
+func requireManageExecPolicy(w http.ResponseWriter, auth *types.ServerAuth) bool {
+	if !auth.HasPermission(shared.PermissionManageExecPolicy) {
+		log.Println("User does not have permission to manage the exec policy")
+		http.Error(w, "User does not have permission to manage the exec policy", http.StatusForbidden)
+		return false
+	}
+	return true
+}
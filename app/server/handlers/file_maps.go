@@ -6,6 +6,8 @@ import (
 	"log"
 	"net/http"
 	"plandex-server/db"
+	"plandex-server/syntax"
+	"plandex-server/syntax/file_map"
 	"runtime"
 	"runtime/debug"
 	"sync"
@@ -102,6 +104,123 @@ func GetFileMapHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func GetFileSymbolsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetFileSymbolsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	var req shared.GetFileSymbolsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Error decoding request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Inputs) > shared.ContextMapMaxBatchSize {
+		http.Error(w, fmt.Sprintf("Too many symbol selections: %d (max %d)", len(req.Inputs), shared.ContextMapMaxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	totalSize := 0
+	for name, input := range req.Inputs {
+		if len(input.Content) > shared.MaxContextBodySize {
+			http.Error(w, fmt.Sprintf("File for %s is too large: %d (max %d)", name, len(input.Content), shared.MaxContextBodySize), http.StatusBadRequest)
+			return
+		}
+		totalSize += len(input.Content)
+	}
+
+	if int64(totalSize) > shared.ContextMapMaxBatchBytes {
+		http.Error(w, fmt.Sprintf("Batch size too large: %d bytes (max %d bytes)", totalSize, shared.ContextMapMaxBatchBytes), http.StatusBadRequest)
+		return
+	}
+
+	resp := shared.GetFileSymbolsResponse{
+		Bodies:    map[string]string{},
+		Unmatched: map[string][]string{},
+	}
+
+	for name, input := range req.Inputs {
+		body, unmatched, err := file_map.ExtractSymbols(r.Context(), input.Path, []byte(input.Content), input.Pattern)
+		if err != nil {
+			log.Printf("Error extracting symbols for %s: %v", name, err)
+			http.Error(w, fmt.Sprintf("Error extracting symbols for %s: %v", name, err), http.StatusBadRequest)
+			return
+		}
+
+		resp.Bodies[name] = body
+		if len(unmatched) > 0 {
+			resp.Unmatched[name] = unmatched
+		}
+	}
+
+	respBytes, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error marshalling response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(respBytes)
+
+	log.Printf("GetFileSymbolsHandler success - selected symbols for %d files", len(req.Inputs))
+}
+
+func GetFileDepsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetFileDepsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	var req shared.GetFileDepsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Error decoding request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Inputs) > shared.ContextMapMaxBatchSize {
+		http.Error(w, fmt.Sprintf("Batch contains too many files: %d (max %d)", len(req.Inputs), shared.ContextMapMaxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.ProjectPaths) > shared.MaxContextMapPaths*10 {
+		http.Error(w, fmt.Sprintf("Too many project paths: %d (max %d)", len(req.ProjectPaths), shared.MaxContextMapPaths*10), http.StatusBadRequest)
+		return
+	}
+
+	for path, input := range req.Inputs {
+		if len(input) > shared.MaxContextMapSingleInputSize {
+			http.Error(w, fmt.Sprintf("File %s is too large: %d (max %d)", path, len(input), shared.MaxContextMapSingleInputSize), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if req.Inputs.TotalSize() > shared.ContextMapMaxBatchBytes {
+		http.Error(w, fmt.Sprintf("Batch size too large: %d bytes (max %d bytes)", req.Inputs.TotalSize(), shared.ContextMapMaxBatchBytes), http.StatusBadRequest)
+		return
+	}
+
+	deps, err := syntax.BuildDependencyGraph(r.Context(), req.Inputs, req.ProjectPaths)
+	if err != nil {
+		log.Printf("Error building dependency graph: %v", err)
+		http.Error(w, fmt.Sprintf("Error building dependency graph: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	respBytes, err := json.Marshal(shared.GetFileDepsResponse{Deps: deps})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error marshalling response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(respBytes)
+
+	log.Printf("GetFileDepsHandler success - resolved imports for %d files", len(req.Inputs))
+}
+
 func LoadCachedFileMapHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Received request for LoadCachedFileMapHandler")
 
@@ -62,10 +62,30 @@ func InviteUserHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	req.Email = strings.ToLower(req.Email)
 
+	orgRole, err := db.GetOrgRole(auth.OrgId, req.OrgRoleId)
+
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if orgRole == nil {
+		log.Printf("Org role not found: %v\n", req.OrgRoleId)
+		http.Error(w, "Org role not found: "+req.OrgRoleId, http.StatusBadRequest)
+		return
+	}
+
 	// ensure current user can invite target user
-	permission := shared.Permission(strings.Join([]string{string(shared.PermissionInviteUser), req.OrgRoleId}, "|"))
+	canInvite, err := hasOrgRolePermission(auth, shared.PermissionInviteUser, orgRole)
 
-	if !auth.HasPermission(permission) {
+	if err != nil {
+		log.Printf("Error checking invite permission: %v\n", err)
+		http.Error(w, "Error checking invite permission: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !canInvite {
 		log.Printf("User does not have permission to invite user with role: %v\n", req.OrgRoleId)
 		http.Error(w, "User does not have permission to invite user with role: "+req.OrgRoleId, http.StatusForbidden)
 		return
@@ -127,19 +147,31 @@ func InviteUserHandler(w http.ResponseWriter, r *http.Request) {
 
 	err = db.WithTx(r.Context(), "invite user", func(tx *sqlx.Tx) error {
 
-		err = db.CreateInvite(&db.Invite{
+		newInvite := &db.Invite{
 			OrgId:     auth.OrgId,
 			OrgRoleId: req.OrgRoleId,
 			Email:     req.Email,
 			Name:      req.Name,
 			InviterId: currentUserId,
-		}, tx)
+		}
+
+		err = db.CreateInvite(newInvite, tx)
 
 		if err != nil {
 			log.Printf("Error creating invite: %v\n", err)
 			return fmt.Errorf("error creating invite: %v", err)
 		}
 
+		err = recordAuditTx(r, auth, auditParams{
+			action:   shared.AuditActionUserInvited,
+			targetId: newInvite.Id,
+			data:     map[string]string{"email": req.Email, "role": orgRole.Name},
+		}, tx)
+
+		if err != nil {
+			return fmt.Errorf("error recording audit log entry: %v", err)
+		}
+
 		err = email.SendInviteEmail(req.Email, req.Name, auth.User.Name, org.Name)
 
 		if err != nil {
@@ -383,13 +415,32 @@ func DeleteInviteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	orgRole, err := db.GetOrgRole(auth.OrgId, invite.OrgRoleId)
+
+	if err != nil || orgRole == nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role", http.StatusInternalServerError)
+		return
+	}
+
 	// ensure current user can remove target invite
-	removePermission := shared.Permission(strings.Join([]string{string(shared.PermissionRemoveUser), invite.OrgRoleId}, "|"))
+	canRemove, err := hasOrgRolePermission(auth, shared.PermissionRemoveUser, orgRole)
 
-	invitePermission := shared.Permission(strings.Join([]string{string(shared.PermissionInviteUser), invite.OrgRoleId}, "|"))
+	if err != nil {
+		log.Printf("Error checking remove permission: %v\n", err)
+		http.Error(w, "Error checking remove permission: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	canInvite, err := hasOrgRolePermission(auth, shared.PermissionInviteUser, orgRole)
+
+	if err != nil {
+		log.Printf("Error checking invite permission: %v\n", err)
+		http.Error(w, "Error checking invite permission: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	if !(auth.HasPermission(removePermission) ||
-		(auth.User.Id == invite.InviterId && auth.HasPermission(invitePermission))) {
+	if !(canRemove || (auth.User.Id == invite.InviterId && canInvite)) {
 		log.Printf("User does not have permission to remove invite with role: %v\n", invite.OrgRoleId)
 		http.Error(w, "User does not have permission to remove invite with role: "+invite.OrgRoleId, http.StatusForbidden)
 		return
@@ -403,5 +454,11 @@ func DeleteInviteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:   shared.AuditActionInviteDeleted,
+		targetId: inviteId,
+		data:     map[string]string{"email": invite.Email, "role": orgRole.Name},
+	})
+
 	log.Println("Successfully deleted invite")
 }
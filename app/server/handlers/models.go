@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"plandex-server/db"
+	"strings"
 
 	shared "plandex-shared"
 
@@ -262,24 +263,42 @@ func UpsertCustomModelsHandler(w http.ResponseWriter, r *http.Request) {
 	toDeleteCustomProviderIds := []string{}
 	toDeleteModelPackIds := []string{}
 
+	// names for the audit log
+	var deletedModelNames, deletedProviderNames, deletedModelPackNames []string
+
 	for _, model := range customModels {
 		if _, exists := inputModelIds[string(model.ModelId)]; !exists {
 			toDeleteCustomModelIds = append(toDeleteCustomModelIds, model.Id)
+			deletedModelNames = append(deletedModelNames, string(model.ModelId))
 		}
 	}
 
 	for _, provider := range customProviders {
 		if _, exists := inputProviderNames[provider.Name]; !exists {
 			toDeleteCustomProviderIds = append(toDeleteCustomProviderIds, provider.Id)
+			deletedProviderNames = append(deletedProviderNames, provider.Name)
 		}
 	}
 
 	for _, modelPack := range customModelPacks {
 		if _, exists := inputModelPackNames[modelPack.Name]; !exists {
 			toDeleteModelPackIds = append(toDeleteModelPackIds, modelPack.Id)
+			deletedModelPackNames = append(deletedModelPackNames, modelPack.Name)
 		}
 	}
 
+	if len(toUpsertCustomModels)+len(toUpsertModelPacks)+len(toDeleteCustomModelIds)+len(toDeleteModelPackIds) > 0 && !auth.HasPermission(shared.PermissionManageModelPacks) {
+		log.Println("User does not have permission to manage custom models and model packs")
+		http.Error(w, "User does not have permission to manage custom models and model packs", http.StatusForbidden)
+		return
+	}
+
+	if len(toUpsertCustomProviders)+len(toDeleteCustomProviderIds) > 0 && !auth.HasPermission(shared.PermissionManageCustomProviders) {
+		log.Println("User does not have permission to manage custom providers")
+		http.Error(w, "User does not have permission to manage custom providers", http.StatusForbidden)
+		return
+	}
+
 	numChanges := len(toUpsertCustomModels) + len(toUpsertCustomProviders) + len(toUpsertModelPacks) + len(toDeleteCustomModelIds) + len(toDeleteCustomProviderIds) + len(toDeleteModelPackIds)
 	if numChanges == 0 {
 		w.WriteHeader(http.StatusOK)
@@ -324,6 +343,35 @@ func UpsertCustomModelsHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		var upsertedModelNames, upsertedProviderNames, upsertedModelPackNames []string
+		for _, model := range toUpsertCustomModels {
+			upsertedModelNames = append(upsertedModelNames, string(model.ModelId))
+		}
+		for _, provider := range toUpsertCustomProviders {
+			upsertedProviderNames = append(upsertedProviderNames, provider.Name)
+		}
+		for _, modelPack := range toUpsertModelPacks {
+			upsertedModelPackNames = append(upsertedModelPackNames, modelPack.Name)
+		}
+
+		data := map[string]string{}
+		for key, names := range map[string][]string{
+			"updatedModels":     upsertedModelNames,
+			"updatedProviders":  upsertedProviderNames,
+			"updatedModelPacks": upsertedModelPackNames,
+			"deletedModels":     deletedModelNames,
+			"deletedProviders":  deletedProviderNames,
+			"deletedModelPacks": deletedModelPackNames,
+		} {
+			if len(names) > 0 {
+				data[key] = strings.Join(names, ", ")
+			}
+		}
+
+		if err := recordAuditTx(r, auth, auditParams{action: shared.AuditActionCustomModelsUpdated, data: data}, tx); err != nil {
+			return fmt.Errorf("error recording audit log entry: %w", err)
+		}
+
 		return nil
 	})
 
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"plandex-server/openapi"
+)
+
+func GetOpenApiSpecHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetOpenApiSpecHandler")
+
+	bytes, err := openapi.SpecJSON()
+	if err != nil {
+		log.Printf("Error getting openapi spec: %v\n", err)
+		http.Error(w, "Error getting openapi spec: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for GetOpenApiSpecHandler")
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"plandex-server/db"
 	"plandex-server/hooks"
+	"plandex-server/types"
 
 	shared "plandex-shared"
 )
@@ -61,3 +62,15 @@ func getApiOrg(orgId string) (*shared.Org, *shared.ApiError) {
 
 	return org.ToApi(), nil
 }
+
+// withoutBillingFields removes billing details from an org for users who can't view billing
+func withoutBillingFields(auth *types.ServerAuth, org *shared.Org) *shared.Org {
+	if org == nil || org.CloudBillingFields == nil || auth.HasPermission(shared.PermissionViewBilling) {
+		return org
+	}
+
+	// copy so orgs returned by hooks aren't modified
+	res := *org
+	res.CloudBillingFields = nil
+	return &res
+}
@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/types"
+	"regexp"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
+)
+
+var orgRoleNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+func CreateOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreateOrgRoleHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeManageOrgRoles(w, auth) {
+		return
+	}
+
+	var req shared.CreateOrgRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+
+	if !validateOrgRoleInput(w, auth, "", req.Name, req.Permissions) {
+		return
+	}
+
+	var role *db.OrgRole
+	err := db.WithTx(r.Context(), "create org role", func(tx *sqlx.Tx) error {
+		var err error
+		role, err = db.CreateOrgRole(auth.OrgId, req.Name, req.Description, req.Permissions, tx)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditTx(r, auth, auditParams{
+			action:   shared.AuditActionOrgRoleCreated,
+			targetId: role.Id,
+			data:     map[string]string{"name": role.Name, "permissions": joinPermissions(req.Permissions)},
+		}, tx)
+	})
+
+	if err != nil {
+		log.Printf("Error creating org role: %v\n", err)
+		http.Error(w, "Error creating org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeOrgRole(w, role)
+
+	log.Println("Successfully created org role", role.Id)
+}
+
+func UpdateOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UpdateOrgRoleHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeManageOrgRoles(w, auth) {
+		return
+	}
+
+	vars := mux.Vars(r)
+	roleId := vars["roleId"]
+
+	log.Println("roleId: ", roleId)
+
+	role := getCustomOrgRole(w, auth, roleId)
+	if role == nil {
+		return
+	}
+
+	var req shared.UpdateOrgRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+
+	if !validateOrgRoleInput(w, auth, role.Id, req.Name, req.Permissions) {
+		return
+	}
+
+	err := db.WithTx(r.Context(), "update org role", func(tx *sqlx.Tx) error {
+		err := db.UpdateOrgRole(auth.OrgId, role.Id, req.Name, req.Description, req.Permissions, tx)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditTx(r, auth, auditParams{
+			action:   shared.AuditActionOrgRoleUpdated,
+			targetId: role.Id,
+			data:     map[string]string{"name": req.Name, "permissions": joinPermissions(req.Permissions)},
+		}, tx)
+	})
+
+	if err != nil {
+		log.Printf("Error updating org role: %v\n", err)
+		http.Error(w, "Error updating org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	role, err = db.GetOrgRole(auth.OrgId, role.Id)
+
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeOrgRole(w, role)
+
+	log.Println("Successfully updated org role", role.Id)
+}
+
+func DeleteOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteOrgRoleHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeManageOrgRoles(w, auth) {
+		return
+	}
+
+	vars := mux.Vars(r)
+	roleId := vars["roleId"]
+
+	log.Println("roleId: ", roleId)
+
+	role := getCustomOrgRole(w, auth, roleId)
+	if role == nil {
+		return
+	}
+
+	numUsers, err := db.NumUsersWithRole(auth.OrgId, role.Id)
+
+	if err != nil {
+		log.Printf("Error counting users with role: %v\n", err)
+		http.Error(w, "Error counting users with role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if numUsers > 0 {
+		log.Printf("Role %s still has %d users\n", role.Id, numUsers)
+		http.Error(w, fmt.Sprintf("Role is assigned to %d user(s) -- give them a different role first", numUsers), http.StatusConflict)
+		return
+	}
+
+	numInvites, err := db.NumPendingInvitesWithRole(auth.OrgId, role.Id)
+
+	if err != nil {
+		log.Printf("Error counting invites with role: %v\n", err)
+		http.Error(w, "Error counting invites with role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if numInvites > 0 {
+		log.Printf("Role %s still has %d pending invites\n", role.Id, numInvites)
+		http.Error(w, fmt.Sprintf("Role is used by %d pending invite(s) -- revoke them first", numInvites), http.StatusConflict)
+		return
+	}
+
+	err = db.WithTx(r.Context(), "delete org role", func(tx *sqlx.Tx) error {
+		err := db.DeleteOrgRole(auth.OrgId, role.Id, tx)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditTx(r, auth, auditParams{
+			action:   shared.AuditActionOrgRoleDeleted,
+			targetId: role.Id,
+			data:     map[string]string{"name": role.Name},
+		}, tx)
+	})
+
+	if err != nil {
+		log.Printf("Error deleting org role: %v\n", err)
+		http.Error(w, "Error deleting org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully deleted org role", role.Id)
+}
+
+// hasOrgRolePermission checks a permission that's scoped to an org role, like inviting or removing a user with that role. Custom roles are scoped like the built-in member role, and also need the user to have every permission in the role, so a member-level permission can't be used to hand out a role with more access than the user's own.
+func hasOrgRolePermission(auth *types.ServerAuth, permission shared.Permission, role *db.OrgRole) (bool, error) {
+	if role.OrgId == nil {
+		return auth.HasPermissionForResource(permission, role.Id), nil
+	}
+
+	memberRoleId, err := db.GetOrgMemberRoleId()
+	if err != nil {
+		return false, err
+	}
+
+	if !auth.HasPermissionForResource(permission, memberRoleId) {
+		return false, nil
+	}
+
+	missing, err := missingCustomOrgRolePermission(auth, role, memberRoleId)
+	if err != nil {
+		return false, err
+	}
+
+	return missing == "", nil
+}
+
+// missingCustomOrgRolePermission returns the first permission in a custom role that the user doesn't have, or an empty string if they have all of them
+func missingCustomOrgRolePermission(auth *types.ServerAuth, role *db.OrgRole, memberRoleId string) (shared.Permission, error) {
+	permissionsByRoleId, err := db.GetOrgRolePermissions([]string{role.Id})
+	if err != nil {
+		return "", err
+	}
+
+	return missingOrgRolePermission(auth, permissionsByRoleId[role.Id], memberRoleId), nil
+}
+
+// missingOrgRolePermission returns the first of a custom role's permissions that the user doesn't have. Permissions that are scoped to an org role apply to member-level users in a custom role, so those are checked against the member role.
+func missingOrgRolePermission(auth *types.ServerAuth, permissions []shared.Permission, memberRoleId string) shared.Permission {
+	for _, permission := range permissions {
+		var hasPermission bool
+		if permission.IsOrgRoleScoped() {
+			hasPermission = auth.HasPermissionForResource(permission, memberRoleId)
+		} else {
+			hasPermission = auth.HasPermission(permission)
+		}
+
+		if !hasPermission {
+			return permission
+		}
+	}
+
+	return ""
+}
+
+func authorizeManageOrgRoles(w http.ResponseWriter, auth *types.ServerAuth) bool {
+	org, err := db.GetOrg(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org: %v\n", err)
+		http.Error(w, "Error getting org: "+err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	if org.IsTrial {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeTrialActionNotAllowed,
+			Status: http.StatusForbidden,
+			Msg:    "Trial user can't manage org roles",
+		})
+		return false
+	}
+
+	if !auth.HasPermission(shared.PermissionManageOrgRoles) {
+		log.Println("User cannot manage org roles")
+		http.Error(w, "User cannot manage org roles", http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+func getCustomOrgRole(w http.ResponseWriter, auth *types.ServerAuth, roleId string) *db.OrgRole {
+	role, err := db.GetOrgRole(auth.OrgId, roleId)
+
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	if role == nil {
+		log.Printf("Org role not found: %s\n", roleId)
+		http.Error(w, "Org role not found", http.StatusNotFound)
+		return nil
+	}
+
+	if role.OrgId == nil {
+		log.Println("Can't change a built-in org role")
+		http.Error(w, "Built-in org roles can't be changed", http.StatusBadRequest)
+		return nil
+	}
+
+	memberRoleId, err := db.GetOrgMemberRoleId()
+	if err != nil {
+		log.Printf("Error getting member role id: %v\n", err)
+		http.Error(w, "Error getting member role id: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	// a role with permissions the user doesn't have could've been created by someone with more access -- changing it would let them rename it or take those permissions away
+	missing, err := missingCustomOrgRolePermission(auth, role, memberRoleId)
+	if err != nil {
+		log.Printf("Error getting org role permissions: %v\n", err)
+		http.Error(w, "Error getting org role permissions: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	if missing != "" {
+		log.Printf("User does not have permission %s in role %s\n", missing, role.Id)
+		http.Error(w, "Can't change a role with a permission you don't have: "+string(missing), http.StatusForbidden)
+		return nil
+	}
+
+	return role
+}
+
+// validateOrgRoleInput checks a custom role's name and ensures the user isn't granting permissions they don't have. When updating a role, its current permissions are checked by getCustomOrgRole.
+func validateOrgRoleInput(w http.ResponseWriter, auth *types.ServerAuth, roleId, name string, permissions []shared.Permission) bool {
+	if !orgRoleNameRegex.MatchString(name) {
+		log.Printf("Invalid org role name: %s\n", name)
+		http.Error(w, "Role name must be lowercase letters, numbers, dashes or underscores", http.StatusBadRequest)
+		return false
+	}
+
+	if shared.DefaultOrgRoleNames[name] {
+		log.Printf("Org role name is reserved: %s\n", name)
+		http.Error(w, "'"+name+"' is a built-in role name", http.StatusBadRequest)
+		return false
+	}
+
+	existing, err := db.GetOrgRoleByName(auth.OrgId, name)
+
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	if existing != nil && existing.Id != roleId {
+		log.Printf("Org role already exists: %s\n", name)
+		http.Error(w, "A role named '"+name+"' already exists", http.StatusConflict)
+		return false
+	}
+
+	memberRoleId, err := db.GetOrgMemberRoleId()
+	if err != nil {
+		log.Printf("Error getting member role id: %v\n", err)
+		http.Error(w, "Error getting member role id: "+err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	for _, permission := range permissions {
+		if !permission.IsValid() {
+			log.Printf("Invalid permission: %s\n", permission)
+			http.Error(w, "Invalid permission: "+string(permission), http.StatusBadRequest)
+			return false
+		}
+	}
+
+	missing := missingOrgRolePermission(auth, permissions, memberRoleId)
+	if missing != "" {
+		log.Printf("User does not have permission %s\n", missing)
+		http.Error(w, "Can't grant a permission you don't have: "+string(missing), http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+func writeOrgRole(w http.ResponseWriter, role *db.OrgRole) {
+	permissionsByRoleId, err := db.GetOrgRolePermissions([]string{role.Id})
+
+	if err != nil {
+		log.Printf("Error getting org role permissions: %v\n", err)
+		http.Error(w, "Error getting org role permissions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiRole := role.ToApi()
+	apiRole.Permissions = permissionsByRoleId[role.Id]
+
+	bytes, err := json.Marshal(apiRole)
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+}
+
+func joinPermissions(permissions []shared.Permission) string {
+	names := make([]string, len(permissions))
+	for i, permission := range permissions {
+		names[i] = string(permission)
+	}
+	return strings.Join(names, ", ")
+}
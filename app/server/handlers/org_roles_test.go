@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"plandex-server/types"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestMissingOrgRolePermission(t *testing.T) {
+	memberRoleId := "member-role"
+
+	// an admin-like user who can manage members but can't delete the org or manage billing
+	auth := &types.ServerAuth{
+		Permissions: shared.Permissions{
+			"create_plan":                    true,
+			"exec_commands":                  true,
+			"set_user_role|" + memberRoleId:  true,
+			"invite_user|" + memberRoleId:    true,
+			"invite_user|some-other-role-id": true,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		permissions []shared.Permission
+		want        shared.Permission
+	}{
+		{"no permissions", nil, ""},
+		{"held permissions", []shared.Permission{shared.PermissionCreatePlan, shared.PermissionExecCommands}, ""},
+		{"scoped permission held for members", []shared.Permission{shared.PermissionInviteUser, shared.PermissionSetUserRole}, ""},
+		{"scoped permission not held for members", []shared.Permission{shared.PermissionRemoveUser}, shared.PermissionRemoveUser},
+		{"higher permission", []shared.Permission{shared.PermissionCreatePlan, shared.PermissionDeleteOrg}, shared.PermissionDeleteOrg},
+		{"billing", []shared.Permission{shared.PermissionManageBilling}, shared.PermissionManageBilling},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingOrgRolePermission(auth, tt.permissions, memberRoleId); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -69,6 +69,10 @@ func CreateOrgHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !requireSessionAuth(w, auth) {
+		return
+	}
+
 	// read the request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -100,7 +104,7 @@ func CreateOrgHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// create a new org
-		org, err = db.CreateOrg(&req, auth.AuthToken.UserId, domain, tx)
+		org, err = db.CreateOrg(&req, auth.User.Id, domain, tx)
 
 		if err != nil {
 			log.Printf("Error creating org: %v\n", err)
@@ -116,6 +120,17 @@ func CreateOrgHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		err = recordAuditTx(r, auth, auditParams{
+			orgId:    org.Id,
+			action:   shared.AuditActionOrgCreated,
+			targetId: org.Id,
+			data:     map[string]string{"name": org.Name},
+		}, tx)
+
+		if err != nil {
+			return fmt.Errorf("error recording audit log entry: %v", err)
+		}
+
 		_, apiErr = hooks.ExecHook(hooks.CreateOrg, hooks.HookParams{
 			Auth: auth,
 			Tx:   tx,
@@ -180,6 +195,8 @@ func GetOrgSessionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	org = withoutBillingFields(auth, org)
+
 	bytes, err := json.Marshal(org)
 
 	if err != nil {
@@ -238,9 +255,24 @@ func ListOrgRolesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var roleIds []string
+	for _, role := range roles {
+		roleIds = append(roleIds, role.Id)
+	}
+
+	permissionsByRoleId, err := db.GetOrgRolePermissions(roleIds)
+
+	if err != nil {
+		log.Printf("Error getting org role permissions: %v\n", err)
+		http.Error(w, "Error getting org role permissions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	var apiRoles []*shared.OrgRole
 	for _, role := range roles {
-		apiRoles = append(apiRoles, role.ToApi())
+		apiRole := role.ToApi()
+		apiRole.Permissions = permissionsByRoleId[role.Id]
+		apiRoles = append(apiRoles, apiRole)
 	}
 
 	bytes, err := json.Marshal(apiRoles)
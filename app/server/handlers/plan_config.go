@@ -6,6 +6,9 @@ import (
 	"log"
 	"net/http"
 	"plandex-server/db"
+	"plandex-server/types"
+	"strconv"
+	"strings"
 
 	shared "plandex-shared"
 
@@ -38,6 +41,8 @@ func GetPlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	maskPlanConfigExec(auth, config)
+
 	res := shared.GetPlanConfigResponse{
 		Config: config,
 	}
@@ -66,7 +71,7 @@ func UpdatePlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanExec(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -79,6 +84,32 @@ func UpdatePlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Config != nil {
+		if !req.Config.ExecSandbox.IsValid() {
+			http.Error(w, fmt.Sprintf("Invalid exec sandbox '%s' -- must be one of: %s", req.Config.ExecSandbox, strings.Join(shared.ExecSandboxChoices, ", ")), http.StatusBadRequest)
+			return
+		}
+
+		current, err := db.GetPlanConfig(planId)
+		if err != nil {
+			log.Println("Error getting plan config: ", err)
+			http.Error(w, "Error getting plan config", http.StatusInternalServerError)
+			return
+		}
+
+		// the sandbox settings decide what commands can reach on the machine of whoever applies the plan, so only the owner can change them
+		if plan.OwnerId != auth.User.Id && !current.SameExecSandbox(req.Config) {
+			http.Error(w, "Only the plan's owner can change exec-sandbox, exec-sandbox-network, exec-sandbox-image, or exec-env", http.StatusForbidden)
+			return
+		}
+
+		// users without exec permission can update the rest of the config, but can't change whether the plan executes commands
+		if !auth.HasPermission(shared.PermissionExecCommands) {
+			req.Config.CanExec = current.CanExec
+			req.Config.AutoExec = current.AutoExec
+		}
+	}
+
 	err = db.StorePlanConfig(planId, req.Config)
 	if err != nil {
 		log.Println("Error storing plan config: ", err)
@@ -86,6 +117,12 @@ func UpdatePlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action: shared.AuditActionPlanConfigUpdated,
+		planId: planId,
+		data:   planConfigAuditData(req.Config),
+	})
+
 	log.Println("UpdatePlanConfigHandler processed successfully")
 }
 
@@ -104,6 +141,8 @@ func GetDefaultPlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	maskPlanConfigExec(auth, config)
+
 	res := shared.GetDefaultPlanConfigResponse{
 		Config: config,
 	}
@@ -135,6 +174,13 @@ func UpdateDefaultPlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Config != nil && !req.Config.ExecSandbox.IsValid() {
+		http.Error(w, fmt.Sprintf("Invalid exec sandbox '%s' -- must be one of: %s", req.Config.ExecSandbox, strings.Join(shared.ExecSandboxChoices, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	maskPlanConfigExec(auth, req.Config)
+
 	err = db.WithTx(r.Context(), "update default plan config", func(tx *sqlx.Tx) error {
 
 		err := db.StoreDefaultPlanConfig(auth.User.Id, req.Config, tx)
@@ -143,6 +189,14 @@ func UpdateDefaultPlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 			return fmt.Errorf("error storing default plan config: %v", err)
 		}
 
+		err = recordAuditTx(r, auth, auditParams{
+			action: shared.AuditActionDefaultPlanConfigUpdated,
+			data:   planConfigAuditData(req.Config),
+		}, tx)
+		if err != nil {
+			return fmt.Errorf("error recording audit log entry: %v", err)
+		}
+
 		return nil
 	})
 
@@ -154,3 +208,31 @@ func UpdateDefaultPlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("UpdateDefaultPlanConfigHandler processed successfully")
 }
+
+// maskPlanConfigExec turns off command execution for users without exec permission
+func maskPlanConfigExec(auth *types.ServerAuth, config *shared.PlanConfig) {
+	if config == nil || auth.HasPermission(shared.PermissionExecCommands) {
+		return
+	}
+
+	config.CanExec = false
+	config.AutoExec = false
+}
+
+// planConfigAuditData records the settings that control what a plan can do without review
+func planConfigAuditData(config *shared.PlanConfig) map[string]string {
+	if config == nil {
+		return nil
+	}
+
+	return map[string]string{
+		"autoMode":           string(config.AutoMode),
+		"autoApply":          strconv.FormatBool(config.AutoApply),
+		"canExec":            strconv.FormatBool(config.CanExec),
+		"autoExec":           strconv.FormatBool(config.AutoExec),
+		"execSandbox":        string(config.GetExecSandbox()),
+		"execSandboxNetwork": strconv.FormatBool(config.ExecSandboxNetwork),
+		"execSandboxImage":   config.GetExecSandboxImage(),
+		"execEnv":            strings.Join(config.ExecEnvAllowlist, ","),
+	}
+}
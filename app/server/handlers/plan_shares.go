@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func ListPlanSharesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListPlanSharesHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+
+	log.Println("planId: ", planId)
+
+	if authorizePlan(w, planId, auth) == nil {
+		return
+	}
+
+	shares, err := db.ListPlanShares(planId)
+
+	if err != nil {
+		log.Printf("Error listing plan shares: %v\n", err)
+		http.Error(w, "Error listing plan shares: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiShares := []*shared.PlanShare{}
+	for _, share := range shares {
+		apiShares = append(apiShares, share.ToApi())
+	}
+
+	bytes, err := json.Marshal(apiShares)
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for ListPlanSharesHandler")
+}
+
+func SharePlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SharePlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+
+	log.Println("planId: ", planId)
+
+	plan := authorizePlanShares(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error reading request body: %v\n", err)
+		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		return
+	}
+	defer r.Body.Close()
+
+	var req shared.SharePlanRequest
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	if email == "" {
+		log.Println("Received empty email field")
+		http.Error(w, "email field is required", http.StatusBadRequest)
+		return
+	}
+
+	if !req.Role.IsValid() {
+		log.Printf("Invalid share role: %s\n", req.Role)
+		http.Error(w, "role must be one of: viewer, collaborator, owner", http.StatusBadRequest)
+		return
+	}
+
+	user, err := db.GetUserByEmail(email)
+
+	if err != nil {
+		log.Printf("Error getting user: %v\n", err)
+		http.Error(w, "Error getting user: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var isMember bool
+	if user != nil {
+		isMember, err = db.ValidateOrgMembership(user.Id, auth.OrgId)
+
+		if err != nil {
+			log.Printf("Error validating org membership: %v\n", err)
+			http.Error(w, "Error validating org membership: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if !isMember {
+		log.Println("User isn't a member of the org")
+		http.Error(w, "No one with that email is a member of the org", http.StatusNotFound)
+		return
+	}
+
+	if user.Id == plan.OwnerId {
+		log.Println("Can't share a plan with its owner")
+		http.Error(w, "Can't share a plan with its owner", http.StatusBadRequest)
+		return
+	}
+
+	err = db.UpsertPlanShare(auth.OrgId, planId, user.Id, auth.User.Id, req.Role)
+
+	if err != nil {
+		log.Printf("Error sharing plan: %v\n", err)
+		http.Error(w, "Error sharing plan: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:   shared.AuditActionPlanShared,
+		planId:   planId,
+		targetId: user.Id,
+		data:     map[string]string{"email": user.Email, "role": string(req.Role)},
+	})
+
+	share, err := db.GetPlanShare(planId, user.Id)
+
+	if err != nil {
+		log.Printf("Error getting plan share: %v\n", err)
+		http.Error(w, "Error getting plan share: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(share.ToApi())
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully shared plan", planId, "with user", user.Id)
+}
+
+func UnsharePlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UnsharePlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	userId := vars["userId"]
+
+	log.Println("planId: ", planId, "userId: ", userId)
+
+	// anyone can remove their own access to a plan
+	if userId == auth.User.Id {
+		if authorizePlan(w, planId, auth) == nil {
+			return
+		}
+	} else if authorizePlanShares(w, planId, auth) == nil {
+		return
+	}
+
+	share, err := db.GetPlanShare(planId, userId)
+
+	if err != nil {
+		log.Printf("Error getting plan share: %v\n", err)
+		http.Error(w, "Error getting plan share: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if share == nil {
+		log.Println("Plan share not found")
+		http.Error(w, "Plan isn't shared with this user", http.StatusNotFound)
+		return
+	}
+
+	err = db.DeletePlanShare(planId, userId)
+
+	if err != nil {
+		log.Printf("Error deleting plan share: %v\n", err)
+		http.Error(w, "Error deleting plan share: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:   shared.AuditActionPlanUnshared,
+		planId:   planId,
+		targetId: userId,
+		data:     map[string]string{"email": share.UserEmail, "role": string(share.Role)},
+	})
+
+	log.Println("Successfully unshared plan", planId, "with user", userId)
+}
@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"plandex-server/db"
 	modelPlan "plandex-server/model/plan"
+	"plandex-server/webhooks"
+	"strings"
 	"time"
 
 	shared "plandex-shared"
@@ -116,7 +118,7 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 	branch := vars["branch"]
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanExec(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -243,6 +245,17 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	webhooks.Emit(webhooks.EmitParams{
+		Type:   webhooks.EventPlanApplied,
+		OrgId:  auth.OrgId,
+		UserId: auth.User.Id,
+		PlanId: planId,
+		Branch: branch,
+		Data: map[string]interface{}{
+			"commitMsg": commitMsg,
+		},
+	})
+
 	w.Write([]byte(commitMsg))
 
 	log.Println("Successfully applied plan", planId)
@@ -261,7 +274,7 @@ func RejectAllChangesHandler(w http.ResponseWriter, r *http.Request) {
 	branch := vars["branch"]
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanExec(w, planId, auth) == nil {
 		return
 	}
 
@@ -313,7 +326,7 @@ func RejectFileHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanExec(w, planId, auth) == nil {
 		return
 	}
 
@@ -373,7 +386,7 @@ func RejectFilesHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanExec(w, planId, auth) == nil {
 		return
 	}
 
@@ -429,6 +442,98 @@ func RejectFilesHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Successfully rejected plan files", req.Paths)
 }
 
+func ReviewReplacementsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ReviewReplacementsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	log.Println("planId: ", planId, "branch: ", branch)
+
+	if authorizePlanExec(w, planId, auth) == nil {
+		return
+	}
+
+	var req shared.ReviewReplacementsRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error decoding request: %v\n", err)
+		http.Error(w, "Error decoding request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.RejectedIds) == 0 && len(req.EditedById) == 0 {
+		http.Error(w, "No changes to reject or edit", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	err = db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:          auth.OrgId,
+		UserId:         auth.User.Id,
+		PlanId:         planId,
+		Branch:         branch,
+		Scope:          db.LockScopeWrite,
+		Ctx:            ctx,
+		CancelFn:       cancel,
+		ClearRepoOnErr: true,
+	}, func(repo *db.GitRepo) error {
+		paths, err := db.ReviewReplacements(auth.OrgId, planId, req.RejectedIds, req.EditedById, time.Now())
+		if err != nil {
+			return err
+		}
+
+		var parts []string
+		if len(req.RejectedIds) > 0 {
+			part := fmt.Sprintf("🚫 Rejected %d pending change", len(req.RejectedIds))
+			if len(req.RejectedIds) > 1 {
+				part += "s"
+			}
+			parts = append(parts, part)
+		}
+		if len(req.EditedById) > 0 {
+			part := fmt.Sprintf("%d pending change", len(req.EditedById))
+			if len(parts) == 0 {
+				part = "✏️  Edited " + part
+			} else {
+				part = "edited " + part
+			}
+			if len(req.EditedById) > 1 {
+				part += "s"
+			}
+			parts = append(parts, part)
+		}
+		msg := strings.Join(parts, " and ")
+		msg += " in:"
+
+		for _, path := range paths {
+			msg += fmt.Sprintf("\n • %s", path)
+		}
+
+		err = repo.GitAddAndCommit(branch, msg)
+		if err != nil {
+			return fmt.Errorf("error committing reviewed changes: %v", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error reviewing changes: %v\n", err)
+		http.Error(w, "Error reviewing changes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully reviewed changes for plan", planId)
+}
+
 func ArchivePlanHandler(w http.ResponseWriter, r *http.Request) {
 	auth := Authenticate(w, r, true)
 	if auth == nil {
@@ -474,6 +579,11 @@ func ArchivePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action: shared.AuditActionPlanArchived,
+		planId: planId,
+	})
+
 	log.Println("Successfully archived plan", planId)
 }
 
@@ -522,6 +632,11 @@ func UnarchivePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action: shared.AuditActionPlanUnarchived,
+		planId: planId,
+	})
+
 	log.Println("Successfully unarchived plan", planId)
 }
 
@@ -164,7 +164,7 @@ func LoadContextHandler(w http.ResponseWriter, r *http.Request) {
 	branchName := vars["branch"]
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanExec(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -224,7 +224,7 @@ func UpdateContextHandler(w http.ResponseWriter, r *http.Request) {
 	branchName := vars["branch"]
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanExec(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -330,7 +330,7 @@ func DeleteContextHandler(w http.ResponseWriter, r *http.Request) {
 	branchName := vars["branch"]
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanExec(w, planId, auth)
 
 	if plan == nil {
 		return
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"plandex-server/db"
 	"plandex-server/hooks"
+	"plandex-server/webhooks"
 	"runtime"
 	"runtime/debug"
 	"sort"
@@ -108,6 +109,17 @@ func CreatePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	webhooks.Emit(webhooks.EmitParams{
+		Type:   webhooks.EventPlanCreated,
+		OrgId:  auth.OrgId,
+		UserId: auth.User.Id,
+		PlanId: plan.Id,
+		Data: map[string]interface{}{
+			"name":      plan.Name,
+			"projectId": projectId,
+		},
+	})
+
 	resp := shared.CreatePlanResponse{
 		Id:   plan.Id,
 		Name: plan.Name,
@@ -169,7 +181,7 @@ func RenamePlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanRename(w, planId, auth)
 
 	if plan == nil {
 		return
@@ -182,12 +194,6 @@ func RenamePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if plan.OwnerId != auth.User.Id {
-		log.Println("Only the plan owner can rename a plan")
-		http.Error(w, "Only the plan owner can rename a plan", http.StatusForbidden)
-		return
-	}
-
 	if requestBody.Name == "" {
 		log.Println("Name cannot be empty")
 		http.Error(w, "Name cannot be empty", http.StatusBadRequest)
@@ -202,6 +208,12 @@ func RenamePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action: shared.AuditActionPlanRenamed,
+		planId: planId,
+		data:   map[string]string{"from": plan.Name, "to": requestBody.Name},
+	})
+
 	log.Println("Successfully renamed plan")
 }
 
@@ -251,6 +263,12 @@ func DeletePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action: shared.AuditActionPlanDeleted,
+		planId: planId,
+		data:   map[string]string{"name": plan.Name, "projectId": plan.ProjectId},
+	})
+
 	err = db.DeletePlanDir(auth.OrgId, planId)
 
 	if err != nil {
@@ -287,6 +305,11 @@ func DeleteAllPlansHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action: shared.AuditActionPlansDeletedAll,
+		data:   map[string]string{"projectId": projectId},
+	})
+
 	log.Println("Successfully deleted all plans")
 }
 
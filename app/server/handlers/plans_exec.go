@@ -13,6 +13,7 @@ import (
 	modelPlan "plandex-server/model/plan"
 	"plandex-server/notify"
 	"plandex-server/types"
+	"plandex-server/webhooks"
 	"time"
 
 	shared "plandex-shared"
@@ -34,7 +35,7 @@ func TellPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	plan := authorizePlanExecUpdate(w, planId, auth)
+	plan := authorizePlanExec(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -66,6 +67,12 @@ func TellPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// without exec permission, the model isn't asked to write commands for the user to run
+	if requestBody.ExecEnabled && !auth.HasPermission(shared.PermissionExecCommands) {
+		log.Println("User doesn't have permission to execute commands -- disabling exec mode")
+		requestBody.ExecEnabled = false
+	}
+
 	_, apiErr := hooks.ExecHook(hooks.WillTellPlan, hooks.HookParams{
 		Auth: auth,
 		Plan: plan,
@@ -112,7 +119,11 @@ func TellPlanHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if requestBody.ConnectStream {
-		startResponseStream(r.Context(), w, auth, planId, branch, false)
+		if isEventStreamRequest(r) {
+			startEventStream(w, r, auth, planId, branch, false)
+		} else {
+			startResponseStream(r.Context(), w, auth, planId, branch, false)
+		}
 	}
 
 	log.Println("Successfully processed request for TellPlanHandler")
@@ -130,7 +141,7 @@ func BuildPlanHandler(w http.ResponseWriter, r *http.Request) {
 	branch := vars["branch"]
 
 	log.Println("planId: ", planId)
-	plan := authorizePlanExecUpdate(w, planId, auth)
+	plan := authorizePlanExec(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -207,7 +218,11 @@ func BuildPlanHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if requestBody.ConnectStream {
-		startResponseStream(r.Context(), w, auth, planId, branch, false)
+		if isEventStreamRequest(r) {
+			startEventStream(w, r, auth, planId, branch, false)
+		} else {
+			startResponseStream(r.Context(), w, auth, planId, branch, false)
+		}
 	}
 
 	log.Println("Successfully processed request for BuildPlanHandler")
@@ -250,7 +265,11 @@ func ConnectPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	startResponseStream(r.Context(), w, auth, planId, branch, true)
+	if isEventStreamRequest(r) {
+		startEventStream(w, r, auth, planId, branch, true)
+	} else {
+		startResponseStream(r.Context(), w, auth, planId, branch, true)
+	}
 
 	log.Println("Successfully processed request for ConnectPlanHandler")
 }
@@ -281,7 +300,7 @@ func StopPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanExec(w, planId, auth) == nil {
 		return
 	}
 
@@ -304,6 +323,14 @@ func StopPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 		if err != nil {
 			log.Printf("Error stopping plan: %v\n", err)
+		} else {
+			webhooks.Emit(webhooks.EmitParams{
+				Type:   webhooks.EventPlanStopped,
+				OrgId:  auth.OrgId,
+				UserId: auth.User.Id,
+				PlanId: planId,
+				Branch: branch,
+			})
 		}
 
 		log.Println("Successfully processed request for StopPlanHandler")
@@ -358,7 +385,7 @@ func RespondMissingFileHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanExec(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -453,7 +480,7 @@ func AutoLoadContextHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanExec(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -615,18 +642,3 @@ func GetBuildStatusHandler(w http.ResponseWriter, r *http.Request) {
 
 	// log.Println("Successfully processed request for GetBuildStatusHandler")
 }
-
-func authorizePlanExecUpdate(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
-	plan := authorizePlan(w, planId, auth)
-	if plan == nil {
-		return nil
-	}
-
-	if plan.OwnerId != auth.User.Id && !auth.HasPermission(shared.PermissionUpdateAnyPlan) {
-		log.Println("User does not have permission to update plan")
-		http.Error(w, "User does not have permission to update plan", http.StatusForbidden)
-		return nil
-	}
-
-	return plan
-}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	"plandex-server/db"
+	"plandex-server/webhooks"
 
 	shared "plandex-shared"
 
@@ -93,7 +94,7 @@ func RewindPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanExec(w, planId, auth) == nil {
 		return
 	}
 
@@ -134,6 +135,13 @@ func RewindPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action: shared.AuditActionPlanRewound,
+		planId: planId,
+		branch: branch,
+		data:   map[string]string{"sha": requestBody.Sha},
+	})
+
 	err = db.SyncPlanTokens(auth.OrgId, planId, branch)
 
 	if err != nil {
@@ -178,6 +186,19 @@ func RewindPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	webhooks.Emit(webhooks.EmitParams{
+		Type:   webhooks.EventPlanRewound,
+		OrgId:  auth.OrgId,
+		UserId: auth.User.Id,
+		PlanId: planId,
+		Branch: branch,
+		Data: map[string]interface{}{
+			"sha":          requestBody.Sha,
+			"latestSha":    sha,
+			"latestCommit": latest,
+		},
+	})
+
 	w.Write(bytes)
 
 	log.Println("Successfully processed request for RewindPlanHandler")
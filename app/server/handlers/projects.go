@@ -22,6 +22,12 @@ func CreateProjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if auth.ApiToken != nil && auth.ApiToken.ProjectId != nil {
+		log.Println("API token is scoped to a project")
+		http.Error(w, "API token is scoped to a single project and can't create projects", http.StatusForbidden)
+		return
+	}
+
 	// read the request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -107,6 +113,9 @@ func ListProjectsHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Error scanning project: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
+		if !auth.CanAccessProject(project.Id) {
+			continue
+		}
 		projects = append(projects, project)
 	}
 
@@ -10,6 +10,7 @@ import (
 	"os"
 	"plandex-server/db"
 	"plandex-server/email"
+	"strconv"
 	"strings"
 
 	shared "plandex-shared"
@@ -182,6 +183,10 @@ func CreateSignInCodeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !requireSessionAuth(w, auth) {
+		return
+	}
+
 	// create pin - 6 alphanumeric characters
 	pinBytes, err := shared.GetRandomAlphanumeric(6)
 	if err != nil {
@@ -257,6 +262,10 @@ func SignOutHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !requireSessionAuth(w, auth) {
+		return
+	}
+
 	_, err := db.Conn.Exec("UPDATE auth_tokens SET deleted_at = NOW() WHERE token_hash = $1", auth.AuthToken.TokenHash)
 
 	if err != nil {
@@ -342,5 +351,10 @@ func UpdateOrgUserConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action: shared.AuditActionOrgUserConfigUpdated,
+		data:   map[string]string{"useClaudeSubscription": strconv.FormatBool(req.UseClaudeSubscription)},
+	})
+
 	log.Println("Successfully updated org user config")
 }
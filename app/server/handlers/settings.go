@@ -91,7 +91,7 @@ func UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanExec(w, planId, auth)
 
 	if plan == nil {
 		return
@@ -205,6 +205,13 @@ func UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action: shared.AuditActionPlanSettingsUpdated,
+		planId: planId,
+		branch: branch,
+		data:   map[string]string{"modelPack": updateSettingsModelPackName(req)},
+	})
+
 	res := shared.UpdateSettingsResponse{
 		Msg: commitMsg,
 	}
@@ -314,6 +321,15 @@ func UpdateDefaultSettingsHandler(w http.ResponseWriter, r *http.Request) {
 			return fmt.Errorf("error storing default settings: %v", err)
 		}
 
+		err = recordAuditTx(r, auth, auditParams{
+			action: shared.AuditActionDefaultSettingsUpdated,
+			data:   map[string]string{"modelPack": updateSettingsModelPackName(req)},
+		}, tx)
+
+		if err != nil {
+			return fmt.Errorf("error recording audit log entry: %v", err)
+		}
+
 		return nil
 	})
 
@@ -539,3 +555,10 @@ func short(v reflect.Value) string {
 		return fmt.Sprintf("%v", v.Interface())
 	}
 }
+
+func updateSettingsModelPackName(req shared.UpdateSettingsRequest) string {
+	if req.ModelPack != nil {
+		return req.ModelPack.Name
+	}
+	return req.ModelPackName
+}
@@ -51,7 +51,9 @@ func startResponseStream(reqCtx context.Context, w http.ResponseWriter, auth *ty
 
 	if isConnect {
 		time.Sleep(100 * time.Millisecond)
-		err = initConnectActive(auth, planId, branch, w)
+		err = initConnectActive(auth, planId, branch, func(msg string) error {
+			return sendStreamMessage(w, msg)
+		})
 
 		if err != nil {
 			log.Println("Response stream manager: error initializing connection to active plan:", err)
@@ -98,9 +100,9 @@ func startResponseStream(reqCtx context.Context, w http.ResponseWriter, auth *ty
 			if err != nil {
 				return
 			}
-		case msg := <-ch:
-			// log.Println("Response stream manager: sending message:", msg)
-			err = sendStreamMessage(w, msg)
+		case event := <-ch:
+			// log.Println("Response stream manager: sending message:", event.Data)
+			err = sendStreamMessage(w, event.Data)
 			if err != nil {
 				return
 			}
@@ -124,7 +126,7 @@ func sendStreamMessage(w http.ResponseWriter, msg string) error {
 	return nil
 }
 
-func initConnectActive(auth *types.ServerAuth, planId, branch string, w http.ResponseWriter) error {
+func initConnectActive(auth *types.ServerAuth, planId, branch string, send func(msg string) error) error {
 	log.Println("Response stream manager: initializing connection to active plan")
 
 	active := modelPlan.GetActivePlan(planId, branch)
@@ -178,7 +180,7 @@ func initConnectActive(auth *types.ServerAuth, planId, branch string, w http.Res
 	}
 
 	log.Println("Response stream manager: sending connect message")
-	err = sendStreamMessage(w, string(bytes))
+	err = send(string(bytes))
 
 	if err != nil {
 		return fmt.Errorf("error sending connect message: %v", err)
@@ -214,7 +216,7 @@ func initConnectActive(auth *types.ServerAuth, planId, branch string, w http.Res
 				return fmt.Errorf("error marshalling message: %v", err)
 			}
 
-			err = sendStreamMessage(w, string(bytes))
+			err = send(string(bytes))
 
 			if err != nil {
 				return fmt.Errorf("error sending message: %v", err)
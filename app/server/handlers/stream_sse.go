@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	modelPlan "plandex-server/model/plan"
+	"plandex-server/types"
+	"strconv"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+// isEventStreamRequest returns true if the client asked for a Server-Sent Events stream instead of the CLI's streaming format
+func isEventStreamRequest(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// event ids are '<streamId>:<seq>' so that a Last-Event-ID from a previous run of the plan isn't mistaken for a position in the current one
+func formatEventId(streamId string, seq int64) string {
+	return fmt.Sprintf("%s:%d", streamId, seq)
+}
+
+func parseEventId(eventId string) (string, int64, bool) {
+	idx := strings.LastIndex(eventId, ":")
+	if idx == -1 {
+		return "", 0, false
+	}
+
+	seq, err := strconv.ParseInt(eventId[idx+1:], 10, 64)
+	if err != nil || seq < 0 {
+		return "", 0, false
+	}
+
+	return eventId[:idx], seq, true
+}
+
+func startEventStream(w http.ResponseWriter, r *http.Request, auth *types.ServerAuth, planId, branch string, isConnect bool) {
+	log.Println("Event stream manager: starting plan event stream")
+
+	reqCtx := r.Context()
+	active := modelPlan.GetActivePlan(planId, branch)
+
+	if active == nil {
+		log.Printf("Event stream manager: active plan not found for plan ID %s on branch %s\n", planId, branch)
+		http.Error(w, "Active plan not found", http.StatusNotFound)
+		return
+	}
+
+	// new tell and build streams get everything sent since the plan became active, connections get a snapshot of the current state followed by new events, and reconnections resume after the last event received
+	var afterSeq int64
+	sendSnapshot := false
+	if isConnect {
+		afterSeq = -1
+		sendSnapshot = true
+	}
+
+	lastEventId := r.Header.Get("Last-Event-ID")
+	if lastEventId != "" {
+		streamId, seq, ok := parseEventId(lastEventId)
+		if ok && streamId == active.StreamId {
+			afterSeq = seq
+			sendSnapshot = false
+		} else {
+			log.Printf("Event stream manager: Last-Event-ID %s doesn't match the current stream, sending a snapshot\n", lastEventId)
+			afterSeq = -1
+			sendSnapshot = true
+		}
+	}
+
+	subscriptionId, backlog, complete, ch := modelPlan.SubscribePlanFrom(reqCtx, planId, branch, afterSeq)
+	if ch == nil {
+		http.Error(w, "Active plan not found", http.StatusNotFound)
+		return
+	}
+	defer func() {
+		log.Println("Event stream manager: client stream closed")
+		modelPlan.UnsubscribePlan(planId, branch, subscriptionId)
+	}()
+
+	if !complete {
+		log.Println("Event stream manager: events after Last-Event-ID are no longer retained, sending a snapshot")
+		backlog = nil
+		sendSnapshot = true
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	bytes, err := json.Marshal(shared.StreamMessage{
+		Type: shared.StreamMessageStart,
+	})
+	if err != nil {
+		log.Printf("Event stream manager: error marshalling message: %v\n", err)
+		return
+	}
+
+	err = sendEvent(w, string(bytes))
+	if err != nil {
+		return
+	}
+
+	if sendSnapshot {
+		err = initConnectActive(auth, planId, branch, func(msg string) error {
+			return sendEvent(w, msg)
+		})
+
+		if err != nil {
+			log.Println("Event stream manager: error initializing connection to active plan:", err)
+			return
+		}
+	}
+
+	for _, event := range backlog {
+		done, err := sendStreamEvent(w, active.StreamId, event)
+		if err != nil || done {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-reqCtx.Done():
+			log.Println("Event stream manager: request context done")
+			return
+		case <-ticker.C:
+			err = writeEventStream(w, ": "+string(shared.StreamMessageHeartbeat)+"\n\n")
+			if err != nil {
+				return
+			}
+		case event := <-ch:
+			done, err := sendStreamEvent(w, active.StreamId, event)
+			if err != nil || done {
+				return
+			}
+		}
+	}
+}
+
+// sendStreamEvent writes a stream event, splitting multi-messages into one event per message. It returns true once the stream has finished, been stopped, or failed, so the response can be closed.
+func sendStreamEvent(w http.ResponseWriter, streamId string, event types.StreamEvent) (bool, error) {
+	var msg shared.StreamMessage
+	err := json.Unmarshal([]byte(event.Data), &msg)
+	if err != nil {
+		log.Printf("Event stream manager: error unmarshalling message: %v\n", err)
+		return false, err
+	}
+
+	msgs := []shared.StreamMessage{msg}
+	if msg.Type == shared.StreamMessageMulti {
+		msgs = msg.StreamMessages
+	}
+
+	id := formatEventId(streamId, event.Seq)
+	done := false
+	var sb strings.Builder
+
+	for _, m := range msgs {
+		bytes, err := json.Marshal(m)
+		if err != nil {
+			log.Printf("Event stream manager: error marshalling message: %v\n", err)
+			return false, err
+		}
+
+		sb.WriteString(formatEvent(id, string(m.Type), string(bytes)))
+
+		if m.Type == shared.StreamMessageFinished || m.Type == shared.StreamMessageAborted || m.Type == shared.StreamMessageError {
+			done = true
+		}
+	}
+
+	err = writeEventStream(w, sb.String())
+	if err != nil {
+		return false, err
+	}
+
+	return done, nil
+}
+
+// sendEvent writes a message without an event id -- used for messages that aren't part of the plan's stream history
+func sendEvent(w http.ResponseWriter, data string) error {
+	var msg shared.StreamMessage
+	err := json.Unmarshal([]byte(data), &msg)
+	if err != nil {
+		log.Printf("Event stream manager: error unmarshalling message: %v\n", err)
+		return err
+	}
+
+	return writeEventStream(w, formatEvent("", string(msg.Type), data))
+}
+
+func formatEvent(id, eventType, data string) string {
+	var sb strings.Builder
+	if id != "" {
+		sb.WriteString("id: " + id + "\n")
+	}
+	sb.WriteString("event: " + eventType + "\n")
+	sb.WriteString("data: " + data + "\n\n")
+	return sb.String()
+}
+
+func writeEventStream(w http.ResponseWriter, s string) error {
+	_, err := w.Write([]byte(s))
+	if err != nil {
+		log.Printf("Event stream manager: error writing to client: %v\n", err)
+		return err
+	} else if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
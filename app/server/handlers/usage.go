@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+func GetUsageReportHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetUsageReportHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if os.Getenv("IS_CLOUD") != "" {
+		log.Println("Usage reports aren't available on Plandex Cloud")
+		http.Error(w, "Usage reports aren't available on Plandex Cloud -- use 'plandex usage' for credits usage", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+
+	by := shared.UsageGroupBy(query.Get("by"))
+	if by == "" {
+		by = shared.UsageGroupByModel
+	}
+	switch by {
+	case shared.UsageGroupByPlan, shared.UsageGroupByUser, shared.UsageGroupByRole, shared.UsageGroupByModel, shared.UsageGroupByDay:
+	default:
+		http.Error(w, "by must be one of: plan, user, role, model, day", http.StatusBadRequest)
+		return
+	}
+
+	params := db.GetUsageReportParams{
+		OrgId:     auth.OrgId,
+		By:        by,
+		PlanId:    query.Get("planId"),
+		SessionId: query.Get("sessionId"),
+	}
+
+	// defaults to the current UTC day so far
+	now := time.Now().UTC()
+	params.Since = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	params.Until = now
+
+	since, ok := parseTimeParam(w, query, "since")
+	if !ok {
+		return
+	}
+	if since != nil {
+		params.Since = *since
+	}
+	until, ok := parseTimeParam(w, query, "until")
+	if !ok {
+		return
+	}
+	if until != nil {
+		params.Until = *until
+	}
+
+	if params.PlanId != "" {
+		if authorizePlan(w, params.PlanId, auth) == nil {
+			return
+		}
+	}
+
+	// without view_org_usage, users only see their own requests
+	allUsers := auth.HasPermission(shared.PermissionViewOrgUsage)
+
+	userEmail := strings.ToLower(strings.TrimSpace(query.Get("userEmail")))
+	if userEmail != "" && userEmail != strings.ToLower(auth.User.Email) {
+		if !allUsers {
+			log.Println("User does not have permission to view other users' usage")
+			http.Error(w, "User does not have permission to view other users' usage", http.StatusForbidden)
+			return
+		}
+
+		user, err := db.GetUserByEmail(userEmail)
+
+		if err != nil {
+			log.Printf("Error getting user: %v\n", err)
+			http.Error(w, "Error getting user: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if user == nil {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+
+		params.UserId = user.Id
+	} else if userEmail != "" || !allUsers {
+		params.UserId = auth.User.Id
+	}
+
+	rows, err := db.GetUsageReport(params)
+
+	if err != nil {
+		log.Printf("Error getting usage report: %v\n", err)
+		http.Error(w, "Error getting usage report: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	totalParams := params
+	totalParams.By = ""
+	totals, err := db.GetUsageReport(totalParams)
+
+	if err != nil {
+		log.Printf("Error getting usage report totals: %v\n", err)
+		http.Error(w, "Error getting usage report totals: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	report := shared.UsageReport{
+		By:       by,
+		Since:    params.Since,
+		Until:    params.Until,
+		Rows:     []*shared.UsageReportRow{},
+		Total:    &shared.UsageReportRow{},
+		AllUsers: params.UserId == "",
+	}
+	for _, row := range rows {
+		report.Rows = append(report.Rows, row.ToApi())
+	}
+	if len(totals) > 0 {
+		report.Total = totals[0].ToApi()
+	}
+
+	bytes, err := json.Marshal(report)
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for GetUsageReportHandler")
+}
@@ -7,7 +7,6 @@ import (
 	"net/http"
 	"os"
 	"plandex-server/db"
-	"strings"
 
 	shared "plandex-shared"
 
@@ -136,10 +135,24 @@ func DeleteOrgUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	orgRole, err := db.GetOrgRole(auth.OrgId, orgUser.OrgRoleId)
+
+	if err != nil || orgRole == nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role", http.StatusInternalServerError)
+		return
+	}
+
 	// ensure current user can remove target user
-	removePermission := shared.Permission(strings.Join([]string{string(shared.PermissionRemoveUser), orgUser.OrgRoleId}, "|"))
+	canRemove, err := hasOrgRolePermission(auth, shared.PermissionRemoveUser, orgRole)
+
+	if err != nil {
+		log.Printf("Error checking remove permission: %v\n", err)
+		http.Error(w, "Error checking remove permission: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	if !auth.HasPermission(removePermission) {
+	if !canRemove {
 		log.Printf("User does not have permission to remove user with role: %v\n", orgUser.OrgRoleId)
 		http.Error(w, "User does not have permission to remove user with role: "+orgUser.OrgRoleId, http.StatusForbidden)
 		return
@@ -185,6 +198,14 @@ func DeleteOrgUserHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	user, err := db.GetUser(userId)
+
+	if err != nil {
+		log.Printf("Error getting user: %v\n", err)
+		http.Error(w, "Error getting user: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	err = db.WithTx(r.Context(), "delete org user", func(tx *sqlx.Tx) error {
 
 		err = db.DeleteOrgUser(auth.OrgId, userId, tx)
@@ -194,6 +215,16 @@ func DeleteOrgUserHandler(w http.ResponseWriter, r *http.Request) {
 			return fmt.Errorf("error deleting org user: %v", err)
 		}
 
+		err = recordAuditTx(r, auth, auditParams{
+			action:   shared.AuditActionUserRemoved,
+			targetId: userId,
+			data:     map[string]string{"email": user.Email, "role": orgRole.Name},
+		}, tx)
+
+		if err != nil {
+			return fmt.Errorf("error recording audit log entry: %v", err)
+		}
+
 		invite, err := db.GetActiveInviteByEmail(auth.OrgId, auth.User.Email)
 
 		if err != nil {
@@ -221,3 +252,150 @@ func DeleteOrgUserHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("Successfully processed request for DeleteOrgUserHandler")
 }
+
+func SetOrgUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for SetOrgUserRoleHandler")
+
+	if os.Getenv("GOENV") == "development" && os.Getenv("LOCAL_MODE") == "1" {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusForbidden,
+			Msg:    "Local mode is not supported for user management",
+		})
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	org, err := db.GetOrg(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org: %v\n", err)
+		http.Error(w, "Error getting org: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if org.IsTrial {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeTrialActionNotAllowed,
+			Status: http.StatusForbidden,
+			Msg:    "Trial user can't set user roles",
+		})
+		return
+	}
+
+	vars := mux.Vars(r)
+	userId := vars["userId"]
+
+	log.Println("userId: ", userId)
+
+	var req shared.SetOrgUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+
+	orgUser, err := db.GetOrgUser(userId, auth.OrgId)
+
+	if err != nil {
+		log.Printf("Error getting org user: %v\n", err)
+		http.Error(w, "Error getting org user: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if orgUser == nil {
+		log.Printf("User %s is not a member of org %s\n", userId, auth.OrgId)
+		http.Error(w, "User "+userId+" is not a member of org "+auth.OrgId, http.StatusNotFound)
+		return
+	}
+
+	// otherwise a user could give themselves a role with more access than they have
+	if userId == auth.User.Id {
+		log.Println("User cannot change their own role")
+		http.Error(w, "You can't change your own role -- ask another org owner or admin", http.StatusForbidden)
+		return
+	}
+
+	currentRole, err := db.GetOrgRole(auth.OrgId, orgUser.OrgRoleId)
+
+	if err != nil || currentRole == nil {
+		log.Printf("Error getting current org role: %v\n", err)
+		http.Error(w, "Error getting current org role", http.StatusInternalServerError)
+		return
+	}
+
+	newRole, err := db.GetOrgRole(auth.OrgId, req.OrgRoleId)
+
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if newRole == nil {
+		log.Printf("Org role not found: %v\n", req.OrgRoleId)
+		http.Error(w, "Org role not found: "+req.OrgRoleId, http.StatusBadRequest)
+		return
+	}
+
+	// the current user needs to be able to set both the user's current role and their new role
+	for _, role := range []*db.OrgRole{currentRole, newRole} {
+		canSet, err := hasOrgRolePermission(auth, shared.PermissionSetUserRole, role)
+
+		if err != nil {
+			log.Printf("Error checking set role permission: %v\n", err)
+			http.Error(w, "Error checking set role permission: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if !canSet {
+			log.Printf("User does not have permission to set role: %v\n", role.Id)
+			http.Error(w, "User does not have permission to set role: "+role.Label, http.StatusForbidden)
+			return
+		}
+	}
+
+	orgOwnerRoleId, err := db.GetOrgOwnerRoleId()
+
+	if err != nil {
+		log.Printf("Error getting org owner role id: %v\n", err)
+		http.Error(w, "Error getting org owner role id: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// verify user isn't the only org owner
+	if currentRole.Id == orgOwnerRoleId && newRole.Id != orgOwnerRoleId {
+		numOwners, err := db.NumUsersWithRole(auth.OrgId, orgOwnerRoleId)
+
+		if err != nil {
+			log.Printf("Error getting number of org owners: %v\n", err)
+			http.Error(w, "Error getting number of org owners: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if numOwners == 1 {
+			log.Println("Cannot change the role of the only org owner")
+			http.Error(w, "Cannot change the role of the only org owner", http.StatusForbidden)
+			return
+		}
+	}
+
+	err = db.SetOrgUserRole(auth.OrgId, userId, newRole.Id)
+
+	if err != nil {
+		log.Printf("Error setting org user role: %v\n", err)
+		http.Error(w, "Error setting org user role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:   shared.AuditActionUserRoleChanged,
+		targetId: userId,
+		data:     map[string]string{"from": currentRole.Name, "to": newRole.Name},
+	})
+
+	log.Println("Successfully processed request for SetOrgUserRoleHandler")
+}
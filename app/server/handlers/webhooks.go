@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/webhooks"
+	"strconv"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func ListWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListWebhookDeliveriesHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageWebhooks) {
+		log.Println("User does not have permission to manage webhooks")
+		http.Error(w, "User does not have permission to manage webhooks", http.StatusForbidden)
+		return
+	}
+
+	query := r.URL.Query()
+
+	var limit int
+	if query.Get("limit") != "" {
+		var err error
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			log.Printf("Invalid limit: %v\n", err)
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	deliveries, err := db.ListWebhookDeliveries(db.ListWebhookDeliveriesParams{
+		OrgId:     auth.OrgId,
+		PlanId:    query.Get("planId"),
+		EventType: query.Get("eventType"),
+		Status:    shared.WebhookDeliveryStatus(query.Get("status")),
+		Limit:     limit,
+	})
+
+	if err != nil {
+		log.Printf("Error listing webhook deliveries: %v\n", err)
+		http.Error(w, "Error listing webhook deliveries: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiDeliveries := []*shared.WebhookDelivery{}
+	for _, delivery := range deliveries {
+		apiDeliveries = append(apiDeliveries, delivery.ToApi())
+	}
+
+	bytes, err := json.Marshal(apiDeliveries)
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for ListWebhookDeliveriesHandler")
+}
+
+func ReplayWebhookDeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ReplayWebhookDeliveryHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageWebhooks) {
+		log.Println("User does not have permission to manage webhooks")
+		http.Error(w, "User does not have permission to manage webhooks", http.StatusForbidden)
+		return
+	}
+
+	vars := mux.Vars(r)
+	deliveryId := vars["deliveryId"]
+
+	log.Println("deliveryId: ", deliveryId)
+
+	delivery, err := db.GetWebhookDelivery(auth.OrgId, deliveryId)
+
+	if err != nil {
+		log.Printf("Error getting webhook delivery: %v\n", err)
+		http.Error(w, "Error getting webhook delivery: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if delivery == nil {
+		log.Println("Webhook delivery not found")
+		http.Error(w, "Webhook delivery not found", http.StatusNotFound)
+		return
+	}
+
+	replay, err := webhooks.Replay(delivery)
+
+	if err != nil {
+		log.Printf("Error replaying webhook delivery: %v\n", err)
+		http.Error(w, "Error replaying webhook delivery: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bytes, err := json.Marshal(replay.ToApi())
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for ReplayWebhookDeliveryHandler")
+}
@@ -0,0 +1,67 @@
+package hooks
+
+import (
+	"fmt"
+	"log"
+	"plandex-server/budgets"
+	"plandex-server/notify"
+
+	shared "plandex-shared"
+)
+
+// execBudgets checks model requests against the org's budgets before they're sent, and records their usage after
+func execBudgets(name string, params HookParams) *shared.ApiError {
+	if !budgets.Enabled() || params.Auth == nil || params.Auth.User == nil {
+		return nil
+	}
+
+	var planId string
+	if params.Plan != nil {
+		planId = params.Plan.Id
+	}
+
+	switch name {
+	case WillSendModelRequest:
+		p := params.WillSendModelRequestParams
+		if p == nil {
+			return nil
+		}
+
+		return budgets.Check(budgets.CheckParams{
+			OrgId:        params.Auth.OrgId,
+			UserId:       params.Auth.User.Id,
+			PlanId:       planId,
+			InputTokens:  p.InputTokens,
+			OutputTokens: p.OutputTokens,
+			ModelConfig:  p.BaseModelConfig,
+		})
+
+	case DidSendModelRequest:
+		p := params.DidSendModelRequestParams
+		if p == nil {
+			return nil
+		}
+		// requests without a plan, like naming or summarizing, still count against org and user budgets
+		if planId == "" {
+			planId = p.PlanId
+		}
+
+		err := budgets.Record(budgets.RecordParams{
+			OrgId:        params.Auth.OrgId,
+			UserId:       params.Auth.User.Id,
+			PlanId:       planId,
+			InputTokens:  p.InputTokens,
+			CachedTokens: p.CachedTokens,
+			OutputTokens: p.OutputTokens,
+			ModelConfig:  p.BaseModelConfig,
+		})
+
+		// the request already happened, so a failure to record it shouldn't fail the caller
+		if err != nil {
+			log.Printf("Error recording model spend: %v\n", err)
+			go notify.NotifyErr(notify.SeverityError, fmt.Errorf("error recording model spend: %v", err))
+		}
+	}
+
+	return nil
+}
@@ -36,6 +36,8 @@ type WillSendModelRequestParams struct {
 	IsUserPrompt bool
 	ModelTag     shared.ModelTag
 	ModelId      shared.ModelId
+
+	BaseModelConfig *shared.BaseModelConfig
 }
 
 type DidSendModelRequestParams struct {
@@ -67,6 +69,7 @@ type DidSendModelRequestParams struct {
 	Req              *types.ExtendedChatCompletionRequest
 	Res              *openai.ChatCompletionResponse
 	ModelConfig      *shared.ModelRoleConfig
+	BaseModelConfig  *shared.BaseModelConfig
 }
 
 type DidFinishBuilderRunParams struct {
@@ -176,11 +179,26 @@ func RegisterHook(name string, hook Hook) {
 }
 
 func ExecHook(name string, params HookParams) (HookResult, *shared.ApiError) {
+	var res HookResult
+
+	apiErr := execBudgets(name, params)
+	if apiErr != nil {
+		return res, apiErr
+	}
+
 	hook, ok := hooks[name]
-	if !ok {
-		return HookResult{}, nil
+	if ok {
+		var apiErr *shared.ApiError
+		res, apiErr = hook(params)
+		if apiErr != nil {
+			return res, apiErr
+		}
 	}
-	return hook(params)
+
+	execUsage(name, params)
+	emitWebhook(name, params)
+
+	return res, nil
 }
 
 func TestUpdate() {
@@ -0,0 +1,87 @@
+package hooks
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"plandex-server/db"
+	"plandex-server/notify"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// execUsage records each model request on self-hosted servers so usage reports don't depend on Plandex Cloud
+func execUsage(name string, params HookParams) {
+	if name != DidSendModelRequest || os.Getenv("IS_CLOUD") != "" {
+		return
+	}
+
+	req := newModelRequest(params, time.Now())
+	if req == nil {
+		return
+	}
+
+	// the request already happened, so a failure to record it shouldn't fail the caller
+	err := db.CreateModelRequest(req)
+	if err != nil {
+		log.Printf("Error recording model request: %v\n", err)
+		go notify.NotifyErr(notify.SeverityError, fmt.Errorf("error recording model request: %v", err))
+	}
+}
+
+// newModelRequest is the model_requests row for a finished model request, or nil if there's no request or user to record it for
+func newModelRequest(params HookParams, now time.Time) *db.ModelRequest {
+	p := params.DidSendModelRequestParams
+	if p == nil || params.Auth == nil || params.Auth.User == nil {
+		return nil
+	}
+
+	req := &db.ModelRequest{
+		OrgId:           params.Auth.OrgId,
+		UserId:          params.Auth.User.Id,
+		ModelId:         p.ModelId,
+		ModelName:       p.ModelName,
+		ModelProvider:   p.ModelProvider,
+		ModelRole:       p.ModelRole,
+		ModelPackName:   p.ModelPackName,
+		Purpose:         p.Purpose,
+		InputTokens:     p.InputTokens,
+		CachedTokens:    p.CachedTokens,
+		OutputTokens:    p.OutputTokens,
+		Cost:            decimal.Zero,
+		Streaming:       p.Streaming,
+		StoppedEarly:    p.StoppedEarly,
+		UserCancelled:   p.UserCancelled,
+		HadError:        p.HadError,
+		NoReportedUsage: p.NoReportedUsage,
+	}
+
+	planId := p.PlanId
+	if planId == "" && params.Plan != nil {
+		planId = params.Plan.Id
+	}
+	if planId != "" {
+		req.PlanId = &planId
+	}
+	if p.SessionId != "" {
+		req.SessionId = &p.SessionId
+	}
+	if p.GenerationId != "" {
+		req.GenerationId = &p.GenerationId
+	}
+	if p.BaseModelConfig != nil {
+		req.Cost = p.BaseModelConfig.GetCost(p.InputTokens, p.CachedTokens, p.OutputTokens)
+	}
+	if !p.RequestStartedAt.IsZero() {
+		latencyMs := int(now.Sub(p.RequestStartedAt).Milliseconds())
+		req.LatencyMs = &latencyMs
+
+		if !p.FirstTokenAt.IsZero() {
+			firstTokenMs := int(p.FirstTokenAt.Sub(p.RequestStartedAt).Milliseconds())
+			req.FirstTokenMs = &firstTokenMs
+		}
+	}
+
+	return req
+}
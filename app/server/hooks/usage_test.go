@@ -0,0 +1,81 @@
+package hooks
+
+import (
+	"plandex-server/db"
+	"plandex-server/types"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+)
+
+func TestNewModelRequest(t *testing.T) {
+	auth := &types.ServerAuth{OrgId: "org", User: &db.User{Id: "user"}}
+	started := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	config := &shared.BaseModelConfig{ModelId: "model"}
+	config.InputCostPerMillion = 2
+	config.OutputCostPerMillion = 8
+
+	req := newModelRequest(HookParams{
+		Auth: auth,
+		Plan: &db.Plan{Id: "plan"},
+		DidSendModelRequestParams: &DidSendModelRequestParams{
+			InputTokens:      1000000,
+			OutputTokens:     500000,
+			ModelId:          "model",
+			ModelRole:        shared.ModelRolePlanner,
+			SessionId:        "session",
+			GenerationId:     "gen",
+			HadError:         true,
+			RequestStartedAt: started,
+			FirstTokenAt:     started.Add(300 * time.Millisecond),
+			BaseModelConfig:  config,
+		},
+	}, started.Add(2*time.Second))
+
+	if req == nil {
+		t.Fatal("expected a row")
+	}
+	if req.OrgId != "org" || req.UserId != "user" || req.ModelRole != shared.ModelRolePlanner || !req.HadError {
+		t.Fatalf("unexpected row: %+v", req)
+	}
+	if req.PlanId == nil || *req.PlanId != "plan" {
+		t.Fatalf("plan id not taken from the plan: %v", req.PlanId)
+	}
+	if req.SessionId == nil || *req.SessionId != "session" || req.GenerationId == nil || *req.GenerationId != "gen" {
+		t.Fatalf("unexpected session or generation id: %+v", req)
+	}
+	if req.Cost.String() != "6" {
+		t.Fatalf("got cost %s, want 6", req.Cost)
+	}
+	if req.LatencyMs == nil || *req.LatencyMs != 2000 || req.FirstTokenMs == nil || *req.FirstTokenMs != 300 {
+		t.Fatalf("unexpected latency: %v %v", req.LatencyMs, req.FirstTokenMs)
+	}
+}
+
+func TestNewModelRequestWithoutPlan(t *testing.T) {
+	auth := &types.ServerAuth{OrgId: "org", User: &db.User{Id: "user"}}
+
+	req := newModelRequest(HookParams{
+		Auth:                      auth,
+		DidSendModelRequestParams: &DidSendModelRequestParams{InputTokens: 10, ModelId: "model"},
+	}, time.Now())
+
+	if req == nil {
+		t.Fatal("expected a row for a request without a plan")
+	}
+	if req.PlanId != nil || req.SessionId != nil || req.GenerationId != nil {
+		t.Fatalf("expected empty ids to be null: %+v", req)
+	}
+	if !req.Cost.IsZero() || req.LatencyMs != nil || req.FirstTokenMs != nil {
+		t.Fatalf("expected no cost or latency: %+v", req)
+	}
+
+	if newModelRequest(HookParams{Auth: auth}, time.Now()) != nil {
+		t.Fatal("expected no row without request params")
+	}
+	if newModelRequest(HookParams{DidSendModelRequestParams: &DidSendModelRequestParams{}}, time.Now()) != nil {
+		t.Fatal("expected no row without a user")
+	}
+}
@@ -0,0 +1,135 @@
+package hooks
+
+import (
+	"plandex-server/webhooks"
+	"time"
+
+	shared "plandex-shared"
+)
+
+// payloads for hook points that are also delivered as outbound webhooks
+// request/response bodies and model output are intentionally left out
+
+type modelRequestWebhookData struct {
+	InputTokens    int                  `json:"inputTokens"`
+	OutputTokens   int                  `json:"outputTokens"`
+	CachedTokens   int                  `json:"cachedTokens"`
+	ModelId        shared.ModelId       `json:"modelId"`
+	ModelName      shared.ModelName     `json:"modelName"`
+	ModelProvider  shared.ModelProvider `json:"modelProvider"`
+	ModelRole      shared.ModelRole     `json:"modelRole"`
+	ModelPackName  string               `json:"modelPackName"`
+	Purpose        string               `json:"purpose"`
+	ConvoMessageId string               `json:"convoMessageId,omitempty"`
+	BuildId        string               `json:"buildId,omitempty"`
+	StoppedEarly   bool                 `json:"stoppedEarly"`
+	UserCancelled  bool                 `json:"userCancelled"`
+	HadError       bool                 `json:"hadError"`
+	Streaming      bool                 `json:"streaming"`
+	SessionId      string               `json:"sessionId,omitempty"`
+	LatencyMs      int64                `json:"latencyMs,omitempty"`
+	FirstTokenMs   int64                `json:"firstTokenMs,omitempty"`
+	RequestStarted time.Time            `json:"requestStartedAt"`
+}
+
+type builderRunWebhookData struct {
+	FilePath           string    `json:"filePath"`
+	Lang               string    `json:"lang"`
+	AutoApplySuccess   bool      `json:"autoApplySuccess"`
+	DidReplacement     bool      `json:"didReplacement"`
+	ReplacementSuccess bool      `json:"replacementSuccess"`
+	DidFastApply       bool      `json:"didFastApply"`
+	FastApplySuccess   bool      `json:"fastApplySuccess"`
+	BuiltWholeFile     bool      `json:"builtWholeFile"`
+	StartedAt          time.Time `json:"startedAt"`
+	FinishedAt         time.Time `json:"finishedAt"`
+}
+
+func emitWebhook(name string, params HookParams) {
+	if !webhooks.Enabled() {
+		return
+	}
+
+	emitParams := webhooks.EmitParams{}
+
+	if params.Auth != nil {
+		emitParams.OrgId = params.Auth.OrgId
+		if params.Auth.User != nil {
+			emitParams.UserId = params.Auth.User.Id
+		}
+	}
+
+	if params.Plan != nil {
+		emitParams.OrgId = params.Plan.OrgId
+		emitParams.PlanId = params.Plan.Id
+	}
+
+	switch name {
+	case WillTellPlan:
+		emitParams.Type = webhooks.EventPlanWillTell
+
+	case DidSendModelRequest:
+		p := params.DidSendModelRequestParams
+		if p == nil {
+			return
+		}
+		emitParams.Type = webhooks.EventModelRequestSent
+		if emitParams.PlanId == "" {
+			emitParams.PlanId = p.PlanId
+		}
+
+		data := modelRequestWebhookData{
+			InputTokens:    p.InputTokens,
+			OutputTokens:   p.OutputTokens,
+			CachedTokens:   p.CachedTokens,
+			ModelId:        p.ModelId,
+			ModelName:      p.ModelName,
+			ModelProvider:  p.ModelProvider,
+			ModelRole:      p.ModelRole,
+			ModelPackName:  p.ModelPackName,
+			Purpose:        p.Purpose,
+			ConvoMessageId: p.ConvoMessageId,
+			BuildId:        p.BuildId,
+			StoppedEarly:   p.StoppedEarly,
+			UserCancelled:  p.UserCancelled,
+			HadError:       p.HadError,
+			Streaming:      p.Streaming,
+			SessionId:      p.SessionId,
+			RequestStarted: p.RequestStartedAt,
+		}
+		if !p.RequestStartedAt.IsZero() {
+			data.LatencyMs = time.Since(p.RequestStartedAt).Milliseconds()
+			if !p.FirstTokenAt.IsZero() {
+				data.FirstTokenMs = p.FirstTokenAt.Sub(p.RequestStartedAt).Milliseconds()
+			}
+		}
+		emitParams.Data = data
+
+	case DidFinishBuilderRun:
+		p := params.DidFinishBuilderRunParams
+		if p == nil {
+			return
+		}
+		emitParams.Type = webhooks.EventBuilderRunFinished
+		if emitParams.PlanId == "" {
+			emitParams.PlanId = p.PlanId
+		}
+		emitParams.Data = builderRunWebhookData{
+			FilePath:           p.FilePath,
+			Lang:               p.Lang,
+			AutoApplySuccess:   p.AutoApplySuccess,
+			DidReplacement:     p.DidReplacement,
+			ReplacementSuccess: p.ReplacementSuccess,
+			DidFastApply:       p.DidFastApply,
+			FastApplySuccess:   p.FastApplySuccess,
+			BuiltWholeFile:     p.BuiltWholeFile,
+			StartedAt:          p.StartedAt,
+			FinishedAt:         p.FinishedAt,
+		}
+
+	default:
+		return
+	}
+
+	webhooks.Emit(emitParams)
+}
@@ -33,6 +33,7 @@ func main() {
 	routes.AddProxyableApiRoutes(r)
 	setup.MustLoadIp()
 	setup.MustInitDb()
+	setup.MustLoadWebhooks()
 	setup.StartServer(r, nil, nil)
 	os.Exit(0)
 }
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'manage_webhooks';
+
+DROP TABLE IF EXISTS webhook_deliveries;
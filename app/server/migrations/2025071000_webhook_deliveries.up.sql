@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  endpoint_id VARCHAR(255) NOT NULL,
+  url TEXT NOT NULL,
+  event_id UUID NOT NULL,
+  event_type VARCHAR(255) NOT NULL,
+  plan_id UUID,
+  payload JSON NOT NULL,
+  status VARCHAR(32) NOT NULL DEFAULT 'pending',
+  num_attempts INTEGER NOT NULL DEFAULT 0,
+  last_status_code INTEGER,
+  last_error TEXT,
+  replay_of_id UUID REFERENCES webhook_deliveries(id) ON DELETE SET NULL,
+  next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  locked_at TIMESTAMP,
+  delivered_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_webhook_deliveries_modtime BEFORE UPDATE ON webhook_deliveries FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries(status, next_attempt_at);
+CREATE INDEX webhook_deliveries_org_idx ON webhook_deliveries(org_id, created_at DESC);
+
+INSERT INTO permissions (name, description, resource_id) VALUES
+  ('manage_webhooks', 'View and replay webhook deliveries', NULL);
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name IN ('owner', 'admin')
+    AND p.name = 'manage_webhooks';
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'manage_api_tokens';
+
+DROP TABLE IF EXISTS api_tokens;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS plan_shares (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  plan_id UUID NOT NULL REFERENCES plans(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role VARCHAR(32) NOT NULL,
+  shared_by_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_plan_shares_modtime BEFORE UPDATE ON plan_shares FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE UNIQUE INDEX plan_shares_plan_user_idx ON plan_shares(plan_id, user_id);
+CREATE INDEX plan_shares_user_idx ON plan_shares(user_id);
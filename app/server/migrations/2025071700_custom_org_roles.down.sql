@@ -0,0 +1,10 @@
+-- move users and invites with custom roles back to the member role before removing custom roles
+UPDATE orgs_users SET org_role_id = (SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'member')
+WHERE org_role_id IN (SELECT id FROM org_roles WHERE org_id IS NOT NULL);
+
+UPDATE invites SET org_role_id = (SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'member')
+WHERE org_role_id IN (SELECT id FROM org_roles WHERE org_id IS NOT NULL);
+
+DELETE FROM org_roles WHERE org_id IS NOT NULL;
+
+DELETE FROM permissions WHERE name IN ('manage_org_roles', 'manage_model_packs', 'manage_custom_providers', 'view_billing', 'exec_commands');
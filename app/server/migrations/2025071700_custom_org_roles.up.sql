@@ -0,0 +1,29 @@
+INSERT INTO permissions (name, description, resource_id) VALUES
+  ('manage_org_roles', 'Create, update and delete custom org roles', NULL),
+  ('manage_model_packs', 'Create, update and delete custom models and model packs', NULL),
+  ('manage_custom_providers', 'Create, update and delete custom model providers', NULL),
+  ('view_billing', 'View an org''s billing details', NULL),
+  ('exec_commands', 'Allow plans to execute commands', NULL);
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name IN ('owner', 'admin')
+    AND p.name IN ('manage_org_roles', 'manage_model_packs', 'manage_custom_providers', 'view_billing', 'exec_commands');
+
+-- members could already edit models and execute commands, so they keep those permissions
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name = 'member'
+    AND p.name IN ('manage_model_packs', 'manage_custom_providers', 'exec_commands');
@@ -0,0 +1,45 @@
+-- org_id and user_id intentionally have no foreign keys so entries outlive the users and plans they describe
+CREATE TABLE IF NOT EXISTS audit_log_entries (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL,
+  user_id UUID NOT NULL,
+  user_email VARCHAR(255) NOT NULL,
+  user_name VARCHAR(255) NOT NULL,
+  api_token_id UUID,
+  action VARCHAR(64) NOT NULL,
+  plan_id UUID,
+  branch VARCHAR(255),
+  target_id VARCHAR(255),
+  data JSON,
+  ip_address VARCHAR(64) NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX audit_log_entries_org_idx ON audit_log_entries(org_id, created_at DESC);
+CREATE INDEX audit_log_entries_org_user_idx ON audit_log_entries(org_id, user_id, created_at DESC);
+CREATE INDEX audit_log_entries_org_plan_idx ON audit_log_entries(org_id, plan_id, created_at DESC);
+
+-- the audit log is append-only
+CREATE OR REPLACE FUNCTION prevent_audit_log_changes() RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log_entries is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_entries_append_only BEFORE UPDATE OR DELETE ON audit_log_entries FOR EACH ROW EXECUTE FUNCTION prevent_audit_log_changes();
+
+CREATE TRIGGER audit_log_entries_no_truncate BEFORE TRUNCATE ON audit_log_entries FOR EACH STATEMENT EXECUTE FUNCTION prevent_audit_log_changes();
+
+INSERT INTO permissions (name, description, resource_id) VALUES
+  ('view_audit_log', 'View the org audit log', NULL);
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name IN ('owner', 'admin')
+    AND p.name = 'view_audit_log';
@@ -0,0 +1,9 @@
+DELETE FROM permissions WHERE name = 'manage_budgets';
+
+DROP TABLE IF EXISTS model_spend_daily;
+DROP TABLE IF EXISTS budgets;
+
+ALTER TABLE custom_models
+  DROP COLUMN IF EXISTS input_cost_per_million,
+  DROP COLUMN IF EXISTS cached_input_cost_per_million,
+  DROP COLUMN IF EXISTS output_cost_per_million;
@@ -0,0 +1,53 @@
+ALTER TABLE custom_models
+  ADD COLUMN input_cost_per_million FLOAT NOT NULL DEFAULT 0.0,
+  ADD COLUMN cached_input_cost_per_million FLOAT NOT NULL DEFAULT 0.0,
+  ADD COLUMN output_cost_per_million FLOAT NOT NULL DEFAULT 0.0;
+
+-- a null user_id or plan_id on a user or plan budget means it applies to each user or plan in the org
+CREATE TABLE IF NOT EXISTS budgets (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  scope VARCHAR(16) NOT NULL,
+  user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+  plan_id UUID REFERENCES plans(id) ON DELETE CASCADE,
+  period VARCHAR(16) NOT NULL,
+  max_tokens BIGINT,
+  max_cost NUMERIC(14, 6),
+  action VARCHAR(16) NOT NULL DEFAULT 'block',
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_budgets_modtime BEFORE UPDATE ON budgets FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE UNIQUE INDEX budgets_target_idx ON budgets(org_id, scope, (COALESCE(user_id::text, '')), (COALESCE(plan_id::text, '')), period);
+
+-- daily rollup of model usage that budgets are checked against
+-- user_id and plan_id intentionally have no foreign keys so spend still counts after a plan is deleted
+-- spend on requests that aren't part of a plan is recorded with the nil uuid as plan_id
+CREATE TABLE IF NOT EXISTS model_spend_daily (
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL,
+  plan_id UUID NOT NULL,
+  day DATE NOT NULL,
+  input_tokens BIGINT NOT NULL DEFAULT 0,
+  cached_tokens BIGINT NOT NULL DEFAULT 0,
+  output_tokens BIGINT NOT NULL DEFAULT 0,
+  cost NUMERIC(14, 6) NOT NULL DEFAULT 0,
+  PRIMARY KEY (org_id, user_id, plan_id, day)
+);
+
+CREATE INDEX model_spend_daily_org_day_idx ON model_spend_daily(org_id, day);
+
+INSERT INTO permissions (name, description, resource_id) VALUES
+  ('manage_budgets', 'Set and remove model spend budgets for the org, its users and plans', NULL);
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name IN ('owner', 'admin')
+    AND p.name = 'manage_budgets';
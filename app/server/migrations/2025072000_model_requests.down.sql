@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'view_org_usage';
+
+DROP TABLE IF EXISTS model_requests;
@@ -0,0 +1,46 @@
+-- one row per model request, for usage reports on self-hosted servers
+-- user_id and plan_id intentionally have no foreign keys so usage still counts after a plan is deleted
+CREATE TABLE IF NOT EXISTS model_requests (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL,
+  plan_id UUID,
+  session_id VARCHAR(255),
+  model_id VARCHAR(255) NOT NULL,
+  model_name VARCHAR(255) NOT NULL,
+  model_provider VARCHAR(255) NOT NULL DEFAULT '',
+  model_role VARCHAR(64) NOT NULL DEFAULT '',
+  model_pack_name VARCHAR(255) NOT NULL DEFAULT '',
+  purpose VARCHAR(255) NOT NULL DEFAULT '',
+  generation_id VARCHAR(255),
+  input_tokens INTEGER NOT NULL DEFAULT 0,
+  cached_tokens INTEGER NOT NULL DEFAULT 0,
+  output_tokens INTEGER NOT NULL DEFAULT 0,
+  cost NUMERIC(14, 6) NOT NULL DEFAULT 0,
+  streaming BOOLEAN NOT NULL DEFAULT FALSE,
+  stopped_early BOOLEAN NOT NULL DEFAULT FALSE,
+  user_cancelled BOOLEAN NOT NULL DEFAULT FALSE,
+  had_error BOOLEAN NOT NULL DEFAULT FALSE,
+  no_reported_usage BOOLEAN NOT NULL DEFAULT FALSE,
+  latency_ms INTEGER,
+  first_token_ms INTEGER,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX model_requests_org_idx ON model_requests(org_id, created_at);
+CREATE INDEX model_requests_org_user_idx ON model_requests(org_id, user_id, created_at);
+CREATE INDEX model_requests_org_plan_idx ON model_requests(org_id, plan_id, created_at);
+
+INSERT INTO permissions (name, description, resource_id) VALUES
+  ('view_org_usage', 'View model usage reports for every user in the org', NULL);
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r, permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name IN ('owner', 'admin')
+    AND p.name = 'view_org_usage';
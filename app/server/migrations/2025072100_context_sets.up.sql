@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS context_sets (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  is_shared BOOLEAN NOT NULL DEFAULT FALSE,
+  entries JSON NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_context_sets_modtime BEFORE UPDATE ON context_sets FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE UNIQUE INDEX context_sets_project_owner_name_idx ON context_sets(project_id, owner_id, name);
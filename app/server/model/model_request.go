@@ -113,6 +113,8 @@ func ModelRequest(
 			ModelName:    baseModelConfig.ModelName,
 			ModelId:      baseModelConfig.ModelId,
 			ModelTag:     baseModelConfig.ModelTag,
+
+			BaseModelConfig: baseModelConfig,
 		},
 	})
 
@@ -248,6 +250,7 @@ func ModelRequest(
 				Req:              &req,
 				StreamResult:     res.Content,
 				ModelConfig:      modelConfig,
+				BaseModelConfig:  baseModelConfig,
 				FirstTokenAt:     res.FirstTokenAt,
 				SessionId:        sessionId,
 			},
@@ -143,7 +143,8 @@ func (state *activeBuildStreamState) loadPendingBuilds(sessionId string) (map[st
 	UpdateActivePlan(plan.Id, branch, func(ap *types.ActivePlan) {
 		ap.Contexts = modelContext
 		for _, context := range modelContext {
-			if context.FilePath != "" {
+			// symbol contexts only hold part of a file, so they can't be built against
+			if context.FilePath != "" && context.ContextType != shared.ContextSymbolType {
 				ap.ContextsByPath[context.FilePath] = context
 			}
 		}
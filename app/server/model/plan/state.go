@@ -8,6 +8,7 @@ import (
 	"plandex-server/notify"
 	"plandex-server/shutdown"
 	"plandex-server/types"
+	"plandex-server/webhooks"
 	"strings"
 	"time"
 
@@ -54,6 +55,8 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 						log.Printf("Error setting plan %s status to ready: %v\n", planId, err)
 					}
 
+					emitFinishedWebhooks(activePlan)
+
 					// cancel *after* the DeleteActivePlan call
 					// allows queued operations to complete
 					DeleteActivePlan(orgId, userId, planId, branch)
@@ -69,6 +72,17 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 						log.Printf("Error setting plan %s status to error: %v\n", planId, err)
 					}
 
+					webhooks.Emit(webhooks.EmitParams{
+						Type:   webhooks.EventPlanFailed,
+						OrgId:  orgId,
+						UserId: userId,
+						PlanId: planId,
+						Branch: branch,
+						Data: map[string]interface{}{
+							"error": apiErr.Msg,
+						},
+					})
+
 					log.Println("Sending error message to client")
 					activePlan.Stream(shared.StreamMessage{
 						Type:  shared.StreamMessageError,
@@ -140,10 +154,10 @@ func UpdateActivePlan(planId, branch string, fn func(*types.ActivePlan)) {
 	activePlans.Update(strings.Join([]string{planId, branch}, "|"), fn)
 }
 
-func SubscribePlan(ctx context.Context, planId, branch string) (string, chan string) {
+func SubscribePlan(ctx context.Context, planId, branch string) (string, chan types.StreamEvent) {
 	log.Printf("Subscribing to plan %s\n", planId)
 	var id string
-	var ch chan string
+	var ch chan types.StreamEvent
 
 	activePlan := GetActivePlan(planId, branch)
 	if activePlan == nil {
@@ -157,6 +171,26 @@ func SubscribePlan(ctx context.Context, planId, branch string) (string, chan str
 	return id, ch
 }
 
+// SubscribePlanFrom is like SubscribePlan but also returns retained events after afterSeq -- see ActivePlan.SubscribeFrom
+func SubscribePlanFrom(ctx context.Context, planId, branch string, afterSeq int64) (string, []types.StreamEvent, bool, chan types.StreamEvent) {
+	log.Printf("Subscribing to plan %s from seq %d\n", planId, afterSeq)
+	var id string
+	var backlog []types.StreamEvent
+	var complete bool
+	var ch chan types.StreamEvent
+
+	activePlan := GetActivePlan(planId, branch)
+	if activePlan == nil {
+		log.Printf("SubscribePlanFrom - No active plan found for plan ID %s on branch %s\n", planId, branch)
+		return "", nil, false, nil
+	}
+
+	UpdateActivePlan(planId, branch, func(activePlan *types.ActivePlan) {
+		id, backlog, complete, ch = activePlan.SubscribeFrom(ctx, afterSeq)
+	})
+	return id, backlog, complete, ch
+}
+
 func UnsubscribePlan(planId, branch, subscriptionId string) {
 	log.Printf("UnsubscribePlan %s - %s - %s\n", planId, branch, subscriptionId)
 
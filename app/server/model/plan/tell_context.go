@@ -3,6 +3,7 @@ package plan
 import (
 	"fmt"
 	"log"
+	"plandex-server/db"
 	"plandex-server/types"
 	"regexp"
 	"sort"
@@ -130,10 +131,15 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 			continue
 		}
 
+		body, numTokens := part.Body, part.NumTokens
+		if part.ContextType == shared.ContextMapType {
+			body, numTokens = state.preselectMap(part)
+		}
+
 		toLoadAll = append(toLoadAll, toLoad{
 			FilePath:    part.FilePath,
-			NumTokens:   part.NumTokens,
-			Body:        part.Body,
+			NumTokens:   numTokens,
+			Body:        body,
 			ContextType: part.ContextType,
 			Name:        part.Name,
 			Url:         part.Url,
@@ -259,6 +265,18 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 
 				args = append(args, part.FilePath, body)
 			}
+		} else if part.ContextType == shared.ContextSymbolType {
+			fmtStr = "\n\n- %s | selected definitions, not the full file:\n\n```\n%s\n```"
+			args = append(args, part.Name, part.Body)
+		} else if part.ContextType == shared.ContextGitDiffType {
+			fmtStr = "\n\n- output of `%s`:\n\n```diff\n%s\n```"
+			args = append(args, part.Name, part.Body)
+		} else if part.ContextType == shared.ContextCommandType {
+			fmtStr = "\n\n- current output of `%s`:\n\n```\n%s\n```"
+			args = append(args, part.Name, part.Body)
+		} else if part.ContextType == shared.ContextDocumentType {
+			fmtStr = "\n\n- %s | text extracted from the document, not the original file:\n\n```\n%s\n```"
+			args = append(args, part.FilePath, part.Body)
 		} else if part.ContextType == shared.ContextMapType {
 			fmtStr = "\n\n- %s | map:\n\n```\n%s\n```"
 			args = append(args, part.FilePath, part.Body)
@@ -367,6 +385,40 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 
 var pathRegex = regexp.MustCompile("`(.+?)`")
 
+// preselectMap cuts a project map that's too large for the architect down to the sections for the files the client's search index ranked highest for the prompt
+func (state *activeTellStreamState) preselectMap(part *db.Context) (string, int) {
+	candidates := state.req.SearchCandidates
+	maxTokens := state.settings.GetArchitectEffectiveMaxTokens() / 2
+	if len(candidates) == 0 || len(part.MapParts) == 0 || part.NumTokens <= maxTokens {
+		return part.Body, part.NumTokens
+	}
+
+	selected := shared.FileMapBodies{}
+	tokens := 0
+	for _, candidate := range candidates {
+		mapBody, ok := part.MapParts[candidate.Path]
+		if !ok {
+			continue
+		}
+		mapTokens := shared.GetNumTokensEstimate(mapBody)
+		if tokens+mapTokens > maxTokens {
+			break
+		}
+		selected[candidate.Path] = mapBody
+		tokens += mapTokens
+	}
+
+	if len(selected) == 0 {
+		return part.Body, part.NumTokens
+	}
+
+	log.Printf("Tell plan - preselectMap - map has %d tokens, preselected %d of %d files with search results\n", part.NumTokens, len(selected), len(part.MapParts))
+
+	body := fmt.Sprintf("[The full map of %d files is too large to include. These are the %d files ranked most relevant to the prompt by a search of the project. Other project files can still be loaded by path.]\n", len(part.MapParts), len(selected)) + selected.CombinedMap(part.MapTokens)
+
+	return body, shared.GetNumTokensEstimate(body)
+}
+
 type checkAutoLoadContextResult struct {
 	autoLoadPaths        []string
 	activatePaths        map[string]bool
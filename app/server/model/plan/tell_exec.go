@@ -471,6 +471,8 @@ func execTellPlan(params execTellPlanParams) {
 			ModelId:      baseModelConfig.ModelId,
 			ModelTag:     baseModelConfig.ModelTag,
 			IsUserPrompt: true,
+
+			BaseModelConfig: baseModelConfig,
 		},
 	})
 	if apiErr != nil {
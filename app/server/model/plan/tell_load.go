@@ -150,7 +150,8 @@ func (state *activeTellStreamState) loadTellPlan() error {
 			if iteration > 0 || missingFileResponse != "" {
 				modelContext = active.Contexts
 			} else {
-				res, err := db.GetPlanContexts(currentOrgId, planId, true, false)
+				// map parts are needed to preselect the map with search results
+				res, err := db.GetPlanContexts(currentOrgId, planId, true, len(req.SearchCandidates) > 0)
 				if err != nil {
 					log.Printf("Error getting plan modelContext: %v\n", err)
 					errCh <- fmt.Errorf("error getting plan modelContext: %v", err)
@@ -393,7 +394,7 @@ func (state *activeTellStreamState) loadTellPlan() error {
 			ap.Contexts = state.modelContext
 
 			for _, context := range state.modelContext {
-				if context.FilePath != "" {
+				if context.FilePath != "" && context.ContextType != shared.ContextSymbolType {
 					ap.ContextsByPath[context.FilePath] = context
 				}
 			}
@@ -62,6 +62,7 @@ func (state *activeTellStreamState) handleUsageChunk(usage *openai.Usage) {
 				Req:              state.originalReq,
 				StreamResult:     state.activePlan.CurrentReplyContent,
 				ModelConfig:      state.modelConfig,
+				BaseModelConfig:  baseModelConfig,
 
 				SessionId: sessionId,
 			},
@@ -128,6 +129,7 @@ func (state *activeTellStreamState) execHookOnStop(sendStreamErr bool) {
 				Req:              state.originalReq,
 				StreamResult:     state.activePlan.CurrentReplyContent,
 				ModelConfig:      state.modelConfig,
+				BaseModelConfig:  baseModelConfig,
 
 				SessionId: active.SessionId,
 			},
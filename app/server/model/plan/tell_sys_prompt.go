@@ -70,6 +70,14 @@ func (state *activeTellStreamState) getTellSysPrompt(params getTellSysPromptPara
 					Type: types.CacheControlTypeEphemeral,
 				},
 			})
+
+			// after the cached parts, since the results change with each prompt
+			if len(req.SearchCandidates) > 0 {
+				sysParts = append(sysParts, types.ExtendedChatMessagePart{
+					Type: openai.ChatMessagePartTypeText,
+					Text: prompts.GetSearchCandidatesPrompt(req.SearchCandidates),
+				})
+			}
 		} else if currentStage.PlanningPhase == shared.PlanningPhaseTasks {
 
 			var txt string
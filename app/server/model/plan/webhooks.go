@@ -0,0 +1,47 @@
+package plan
+
+import (
+	"plandex-server/types"
+	"plandex-server/webhooks"
+	"sort"
+)
+
+func emitFinishedWebhooks(activePlan *types.ActivePlan) {
+	if !webhooks.Enabled() {
+		return
+	}
+
+	var builtFiles []string
+	for path := range activePlan.BuiltFiles {
+		builtFiles = append(builtFiles, path)
+	}
+	sort.Strings(builtFiles)
+
+	params := webhooks.EmitParams{
+		OrgId:  activePlan.OrgId,
+		UserId: activePlan.UserId,
+		PlanId: activePlan.Id,
+		Branch: activePlan.Branch,
+	}
+
+	if !activePlan.BuildOnly {
+		tellParams := params
+		tellParams.Type = webhooks.EventPlanTellFinished
+		tellParams.Data = map[string]interface{}{
+			"sessionId":    activePlan.SessionId,
+			"replyIds":     activePlan.StoredReplyIds,
+			"didEditFiles": activePlan.DidEditFiles,
+		}
+		webhooks.Emit(tellParams)
+	}
+
+	if activePlan.BuildOnly || len(builtFiles) > 0 {
+		buildParams := params
+		buildParams.Type = webhooks.EventPlanBuildFinished
+		buildParams.Data = map[string]interface{}{
+			"sessionId":  activePlan.SessionId,
+			"builtFiles": builtFiles,
+		}
+		webhooks.Emit(buildParams)
+	}
+}
@@ -1,6 +1,12 @@
 package prompts
 
-import "strconv"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+)
 
 func GetArchitectContextSummary(tokenLimit int) string {
 	return `
@@ -325,3 +331,25 @@ Important: your response should address the user! Don't say things like "The use
 
 	return s
 }
+
+func GetSearchCandidatesPrompt(candidates []shared.SearchResult) string {
+	s := `
+[SEARCH RESULTS:]
+
+A lexical search of the project's file contents, paths, and definition names ranked these files as the most likely to be relevant to the user's latest prompt, most relevant first. Use them as a starting point when deciding what to load—check the project map for the files and definitions they point to. They are keyword matches, not a judgment of relevance, so skip any that aren't actually related to the task, and load other files from the map as needed.
+
+`
+	for _, candidate := range candidates {
+		if len(candidate.Symbols) > 0 {
+			s += fmt.Sprintf("- %s (matching definitions: %s)\n", candidate.Path, strings.Join(candidate.Symbols, ", "))
+		} else {
+			s += fmt.Sprintf("- %s\n", candidate.Path)
+		}
+	}
+
+	s += `
+[END OF SEARCH RESULTS]
+`
+
+	return s
+}
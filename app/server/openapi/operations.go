@@ -0,0 +1,357 @@
+package openapi
+
+import (
+	"reflect"
+
+	shared "plandex-shared"
+)
+
+type responseKind int
+
+const (
+	responseNone responseKind = iota
+	responseJSON
+	responseText
+	responseStream
+)
+
+type param struct {
+	name  string
+	desc  string
+	array bool
+}
+
+// operation describes a single route. Every route registered in the routes package must have exactly one operation here -- see spec_test.go.
+type operation struct {
+	method  string
+	path    string
+	id      string
+	tag     string
+	summary string
+	noAuth  bool
+	query   []param
+
+	req     reflect.Type
+	resKind responseKind
+	res     reflect.Type
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+var enums = map[reflect.Type][]string{
+	typeOf[shared.ApiErrorType](): {
+		string(shared.ApiErrorTypeInvalidToken),
+		string(shared.ApiErrorTypeAuthOutdated),
+		string(shared.ApiErrorTypeTrialPlansExceeded),
+		string(shared.ApiErrorTypeTrialMessagesExceeded),
+		string(shared.ApiErrorTypeTrialActionNotAllowed),
+		string(shared.ApiErrorTypeContinueNoMessages),
+		string(shared.ApiErrorTypeCloudInsufficientCredits),
+		string(shared.ApiErrorTypeCloudMonthlyMaxReached),
+		string(shared.ApiErrorTypeCloudSubscriptionPaused),
+		string(shared.ApiErrorTypeCloudSubscriptionOverdue),
+		string(shared.ApiErrorTypeBudgetExceeded),
+		string(shared.ApiErrorTypeOther),
+	},
+	typeOf[shared.AuditAction](): {
+		string(shared.AuditActionPlanDeleted),
+		string(shared.AuditActionPlansDeletedAll),
+		string(shared.AuditActionPlanRenamed),
+		string(shared.AuditActionPlanArchived),
+		string(shared.AuditActionPlanUnarchived),
+		string(shared.AuditActionPlanRewound),
+		string(shared.AuditActionPlanShared),
+		string(shared.AuditActionPlanUnshared),
+		string(shared.AuditActionBranchDeleted),
+		string(shared.AuditActionPlanSettingsUpdated),
+		string(shared.AuditActionDefaultSettingsUpdated),
+		string(shared.AuditActionPlanConfigUpdated),
+		string(shared.AuditActionDefaultPlanConfigUpdated),
+		string(shared.AuditActionOrgUserConfigUpdated),
+		string(shared.AuditActionCustomModelsUpdated),
+		string(shared.AuditActionOrgCreated),
+		string(shared.AuditActionUserInvited),
+		string(shared.AuditActionInviteDeleted),
+		string(shared.AuditActionUserRemoved),
+		string(shared.AuditActionUserRoleChanged),
+		string(shared.AuditActionOrgRoleCreated),
+		string(shared.AuditActionOrgRoleUpdated),
+		string(shared.AuditActionOrgRoleDeleted),
+		string(shared.AuditActionApiTokenCreated),
+		string(shared.AuditActionApiTokenRevoked),
+		string(shared.AuditActionBudgetSet),
+		string(shared.AuditActionBudgetDeleted),
+		string(shared.AuditActionExecPolicyUpdated),
+		string(shared.AuditActionExecPolicyDeleted),
+	},
+	typeOf[shared.BudgetAction](): {
+		string(shared.BudgetActionBlock),
+		string(shared.BudgetActionWarn),
+	},
+	typeOf[shared.BudgetPeriod](): {
+		string(shared.BudgetPeriodDaily),
+		string(shared.BudgetPeriodMonthly),
+	},
+	typeOf[shared.BudgetScope](): {
+		string(shared.BudgetScopeOrg),
+		string(shared.BudgetScopeUser),
+		string(shared.BudgetScopePlan),
+	},
+	typeOf[shared.BuildMode](): {
+		string(shared.BuildModeAuto),
+		string(shared.BuildModeNone),
+	},
+	typeOf[shared.ExecPolicyVerdict](): {
+		string(shared.ExecPolicyAllow),
+		string(shared.ExecPolicyConfirm),
+		string(shared.ExecPolicyDeny),
+	},
+	typeOf[shared.Permission](): permissionNames(),
+	typeOf[shared.PlanShareRole](): {
+		string(shared.PlanShareRoleViewer),
+		string(shared.PlanShareRoleCollaborator),
+		string(shared.PlanShareRoleOwner),
+	},
+	typeOf[shared.RespondMissingFileChoice](): {
+		string(shared.RespondMissingFileChoiceLoad),
+		string(shared.RespondMissingFileChoiceSkip),
+		string(shared.RespondMissingFileChoiceOverwrite),
+	},
+	typeOf[shared.StreamMessageType](): {
+		string(shared.StreamMessageStart),
+		string(shared.StreamMessageConnectActive),
+		string(shared.StreamMessageHeartbeat),
+		string(shared.StreamMessageReply),
+		string(shared.StreamMessageDescribing),
+		string(shared.StreamMessageRepliesFinished),
+		string(shared.StreamMessageBuildInfo),
+		string(shared.StreamMessagePromptMissingFile),
+		string(shared.StreamMessageLoadContext),
+		string(shared.StreamMessageAborted),
+		string(shared.StreamMessageFinished),
+		string(shared.StreamMessageError),
+		string(shared.StreamMessageMulti),
+	},
+	typeOf[shared.UsageGroupBy](): {
+		string(shared.UsageGroupByPlan),
+		string(shared.UsageGroupByUser),
+		string(shared.UsageGroupByRole),
+		string(shared.UsageGroupByModel),
+		string(shared.UsageGroupByDay),
+	},
+	typeOf[shared.WebhookDeliveryStatus](): {
+		string(shared.WebhookDeliveryStatusPending),
+		string(shared.WebhookDeliveryStatusInProgress),
+		string(shared.WebhookDeliveryStatusDelivered),
+		string(shared.WebhookDeliveryStatusFailed),
+	},
+}
+
+func permissionNames() []string {
+	var names []string
+	for _, permission := range shared.AllPermissions {
+		names = append(names, string(permission))
+	}
+	return names
+}
+
+// extraSchemas are request/response types that aren't used by any self-hosted route (cloud-only or used inside other messages) but are still part of the shared API types
+var extraSchemas = []reflect.Type{
+	typeOf[shared.AuthHeader](),
+	typeOf[shared.ConvertTrialRequest](),
+	typeOf[shared.UiSignInToken](),
+	typeOf[shared.GetContextBodyRequest](),
+	typeOf[shared.CreditsLogRequest](),
+	typeOf[shared.CreditsLogResponse](),
+	typeOf[shared.CreditsSummaryResponse](),
+	typeOf[shared.GetBalanceResponse](),
+	typeOf[shared.StreamMessage](),
+}
+
+const planIdBranch = "/plans/{planId}/{branch}"
+
+func ops() []operation {
+	var operations []operation
+	add := func(op operation) {
+		operations = append(operations, op)
+	}
+
+	withRes := func(op operation, kind responseKind, t reflect.Type) operation {
+		op.resKind = kind
+		op.res = t
+		return op
+	}
+
+	// health
+	add(operation{method: "GET", path: "/health", id: "healthCheck", tag: "health", summary: "Check that the server is running", noAuth: true, resKind: responseText})
+	add(operation{method: "GET", path: "/version", id: "getVersion", tag: "health", summary: "Get the server version", noAuth: true, resKind: responseText})
+	add(operation{method: "GET", path: "/openapi.json", id: "getOpenApiSpec", tag: "health", summary: "Get this OpenAPI document", noAuth: true, resKind: responseJSON})
+
+	// accounts
+	add(withRes(operation{method: "POST", path: "/accounts/email_verifications", id: "createEmailVerification", tag: "accounts", summary: "Send an email verification pin", noAuth: true, req: typeOf[shared.CreateEmailVerificationRequest]()}, responseJSON, typeOf[shared.CreateEmailVerificationResponse]()))
+	add(operation{method: "POST", path: "/accounts/email_verifications/check_pin", id: "checkEmailPin", tag: "accounts", summary: "Check an email verification pin", noAuth: true, req: typeOf[shared.VerifyEmailPinRequest]()})
+	add(operation{method: "POST", path: "/accounts/sign_in_codes", id: "createSignInCode", tag: "accounts", summary: "Create a sign in code for another device", resKind: responseText})
+	add(withRes(operation{method: "POST", path: "/accounts/sign_in", id: "signIn", tag: "accounts", summary: "Sign in", noAuth: true, req: typeOf[shared.SignInRequest]()}, responseJSON, typeOf[shared.SessionResponse]()))
+	add(operation{method: "POST", path: "/accounts/sign_out", id: "signOut", tag: "accounts", summary: "Sign out"})
+	add(withRes(operation{method: "POST", path: "/accounts", id: "createAccount", tag: "accounts", summary: "Create an account", noAuth: true, req: typeOf[shared.CreateAccountRequest]()}, responseJSON, typeOf[shared.SessionResponse]()))
+
+	// orgs and users
+	add(withRes(operation{method: "GET", path: "/orgs/session", id: "getOrgSession", tag: "orgs", summary: "Get the current org"}, responseJSON, typeOf[shared.Org]()))
+	add(withRes(operation{method: "GET", path: "/orgs", id: "listOrgs", tag: "orgs", summary: "List orgs the user belongs to"}, responseJSON, typeOf[[]*shared.Org]()))
+	add(withRes(operation{method: "POST", path: "/orgs", id: "createOrg", tag: "orgs", summary: "Create an org", req: typeOf[shared.CreateOrgRequest]()}, responseJSON, typeOf[shared.CreateOrgResponse]()))
+	add(withRes(operation{method: "GET", path: "/users", id: "listUsers", tag: "orgs", summary: "List users in the org"}, responseJSON, typeOf[shared.ListUsersResponse]()))
+	add(operation{method: "DELETE", path: "/orgs/users/{userId}", id: "deleteOrgUser", tag: "orgs", summary: "Remove a user from the org"})
+	add(operation{method: "PUT", path: "/orgs/users/{userId}/role", id: "setOrgUserRole", tag: "orgs", summary: "Change a user's org role", req: typeOf[shared.SetOrgUserRoleRequest]()})
+	add(withRes(operation{method: "GET", path: "/orgs/roles", id: "listOrgRoles", tag: "orgs", summary: "List built-in and custom org roles with their permissions"}, responseJSON, typeOf[[]*shared.OrgRole]()))
+	add(withRes(operation{method: "POST", path: "/orgs/roles", id: "createOrgRole", tag: "orgs", summary: "Create a custom org role", req: typeOf[shared.CreateOrgRoleRequest]()}, responseJSON, typeOf[shared.OrgRole]()))
+	add(withRes(operation{method: "PUT", path: "/orgs/roles/{roleId}", id: "updateOrgRole", tag: "orgs", summary: "Update a custom org role", req: typeOf[shared.UpdateOrgRoleRequest]()}, responseJSON, typeOf[shared.OrgRole]()))
+	add(operation{method: "DELETE", path: "/orgs/roles/{roleId}", id: "deleteOrgRole", tag: "orgs", summary: "Delete a custom org role that no users or pending invites have"})
+
+	// api tokens
+	add(withRes(operation{method: "POST", path: "/api_tokens", id: "createApiToken", tag: "apiTokens", summary: "Create an API token -- the token is only returned once", req: typeOf[shared.CreateApiTokenRequest]()}, responseJSON, typeOf[shared.CreateApiTokenResponse]()))
+	add(withRes(operation{method: "GET", path: "/api_tokens", id: "listApiTokens", tag: "apiTokens", summary: "List active API tokens"}, responseJSON, typeOf[[]*shared.ApiToken]()))
+	add(withRes(operation{method: "GET", path: "/api_tokens/session", id: "getApiTokenSession", tag: "apiTokens", summary: "Get the user and org the current API token acts as"}, responseJSON, typeOf[shared.SessionResponse]()))
+	add(operation{method: "DELETE", path: "/api_tokens/{tokenId}", id: "revokeApiToken", tag: "apiTokens", summary: "Revoke an API token"})
+
+	// invites
+	add(operation{method: "POST", path: "/invites", id: "inviteUser", tag: "invites", summary: "Invite a user to the org", req: typeOf[shared.InviteRequest]()})
+	add(withRes(operation{method: "GET", path: "/invites/pending", id: "listPendingInvites", tag: "invites", summary: "List pending invites"}, responseJSON, typeOf[[]*shared.Invite]()))
+	add(withRes(operation{method: "GET", path: "/invites/accepted", id: "listAcceptedInvites", tag: "invites", summary: "List accepted invites"}, responseJSON, typeOf[[]*shared.Invite]()))
+	add(withRes(operation{method: "GET", path: "/invites/all", id: "listAllInvites", tag: "invites", summary: "List all invites"}, responseJSON, typeOf[[]*shared.Invite]()))
+	add(operation{method: "DELETE", path: "/invites/{inviteId}", id: "deleteInvite", tag: "invites", summary: "Delete an invite"})
+
+	// projects
+	add(withRes(operation{method: "POST", path: "/projects", id: "createProject", tag: "projects", summary: "Create a project", req: typeOf[shared.CreateProjectRequest]()}, responseJSON, typeOf[shared.CreateProjectResponse]()))
+	add(withRes(operation{method: "GET", path: "/projects", id: "listProjects", tag: "projects", summary: "List projects"}, responseJSON, typeOf[[]*shared.Project]()))
+	add(operation{method: "PUT", path: "/projects/{projectId}/set_plan", id: "setProjectPlan", tag: "projects", summary: "Set the current plan for a project", req: typeOf[shared.SetProjectPlanRequest]()})
+	add(operation{method: "PUT", path: "/projects/{projectId}/rename", id: "renameProject", tag: "projects", summary: "Rename a project", req: typeOf[shared.RenameProjectRequest]()})
+	add(withRes(operation{method: "POST", path: "/projects/{projectId}/plans/current_branches", id: "getCurrentBranchByPlanId", tag: "projects", summary: "Get the current branch of each plan", req: typeOf[shared.GetCurrentBranchByPlanIdRequest]()}, responseJSON, typeOf[map[string]*shared.Branch]()))
+
+	// plans
+	projectIdsQuery := param{name: "projectId", desc: "Only include plans in these projects", array: true}
+	add(withRes(operation{method: "GET", path: "/plans", id: "listPlans", tag: "plans", summary: "List plans", query: []param{projectIdsQuery}}, responseJSON, typeOf[[]*shared.Plan]()))
+	add(withRes(operation{method: "GET", path: "/plans/archive", id: "listArchivedPlans", tag: "plans", summary: "List archived plans", query: []param{projectIdsQuery}}, responseJSON, typeOf[[]*shared.Plan]()))
+	add(withRes(operation{method: "GET", path: "/plans/ps", id: "listPlansRunning", tag: "plans", summary: "List running and recently finished plans", query: []param{projectIdsQuery, {name: "recent", desc: "Set to 'true' to include recently finished plans"}}}, responseJSON, typeOf[shared.ListPlansRunningResponse]()))
+	add(withRes(operation{method: "POST", path: "/projects/{projectId}/plans", id: "createPlan", tag: "plans", summary: "Create a plan", req: typeOf[shared.CreatePlanRequest]()}, responseJSON, typeOf[shared.CreatePlanResponse]()))
+	add(operation{method: "DELETE", path: "/projects/{projectId}/plans", id: "deleteAllPlans", tag: "plans", summary: "Delete all plans in a project"})
+	add(withRes(operation{method: "GET", path: "/plans/{planId}", id: "getPlan", tag: "plans", summary: "Get a plan"}, responseJSON, typeOf[shared.Plan]()))
+	add(operation{method: "DELETE", path: "/plans/{planId}", id: "deletePlan", tag: "plans", summary: "Delete a plan"})
+	add(withRes(operation{method: "GET", path: "/plans/{planId}/current_plan/{sha}", id: "getCurrentPlanStateAtSha", tag: "plans", summary: "Get the plan's pending changes at a commit"}, responseJSON, typeOf[shared.CurrentPlanState]()))
+	add(withRes(operation{method: "GET", path: planIdBranch + "/current_plan", id: "getCurrentPlanState", tag: "plans", summary: "Get the plan's pending changes"}, responseJSON, typeOf[shared.CurrentPlanState]()))
+	add(operation{method: "PATCH", path: planIdBranch + "/apply", id: "applyPlan", tag: "plans", summary: "Mark pending changes as applied -- responds with a suggested commit message", req: typeOf[shared.ApplyPlanRequest](), resKind: responseText})
+	add(operation{method: "PATCH", path: "/plans/{planId}/archive", id: "archivePlan", tag: "plans", summary: "Archive a plan"})
+	add(operation{method: "PATCH", path: "/plans/{planId}/unarchive", id: "unarchivePlan", tag: "plans", summary: "Unarchive a plan"})
+	add(operation{method: "PATCH", path: "/plans/{planId}/rename", id: "renamePlan", tag: "plans", summary: "Rename a plan", req: typeOf[shared.RenamePlanRequest]()})
+	add(withRes(operation{method: "GET", path: "/plans/{planId}/shares", id: "listPlanShares", tag: "plans", summary: "List the users a plan is shared with"}, responseJSON, typeOf[[]*shared.PlanShare]()))
+	add(withRes(operation{method: "POST", path: "/plans/{planId}/shares", id: "sharePlan", tag: "plans", summary: "Share a plan with an org member, or change their role", req: typeOf[shared.SharePlanRequest]()}, responseJSON, typeOf[shared.PlanShare]()))
+	add(operation{method: "DELETE", path: "/plans/{planId}/shares/{userId}", id: "unsharePlan", tag: "plans", summary: "Remove a user's access to a plan"})
+	add(operation{method: "PATCH", path: planIdBranch + "/reject_all", id: "rejectAllChanges", tag: "plans", summary: "Reject all pending changes"})
+	add(operation{method: "PATCH", path: planIdBranch + "/reject_file", id: "rejectFile", tag: "plans", summary: "Reject pending changes to a file", req: typeOf[shared.RejectFileRequest]()})
+	add(operation{method: "PATCH", path: planIdBranch + "/reject_files", id: "rejectFiles", tag: "plans", summary: "Reject pending changes to several files", req: typeOf[shared.RejectFilesRequest]()})
+	add(operation{method: "PATCH", path: planIdBranch + "/review_replacements", id: "reviewReplacements", tag: "plans", summary: "Reject or edit individual pending changes within files", req: typeOf[shared.ReviewReplacementsRequest]()})
+	add(operation{method: "GET", path: planIdBranch + "/diffs", id: "getPlanDiffs", tag: "plans", summary: "Get pending changes as a git diff", query: []param{{name: "plain", desc: "Set to 'true' for a diff without color codes"}}, resKind: responseText})
+
+	// context
+	add(withRes(operation{method: "GET", path: planIdBranch + "/context", id: "listContext", tag: "context", summary: "List context"}, responseJSON, typeOf[[]*shared.Context]()))
+	add(withRes(operation{method: "POST", path: planIdBranch + "/context", id: "loadContext", tag: "context", summary: "Load context", req: typeOf[shared.LoadContextRequest]()}, responseJSON, typeOf[shared.LoadContextResponse]()))
+	add(withRes(operation{method: "GET", path: planIdBranch + "/context/{contextId}/body", id: "getContextBody", tag: "context", summary: "Get the body of a context"}, responseJSON, typeOf[shared.GetContextBodyResponse]()))
+	add(withRes(operation{method: "PUT", path: planIdBranch + "/context", id: "updateContext", tag: "context", summary: "Update context", req: typeOf[shared.UpdateContextRequest]()}, responseJSON, typeOf[shared.UpdateContextResponse]()))
+	add(withRes(operation{method: "DELETE", path: planIdBranch + "/context", id: "deleteContext", tag: "context", summary: "Remove context", req: typeOf[shared.DeleteContextRequest]()}, responseJSON, typeOf[shared.DeleteContextResponse]()))
+	add(withRes(operation{method: "POST", path: planIdBranch + "/context_sets", id: "saveContextSet", tag: "context", summary: "Save the plan's context as a named set in its project", req: typeOf[shared.SaveContextSetRequest]()}, responseJSON, typeOf[shared.ContextSet]()))
+	add(withRes(operation{method: "GET", path: "/projects/{projectId}/context_sets", id: "listContextSets", tag: "context", summary: "List your context sets in a project and the ones shared with the org"}, responseJSON, typeOf[[]*shared.ContextSet]()))
+	add(withRes(operation{method: "GET", path: "/projects/{projectId}/context_sets/{name}", id: "getContextSet", tag: "context", summary: "Get a context set by name"}, responseJSON, typeOf[shared.ContextSet]()))
+	add(withRes(operation{method: "POST", path: "/file_map", id: "getFileMap", tag: "context", summary: "Build a project map", req: typeOf[shared.GetFileMapRequest]()}, responseJSON, typeOf[shared.GetFileMapResponse]()))
+	add(withRes(operation{method: "POST", path: "/file_map/symbols", id: "getFileSymbols", tag: "context", summary: "Select definitions from files by symbol pattern", req: typeOf[shared.GetFileSymbolsRequest]()}, responseJSON, typeOf[shared.GetFileSymbolsResponse]()))
+	add(withRes(operation{method: "POST", path: "/file_map/deps", id: "getFileDeps", tag: "context", summary: "Resolve the project files that each file imports", req: typeOf[shared.GetFileDepsRequest]()}, responseJSON, typeOf[shared.GetFileDepsResponse]()))
+	add(withRes(operation{method: "POST", path: planIdBranch + "/load_cached_file_map", id: "loadCachedFileMap", tag: "context", summary: "Load a cached project map", req: typeOf[shared.LoadCachedFileMapRequest]()}, responseJSON, typeOf[shared.LoadCachedFileMapResponse]()))
+
+	// history
+	add(withRes(operation{method: "GET", path: planIdBranch + "/convo", id: "listConvo", tag: "history", summary: "List conversation messages"}, responseJSON, typeOf[[]*shared.ConvoMessage]()))
+	add(withRes(operation{method: "PATCH", path: planIdBranch + "/rewind", id: "rewindPlan", tag: "history", summary: "Rewind the plan to an earlier state", req: typeOf[shared.RewindPlanRequest]()}, responseJSON, typeOf[shared.RewindPlanResponse]()))
+	add(withRes(operation{method: "GET", path: planIdBranch + "/logs", id: "listLogs", tag: "history", summary: "List plan history"}, responseJSON, typeOf[shared.LogResponse]()))
+	add(operation{method: "GET", path: planIdBranch + "/status", id: "getPlanStatus", tag: "history", summary: "Get the latest summary of the plan", resKind: responseText})
+
+	// branches
+	add(withRes(operation{method: "GET", path: "/plans/{planId}/branches", id: "listBranches", tag: "branches", summary: "List branches"}, responseJSON, typeOf[[]*shared.Branch]()))
+	add(operation{method: "DELETE", path: "/plans/{planId}/branches/{branch}", id: "deleteBranch", tag: "branches", summary: "Delete a branch"})
+	add(operation{method: "POST", path: planIdBranch + "/branches", id: "createBranch", tag: "branches", summary: "Create a branch from this branch", req: typeOf[shared.CreateBranchRequest]()})
+
+	// settings and config
+	add(withRes(operation{method: "GET", path: planIdBranch + "/settings", id: "getSettings", tag: "settings", summary: "Get plan model settings"}, responseJSON, typeOf[shared.PlanSettings]()))
+	add(withRes(operation{method: "PUT", path: planIdBranch + "/settings", id: "updateSettings", tag: "settings", summary: "Update plan model settings", req: typeOf[shared.UpdateSettingsRequest]()}, responseJSON, typeOf[shared.UpdateSettingsResponse]()))
+	add(withRes(operation{method: "GET", path: "/default_settings", id: "getDefaultSettings", tag: "settings", summary: "Get default model settings"}, responseJSON, typeOf[shared.PlanSettings]()))
+	add(withRes(operation{method: "PUT", path: "/default_settings", id: "updateDefaultSettings", tag: "settings", summary: "Update default model settings", req: typeOf[shared.UpdateSettingsRequest]()}, responseJSON, typeOf[shared.UpdateSettingsResponse]()))
+	add(withRes(operation{method: "GET", path: "/plans/{planId}/config", id: "getPlanConfig", tag: "settings", summary: "Get plan config"}, responseJSON, typeOf[shared.GetPlanConfigResponse]()))
+	add(operation{method: "PUT", path: "/plans/{planId}/config", id: "updatePlanConfig", tag: "settings", summary: "Update plan config", req: typeOf[shared.UpdatePlanConfigRequest]()})
+	add(withRes(operation{method: "GET", path: "/default_plan_config", id: "getDefaultPlanConfig", tag: "settings", summary: "Get default plan config"}, responseJSON, typeOf[shared.GetDefaultPlanConfigResponse]()))
+	add(operation{method: "PUT", path: "/default_plan_config", id: "updateDefaultPlanConfig", tag: "settings", summary: "Update default plan config", req: typeOf[shared.UpdateDefaultPlanConfigRequest]()})
+	add(withRes(operation{method: "GET", path: "/org_user_config", id: "getOrgUserConfig", tag: "settings", summary: "Get the user's config for the org"}, responseJSON, typeOf[shared.OrgUserConfig]()))
+	add(operation{method: "PUT", path: "/org_user_config", id: "updateOrgUserConfig", tag: "settings", summary: "Update the user's config for the org", req: typeOf[shared.OrgUserConfig]()})
+
+	// models
+	add(withRes(operation{method: "GET", path: "/custom_models", id: "listCustomModels", tag: "models", summary: "List custom models"}, responseJSON, typeOf[[]*shared.CustomModel]()))
+	add(operation{method: "POST", path: "/custom_models", id: "upsertCustomModels", tag: "models", summary: "Create or update custom models, providers and model packs", req: typeOf[shared.ModelsInput]()})
+	add(withRes(operation{method: "GET", path: "/custom_models/{modelId}", id: "getCustomModel", tag: "models", summary: "Get a custom model"}, responseJSON, typeOf[shared.CustomModel]()))
+	add(withRes(operation{method: "GET", path: "/custom_providers", id: "listCustomProviders", tag: "models", summary: "List custom providers"}, responseJSON, typeOf[[]*shared.CustomProvider]()))
+	add(withRes(operation{method: "GET", path: "/custom_providers/{providerId}", id: "getCustomProvider", tag: "models", summary: "Get a custom provider"}, responseJSON, typeOf[shared.CustomProvider]()))
+	add(withRes(operation{method: "GET", path: "/model_sets", id: "listModelPacks", tag: "models", summary: "List model packs"}, responseJSON, typeOf[[]*shared.ModelPack]()))
+	add(operation{method: "POST", path: "/model_sets", id: "createModelPack", tag: "models", summary: "Deprecated -- use POST /custom_models"})
+	add(operation{method: "PUT", path: "/model_sets/{setId}", id: "updateModelPack", tag: "models", summary: "Deprecated -- use POST /custom_models"})
+
+	// webhooks
+	add(withRes(operation{method: "GET", path: "/webhooks/deliveries", id: "listWebhookDeliveries", tag: "webhooks", summary: "List webhook deliveries", query: []param{
+		{name: "planId", desc: "Only include deliveries for this plan"},
+		{name: "eventType", desc: "Only include deliveries for this event type"},
+		{name: "status", desc: "Only include deliveries with this status"},
+		{name: "limit", desc: "Maximum number of deliveries to return (default 100)"},
+	}}, responseJSON, typeOf[[]*shared.WebhookDelivery]()))
+	add(withRes(operation{method: "POST", path: "/webhooks/deliveries/{deliveryId}/replay", id: "replayWebhookDelivery", tag: "webhooks", summary: "Send a webhook delivery again"}, responseJSON, typeOf[shared.WebhookDelivery]()))
+
+	// audit log
+	add(withRes(operation{method: "GET", path: "/audit", id: "listAuditLog", tag: "audit", summary: "List audit log entries, newest first", query: []param{
+		{name: "userId", desc: "Only include actions taken by this user"},
+		{name: "userEmail", desc: "Only include actions taken by the user with this email"},
+		{name: "action", desc: "Only include this action, like 'plan.deleted', or every action in a category, like 'plan'"},
+		{name: "planId", desc: "Only include actions on this plan"},
+		{name: "since", desc: "Only include actions at or after this RFC 3339 time"},
+		{name: "until", desc: "Only include actions before this RFC 3339 time"},
+		{name: "limit", desc: "Maximum number of entries to return (default 100)"},
+	}}, responseJSON, typeOf[[]*shared.AuditLogEntry]()))
+
+	// budgets
+	add(withRes(operation{method: "GET", path: "/budgets", id: "listBudgets", tag: "budgets", summary: "List the org's model spend budgets"}, responseJSON, typeOf[[]*shared.Budget]()))
+	add(withRes(operation{method: "PUT", path: "/budgets", id: "setBudget", tag: "budgets", summary: "Create a budget, or replace the budget with the same scope, user or plan, and period", req: typeOf[shared.SetBudgetRequest]()}, responseJSON, typeOf[shared.Budget]()))
+	add(withRes(operation{method: "GET", path: "/budgets/status", id: "getBudgetStatus", tag: "budgets", summary: "Get spend and remaining budget in the current period for every budget that applies to the user", query: []param{
+		{name: "planId", desc: "Also include budgets for this plan"},
+	}}, responseJSON, typeOf[[]*shared.BudgetStatus]()))
+	add(operation{method: "DELETE", path: "/budgets/{budgetId}", id: "deleteBudget", tag: "budgets", summary: "Delete a budget"})
+
+	// usage
+	add(withRes(operation{method: "GET", path: "/usage", id: "getUsageReport", tag: "usage", summary: "Sum model requests by plan, user, model role, model, or day -- only includes the user's own requests without view_org_usage", query: []param{
+		{name: "by", desc: "How to group requests: plan, user, role, model, or day (default model)"},
+		{name: "since", desc: "Only include requests at or after this RFC 3339 time (default the start of the current UTC day)"},
+		{name: "until", desc: "Only include requests before this RFC 3339 time (default now)"},
+		{name: "planId", desc: "Only include requests on this plan"},
+		{name: "userEmail", desc: "Only include requests by the user with this email"},
+		{name: "sessionId", desc: "Only include requests from this REPL session"},
+	}}, responseJSON, typeOf[shared.UsageReport]()))
+
+	// exec policy
+	add(withRes(operation{method: "GET", path: "/exec_policy", id: "getOrgExecPolicy", tag: "execPolicy", summary: "Get the org's policy for commands in apply scripts -- empty if it doesn't have one"}, responseJSON, typeOf[shared.ExecPolicy]()))
+	add(operation{method: "PUT", path: "/exec_policy", id: "setOrgExecPolicy", tag: "execPolicy", summary: "Set the org's policy for commands in apply scripts", req: typeOf[shared.SetOrgExecPolicyRequest]()})
+	add(operation{method: "DELETE", path: "/exec_policy", id: "deleteOrgExecPolicy", tag: "execPolicy", summary: "Remove the org's policy for commands in apply scripts"})
+
+	// plan execution
+	add(operation{method: "POST", path: planIdBranch + "/tell", id: "tellPlan", tag: "exec", summary: "Send a prompt -- streams the response if connectStream is true", req: typeOf[shared.TellPlanRequest](), resKind: responseStream})
+	add(operation{method: "PATCH", path: planIdBranch + "/build", id: "buildPlan", tag: "exec", summary: "Build pending changes -- streams the response if connectStream is true", req: typeOf[shared.BuildPlanRequest](), resKind: responseStream})
+	add(operation{method: "PATCH", path: planIdBranch + "/connect", id: "connectPlan", tag: "exec", summary: "Connect to the stream of an active plan", resKind: responseStream})
+	add(operation{method: "GET", path: planIdBranch + "/connect", id: "connectPlanEventSource", tag: "exec", summary: "Connect to the stream of an active plan (for EventSource clients)", resKind: responseStream})
+	add(operation{method: "DELETE", path: planIdBranch + "/stop", id: "stopPlan", tag: "exec", summary: "Stop an active plan"})
+	add(operation{method: "POST", path: planIdBranch + "/respond_missing_file", id: "respondMissingFile", tag: "exec", summary: "Respond to a promptMissingFile stream message", req: typeOf[shared.RespondMissingFileRequest]()})
+	add(withRes(operation{method: "POST", path: planIdBranch + "/auto_load_context", id: "autoLoadContext", tag: "exec", summary: "Load context requested by a loadContext stream message", req: typeOf[shared.LoadContextRequest]()}, responseJSON, typeOf[shared.LoadContextResponse]()))
+	add(withRes(operation{method: "GET", path: planIdBranch + "/build_status", id: "getBuildStatus", tag: "exec", summary: "Get the build status of an active plan"}, responseJSON, typeOf[shared.GetBuildStatusResponse]()))
+
+	return operations
+}
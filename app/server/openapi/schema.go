@@ -0,0 +1,173 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// schemaBuilder converts Go types to OpenAPI schemas. Named struct types are added to components and referenced with $ref, which also handles recursive types.
+type schemaBuilder struct {
+	components map[string]any
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{components: map[string]any{}}
+}
+
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+// component adds a named type to components (if it isn't there already) and returns a reference to it
+func (b *schemaBuilder) component(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	name := t.Name()
+	if _, ok := b.components[name]; !ok {
+		// placeholder first so recursive references don't loop
+		b.components[name] = map[string]any{}
+		b.components[name] = b.define(t)
+	}
+
+	return ref(name)
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return b.schema(t.Elem())
+	}
+
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	if t.Name() != "" && t.PkgPath() != "" {
+		return b.component(t)
+	}
+
+	return b.define(t)
+}
+
+// define returns the schema for a type's underlying structure
+func (b *schemaBuilder) define(t reflect.Type) map[string]any {
+	if t.PkgPath() == "github.com/shopspring/decimal" && t.Name() == "Decimal" {
+		return map[string]any{"type": "string", "format": "decimal"}
+	}
+
+	if t.Kind() != reflect.Interface && (t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType)) {
+		// custom JSON encoding -- the Go fields don't describe the wire format
+		return map[string]any{"description": "Custom JSON encoding"}
+	}
+
+	var s map[string]any
+
+	switch t.Kind() {
+	case reflect.Bool:
+		s = map[string]any{"type": "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = map[string]any{"type": "integer"}
+		if t.Kind() == reflect.Int64 || t.Kind() == reflect.Uint64 {
+			s["format"] = "int64"
+		}
+
+	case reflect.Float32, reflect.Float64:
+		s = map[string]any{"type": "number"}
+
+	case reflect.String:
+		s = map[string]any{"type": "string"}
+		if values, ok := enums[t]; ok {
+			s["enum"] = values
+		}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			s = map[string]any{"type": "string", "format": "byte"}
+		} else {
+			s = map[string]any{"type": "array", "items": b.schema(t.Elem())}
+		}
+
+	case reflect.Map:
+		s = map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+
+	case reflect.Struct:
+		s = b.structSchema(t)
+
+	default:
+		// interfaces and anything else that can hold arbitrary JSON
+		s = map[string]any{}
+	}
+
+	return s
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	b.addFields(t, props)
+
+	return map[string]any{
+		"type":       "object",
+		"properties": props,
+	}
+}
+
+// addFields follows encoding/json: embedded structs without a json name are flattened into the parent
+func (b *schemaBuilder) addFields(t reflect.Type, props map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.addFields(ft, props)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		var s map[string]any
+		if strings.Contains(opts, "string") {
+			s = map[string]any{"type": "string"}
+		} else {
+			s = b.schema(f.Type)
+		}
+
+		switch f.Type.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+			if _, isRef := s["$ref"]; isRef {
+				// siblings of $ref are ignored in OpenAPI 3.0, so wrap it
+				s = map[string]any{"allOf": []any{s}, "nullable": true}
+			} else {
+				s["nullable"] = true
+			}
+		}
+
+		props[name] = s
+	}
+}
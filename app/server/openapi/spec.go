@@ -0,0 +1,227 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	shared "plandex-shared"
+)
+
+// SpecVersion is the version of the API described by the spec. Bump it when routes or request/response types change in a way that affects clients.
+const SpecVersion = "1.0.0"
+
+var pathParamRegex = regexp.MustCompile(`\{([^}]+)\}`)
+
+var specOnce sync.Once
+var specJson []byte
+var specErr error
+
+// Spec builds the OpenAPI 3 document for the server API
+func Spec() map[string]any {
+	b := newSchemaBuilder()
+
+	// the error shape used by writeApiError -- plain http.Error responses are text
+	b.component(typeOf[shared.ApiError]())
+
+	for _, t := range extraSchemas {
+		b.component(t)
+	}
+
+	paths := map[string]any{}
+	tags := map[string]bool{}
+
+	for _, op := range ops() {
+		tags[op.tag] = true
+
+		item, ok := paths[op.path].(map[string]any)
+		if !ok {
+			item = map[string]any{}
+			paths[op.path] = item
+		}
+
+		item[strings.ToLower(op.method)] = b.operation(op)
+	}
+
+	var tagNames []string
+	for tag := range tags {
+		tagNames = append(tagNames, tag)
+	}
+	sort.Strings(tagNames)
+
+	var tagList []any
+	for _, tag := range tagNames {
+		tagList = append(tagList, map[string]any{"name": tag})
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "Plandex Server API",
+			"version":     SpecVersion,
+			"description": "API used by the Plandex CLI. Errors are returned as an ApiError JSON object, or as plain text for simple request validation errors.",
+		},
+		"tags":  tagList,
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": b.components,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "Base64url encoded JSON of an AuthHeader object: {\"token\": \"...\", \"orgId\": \"...\", \"hash\": \"...\"}. The token is either a session token or an API token (starting with '" + shared.ApiTokenPrefix + "'). orgId is optional for API tokens.",
+				},
+				"cookieAuth": map[string]any{
+					"type":        "apiKey",
+					"in":          "cookie",
+					"name":        "authToken",
+					"description": "Same value as bearerAuth -- used by browser sessions",
+				},
+			},
+			"responses": map[string]any{
+				"Error": map[string]any{
+					"description": "Error",
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": ref("ApiError")},
+						"text/plain":       map[string]any{"schema": map[string]any{"type": "string"}},
+					},
+				},
+			},
+		},
+		"security": []any{
+			map[string]any{"bearerAuth": []any{}},
+			map[string]any{"cookieAuth": []any{}},
+		},
+	}
+}
+
+// SpecJSON returns the spec encoded as indented JSON. It's built once and cached.
+func SpecJSON() ([]byte, error) {
+	specOnce.Do(func() {
+		specJson, specErr = json.MarshalIndent(Spec(), "", "  ")
+		if specErr != nil {
+			specErr = fmt.Errorf("error marshalling openapi spec: %v", specErr)
+			return
+		}
+		specJson = append(specJson, '\n')
+	})
+
+	return specJson, specErr
+}
+
+func (b *schemaBuilder) operation(op operation) map[string]any {
+	res := map[string]any{
+		"operationId": op.id,
+		"summary":     op.summary,
+		"tags":        []any{op.tag},
+	}
+
+	if op.noAuth {
+		res["security"] = []any{}
+	}
+
+	var params []any
+	for _, match := range pathParamRegex.FindAllStringSubmatch(op.path, -1) {
+		params = append(params, map[string]any{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": "string"},
+		})
+	}
+	for _, p := range op.query {
+		var s map[string]any
+		if p.array {
+			s = map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+		} else {
+			s = map[string]any{"type": "string"}
+		}
+		params = append(params, map[string]any{
+			"name":        p.name,
+			"in":          "query",
+			"description": p.desc,
+			"schema":      s,
+		})
+	}
+	if len(params) > 0 {
+		res["parameters"] = params
+	}
+
+	if op.req != nil {
+		res["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": b.schema(op.req)},
+			},
+		}
+	}
+
+	var ok map[string]any
+	switch op.resKind {
+	case responseNone:
+		ok = map[string]any{"description": "OK"}
+
+	case responseText:
+		ok = map[string]any{
+			"description": "OK",
+			"content": map[string]any{
+				"text/plain": map[string]any{"schema": map[string]any{"type": "string"}},
+			},
+		}
+
+	case responseJSON:
+		var s map[string]any
+		if op.res == nil {
+			s = map[string]any{"type": "object"}
+		} else {
+			s = b.schema(op.res)
+		}
+		ok = map[string]any{
+			"description": "OK",
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": s},
+			},
+		}
+
+	case responseStream:
+		ok = map[string]any{
+			"description": "Stream of StreamMessage objects. By default each message is JSON followed by the separator '" + shared.STREAM_MESSAGE_SEPARATOR + "'. Send 'Accept: text/event-stream' to receive Server-Sent Events instead -- each event's name is the message type and its data is the message JSON.",
+			"content": map[string]any{
+				"text/plain":        map[string]any{"schema": map[string]any{"type": "string"}},
+				"text/event-stream": map[string]any{"schema": map[string]any{"type": "string"}},
+			},
+		}
+	}
+
+	res["responses"] = map[string]any{
+		"200":     ok,
+		"default": map[string]any{"$ref": "#/components/responses/Error"},
+	}
+
+	return res
+}
+
+// Operations returns the method and path of every documented route, in 'METHOD /path' format
+func Operations() []string {
+	var res []string
+	for _, op := range ops() {
+		res = append(res, op.method+" "+op.path)
+	}
+	return res
+}
+
+// SchemaNames returns the names of every schema in the spec's components
+func SchemaNames() []string {
+	spec := Spec()
+	schemas := spec["components"].(map[string]any)["schemas"].(map[string]any)
+
+	var res []string
+	for name := range schemas {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
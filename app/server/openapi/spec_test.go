@@ -0,0 +1,140 @@
+package openapi_test
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"plandex-server/openapi"
+	"plandex-server/routes"
+	"sort"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+var update = flag.Bool("update", false, "rewrite openapi.json from the current spec")
+
+func registeredRoutes(t *testing.T) []string {
+	routes.RegisterHandlePlandex(func(router *mux.Router, path string, isStreaming bool, handler routes.PlandexHandler) *mux.Route {
+		return router.HandleFunc(path, handler)
+	})
+
+	r := mux.NewRouter()
+	routes.AddHealthRoutes(r)
+	routes.AddApiRoutes(r)
+	routes.AddProxyableApiRoutes(r)
+
+	var res []string
+	err := r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			// health routes accept any method
+			methods = []string{http.MethodGet}
+		}
+
+		for _, method := range methods {
+			res = append(res, method+" "+path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error walking routes: %v", err)
+	}
+
+	return res
+}
+
+func TestSpecCoversRoutes(t *testing.T) {
+	documented := map[string]bool{}
+	for _, op := range openapi.Operations() {
+		if documented[op] {
+			t.Errorf("%s is documented more than once", op)
+		}
+		documented[op] = true
+	}
+
+	registered := map[string]bool{}
+	for _, route := range registeredRoutes(t) {
+		registered[route] = true
+		if !documented[route] {
+			t.Errorf("%s is registered but missing from the openapi spec -- add it to openapi/operations.go", route)
+		}
+	}
+
+	for op := range documented {
+		if !registered[op] {
+			t.Errorf("%s is in the openapi spec but isn't a registered route", op)
+		}
+	}
+}
+
+func TestSpecCoversReqResTypes(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "../../shared/req_res.go", nil, 0)
+	if err != nil {
+		t.Fatalf("error parsing req_res.go: %v", err)
+	}
+
+	schemas := map[string]bool{}
+	for _, name := range openapi.SchemaNames() {
+		schemas[name] = true
+	}
+
+	var missing []string
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			// aliases are documented under the type they alias
+			if !typeSpec.Name.IsExported() || typeSpec.Assign.IsValid() {
+				continue
+			}
+
+			if !schemas[typeSpec.Name.Name] {
+				missing = append(missing, typeSpec.Name.Name)
+			}
+		}
+	}
+
+	sort.Strings(missing)
+	for _, name := range missing {
+		t.Errorf("shared.%s is missing from the openapi spec -- use it in an operation or add it to extraSchemas in openapi/operations.go", name)
+	}
+}
+
+func TestSpecMatchesCommittedFile(t *testing.T) {
+	spec, err := openapi.SpecJSON()
+	if err != nil {
+		t.Fatalf("error building spec: %v", err)
+	}
+
+	if *update {
+		err = os.WriteFile("openapi.json", spec, 0644)
+		if err != nil {
+			t.Fatalf("error writing openapi.json: %v", err)
+		}
+		return
+	}
+
+	committed, err := os.ReadFile("openapi.json")
+	if err != nil {
+		t.Fatalf("error reading openapi.json: %v", err)
+	}
+
+	if !bytes.Equal(spec, committed) {
+		t.Errorf("openapi.json is out of date -- run 'go test ./openapi -update' from app/server to regenerate it")
+	}
+}
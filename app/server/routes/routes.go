@@ -89,6 +89,8 @@ func AddProxyableApiRoutesWithPrefix(r *mux.Router, prefix string) {
 func addApiRoutes(r *mux.Router, prefix string) {
 	EnsureHandlePlandex()
 
+	HandlePlandexFn(r, prefix+"/openapi.json", false, handlers.GetOpenApiSpecHandler).Methods("GET")
+
 	HandlePlandexFn(r, prefix+"/accounts/email_verifications", false, handlers.CreateEmailVerificationHandler).Methods("POST")
 	HandlePlandexFn(r, prefix+"/accounts/email_verifications/check_pin", false, handlers.CheckEmailPinHandler).Methods("POST")
 	HandlePlandexFn(r, prefix+"/accounts/sign_in_codes", false, handlers.CreateSignInCodeHandler).Methods("POST")
@@ -102,7 +104,16 @@ func addApiRoutes(r *mux.Router, prefix string) {
 
 	HandlePlandexFn(r, prefix+"/users", false, handlers.ListUsersHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/orgs/users/{userId}", false, handlers.DeleteOrgUserHandler).Methods("DELETE")
+	HandlePlandexFn(r, prefix+"/orgs/users/{userId}/role", false, handlers.SetOrgUserRoleHandler).Methods("PUT")
 	HandlePlandexFn(r, prefix+"/orgs/roles", false, handlers.ListOrgRolesHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/orgs/roles", false, handlers.CreateOrgRoleHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/orgs/roles/{roleId}", false, handlers.UpdateOrgRoleHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/orgs/roles/{roleId}", false, handlers.DeleteOrgRoleHandler).Methods("DELETE")
+
+	HandlePlandexFn(r, prefix+"/api_tokens", false, handlers.CreateApiTokenHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/api_tokens", false, handlers.ListApiTokensHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/api_tokens/session", false, handlers.GetApiTokenSessionHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/api_tokens/{tokenId}", false, handlers.RevokeApiTokenHandler).Methods("DELETE")
 
 	HandlePlandexFn(r, prefix+"/invites", false, handlers.InviteUserHandler).Methods("POST")
 	HandlePlandexFn(r, prefix+"/invites/pending", false, handlers.ListPendingInvitesHandler).Methods("GET")
@@ -135,9 +146,13 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/plans/{planId}/unarchive", false, handlers.UnarchivePlanHandler).Methods("PATCH")
 
 	HandlePlandexFn(r, prefix+"/plans/{planId}/rename", false, handlers.RenamePlanHandler).Methods("PATCH")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/shares", false, handlers.ListPlanSharesHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/shares", false, handlers.SharePlanHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/shares/{userId}", false, handlers.UnsharePlanHandler).Methods("DELETE")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_all", false, handlers.RejectAllChangesHandler).Methods("PATCH")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_file", false, handlers.RejectFileHandler).Methods("PATCH")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_files", false, handlers.RejectFilesHandler).Methods("PATCH")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/review_replacements", false, handlers.ReviewReplacementsHandler).Methods("PATCH")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/diffs", false, handlers.GetPlanDiffsHandler).Methods("GET")
 
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context", false, handlers.ListContextHandler).Methods("GET")
@@ -145,6 +160,9 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context/{contextId}/body", false, handlers.GetContextBodyHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context", false, handlers.UpdateContextHandler).Methods("PUT")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context", false, handlers.DeleteContextHandler).Methods("DELETE")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context_sets", false, handlers.SaveContextSetHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/projects/{projectId}/context_sets", false, handlers.ListContextSetsHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/projects/{projectId}/context_sets/{name}", false, handlers.GetContextSetHandler).Methods("GET")
 
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/convo", false, handlers.ListConvoHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/rewind", false, handlers.RewindPlanHandler).Methods("PATCH")
@@ -177,6 +195,8 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/default_settings", false, handlers.UpdateDefaultSettingsHandler).Methods("PUT")
 
 	HandlePlandexFn(r, prefix+"/file_map", false, handlers.GetFileMapHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/file_map/symbols", false, handlers.GetFileSymbolsHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/file_map/deps", false, handlers.GetFileDepsHandler).Methods("POST")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/load_cached_file_map", false, handlers.LoadCachedFileMapHandler).Methods("POST")
 
 	HandlePlandexFn(r, prefix+"/plans/{planId}/config", false, handlers.GetPlanConfigHandler).Methods("GET")
@@ -187,12 +207,30 @@ func addApiRoutes(r *mux.Router, prefix string) {
 
 	HandlePlandexFn(r, prefix+"/org_user_config", false, handlers.GetOrgUserConfigHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/org_user_config", false, handlers.UpdateOrgUserConfigHandler).Methods("PUT")
+
+	HandlePlandexFn(r, prefix+"/webhooks/deliveries", false, handlers.ListWebhookDeliveriesHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/webhooks/deliveries/{deliveryId}/replay", false, handlers.ReplayWebhookDeliveryHandler).Methods("POST")
+
+	HandlePlandexFn(r, prefix+"/audit", false, handlers.ListAuditLogHandler).Methods("GET")
+
+	HandlePlandexFn(r, prefix+"/budgets", false, handlers.ListBudgetsHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/budgets", false, handlers.SetBudgetHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/budgets/status", false, handlers.GetBudgetStatusHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/budgets/{budgetId}", false, handlers.DeleteBudgetHandler).Methods("DELETE")
+
+	HandlePlandexFn(r, prefix+"/usage", false, handlers.GetUsageReportHandler).Methods("GET")
+
+	HandlePlandexFn(r, prefix+"/exec_policy", false, handlers.GetOrgExecPolicyHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/exec_policy", false, handlers.SetOrgExecPolicyHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/exec_policy", false, handlers.DeleteOrgExecPolicyHandler).Methods("DELETE")
 }
 
 func addProxyableApiRoutes(r *mux.Router, prefix string) {
 	EnsureHandlePlandex()
 
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/connect", true, handlers.ConnectPlanHandler).Methods("PATCH")
+	// GET is for browser EventSource clients, which can only make GET requests
+	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/connect", true, handlers.ConnectPlanHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/stop", false, handlers.StopPlanHandler).Methods("DELETE")
 
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/respond_missing_file", false, handlers.RespondMissingFileHandler).Methods("POST")
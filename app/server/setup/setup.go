@@ -12,6 +12,7 @@ import (
 	"plandex-server/model/plan"
 	"plandex-server/notify"
 	"plandex-server/shutdown"
+	"plandex-server/webhooks"
 	"runtime/debug"
 	"syscall"
 	"time"
@@ -41,6 +42,13 @@ func MustInitDb() {
 	}
 }
 
+func MustLoadWebhooks() {
+	err := webhooks.LoadConfig()
+	if err != nil {
+		log.Fatal("Error loading webhooks config: ", err)
+	}
+}
+
 var shutdownHooks []func()
 
 func RegisterShutdownHook(hook func()) {
@@ -104,6 +112,8 @@ func StartServer(handler http.Handler, configureFn func(handler http.Handler) ht
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
+	go webhooks.RunWorker(shutdown.ShutdownCtx)
+
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to start server: %v", err)
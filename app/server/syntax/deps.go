@@ -0,0 +1,323 @@
+package syntax
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+var goModuleRegex = regexp.MustCompile(`(?m)^module\s+("?)([^\s"]+)("?)\s*$`)
+
+var jsResolveSuffixes = []string{"", ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".d.ts", ".svelte", ".vue", "/index.ts", "/index.tsx", "/index.js", "/index.jsx"}
+
+// dependencyResolver resolves import paths to files in the project
+type dependencyResolver struct {
+	paths map[string]bool
+	// project paths keyed by every trailing run of their path segments, so 'pkg/util.py' is under both 'util.py' and 'pkg/util.py'
+	bySuffix   map[string][]string
+	filesByDir map[string][]string
+	// go.mod module paths keyed by the directory they're in
+	goModules map[string]string
+}
+
+// BuildDependencyGraph returns, for each file in inputs, the project files it imports. Only files in projectPaths are resolved, so imports of packages outside the project are dropped. Any go.mod files in inputs are used to resolve Go imports.
+func BuildDependencyGraph(ctx context.Context, inputs map[string]string, projectPaths []string) (map[string][]string, error) {
+	r := &dependencyResolver{
+		paths:      map[string]bool{},
+		bySuffix:   map[string][]string{},
+		filesByDir: map[string][]string{},
+		goModules:  map[string]string{},
+	}
+
+	for _, p := range projectPaths {
+		p = path.Clean(p)
+		if r.paths[p] {
+			continue
+		}
+		r.paths[p] = true
+		r.filesByDir[path.Dir(p)] = append(r.filesByDir[path.Dir(p)], p)
+
+		segments := strings.Split(p, "/")
+		for i := range segments {
+			suffix := strings.Join(segments[i:], "/")
+			r.bySuffix[suffix] = append(r.bySuffix[suffix], p)
+		}
+	}
+
+	for p, content := range inputs {
+		if path.Base(p) == "go.mod" {
+			if m := goModuleRegex.FindStringSubmatch(content); m != nil {
+				r.goModules[path.Dir(path.Clean(p))] = m[2]
+			}
+		}
+	}
+
+	res := map[string][]string{}
+	for p, content := range inputs {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if path.Base(p) == "go.mod" {
+			continue
+		}
+
+		imports, err := GetImports(ctx, p, []byte(content))
+		if err != nil {
+			return nil, fmt.Errorf("error getting imports for %s: %v", p, err)
+		}
+
+		file := path.Clean(p)
+		seen := map[string]bool{file: true}
+		deps := []string{}
+		for _, imp := range imports {
+			for _, dep := range r.resolve(file, imp) {
+				if !seen[dep] {
+					seen[dep] = true
+					deps = append(deps, dep)
+				}
+			}
+		}
+		sort.Strings(deps)
+		res[p] = deps
+	}
+
+	return res, nil
+}
+
+func (r *dependencyResolver) resolve(file string, imp Import) []string {
+	dir := path.Dir(file)
+
+	switch GetLanguageForPath(file) {
+	case shared.LanguageGo:
+		return r.resolveGo(imp.Path)
+
+	case shared.LanguageJavascript, shared.LanguageTypescript, shared.LanguageJsx, shared.LanguageTsx:
+		// bare specifiers are packages, and aliases like '@/x' depend on bundler config
+		if !strings.HasPrefix(imp.Path, ".") {
+			return nil
+		}
+		return r.firstExisting(path.Join(dir, imp.Path), jsResolveSuffixes)
+
+	case shared.LanguagePython:
+		return r.resolvePython(dir, imp)
+
+	case shared.LanguageRust:
+		return r.resolveRust(file, imp)
+
+	case shared.LanguageJava, shared.LanguageKotlin, shared.LanguageScala:
+		segments := strings.Split(imp.Path, ".")
+		// 'com.x.Foo' is Foo in com/x, while a static import like 'com.x.Foo.bar' is one level down
+		for _, n := range []int{len(segments), len(segments) - 1} {
+			if n < 1 {
+				continue
+			}
+			base := strings.Join(segments[:n], "/")
+			for _, ext := range []string{".java", ".kt", ".scala"} {
+				if found := r.closestBySuffix(file, base+ext); len(found) > 0 {
+					return found
+				}
+			}
+		}
+
+	case shared.LanguageC, shared.LanguageCpp:
+		if found := r.firstExisting(path.Join(dir, imp.Path), []string{""}); len(found) > 0 {
+			return found
+		}
+		return r.closestBySuffix(file, path.Clean(imp.Path))
+
+	case shared.LanguageRuby:
+		if strings.HasPrefix(imp.Path, ".") {
+			return r.firstExisting(path.Join(dir, imp.Path), []string{"", ".rb"})
+		}
+		return r.closestBySuffix(file, strings.TrimSuffix(imp.Path, ".rb")+".rb")
+	}
+
+	return nil
+}
+
+func (r *dependencyResolver) firstExisting(base string, suffixes []string) []string {
+	for _, suffix := range suffixes {
+		if candidate := path.Clean(base + suffix); r.paths[candidate] {
+			return []string{candidate}
+		}
+	}
+	return nil
+}
+
+// closestBySuffix finds project files ending in suffix -- when there's more than one, the ones that share the longest directory prefix with the importing file are used
+func (r *dependencyResolver) closestBySuffix(file, suffix string) []string {
+	matches := r.bySuffix[suffix]
+	if len(matches) <= 1 {
+		return matches
+	}
+
+	best := -1
+	var res []string
+	for _, m := range matches {
+		n := commonDirPrefixLen(file, m)
+		if n > best {
+			best = n
+			res = []string{m}
+		} else if n == best {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+func commonDirPrefixLen(a, b string) int {
+	as := strings.Split(path.Dir(a), "/")
+	bs := strings.Split(path.Dir(b), "/")
+	n := 0
+	for n < len(as) && n < len(bs) && as[n] == bs[n] {
+		n++
+	}
+	return n
+}
+
+// resolveGo maps an import path to the non-test files of its package, using go.mod module paths where available and otherwise matching the trailing segments of a project directory
+func (r *dependencyResolver) resolveGo(imp string) []string {
+	var pkgDir string
+	for modDir, module := range r.goModules {
+		if imp == module || strings.HasPrefix(imp, module+"/") {
+			candidate := path.Join(modDir, strings.TrimPrefix(imp, module))
+			// with nested modules, the longest module path wins
+			if pkgDir == "" || len(candidate) > len(pkgDir) {
+				pkgDir = candidate
+			}
+		}
+	}
+
+	if pkgDir == "" {
+		// standard library packages have no dot in their first segment
+		segments := strings.Split(imp, "/")
+		if len(segments) < 2 || !strings.Contains(segments[0], ".") {
+			return nil
+		}
+		for i := 1; i < len(segments)-1 && pkgDir == ""; i++ {
+			suffix := strings.Join(segments[i:], "/")
+			for dir := range r.filesByDir {
+				if dir == suffix || strings.HasSuffix(dir, "/"+suffix) {
+					pkgDir = dir
+					break
+				}
+			}
+		}
+	}
+
+	var res []string
+	for _, f := range r.filesByDir[pkgDir] {
+		if strings.HasSuffix(f, ".go") && !strings.HasSuffix(f, "_test.go") {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+func (r *dependencyResolver) resolvePython(dir string, imp Import) []string {
+	module := imp.Path
+	var base string
+	relative := strings.HasPrefix(module, ".")
+	if relative {
+		// each leading dot past the first goes up a package
+		dots := len(module) - len(strings.TrimLeft(module, "."))
+		base = dir
+		for i := 1; i < dots; i++ {
+			base = path.Dir(base)
+		}
+		module = module[dots:]
+	}
+
+	resolveModule := func(dotted string) []string {
+		rel := strings.ReplaceAll(dotted, ".", "/")
+		if relative {
+			if rel == "" {
+				return r.firstExisting(path.Join(base, "__init__.py"), []string{""})
+			}
+			return r.firstExisting(path.Join(base, rel), []string{".py", "/__init__.py"})
+		}
+		if rel == "" {
+			return nil
+		}
+		for _, suffix := range []string{".py", "/__init__.py"} {
+			if found := r.bySuffix[rel+suffix]; len(found) > 0 {
+				return found
+			}
+		}
+		return nil
+	}
+
+	var res []string
+	// 'from pkg import mod' imports a module when mod isn't defined in pkg/__init__.py
+	for _, name := range imp.Names {
+		res = append(res, resolveModule(strings.Trim(module+"."+name, "."))...)
+	}
+	if len(res) == 0 {
+		res = resolveModule(module)
+	}
+	return res
+}
+
+// resolveRust resolves crate::, super::, and self:: paths to the module files they refer to
+func (r *dependencyResolver) resolveRust(file string, imp Import) []string {
+	segments := strings.Split(imp.Path, "::")
+	if len(segments) == 0 {
+		return nil
+	}
+
+	// the directory a module's children live in: src/db.rs and src/db/mod.rs both have children in src/db
+	moduleDir := strings.TrimSuffix(file, ".rs")
+	switch path.Base(file) {
+	case "mod.rs", "lib.rs", "main.rs":
+		moduleDir = path.Dir(file)
+	}
+
+	var base string
+	switch segments[0] {
+	case "crate":
+		base = path.Dir(file)
+		for base != "." && base != "/" && path.Base(base) != "src" {
+			base = path.Dir(base)
+		}
+		if path.Base(base) != "src" {
+			return nil
+		}
+	case "self":
+		base = moduleDir
+	case "super":
+		base = path.Dir(moduleDir)
+	default:
+		// external crates
+		return nil
+	}
+	segments = segments[1:]
+	for len(segments) > 0 && segments[0] == "super" {
+		base = path.Dir(base)
+		segments = segments[1:]
+	}
+
+	resolveModule := func(segments []string) []string {
+		// the path may end in an item rather than a module, like crate::db::Pool, so use the longest prefix that's a module file
+		for n := len(segments); n > 0; n-- {
+			modPath := path.Join(base, strings.Join(segments[:n], "/"))
+			if found := r.firstExisting(modPath, []string{".rs", "/mod.rs"}); len(found) > 0 {
+				return found
+			}
+		}
+		return nil
+	}
+
+	var res []string
+	for _, name := range imp.Names {
+		res = append(res, resolveModule(append(append([]string{}, segments...), name))...)
+	}
+	if len(res) == 0 {
+		res = resolveModule(segments)
+	}
+	return res
+}
@@ -0,0 +1,75 @@
+package syntax
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDependencyGraph(t *testing.T) {
+	projectPaths := []string{
+		"go.mod",
+		"main.go",
+		"internal/db/db.go",
+		"internal/db/db_test.go",
+		"web/src/app.ts",
+		"web/src/util/index.ts",
+		"web/src/api.js",
+		"py/pkg/__init__.py",
+		"py/pkg/models.py",
+		"py/pkg/views.py",
+		"py/pkg/sub/helpers.py",
+		"crate/src/lib.rs",
+		"crate/src/db.rs",
+		"crate/src/db/pool.rs",
+		"crate/src/util/mod.rs",
+		"native/lib.c",
+		"native/lib.h",
+	}
+
+	inputs := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.21\n",
+		"main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/app/internal/db"
+)
+
+func main() { fmt.Println(db.Open()) }
+`,
+		"web/src/app.ts": `import { format } from "./util";
+import React from "react";
+export * from "./api";
+const lazy = () => import("./missing");
+`,
+		"py/pkg/views.py": `import os
+from . import models
+from .sub.helpers import render
+`,
+		"crate/src/lib.rs": `mod db;
+mod util;
+use crate::db::{pool, Conn};
+use std::collections::HashMap;
+`,
+		"crate/src/db.rs": `use super::util::*;
+`,
+		"native/lib.c": `#include <stdio.h>
+#include "lib.h"
+`,
+	}
+
+	got, err := BuildDependencyGraph(context.Background(), inputs, projectPaths)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string][]string{
+		"main.go":          {"internal/db/db.go"},
+		"web/src/app.ts":   {"web/src/api.js", "web/src/util/index.ts"},
+		"py/pkg/views.py":  {"py/pkg/models.py", "py/pkg/sub/helpers.py"},
+		"crate/src/lib.rs": {"crate/src/db.rs", "crate/src/db/pool.rs", "crate/src/util/mod.rs"},
+		"crate/src/db.rs":  {"crate/src/util/mod.rs"},
+		"native/lib.c":     {"native/lib.h"},
+	}, got)
+}
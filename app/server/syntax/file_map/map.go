@@ -26,6 +26,8 @@ type Definition struct {
 	TagAttrs  []string     // For xml style markup tags, the class and id attributes
 	TagReps   int          // For tags, the number of times this tag is repeated
 	Line      int          // Line number where definition starts
+	EndLine   int          // Line number where definition ends, including its body
+	Name      string       // The defined name, used to select definitions for symbol context -- empty if it couldn't be determined
 	Children  []Definition // For parent types that can contain nested definitions
 }
 
@@ -192,8 +194,10 @@ func mapTraditional(baseNode Node, parentNode *Node) []Definition {
 				}
 
 				def := Definition{
-					Type: node.Type,
-					Line: int(tsNode.StartPoint().Row) + 1,
+					Type:    node.Type,
+					Line:    int(tsNode.StartPoint().Row) + 1,
+					EndLine: int(tsNode.EndPoint().Row) + 1,
+					Name:    definitionName(node),
 				}
 
 				if isAssignmentNode(node) {
@@ -258,6 +262,9 @@ func mapTraditional(baseNode Node, parentNode *Node) []Definition {
 							// collapse if signature is empty
 							if len(children) > 0 {
 								sig = children[0].Signature
+								if children[0].Name != "" {
+									def.Name = children[0].Name
+								}
 								grandchildren := children[0].Children
 								sibs := children[1:]
 								children = append(grandchildren, sibs...)
@@ -490,6 +497,7 @@ func mapMarkdownSimple(content []byte) []Definition {
 				defs = append(defs, Definition{
 					Type:      fmt.Sprintf("h%d", level),
 					Signature: heading,
+					Name:      heading,
 					Line:      i + 1,
 				})
 			}
@@ -510,6 +518,7 @@ func mapMarkdownSimple(content []byte) []Definition {
 					defs = append(defs, Definition{
 						Type:      "h1",
 						Signature: prevLine,
+						Name:      prevLine,
 						Line:      i, // Use previous line's number
 					})
 				} else if isAllDashes {
@@ -517,6 +526,7 @@ func mapMarkdownSimple(content []byte) []Definition {
 					defs = append(defs, Definition{
 						Type:      "h2",
 						Signature: prevLine,
+						Name:      prevLine,
 						Line:      i, // Use previous line's number
 					})
 				}
@@ -524,5 +534,16 @@ func mapMarkdownSimple(content []byte) []Definition {
 		}
 	}
 
+	// a section runs until the next heading at the same or a higher level
+	for i := range defs {
+		defs[i].EndLine = len(lines)
+		for _, next := range defs[i+1:] {
+			if next.Type <= defs[i].Type {
+				defs[i].EndLine = next.Line - 1
+				break
+			}
+		}
+	}
+
 	return defs
 }
@@ -0,0 +1,286 @@
+package file_map
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// fields that hold a definition's name, or a node that contains it, across tree-sitter grammars
+var nameFields = []string{"name", "declarator", "definition", "left", "pattern", "key", "type"}
+
+// definitionName finds the name a definition node defines, like 'Start' for 'func (s *Server) Start()'
+func definitionName(node Node) string {
+	name := findName(node.TsNode, node.Bytes, 0)
+	if name == "" {
+		name = nameFromSignature(string(node.TsNode.Content(node.Bytes)))
+	}
+
+	// Go methods aren't nested in their type, so qualify them with it
+	if node.Lang == shared.LanguageGo && node.Type == "method_declaration" && name != "" {
+		if receiver := receiverType(node.TsNode, node.Bytes); receiver != "" {
+			name = receiver + "." + name
+		}
+	}
+
+	return name
+}
+
+func findName(n *tree_sitter.Node, bytes []byte, depth int) string {
+	if n == nil || depth > 4 {
+		return ""
+	}
+
+	if isNameNode(n) {
+		return n.Content(bytes)
+	}
+
+	for _, field := range nameFields {
+		child := n.ChildByFieldName(field)
+		if child == nil {
+			continue
+		}
+		if name := findName(child, bytes, depth+1); name != "" {
+			return name
+		}
+	}
+
+	// declarations that wrap a single spec or declarator, like Go's 'type Foo struct' or JS's 'const foo = ...'
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		child := n.NamedChild(i)
+		t := child.Type()
+		if strings.HasSuffix(t, "_spec") || strings.HasSuffix(t, "_declarator") || strings.HasSuffix(t, "_definition") || strings.HasSuffix(t, "_declaration") {
+			if name := findName(child, bytes, depth+1); name != "" {
+				return name
+			}
+		}
+	}
+
+	// grammars like Kotlin's don't put the name in a field, so check the declaration's own children
+	if depth == 0 {
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			if child := n.NamedChild(i); isNameNode(child) {
+				return child.Content(bytes)
+			}
+		}
+	}
+
+	return ""
+}
+
+func isNameNode(n *tree_sitter.Node) bool {
+	t := n.Type()
+	return strings.HasSuffix(t, "identifier") || t == "name" || t == "constant" || t == "word" || t == "simple_identifier"
+}
+
+var identifierRegex = regexp.MustCompile(`[A-Za-z_$][A-Za-z0-9_$]*`)
+
+var signatureKeywords = map[string]bool{
+	"abstract": true, "async": true, "class": true, "const": true, "def": true, "default": true, "enum": true,
+	"export": true, "final": true, "fn": true, "func": true, "function": true, "impl": true, "interface": true,
+	"let": true, "module": true, "object": true, "override": true, "fun": true, "suspend": true, "data": true, "sealed": true, "open": true, "defp": true, "private": true, "protected": true, "pub": true,
+	"public": true, "static": true, "struct": true, "trait": true, "type": true, "val": true, "var": true,
+}
+
+// nameFromSignature is a fallback for grammars where the name isn't in a known field -- it takes the first identifier that isn't a keyword, before any parameters or body
+func nameFromSignature(sig string) string {
+	if i := strings.IndexAny(sig, "({=:<\n"); i > 0 {
+		if name := firstNonKeyword(sig[:i]); name != "" {
+			return name
+		}
+	}
+	return firstNonKeyword(sig)
+}
+
+func firstNonKeyword(s string) string {
+	for _, ident := range identifierRegex.FindAllString(s, -1) {
+		if !signatureKeywords[ident] {
+			return ident
+		}
+	}
+	return ""
+}
+
+// receiverType returns the type a Go method is defined on, like 'Server' for 'func (s *Server) Start()'
+func receiverType(n *tree_sitter.Node, bytes []byte) string {
+	receiver := n.ChildByFieldName("receiver")
+	if receiver == nil {
+		return ""
+	}
+
+	var find func(n *tree_sitter.Node) string
+	find = func(n *tree_sitter.Node) string {
+		if n.Type() == "type_identifier" {
+			return n.Content(bytes)
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			if name := find(n.NamedChild(i)); name != "" {
+				return name
+			}
+		}
+		return ""
+	}
+
+	return find(receiver)
+}
+
+// ExtractSymbols returns the source of the definitions in a file that match a symbol pattern like 'Server.Start' or 'Handle*,parse*', along with the signatures of any definitions that enclose them. Patterns that don't match any definition are returned as unmatched.
+func ExtractSymbols(ctx context.Context, filename string, content []byte, pattern string) (string, []string, error) {
+	patterns := shared.SplitSymbolPatterns(pattern)
+	if len(patterns) == 0 {
+		return "", nil, fmt.Errorf("no symbols given for %s", filename)
+	}
+
+	fileMap, err := MapFile(ctx, filename, content)
+	if err != nil {
+		return "", nil, fmt.Errorf("error mapping %s: %v", filename, err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+
+	var ranges []lineRange
+	matched := map[string]bool{}
+
+	var walk func(defs []Definition, qualified []string, parents []Definition)
+	walk = func(defs []Definition, qualified []string, parents []Definition) {
+		for _, def := range defs {
+			names := qualified
+			if def.Name != "" {
+				names = append(append([]string{}, qualified...), splitQualifiedName(def.Name)...)
+
+				var hit bool
+				for _, p := range patterns {
+					if matchSymbol(p, names) {
+						matched[p] = true
+						hit = true
+					}
+				}
+
+				// a selected definition already includes everything nested in it
+				if hit && def.Line > 0 && def.EndLine >= def.Line {
+					ranges = append(ranges, lineRange{start: precedingComments(lines, def.Line), end: def.EndLine})
+					for _, parent := range parents {
+						sigLines := strings.Count(strings.TrimSpace(parent.Signature), "\n") + 1
+						ranges = append(ranges, lineRange{start: parent.Line, end: min(parent.Line+sigLines-1, parent.EndLine)})
+					}
+					continue
+				}
+			}
+
+			walk(def.Children, names, append(append([]Definition{}, parents...), def))
+		}
+	}
+	walk(fileMap.Definitions, nil, nil)
+
+	var unmatched []string
+	for _, p := range patterns {
+		if !matched[p] {
+			unmatched = append(unmatched, p)
+		}
+	}
+
+	return renderLineRanges(lines, ranges), unmatched, nil
+}
+
+type lineRange struct {
+	start, end int // 1-based and inclusive
+}
+
+func splitQualifiedName(name string) []string {
+	return strings.FieldsFunc(strings.ReplaceAll(name, "::", "."), func(r rune) bool { return r == '.' })
+}
+
+// matchSymbol matches each dot-separated segment of a pattern against the trailing segments of a qualified name, so 'Start' matches 'Server.Start' and 'Server.*' matches every method of Server
+func matchSymbol(pattern string, qualified []string) bool {
+	segments := splitQualifiedName(pattern)
+	if len(segments) == 0 || len(segments) > len(qualified) {
+		return false
+	}
+
+	offset := len(qualified) - len(segments)
+	for i, segment := range segments {
+		ok, err := path.Match(segment, qualified[offset+i])
+		if err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+var commentPrefixes = []string{"//", "/*", "*", "#", "--", ";"}
+
+// precedingComments moves the start of a definition up to include any doc comment directly above it
+func precedingComments(lines []string, start int) int {
+	for start > 1 {
+		prev := strings.TrimSpace(lines[start-2])
+		var isComment bool
+		for _, prefix := range commentPrefixes {
+			if strings.HasPrefix(prev, prefix) {
+				isComment = true
+				break
+			}
+		}
+		if !isComment {
+			break
+		}
+		start--
+	}
+	return start
+}
+
+func onlyBlankLines(lines []string, from, to int) bool {
+	for _, line := range lines[from:to] {
+		if strings.TrimSpace(line) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func renderLineRanges(lines []string, ranges []lineRange) string {
+	if len(ranges) == 0 {
+		return ""
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start < ranges[j].start
+	})
+
+	merged := []lineRange{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.start <= last.end+1 || onlyBlankLines(lines, last.end, r.start-1) {
+			last.end = max(last.end, r.end)
+		} else {
+			merged = append(merged, r)
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range merged {
+		if i > 0 || r.start > 1 {
+			b.WriteString("⋮\n")
+		}
+		end := min(r.end, len(lines))
+		for _, line := range lines[r.start-1 : end] {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	total := len(lines)
+	if total > 0 && lines[total-1] == "" {
+		total--
+	}
+	if merged[len(merged)-1].end < total {
+		b.WriteString("⋮\n")
+	}
+
+	return b.String()
+}
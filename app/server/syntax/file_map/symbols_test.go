@@ -0,0 +1,118 @@
+package file_map
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const goSymbolsSource = `package server
+
+import "net/http"
+
+// Server handles requests
+type Server struct {
+	mux *http.ServeMux
+}
+
+// Start starts the server
+func (s *Server) Start() error {
+	return http.ListenAndServe(":8080", s.mux)
+}
+
+func (s *Server) Stop() error {
+	return nil
+}
+
+func HandleHealth(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func HandleReady(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+`
+
+const pythonSymbolsSource = `import os
+
+
+class Store:
+    def __init__(self, path):
+        self.path = path
+
+    def load(self):
+        with open(self.path) as f:
+            return f.read()
+
+    def save(self, data):
+        with open(self.path, "w") as f:
+            f.write(data)
+`
+
+func TestExtractSymbols(t *testing.T) {
+	tests := []struct {
+		name      string
+		filename  string
+		source    string
+		pattern   string
+		want      string
+		unmatched []string
+	}{
+		{
+			name:     "go method with doc comment",
+			filename: "server.go",
+			source:   goSymbolsSource,
+			pattern:  "Server.Start",
+			want: `⋮
+// Start starts the server
+func (s *Server) Start() error {
+	return http.ListenAndServe(":8080", s.mux)
+}
+⋮
+`,
+		},
+		{
+			name:     "glob and multiple patterns",
+			filename: "server.go",
+			source:   goSymbolsSource,
+			pattern:  "Handle*, Stop, Missing",
+			want: `⋮
+func (s *Server) Stop() error {
+	return nil
+}
+
+func HandleHealth(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func HandleReady(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+`,
+			unmatched: []string{"Missing"},
+		},
+		{
+			name:     "python method includes enclosing class signature",
+			filename: "store.py",
+			source:   pythonSymbolsSource,
+			pattern:  "Store.save",
+			want: `⋮
+class Store:
+⋮
+    def save(self, data):
+        with open(self.path, "w") as f:
+            f.write(data)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, unmatched, err := ExtractSymbols(context.Background(), tt.filename, []byte(tt.source), tt.pattern)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.unmatched, unmatched)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package syntax
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	shared "plandex-shared"
+
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Import is a module, package, or file referenced by an import statement, exactly as written in the source ('./util', '..models', 'crate::db::Pool', 'github.com/x/y')
+type Import struct {
+	Path string
+	// for Python's 'from pkg import a, b' and Rust's 'use pkg::{a, b}', the imported names, since each one may be a module of its own
+	Names []string
+}
+
+// GetImports returns the imports of a file for languages in shared.ImportGraphSupportSet
+func GetImports(ctx context.Context, path string, content []byte) ([]Import, error) {
+	parser, lang, fallbackParser, _ := GetParserForPath(path)
+	if parser == nil || !shared.ImportGraphSupportSet[lang] {
+		return nil, nil
+	}
+
+	tree, err := parser.ParseCtx(ctx, nil, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	defer tree.Close()
+
+	root := tree.RootNode()
+	if root.HasError() && fallbackParser != nil {
+		fallbackTree, err := fallbackParser.ParseCtx(ctx, nil, content)
+		if err == nil {
+			defer fallbackTree.Close()
+			if !fallbackTree.RootNode().HasError() {
+				root = fallbackTree.RootNode()
+			}
+		}
+	}
+
+	var imports []Import
+	add := func(path string, names ...string) {
+		if path != "" {
+			imports = append(imports, Import{Path: path, Names: names})
+		}
+	}
+
+	visitNodes(root, func(n *tree_sitter.Node) {
+		switch lang {
+		case shared.LanguageGo:
+			if n.Type() == "import_spec" {
+				add(stringContent(n.ChildByFieldName("path"), content))
+			}
+
+		case shared.LanguageJavascript, shared.LanguageTypescript, shared.LanguageJsx, shared.LanguageTsx:
+			switch n.Type() {
+			case "import_statement", "export_statement":
+				add(stringContent(n.ChildByFieldName("source"), content))
+			case "call_expression":
+				// require('./x') and dynamic import('./x')
+				fn := n.ChildByFieldName("function")
+				if fn == nil || (fn.Type() != "import" && fn.Content(content) != "require") {
+					return
+				}
+				if args := n.ChildByFieldName("arguments"); args != nil && args.NamedChildCount() > 0 {
+					add(stringContent(args.NamedChild(0), content))
+				}
+			}
+
+		case shared.LanguagePython:
+			switch n.Type() {
+			case "import_statement":
+				for i := 0; i < int(n.NamedChildCount()); i++ {
+					child := n.NamedChild(i)
+					if child.Type() == "aliased_import" {
+						child = child.ChildByFieldName("name")
+					}
+					if child != nil && child.Type() == "dotted_name" {
+						add(child.Content(content))
+					}
+				}
+			case "import_from_statement":
+				module := n.ChildByFieldName("module_name")
+				if module == nil {
+					return
+				}
+				var names []string
+				for i := 0; i < int(n.ChildCount()); i++ {
+					if n.FieldNameForChild(i) != "name" {
+						continue
+					}
+					child := n.Child(i)
+					if child.Type() == "aliased_import" {
+						child = child.ChildByFieldName("name")
+					}
+					if child != nil {
+						names = append(names, child.Content(content))
+					}
+				}
+				add(module.Content(content), names...)
+			}
+
+		case shared.LanguageRust:
+			switch n.Type() {
+			case "use_declaration":
+				if arg := n.ChildByFieldName("argument"); arg != nil {
+					p, names := rustUsePath(arg, content)
+					add(p, names...)
+				}
+			case "mod_item":
+				// 'mod foo;' without a body refers to foo.rs or foo/mod.rs
+				if n.ChildByFieldName("body") == nil {
+					if name := n.ChildByFieldName("name"); name != nil {
+						add("self::" + name.Content(content))
+					}
+				}
+			}
+
+		case shared.LanguageJava, shared.LanguageScala:
+			if n.Type() == "import_declaration" {
+				for i := 0; i < int(n.NamedChildCount()); i++ {
+					child := n.NamedChild(i)
+					if child.Type() == "scoped_identifier" || child.Type() == "identifier" || child.Type() == "stable_identifier" {
+						add(child.Content(content))
+						break
+					}
+				}
+			}
+
+		case shared.LanguageKotlin:
+			if n.Type() == "import_header" {
+				for i := 0; i < int(n.NamedChildCount()); i++ {
+					if child := n.NamedChild(i); child.Type() == "identifier" {
+						add(child.Content(content))
+						break
+					}
+				}
+			}
+
+		case shared.LanguageC, shared.LanguageCpp:
+			// only quoted includes are part of the project -- <system> headers are skipped
+			if n.Type() == "preproc_include" {
+				if p := n.ChildByFieldName("path"); p != nil && p.Type() == "string_literal" {
+					add(stringContent(p, content))
+				}
+			}
+
+		case shared.LanguageRuby:
+			if n.Type() != "call" {
+				return
+			}
+			method := n.ChildByFieldName("method")
+			if method == nil {
+				return
+			}
+			kind := method.Content(content)
+			if kind != "require" && kind != "require_relative" {
+				return
+			}
+			if args := n.ChildByFieldName("arguments"); args != nil && args.NamedChildCount() > 0 {
+				p := stringContent(args.NamedChild(0), content)
+				if kind == "require_relative" && p != "" && !strings.HasPrefix(p, ".") {
+					p = "./" + p
+				}
+				add(p)
+			}
+		}
+	})
+
+	return imports, nil
+}
+
+// stringContent returns the value of a string literal node without its quotes
+func stringContent(n *tree_sitter.Node, content []byte) string {
+	if n == nil {
+		return ""
+	}
+	return strings.Trim(n.Content(content), "\"'`")
+}
+
+// rustUsePath reduces a use tree like 'crate::db::{Pool, Conn}', 'super::util as u', or 'crate::db::*' to the path it refers to, along with any names in a use list, which may be modules of their own
+func rustUsePath(n *tree_sitter.Node, content []byte) (string, []string) {
+	switch n.Type() {
+	case "scoped_use_list":
+		p := n.ChildByFieldName("path")
+		list := n.ChildByFieldName("list")
+		if p == nil {
+			return "", nil
+		}
+		var names []string
+		if list != nil {
+			for i := 0; i < int(list.NamedChildCount()); i++ {
+				item := list.NamedChild(i)
+				if item.Type() == "use_as_clause" {
+					item = item.ChildByFieldName("path")
+				}
+				if item != nil && item.Type() == "identifier" {
+					names = append(names, item.Content(content))
+				}
+			}
+		}
+		return p.Content(content), names
+	case "use_as_clause":
+		if p := n.ChildByFieldName("path"); p != nil {
+			return p.Content(content), nil
+		}
+		return "", nil
+	case "use_wildcard":
+		return strings.TrimSuffix(n.Content(content), "::*"), nil
+	}
+	return n.Content(content), nil
+}
@@ -21,6 +21,9 @@ import (
 const MaxStreamRate = 70 * time.Millisecond
 const ActivePlanTimeout = 2 * time.Hour
 
+// number of stream events kept in memory so that clients can resume a stream after reconnecting
+const MaxStreamHistory = 5000
+
 type ActiveBuild struct {
 	ReplyId           string
 	FileDescription   string
@@ -36,12 +39,18 @@ type ActiveBuild struct {
 	IsResetOp         bool
 }
 
+// StreamEvent is a stream message along with its position in the active plan's stream
+type StreamEvent struct {
+	Seq  int64
+	Data string
+}
+
 type subscription struct {
-	ch           chan string
+	ch           chan StreamEvent
 	ctx          context.Context
 	cancelFn     context.CancelFunc
 	mu           sync.Mutex // Protects the messageQueue
-	messageQueue []string
+	messageQueue []StreamEvent
 	cond         *sync.Cond // Used to wait for and signal new messages
 }
 
@@ -82,9 +91,12 @@ type ActivePlan struct {
 	StoredReplyIds        []string
 	DidEditFiles          bool
 	SessionId             string
+	StreamId              string
 
 	subscriptions  map[string]*subscription
 	subscriptionMu sync.Mutex
+	streamSeq      int64
+	streamHistory  []StreamEvent
 
 	streamCh              chan string
 	streamMu              sync.Mutex
@@ -126,6 +138,7 @@ func NewActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, auto
 		AllowOverwritePaths:   map[string]bool{},
 		SkippedPaths:          map[string]bool{},
 		SessionId:             sessionId,
+		StreamId:              uuid.New().String(),
 		streamCh:              make(chan string),
 		subscriptions:         map[string]*subscription{},
 		subscriptionMu:        sync.Mutex{},
@@ -143,13 +156,18 @@ func NewActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, auto
 			case <-active.Ctx.Done():
 				return
 			case msg := <-active.streamCh:
-				var subscriptions map[string]*subscription
+				// history and subscriptions are updated under the same lock so a subscriber resuming from history can't miss or repeat an event
 				active.subscriptionMu.Lock()
-				subscriptions = active.subscriptions
-				active.subscriptionMu.Unlock()
-				for _, sub := range subscriptions {
-					sub.enqueueMessage(msg)
+				active.streamSeq++
+				event := StreamEvent{Seq: active.streamSeq, Data: msg}
+				active.streamHistory = append(active.streamHistory, event)
+				if len(active.streamHistory) > MaxStreamHistory {
+					active.streamHistory = active.streamHistory[len(active.streamHistory)-MaxStreamHistory:]
 				}
+				for _, sub := range active.subscriptions {
+					sub.enqueueMessage(event)
+				}
+				active.subscriptionMu.Unlock()
 
 			}
 		}
@@ -336,10 +354,31 @@ func (ap *ActivePlan) PathQueueEmpty(path string) bool {
 	return true
 }
 
-func (ap *ActivePlan) Subscribe(reqCtx context.Context) (string, chan string) {
+func (ap *ActivePlan) Subscribe(reqCtx context.Context) (string, chan StreamEvent) {
+	id, _, _, ch := ap.SubscribeFrom(reqCtx, -1)
+	return id, ch
+}
+
+// SubscribeFrom subscribes to the stream and also returns the retained events with a sequence number after afterSeq. Pass -1 to only receive new events. complete is false if some events after afterSeq are no longer retained.
+func (ap *ActivePlan) SubscribeFrom(reqCtx context.Context, afterSeq int64) (id string, backlog []StreamEvent, complete bool, ch chan StreamEvent) {
 	ap.subscriptionMu.Lock()
 	defer ap.subscriptionMu.Unlock()
-	id := uuid.New().String()
+	id = uuid.New().String()
+
+	complete = true
+	if afterSeq >= 0 {
+		for _, event := range ap.streamHistory {
+			if event.Seq > afterSeq {
+				backlog = append(backlog, event)
+			}
+		}
+
+		oldestRetained := ap.streamSeq + 1
+		if len(ap.streamHistory) > 0 {
+			oldestRetained = ap.streamHistory[0].Seq
+		}
+		complete = afterSeq >= oldestRetained-1
+	}
 
 	planCtx := ap.Ctx // from the plan
 
@@ -361,7 +400,7 @@ func (ap *ActivePlan) Subscribe(reqCtx context.Context) (string, chan string) {
 	sub := newSubscription(subCtx)
 
 	ap.subscriptions[id] = sub
-	return id, sub.ch
+	return id, backlog, complete, sub.ch
 }
 
 func (ap *ActivePlan) Unsubscribe(id string) {
@@ -390,10 +429,10 @@ func (b *ActiveBuild) BuildFinished() bool {
 func newSubscription(ctx context.Context) *subscription {
 	ctx, cancel := context.WithCancel(ctx)
 	sub := &subscription{
-		ch:           make(chan string),
+		ch:           make(chan StreamEvent),
 		ctx:          ctx,
 		cancelFn:     cancel,
-		messageQueue: make([]string, 0),
+		messageQueue: make([]StreamEvent, 0),
 	}
 	sub.mu = sync.Mutex{}
 	sub.cond = sync.NewCond(&sub.mu)
@@ -427,7 +466,7 @@ func (sub *subscription) processMessages() {
 }
 
 // Adding a message to the subscription's queue
-func (sub *subscription) enqueueMessage(msg string) {
+func (sub *subscription) enqueueMessage(msg StreamEvent) {
 	// log.Printf("ActivePlan: enqueueing message: %s\n", msg)
 	sub.mu.Lock()
 	sub.messageQueue = append(sub.messageQueue, msg)
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"plandex-server/shutdown"
+	"testing"
+)
+
+func newTestActivePlan(t *testing.T) *ActivePlan {
+	if shutdown.ShutdownCtx == nil {
+		shutdown.ShutdownCtx, shutdown.ShutdownCancel = context.WithCancel(context.Background())
+	}
+
+	ap := NewActivePlan("org", "user", "plan", "main", "", false, false, "")
+	t.Cleanup(ap.CancelFn)
+	return ap
+}
+
+// sendAndWait pushes n messages through the stream manager and waits until a subscriber has received all of them
+func sendAndWait(t *testing.T, ap *ActivePlan, n int) {
+	_, ch := ap.Subscribe(context.Background())
+
+	go func() {
+		for i := 0; i < n; i++ {
+			ap.streamCh <- fmt.Sprintf(`{"type":"reply","replyChunk":"%d"}`, i)
+		}
+	}()
+
+	for i := 0; i < n; i++ {
+		<-ch
+	}
+}
+
+func TestSubscribeFromResumesAfterSeq(t *testing.T) {
+	ap := newTestActivePlan(t)
+	sendAndWait(t, ap, 3)
+
+	_, backlog, complete, _ := ap.SubscribeFrom(context.Background(), 1)
+	if !complete {
+		t.Fatalf("expected complete backlog")
+	}
+	if len(backlog) != 2 || backlog[0].Seq != 2 || backlog[1].Seq != 3 {
+		t.Fatalf("expected events 2 and 3, got %v", backlog)
+	}
+
+	_, backlog, complete, _ = ap.SubscribeFrom(context.Background(), -1)
+	if !complete || len(backlog) != 0 {
+		t.Fatalf("expected no backlog for new subscriber, got %v", backlog)
+	}
+
+	_, backlog, complete, _ = ap.SubscribeFrom(context.Background(), 3)
+	if !complete || len(backlog) != 0 {
+		t.Fatalf("expected no backlog for up to date subscriber, got %v", backlog)
+	}
+}
+
+func TestSubscribeFromReportsDroppedEvents(t *testing.T) {
+	ap := newTestActivePlan(t)
+	sendAndWait(t, ap, MaxStreamHistory+2)
+
+	_, backlog, complete, _ := ap.SubscribeFrom(context.Background(), 0)
+	if complete {
+		t.Fatalf("expected incomplete backlog after history was trimmed")
+	}
+	if len(backlog) != MaxStreamHistory {
+		t.Fatalf("expected %d retained events, got %d", MaxStreamHistory, len(backlog))
+	}
+
+	_, _, complete, _ = ap.SubscribeFrom(context.Background(), 2)
+	if !complete {
+		t.Fatalf("expected complete backlog from the oldest retained event")
+	}
+}
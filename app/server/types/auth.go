@@ -7,12 +7,24 @@ import (
 )
 
 type ServerAuth struct {
-	AuthToken   *db.AuthToken
+	AuthToken *db.AuthToken
+
+	// set instead of AuthToken when the request is authenticated with an API token -- User is the token's creator
+	ApiToken *db.ApiToken
+
 	User        *db.User
 	OrgId       string
 	Permissions shared.Permissions
 }
 
+// CanAccessProject returns false if the request is authenticated with an API token that's scoped to a different project
+func (a *ServerAuth) CanAccessProject(projectId string) bool {
+	if a.ApiToken == nil || a.ApiToken.ProjectId == nil {
+		return true
+	}
+	return *a.ApiToken.ProjectId == projectId
+}
+
 func (a *ServerAuth) HasPermission(permission shared.Permission) bool {
 	return a.Permissions.HasPermission(permission)
 }
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"plandex-server/db"
+	"testing"
+)
+
+func TestCanAccessProject(t *testing.T) {
+	projectId := "project-1"
+
+	tests := []struct {
+		name string
+		auth ServerAuth
+		want bool
+	}{
+		{"session", ServerAuth{AuthToken: &db.AuthToken{}}, true},
+		{"unscoped api token", ServerAuth{ApiToken: &db.ApiToken{}}, true},
+		{"api token for the project", ServerAuth{ApiToken: &db.ApiToken{ProjectId: &projectId}}, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.auth.CanAccessProject(projectId); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	auth := ServerAuth{ApiToken: &db.ApiToken{ProjectId: &projectId}}
+	if auth.CanAccessProject("project-2") {
+		t.Error("api token scoped to one project could access another")
+	}
+}
@@ -0,0 +1,147 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Webhook endpoints are configured with a JSON file, pointed to by the WEBHOOKS_CONFIG_PATH environment variable:
+//
+//	{
+//	  "endpoints": [
+//	    {
+//	      "id": "ci",
+//	      "url": "https://ci.example.com/plandex",
+//	      "secretEnvVar": "CI_WEBHOOK_SECRET",
+//	      "events": ["plan.tell_finished", "plan.build_finished", "plan.applied"]
+//	    }
+//	  ]
+//	}
+//
+// An endpoint with no 'events' receives every event. An event pattern ending in '.*' matches a whole group (e.g. "plan.*").
+// 'orgIds' optionally limits an endpoint to specific orgs.
+
+const defaultMaxAttempts = 8
+const defaultTimeout = 10 * time.Second
+
+type Endpoint struct {
+	Id             string   `json:"id"`
+	Url            string   `json:"url"`
+	Secret         string   `json:"secret"`
+	SecretEnvVar   string   `json:"secretEnvVar"`
+	Events         []string `json:"events"`
+	OrgIds         []string `json:"orgIds"`
+	MaxAttempts    int      `json:"maxAttempts"`
+	TimeoutSeconds int      `json:"timeoutSeconds"`
+}
+
+type Config struct {
+	Endpoints []*Endpoint `json:"endpoints"`
+}
+
+var config *Config
+var endpointsById = map[string]*Endpoint{}
+
+func Enabled() bool {
+	return config != nil && len(config.Endpoints) > 0
+}
+
+func LoadConfig() error {
+	path := os.Getenv("WEBHOOKS_CONFIG_PATH")
+	if path == "" {
+		return nil
+	}
+
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading webhooks config: %v", err)
+	}
+
+	var c Config
+	err = json.Unmarshal(bytes, &c)
+	if err != nil {
+		return fmt.Errorf("error parsing webhooks config: %v", err)
+	}
+
+	byId := map[string]*Endpoint{}
+	for i, endpoint := range c.Endpoints {
+		if endpoint.Id == "" {
+			return fmt.Errorf("webhook endpoint %d is missing an id", i)
+		}
+
+		if byId[endpoint.Id] != nil {
+			return fmt.Errorf("duplicate webhook endpoint id: %s", endpoint.Id)
+		}
+
+		parsed, err := url.Parse(endpoint.Url)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("webhook endpoint %s has an invalid url: %s", endpoint.Id, endpoint.Url)
+		}
+
+		if endpoint.SecretEnvVar != "" {
+			endpoint.Secret = os.Getenv(endpoint.SecretEnvVar)
+			if endpoint.Secret == "" {
+				return fmt.Errorf("webhook endpoint %s: %s is not set", endpoint.Id, endpoint.SecretEnvVar)
+			}
+		}
+
+		if endpoint.Secret == "" {
+			log.Printf("Warning: webhook endpoint %s has no secret -- requests will be unsigned\n", endpoint.Id)
+		}
+
+		if endpoint.MaxAttempts <= 0 {
+			endpoint.MaxAttempts = defaultMaxAttempts
+		}
+
+		byId[endpoint.Id] = endpoint
+	}
+
+	config = &c
+	endpointsById = byId
+
+	log.Printf("Loaded %d webhook endpoint(s)\n", len(c.Endpoints))
+
+	return nil
+}
+
+func (e *Endpoint) timeout() time.Duration {
+	if e.TimeoutSeconds > 0 {
+		return time.Duration(e.TimeoutSeconds) * time.Second
+	}
+	return defaultTimeout
+}
+
+func (e *Endpoint) matches(eventType EventType, orgId string) bool {
+	if len(e.OrgIds) > 0 {
+		found := false
+		for _, id := range e.OrgIds {
+			if id == orgId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(e.Events) == 0 {
+		return true
+	}
+
+	for _, pattern := range e.Events {
+		if pattern == "*" || pattern == string(eventType) {
+			return true
+		}
+		if strings.HasSuffix(pattern, ".*") && strings.HasPrefix(string(eventType), strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
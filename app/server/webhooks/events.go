@@ -0,0 +1,153 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"plandex-server/db"
+	"plandex-server/notify"
+	"runtime/debug"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventPlanCreated       EventType = "plan.created"
+	EventPlanWillTell      EventType = "plan.will_tell"
+	EventPlanTellFinished  EventType = "plan.tell_finished"
+	EventPlanBuildFinished EventType = "plan.build_finished"
+	EventPlanFailed        EventType = "plan.failed"
+	EventPlanApplied       EventType = "plan.applied"
+	EventPlanRewound       EventType = "plan.rewound"
+	EventPlanStopped       EventType = "plan.stopped"
+
+	EventModelRequestSent   EventType = "model.request_sent"
+	EventBuilderRunFinished EventType = "builder.run_finished"
+
+	EventBudgetExceeded EventType = "budget.exceeded"
+)
+
+// Event is the JSON body POSTed to webhook endpoints
+type Event struct {
+	Id        string      `json:"id"`
+	Type      EventType   `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+	OrgId     string      `json:"orgId"`
+	UserId    string      `json:"userId,omitempty"`
+	PlanId    string      `json:"planId,omitempty"`
+	Branch    string      `json:"branch,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+type EmitParams struct {
+	Type   EventType
+	OrgId  string
+	UserId string
+	PlanId string
+	Branch string
+	Data   interface{}
+}
+
+// Emit queues an event for delivery to every matching endpoint.
+// It returns immediately -- the delivery rows are written in the background and picked up by the worker.
+func Emit(params EmitParams) {
+	if !Enabled() || params.OrgId == "" {
+		return
+	}
+
+	var endpoints []*Endpoint
+	for _, endpoint := range config.Endpoints {
+		if endpoint.matches(params.Type, params.OrgId) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	if len(endpoints) == 0 {
+		return
+	}
+
+	event := Event{
+		Id:        uuid.New().String(),
+		Type:      params.Type,
+		CreatedAt: time.Now().UTC(),
+		OrgId:     params.OrgId,
+		UserId:    params.UserId,
+		PlanId:    params.PlanId,
+		Branch:    params.Branch,
+		Data:      params.Data,
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic in webhooks.Emit: %v\n%s", r, debug.Stack())
+				go notify.NotifyErr(notify.SeverityError, fmt.Errorf("panic in webhooks.Emit: %v\n%s", r, debug.Stack()))
+			}
+		}()
+
+		payload, err := json.Marshal(event)
+		if err != nil {
+			log.Printf("Error marshalling webhook event %s: %v\n", event.Type, err)
+			return
+		}
+
+		var planId *string
+		if event.PlanId != "" {
+			planId = &event.PlanId
+		}
+
+		deliveries := make([]*db.WebhookDelivery, len(endpoints))
+		for i, endpoint := range endpoints {
+			deliveries[i] = &db.WebhookDelivery{
+				OrgId:      event.OrgId,
+				EndpointId: endpoint.Id,
+				Url:        endpoint.Url,
+				EventId:    event.Id,
+				EventType:  string(event.Type),
+				PlanId:     planId,
+				Payload:    payload,
+			}
+		}
+
+		err = db.CreateWebhookDeliveries(deliveries)
+		if err != nil {
+			log.Printf("Error queueing webhook deliveries for %s: %v\n", event.Type, err)
+			go notify.NotifyErr(notify.SeverityError, fmt.Errorf("error queueing webhook deliveries for %s: %v", event.Type, err))
+			return
+		}
+
+		wake()
+	}()
+}
+
+// Replay queues a new delivery of a previously sent event to the same endpoint
+func Replay(delivery *db.WebhookDelivery) (*db.WebhookDelivery, error) {
+	endpoint := endpointsById[delivery.EndpointId]
+	if endpoint == nil {
+		return nil, fmt.Errorf("webhook endpoint %s is no longer configured", delivery.EndpointId)
+	}
+
+	replayOfId := delivery.Id
+	replay := &db.WebhookDelivery{
+		OrgId:      delivery.OrgId,
+		EndpointId: endpoint.Id,
+		Url:        endpoint.Url,
+		EventId:    delivery.EventId,
+		EventType:  delivery.EventType,
+		PlanId:     delivery.PlanId,
+		Payload:    delivery.Payload,
+		ReplayOfId: &replayOfId,
+	}
+
+	err := db.CreateWebhookDeliveries([]*db.WebhookDelivery{replay})
+	if err != nil {
+		return nil, err
+	}
+
+	wake()
+
+	return replay, nil
+}
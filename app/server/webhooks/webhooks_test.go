@@ -0,0 +1,89 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEndpointMatches(t *testing.T) {
+	tcs := []struct {
+		name      string
+		endpoint  Endpoint
+		eventType EventType
+		orgId     string
+		want      bool
+	}{
+		{
+			name:      "no filters",
+			endpoint:  Endpoint{},
+			eventType: EventPlanApplied,
+			orgId:     "org1",
+			want:      true,
+		},
+		{
+			name:      "exact event",
+			endpoint:  Endpoint{Events: []string{"plan.applied"}},
+			eventType: EventPlanApplied,
+			orgId:     "org1",
+			want:      true,
+		},
+		{
+			name:      "other event",
+			endpoint:  Endpoint{Events: []string{"plan.applied"}},
+			eventType: EventPlanRewound,
+			orgId:     "org1",
+			want:      false,
+		},
+		{
+			name:      "group pattern",
+			endpoint:  Endpoint{Events: []string{"plan.*"}},
+			eventType: EventPlanTellFinished,
+			orgId:     "org1",
+			want:      true,
+		},
+		{
+			name:      "group pattern doesn't match other group",
+			endpoint:  Endpoint{Events: []string{"plan.*"}},
+			eventType: EventModelRequestSent,
+			orgId:     "org1",
+			want:      false,
+		},
+		{
+			name:      "org filter",
+			endpoint:  Endpoint{OrgIds: []string{"org2"}},
+			eventType: EventPlanApplied,
+			orgId:     "org1",
+			want:      false,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.endpoint.matches(tc.eventType, tc.orgId)
+			if got != tc.want {
+				t.Errorf("matches() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{"a":1}' | openssl dgst -sha256 -hmac secret
+	want := "49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686"
+	got := Sign("secret", "1700000000", []byte(`{"a":1}`))
+	if got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	if retryDelay(0) != baseRetryDelay {
+		t.Errorf("first retry delay = %v, want %v", retryDelay(0), baseRetryDelay)
+	}
+	if retryDelay(2) != 4*baseRetryDelay {
+		t.Errorf("third retry delay = %v, want %v", retryDelay(2), 4*baseRetryDelay)
+	}
+	if retryDelay(50) != time.Hour {
+		t.Errorf("retry delay should be capped at %v", maxRetryDelay)
+	}
+}
@@ -0,0 +1,198 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/notify"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const pollInterval = 5 * time.Second
+const claimBatchSize = 20
+const staleClaimTimeout = 5 * time.Minute
+const baseRetryDelay = 10 * time.Second
+const maxRetryDelay = time.Hour
+const maxErrorBodyBytes = 1024
+
+var wakeCh = make(chan struct{}, 1)
+
+var httpClient = &http.Client{}
+
+func wake() {
+	select {
+	case wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// RunWorker sends pending deliveries until ctx is canceled. It's a no-op if no endpoints are configured.
+func RunWorker(ctx context.Context) {
+	if !Enabled() {
+		return
+	}
+
+	log.Println("Starting webhook delivery worker")
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	lastStaleCheck := time.Time{}
+
+	for {
+		if time.Since(lastStaleCheck) > staleClaimTimeout {
+			err := db.ReleaseStaleWebhookDeliveries(staleClaimTimeout)
+			if err != nil {
+				log.Printf("Webhook worker: %v\n", err)
+			}
+			lastStaleCheck = time.Now()
+		}
+
+		processBatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Println("Webhook delivery worker stopped")
+			return
+		case <-ticker.C:
+		case <-wakeCh:
+		}
+	}
+}
+
+func processBatch(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("panic in webhook processBatch: %v\n%s", r, debug.Stack())
+			go notify.NotifyErr(notify.SeverityError, fmt.Errorf("panic in webhook processBatch: %v\n%s", r, debug.Stack()))
+		}
+	}()
+
+	deliveries, err := db.ClaimWebhookDeliveries(claimBatchSize)
+	if err != nil {
+		log.Printf("Webhook worker: %v\n", err)
+		return
+	}
+
+	if len(deliveries) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery *db.WebhookDelivery) {
+			defer wg.Done()
+			attemptDelivery(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+}
+
+func attemptDelivery(ctx context.Context, delivery *db.WebhookDelivery) {
+	endpoint := endpointsById[delivery.EndpointId]
+	if endpoint == nil {
+		err := db.MarkWebhookAttemptFailed(delivery.Id, nil, "endpoint is no longer configured", nil)
+		if err != nil {
+			log.Printf("Webhook worker: %v\n", err)
+		}
+		return
+	}
+
+	statusCode, sendErr := send(ctx, endpoint, delivery)
+
+	if sendErr == nil {
+		log.Printf("Webhook %s delivered to %s (%s)\n", delivery.EventType, endpoint.Id, delivery.Id)
+		err := db.MarkWebhookDelivered(delivery.Id, statusCode)
+		if err != nil {
+			log.Printf("Webhook worker: %v\n", err)
+		}
+		return
+	}
+
+	log.Printf("Webhook %s delivery to %s failed (attempt %d): %v\n", delivery.EventType, endpoint.Id, delivery.NumAttempts+1, sendErr)
+
+	var statusCodePtr *int
+	if statusCode != 0 {
+		statusCodePtr = &statusCode
+	}
+
+	var nextAttemptAt *time.Time
+	if delivery.NumAttempts+1 < endpoint.MaxAttempts {
+		t := time.Now().Add(retryDelay(delivery.NumAttempts))
+		nextAttemptAt = &t
+	}
+
+	err := db.MarkWebhookAttemptFailed(delivery.Id, statusCodePtr, sendErr.Error(), nextAttemptAt)
+	if err != nil {
+		log.Printf("Webhook worker: %v\n", err)
+	}
+}
+
+func send(ctx context.Context, endpoint *Endpoint, delivery *db.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, endpoint.timeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %v", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Plandex-Webhooks")
+	req.Header.Set("X-Plandex-Event", delivery.EventType)
+	req.Header.Set("X-Plandex-Event-Id", delivery.EventId)
+	req.Header.Set("X-Plandex-Delivery", delivery.Id)
+	req.Header.Set("X-Plandex-Timestamp", timestamp)
+	if endpoint.Secret != "" {
+		req.Header.Set("X-Plandex-Signature", "sha256="+Sign(endpoint.Secret, timestamp, delivery.Payload))
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error sending request: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		io.Copy(io.Discard, res.Body)
+		return res.StatusCode, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodyBytes))
+	return res.StatusCode, fmt.Errorf("endpoint responded with status %d: %s", res.StatusCode, string(body))
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of "<timestamp>.<payload>".
+// Receivers should recompute it with their secret and compare against the X-Plandex-Signature header,
+// and reject requests with a stale X-Plandex-Timestamp to prevent replays.
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func retryDelay(numPriorAttempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 0; i < numPriorAttempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
@@ -43,6 +43,8 @@ When checking for sufficient credits on Plandex Cloud, we use MaxOutputTokens-In
 
 'PredictedOutputEnabled' is used to enable predicted output for the model (currently only supported by gpt-4o).
 
+'InputCostPerMillion', 'CachedInputCostPerMillion', and 'OutputCostPerMillion' are the publisher's list prices in USD per million tokens. They're used to compute spend against budgets on self-hosted servers. Models without prices (like local models) only count toward token budgets.
+
 'ApiKeyEnvVar' is the environment variable that contains the API key for the model.
 */
 
@@ -56,6 +58,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			ReservedOutputTokens: 40000, ModelCompatibility: FullCompatibility,
 			PreferredOutputFormat: ModelOutputFormatXml, SystemPromptDisabled: true,
 			RoleParamsDisabled: true, ReasoningEffortEnabled: true, StopDisabled: true,
+			InputCostPerMillion: 2, CachedInputCostPerMillion: 0.5, OutputCostPerMillion: 8,
 		},
 		RequiresVariantOverrides: []string{"ReasoningEffort"},
 		Variants: []BaseModelConfigVariant{
@@ -78,7 +81,8 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			ReservedOutputTokens: 40000, ModelCompatibility: FullCompatibility,
 			PreferredOutputFormat: ModelOutputFormatToolCallJson, SystemPromptDisabled: true,
 			RoleParamsDisabled: true, ReasoningEffortEnabled: true, ReasoningEffort: ReasoningEffortHigh,
-			StopDisabled: true,
+			StopDisabled:        true,
+			InputCostPerMillion: 1.1, CachedInputCostPerMillion: 0.275, OutputCostPerMillion: 4.4,
 		},
 		RequiresVariantOverrides: []string{"ReasoningEffort"},
 		Variants: []BaseModelConfigVariant{
@@ -100,6 +104,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 75000, MaxTokens: 1047576,
 			MaxOutputTokens: 32768, ReservedOutputTokens: 32768,
 			ModelCompatibility: FullCompatibility, PreferredOutputFormat: ModelOutputFormatToolCallJson,
+			InputCostPerMillion: 2, CachedInputCostPerMillion: 0.5, OutputCostPerMillion: 8,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderOpenAI, ModelName: "gpt-4.1"},
@@ -115,6 +120,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 75000, MaxTokens: 1047576,
 			MaxOutputTokens: 32768, ReservedOutputTokens: 32768,
 			ModelCompatibility: FullCompatibility, PreferredOutputFormat: ModelOutputFormatToolCallJson,
+			InputCostPerMillion: 0.4, CachedInputCostPerMillion: 0.1, OutputCostPerMillion: 1.6,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderOpenAI, ModelName: "gpt-4.1-mini"},
@@ -130,6 +136,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 75000, MaxTokens: 1047576,
 			MaxOutputTokens: 32768, ReservedOutputTokens: 32768,
 			ModelCompatibility: FullCompatibility, PreferredOutputFormat: ModelOutputFormatToolCallJson,
+			InputCostPerMillion: 0.1, CachedInputCostPerMillion: 0.025, OutputCostPerMillion: 0.4,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderOpenAI, ModelName: "gpt-4.1-nano"},
@@ -146,6 +153,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			ReservedOutputTokens: 20000, SupportsCacheControl: true,
 			PreferredOutputFormat: ModelOutputFormatXml, SingleMessageNoSystemPrompt: true,
 			TokenEstimatePaddingPct: 0.10,
+			InputCostPerMillion:     15, CachedInputCostPerMillion: 1.5, OutputCostPerMillion: 75,
 		},
 		Variants: []BaseModelConfigVariant{
 			{IsBaseVariant: true},
@@ -175,6 +183,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			ReservedOutputTokens: 40000, SupportsCacheControl: true,
 			PreferredOutputFormat: ModelOutputFormatXml, SingleMessageNoSystemPrompt: true,
 			TokenEstimatePaddingPct: 0.10,
+			InputCostPerMillion:     3, CachedInputCostPerMillion: 0.3, OutputCostPerMillion: 15,
 		},
 		Variants: []BaseModelConfigVariant{
 			{IsBaseVariant: true},
@@ -203,6 +212,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			ReservedOutputTokens: 20000, SupportsCacheControl: true,
 			PreferredOutputFormat: ModelOutputFormatXml, SingleMessageNoSystemPrompt: true,
 			TokenEstimatePaddingPct: 0.10,
+			InputCostPerMillion:     3, CachedInputCostPerMillion: 0.3, OutputCostPerMillion: 15,
 		},
 		Variants: []BaseModelConfigVariant{
 			{IsBaseVariant: true},
@@ -231,6 +241,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			ReservedOutputTokens: 20000, SupportsCacheControl: true,
 			PreferredOutputFormat: ModelOutputFormatXml, SingleMessageNoSystemPrompt: true,
 			TokenEstimatePaddingPct: 0.10,
+			InputCostPerMillion:     3, CachedInputCostPerMillion: 0.3, OutputCostPerMillion: 15,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderAnthropic, ModelName: "anthropic/claude-3-5-sonnet-latest"},
@@ -248,6 +259,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			ReservedOutputTokens: 8192, SupportsCacheControl: true,
 			PreferredOutputFormat: ModelOutputFormatXml, SingleMessageNoSystemPrompt: true,
 			TokenEstimatePaddingPct: 0.10,
+			InputCostPerMillion:     0.8, CachedInputCostPerMillion: 0.08, OutputCostPerMillion: 4,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderAnthropic, ModelName: "anthropic/claude-3-5-haiku-latest"},
@@ -264,6 +276,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 75000, MaxTokens: 2000000,
 			MaxOutputTokens: 8192, ReservedOutputTokens: 8192,
 			PreferredOutputFormat: ModelOutputFormatXml,
+			InputCostPerMillion:   1.25, OutputCostPerMillion: 5,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderGoogleAIStudio, ModelName: "gemini/gemini-1.5-pro"},
@@ -279,6 +292,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 75000, MaxTokens: 1048576,
 			MaxOutputTokens: 65535, ReservedOutputTokens: 65535,
 			PreferredOutputFormat: ModelOutputFormatXml,
+			InputCostPerMillion:   1.25, CachedInputCostPerMillion: 0.31, OutputCostPerMillion: 10,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderGoogleAIStudio, ModelName: "gemini/gemini-2.5-pro"},
@@ -294,6 +308,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 75000, MaxTokens: 1048576,
 			MaxOutputTokens: 65535, ReservedOutputTokens: 65535,
 			PreferredOutputFormat: ModelOutputFormatXml,
+			InputCostPerMillion:   0.3, CachedInputCostPerMillion: 0.075, OutputCostPerMillion: 2.5,
 		},
 		Variants: []BaseModelConfigVariant{
 			{IsBaseVariant: true},
@@ -321,6 +336,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 7500, MaxTokens: 64000,
 			MaxOutputTokens: 8192, ReservedOutputTokens: 8192,
 			PreferredOutputFormat: ModelOutputFormatXml,
+			InputCostPerMillion:   0.27, CachedInputCostPerMillion: 0.07, OutputCostPerMillion: 1.1,
 		},
 		Providers: []BaseModelUsesProvider{
 			{Provider: ModelProviderDeepSeek, ModelName: "deepseek/deepseek-chat"},
@@ -335,6 +351,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 7500, MaxTokens: 164000,
 			MaxOutputTokens: 33000, ReservedOutputTokens: 20000,
 			PreferredOutputFormat: ModelOutputFormatXml,
+			InputCostPerMillion:   0.55, CachedInputCostPerMillion: 0.14, OutputCostPerMillion: 2.19,
 		},
 		Variants: []BaseModelConfigVariant{
 			{VariantTag: "visible", IsDefaultVariant: true, Description: "(reasoning visible)", Overrides: BaseModelShared{IncludeReasoning: true}},
@@ -540,6 +557,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 7500, MaxTokens: 128000,
 			MaxOutputTokens: 128000, ReservedOutputTokens: 30000,
 			PreferredOutputFormat: ModelOutputFormatXml,
+			InputCostPerMillion:   2, OutputCostPerMillion: 8,
 		},
 		Variants: []BaseModelConfigVariant{
 			{VariantTag: "visible", IsDefaultVariant: true, Description: "(reasoning visible)", Overrides: BaseModelShared{IncludeReasoning: true}},
@@ -573,6 +591,7 @@ var BuiltInModels = []*BaseModelConfigSchema{
 			DefaultMaxConvoTokens: 7500, MaxTokens: 127000,
 			MaxOutputTokens: 127000, ReservedOutputTokens: 30000,
 			PreferredOutputFormat: ModelOutputFormatXml,
+			InputCostPerMillion:   1, OutputCostPerMillion: 5,
 		},
 		Variants: []BaseModelConfigVariant{
 			{VariantTag: "visible", IsDefaultVariant: true, Description: "(reasoning visible)", Overrides: BaseModelShared{IncludeReasoning: true}},
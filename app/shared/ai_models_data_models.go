@@ -46,6 +46,9 @@ type BaseModelShared struct {
 	SupportsCacheControl        bool              `json:"supportsCacheControl,omitempty"`
 	SingleMessageNoSystemPrompt bool              `json:"singleMessageNoSystemPrompt,omitempty"`
 	TokenEstimatePaddingPct     float64           `json:"tokenEstimatePaddingPct,omitempty"`
+	InputCostPerMillion         float64           `json:"inputCostPerMillion,omitempty"`
+	CachedInputCostPerMillion   float64           `json:"cachedInputCostPerMillion,omitempty"`
+	OutputCostPerMillion        float64           `json:"outputCostPerMillion,omitempty"`
 	ModelCompatibility
 }
 
@@ -0,0 +1,32 @@
+package shared
+
+import "github.com/shopspring/decimal"
+
+var tokensPerMillion = decimal.NewFromInt(1000000)
+
+func (b BaseModelShared) HasPricing() bool {
+	return b.InputCostPerMillion > 0 || b.OutputCostPerMillion > 0
+}
+
+// GetCost returns the list price in USD of a request with the given usage.
+// cachedTokens are included in inputTokens, as reported by providers, and are billed at the cached rate if the model has one.
+func (b BaseModelShared) GetCost(inputTokens, cachedTokens, outputTokens int) decimal.Decimal {
+	if !b.HasPricing() {
+		return decimal.Zero
+	}
+
+	if cachedTokens > inputTokens {
+		cachedTokens = inputTokens
+	}
+
+	cachedRate := b.CachedInputCostPerMillion
+	if cachedRate == 0 {
+		cachedRate = b.InputCostPerMillion
+	}
+
+	uncached := decimal.NewFromInt(int64(inputTokens - cachedTokens)).Mul(decimal.NewFromFloat(b.InputCostPerMillion))
+	cached := decimal.NewFromInt(int64(cachedTokens)).Mul(decimal.NewFromFloat(cachedRate))
+	output := decimal.NewFromInt(int64(outputTokens)).Mul(decimal.NewFromFloat(b.OutputCostPerMillion))
+
+	return uncached.Add(cached).Add(output).Div(tokensPerMillion)
+}
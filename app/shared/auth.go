@@ -30,6 +30,8 @@ const (
 	ApiErrorTypeCloudSubscriptionPaused  ApiErrorType = "cloud_subscription_paused"
 	ApiErrorTypeCloudSubscriptionOverdue ApiErrorType = "cloud_subscription_overdue"
 
+	ApiErrorTypeBudgetExceeded ApiErrorType = "budget_exceeded"
+
 	ApiErrorTypeOther ApiErrorType = "other"
 )
 
@@ -46,6 +48,10 @@ type BillingError struct {
 	IsTrial              bool `json:"isTrial"`
 }
 
+type BudgetExceededError struct {
+	Status *BudgetStatus `json:"status"`
+}
+
 type ApiError struct {
 	Type   ApiErrorType `json:"type"`
 	Status int          `json:"status"`
@@ -59,6 +65,9 @@ type ApiError struct {
 
 	// only used for billing errors
 	BillingError *BillingError `json:"billingError,omitempty"`
+
+	// only used for budget exceeded errors
+	BudgetExceededError *BudgetExceededError `json:"budgetExceededError,omitempty"`
 }
 
 func (e *ApiError) Error() string {
@@ -31,9 +31,42 @@ type ContextUpdateResult struct {
 	NumImages       int
 	NumTrees        int
 	NumMaps         int
+	NumSymbols      int
+	NumGitDiffs     int
+	NumCommands     int
+	NumDocuments    int
 	MaxTokens       int
 }
 
+// separates a file path from a symbol pattern, like 'server/api.go#Server.Start'
+const SymbolSeparator = "#"
+
+// ParseSymbolResource splits a symbol resource like 'server/api.go#Server.Start' into its file path and pattern
+func ParseSymbolResource(resource string) (string, string, bool) {
+	i := strings.LastIndex(resource, SymbolSeparator)
+	if i <= 0 || i == len(resource)-1 {
+		return "", "", false
+	}
+	return resource[:i], resource[i+1:], true
+}
+
+// SplitSymbolPatterns splits a comma-separated symbol pattern like 'Server.Start,Handle*'
+func SplitSymbolPatterns(pattern string) []string {
+	var patterns []string
+	for _, p := range strings.Split(pattern, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// SymbolPattern is the pattern a symbol context selects from its file
+func (c *Context) SymbolPattern() string {
+	return strings.TrimPrefix(c.Name, c.FilePath+SymbolSeparator)
+}
+
 func (c *Context) TypeAndIcon() (string, string) {
 	var icon string
 	var t string
@@ -59,6 +92,18 @@ func (c *Context) TypeAndIcon() (string, string) {
 	case ContextMapType:
 		icon = "🗺️ "
 		t = "map"
+	case ContextSymbolType:
+		icon = "🔣"
+		t = "symbol"
+	case ContextGitDiffType:
+		icon = "🔀"
+		t = "git diff"
+	case ContextCommandType:
+		icon = "💻"
+		t = "cmd"
+	case ContextDocumentType:
+		icon = "📑"
+		t = "doc"
 	}
 
 	return t, icon
@@ -117,6 +162,10 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 	var numTrees int
 	var numUrls int
 	var numMaps int
+	var numSymbols int
+	var numGitDiffs int
+	var numCommands int
+	var numDocuments int
 
 	for _, context := range contexts {
 		switch context.ContextType {
@@ -132,6 +181,14 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 			hasPiped = true
 		case ContextMapType:
 			numMaps++
+		case ContextSymbolType:
+			numSymbols++
+		case ContextGitDiffType:
+			numGitDiffs++
+		case ContextCommandType:
+			numCommands++
+		case ContextDocumentType:
+			numDocuments++
 		}
 	}
 
@@ -171,6 +228,34 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 		}
 		added = append(added, fmt.Sprintf("%d %s", numMaps, label))
 	}
+	if numSymbols > 0 {
+		label := "symbol selection"
+		if numSymbols > 1 {
+			label = "symbol selections"
+		}
+		added = append(added, fmt.Sprintf("%d %s", numSymbols, label))
+	}
+	if numGitDiffs > 0 {
+		label := "git diff"
+		if numGitDiffs > 1 {
+			label = "git diffs"
+		}
+		added = append(added, fmt.Sprintf("%d %s", numGitDiffs, label))
+	}
+	if numCommands > 0 {
+		label := "command output"
+		if numCommands > 1 {
+			label = "command outputs"
+		}
+		added = append(added, fmt.Sprintf("%d %s", numCommands, label))
+	}
+	if numDocuments > 0 {
+		label := "document"
+		if numDocuments > 1 {
+			label = "documents"
+		}
+		added = append(added, fmt.Sprintf("%d %s", numDocuments, label))
+	}
 
 	msg := "Loaded "
 
@@ -235,12 +320,16 @@ func SummaryForRemoveContext(contexts []*Context, previousTotalTokens int) strin
 }
 
 type SummaryForUpdateContextParams struct {
-	NumFiles    int
-	NumTrees    int
-	NumUrls     int
-	NumMaps     int
-	TokensDiff  int
-	TotalTokens int
+	NumFiles     int
+	NumTrees     int
+	NumUrls      int
+	NumMaps      int
+	NumSymbols   int
+	NumGitDiffs  int
+	NumCommands  int
+	NumDocuments int
+	TokensDiff   int
+	TotalTokens  int
 }
 
 func SummaryForUpdateContext(params SummaryForUpdateContextParams) string {
@@ -248,6 +337,10 @@ func SummaryForUpdateContext(params SummaryForUpdateContextParams) string {
 	numTrees := params.NumTrees
 	numUrls := params.NumUrls
 	numMaps := params.NumMaps
+	numSymbols := params.NumSymbols
+	numGitDiffs := params.NumGitDiffs
+	numCommands := params.NumCommands
+	numDocuments := params.NumDocuments
 	tokensDiff := params.TokensDiff
 	totalTokens := params.TotalTokens
 
@@ -281,6 +374,34 @@ func SummaryForUpdateContext(params SummaryForUpdateContextParams) string {
 		}
 		toAdd = append(toAdd, fmt.Sprintf("%d map%s", numMaps, postfix))
 	}
+	if numSymbols > 0 {
+		postfix := "s"
+		if numSymbols == 1 {
+			postfix = ""
+		}
+		toAdd = append(toAdd, fmt.Sprintf("%d symbol selection%s", numSymbols, postfix))
+	}
+	if numGitDiffs > 0 {
+		postfix := "s"
+		if numGitDiffs == 1 {
+			postfix = ""
+		}
+		toAdd = append(toAdd, fmt.Sprintf("%d git diff%s", numGitDiffs, postfix))
+	}
+	if numCommands > 0 {
+		postfix := "s"
+		if numCommands == 1 {
+			postfix = ""
+		}
+		toAdd = append(toAdd, fmt.Sprintf("%d command output%s", numCommands, postfix))
+	}
+	if numDocuments > 0 {
+		postfix := "s"
+		if numDocuments == 1 {
+			postfix = ""
+		}
+		toAdd = append(toAdd, fmt.Sprintf("%d document%s", numDocuments, postfix))
+	}
 
 	if len(toAdd) <= 2 {
 		msg += " " + strings.Join(toAdd, " and ")
@@ -92,6 +92,10 @@ const (
 	ContextPipedDataType     ContextType = "piped data"
 	ContextImageType         ContextType = "image"
 	ContextMapType           ContextType = "map"
+	ContextSymbolType        ContextType = "symbol"
+	ContextGitDiffType       ContextType = "git diff"
+	ContextCommandType       ContextType = "command"
+	ContextDocumentType      ContextType = "document"
 )
 
 type FileMapBodies map[string]string
@@ -102,6 +106,8 @@ type Context struct {
 	ContextType     ContextType           `json:"contextType"`
 	Name            string                `json:"name"`
 	Url             string                `json:"url"`
+	UrlETag         string                `json:"urlETag,omitempty"`
+	UrlLastModified string                `json:"urlLastModified,omitempty"`
 	FilePath        string                `json:"file_path"`
 	Sha             string                `json:"sha"`
 	NumTokens       int                   `json:"numTokens"`
@@ -301,10 +307,274 @@ type CurrentPlanState struct {
 }
 
 type OrgRole struct {
-	Id          string `json:"id"`
-	IsDefault   bool   `json:"isDefault"`
-	Label       string `json:"label"`
-	Description string `json:"description"`
+	Id          string       `json:"id"`
+	Name        string       `json:"name"`
+	IsDefault   bool         `json:"isDefault"`
+	Label       string       `json:"label"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions,omitempty"`
+}
+
+// names of the built-in org roles, which custom roles can't reuse
+var DefaultOrgRoleNames = map[string]bool{
+	"owner":         true,
+	"admin":         true,
+	"billing_admin": true,
+	"member":        true,
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending    WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusInProgress WebhookDeliveryStatus = "in_progress"
+	WebhookDeliveryStatusDelivered  WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryStatusFailed     WebhookDeliveryStatus = "failed"
+)
+
+type WebhookDelivery struct {
+	Id             string                `json:"id"`
+	EndpointId     string                `json:"endpointId"`
+	Url            string                `json:"url"`
+	EventId        string                `json:"eventId"`
+	EventType      string                `json:"eventType"`
+	PlanId         *string               `json:"planId,omitempty"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	NumAttempts    int                   `json:"numAttempts"`
+	LastStatusCode *int                  `json:"lastStatusCode,omitempty"`
+	LastError      *string               `json:"lastError,omitempty"`
+	ReplayOfId     *string               `json:"replayOfId,omitempty"`
+	NextAttemptAt  time.Time             `json:"nextAttemptAt"`
+	DeliveredAt    *time.Time            `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+}
+
+type AuditAction string
+
+const (
+	AuditActionPlanDeleted              AuditAction = "plan.deleted"
+	AuditActionPlansDeletedAll          AuditAction = "plan.deleted_all"
+	AuditActionPlanRenamed              AuditAction = "plan.renamed"
+	AuditActionPlanArchived             AuditAction = "plan.archived"
+	AuditActionPlanUnarchived           AuditAction = "plan.unarchived"
+	AuditActionPlanRewound              AuditAction = "plan.rewound"
+	AuditActionPlanShared               AuditAction = "plan.shared"
+	AuditActionPlanUnshared             AuditAction = "plan.unshared"
+	AuditActionBranchDeleted            AuditAction = "branch.deleted"
+	AuditActionPlanSettingsUpdated      AuditAction = "settings.updated"
+	AuditActionDefaultSettingsUpdated   AuditAction = "settings.default_updated"
+	AuditActionPlanConfigUpdated        AuditAction = "plan_config.updated"
+	AuditActionDefaultPlanConfigUpdated AuditAction = "plan_config.default_updated"
+	AuditActionOrgUserConfigUpdated     AuditAction = "org_user_config.updated"
+	AuditActionCustomModelsUpdated      AuditAction = "custom_models.updated"
+	AuditActionOrgCreated               AuditAction = "org.created"
+	AuditActionUserInvited              AuditAction = "invite.created"
+	AuditActionInviteDeleted            AuditAction = "invite.deleted"
+	AuditActionUserRemoved              AuditAction = "user.removed"
+	AuditActionUserRoleChanged          AuditAction = "user.role_changed"
+	AuditActionOrgRoleCreated           AuditAction = "org_role.created"
+	AuditActionOrgRoleUpdated           AuditAction = "org_role.updated"
+	AuditActionOrgRoleDeleted           AuditAction = "org_role.deleted"
+	AuditActionApiTokenCreated          AuditAction = "api_token.created"
+	AuditActionApiTokenRevoked          AuditAction = "api_token.revoked"
+	AuditActionBudgetSet                AuditAction = "budget.set"
+	AuditActionBudgetDeleted            AuditAction = "budget.deleted"
+	AuditActionExecPolicyUpdated        AuditAction = "exec_policy.updated"
+	AuditActionExecPolicyDeleted        AuditAction = "exec_policy.deleted"
+)
+
+// AuditLogEntry records who took a security-relevant or destructive action in an org.
+// UserEmail and UserName are stored with the entry so it still reads correctly after the user leaves the org.
+type AuditLogEntry struct {
+	Id         string            `json:"id"`
+	UserId     string            `json:"userId"`
+	UserEmail  string            `json:"userEmail"`
+	UserName   string            `json:"userName"`
+	ApiTokenId *string           `json:"apiTokenId,omitempty"`
+	Action     AuditAction       `json:"action"`
+	PlanId     *string           `json:"planId,omitempty"`
+	Branch     *string           `json:"branch,omitempty"`
+	TargetId   *string           `json:"targetId,omitempty"`
+	Data       map[string]string `json:"data,omitempty"`
+	IpAddress  string            `json:"ipAddress,omitempty"`
+	CreatedAt  time.Time         `json:"createdAt"`
+}
+
+type BudgetScope string
+
+const (
+	BudgetScopeOrg  BudgetScope = "org"
+	BudgetScopeUser BudgetScope = "user"
+	BudgetScopePlan BudgetScope = "plan"
+)
+
+type BudgetPeriod string
+
+const (
+	BudgetPeriodDaily   BudgetPeriod = "daily"
+	BudgetPeriodMonthly BudgetPeriod = "monthly"
+)
+
+type BudgetAction string
+
+const (
+	// requests that would go over the budget are rejected
+	BudgetActionBlock BudgetAction = "block"
+	// requests that would go over the budget still run, but a warning is logged and a budget.exceeded webhook is sent
+	BudgetActionWarn BudgetAction = "warn"
+)
+
+// Budget caps model spend in an org over a daily or monthly period (UTC).
+// A user or plan budget with no UserId or PlanId applies to each user or plan in the org separately.
+// Cost is computed from the list prices in each model's config, so models without prices only count toward MaxTokens.
+type Budget struct {
+	Id        string           `json:"id"`
+	Scope     BudgetScope      `json:"scope"`
+	UserId    *string          `json:"userId,omitempty"`
+	UserEmail *string          `json:"userEmail,omitempty"`
+	PlanId    *string          `json:"planId,omitempty"`
+	PlanName  *string          `json:"planName,omitempty"`
+	Period    BudgetPeriod     `json:"period"`
+	MaxTokens *int64           `json:"maxTokens,omitempty"`
+	MaxCost   *decimal.Decimal `json:"maxCost,omitempty"`
+	Action    BudgetAction     `json:"action"`
+	CreatedAt time.Time        `json:"createdAt"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
+
+// BudgetStatus is a budget's spend so far in its current period, for the user and plan it was requested for
+type BudgetStatus struct {
+	Budget          *Budget          `json:"budget"`
+	PeriodStart     time.Time        `json:"periodStart"`
+	PeriodEnd       time.Time        `json:"periodEnd"`
+	UsedTokens      int64            `json:"usedTokens"`
+	UsedCost        decimal.Decimal  `json:"usedCost"`
+	RemainingTokens *int64           `json:"remainingTokens,omitempty"`
+	RemainingCost   *decimal.Decimal `json:"remainingCost,omitempty"`
+	Exceeded        bool             `json:"exceeded"`
+}
+
+// UsageGroupBy is how a usage report groups model requests
+type UsageGroupBy string
+
+const (
+	UsageGroupByPlan  UsageGroupBy = "plan"
+	UsageGroupByUser  UsageGroupBy = "user"
+	UsageGroupByRole  UsageGroupBy = "role"
+	UsageGroupByModel UsageGroupBy = "model"
+	UsageGroupByDay   UsageGroupBy = "day"
+)
+
+type UsageReportRow struct {
+	// the plan or user id, model role, model id, or UTC day (2025-07-01)
+	Key string `json:"key"`
+	// the plan's name or user's email, when grouping by plan or user
+	Label string `json:"label,omitempty"`
+
+	Requests     int64           `json:"requests"`
+	InputTokens  int64           `json:"inputTokens"`
+	CachedTokens int64           `json:"cachedTokens"`
+	OutputTokens int64           `json:"outputTokens"`
+	Cost         decimal.Decimal `json:"cost"`
+	Errors       int64           `json:"errors"`
+
+	AvgLatencyMs    int64 `json:"avgLatencyMs"`
+	AvgFirstTokenMs int64 `json:"avgFirstTokenMs"`
+}
+
+type UsageReport struct {
+	By    UsageGroupBy      `json:"by"`
+	Since time.Time         `json:"since"`
+	Until time.Time         `json:"until"`
+	Rows  []*UsageReportRow `json:"rows"`
+	Total *UsageReportRow   `json:"total"`
+
+	// false when the report only includes the requesting user's own requests
+	AllUsers bool `json:"allUsers"`
+}
+
+// ApiTokenPrefix starts every API token, which lets the server tell them apart from session tokens
+const ApiTokenPrefix = "pdx_"
+
+type ApiToken struct {
+	Id          string       `json:"id"`
+	CreatorId   string       `json:"creatorId"`
+	ProjectId   *string      `json:"projectId,omitempty"`
+	Name        string       `json:"name"`
+	Prefix      string       `json:"prefix"`
+	Permissions []Permission `json:"permissions"`
+	ExpiresAt   *time.Time   `json:"expiresAt,omitempty"`
+	LastUsedAt  *time.Time   `json:"lastUsedAt,omitempty"`
+	RevokedAt   *time.Time   `json:"revokedAt,omitempty"`
+	CreatedAt   time.Time    `json:"createdAt"`
+}
+
+type PlanShareRole string
+
+const (
+	// viewers can read a plan's conversation, context, changes and logs
+	PlanShareRoleViewer PlanShareRole = "viewer"
+	// collaborators can also tell, build, apply, and update context, settings and branches
+	PlanShareRoleCollaborator PlanShareRole = "collaborator"
+	// owners can also rename, archive, delete and share the plan
+	PlanShareRoleOwner PlanShareRole = "owner"
+)
+
+var planShareRoleLevels = map[PlanShareRole]int{
+	PlanShareRoleViewer:       1,
+	PlanShareRoleCollaborator: 2,
+	PlanShareRoleOwner:        3,
+}
+
+func (role PlanShareRole) IsValid() bool {
+	_, ok := planShareRoleLevels[role]
+	return ok
+}
+
+// Includes returns true if the role has at least the access of the other role
+func (role PlanShareRole) Includes(other PlanShareRole) bool {
+	level, ok := planShareRoleLevels[role]
+	if !ok {
+		return false
+	}
+	return level >= planShareRoleLevels[other]
+}
+
+type PlanShare struct {
+	Id         string        `json:"id"`
+	PlanId     string        `json:"planId"`
+	UserId     string        `json:"userId"`
+	UserEmail  string        `json:"userEmail"`
+	UserName   string        `json:"userName"`
+	Role       PlanShareRole `json:"role"`
+	SharedById string        `json:"sharedById"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
+}
+
+// ContextSetEntry is a loaded context as saved in a context set. It describes how to load the context again rather than copying it, so files, urls, maps and trees are re-read when the set is restored. Only notes and piped data, which can't be re-read, keep their bodies.
+type ContextSetEntry struct {
+	ContextType     ContextType           `json:"contextType"`
+	Name            string                `json:"name"`
+	FilePath        string                `json:"filePath,omitempty"`
+	Url             string                `json:"url,omitempty"`
+	Body            string                `json:"body,omitempty"`
+	ForceSkipIgnore bool                  `json:"forceSkipIgnore,omitempty"`
+	ImageDetail     openai.ImageURLDetail `json:"imageDetail,omitempty"`
+	// the user who loaded the context the entry was saved from -- commands and git diffs only run without confirmation for them
+	OwnerId string `json:"ownerId,omitempty"`
+}
+
+type ContextSet struct {
+	Id         string             `json:"id"`
+	ProjectId  string             `json:"projectId"`
+	OwnerId    string             `json:"ownerId"`
+	OwnerEmail string             `json:"ownerEmail"`
+	Name       string             `json:"name"`
+	IsShared   bool               `json:"isShared"`
+	Entries    []*ContextSetEntry `json:"entries"`
+	CreatedAt  time.Time          `json:"createdAt"`
+	UpdatedAt  time.Time          `json:"updatedAt"`
 }
 
 type CloudBillingFields struct {
@@ -0,0 +1,91 @@
+package shared
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type ExecPolicyVerdict string
+
+const (
+	ExecPolicyAllow   ExecPolicyVerdict = "allow"
+	ExecPolicyConfirm ExecPolicyVerdict = "confirm"
+	ExecPolicyDeny    ExecPolicyVerdict = "deny"
+)
+
+// Severity orders verdicts so the strictest one wins when several rules match
+func (v ExecPolicyVerdict) Severity() int {
+	switch v {
+	case ExecPolicyAllow:
+		return 0
+	case ExecPolicyConfirm:
+		return 1
+	case ExecPolicyDeny:
+		return 2
+	}
+	return -1
+}
+
+func (v ExecPolicyVerdict) IsValid() bool {
+	return v.Severity() >= 0
+}
+
+// ExecPolicy holds allow, confirm, and deny rules for the commands in model-written apply scripts.
+// A rule is a command prefix like 'go test' or 'npm run *', where options like '-rf' can appear anywhere in the command, and 'curl | sh' matches a pipeline.
+// A policy can live in the project's .plandex-policy.json or be set for the whole org.
+type ExecPolicy struct {
+	Allow   []string `json:"allow,omitempty"`
+	Confirm []string `json:"confirm,omitempty"`
+	Deny    []string `json:"deny,omitempty"`
+	// verdict for commands that no rule matches -- confirm if unset
+	Default ExecPolicyVerdict `json:"default,omitempty"`
+}
+
+func (p *ExecPolicy) IsEmpty() bool {
+	return p == nil || (len(p.Allow) == 0 && len(p.Confirm) == 0 && len(p.Deny) == 0 && p.Default == "")
+}
+
+func (p *ExecPolicy) Validate() error {
+	if p.Default != "" && !p.Default.IsValid() {
+		return fmt.Errorf("default must be one of: allow, confirm, deny")
+	}
+
+	for verdict, rules := range map[ExecPolicyVerdict][]string{
+		ExecPolicyAllow:   p.Allow,
+		ExecPolicyConfirm: p.Confirm,
+		ExecPolicyDeny:    p.Deny,
+	} {
+		for _, rule := range rules {
+			if strings.TrimSpace(rule) == "" {
+				return fmt.Errorf("%s rules can't be empty", verdict)
+			}
+			for _, segment := range strings.Split(rule, "|") {
+				if strings.TrimSpace(segment) == "" {
+					return fmt.Errorf("%s rule '%s' has an empty pipeline segment", verdict, rule)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *ExecPolicy) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case nil:
+		*p = ExecPolicy{}
+		return nil
+	case []byte:
+		return json.Unmarshal(s, p)
+	case string:
+		return json.Unmarshal([]byte(s), p)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (p ExecPolicy) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -45,6 +46,21 @@ var AutoModeOptions = [][3]string{
 	{string(AutoModeCustom), "Custom", AutoModeDescriptions[AutoModeCustom]},
 }
 
+type ExecSandboxType string
+
+const (
+	ExecSandboxNone      ExecSandboxType = "none"
+	ExecSandboxBwrap     ExecSandboxType = "bwrap"
+	ExecSandboxContainer ExecSandboxType = "container"
+)
+
+const DefaultExecSandboxImage = "debian:bookworm-slim"
+
+var ExecSandboxChoices = []string{string(ExecSandboxNone), string(ExecSandboxBwrap), string(ExecSandboxContainer)}
+
+// free-form string settings have no choices to pick from
+var noChoices = []string{}
+
 var AutoModeLabels = map[AutoModeType]string{}
 
 // populated in init()
@@ -65,6 +81,10 @@ type PlanConfig struct {
 	AutoUpdateContext bool `json:"autoUpdateContext"`
 	AutoLoadContext   bool `json:"autoContext"`
 	SmartContext      bool `json:"smartContext"`
+	// also auto-load files that import or are imported by auto-loaded files, up to this many hops away
+	AutoContextDepHops int `json:"autoContextDepHops"`
+	// in a monorepo, limits the auto-context map and 'plandex ls' to these directories (relative to the project root)
+	Scope []string `json:"scope,omitempty"`
 
 	// AutoApproveContext bool `json:"autoApproveContext"`
 	// QuietContext       bool `json:"quietContext"`
@@ -83,6 +103,13 @@ type PlanConfig struct {
 	AutoDebug      bool `json:"autoDebug"`
 	AutoDebugTries int  `json:"autoDebugTries"`
 
+	// where the apply script runs -- the empty value is the same as ExecSandboxNone
+	ExecSandbox        ExecSandboxType `json:"execSandbox,omitempty"`
+	ExecSandboxNetwork bool            `json:"execSandboxNetwork,omitempty"`
+	ExecSandboxImage   string          `json:"execSandboxImage,omitempty"`
+	// if set, only these env vars (plus a base set like PATH) are passed to executed commands -- sandboxed commands get only the base set otherwise
+	ExecEnvAllowlist []string `json:"execEnvAllowlist,omitempty"`
+
 	AutoRevertOnRewind bool `json:"autoRevertOnRewind"`
 
 	SkipChangesMenu bool `json:"skipChangesMenu"`
@@ -202,6 +229,32 @@ func (p *PlanConfig) SetAutoMode(mode AutoModeType) {
 	}
 }
 
+func (p *PlanConfig) GetExecSandbox() ExecSandboxType {
+	if p.ExecSandbox == "" {
+		return ExecSandboxNone
+	}
+	return p.ExecSandbox
+}
+
+func (p *PlanConfig) GetExecSandboxImage() string {
+	if p.ExecSandboxImage == "" {
+		return DefaultExecSandboxImage
+	}
+	return p.ExecSandboxImage
+}
+
+func (s ExecSandboxType) IsValid() bool {
+	return s == "" || slices.Contains(ExecSandboxChoices, string(s))
+}
+
+// SameExecSandbox checks whether two configs run commands with the same sandbox, network access, image, and env
+func (p *PlanConfig) SameExecSandbox(other *PlanConfig) bool {
+	return p.GetExecSandbox() == other.GetExecSandbox() &&
+		p.ExecSandboxNetwork == other.ExecSandboxNetwork &&
+		p.GetExecSandboxImage() == other.GetExecSandboxImage() &&
+		slices.Equal(p.ExecEnvAllowlist, other.ExecEnvAllowlist)
+}
+
 type ConfigSetting struct {
 	Name            string
 	Desc            string
@@ -321,6 +374,19 @@ var ConfigSettingsByKey = map[string]ConfigSetting{
 			return fmt.Sprintf("%t", p.AutoLoadContext)
 		},
 	},
+	"autocontextdeps": {
+		Name: "auto-context-deps",
+		Desc: "Also auto-load files that import or are imported by auto-loaded files, up to this many hops (0 to disable)",
+		Visible: func(p *PlanConfig) bool {
+			return p.AutoLoadContext
+		},
+		IntSetter: func(p *PlanConfig, value int) {
+			p.AutoContextDepHops = max(value, 0)
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%d", p.AutoContextDepHops)
+		},
+	},
 	"smartcontext": {
 		Name: "smart-context",
 		Desc: "Load only necessary context for each task in the plan",
@@ -414,6 +480,73 @@ var ConfigSettingsByKey = map[string]ConfigSetting{
 			return fmt.Sprintf("%t", p.AutoExec)
 		},
 	},
+	"execsandbox": {
+		Name: "exec-sandbox",
+		Desc: "Where executed commands run: none, bwrap (Linux namespace sandbox with a read-only filesystem and a writable project dir), or container (docker or podman)",
+		Visible: func(p *PlanConfig) bool {
+			return p.CanExec
+		},
+		StringSetter: func(p *PlanConfig, value string) {
+			value = strings.ToLower(strings.TrimSpace(value))
+			if value == "" {
+				value = string(ExecSandboxNone)
+			}
+			p.ExecSandbox = ExecSandboxType(value)
+		},
+		Getter: func(p *PlanConfig) string {
+			return string(p.GetExecSandbox())
+		},
+		Choices: &ExecSandboxChoices,
+	},
+	"execsandboxnetwork": {
+		Name: "exec-sandbox-network",
+		Desc: "Allow network access for sandboxed commands",
+		Visible: func(p *PlanConfig) bool {
+			return p.CanExec && p.GetExecSandbox() != ExecSandboxNone
+		},
+		BoolSetter: func(p *PlanConfig, enabled bool) {
+			p.ExecSandboxNetwork = enabled
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%t", p.ExecSandboxNetwork)
+		},
+	},
+	"execsandboximage": {
+		Name: "exec-sandbox-image",
+		Desc: "Container image for the container sandbox",
+		Visible: func(p *PlanConfig) bool {
+			return p.CanExec && p.GetExecSandbox() == ExecSandboxContainer
+		},
+		StringSetter: func(p *PlanConfig, value string) {
+			p.ExecSandboxImage = strings.TrimSpace(value)
+		},
+		Getter: func(p *PlanConfig) string {
+			return p.GetExecSandboxImage()
+		},
+		Choices: &noChoices,
+	},
+	"execenv": {
+		Name: "exec-env",
+		Desc: "Env vars passed to executed commands, comma-separated with * wildcards ('none' to clear). When empty, sandboxed commands only get a base set like PATH and unsandboxed commands get everything",
+		Visible: func(p *PlanConfig) bool {
+			return p.CanExec
+		},
+		StringSetter: func(p *PlanConfig, value string) {
+			p.ExecEnvAllowlist = nil
+			if strings.EqualFold(strings.TrimSpace(value), "none") {
+				return
+			}
+			for _, name := range strings.Split(value, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					p.ExecEnvAllowlist = append(p.ExecEnvAllowlist, name)
+				}
+			}
+		},
+		Getter: func(p *PlanConfig) string {
+			return strings.Join(p.ExecEnvAllowlist, ",")
+		},
+		Choices: &noChoices,
+	},
 	"autodebug": {
 		Name: "auto-debug",
 		Desc: "Automatically debug failed commands",
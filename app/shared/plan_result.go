@@ -22,6 +22,17 @@ func (res *PlanFileResult) NumPendingReplacements() int {
 	return numPending
 }
 
+// PendingReplacements leaves out replacements that failed or were rejected in review
+func (res *PlanFileResult) PendingReplacements() []*Replacement {
+	var pending []*Replacement
+	for _, rep := range res.Replacements {
+		if rep.IsPending() {
+			pending = append(pending, rep)
+		}
+	}
+	return pending
+}
+
 func (res *PlanFileResult) IsPending() bool {
 	return res.AppliedAt == nil && res.RejectedAt == nil && (res.Content != "" || res.NumPendingReplacements() > 0 || res.RemovedFile)
 }
@@ -87,7 +98,8 @@ func (p PlanFileResultsByPath) ConflictedPaths(filesByPath map[string]string) ma
 		for _, res := range planRes {
 
 			// log.Println("res:", res.Id)
-			if len(res.Replacements) == 0 {
+			replacements := res.PendingReplacements()
+			if len(replacements) == 0 {
 				continue
 			}
 
@@ -97,7 +109,7 @@ func (p PlanFileResultsByPath) ConflictedPaths(filesByPath map[string]string) ma
 			}
 
 			var succeeded bool
-			updated, succeeded = ApplyReplacements(maybeWithLineNums, res.Replacements, false)
+			updated, succeeded = ApplyReplacements(maybeWithLineNums, replacements, false)
 
 			updated = RemoveLineNums(LineNumberedTextType(updated))
 
@@ -163,7 +163,7 @@ func (state *CurrentPlanState) pendingChangesSummary(forApply bool, commitSummar
 					pendingNewFilesSet[result.Path] = true
 				} else {
 					pendingReplacementPathsSet[result.Path] = true
-					pendingReplacementsByPath[result.Path] = append(pendingReplacementsByPath[result.Path], result.Replacements...)
+					pendingReplacementsByPath[result.Path] = append(pendingReplacementsByPath[result.Path], result.PendingReplacements()...)
 				}
 			}
 		}
@@ -23,8 +23,70 @@ const (
 	PermissionDeleteAnyPlan         Permission = "delete_any_plan"
 	PermissionUpdateAnyPlan         Permission = "update_any_plan"
 	PermissionArchiveAnyPlan        Permission = "archive_any_plan"
+	PermissionManageWebhooks        Permission = "manage_webhooks"
+	PermissionManageApiTokens       Permission = "manage_api_tokens"
+	PermissionManageOrgRoles        Permission = "manage_org_roles"
+	PermissionManageModelPacks      Permission = "manage_model_packs"
+	PermissionManageCustomProviders Permission = "manage_custom_providers"
+	PermissionViewBilling           Permission = "view_billing"
+	PermissionExecCommands          Permission = "exec_commands"
+	PermissionViewAuditLog          Permission = "view_audit_log"
+	PermissionManageBudgets         Permission = "manage_budgets"
+	PermissionViewOrgUsage          Permission = "view_org_usage"
+	PermissionManageExecPolicy      Permission = "manage_exec_policy"
 )
 
+// AllPermissions lists every permission that can be included in a custom org role
+var AllPermissions = []Permission{
+	PermissionDeleteOrg,
+	PermissionManageEmailDomainAuth,
+	PermissionManageBilling,
+	PermissionInviteUser,
+	PermissionRemoveUser,
+	PermissionSetUserRole,
+	PermissionListOrgRoles,
+	PermissionCreateProject,
+	PermissionRenameAnyProject,
+	PermissionDeleteAnyProject,
+	PermissionCreatePlan,
+	PermissionManageAnyPlanShares,
+	PermissionRenameAnyPlan,
+	PermissionDeleteAnyPlan,
+	PermissionUpdateAnyPlan,
+	PermissionArchiveAnyPlan,
+	PermissionManageWebhooks,
+	PermissionManageApiTokens,
+	PermissionManageOrgRoles,
+	PermissionManageModelPacks,
+	PermissionManageCustomProviders,
+	PermissionViewBilling,
+	PermissionExecCommands,
+	PermissionViewAuditLog,
+	PermissionManageBudgets,
+	PermissionViewOrgUsage,
+	PermissionManageExecPolicy,
+}
+
+// these permissions apply to users with a specific org role, like inviting members. In a custom role, they apply to member-level users, which includes users with a custom role.
+var orgRoleScopedPermissions = map[Permission]bool{
+	PermissionInviteUser:  true,
+	PermissionRemoveUser:  true,
+	PermissionSetUserRole: true,
+}
+
+func (p Permission) IsValid() bool {
+	for _, permission := range AllPermissions {
+		if permission == p {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Permission) IsOrgRoleScoped() bool {
+	return orgRoleScopedPermissions[p]
+}
+
 type Permissions map[string]bool
 
 func (perms Permissions) HasPermission(permission Permission) bool {
@@ -43,6 +105,9 @@ func (perms Permissions) HasPermissionForResource(permission Permission, resourc
 	for p := range perms {
 		split := strings.Split(p, "|")
 		perm := Permission(split[0])
+		if len(split) < 2 {
+			continue
+		}
 		resId := split[1]
 
 		if perm == permission && resId == resourceId {
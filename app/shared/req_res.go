@@ -140,6 +140,15 @@ type TellPlanRequest struct {
 	IsImplementationOfChat bool            `json:"isImplementationOfChat"`
 	IsGitRepo              bool            `json:"isGitRepo"`
 	SessionId              string          `json:"sessionId"`
+
+	// files ranked by the client's local search index for the prompt, most relevant first -- used to preselect the project map for auto-context
+	SearchCandidates []SearchResult `json:"searchCandidates,omitempty"`
+}
+
+type SearchResult struct {
+	Path    string   `json:"path"`
+	Score   float64  `json:"score"`
+	Symbols []string `json:"symbols,omitempty"` // matching definition names
 }
 
 type BuildPlanRequest struct {
@@ -185,11 +194,14 @@ func (f FileMapInputs) TotalSize() int64 {
 }
 
 type LoadContextParams struct {
-	ContextType     ContextType           `json:"contextType"`
-	Name            string                `json:"name"`
-	Url             string                `json:"url"`
-	FilePath        string                `json:"file_path"`
-	Body            string                `json:"body"`
+	ContextType ContextType `json:"contextType"`
+	Name        string      `json:"name"`
+	Url         string      `json:"url"`
+	FilePath    string      `json:"file_path"`
+	Body        string      `json:"body"`
+	// validators from the url's response, so updates can skip pages that haven't changed
+	UrlETag         string                `json:"urlETag,omitempty"`
+	UrlLastModified string                `json:"urlLastModified,omitempty"`
 	ForceSkipIgnore bool                  `json:"forceSkipIgnore"`
 	ImageDetail     openai.ImageURLDetail `json:"imageDetail"`
 	AutoLoaded      bool                  `json:"autoLoaded"`
@@ -221,6 +233,8 @@ type LoadContextResponse struct {
 
 type UpdateContextParams struct {
 	Body            string            `json:"body"`
+	UrlETag         string            `json:"urlETag,omitempty"`
+	UrlLastModified string            `json:"urlLastModified,omitempty"`
 	InputShas       map[string]string `json:"inputShas"`
 	InputTokens     map[string]int    `json:"inputTokens"`
 	InputSizes      map[string]int64  `json:"inputSizes"`
@@ -236,6 +250,32 @@ type GetFileMapResponse struct {
 	MapBodies FileMapBodies `json:"mapBodies"`
 }
 
+// FileSymbolsInput is a file and the symbol pattern to select from it, like 'Server.Start' or 'Handle*'
+type FileSymbolsInput struct {
+	Path    string `json:"path"`
+	Pattern string `json:"pattern"`
+	Content string `json:"content"`
+}
+
+type GetFileSymbolsRequest struct {
+	Inputs map[string]FileSymbolsInput `json:"inputs"` // keyed by context name ('path#pattern')
+}
+
+type GetFileSymbolsResponse struct {
+	Bodies    map[string]string   `json:"bodies"`
+	Unmatched map[string][]string `json:"unmatched,omitempty"`
+}
+
+type GetFileDepsRequest struct {
+	Inputs FileMapInputs `json:"inputs"`
+	// every project file imports can resolve to, not just those in this batch
+	ProjectPaths []string `json:"projectPaths"`
+}
+
+type GetFileDepsResponse struct {
+	Deps map[string][]string `json:"deps"` // project files each input imports
+}
+
 type LoadCachedFileMapRequest struct {
 	FilePaths []string `json:"filePaths"`
 }
@@ -275,6 +315,13 @@ type RejectFilesRequest struct {
 	Paths []string `json:"paths"`
 }
 
+// ReviewReplacementsRequest rejects or edits individual pending changes (replacements) within files
+type ReviewReplacementsRequest struct {
+	RejectedIds []string `json:"rejectedIds"`
+	// replacement id -> updated 'new' text
+	EditedById map[string]string `json:"editedById"`
+}
+
 type RewindPlanRequest struct {
 	Sha string `json:"sha"`
 }
@@ -342,6 +389,81 @@ type GetBuildStatusResponse struct {
 	IsBuildingByPath map[string]bool `json:"isBuildingByPath"`
 }
 
+type CreateApiTokenRequest struct {
+	Name string `json:"name"`
+
+	// optional -- limits the token to a single project
+	ProjectId string `json:"projectId"`
+
+	// must be a subset of the creator's own permissions
+	Permissions []Permission `json:"permissions"`
+
+	// optional -- the token never expires if this isn't set
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type CreateApiTokenResponse struct {
+	// the full token is only returned once, when it's created
+	Token    string    `json:"token"`
+	ApiToken *ApiToken `json:"apiToken"`
+}
+
+type SharePlanRequest struct {
+	// the user must be a member of the plan's org
+	Email string        `json:"email"`
+	Role  PlanShareRole `json:"role"`
+}
+
+type SaveContextSetRequest struct {
+	Name string `json:"name"`
+	// shared sets can be restored by any org member with access to the project
+	IsShared bool `json:"isShared"`
+}
+
+type CreateOrgRoleRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// must be a subset of the creator's own permissions
+	Permissions []Permission `json:"permissions"`
+}
+
+type UpdateOrgRoleRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// replaces the role's permissions -- must be a subset of the updater's own permissions
+	Permissions []Permission `json:"permissions"`
+}
+
+type SetOrgUserRoleRequest struct {
+	OrgRoleId string `json:"orgRoleId"`
+}
+
+// SetBudgetRequest creates a budget, or replaces the budget with the same scope, user or plan, and period
+type SetBudgetRequest struct {
+	Scope BudgetScope `json:"scope"`
+
+	// optional -- for a user budget, limits it to a single user. Otherwise it applies to each user.
+	UserEmail string `json:"userEmail"`
+
+	// optional -- for a plan budget, limits it to a single plan. Otherwise it applies to each plan.
+	PlanId string `json:"planId"`
+
+	Period BudgetPeriod `json:"period"`
+
+	// at least one of these must be set
+	MaxTokens *int64           `json:"maxTokens"`
+	MaxCost   *decimal.Decimal `json:"maxCost"`
+
+	// defaults to BudgetActionBlock
+	Action BudgetAction `json:"action"`
+}
+
+type SetOrgExecPolicyRequest struct {
+	Policy *ExecPolicy `json:"policy"`
+}
+
 // Cloud requests and responses
 type CreditsLogRequest struct {
 	TransactionType CreditsTransactionType `json:"transactionType"`
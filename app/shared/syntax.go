@@ -93,6 +93,23 @@ var lacksFileMapSupport = []Language{
 	LanguageOCaml,
 }
 
+// languages whose imports can be resolved to project files for 'plandex load --with-deps'
+var ImportGraphSupportSet = map[Language]bool{
+	LanguageGo:         true,
+	LanguageJavascript: true,
+	LanguageTypescript: true,
+	LanguageJsx:        true,
+	LanguageTsx:        true,
+	LanguagePython:     true,
+	LanguageRust:       true,
+	LanguageJava:       true,
+	LanguageKotlin:     true,
+	LanguageScala:      true,
+	LanguageC:          true,
+	LanguageCpp:        true,
+	LanguageRuby:       true,
+}
+
 var SkipTreeSitter = map[Language]bool{
 	LanguageMarkdown: true,
 }
@@ -130,6 +147,14 @@ func HasFileMapSupport(path string) bool {
 	return isDockerfile || (lang != "" && FileMapSupportSet[lang])
 }
 
+// HasImportGraphSupport is true for source files whose imports can be resolved, as well as go.mod files, which are needed to resolve Go imports
+func HasImportGraphSupport(path string) bool {
+	if filepath.Base(path) == "go.mod" {
+		return true
+	}
+	return ImportGraphSupportSet[LanguageByExtension[filepath.Ext(path)]]
+}
+
 var LanguageByExtension = map[string]Language{
 	".sh":     LanguageBash,
 	".bash":   LanguageBash,